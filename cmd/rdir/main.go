@@ -6,7 +6,6 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/gdamore/tcell/v2"
 	apppkg "github.com/kk-code-lab/rdir/internal/app"
 	"github.com/kk-code-lab/rdir/internal/shellsetup"
 )
@@ -15,32 +14,60 @@ func printHelp() {
 	fmt.Print(`rdir - Terminal-based file manager
 
 USAGE:
-    rdir [OPTIONS]
+    rdir [OPTIONS] [PATH]
+
+    PATH is the directory to start in; a file starts in its directory with
+    the file selected.
 
 OPTIONS:
     -h, --help            Show this help message and exit
     -s, --setup [SHELL]   Output shell integration snippet (optionally force SHELL)
+        --no-altscreen    Draw inline instead of in the alternate screen
+                          (also RDIR_NO_ALTSCREEN=1)
+        --summary         Print a one-line session summary to stderr on exit
+                          (also RDIR_EXIT_SUMMARY=1)
+        --doctor          Report the editor, pager, clipboard and shell rdir
+                          would use, and why any of them is missing
+        --clipboard-helper [ADDR]
+                          Run on the local machine to receive copies from rdir
+                          over SSH (default socket in the user cache dir)
+        --share           Let other rdir instances follow this session
+                          read-only (experimental; also RDIR_SHARE=1)
+        --follow [PID|SOCKET]
+                          Mirror a session started with --share; keys other
+                          than q are ignored
+        --trace-startup   Print how long each start-up phase took to stderr
+                          on exit (also RDIR_TRACE_STARTUP=1)
+    -0, --print0          End paths printed by RDIR_ENTER=print with NUL
+                          instead of newline, for xargs -0 (also RDIR_PRINT0=1)
+    -,  --stdin-path      Read PATH from the first line of standard input,
+                          e.g. echo /some/path | rdir -
+
+EXIT STATUS:
+    0   a path was chosen (picker mode) or rdir exited normally
+    1   picker mode (RDIR_ENTER=print) was left without choosing a file
+    2   rdir failed to start or to print the chosen paths
 `)
 }
 
 var parentShellDetector = shellsetup.DetectParentShellName
 
 func main() {
-	// Set UTF-8 as fallback encoding for maximum compatibility
-	// This ensures Polish and other Unicode characters display correctly
-	tcell.SetEncodingFallback(tcell.EncodingFallbackUTF8)
-
-	// Parse command-line arguments
-	if len(os.Args) > 1 {
-		arg := os.Args[1]
+	// Parse command-line arguments. Modes that print and exit return before
+	// the terminal or any configuration is touched. The start path is passed
+	// in Options rather than the environment, which shells and editors
+	// started from rdir would inherit.
+	var startPath string
+	for i := 1; i < len(os.Args); i++ {
+		arg := os.Args[i]
 		switch {
 		case arg == "-h" || arg == "--help":
 			printHelp()
 			os.Exit(0)
 		case arg == "-s" || arg == "--setup":
 			shellOverride := ""
-			if len(os.Args) > 2 {
-				shellOverride = os.Args[2]
+			if len(os.Args) > i+1 {
+				shellOverride = os.Args[i+1]
 			}
 			shellsetup.PrintSetup(shellOverride, shellsetup.Config{DetectParent: parentShellDetector})
 			os.Exit(0)
@@ -48,13 +75,62 @@ func main() {
 			shellOverride := strings.TrimPrefix(arg, "--setup=")
 			shellsetup.PrintSetup(shellOverride, shellsetup.Config{DetectParent: parentShellDetector})
 			os.Exit(0)
+		case arg == "--doctor":
+			if err := apppkg.WriteDoctor(os.Stdout); err != nil {
+				os.Exit(apppkg.ExitError)
+			}
+			os.Exit(0)
+		case arg == "--clipboard-helper":
+			address := ""
+			if len(os.Args) > i+1 && !strings.HasPrefix(os.Args[i+1], "-") {
+				address = os.Args[i+1]
+			}
+			os.Exit(apppkg.RunClipboardHelper(address, os.Stderr))
+		case strings.HasPrefix(arg, "--clipboard-helper="):
+			os.Exit(apppkg.RunClipboardHelper(strings.TrimPrefix(arg, "--clipboard-helper="), os.Stderr))
+		case arg == "--clipboard-send":
+			os.Exit(apppkg.RunClipboardSend(os.Stdin, os.Stderr))
+		case arg == "--no-altscreen":
+			_ = os.Setenv(apppkg.EnvNoAltScreen, "1")
+		case arg == "--summary":
+			_ = os.Setenv(apppkg.EnvExitSummary, "1")
+		case arg == "--share":
+			_ = os.Setenv(apppkg.EnvShare, "1")
+		case arg == "--follow":
+			target := "auto"
+			if len(os.Args) > i+1 && !strings.HasPrefix(os.Args[i+1], "-") {
+				i++
+				target = os.Args[i]
+			}
+			_ = os.Setenv(apppkg.EnvFollow, target)
+		case strings.HasPrefix(arg, "--follow="):
+			_ = os.Setenv(apppkg.EnvFollow, strings.TrimPrefix(arg, "--follow="))
+		case arg == "--trace-startup":
+			_ = os.Setenv(apppkg.EnvTraceStartup, "1")
+		case arg == "-0" || arg == "--print0":
+			_ = os.Setenv(apppkg.EnvPrint0, "1")
+		case arg == "-" || arg == "--stdin-path":
+			path, err := apppkg.ReadStartPath(os.Stdin)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading start path: %v\n", err)
+				os.Exit(apppkg.ExitError)
+			}
+			startPath = path
+		case !strings.HasPrefix(arg, "-"):
+			startPath = arg
 		}
 	}
 
-	app, err := apppkg.NewApplication()
+	os.Exit(run(startPath))
+}
+
+// run executes the session and returns the exit status; it is separate from
+// main so deferred cleanup happens before os.Exit.
+func run(startPath string) int {
+	app, err := apppkg.NewApplicationWith(apppkg.Options{StartPath: startPath})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing application: %v\n", err)
-		os.Exit(1)
+		return apppkg.ExitError
 	}
 	defer func() {
 		_ = app.Close()
@@ -62,6 +138,21 @@ func main() {
 
 	app.Run()
 
+	// The "print" Enter behavior hands the chosen files to the caller, e.g.
+	// vim "$(rdir)". The screen is already torn down at this point.
+	code := app.ExitCode()
+	if err := apppkg.WritePrintPaths(os.Stdout, app.GetPrintPaths(), apppkg.Print0Enabled(os.Getenv)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing paths: %v\n", err)
+		code = apppkg.ExitError
+	}
+	for _, entry := range app.GetQuickfixOutput() {
+		fmt.Fprintln(os.Stdout, entry)
+	}
+	if apppkg.ExitSummaryEnabled(os.Getenv) {
+		fmt.Fprintln(os.Stderr, app.ExitSummary())
+	}
+	_ = app.WriteStartupTrace(os.Stderr)
+
 	// Write selected directory to temp file for shell integration.
 	// If RDIR_RESULT_FILE is set, honor it; otherwise fall back to PID-based file.
 	if path := app.GetCurrentPath(); path != "" {
@@ -75,4 +166,5 @@ func main() {
 			fmt.Fprintf(os.Stderr, "Warning: could not write result file: %v\n", err)
 		}
 	}
+	return code
 }
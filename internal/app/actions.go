@@ -1,7 +1,9 @@
 package app
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path"
@@ -11,29 +13,47 @@ import (
 	"time"
 
 	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+	"github.com/kk-code-lab/rdir/internal/procwatch"
 	statepkg "github.com/kk-code-lab/rdir/internal/state"
 	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+	pagerui "github.com/kk-code-lab/rdir/internal/ui/pager"
 )
 
 var commandBuilder = exec.Command
 
 func (app *Application) handleClipboard() bool {
-	if app.clipboardAvail && len(app.clipboardCmd) > 0 {
-		path := clipboardPayload(app.state.CurrentFilePath(), runtime.GOOS)
-		err := runExternalCommand(app.clipboardCmd, func(cmd *exec.Cmd) {
-			cmd.Stdin = strings.NewReader(path)
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-		}, "clipboard")
-		if err != nil {
-			app.state.LastError = err
-			return true
-		}
-		app.state.LastYankTime = time.Now()
+	app.copyText(clipboardPayload(app.state.CurrentFilePath(), runtime.GOOS))
+	return true
+}
+
+// handlePathChoice copies the chosen form from the Y menu as written: it is
+// meant for another environment, so it is not normalized for this one.
+func (app *Application) handlePathChoice(index int) bool {
+	choice := app.state.PathChoice
+	app.state.PathChoice = nil
+	if choice == nil || index < 0 || index >= len(choice.Forms) {
+		return true
 	}
+	app.copyText(textutil.SanitizeTerminalText(choice.Forms[index].Path))
 	return true
 }
 
+func (app *Application) copyText(text string) {
+	if !app.clipboardAvail || len(app.clipboardCmd) == 0 {
+		return
+	}
+	err := runExternalCommand(app.clipboardCmd, func(cmd *exec.Cmd) {
+		cmd.Stdin = strings.NewReader(text)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+	}, "clipboard", procwatch.Clipboard)
+	if err != nil {
+		app.state.ReportError(statepkg.ErrorSourceGeneral, err)
+		return
+	}
+	app.state.LastYankTime = time.Now()
+}
+
 func normalizeClipboardPath(inputPath string, goos string) string {
 	if strings.EqualFold(goos, "windows") {
 		cleaned := filepath.Clean(inputPath)
@@ -55,20 +75,46 @@ func (app *Application) handleRightArrow() bool {
 
 	if file.IsDir {
 		if _, err := app.reducer.Reduce(app.state, statepkg.EnterDirectoryAction{}); err != nil {
-			app.state.LastError = err
+			app.state.ReportError(statepkg.ErrorSourceDirectory, err)
 		}
 		return true
 	}
 
+	filePath := app.state.CurrentFilePath()
+	switch behavior := app.state.Enter.For(file.Name); behavior {
+	case statepkg.EnterEditor:
+		if app.state.EditorAvailable && len(app.editorCmd) > 0 {
+			if err := app.openFileInEditor(filePath); err != nil {
+				app.state.ReportError(statepkg.ErrorSourceGeneral, err)
+			}
+			return true
+		}
+	case statepkg.EnterOpen:
+		if err := openWithSystemHandler(filePath, app.reportCommandError); err != nil {
+			app.state.ReportError(statepkg.ErrorSourceGeneral, err)
+		}
+		return true
+	case statepkg.EnterPrint:
+		app.logf("handleRightArrow print %s", filePath)
+		var paths []string
+		if app.state.Enter.Picks() && PrintMarkedEnabled(app.getenv) {
+			paths = app.state.MarkedPaths()
+		}
+		if len(paths) == 0 {
+			paths = []string{filePath}
+		}
+		return !app.finishWith(paths)
+	}
+
 	// Ensure preview matches the currently selected file; when user opens the
 	// fullscreen pager immediately after moving the cursor, the async preview
 	// load may still point to the previous selection.
 	if err := app.reducer.EnsurePreviewCurrent(app.state); err != nil {
-		app.state.LastError = err
+		app.state.ReportError(statepkg.ErrorSourcePreview, err)
 	}
 
 	if _, err := app.reducer.Reduce(app.state, statepkg.PreviewEnterFullScreenAction{}); err != nil {
-		app.state.LastError = err
+		app.state.ReportError(statepkg.ErrorSourcePreview, err)
 		return true
 	}
 
@@ -78,16 +124,47 @@ func (app *Application) handleRightArrow() bool {
 
 	defer func() {
 		if _, err := app.reducer.Reduce(app.state, statepkg.PreviewExitFullScreenAction{}); err != nil {
-			app.state.LastError = err
+			app.state.ReportError(statepkg.ErrorSourcePreview, err)
 		}
 	}()
 
 	if err := app.runPreviewPager(); err != nil {
-		app.state.LastError = err
+		app.state.ReportError(statepkg.ErrorSourceGeneral, err)
 	}
 	return true
 }
 
+// openWithSystemHandler hands filePath to the OS default application without
+// suspending the UI; the handler runs detached. An opener stopped for
+// overrunning its procwatch limit is passed to report.
+func openWithSystemHandler(filePath string, report func(error)) error {
+	base, ok := detectOpenCommand()
+	if !ok {
+		return fmt.Errorf("no system open command available")
+	}
+	args := append(append([]string{}, base...), filePath)
+	cmd := commandBuilder(args[0], args[1:]...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("open %s: %w", filepath.Base(filePath), err)
+	}
+	go func() {
+		var timeout *procwatch.TimeoutError
+		if err := procwatch.Wait(cmd, procwatch.Open); errors.As(err, &timeout) && report != nil {
+			report(fmt.Errorf("open %s: %w", filepath.Base(filePath), err))
+		}
+	}()
+	return nil
+}
+
+// reportCommandError hands an error from a background command to the event
+// loop.
+func (app *Application) reportCommandError(err error) {
+	select {
+	case app.actionCh <- statepkg.CommandErrorAction{Err: err}:
+	case <-app.closed:
+	}
+}
+
 func (app *Application) handleEditorOpen() bool {
 	if !app.state.EditorAvailable || len(app.editorCmd) == 0 {
 		return false
@@ -100,7 +177,7 @@ func (app *Application) handleEditorOpen() bool {
 
 	filePath := filepath.Join(app.state.CurrentPath, file.Name)
 	if err := app.openFileInEditor(filePath); err != nil {
-		app.state.LastError = err
+		app.state.ReportError(statepkg.ErrorSourceGeneral, err)
 	}
 	return true
 }
@@ -115,7 +192,7 @@ func (app *Application) handleOpenPager() bool {
 
 	// Ensure preview is current before entering fullscreen pager (fast key bursts).
 	if err := app.reducer.EnsurePreviewCurrent(app.state); err != nil {
-		app.state.LastError = err
+		app.state.ReportError(statepkg.ErrorSourcePreview, err)
 	}
 
 	// After EnsurePreviewCurrent, bail if preview still mismatches selection.
@@ -123,15 +200,115 @@ func (app *Application) handleOpenPager() bool {
 		return true
 	}
 	if err := app.openFileInPager(filePath); err != nil {
-		app.state.LastError = err
+		app.state.ReportError(statepkg.ErrorSourceGeneral, err)
+	}
+	return true
+}
+
+// handlePrivilegedRead shows a file rdir cannot open in the pager, reading
+// it through the configured helper (confirmed by PrivilegedReadAction). The
+// helper runs on the terminal so it can ask for a password. Its output only
+// lives in the pager: the preview is restored afterwards.
+func (app *Application) handlePrivilegedRead(filePath string) bool {
+	in, out := os.Stdin, os.Stderr
+	if tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0); err == nil {
+		defer func() { _ = tty.Close() }()
+		in, out = tty, tty
+	}
+
+	saved, savedScroll, savedWrap, savedFullScreen := app.state.PreviewData, app.state.PreviewScrollOffset, app.state.PreviewWrapOffset, app.state.PreviewFullScreen
+	app.stopEventPoller()
+	app.logf("handlePrivilegedRead: suspending screen")
+	if err := app.screen.Suspend(); err != nil {
+		app.startEventPoller()
+		app.state.ReportError(statepkg.ErrorSourceGeneral, fmt.Errorf("failed to suspend screen: %w", err))
+		return true
+	}
+
+	readErr := app.runPrivilegedPager(filePath, in, out)
+	app.recordAudit(statepkg.NewAuditEntry("privileged-read", filePath, "", readErr))
+
+	app.state.PreviewData, app.state.PreviewScrollOffset, app.state.PreviewWrapOffset, app.state.PreviewFullScreen = saved, savedScroll, savedWrap, savedFullScreen
+	err := app.screen.Resume()
+	app.logf("handlePrivilegedRead: resumed screen err=%v", readErr)
+	app.drainPendingEvents()
+	_ = flushConsoleInput()
+	if errReinit := app.reinitScreen(); errReinit != nil && err == nil {
+		err = errReinit
+	}
+	if app.processActions() {
+		app.renderer.Render(app.state)
+		app.screen.Show()
+	}
+	if readErr != nil {
+		err = readErr
+	}
+	if err != nil {
+		app.state.ReportError(statepkg.ErrorSourceGeneral, err)
+	}
+	return true
+}
+
+// runPrivilegedPager starts the helper, waits for any password prompt to be
+// answered and pages its output.
+func (app *Application) runPrivilegedPager(filePath string, in, out *os.File) error {
+	helper := strings.Join(app.state.PrivilegedHelper, " ")
+	_, _ = fmt.Fprintf(out, "rdir: reading %s with %s\r\n", textutil.SanitizeTerminalText(filePath), textutil.SanitizeTerminalText(helper))
+	reader, err := fsutil.StartHelperRead(app.state.PrivilegedHelper, filePath, in, out)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = reader.Close() }()
+	if err := reader.Ready(); err != nil {
+		return err
+	}
+	head := make([]byte, statepkg.PrivilegedPreviewHead())
+	n, err := reader.ReadAt(head, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	preview, err := statepkg.PrivilegedPreview(filePath, head[:n], reader, helper)
+	if err != nil {
+		return err
+	}
+
+	app.state.PreviewData = preview
+	app.state.PreviewScrollOffset = 0
+	app.state.PreviewWrapOffset = 0
+	app.state.PreviewFullScreen = true
+	view, err := pagerui.NewPreviewPager(app.state, app.editorCmd, app.reducer, app.clipboardCmd)
+	if err != nil {
+		return err
+	}
+	view.SetAltScreen(app.altScreen)
+	return view.Run()
+}
+
+// handleNormalizeLineEndings rewrites a file confirmed through
+// NormalizeLineEndingsAction and refreshes the listing so the backup shows up.
+func (app *Application) handleNormalizeLineEndings(action statepkg.NormalizeLineEndingsAction) bool {
+	backup, changed, err := fsutil.ConvertLineEndings(action.Path, action.Target)
+	app.logf("normalize line endings path=%s target=%s changed=%d backup=%s err=%v", action.Path, action.Target, changed, backup, err)
+	if err != nil || changed > 0 {
+		app.recordAudit(statepkg.NewAuditEntry("line-endings", action.Path, backup, err))
+	}
+	if err != nil {
+		app.state.ReportError(statepkg.ErrorSourceFiles, fmt.Errorf("convert line endings: %w", err))
+		return true
+	}
+	if changed > 0 {
+		app.operations++
+	}
+	if _, err := app.reducer.Reduce(app.state, statepkg.RefreshDirectoryAction{}); err != nil {
+		app.state.ReportError(statepkg.ErrorSourceFiles, err)
 	}
 	return true
 }
 
 func (app *Application) handleOpenShell() bool {
-	shellArgs, ok := detectShellCommand()
+	shellArgs, ok := detectShellCommand(app.getenv)
 	if !ok || len(shellArgs) == 0 {
-		app.state.LastError = fmt.Errorf("no shell command available")
+		app.state.ReportError(statepkg.ErrorSourceGeneral, fmt.Errorf("no shell command available"))
 		return true
 	}
 
@@ -150,7 +327,7 @@ func (app *Application) handleOpenShell() bool {
 	app.logf("handleOpenShell: suspending screen")
 	if err := app.screen.Suspend(); err != nil {
 		app.startEventPoller()
-		app.state.LastError = fmt.Errorf("failed to suspend screen: %w", err)
+		app.state.ReportError(statepkg.ErrorSourceGeneral, fmt.Errorf("failed to suspend screen: %w", err))
 		return true
 	}
 
@@ -170,10 +347,12 @@ func (app *Application) handleOpenShell() bool {
 			app.screen.Show()
 		}
 		if runErr != nil {
-			app.state.LastError = runErr
+			app.state.ReportError(statepkg.ErrorSourceGeneral, runErr)
 		}
 	}()
 
+	stdin, closeStdin := childStdin()
+	defer closeStdin()
 	runErr = runExternalCommand(shellArgs, func(cmd *exec.Cmd) {
 		cmd.Dir = app.state.CurrentPath
 		if useTTY && tty != nil {
@@ -181,17 +360,17 @@ func (app *Application) handleOpenShell() bool {
 			cmd.Stdout = tty
 			cmd.Stderr = tty
 		} else {
-			cmd.Stdin = os.Stdin
+			cmd.Stdin = stdin
 			cmd.Stdout = os.Stdout
 			cmd.Stderr = os.Stderr
 		}
-	}, "shell")
+	}, "shell", procwatch.Interactive)
 
 	return true
 }
 
 func (app *Application) pagerArgs(filePath string) []string {
-	base := detectPagerCommand(runtime.GOOS, os.Getenv("PAGER"), pagerLookPath)
+	base := detectPagerCommand(runtime.GOOS, app.getenv("PAGER"), pagerLookPath)
 	if len(base) == 0 {
 		return nil
 	}
@@ -234,7 +413,7 @@ func (app *Application) openFileInPager(filePath string) error {
 		cmd.Stdin = tty
 		cmd.Stdout = tty
 		cmd.Stderr = tty
-	}, "pager")
+	}, "pager", procwatch.Interactive)
 
 	if err := app.screen.Resume(); err != nil {
 		app.startEventPoller()
@@ -270,11 +449,13 @@ func (app *Application) openFileInPagerFallback(args []string) (err error) {
 		}
 	}()
 
+	stdin, closeStdin := childStdin()
+	defer closeStdin()
 	return runExternalCommand(args, func(cmd *exec.Cmd) {
-		cmd.Stdin = os.Stdin
+		cmd.Stdin = stdin
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
-	}, "pager")
+	}, "pager", procwatch.Interactive)
 }
 
 func (app *Application) openFileInEditor(filePath string) error {
@@ -303,17 +484,19 @@ func (app *Application) openFileInEditor(filePath string) error {
 		return fmt.Errorf("failed to suspend screen: %w", err)
 	}
 
+	stdin, closeStdin := childStdin()
+	defer closeStdin()
 	runErr := runExternalCommand(editorArgs, func(cmd *exec.Cmd) {
 		if useTTY {
 			cmd.Stdin = tty
 			cmd.Stdout = tty
 			cmd.Stderr = tty
 		} else {
-			cmd.Stdin = os.Stdin
+			cmd.Stdin = stdin
 			cmd.Stdout = os.Stdout
 			cmd.Stderr = os.Stderr
 		}
-	}, "editor")
+	}, "editor", procwatch.Interactive)
 
 	if err := app.screen.Resume(); err != nil {
 		app.startEventPoller()
@@ -346,21 +529,21 @@ func (app *Application) openFileInEditorFallback(args []string) (err error) {
 		}
 	}()
 
+	stdin, closeStdin := childStdin()
+	defer closeStdin()
 	return runExternalCommand(args, func(cmd *exec.Cmd) {
-		cmd.Stdin = os.Stdin
+		cmd.Stdin = stdin
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
-	}, "editor")
+	}, "editor", procwatch.Interactive)
 }
 
 func (app *Application) editorArgsWithFile(filePath string) []string {
-	args := make([]string, len(app.editorCmd)+1)
-	copy(args, app.editorCmd)
-	args[len(app.editorCmd)] = filePath
-	return args
+	return statepkg.EditorArgs(app.editorCmd, filePath, 0)
 }
 
-func runExternalCommand(args []string, configure func(*exec.Cmd), label string) error {
+// runExternalCommand runs args under the procwatch limits of kind.
+func runExternalCommand(args []string, configure func(*exec.Cmd), label string, kind procwatch.Kind) error {
 	if len(args) == 0 {
 		return fmt.Errorf("no %s command provided", label)
 	}
@@ -368,7 +551,7 @@ func runExternalCommand(args []string, configure func(*exec.Cmd), label string)
 	if configure != nil {
 		configure(cmd)
 	}
-	if err := cmd.Run(); err != nil {
+	if err := procwatch.Run(cmd, kind); err != nil {
 		return fmt.Errorf("%s command %q failed: %w", label, args[0], err)
 	}
 	return nil
@@ -30,7 +30,7 @@ func TestNormalizeClipboardPathUnix(t *testing.T) {
 	}
 }
 
-func TestHandleClipboardSetsLastErrorOnFailure(t *testing.T) {
+func TestHandleClipboardReportsErrorOnFailure(t *testing.T) {
 	app := newTestApplicationWithFile(t)
 	app.clipboardAvail = true
 	app.clipboardCmd = []string{"fake-clip", "--flag"}
@@ -40,10 +40,11 @@ func TestHandleClipboardSetsLastErrorOnFailure(t *testing.T) {
 		app.handleClipboard()
 	})
 
-	if app.state.LastError == nil {
-		t.Fatalf("expected clipboard failure to set LastError")
+	err := app.state.Errors.Err(statepkg.ErrorSourceGeneral)
+	if err == nil {
+		t.Fatalf("expected clipboard failure to be reported")
 	}
-	if got := app.state.LastError.Error(); !strings.Contains(got, "fake-clip") {
+	if got := err.Error(); !strings.Contains(got, "fake-clip") {
 		t.Fatalf("expected error mentioning command, got %q", got)
 	}
 	if !app.state.LastYankTime.IsZero() {
@@ -65,8 +66,8 @@ func TestHandleClipboardUpdatesYankTimeOnSuccess(t *testing.T) {
 	if app.state.LastYankTime.IsZero() {
 		t.Fatalf("expected LastYankTime to update on success")
 	}
-	if app.state.LastError != nil {
-		t.Fatalf("expected LastError to remain nil on success, got %v", app.state.LastError)
+	if banner := app.state.Errors.Top(); banner != nil {
+		t.Fatalf("expected no error on success, got %v", banner.Err)
 	}
 	assertCommandRecorded(t, recorded, []string{"fake-clip"})
 }
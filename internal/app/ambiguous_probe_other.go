@@ -0,0 +1,11 @@
+//go:build windows || plan9 || js || wasip1
+
+package app
+
+import "time"
+
+// probeAmbiguousWidth cannot query the console here; auto keeps the guess
+// from the locale.
+func probeAmbiguousWidth(time.Duration) (wide, ok bool) {
+	return false, false
+}
@@ -0,0 +1,68 @@
+//go:build !windows && !plan9 && !js && !wasip1
+
+package app
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
+)
+
+// probeAmbiguousWidth asks the terminal how wide it draws an ambiguous-width
+// character: it prints "·" at the start of the line, requests the cursor
+// position (CSI 6n) and erases the line again. ok is false when there is no
+// terminal or it does not answer within timeout.
+//
+// A device attributes request (CSI c) goes out after the position request,
+// and the probe reads until its answer is in, for up to ambiguousDrainTimeout
+// past timeout. Terminals answer in order, so a slow position report is
+// consumed here rather than reaching tcell as stray key presses.
+func probeAmbiguousWidth(timeout time.Duration) (wide, ok bool) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return false, false
+	}
+	defer tty.Close()
+	fd := int(tty.Fd())
+	saved, err := term.MakeRaw(fd)
+	if err != nil {
+		return false, false
+	}
+	defer func() { _ = term.Restore(fd, saved) }()
+
+	if _, err := tty.WriteString("\r·\x1b[6n\x1b[c"); err != nil {
+		return false, false
+	}
+	defer func() { _, _ = tty.WriteString("\r\x1b[K") }()
+
+	deadline := time.Now().Add(timeout)
+	drainBy := deadline.Add(ambiguousDrainTimeout)
+	var reply []byte
+	column, answered := 0, false
+	buf := make([]byte, 64)
+	for !deviceAttributesReply(reply) {
+		wait := time.Until(drainBy)
+		if wait <= 0 {
+			break
+		}
+		fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+		n, err := unix.Poll(fds, int(wait.Milliseconds())+1)
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil || n == 0 {
+			break
+		}
+		read, err := tty.Read(buf)
+		if err != nil {
+			break
+		}
+		reply = append(reply, buf[:read]...)
+		if !answered && time.Now().Before(deadline) {
+			column, answered = cursorColumn(reply)
+		}
+	}
+	return column > 2, answered
+}
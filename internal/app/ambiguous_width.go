@@ -0,0 +1,83 @@
+package app
+
+import (
+	"bytes"
+	"time"
+
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+)
+
+// ambiguousProbeTimeout bounds how long startup waits for the terminal to
+// report the cursor position; terminals that do not answer keep the guess.
+const ambiguousProbeTimeout = 150 * time.Millisecond
+
+// ambiguousDrainTimeout is how much longer the probe keeps reading after an
+// answer is late, so a report arriving then is not left for tcell to read
+// as keys.
+const ambiguousDrainTimeout = 300 * time.Millisecond
+
+// configureAmbiguousWidth applies RDIR_AMBIGUOUS_WIDTH to every width
+// calculation and to tcell's cell layout. probe asks the terminal when auto
+// cannot decide from the environment; it is nil when rdir does not own the
+// terminal (an embedder's screen).
+func configureAmbiguousWidth(getenv func(string) string, probe func() (wide, ok bool)) error {
+	setting, err := statepkg.LoadAmbiguousWidth(getenv)
+	wide, needsProbe := statepkg.ResolveAmbiguousWidth(setting, getenv)
+	if needsProbe && probe != nil {
+		if probed, ok := probe(); ok {
+			wide = probed
+		}
+	}
+	textutil.SetAmbiguousWide(wide)
+	return err
+}
+
+// cursorColumn finds a cursor position report (ESC [ row ; column R) in
+// reply, skipping anything typed before it.
+func cursorColumn(reply []byte) (int, bool) {
+	for {
+		start := bytes.Index(reply, []byte("\x1b["))
+		if start < 0 {
+			return 0, false
+		}
+		reply = reply[start+2:]
+		row, rest, ok := leadingNumber(reply)
+		if !ok || row == 0 || len(rest) == 0 || rest[0] != ';' {
+			continue
+		}
+		column, rest, ok := leadingNumber(rest[1:])
+		if !ok || len(rest) == 0 || rest[0] != 'R' {
+			continue
+		}
+		return column, true
+	}
+}
+
+func leadingNumber(b []byte) (int, []byte, bool) {
+	n, i := 0, 0
+	for ; i < len(b) && b[i] >= '0' && b[i] <= '9'; i++ {
+		n = n*10 + int(b[i]-'0')
+	}
+	return n, b[i:], i > 0
+}
+
+// deviceAttributesReply reports whether reply holds the answer to a primary
+// device attributes request (ESC [ ? params c). Terminals answer requests in
+// order, so it marks the end of everything the probe asked for.
+func deviceAttributesReply(reply []byte) bool {
+	for {
+		start := bytes.Index(reply, []byte("\x1b[?"))
+		if start < 0 {
+			return false
+		}
+		reply = reply[start+3:]
+		i := 0
+		for i < len(reply) && (reply[i] >= '0' && reply[i] <= '9' || reply[i] == ';') {
+			i++
+		}
+		if i < len(reply) && reply[i] == 'c' {
+			return true
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"testing"
+
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+)
+
+func TestCursorColumn(t *testing.T) {
+	cases := []struct {
+		reply  string
+		column int
+		ok     bool
+	}{
+		{"\x1b[12;3R", 3, true},
+		{"x\x1b[1;2R", 2, true},
+		{"\x1b[A\x1b[5;2R", 2, true},
+		{"\x1b[12;3", 0, false},
+		{"", 0, false},
+	}
+	for _, tc := range cases {
+		column, ok := cursorColumn([]byte(tc.reply))
+		if column != tc.column || ok != tc.ok {
+			t.Fatalf("cursorColumn(%q) = %d, %v; want %d, %v", tc.reply, column, ok, tc.column, tc.ok)
+		}
+	}
+}
+
+func TestDeviceAttributesReply(t *testing.T) {
+	cases := map[string]bool{
+		"\x1b[?62;22c":         true,
+		"\x1b[12;3R\x1b[?1;2c": true,
+		"\x1b[?6c":             true,
+		"\x1b[12;3R":           false,
+		"\x1b[?62;22":          false,
+		"\x1b[62c":             false,
+		"":                     false,
+	}
+	for reply, want := range cases {
+		if got := deviceAttributesReply([]byte(reply)); got != want {
+			t.Errorf("deviceAttributesReply(%q) = %v, want %v", reply, got, want)
+		}
+	}
+}
+
+func TestConfigureAmbiguousWidthProbesOnlyWhenNeeded(t *testing.T) {
+	defer textutil.SetAmbiguousWide(false)
+	env := func(vars map[string]string) func(string) string {
+		return func(key string) string { return vars[key] }
+	}
+	probes := 0
+	narrowTerminal := func() (bool, bool) { probes++; return false, true }
+	silentTerminal := func() (bool, bool) { probes++; return false, false }
+
+	if err := configureAmbiguousWidth(env(map[string]string{"LANG": "ja_JP.UTF-8"}), narrowTerminal); err != nil || textutil.AmbiguousWide() {
+		t.Fatalf("expected the terminal's answer (narrow) to win, err=%v", err)
+	}
+	if err := configureAmbiguousWidth(env(map[string]string{"LANG": "ja_JP.UTF-8"}), silentTerminal); err != nil || !textutil.AmbiguousWide() {
+		t.Fatalf("expected the CJK locale guess (wide) without an answer, err=%v", err)
+	}
+	if probes != 2 {
+		t.Fatalf("probes = %d, want 2", probes)
+	}
+	if err := configureAmbiguousWidth(env(map[string]string{"LANG": "en_US.UTF-8"}), narrowTerminal); err != nil || textutil.AmbiguousWide() || probes != 2 {
+		t.Fatalf("expected narrow without probing, err=%v probes=%d", err, probes)
+	}
+	if err := configureAmbiguousWidth(env(map[string]string{"RDIR_AMBIGUOUS_WIDTH": "wide"}), narrowTerminal); err != nil || !textutil.AmbiguousWide() || probes != 2 {
+		t.Fatalf("expected wide from the setting without probing, err=%v probes=%d", err, probes)
+	}
+	if err := configureAmbiguousWidth(env(map[string]string{"RDIR_AMBIGUOUS_WIDTH": "huge"}), nil); err == nil {
+		t.Fatalf("expected an invalid value to be reported")
+	}
+}
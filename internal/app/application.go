@@ -2,35 +2,160 @@ package app
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
 	statepkg "github.com/kk-code-lab/rdir/internal/state"
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
 	inputui "github.com/kk-code-lab/rdir/internal/ui/input"
 	renderui "github.com/kk-code-lab/rdir/internal/ui/render"
 )
 
-var debugLoggingEnabled = os.Getenv("RDIR_DEBUG_LOG") == "1"
+// EnvDebugLog writes a debug log to rdir_debug.log in the temp directory
+// when set to 1.
+const EnvDebugLog = "RDIR_DEBUG_LOG"
+
+// EnvNoAltScreen runs rdir inline instead of in the alternate screen buffer
+// (set by --no-altscreen), so the final listing stays in the scrollback.
+const EnvNoAltScreen = "RDIR_NO_ALTSCREEN"
+
+// useAltScreen reports whether the UI should run in the alternate screen.
+// Windows consoles always run inline.
+func useAltScreen(goos string, getenv func(string) string) bool {
+	if strings.EqualFold(goos, "windows") {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(getenv(EnvNoAltScreen))) {
+	case "", "0", "false", "no":
+		return true
+	}
+	return false
+}
+
+// EnvExitSummary prints a one-line session summary to stderr on exit (same as
+// --summary).
+const EnvExitSummary = "RDIR_EXIT_SUMMARY"
+
+// ExitSummaryEnabled reports whether the exit summary was requested.
+func ExitSummaryEnabled(getenv func(string) string) bool {
+	switch strings.ToLower(strings.TrimSpace(getenv(EnvExitSummary))) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
+// EnvPrint0 terminates printed paths with NUL instead of newline (same as
+// -0/--print0), for pipelines such as xargs -0.
+const EnvPrint0 = "RDIR_PRINT0"
+
+// Print0Enabled reports whether NUL-separated output was requested.
+func Print0Enabled(getenv func(string) string) bool {
+	switch strings.ToLower(strings.TrimSpace(getenv(EnvPrint0))) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
+// EnvPrintMarked makes Enter/→ in picker mode (RDIR_ENTER=print) print the
+// marked entries instead of the file under the cursor (1, true or yes).
+const EnvPrintMarked = "RDIR_PRINT_MARKED"
+
+// PrintMarkedEnabled reports whether marked entries are printed in picker
+// mode.
+func PrintMarkedEnabled(getenv func(string) string) bool {
+	switch strings.ToLower(strings.TrimSpace(getenv(EnvPrintMarked))) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
+// Exit statuses of rdir. They matter in picker mode (RDIR_ENTER=print), where
+// wrapper scripts branch on whether the user chose something.
+const (
+	ExitChosen    = 0 // a path was chosen, or rdir is not used as a picker
+	ExitCancelled = 1 // picker mode was left without choosing
+	ExitError     = 2 // rdir could not start or could not print the result
+)
+
+// ExitCode returns the exit status for the finished session.
+func (app *Application) ExitCode() int {
+	if len(app.printPaths) == 0 && app.state != nil && app.state.Enter.Picks() {
+		return ExitCancelled
+	}
+	return ExitChosen
+}
+
+// WritePrintPaths writes the chosen paths one per line, or each terminated
+// by NUL when null is set so names containing newlines stay intact.
+func WritePrintPaths(w io.Writer, paths []string, null bool) error {
+	term := "\n"
+	if null {
+		term = "\x00"
+	}
+	for _, path := range paths {
+		if _, err := io.WriteString(w, path+term); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configureAltScreen applies the choice to tcell, which reads it from the
+// environment on every screen Init.
+func configureAltScreen(enabled bool) {
+	if !enabled {
+		_ = os.Setenv("TCELL_ALTSCREEN", "disable")
+	}
+}
 
 // Application represents the running app.
 type Application struct {
-	screen         tcell.Screen
-	state          *statepkg.AppState
-	reducer        *statepkg.StateReducer
-	renderer       *renderui.Renderer
-	input          *inputui.InputHandler
-	actionCh       chan statepkg.Action
-	debugLog       interface{ Printf(string, ...interface{}) }
-	debugLogFile   *os.File
-	eventChan      chan tcell.Event
-	eventStop      chan struct{}
-	eventStopped   chan struct{}
-	shouldQuit     bool
-	currentPath    string
-	clipboardCmd   []string
-	clipboardAvail bool
-	editorCmd      []string
+	screen             tcell.Screen
+	state              *statepkg.AppState
+	reducer            *statepkg.StateReducer
+	renderer           *renderui.Renderer
+	input              *inputui.InputHandler
+	actionCh           chan statepkg.Action
+	closed             chan struct{} // closed by Close; actionCh stays open for late senders
+	debugLog           interface{ Printf(string, ...interface{}) }
+	debugLogFile       *os.File
+	eventChan          chan tcell.Event
+	eventStop          chan struct{}
+	eventStopped       chan struct{}
+	shouldQuit         bool
+	currentPath        string
+	printPaths         []string
+	clipboardCmd       []string
+	clipboardAvail     bool
+	editorCmd          []string
+	env                func(string) string // Options.Getenv; nil reads the process environment
+	stagingFile        string
+	pagerStateFile     string
+	sessionPagerMemory *statepkg.PagerMemory // pager state kept in memory when pagerStateFile is off
+	altScreen          bool
+	screenDone         bool          // finalized by Run; simulation screens panic on a second Fini
+	operations         int           // file operations performed, for the exit summary
+	startup            *startupTrace // nil unless RDIR_TRACE_STARTUP is set
+	share              sharing       // rdir --share / --follow
+	hooks              *hookRunner   // nil unless a RDIR_HOOK_* command is set
+	archiveCancel      func()        // stops the running archive extraction
+	powerChecking      bool          // a power source check is running
+	powerUnknown       bool          // the platform cannot tell the power source
+
+	// Embedding (see Options)
+	accept                    func(paths []string) bool
+	keys                      map[string]KeyHandler
+	onCursor                  func(dir, selected string)
+	cursorDir, cursorSelected string
+
+	// Preview load held back while input is queued (see flushDeferredPreview)
+	deferredPreview *statepkg.PreviewLoadStartAction
 
 	// Mouse state
 	lastClickTime    time.Time
@@ -38,11 +163,24 @@ type Application struct {
 	lastMouseButtons tcell.ButtonMask
 }
 
+// getenv reads a setting through Options.Getenv.
+func (app *Application) getenv(key string) string {
+	if app.env != nil {
+		return app.env(key)
+	}
+	return os.Getenv(key)
+}
+
 // Close cleans up resources.
 func (app *Application) Close() error {
-	close(app.actionCh)
+	app.stopSharing()
+	app.hooks.close()
+	if app.archiveCancel != nil {
+		app.archiveCancel()
+	}
+	close(app.closed)
 	app.stopEventPoller()
-	if app.screen != nil {
+	if app.screen != nil && !app.screenDone {
 		app.screen.Fini()
 	}
 	if app.debugLogFile != nil {
@@ -56,6 +194,39 @@ func (app *Application) GetCurrentPath() string {
 	return app.currentPath
 }
 
+// GetQuickfixOutput returns the pager search hits exported with
+// RDIR_QUICKFIX=-, in quickfix format, to print to stdout after exit.
+func (app *Application) GetQuickfixOutput() []string {
+	if app.state == nil {
+		return nil
+	}
+	return app.state.QuickfixOutput
+}
+
+// GetPrintPaths returns the files chosen with the "print" Enter behavior: the
+// marked entries when there are any, otherwise the file Enter was pressed on.
+func (app *Application) GetPrintPaths() []string {
+	return app.printPaths
+}
+
+// ExitSummary returns a one-line recap of the session (final directory,
+// file operations performed, last selected entry) for the scrollback.
+func (app *Application) ExitSummary() string {
+	dir := app.state.CurrentPath
+	parts := []string{"rdir: " + dir}
+
+	ops := "operations"
+	if app.operations == 1 {
+		ops = "operation"
+	}
+	parts = append(parts, fmt.Sprintf("%d %s", app.operations, ops))
+
+	if file := app.state.CurrentFile(); file != nil {
+		parts = append(parts, "last: "+file.Name)
+	}
+	return textutil.SanitizeTerminalText(strings.Join(parts, " · "))
+}
+
 // GetCwd returns current working directory.
 func GetCwd() (string, error) {
 	return os.Getwd()
@@ -117,7 +288,7 @@ func (app *Application) drainPendingEvents() {
 }
 
 func (app *Application) logf(format string, args ...interface{}) {
-	if !debugLoggingEnabled || app == nil || app.debugLog == nil {
+	if app == nil || app.debugLog == nil {
 		return
 	}
 	ts := time.Now().Format("2006-01-02 15:04:05.000000 -0700 MST")
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	fsutil "github.com/kk-code-lab/rdir/internal/fs"
@@ -247,6 +248,25 @@ func TestDetectClipboardFallsBackToPowershell(t *testing.T) {
 	}
 }
 
+func TestRemoteClipboardCommandOverSSH(t *testing.T) {
+	orig := clipboardExecutable
+	clipboardExecutable = func() (string, error) { return "/usr/local/bin/rdir", nil }
+	defer func() { clipboardExecutable = orig }()
+
+	env := map[string]string{}
+	getenv := func(key string) string { return env[key] }
+	if _, ok := remoteClipboardCommand(getenv); ok {
+		t.Fatalf("expected local clipboard outside SSH")
+	}
+
+	env["SSH_TTY"] = "/dev/pts/3"
+	args, ok := remoteClipboardCommand(getenv)
+	expected := []string{"/usr/local/bin/rdir", clipboardSendFlag}
+	if !ok || !reflect.DeepEqual(args, expected) {
+		t.Fatalf("expected %v over SSH, got %v (ok=%v)", expected, args, ok)
+	}
+}
+
 func TestDetectEditorCommandWindowsFallbacks(t *testing.T) {
 	lookPath := func(cmd string) (string, error) {
 		switch cmd {
@@ -287,6 +307,78 @@ func TestDetectEditorCommandUnixFallbacks(t *testing.T) {
 	}
 }
 
+func TestDetectEditorCommandPrefersVisualThenEditor(t *testing.T) {
+	lookPath := func(cmd string) (string, error) {
+		switch cmd {
+		case "code":
+			return "/usr/bin/code", nil
+		case "vim":
+			return "/usr/bin/vim", nil
+		}
+		return "", errors.New("not found")
+	}
+	env := map[string]string{"VISUAL": "subl -w", "EDITOR": `code --wait`}
+	args, status, ok := explainEditorCommand("linux", func(k string) string { return env[k] }, lookPath)
+	if !ok {
+		t.Fatalf("expected $EDITOR to be used")
+	}
+	if expected := []string{"/usr/bin/code", "--wait"}; !reflect.DeepEqual(args, expected) {
+		t.Fatalf("expected %v, got %v", expected, args)
+	}
+	if !strings.HasPrefix(status, "$EDITOR") {
+		t.Fatalf("unexpected status %q", status)
+	}
+}
+
+func TestExplainEditorCommandReportsWhyUnavailable(t *testing.T) {
+	lookPath := func(string) (string, error) { return "", errors.New("not found") }
+	env := map[string]string{"VISUAL": "subl -w"}
+	_, status, ok := explainEditorCommand("linux", func(k string) string { return env[k] }, lookPath)
+	if ok {
+		t.Fatalf("expected no editor")
+	}
+	for _, want := range []string{`$VISUAL "subl -w": subl not found`, "none of vim, nano"} {
+		if !strings.Contains(status, want) {
+			t.Fatalf("expected %q in %q", want, status)
+		}
+	}
+}
+
+func TestParseEditorCommandQuotesAndEscapes(t *testing.T) {
+	cases := map[string][]string{
+		`code --wait`:                        {"code", "--wait"},
+		`"/opt/My Editor/edit" -w`:           {"/opt/My Editor/edit", "-w"},
+		`/opt/My\ Editor/edit --title 'a b'`: {"/opt/My Editor/edit", "--title", "a b"},
+		`emacs --eval "(setq x \"y\")"`:      {"emacs", "--eval", `(setq x "y")`},
+		`C:\Tools\edit.exe /n`:               {`C:\Tools\edit.exe`, "/n"},
+	}
+	for input, expected := range cases {
+		if got := parseEditorCommand(input); !reflect.DeepEqual(got, expected) {
+			t.Errorf("parseEditorCommand(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
+
+func TestWriteDoctorReportsMissingTools(t *testing.T) {
+	lookPath := func(cmd string) (string, error) {
+		switch cmd {
+		case "less", "bash":
+			return "/usr/bin/" + cmd, nil
+		}
+		return "", errors.New("not found")
+	}
+	var buf strings.Builder
+	if err := writeDoctor(&buf, "linux", func(string) string { return "" }, lookPath); err != nil {
+		t.Fatalf("writeDoctor: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"editor     missing  $VISUAL and $EDITOR are unset", "pager      ok       less", "clipboard  missing", "shell      ok       /usr/bin/bash", "timeouts   ok       clipboard=5s, decompress=30s, hook=5s, open=off, query=5s, kill=2s", "widths     ok       ambiguous characters narrow"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in:\n%s", want, out)
+		}
+	}
+}
+
 func TestDetectShellUsesShellEnvOnUnix(t *testing.T) {
 	lookPath := func(cmd string) (string, error) {
 		if cmd == "zsh" {
@@ -335,3 +427,213 @@ func TestDetectShellPrefersPwshOnWindows(t *testing.T) {
 		t.Fatalf("expected %v, got %v", expected, args)
 	}
 }
+
+func TestDetectOpenCommandInternal(t *testing.T) {
+	if args, ok := detectOpenCommandInternal("darwin", nil); !ok || len(args) != 1 || args[0] != "open" {
+		t.Fatalf("darwin open command = %v, %v", args, ok)
+	}
+	if args, ok := detectOpenCommandInternal("windows", nil); !ok || args[0] != "cmd" || args[len(args)-1] != "" {
+		t.Fatalf("windows open command = %v, %v", args, ok)
+	}
+
+	lookPath := func(name string) (string, error) {
+		if name == "gio" {
+			return "/usr/bin/gio", nil
+		}
+		return "", errors.New("not found")
+	}
+	args, ok := detectOpenCommandInternal("linux", lookPath)
+	if !ok || len(args) != 2 || args[0] != "/usr/bin/gio" || args[1] != "open" {
+		t.Fatalf("linux fallback open command = %v, %v", args, ok)
+	}
+}
+
+func TestUseAltScreen(t *testing.T) {
+	tests := []struct {
+		goos  string
+		value string
+		want  bool
+	}{
+		{goos: "linux", value: "", want: true},
+		{goos: "linux", value: "0", want: true},
+		{goos: "darwin", value: "1", want: false},
+		{goos: "linux", value: "yes", want: false},
+		{goos: "windows", value: "", want: false},
+	}
+	for _, tt := range tests {
+		getenv := func(key string) string {
+			if key == EnvNoAltScreen {
+				return tt.value
+			}
+			return ""
+		}
+		if got := useAltScreen(tt.goos, getenv); got != tt.want {
+			t.Errorf("useAltScreen(%s, %q) = %v, want %v", tt.goos, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestExitSummaryReportsDirectoryOperationsAndSelection(t *testing.T) {
+	app := &Application{
+		state: &statepkg.AppState{
+			CurrentPath: "/work/logs",
+			Files:       []statepkg.FileEntry{{Name: "build.log"}},
+		},
+		operations: 3,
+	}
+
+	want := "rdir: /work/logs · 3 operations · last: build.log"
+	if got := app.ExitSummary(); got != want {
+		t.Fatalf("ExitSummary() = %q, want %q", got, want)
+	}
+
+	if !ExitSummaryEnabled(func(string) string { return "1" }) || ExitSummaryEnabled(func(string) string { return "" }) {
+		t.Fatal("ExitSummaryEnabled should follow RDIR_EXIT_SUMMARY")
+	}
+}
+
+func TestWritePrintPathsSeparators(t *testing.T) {
+	paths := []string{"/tmp/a b.txt", "/tmp/odd\nname"}
+
+	var lines strings.Builder
+	if err := WritePrintPaths(&lines, paths, false); err != nil {
+		t.Fatalf("WritePrintPaths: %v", err)
+	}
+	if got := lines.String(); got != "/tmp/a b.txt\n/tmp/odd\nname\n" {
+		t.Fatalf("newline output = %q", got)
+	}
+
+	var nul strings.Builder
+	if err := WritePrintPaths(&nul, paths, true); err != nil {
+		t.Fatalf("WritePrintPaths: %v", err)
+	}
+	if got := nul.String(); got != "/tmp/a b.txt\x00/tmp/odd\nname\x00" {
+		t.Fatalf("NUL output = %q", got)
+	}
+
+	if !Print0Enabled(func(string) string { return "1" }) || Print0Enabled(func(string) string { return "" }) {
+		t.Fatal("Print0Enabled should follow RDIR_PRINT0")
+	}
+}
+
+func TestExitCodeInPickerMode(t *testing.T) {
+	app := &Application{state: &statepkg.AppState{}}
+	if got := app.ExitCode(); got != ExitChosen {
+		t.Fatalf("outside picker mode expected %d, got %d", ExitChosen, got)
+	}
+
+	app.state.Enter = statepkg.EnterConfig{Default: statepkg.EnterPager, ByExt: map[string]statepkg.EnterBehavior{"pdf": statepkg.EnterPrint}}
+	if got := app.ExitCode(); got != ExitChosen {
+		t.Fatalf("a printing extension alone expected %d, got %d", ExitChosen, got)
+	}
+
+	app.state.Enter = statepkg.EnterConfig{Default: statepkg.EnterPrint}
+	if got := app.ExitCode(); got != ExitCancelled {
+		t.Fatalf("cancelled picker expected %d, got %d", ExitCancelled, got)
+	}
+
+	app.printPaths = []string{"/tmp/a.pdf"}
+	if got := app.ExitCode(); got != ExitChosen {
+		t.Fatalf("chosen path expected %d, got %d", ExitChosen, got)
+	}
+}
+
+func TestRightArrowPrintsMarkedOnlyWhenAskedInPickerMode(t *testing.T) {
+	dir := t.TempDir()
+	newApp := func(enter statepkg.EnterConfig, printMarked string) *Application {
+		state := &statepkg.AppState{
+			CurrentPath: dir,
+			Files: []statepkg.FileEntry{
+				{Name: "a.txt", FullPath: filepath.Join(dir, "a.txt")},
+				{Name: "b.txt", FullPath: filepath.Join(dir, "b.txt")},
+			},
+			Enter: enter,
+		}
+		app := &Application{state: state, reducer: statepkg.NewStateReducer()}
+		app.env = func(key string) string {
+			if key == EnvPrintMarked {
+				return printMarked
+			}
+			return ""
+		}
+		if _, err := app.reducer.Reduce(state, statepkg.ToggleMarkAction{}); err != nil {
+			t.Fatalf("mark: %v", err)
+		}
+		state.SelectedIndex = 1
+		return app
+	}
+	marked := []string{filepath.Join(dir, "a.txt")}
+	current := []string{filepath.Join(dir, "b.txt")}
+
+	cases := []struct {
+		name        string
+		enter       statepkg.EnterConfig
+		printMarked string
+		want        []string
+	}{
+		{"picker without the setting", statepkg.EnterConfig{Default: statepkg.EnterPrint}, "", current},
+		{"picker with the setting", statepkg.EnterConfig{Default: statepkg.EnterPrint}, "1", marked},
+		{"printing extension", statepkg.EnterConfig{Default: statepkg.EnterPager, ByExt: map[string]statepkg.EnterBehavior{"txt": statepkg.EnterPrint}}, "1", current},
+	}
+	for _, tc := range cases {
+		app := newApp(tc.enter, tc.printMarked)
+		app.handleRightArrow()
+		if got := app.GetPrintPaths(); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: printed %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestStartupTraceWritesPhasesInOrder(t *testing.T) {
+	app := &Application{}
+	var out strings.Builder
+	if err := app.WriteStartupTrace(&out); err != nil || out.Len() != 0 {
+		t.Fatalf("disabled trace wrote %q, %v", out.String(), err)
+	}
+
+	app.startup = newStartupTrace(StartupTraceEnabled(func(string) string { return "yes" }))
+	app.startup.mark("screen init")
+	app.startup.mark("first frame")
+	if err := app.WriteStartupTrace(&out); err != nil {
+		t.Fatalf("WriteStartupTrace: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "startup screen init") || !strings.HasPrefix(lines[1], "startup first frame") {
+		t.Fatalf("unexpected trace:\n%s", out.String())
+	}
+}
+
+func TestPowerCheckUpdatesEcoMode(t *testing.T) {
+	defer func(orig func() (bool, bool)) { onBattery = orig }(onBattery)
+	app := &Application{
+		state:    &statepkg.AppState{},
+		reducer:  statepkg.NewStateReducer(),
+		actionCh: make(chan statepkg.Action, 1),
+		closed:   make(chan struct{}),
+	}
+	check := func() bool {
+		app.startPowerCheck()
+		return app.handleAction(<-app.actionCh)
+	}
+
+	onBattery = func() (bool, bool) { return true, true }
+	if !check() || !app.state.EcoActive {
+		t.Fatalf("expected eco mode on battery, got %+v", app.state.EcoActive)
+	}
+	if got := app.animationInterval(); got != ecoAnimationInterval {
+		t.Fatalf("animation interval in eco mode = %v", got)
+	}
+	if check() {
+		t.Fatal("an unchanged power source should not redraw")
+	}
+
+	onBattery = func() (bool, bool) { return false, false }
+	check()
+	if !app.powerUnknown || !app.state.EcoActive {
+		t.Fatalf("an unknown power source should stop the checks and keep the state, got unknown=%v eco=%v", app.powerUnknown, app.state.EcoActive)
+	}
+	app.startPowerCheck()
+	if app.powerChecking {
+		t.Fatal("expected no check once the power source is unknown")
+	}
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"syscall"
+
+	"github.com/kk-code-lab/rdir/internal/clipboard"
+	"github.com/kk-code-lab/rdir/internal/procwatch"
+)
+
+// clipboardSendFlag is the hidden command line rdir runs as its own clipboard
+// command over SSH: it reads the text on stdin and passes it to the helper or
+// to the terminal with OSC 52.
+const clipboardSendFlag = "--clipboard-send"
+
+var clipboardExecutable = os.Executable
+
+// remoteClipboardCommand returns the clipboard command for SSH sessions, or
+// false when rdir runs locally and should use pbcopy, xclip and friends.
+func remoteClipboardCommand(getenv func(string) string) ([]string, bool) {
+	if getenv(clipboard.EnvSocket) == "" && !clipboard.Remote(getenv) {
+		return nil, false
+	}
+	self, err := clipboardExecutable()
+	if err != nil || self == "" {
+		return nil, false
+	}
+	return []string{self, clipboardSendFlag}, true
+}
+
+// RunClipboardSend implements rdir --clipboard-send.
+func RunClipboardSend(stdin io.Reader, stderr io.Writer) int {
+	data, err := io.ReadAll(io.LimitReader(stdin, clipboard.MaxHelperBytes+1))
+	if err == nil && len(data) > clipboard.MaxHelperBytes {
+		err = fmt.Errorf("more than %d bytes", clipboard.MaxHelperBytes)
+	}
+	if err == nil {
+		err = clipboard.CopyRemote(os.Getenv, data)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "rdir: %v\n", err)
+		return ExitError
+	}
+	return 0
+}
+
+// RunClipboardHelper implements rdir --clipboard-helper: it runs on the local
+// machine and copies whatever rdir sessions on remote machines send through
+// the forwarded socket, until interrupted.
+func RunClipboardHelper(address string, stderr io.Writer) int {
+	if address == "" {
+		address = clipboard.DefaultHelperAddress()
+	}
+	cmd, ok := detectClipboardInternal(runtime.GOOS, exec.LookPath)
+	if !ok {
+		_, _ = fmt.Fprintln(stderr, "rdir: no clipboard command found (pbcopy, xclip, wl-copy, xsel)")
+		return ExitError
+	}
+	limits, err := procwatch.Load(os.Getenv)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "rdir: %v\n", err)
+	}
+	procwatch.Set(limits)
+	listener, err := clipboard.Listen(address)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "rdir: %v\n", err)
+		return ExitError
+	}
+	logf := func(format string, args ...any) {
+		_, _ = fmt.Fprintf(stderr, "rdir clipboard helper: "+format+"\n", args...)
+	}
+	logf("listening on %s, copying with %s", address, cmd[0])
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		_ = listener.Close()
+	}()
+
+	copyLocal := func(data []byte) error {
+		c := exec.Command(cmd[0], cmd[1:]...)
+		c.Stdin = bytes.NewReader(data)
+		var out bytes.Buffer
+		c.Stdout, c.Stderr = &out, &out
+		if err := procwatch.Run(c, procwatch.Clipboard); err != nil {
+			var timeout *procwatch.TimeoutError
+			if out.Len() > 0 && !errors.As(err, &timeout) {
+				return fmt.Errorf("%s: %s", cmd[0], bytes.TrimSpace(out.Bytes()))
+			}
+			return err
+		}
+		return nil
+	}
+	if err := clipboard.Serve(listener, copyLocal, logf); err != nil {
+		logf("%v", err)
+		return ExitError
+	}
+	return 0
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/kk-code-lab/rdir/internal/clipboard"
+	"github.com/kk-code-lab/rdir/internal/procwatch"
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
+)
+
+// WriteDoctor prints which external commands rdir would use (rdir --doctor),
+// and for the ones it cannot find, why.
+func WriteDoctor(w io.Writer) error {
+	return writeDoctor(w, runtime.GOOS, os.Getenv, exec.LookPath)
+}
+
+func writeDoctor(w io.Writer, goos string, getenv func(string) string, lookPath func(string) (string, error)) error {
+	type check struct {
+		name   string
+		ok     bool
+		detail string
+	}
+	var checks []check
+
+	_, editorStatus, editorOK := explainEditorCommand(goos, getenv, lookPath)
+	checks = append(checks, check{"editor", editorOK, editorStatus})
+
+	pager := detectPagerCommand(goos, getenv("PAGER"), lookPath)
+	pagerOK := len(pager) > 0
+	pagerDetail := "no pager command"
+	if pagerOK {
+		pagerDetail = strings.Join(pager, " ")
+		if _, err := lookPath(pager[0]); err != nil {
+			pagerOK = false
+			pagerDetail = fmt.Sprintf("%s not found in PATH", pager[0])
+		}
+	}
+	checks = append(checks, check{"pager", pagerOK, pagerDetail})
+
+	clipboard, clipboardOK := detectClipboardInternal(goos, lookPath)
+	clipboardDetail := "none of pbcopy, xclip, wl-copy, xsel found in PATH"
+	if clipboardOK {
+		clipboardDetail = strings.Join(clipboard, " ")
+	}
+	if remote, ok := remoteClipboardCommand(getenv); ok {
+		clipboardOK = true
+		clipboardDetail = remoteClipboardDetail(getenv, remote)
+	}
+	checks = append(checks, check{"clipboard", clipboardOK, clipboardDetail})
+
+	shell, shellOK := detectShellCommandInternal(goos, getenv, lookPath)
+	shellDetail := "no shell found in PATH"
+	if shellOK {
+		shellDetail = strings.Join(shell, " ")
+	}
+	checks = append(checks, check{"shell", shellOK, shellDetail})
+
+	limits, limitsErr := procwatch.Load(getenv)
+	limitsDetail := limits.String()
+	if limitsErr != nil {
+		limitsDetail += " (" + limitsErr.Error() + ")"
+	}
+	checks = append(checks, check{"timeouts", true, limitsDetail})
+
+	ambiguous, ambiguousErr := statepkg.LoadAmbiguousWidth(getenv)
+	wide, needsProbe := statepkg.ResolveAmbiguousWidth(ambiguous, getenv)
+	widthsDetail := "ambiguous characters narrow"
+	switch {
+	case needsProbe:
+		widthsDetail = "ambiguous characters as the terminal reports (CJK locale; wide if it does not answer)"
+	case wide:
+		widthsDetail = "ambiguous characters wide"
+	}
+	if ambiguousErr != nil {
+		widthsDetail += " (" + ambiguousErr.Error() + ")"
+	}
+	checks = append(checks, check{"widths", true, widthsDetail})
+
+	for _, c := range checks {
+		mark := "ok"
+		if !c.ok {
+			mark = "missing"
+		}
+		if _, err := fmt.Fprintf(w, "%-10s %-8s %s\n", c.name, mark, c.detail); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remoteClipboardDetail describes how copies leave an SSH session.
+func remoteClipboardDetail(getenv func(string) string, cmd []string) string {
+	if address := getenv(clipboard.EnvSocket); address != "" {
+		return fmt.Sprintf("%s (helper at %s, OSC 52 fallback)", strings.Join(cmd, " "), address)
+	}
+	return fmt.Sprintf("%s (OSC 52; set %s for a local helper)", strings.Join(cmd, " "), clipboard.EnvSocket)
+}
@@ -0,0 +1,194 @@
+package app
+
+import (
+	"os"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
+)
+
+// Options configures an Application. The zero value is the rdir binary:
+// configuration from the environment and the terminal as the screen. The
+// other fields are for programs embedding rdir (see pkg/picker).
+type Options struct {
+	// StartPath is the directory or file to start in, instead of
+	// RDIR_START_PATH.
+	StartPath string
+	// Getenv replaces os.Getenv for rdir's settings: every RDIR_* variable
+	// read by the application, and PAGER, VISUAL, EDITOR and SHELL. The
+	// search engine's debug switches are read from the process environment.
+	Getenv func(string) string
+	// Screen replaces the terminal; it is initialized and finalized by the
+	// application.
+	Screen tcell.Screen
+	// FS replaces the local filesystem for listing, previews, the pager and
+	// search; file operations still act on the disk.
+	FS fsutil.FS
+	// ReadEntries lists directories instead of FS.
+	ReadEntries statepkg.EntryReader
+	// Accept is called with the picked paths before rdir exits with them;
+	// returning false keeps rdir running.
+	Accept func(paths []string) bool
+	// Keys binds key names such as "ctrl+o", "f2" or "x" to handlers, which
+	// run in the file list before rdir's own bindings.
+	Keys map[string]KeyHandler
+	// OnCursor is called when the directory or the entry under the cursor
+	// changes; selected is empty in an empty directory.
+	OnCursor func(dir, selected string)
+}
+
+// View is what a key handler sees of the file list.
+type View struct {
+	Dir      string
+	Selected string   // entry under the cursor, empty in an empty directory
+	Marked   []string // marked entries, in list order
+}
+
+// KeyHandler handles a bound key. done ends the session with paths as the
+// pick; done with no paths quits without one.
+type KeyHandler func(view View) (paths []string, done bool)
+
+func (o Options) getenv() func(string) string {
+	if o.Getenv != nil {
+		return o.Getenv
+	}
+	return os.Getenv
+}
+
+func (app *Application) view() View {
+	view := View{Dir: app.state.CurrentPath, Marked: app.state.MarkedPaths()}
+	if app.state.CurrentFile() != nil {
+		view.Selected = app.state.CurrentFilePath()
+	}
+	return view
+}
+
+// finishWith ends the session with paths as the pick unless Accept turns
+// them down. It reports whether the session ends.
+func (app *Application) finishWith(paths []string) bool {
+	if len(paths) > 0 && app.accept != nil && !app.accept(paths) {
+		return false
+	}
+	app.printPaths = paths
+	app.shouldQuit = true
+	return true
+}
+
+// handleBoundKey runs the handler bound to ev, if any, while the file list
+// has the keyboard. It reports whether the key was handled.
+func (app *Application) handleBoundKey(ev *tcell.EventKey) bool {
+	if len(app.keys) == 0 || !app.listHasFocus() {
+		return false
+	}
+	handler := app.keys[keyName(ev)]
+	if handler == nil {
+		return false
+	}
+	paths, done := handler(app.view())
+	if done {
+		app.logf("bound key %s finished with %d paths", keyName(ev), len(paths))
+		app.finishWith(paths)
+	}
+	return true
+}
+
+// listHasFocus reports whether keys go to the file list rather than a
+// prompt, search, dialog or overlay.
+func (app *Application) listHasFocus() bool {
+	s := app.state
+	return !s.HelpVisible && s.AuditView == nil && s.PendingConfirm == nil &&
+		s.PathChoice == nil && s.Prompt == nil && s.Rename == nil && s.Conflicts == nil &&
+		!s.TypeAheadActive && !s.FilterActive && !s.GlobalSearchActive && !s.PreviewFullScreen
+}
+
+// notifyCursor calls OnCursor when the directory or selection moved.
+func (app *Application) notifyCursor() {
+	if app.onCursor == nil {
+		return
+	}
+	view := app.view()
+	if view.Dir == app.cursorDir && view.Selected == app.cursorSelected {
+		return
+	}
+	app.cursorDir, app.cursorSelected = view.Dir, view.Selected
+	app.onCursor(view.Dir, view.Selected)
+}
+
+// keyName names a key event the way bindings are written: lower-case
+// modifiers in the order ctrl, alt, meta, shift joined with "+", then the
+// character of a printable key as typed or tcell's key name in lower case,
+// e.g. "x", "X", "alt+x", "ctrl+o", "f2", "enter".
+func keyName(ev *tcell.EventKey) string {
+	mod := ev.Modifiers()
+	var base string
+	if ev.Key() == tcell.KeyRune {
+		base = string(ev.Rune())
+		mod &^= tcell.ModShift // already in the character
+	} else {
+		name, ok := tcell.KeyNames[ev.Key()]
+		if !ok {
+			return ""
+		}
+		if rest, found := strings.CutPrefix(name, "Ctrl-"); found {
+			mod |= tcell.ModCtrl
+			name = rest
+		}
+		base = strings.ToLower(name)
+	}
+	return joinKeyName(mod, base)
+}
+
+// normalizeKeyName rewrites a binding such as "Ctrl+O" or "Alt+x" into the
+// form keyName produces, so bindings are case-insensitive except for the
+// character of a printable key.
+func normalizeKeyName(name string) string {
+	name = strings.TrimSpace(name)
+	base := name
+	var prefix string
+	if i := strings.LastIndex(name[:max(len(name)-1, 0)], "+"); i >= 0 {
+		prefix, base = name[:i], name[i+1:]
+	}
+	var mod tcell.ModMask
+	for _, part := range strings.Split(prefix, "+") {
+		switch strings.ToLower(strings.TrimSpace(part)) {
+		case "ctrl":
+			mod |= tcell.ModCtrl
+		case "alt":
+			mod |= tcell.ModAlt
+		case "meta":
+			mod |= tcell.ModMeta
+		case "shift":
+			mod |= tcell.ModShift
+		}
+	}
+	switch {
+	case len([]rune(base)) != 1:
+		base = strings.ToLower(base)
+	case mod&tcell.ModCtrl != 0:
+		// Ctrl+letter arrives as a control key named by its letter.
+		base = strings.ToLower(base)
+	default:
+		mod &^= tcell.ModShift // a printable key is matched by its character
+	}
+	return joinKeyName(mod, base)
+}
+
+func joinKeyName(mod tcell.ModMask, base string) string {
+	var parts []string
+	for _, m := range []struct {
+		mask tcell.ModMask
+		name string
+	}{
+		{tcell.ModCtrl, "ctrl"},
+		{tcell.ModAlt, "alt"},
+		{tcell.ModMeta, "meta"},
+		{tcell.ModShift, "shift"},
+	} {
+		if mod&m.mask != 0 {
+			parts = append(parts, m.name)
+		}
+	}
+	return strings.Join(append(parts, base), "+")
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestKeyNamesMatchBindings(t *testing.T) {
+	for _, tt := range []struct {
+		binding string
+		ev      *tcell.EventKey
+	}{
+		{"x", tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone)},
+		{"X", tcell.NewEventKey(tcell.KeyRune, 'X', tcell.ModShift)},
+		{"Alt+x", tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModAlt)},
+		{"ctrl+o", tcell.NewEventKey(tcell.KeyCtrlO, 0, tcell.ModNone)},
+		{"Ctrl+O", tcell.NewEventKey(tcell.KeyCtrlO, 0, tcell.ModCtrl)},
+		{"F2", tcell.NewEventKey(tcell.KeyF2, 0, tcell.ModNone)},
+		{"enter", tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)},
+		{"+", tcell.NewEventKey(tcell.KeyRune, '+', tcell.ModNone)},
+		{"alt++", tcell.NewEventKey(tcell.KeyRune, '+', tcell.ModAlt)},
+	} {
+		if got, want := keyName(tt.ev), normalizeKeyName(tt.binding); got != want {
+			t.Errorf("%s: event named %q, binding normalized to %q", tt.binding, got, want)
+		}
+	}
+	if keyName(tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone)) == normalizeKeyName("X") {
+		t.Errorf("printable keys should match their character exactly")
+	}
+}
+
+func TestBoundKeysWaitForThePrompt(t *testing.T) {
+	app := newTestApplicationWithFile(t)
+	ran := 0
+	app.keys = map[string]KeyHandler{"x": func(View) ([]string, bool) { ran++; return nil, false }}
+
+	x := tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone)
+	if !app.handleBoundKey(x) || ran != 1 {
+		t.Fatalf("expected the binding to run in the file list")
+	}
+	app.state.FilterActive = true
+	if app.handleBoundKey(x) || ran != 1 {
+		t.Fatalf("expected typing in the filter to bypass the binding")
+	}
+}
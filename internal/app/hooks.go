@@ -0,0 +1,241 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kk-code-lab/rdir/internal/procwatch"
+)
+
+// Hooks are shell commands run when rdir enters or leaves a directory and
+// when the selection settles on a new entry, e.g. to update a tmux status
+// line. They run one at a time in the background, without a terminal, and
+// see the event in RDIR_HOOK_EVENT, the directory in RDIR_HOOK_DIR, the
+// selected path in RDIR_HOOK_SELECTED and rdir's process id in RDIR_HOOK_PID.
+const (
+	EnvHookEnter       = "RDIR_HOOK_ENTER"
+	EnvHookLeave       = "RDIR_HOOK_LEAVE"
+	EnvHookSelect      = "RDIR_HOOK_SELECT"
+	EnvHookSelectDelay = "RDIR_HOOK_SELECT_DELAY"
+)
+
+const (
+	defaultHookSelectDelay = 200 * time.Millisecond
+	maxHookSelectDelay     = 10 * time.Second
+	hookQueue              = 32
+)
+
+type hookConfig struct {
+	onEnter     string
+	onLeave     string
+	onSelect    string
+	selectDelay time.Duration
+}
+
+func (c hookConfig) empty() bool {
+	return c.onEnter == "" && c.onLeave == "" && c.onSelect == ""
+}
+
+// loadHooks reads the hook commands and RDIR_HOOK_SELECT_DELAY, a duration
+// up to 10s the selection must stay put before the select hook runs. An
+// invalid delay falls back to the default and is reported.
+func loadHooks(getenv func(string) string) (hookConfig, error) {
+	cfg := hookConfig{
+		onEnter:     strings.TrimSpace(getenv(EnvHookEnter)),
+		onLeave:     strings.TrimSpace(getenv(EnvHookLeave)),
+		onSelect:    strings.TrimSpace(getenv(EnvHookSelect)),
+		selectDelay: defaultHookSelectDelay,
+	}
+	raw := strings.TrimSpace(getenv(EnvHookSelectDelay))
+	if raw == "" {
+		return cfg, nil
+	}
+	if raw == "0" {
+		cfg.selectDelay = 0
+		return cfg, nil
+	}
+	delay, err := time.ParseDuration(raw)
+	if err != nil || delay < 0 || delay > maxHookSelectDelay {
+		return cfg, fmt.Errorf("ignoring invalid %s %q (use a duration such as 200ms, at most %s)", EnvHookSelectDelay, raw, maxHookSelectDelay)
+	}
+	cfg.selectDelay = delay
+	return cfg, nil
+}
+
+// hookJob is one hook run.
+type hookJob struct {
+	event    string // "enter", "leave" or "select"
+	command  string
+	dir      string
+	selected string
+}
+
+// hookRunner fires hooks as the session moves. observe and close are called
+// from the event loop; jobs run in order on a single worker.
+type hookRunner struct {
+	cfg  hookConfig
+	run  func(hookJob)
+	logf func(string, ...interface{})
+
+	dir      string
+	selected string
+
+	mu          sync.Mutex
+	closed      bool
+	selectTimer *time.Timer
+	jobs        chan hookJob
+	done        chan struct{}
+}
+
+// newHookRunner starts the worker, or returns nil when no hook is set.
+func newHookRunner(cfg hookConfig, run func(hookJob), logf func(string, ...interface{})) *hookRunner {
+	if cfg.empty() {
+		return nil
+	}
+	r := &hookRunner{
+		cfg:  cfg,
+		run:  run,
+		logf: logf,
+		jobs: make(chan hookJob, hookQueue),
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(r.done)
+		for job := range r.jobs {
+			r.run(job)
+		}
+	}()
+	return r
+}
+
+// observe fires leave/enter when dir changed and (re)starts the select
+// delay when selected changed.
+func (r *hookRunner) observe(dir, selected string) {
+	if r == nil {
+		return
+	}
+	if dir != r.dir {
+		if r.dir != "" {
+			r.queue(hookJob{event: "leave", command: r.cfg.onLeave, dir: r.dir})
+		}
+		r.dir = dir
+		r.queue(hookJob{event: "enter", command: r.cfg.onEnter, dir: dir, selected: selected})
+	}
+	if selected == r.selected {
+		return
+	}
+	r.selected = selected
+	if r.cfg.onSelect == "" || selected == "" {
+		r.stopSelectTimer()
+		return
+	}
+	job := hookJob{event: "select", command: r.cfg.onSelect, dir: dir, selected: selected}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.selectTimer != nil {
+		r.selectTimer.Stop()
+	}
+	r.selectTimer = time.AfterFunc(r.cfg.selectDelay, func() { r.queue(job) })
+}
+
+func (r *hookRunner) stopSelectTimer() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.selectTimer != nil {
+		r.selectTimer.Stop()
+		r.selectTimer = nil
+	}
+}
+
+// queue hands job to the worker; it is dropped when the hook is unset or
+// the queue is full, so a slow hook cannot stall the UI.
+func (r *hookRunner) queue(job hookJob) {
+	if job.command == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	select {
+	case r.jobs <- job:
+	default:
+		r.logf("hook %s dropped: queue full", job.event)
+	}
+}
+
+// close fires leave for the last directory, drops a pending select and
+// waits as long as one hook may take (see procwatch.Hook) for the queued
+// hooks.
+func (r *hookRunner) close() {
+	if r == nil {
+		return
+	}
+	r.stopSelectTimer()
+	if r.dir != "" {
+		r.queue(hookJob{event: "leave", command: r.cfg.onLeave, dir: r.dir})
+	}
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.jobs)
+	}
+	r.mu.Unlock()
+	wait := procwatch.DefaultHookTimeout
+	if policy := procwatch.PolicyFor(procwatch.Hook); policy.Timeout > 0 {
+		wait = policy.Timeout + policy.Grace
+	}
+	select {
+	case <-r.done:
+	case <-time.After(wait):
+	}
+}
+
+// observeHooks reports the current directory and selection to the hooks.
+func (app *Application) observeHooks() {
+	if app.hooks == nil {
+		return
+	}
+	selected := ""
+	if app.state.CurrentFile() != nil {
+		selected = app.state.CurrentFilePath()
+	}
+	app.hooks.observe(app.state.CurrentPath, selected)
+}
+
+// runHook runs job through the platform shell with no terminal attached,
+// under the procwatch.Hook limit.
+func runHook(job hookJob, logf func(string, ...interface{})) {
+	args := hookShell(runtime.GOOS, job.command)
+	cmd := exec.Command(args[0], args[1:]...)
+	if info, err := os.Stat(job.dir); err == nil && info.IsDir() {
+		cmd.Dir = job.dir
+	}
+	cmd.Env = append(os.Environ(), hookEnv(job)...)
+	if err := procwatch.Run(cmd, procwatch.Hook); err != nil {
+		logf("hook %s %q: %v", job.event, job.command, err)
+	}
+}
+
+func hookShell(goos, command string) []string {
+	if goos == "windows" {
+		return []string{"cmd", "/c", command}
+	}
+	return []string{"/bin/sh", "-c", command}
+}
+
+func hookEnv(job hookJob) []string {
+	return []string{
+		"RDIR_HOOK_EVENT=" + job.event,
+		"RDIR_HOOK_DIR=" + job.dir,
+		"RDIR_HOOK_SELECTED=" + job.selected,
+		"RDIR_HOOK_PID=" + strconv.Itoa(os.Getpid()),
+	}
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadHooks(t *testing.T) {
+	env := map[string]string{
+		EnvHookEnter:       " tmux refresh-client -S ",
+		EnvHookSelectDelay: "50ms",
+	}
+	cfg, err := loadHooks(func(key string) string { return env[key] })
+	if err != nil {
+		t.Fatalf("loadHooks: %v", err)
+	}
+	if cfg.onEnter != "tmux refresh-client -S" || cfg.onLeave != "" || cfg.selectDelay != 50*time.Millisecond {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+
+	env[EnvHookSelectDelay] = "forever"
+	cfg, err = loadHooks(func(key string) string { return env[key] })
+	if err == nil {
+		t.Fatalf("expected an error for an invalid delay")
+	}
+	if cfg.selectDelay != defaultHookSelectDelay {
+		t.Fatalf("delay = %v, want the default", cfg.selectDelay)
+	}
+
+	if r := newHookRunner(hookConfig{}, nil, nil); r != nil {
+		t.Fatalf("expected no runner without hooks")
+	}
+}
+
+func recordingHookRunner(cfg hookConfig) (*hookRunner, <-chan hookJob) {
+	ran := make(chan hookJob, hookQueue)
+	r := newHookRunner(cfg, func(job hookJob) { ran <- job }, func(string, ...interface{}) {})
+	return r, ran
+}
+
+func nextHook(t *testing.T, ran <-chan hookJob) hookJob {
+	t.Helper()
+	select {
+	case job := <-ran:
+		return job
+	case <-time.After(2 * time.Second):
+		t.Fatalf("no hook ran")
+		return hookJob{}
+	}
+}
+
+func TestHookRunnerEnterAndLeave(t *testing.T) {
+	r, ran := recordingHookRunner(hookConfig{onEnter: "enter", onLeave: "leave"})
+
+	r.observe("/a", "/a/x")
+	r.observe("/a", "/a/y")
+	r.observe("/b", "")
+	r.close()
+
+	// close waits for the worker, so every hook has run by now.
+	var got []string
+	for len(ran) > 0 {
+		job := <-ran
+		got = append(got, job.event+" "+job.dir)
+	}
+	want := []string{"enter /a", "leave /a", "enter /b", "leave /b"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("hooks = %v, want %v", got, want)
+	}
+}
+
+func TestHookRunnerDebouncesSelection(t *testing.T) {
+	r, ran := recordingHookRunner(hookConfig{onSelect: "select", selectDelay: 30 * time.Millisecond})
+	defer r.close()
+
+	r.observe("/a", "/a/1")
+	r.observe("/a", "/a/2")
+	r.observe("/a", "/a/3")
+
+	job := nextHook(t, ran)
+	if job.event != "select" || job.selected != "/a/3" {
+		t.Fatalf("hook = %+v, want select of /a/3", job)
+	}
+	select {
+	case extra := <-ran:
+		t.Fatalf("unexpected extra hook %+v", extra)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestRunHookPassesContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/sh")
+	}
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	job := hookJob{
+		event:    "select",
+		command:  `printf '%s|%s|%s|%s' "$RDIR_HOOK_EVENT" "$RDIR_HOOK_DIR" "$RDIR_HOOK_SELECTED" "$PWD" > ` + out,
+		dir:      dir,
+		selected: filepath.Join(dir, "file"),
+	}
+	runHook(job, func(format string, args ...interface{}) { t.Errorf(format, args...) })
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("hook did not run: %v", err)
+	}
+	want := "select|" + dir + "|" + job.selected + "|" + dir
+	if string(data) != want {
+		t.Fatalf("hook saw %q, want %q", data, want)
+	}
+}
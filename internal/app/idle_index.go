@@ -0,0 +1,43 @@
+package app
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
+)
+
+// isUserInput reports whether ev comes from the user (keys, mouse, paste)
+// rather than the terminal or rdir itself.
+func isUserInput(ev tcell.Event) bool {
+	switch ev.(type) {
+	case *tcell.EventKey, *tcell.EventMouse, *tcell.EventPaste:
+		return true
+	}
+	return false
+}
+
+// idleTimer fires after d without input, or never when idle index warming
+// is off or the session only follows another one.
+func (app *Application) idleTimer(d time.Duration) <-chan time.Time {
+	if !app.state.IdleIndex.Enabled() || app.state.Following != "" {
+		return nil
+	}
+	return time.After(d)
+}
+
+// startIndexWarm warms the global search index for the current directory.
+func (app *Application) startIndexWarm() {
+	app.logf("idle: warming search index for %s", app.state.CurrentPath)
+	if _, err := app.reducer.Reduce(app.state, statepkg.IndexWarmStartAction{At: time.Now()}); err != nil {
+		app.state.ReportError(statepkg.ErrorSourceGeneral, err)
+	}
+}
+
+// cancelIndexWarm stops an unfinished warm-up as soon as the user acts, so
+// it does not compete with what they asked for.
+func (app *Application) cancelIndexWarm() {
+	if _, err := app.reducer.Reduce(app.state, statepkg.IndexWarmCancelAction{}); err != nil {
+		app.state.ReportError(statepkg.ErrorSourceGeneral, err)
+	}
+}
@@ -1,14 +1,19 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+	"github.com/kk-code-lab/rdir/internal/procwatch"
 	statepkg "github.com/kk-code-lab/rdir/internal/state"
 	textutil "github.com/kk-code-lab/rdir/internal/textutil"
 	"github.com/kk-code-lab/rdir/internal/ui/input"
@@ -18,42 +23,158 @@ import (
 
 const doubleClickThreshold = 300 * time.Millisecond
 
+// inputFrameInterval is the minimum time between frames while more input is
+// already queued (e.g. a held j/k key), so bursts coalesce into few renders.
+const inputFrameInterval = 33 * time.Millisecond
+
+// resizeSettleInterval is how long the terminal size must stay put before a
+// resize is applied, so a tiling window manager dragging a border reflows the
+// layout once instead of on every intermediate size.
+const resizeSettleInterval = 40 * time.Millisecond
+
+// NewApplication creates the application the rdir binary runs.
 func NewApplication() (*Application, error) {
-	if runtime.GOOS == "windows" {
-		_ = os.Setenv("TCELL_ALTSCREEN", "disable")
-	}
-	screen, err := tcell.NewScreen()
+	return NewApplicationWith(Options{})
+}
+
+// NewApplicationWith creates an application configured by opts.
+func NewApplicationWith(opts Options) (*Application, error) {
+	getenv := opts.getenv()
+	trace := newStartupTrace(StartupTraceEnabled(getenv))
+	trace.mark("process")
+
+	follower, followPath, err := connectFollow(getenv)
 	if err != nil {
 		return nil, err
 	}
-	if err := screen.Init(); err != nil {
-		return nil, err
-	}
-	// Parse mouse sequences so modified clicks don't leak as key events.
-	screen.EnableMouse()
 
+	// A start path (positional argument or -/--stdin-path) opens its
+	// directory, selecting the file when it names one.
 	cwd, err := GetCwd()
+	var startName string
+	start := opts.StartPath
+	if start == "" {
+		start = getenv(EnvStartPath)
+	}
+	switch {
+	case err != nil:
+	case opts.ReadEntries != nil && start != "":
+		// Another backend's paths need not exist locally; open it as a directory.
+		cwd = filepath.Clean(start)
+	default:
+		cwd, startName, err = resolveStartPath(start, cwd, fsutil.OrLocal(opts.FS).Stat)
+	}
 	if err != nil {
-		screen.Fini()
+		closeFollower(follower)
 		return nil, err
 	}
 
-	clipboardCmd, clipboardAvail := detectClipboard()
-	editorCmd, editorAvail := detectEditorCommand()
+	// Set UTF-8 as fallback encoding for maximum compatibility
+	// This ensures Polish and other Unicode characters display correctly
+	tcell.SetEncodingFallback(tcell.EncodingFallbackUTF8)
 
-	state := newInitialState(cwd, clipboardAvail, editorAvail)
+	// Widths of ambiguous characters must be settled before tcell lays out
+	// cells; only a terminal rdir opens itself is asked.
+	var probeAmbiguous func() (bool, bool)
+	if opts.Screen == nil {
+		probeAmbiguous = func() (bool, bool) {
+			defer trace.mark("ambiguous width probe")
+			return probeAmbiguousWidth(ambiguousProbeTimeout)
+		}
+	}
+	ambiguousErr := configureAmbiguousWidth(getenv, probeAmbiguous)
+
+	// An embedder's screen is left as it is on exit, like the alternate screen.
+	screen := opts.Screen
+	altScreen := true
+	if screen == nil {
+		altScreen = useAltScreen(runtime.GOOS, getenv)
+		configureAltScreen(altScreen)
+		if screen, err = tcell.NewScreen(); err != nil {
+			closeFollower(follower)
+			return nil, err
+		}
+	}
+	if err := screen.Init(); err != nil {
+		closeFollower(follower)
+		return nil, err
+	}
+	// Parse mouse sequences so modified clicks don't leak as key events.
+	screen.EnableMouse()
+	trace.mark("screen init")
+
+	// Clipboard and editor detection search PATH; they run after the first
+	// frame (finishStartup), before any key is handled.
+	state := newInitialState(cwd, false, false)
+	enterCfg, enterErr := statepkg.LoadEnterConfig(getenv)
+	state.Enter = enterCfg
+	wrapCfg, wrapErr := statepkg.LoadWrapConfig(getenv)
+	state.Wrap = wrapCfg
+	truncateCfg, truncateErr := statepkg.LoadTruncateConfig(getenv)
+	state.Truncate = truncateCfg
+	readingWidth, readingErr := statepkg.LoadReadingWidth(getenv)
+	state.ReadingWidth = readingWidth
+	escTimeout, escTimeoutErr := statepkg.LoadEscapeTimeout(getenv)
+	state.EscapeTimeout = escTimeout
+	minContrast, minContrastErr := statepkg.LoadMinContrast(getenv)
+	state.MinContrast = minContrast
+	background, backgroundErr := statepkg.DetectBackground(getenv)
+	state.Background = background
+	copyRef, copyRefErr := statepkg.LoadCopyRefTemplate(getenv)
+	state.CopyRefTemplate = copyRef
+	state.QuickfixFile = statepkg.DefaultQuickfixFile(getenv)
+	state.MoveReferences = statepkg.MoveReferencesEnabled(getenv)
+	state.ReferencesFile = statepkg.DefaultReferencesFile()
+	state.PrivilegedHelper = statepkg.PrivilegedHelperCommand(getenv)
+	state.AuditFile = statepkg.DefaultAuditFile(getenv)
+	previewDefaults, previewDefaultsErr := statepkg.LoadPreviewDefaults(getenv)
+	state.PreviewDefaults = previewDefaults
+	formatters, formattersErr := statepkg.LoadExternalFormatters(getenv, exec.LookPath)
+	statepkg.SetExternalFormatters(formatters)
+	state.FoldDiacritics = statepkg.FoldDiacriticsEnabled(getenv)
+	filterScoring, filterScoringErr := statepkg.LoadFilterScoring(getenv)
+	state.FilterScoring = filterScoring
+	slowPaths, slowPathsErr := statepkg.LoadSlowPathConfig(getenv)
+	state.SlowPaths = slowPaths
+	ecoMode, ecoErr := statepkg.LoadEcoMode(getenv)
+	state.Eco = ecoMode
+	state.EcoActive = ecoMode == statepkg.EcoOn
+	idleIndex, idleIndexErr := statepkg.LoadIdleIndexConfig(getenv)
+	state.IdleIndex = idleIndex
+	cmdLimits, cmdLimitsErr := procwatch.Load(getenv)
+	procwatch.Set(cmdLimits)
+	hooks, hooksErr := loadHooks(getenv)
+	pathMappings, pathMapErr := statepkg.LoadPathMappings(getenv)
+	state.PathContext = fsutil.PathContext{
+		Windows:   runtime.GOOS == "windows",
+		WSLDistro: getenv("WSL_DISTRO_NAME"),
+		Mappings:  pathMappings,
+	}
+	state.ReportError(statepkg.ErrorSourceGeneral, errors.Join(enterErr, wrapErr, truncateErr, readingErr, escTimeoutErr, minContrastErr, backgroundErr, ambiguousErr, copyRefErr, previewDefaultsErr, formattersErr, filterScoringErr, slowPathsErr, ecoErr, idleIndexErr, pathMapErr, hooksErr, cmdLimitsErr))
 	state.DirectoryLoader = statepkg.NewAsyncDirectoryLoader()
+	state.ReadEntries = opts.ReadEntries
+	state.FS = opts.FS
 	state.PreviewLoader = statepkg.NewAsyncPreviewLoader()
+	trace.mark("config")
 	w, h := screen.Size()
 	state.ScreenWidth = w
 	state.ScreenHeight = h
 
+	// Background work (preview timers, loaders) may still dispatch after
+	// Close; closed drops those actions instead of sending to a dead loop.
 	actionCh := make(chan statepkg.Action, 10)
+	closed := make(chan struct{})
 	state.SetDispatch(func(action statepkg.Action) {
 		select {
+		case <-closed:
 		case actionCh <- action:
 		default:
-			go func() { actionCh <- action }()
+			go func() {
+				select {
+				case actionCh <- action:
+				case <-closed:
+				}
+			}()
 		}
 	})
 
@@ -67,7 +188,7 @@ func NewApplication() (*Application, error) {
 	if commit == "" {
 		commit = "unknown"
 	}
-	if debugLoggingEnabled {
+	if getenv(EnvDebugLog) == "1" {
 		path := filepath.Join(os.TempDir(), "rdir_debug.log")
 		if f, err := os.Create(path); err == nil {
 			debugFile = f
@@ -82,12 +203,14 @@ func NewApplication() (*Application, error) {
 
 	if err := statepkg.LoadDirectory(state); err != nil {
 		screen.Fini()
+		closeFollower(follower)
 		if debugFile != nil {
 			_ = debugFile.Close()
 		}
 		return nil, err
 	}
 	state.RefreshParentEntries()
+	trace.mark("directory")
 
 	app := &Application{
 		screen:         screen,
@@ -96,12 +219,33 @@ func NewApplication() (*Application, error) {
 		renderer:       renderer,
 		input:          inputHandler,
 		actionCh:       actionCh,
-		debugLog:       debugLogger,
+		closed:         closed,
 		debugLogFile:   debugFile,
 		currentPath:    cwd,
-		clipboardCmd:   clipboardCmd,
-		clipboardAvail: clipboardAvail,
-		editorCmd:      editorCmd,
+		env:            getenv,
+		stagingFile:    statepkg.DefaultStagingFile(getenv),
+		pagerStateFile: statepkg.DefaultPagerStateFile(getenv),
+		altScreen:      altScreen,
+		startup:        trace,
+	}
+	if debugLogger != nil {
+		app.debugLog = debugLogger
+	}
+	app.share.follower = follower
+	app.accept = opts.Accept
+	app.onCursor = opts.OnCursor
+	if len(opts.Keys) > 0 {
+		app.keys = make(map[string]KeyHandler, len(opts.Keys))
+		for name, handler := range opts.Keys {
+			app.keys[normalizeKeyName(name)] = handler
+		}
+	}
+	app.hooks = newHookRunner(hooks, func(job hookJob) { runHook(job, app.logf) }, app.logf)
+	state.Following = followPath
+	if ShareEnabled(getenv) && follower == nil {
+		if err := app.startSharing(); err != nil {
+			state.ReportError(statepkg.ErrorSourceGeneral, errors.Join(state.Errors.Err(statepkg.ErrorSourceGeneral), err))
+		}
 	}
 
 	inputHandler.SetState(state)
@@ -110,10 +254,30 @@ func NewApplication() (*Application, error) {
 		ts := time.Now().Format("2006-01-02 15:04:05.000000 -0700 MST")
 		debugLogger.Printf("%s session start pid=%d goos=%s goarch=%s cwd=%s commit=%s", ts, os.Getpid(), runtime.GOOS, runtime.GOARCH, cwd, commit)
 	}
-	_ = reducer.GeneratePreview(state)
+	if startName != "" {
+		if err := reducer.RevealEntry(state, startName); err != nil {
+			state.ReportError(statepkg.ErrorSourceGeneral, errors.Join(state.Errors.Err(statepkg.ErrorSourceGeneral), err))
+		}
+	} else {
+		_ = reducer.GeneratePreview(state)
+	}
+	trace.mark("preview start")
 	return app, nil
 }
 
+// finishStartup does the start-up work the first frame does not need: PATH
+// lookups for the clipboard and editor, and loading the shared staging file.
+func (app *Application) finishStartup() {
+	clipboardCmd, clipboardAvail := detectClipboard(app.getenv)
+	editorCmd, editorStatus, editorAvail := detectEditorCommand(app.getenv)
+	app.clipboardCmd, app.clipboardAvail = clipboardCmd, clipboardAvail
+	app.editorCmd = editorCmd
+	app.state.ClipboardAvailable = clipboardAvail
+	app.state.EditorAvailable = editorAvail
+	app.state.EditorStatus = editorStatus
+	app.syncStagingFromFile()
+}
+
 func newInitialState(cwd string, clipboardAvail, editorAvail bool) *statepkg.AppState {
 	return &statepkg.AppState{
 		CurrentPath:        cwd,
@@ -132,22 +296,40 @@ func newInitialState(cwd string, clipboardAvail, editorAvail bool) *statepkg.App
 }
 
 func (app *Application) Run() {
-	defer app.screen.Fini()
+	defer app.finishScreen()
 	defer app.logf("session end")
 
 	app.renderer.Render(app.state)
-	renderPending := false
+	app.startup.mark("first frame")
+	app.finishStartup()
+	app.startup.mark("deferred init")
+	app.publishShare()
+	app.observeHooks()
+	app.notifyCursor()
+	app.startFollowing()
+	// Redraw with the staged marks and any errors the deferred work found.
+	renderPending := true
+
+	// In auto eco mode the power source is checked now and then, as long as
+	// the platform can tell. The checks run off the loop: pmset can be slow.
+	var powerCh <-chan time.Time
+	if app.state.Eco == statepkg.EcoAuto {
+		app.startPowerCheck()
+		powerTicker := time.NewTicker(powerCheckInterval)
+		defer powerTicker.Stop()
+		powerCh = powerTicker.C
+	}
 
 	app.startEventPoller()
 	defer app.stopEventPoller()
 
-	const animationInterval = 50 * time.Millisecond
 	var animationTimer *time.Timer
 	var animationCh <-chan time.Time
 
 	startAnimation := func() {
+		interval := app.animationInterval()
 		if animationTimer == nil {
-			animationTimer = time.NewTimer(animationInterval)
+			animationTimer = time.NewTimer(interval)
 		} else {
 			if !animationTimer.Stop() {
 				select {
@@ -155,7 +337,7 @@ func (app *Application) Run() {
 				default:
 				}
 			}
-			animationTimer.Reset(animationInterval)
+			animationTimer.Reset(interval)
 		}
 		animationCh = animationTimer.C
 	}
@@ -173,10 +355,32 @@ func (app *Application) Run() {
 		animationCh = nil
 	}
 
+	var lastRender time.Time
+	var frameCh <-chan time.Time
+
+	// Resizes wait until the size settles; only the last one is applied.
+	var pendingResize *tcell.EventResize
+	var resizeCh <-chan time.Time
+
+	// The global search index is warmed once input has paused for
+	// IdleIndex.After, once per pause: the timer re-arms on the next input.
+	idleCh := app.idleTimer(app.state.IdleIndex.After)
+
 	for !app.shouldQuit {
 		if renderPending {
-			app.renderer.Render(app.state)
-			renderPending = false
+			if wait := inputFrameInterval - time.Since(lastRender); app.inputQueued() && wait > 0 {
+				// More keys are waiting; skip this frame but make sure one is
+				// drawn once the burst slows down.
+				if frameCh == nil {
+					frameCh = time.After(wait)
+				}
+			} else {
+				app.renderer.Render(app.state)
+				app.publishShare()
+				lastRender = time.Now()
+				renderPending = app.syncListWidth()
+				frameCh = nil
+			}
 		}
 
 		if app.shouldAnimate() {
@@ -193,11 +397,37 @@ func (app *Application) Run() {
 				continue
 			}
 			app.logf("recv event: %s", formatTcellEvent(ev))
+			if resize, ok := ev.(*tcell.EventResize); ok {
+				pendingResize = resize
+				resizeCh = time.After(resizeSettleInterval)
+				continue
+			}
+			if isUserInput(ev) {
+				app.cancelIndexWarm()
+				idleCh = app.idleTimer(app.state.IdleIndex.After)
+			}
 			if ev != nil && app.handleEvent(ev) {
 				renderPending = true
 			}
+		case <-idleCh:
+			app.startIndexWarm()
+			idleCh = nil
+		case <-resizeCh:
+			resizeCh = nil
+			if pendingResize != nil && app.handleEvent(pendingResize) {
+				renderPending = true
+			}
+			pendingResize = nil
 		case <-animationCh:
 			renderPending = true
+		case <-powerCh:
+			if app.powerUnknown {
+				powerCh = nil
+				break
+			}
+			app.startPowerCheck()
+		case <-frameCh:
+			frameCh = nil
 		case action := <-app.actionCh:
 			app.logf("action: %T", action)
 			if app.handleAction(action) {
@@ -208,14 +438,71 @@ func (app *Application) Run() {
 		if app.processActions() {
 			renderPending = true
 		}
+		if app.flushDeferredPreview() {
+			renderPending = true
+		}
+		app.observeHooks()
+		app.notifyCursor()
 	}
 
 	stopAnimation()
 }
 
+// syncListWidth hands the list panel width of the frame just drawn to the
+// state, which lays the grid out by it, and reports whether that changed.
+func (app *Application) syncListWidth() bool {
+	layout, ok := app.renderer.LastLayout()
+	if !ok || layout.MainPanelWidth == app.state.ListWidth {
+		return false
+	}
+	if _, err := app.reducer.Reduce(app.state, statepkg.ListWidthAction{Width: layout.MainPanelWidth}); err != nil {
+		app.state.ReportError(statepkg.ErrorSourceGeneral, err)
+	}
+	return app.state.ListGridEnabled
+}
+
+// inputQueued reports whether terminal events are waiting to be handled.
+func (app *Application) inputQueued() bool {
+	return app.eventChan != nil && len(app.eventChan) > 0
+}
+
+// flushDeferredPreview starts the preview load held back during an input
+// burst once the queue has drained. Stale tokens are ignored by the reducer,
+// so previews for entries the selection merely passed over never load.
+func (app *Application) flushDeferredPreview() bool {
+	if app.deferredPreview == nil || app.inputQueued() {
+		return false
+	}
+	action := *app.deferredPreview
+	app.deferredPreview = nil
+	return app.handleAction(action)
+}
+
+// finishScreen tears down the terminal UI. Inline (no alternate screen) runs
+// park the cursor below the last drawn row so the final listing stays in the
+// scrollback and the shell prompt starts on a fresh line.
+func (app *Application) finishScreen() {
+	app.screenDone = true
+	if app.altScreen {
+		app.screen.Fini()
+		return
+	}
+	_, h := app.screen.Size()
+	app.screen.ShowCursor(0, h-1)
+	app.screen.Show()
+	app.screen.Fini()
+	_, _ = fmt.Fprintln(os.Stderr)
+}
+
 func (app *Application) handleEvent(ev tcell.Event) bool {
 	switch ev := ev.(type) {
 	case *tcell.EventKey:
+		if app.state.Following != "" && !followerAllows(ev) {
+			return false
+		}
+		if app.handleBoundKey(ev) {
+			return true
+		}
 		if !app.input.ProcessEvent(ev) {
 			app.shouldQuit = true
 		}
@@ -224,6 +511,9 @@ func (app *Application) handleEvent(ev tcell.Event) bool {
 			app.shouldQuit = true
 		}
 	case *tcell.EventMouse:
+		if app.state.Following != "" {
+			return false
+		}
 		if !app.handleMouse(ev) {
 			app.shouldQuit = true
 		}
@@ -252,9 +542,7 @@ func (app *Application) reinitScreen() error {
 		app.screen.Fini()
 	}
 
-	if runtime.GOOS == "windows" {
-		_ = os.Setenv("TCELL_ALTSCREEN", "disable")
-	}
+	configureAltScreen(app.altScreen)
 
 	scr, err := tcell.NewScreen()
 	if err != nil {
@@ -289,7 +577,21 @@ func (app *Application) reinitScreen() error {
 	return nil
 }
 
-// handleMouse maps primary-clicks to selection and navigation.
+// sideButtonDirection maps mouse button 4 to back and 5 to forward. tcell
+// reports them from the Windows console; xterm-style terminals do not send
+// them in a form it decodes.
+func sideButtonDirection(pressed tcell.ButtonMask) string {
+	switch {
+	case pressed&tcell.Button4 != 0:
+		return "back"
+	case pressed&tcell.Button5 != 0:
+		return "forward"
+	}
+	return ""
+}
+
+// handleMouse maps primary-clicks to selection and navigation, and the side
+// buttons to history.
 func (app *Application) handleMouse(ev *tcell.EventMouse) bool {
 	if app.state == nil {
 		return true
@@ -318,6 +620,16 @@ func (app *Application) handleMouse(ev *tcell.EventMouse) bool {
 		return true
 	}
 
+	// Side buttons go back and forward like in GUI file managers; only the
+	// press counts.
+	pressed := buttons &^ app.lastMouseButtons
+	if direction := sideButtonDirection(pressed); direction != "" {
+		if action := input.HistoryAction(app.state, direction); action != nil {
+			app.actionCh <- action
+		}
+		return true
+	}
+
 	if buttons&tcell.Button1 == 0 {
 		return true
 	}
@@ -346,7 +658,8 @@ func (app *Application) handleMouse(ev *tcell.EventMouse) bool {
 		sidebarWidth = layout.SidebarWidth
 	}
 
-	// Sidebar click: jump to parent directory (any row).
+	// Sidebar click: open the sibling directory clicked, or go up to the
+	// parent with the clicked entry selected.
 	if sidebarWidth > 0 && x < sidebarWidth {
 		if app.handleSidebarClick(y) {
 			return true
@@ -377,7 +690,7 @@ func (app *Application) handleMouse(ev *tcell.EventMouse) bool {
 	if app.state.FilterActive || app.state.GlobalSearchActive {
 		listStartY = 2
 	}
-	bottomLimit := app.state.ScreenHeight - 2 // leave room for status line
+	bottomLimit := app.state.ScreenHeight - 2 - app.state.StagingPanelRows() // leave room for staging panel and status line
 	if y < listStartY || y >= bottomLimit {
 		return true
 	}
@@ -400,6 +713,13 @@ func (app *Application) handleMouse(ev *tcell.EventMouse) bool {
 	}
 
 	displayIdx := app.state.ScrollOffset + row
+	if grid := app.state.ListGrid(); grid.Columns > 1 {
+		column := (x - mainStart) / grid.ColumnWidth
+		if column >= grid.Columns || row >= grid.Rows {
+			return true
+		}
+		displayIdx += column * grid.Rows
+	}
 	displayFiles := app.state.DisplayFiles()
 	if displayIdx < 0 || displayIdx >= len(displayFiles) {
 		return true
@@ -432,40 +752,7 @@ func (app *Application) handleSidebarClick(y int) bool {
 		return false
 	}
 
-	h := app.state.ScreenHeight
-	maxRows := h - 2
-	if maxRows < 1 {
-		maxRows = 1
-	}
-
-	currentName := filepath.Base(app.state.CurrentPath)
-	currentIdx := 0
-	foundCurrent := false
-	for idx, entry := range entries {
-		if entry.Name == currentName {
-			currentIdx = idx
-			foundCurrent = true
-			break
-		}
-	}
-	if !foundCurrent {
-		currentIdx = 0
-	}
-
-	startIdx := 0
-	if len(entries) > maxRows {
-		startIdx = currentIdx - maxRows/2
-		if startIdx < 0 {
-			startIdx = 0
-		}
-		if startIdx > len(entries)-maxRows {
-			startIdx = len(entries) - maxRows
-		}
-	}
-	endIdx := len(entries)
-	if endIdx-startIdx > maxRows {
-		endIdx = startIdx + maxRows
-	}
+	startIdx, endIdx, _ := app.state.ParentPaneWindow(app.state.ScreenHeight - 2)
 
 	row := y - 1 // sidebar starts at y=1
 	if row < 0 || row >= endIdx-startIdx {
@@ -474,7 +761,7 @@ func (app *Application) handleSidebarClick(y int) bool {
 
 	_ = app.registerClick(fmt.Sprintf("sidebar-%d", row))
 
-	app.actionCh <- statepkg.GoUpAction{}
+	app.actionCh <- statepkg.ParentPaneSelectAction{Index: startIdx + row}
 	return true
 }
 
@@ -607,6 +894,57 @@ func (app *Application) processActions() bool {
 	}
 }
 
+const (
+	animationInterval    = 50 * time.Millisecond
+	ecoAnimationInterval = 250 * time.Millisecond
+	powerCheckInterval   = time.Minute
+)
+
+// animationInterval is the spinner frame interval; eco mode slows it down.
+func (app *Application) animationInterval() time.Duration {
+	if app.state != nil && app.state.EcoActive {
+		return ecoAnimationInterval
+	}
+	return animationInterval
+}
+
+var onBattery = fsutil.OnBattery
+
+// startPowerCheck finds out in the background whether the machine runs on
+// battery, for auto eco mode, and sends the answer as a PowerSourceAction.
+// Only one check runs at a time.
+func (app *Application) startPowerCheck() {
+	if app.powerChecking || app.powerUnknown {
+		return
+	}
+	app.powerChecking = true
+	actions, closed := app.actionCh, app.closed
+	go func() {
+		battery, known := onBattery()
+		select {
+		case actions <- statepkg.PowerSourceAction{OnBattery: battery, Known: known}:
+		case <-closed:
+		}
+	}()
+}
+
+// handlePowerSource ends a power source check. An unknown source stops the
+// checks; an unchanged one needs no redraw.
+func (app *Application) handlePowerSource(a statepkg.PowerSourceAction) bool {
+	app.powerChecking = false
+	if !a.Known {
+		app.powerUnknown = true
+		return false
+	}
+	if a.OnBattery == app.state.OnBattery {
+		return false
+	}
+	if _, err := app.reducer.Reduce(app.state, a); err != nil {
+		app.state.ReportError(statepkg.ErrorSourceGeneral, err)
+	}
+	return true
+}
+
 func (app *Application) shouldAnimate() bool {
 	if app.state == nil {
 		return false
@@ -635,6 +973,12 @@ func (app *Application) handleAction(action statepkg.Action) bool {
 		app.currentPath = app.state.CurrentPath
 		app.shouldQuit = true
 		return false
+	case statepkg.PreviewLoadStartAction:
+		if app.inputQueued() {
+			start := action.(statepkg.PreviewLoadStartAction)
+			app.deferredPreview = &start
+			return false
+		}
 	}
 
 	return app.handleAppAction(action)
@@ -645,6 +989,9 @@ func (app *Application) handleAppAction(action statepkg.Action) bool {
 	case statepkg.YankPathAction:
 		app.logf("handleAppAction YankPathAction")
 		return app.handleClipboard()
+	case statepkg.PathChoiceSelectAction:
+		app.logf("handleAppAction PathChoiceSelectAction")
+		return app.handlePathChoice(action.(statepkg.PathChoiceSelectAction).Index)
 	case statepkg.RightArrowAction:
 		app.logf("handleAppAction RightArrowAction")
 		return app.handleRightArrow()
@@ -658,19 +1005,100 @@ func (app *Application) handleAppAction(action statepkg.Action) bool {
 	case statepkg.OpenShellAction:
 		app.logf("handleAppAction OpenShellAction")
 		return app.handleOpenShell()
+	case statepkg.StageCutAction, statepkg.StageCopyAction, statepkg.ClearStagingAction:
+		app.logf("handleAppAction %T", action)
+		return app.handleStagingChange(action)
+	case statepkg.PasteStagedAction:
+		app.logf("handleAppAction PasteStagedAction")
+		return app.handlePasteStaged(action.(statepkg.PasteStagedAction))
+	case statepkg.RenameEntryAction:
+		app.logf("handleAppAction RenameEntryAction")
+		return app.handleRenameEntry(action.(statepkg.RenameEntryAction))
+	case statepkg.NormalizeLineEndingsAction:
+		if a := action.(statepkg.NormalizeLineEndingsAction); a.Path != "" {
+			app.logf("handleAppAction NormalizeLineEndingsAction path=%s target=%s", a.Path, a.Target)
+			return app.handleNormalizeLineEndings(a)
+		}
+	case statepkg.PrivilegedReadAction:
+		if a := action.(statepkg.PrivilegedReadAction); a.Path != "" {
+			app.logf("handleAppAction PrivilegedReadAction path=%s", a.Path)
+			return app.handlePrivilegedRead(a.Path)
+		}
+	case statepkg.ExtractArchivesAction:
+		a := action.(statepkg.ExtractArchivesAction)
+		app.logf("handleAppAction ExtractArchivesAction count=%d dest=%s", len(a.Targets), a.Dest)
+		return app.startArchiveJob(a, func(ctx context.Context, send func(statepkg.Action)) statepkg.Action {
+			return statepkg.RunExtraction(ctx, a, send)
+		})
+	case statepkg.CompressAction:
+		a := action.(statepkg.CompressAction)
+		app.logf("handleAppAction CompressAction count=%d archive=%s", len(a.Sources), a.Archive)
+		return app.startArchiveJob(a, func(ctx context.Context, send func(statepkg.Action)) statepkg.Action {
+			return statepkg.RunCompression(ctx, a, send)
+		})
+	case statepkg.ArchiveCancelAction:
+		if app.archiveCancel != nil {
+			app.archiveCancel()
+		}
+	case statepkg.ArchiveDoneAction:
+		a := action.(statepkg.ArchiveDoneAction)
+		app.logf("handleAppAction ArchiveDoneAction dest=%s files=%d err=%v", a.Dest, a.Files, a.Err)
+		app.archiveCancel = nil
+		app.operations += len(a.Audit)
+		app.recordAudit(a.Audit...)
+	case statepkg.PasteDoneAction:
+		return app.handlePasteDone(action.(statepkg.PasteDoneAction))
+	case statepkg.PowerSourceAction:
+		return app.handlePowerSource(action.(statepkg.PowerSourceAction))
+	case statepkg.ReferenceScanDoneAction:
+		a := action.(statepkg.ReferenceScanDoneAction)
+		app.logf("handleAppAction ReferenceScanDoneAction hits=%d canceled=%v", len(a.Report.Hits), a.Report.Canceled)
+		app.archiveCancel = nil
+		// Like ConfirmAcceptAction: the paste or rename runs through the app.
+		next := app.state.FinishReferenceScan(a)
+		if next == nil {
+			return true
+		}
+		return app.handleAppAction(next)
+	case statepkg.ConflictConfirmAction:
+		// Like ConfirmAcceptAction: the paste or extraction runs through the app.
+		resumed := app.state.FinishConflicts()
+		if resumed == nil {
+			return true
+		}
+		app.logf("handleAppAction ConflictConfirmAction %T", resumed)
+		return app.handleAppAction(resumed)
+	case statepkg.ErrorRetryAction:
+		retry := app.state.RetryError()
+		if retry == nil {
+			return true
+		}
+		app.logf("handleAppAction ErrorRetryAction %T", retry)
+		return app.handleAppAction(retry)
+	case statepkg.ConfirmAcceptAction:
+		// Replay the confirmed action through the app so side-effect actions work too.
+		pending := app.state.PendingConfirm
+		app.state.PendingConfirm = nil
+		if pending == nil || pending.Action == nil {
+			return true
+		}
+		app.logf("handleAppAction ConfirmAcceptAction %T", pending.Action)
+		return app.handleAppAction(pending.Action)
 	}
 
 	if _, err := app.reducer.Reduce(app.state, action); err != nil {
-		app.state.LastError = err
+		app.state.ReportError(statepkg.ErrorSourceGeneral, err)
 	}
 	return true
 }
 
 func (app *Application) runPreviewPager() (err error) {
-	view, err := pagerui.NewPreviewPager(app.state, app.editorCmd, app.reducer, app.clipboardCmd)
+	memory := app.loadPagerMemory()
+	view, err := app.newPreviewPager(memory)
 	if err != nil {
 		return err
 	}
+	defer app.savePagerMemory(memory)
 
 	app.stopEventPoller()
 	app.logf("runPreviewPager: suspending screen")
@@ -695,5 +1123,39 @@ func (app *Application) runPreviewPager() (err error) {
 		}
 	}()
 
-	return view.Run()
+	// Picking a recent file ends the session; the next one shows that file,
+	// and the browser's own preview is rebuilt once the pager is left.
+	for {
+		if err := view.Run(); err != nil {
+			return err
+		}
+		next := view.SwitchTo()
+		if next == "" {
+			break
+		}
+		app.logf("runPreviewPager: switching to %s", next)
+		if err := app.reducer.ShowPagerFile(app.state, next); err != nil {
+			return err
+		}
+		if view, err = app.newPreviewPager(memory); err != nil {
+			return err
+		}
+	}
+	if app.state.PreviewPath != app.state.CurrentFilePath() {
+		if err := app.reducer.EnsurePreviewCurrent(app.state); err != nil {
+			return err
+		}
+		app.state.PreviewFullScreen = true
+	}
+	return nil
+}
+
+func (app *Application) newPreviewPager(memory *statepkg.PagerMemory) (*pagerui.PreviewPager, error) {
+	view, err := pagerui.NewPreviewPager(app.state, app.editorCmd, app.reducer, app.clipboardCmd)
+	if err != nil {
+		return nil, err
+	}
+	view.SetAltScreen(app.altScreen)
+	view.SetMemory(memory)
+	return view, nil
 }
@@ -196,3 +196,78 @@ func TestHandleMouseIgnoresDragWhileHeld(t *testing.T) {
 		t.Fatalf("expected selection action from second click")
 	}
 }
+
+func TestHandleMouseSidebarSelectsParentEntry(t *testing.T) {
+	scr := tcell.NewSimulationScreen("")
+	if err := scr.Init(); err != nil {
+		t.Fatalf("init simulation screen: %v", err)
+	}
+	defer scr.Fini()
+	scr.SetSize(160, 40)
+
+	renderer := renderui.NewRenderer(scr)
+	state := &statepkg.AppState{
+		CurrentPath:   "/tmp/beta",
+		Files:         []statepkg.FileEntry{{Name: "a.txt", FullPath: "/tmp/beta/a.txt"}},
+		ParentEntries: []statepkg.FileEntry{{Name: "alpha", IsDir: true}, {Name: "beta", IsDir: true}, {Name: "notes.txt"}},
+		ScreenWidth:   160,
+		ScreenHeight:  40,
+	}
+	renderer.Render(state)
+	layout, ok := renderer.LastLayout()
+	if !ok || layout.SidebarWidth == 0 {
+		t.Fatalf("expected a sidebar in the test layout")
+	}
+
+	actionCh := make(chan statepkg.Action, 2)
+	app := &Application{renderer: renderer, state: state, actionCh: actionCh}
+
+	// Rows start at y=1; the third row is notes.txt.
+	if !app.handleMouse(tcell.NewEventMouse(1, 3, tcell.Button1, tcell.ModNone)) {
+		t.Fatalf("handleMouse returned false")
+	}
+	select {
+	case act := <-actionCh:
+		if sel, ok := act.(statepkg.ParentPaneSelectAction); !ok || sel.Index != 2 {
+			t.Fatalf("expected ParentPaneSelectAction{2}, got %#v", act)
+		}
+	default:
+		t.Fatalf("expected an action for the sidebar click")
+	}
+}
+
+func TestHandleMouseSideButtonsGoThroughHistory(t *testing.T) {
+	scr := tcell.NewSimulationScreen("")
+	if err := scr.Init(); err != nil {
+		t.Fatalf("init simulation screen: %v", err)
+	}
+	defer scr.Fini()
+	scr.SetSize(80, 20)
+
+	state := &statepkg.AppState{CurrentPath: "/tmp", ScreenWidth: 80, ScreenHeight: 20}
+	actionCh := make(chan statepkg.Action, 2)
+	app := &Application{renderer: renderui.NewRenderer(scr), state: state, actionCh: actionCh}
+
+	app.handleMouse(tcell.NewEventMouse(5, 5, tcell.Button4, tcell.ModNone))
+	if got := <-actionCh; got != (statepkg.GoToHistoryAction{Direction: "back"}) {
+		t.Fatalf("button 4: got %#v, want back", got)
+	}
+	// Holding the button does not repeat.
+	app.handleMouse(tcell.NewEventMouse(5, 5, tcell.Button4, tcell.ModNone))
+	app.handleMouse(tcell.NewEventMouse(5, 5, tcell.ButtonNone, tcell.ModNone))
+	select {
+	case got := <-actionCh:
+		t.Fatalf("expected one action per press, got %#v", got)
+	default:
+	}
+
+	state.GlobalSearchActive = true
+	app.handleMouse(tcell.NewEventMouse(5, 5, tcell.Button4, tcell.ModNone))
+	if got := <-actionCh; got != (statepkg.GlobalSearchClearAction{}) {
+		t.Fatalf("button 4 in global search: got %#v, want GlobalSearchClearAction", got)
+	}
+	app.handleMouse(tcell.NewEventMouse(5, 5, tcell.Button5, tcell.ModNone))
+	if got := <-actionCh; got != (statepkg.GoToHistoryAction{Direction: "forward"}) {
+		t.Fatalf("button 5: got %#v, want forward", got)
+	}
+}
@@ -3,6 +3,9 @@ package app
 import (
 	"path/filepath"
 	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
 )
 
 func TestBuildBreadcrumbPath(t *testing.T) {
@@ -38,3 +41,26 @@ func TestBuildBreadcrumbPath(t *testing.T) {
 		}
 	}
 }
+
+func TestPreviewLoadStartDeferredWhileInputQueued(t *testing.T) {
+	app := &Application{
+		state:     &statepkg.AppState{},
+		reducer:   statepkg.NewStateReducer(),
+		eventChan: make(chan tcell.Event, 2),
+	}
+	app.eventChan <- tcell.NewEventKey(tcell.KeyDown, 0, 0)
+
+	app.handleAction(statepkg.PreviewLoadStartAction{Token: 7})
+	if app.deferredPreview == nil || app.deferredPreview.Token != 7 {
+		t.Fatalf("expected preview start to be deferred, got %+v", app.deferredPreview)
+	}
+	if app.flushDeferredPreview() || app.deferredPreview == nil {
+		t.Fatal("deferred preview must wait until the input queue drains")
+	}
+
+	<-app.eventChan
+	app.flushDeferredPreview()
+	if app.deferredPreview != nil {
+		t.Fatal("expected deferred preview to be flushed once input drained")
+	}
+}
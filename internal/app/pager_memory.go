@@ -0,0 +1,29 @@
+package app
+
+import (
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
+)
+
+// loadPagerMemory reads the remembered per-file pager state fresh for every
+// pager session, so views left in other rdir instances are picked up too.
+// Without a state file the state is only kept for this run, so switching
+// between recent files still returns to where each was left.
+func (app *Application) loadPagerMemory() *statepkg.PagerMemory {
+	if app.pagerStateFile == "" {
+		if app.sessionPagerMemory == nil {
+			app.sessionPagerMemory, _ = statepkg.LoadPagerMemory("")
+		}
+		return app.sessionPagerMemory
+	}
+	memory, err := statepkg.LoadPagerMemory(app.pagerStateFile)
+	if err != nil {
+		app.logf("pager state load failed: %v", err)
+	}
+	return memory
+}
+
+func (app *Application) savePagerMemory(memory *statepkg.PagerMemory) {
+	if err := memory.Save(); err != nil {
+		app.state.ReportError(statepkg.ErrorSourceGeneral, err)
+	}
+}
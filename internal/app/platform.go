@@ -1,17 +1,42 @@
 package app
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"unicode"
+
+	"golang.org/x/term"
 )
 
 var pagerLookPath = exec.LookPath
 
-func detectClipboard() ([]string, bool) {
+// childStdin returns the stdin for a shell, pager or editor: rdir's own when
+// it is a terminal, otherwise the terminal reopened (/dev/tty, CONIN$ on
+// Windows), as after `echo path | rdir -` stdin is a spent pipe. close
+// releases what was opened.
+func childStdin() (stdin *os.File, close func()) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return os.Stdin, func() {}
+	}
+	name := "/dev/tty"
+	if runtime.GOOS == "windows" {
+		name = "CONIN$"
+	}
+	tty, err := os.OpenFile(name, os.O_RDWR, 0)
+	if err != nil {
+		return os.Stdin, func() {}
+	}
+	return tty, func() { _ = tty.Close() }
+}
+
+func detectClipboard(getenv func(string) string) ([]string, bool) {
+	if cmd, ok := remoteClipboardCommand(getenv); ok {
+		return cmd, true
+	}
 	return detectClipboardInternal(runtime.GOOS, exec.LookPath)
 }
 
@@ -49,22 +74,37 @@ func detectClipboardInternal(goos string, lookPath func(string) (string, error))
 	return nil, false
 }
 
-func detectEditorCommand() ([]string, bool) {
-	return detectEditorCommandInternal(runtime.GOOS, os.Getenv, exec.LookPath)
+func detectEditorCommand(getenv func(string) string) ([]string, string, bool) {
+	return explainEditorCommand(runtime.GOOS, getenv, exec.LookPath)
 }
 
 func detectEditorCommandInternal(goos string, getenv func(string) string, lookPath func(string) (string, error)) ([]string, bool) {
-	candidates := []string{getenv("VISUAL"), getenv("EDITOR")}
+	args, _, ok := explainEditorCommand(goos, getenv, lookPath)
+	return args, ok
+}
 
-	for _, candidate := range candidates {
-		args := parseEditorCommand(candidate)
+// explainEditorCommand resolves $VISUAL, then $EDITOR, then the platform
+// defaults. The status says where the command came from, or why none of the
+// candidates could be used, e.g. `$VISUAL "subl -w": subl not found in PATH`.
+func explainEditorCommand(goos string, getenv func(string) string, lookPath func(string) (string, error)) ([]string, string, bool) {
+	var problems []string
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		value := getenv(name)
+		args := parseEditorCommand(value)
 		if len(args) == 0 {
+			if strings.TrimSpace(value) != "" {
+				problems = append(problems, fmt.Sprintf("$%s %q: no command", name, value))
+			}
 			continue
 		}
 		if resolved, ok := resolveEditorExecutableWithLookup(args[0], lookPath); ok {
 			args[0] = resolved
-			return args, true
+			return args, fmt.Sprintf("$%s (%s)", name, strings.Join(args, " ")), true
 		}
+		problems = append(problems, fmt.Sprintf("$%s %q: %s not found in PATH", name, strings.TrimSpace(value), args[0]))
+	}
+	if len(problems) == 0 {
+		problems = append(problems, "$VISUAL and $EDITOR are unset")
 	}
 
 	var defaults [][]string
@@ -81,21 +121,24 @@ func detectEditorCommandInternal(goos string, getenv func(string) string, lookPa
 		}
 	}
 
+	names := make([]string, 0, len(defaults))
 	for _, def := range defaults {
 		if len(def) == 0 {
 			continue
 		}
 		if resolved, ok := resolveEditorExecutableWithLookup(def[0], lookPath); ok {
 			args := append([]string{resolved}, def[1:]...)
-			return args, true
+			return args, fmt.Sprintf("default (%s)", strings.Join(args, " ")), true
 		}
+		names = append(names, def[0])
 	}
+	problems = append(problems, fmt.Sprintf("none of %s found in PATH", strings.Join(names, ", ")))
 
-	return nil, false
+	return nil, strings.Join(problems, "; "), false
 }
 
-func detectShellCommand() ([]string, bool) {
-	return detectShellCommandInternal(runtime.GOOS, os.Getenv, exec.LookPath)
+func detectShellCommand(getenv func(string) string) ([]string, bool) {
+	return detectShellCommandInternal(runtime.GOOS, getenv, exec.LookPath)
 }
 
 func detectShellCommandInternal(goos string, getenv func(string) string, lookPath func(string) (string, error)) ([]string, bool) {
@@ -183,9 +226,27 @@ func parseEditorCommand(cmd string) []string {
 	var current strings.Builder
 	inSingle := false
 	inDouble := false
+	escaped := false
 
 	for _, r := range cmd {
+		if escaped {
+			escaped = false
+			if r == '"' || r == '\'' || unicode.IsSpace(r) {
+				current.WriteRune(r)
+				continue
+			}
+			current.WriteRune('\\')
+		}
 		switch r {
+		case '\\':
+			// A backslash escapes a quote or a space outside single quotes;
+			// before anything else it is kept, so Windows paths survive.
+			if !inSingle {
+				escaped = true
+			} else {
+				current.WriteRune(r)
+			}
+			continue
 		case '\'':
 			if inDouble {
 				current.WriteRune(r)
@@ -212,6 +273,9 @@ func parseEditorCommand(cmd string) []string {
 		}
 	}
 
+	if escaped {
+		current.WriteRune('\\')
+	}
 	if current.Len() > 0 {
 		args = append(args, current.String())
 	}
@@ -288,3 +352,31 @@ func defaultPagerCommand(goos string, lookPath func(string) (string, error)) []s
 	}
 	return []string{"less"}
 }
+
+func detectOpenCommand() ([]string, bool) {
+	return detectOpenCommandInternal(runtime.GOOS, exec.LookPath)
+}
+
+// detectOpenCommandInternal returns the command that opens a file with the
+// OS-registered handler; the file path is appended as the last argument.
+func detectOpenCommandInternal(goos string, lookPath func(string) (string, error)) ([]string, bool) {
+	switch strings.ToLower(goos) {
+	case "windows":
+		// The empty argument is the window title expected by start.
+		return []string{"cmd", "/c", "start", ""}, true
+	case "darwin":
+		return []string{"open"}, true
+	}
+	for _, candidate := range []string{"xdg-open", "gio", "open"} {
+		if lookPath == nil {
+			return []string{candidate}, true
+		}
+		if resolved, err := lookPath(candidate); err == nil && resolved != "" {
+			if candidate == "gio" {
+				return []string{resolved, "open"}, true
+			}
+			return []string{resolved}, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,174 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kk-code-lab/rdir/internal/share"
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
+)
+
+// EnvShare lets other rdir instances follow this session read-only (same as
+// --share). Experimental.
+const EnvShare = "RDIR_SHARE"
+
+// EnvFollow makes rdir mirror a shared session instead of taking input (set
+// by --follow): a sharing process id, its socket path, or "auto" for the
+// only session sharing.
+const EnvFollow = "RDIR_FOLLOW"
+
+// ShareEnabled reports whether session sharing was requested.
+func ShareEnabled(getenv func(string) string) bool {
+	switch strings.ToLower(strings.TrimSpace(getenv(EnvShare))) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
+// followTarget returns the --follow argument, "" meaning "the only session
+// sharing", and whether following was requested at all.
+func followTarget(getenv func(string) string) (string, bool) {
+	target := strings.TrimSpace(getenv(EnvFollow))
+	switch strings.ToLower(target) {
+	case "", "0", "false", "no":
+		return "", false
+	case "1", "true", "yes", "auto":
+		return "", true
+	}
+	return target, true
+}
+
+// sharing holds the session-sharing side of an Application: the server
+// when sharing, the connection when following.
+type sharing struct {
+	server     *share.Server
+	lastShared statepkg.FollowSnapshot
+
+	follower   *share.Follower
+	followDone chan struct{}
+	followWG   sync.WaitGroup
+}
+
+// connectFollow resolves and dials the session to follow, before the
+// screen is set up so a failure is reported on the plain terminal.
+func connectFollow(getenv func(string) string) (*share.Follower, string, error) {
+	target, ok := followTarget(getenv)
+	if !ok {
+		return nil, "", nil
+	}
+	path, err := share.Resolve(target)
+	if err != nil {
+		return nil, "", err
+	}
+	follower, err := share.Dial(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot follow %s: %w", path, err)
+	}
+	return follower, path, nil
+}
+
+func closeFollower(follower *share.Follower) {
+	if follower != nil {
+		_ = follower.Close()
+	}
+}
+
+// startSharing opens this session's socket. Failing to share is reported
+// but does not stop rdir.
+func (app *Application) startSharing() error {
+	server, err := share.Listen(share.SocketPath(os.Getpid()))
+	if err != nil {
+		return fmt.Errorf("session sharing: %w", err)
+	}
+	app.share.server = server
+	app.state.SharingPath = server.Path()
+	return nil
+}
+
+// publishShare sends the current location to followers when it changed.
+func (app *Application) publishShare() {
+	if app.share.server == nil {
+		return
+	}
+	snap := app.state.FollowSnapshot()
+	if snap == app.share.lastShared {
+		return
+	}
+	app.share.lastShared = snap
+	line, err := json.Marshal(snap)
+	if err != nil {
+		return
+	}
+	app.share.server.Publish(line)
+}
+
+// startFollowing mirrors snapshots from the followed session until it ends
+// or rdir exits.
+func (app *Application) startFollowing() {
+	follower := app.share.follower
+	if follower == nil {
+		return
+	}
+	app.share.followDone = make(chan struct{})
+	done := app.share.followDone
+	send := func(action statepkg.Action) bool {
+		select {
+		case app.actionCh <- action:
+			return true
+		case <-done:
+			return false
+		}
+	}
+	app.share.followWG.Add(1)
+	go func() {
+		defer app.share.followWG.Done()
+		for {
+			line, err := follower.Next()
+			if err != nil {
+				send(statepkg.FollowEndedAction{})
+				return
+			}
+			var snap statepkg.FollowSnapshot
+			if json.Unmarshal(line, &snap) != nil {
+				continue
+			}
+			if !send(statepkg.FollowSnapshotAction{Snapshot: snap}) {
+				return
+			}
+		}
+	}()
+}
+
+// stopSharing closes the socket or the followed connection. It returns
+// once the follow goroutine can no longer send actions.
+func (app *Application) stopSharing() {
+	if app.share.server != nil {
+		_ = app.share.server.Close()
+		app.share.server = nil
+	}
+	if app.share.follower != nil {
+		if app.share.followDone != nil {
+			close(app.share.followDone)
+		}
+		_ = app.share.follower.Close()
+		app.share.followWG.Wait()
+		app.share.follower = nil
+	}
+}
+
+// followerAllows reports whether a key may act while following: only the
+// ones that leave rdir.
+func followerAllows(ev *tcell.EventKey) bool {
+	switch ev.Key() {
+	case tcell.KeyCtrlC:
+		return true
+	case tcell.KeyRune:
+		return ev.Rune() == 'q' || ev.Rune() == 'Q'
+	}
+	return false
+}
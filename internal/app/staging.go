@@ -0,0 +1,188 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
+)
+
+// syncStagingFromFile pulls in entries staged by other rdir instances so the
+// panel and paste operate on the shared set.
+func (app *Application) syncStagingFromFile() {
+	if app.stagingFile == "" {
+		return
+	}
+	area, err := statepkg.LoadStagingFile(app.stagingFile)
+	if err != nil {
+		app.state.ReportError(statepkg.ErrorSourceFiles, err)
+		return
+	}
+	if _, err := app.reducer.Reduce(app.state, statepkg.StagingSyncAction{Area: area}); err != nil {
+		app.state.ReportError(statepkg.ErrorSourceFiles, err)
+	}
+}
+
+// updateStaging runs change on the staging area shared through the staging
+// file, holding its lock from reading the file until the area change leaves
+// behind is written back. Without a staging file change runs on the area of
+// this process alone.
+func (app *Application) updateStaging(change func()) {
+	if app.stagingFile == "" {
+		change()
+		return
+	}
+	_, err := statepkg.UpdateStagingFile(app.stagingFile, func(area statepkg.StagingArea) statepkg.StagingArea {
+		if _, err := app.reducer.Reduce(app.state, statepkg.StagingSyncAction{Area: area}); err != nil {
+			app.state.ReportError(statepkg.ErrorSourceFiles, err)
+		}
+		change()
+		return app.state.Staging
+	})
+	if err != nil {
+		app.state.ReportError(statepkg.ErrorSourceFiles, err)
+	}
+}
+
+// handleStagingChange applies a stage/unstage/clear action on top of the
+// shared staging file and writes the result back.
+func (app *Application) handleStagingChange(action statepkg.Action) bool {
+	app.updateStaging(func() {
+		if _, err := app.reducer.Reduce(app.state, action); err != nil {
+			app.state.ReportError(statepkg.ErrorSourceFiles, err)
+		}
+	})
+	return true
+}
+
+func (app *Application) handlePasteStaged(action statepkg.PasteStagedAction) bool {
+	app.syncStagingFromFile()
+	if app.state.Staging.Empty() {
+		return true
+	}
+	if err := app.state.RequireLocalDisk("paste"); err != nil {
+		app.state.ReportError(statepkg.ErrorSourceFiles, err)
+		return true
+	}
+	if app.state.ConfirmProtectedPaste(action) {
+		return true
+	}
+	if !action.Confirmed && !action.ReferencesChecked {
+		if scan := app.state.PasteReferenceScan(); scan != nil {
+			return app.startReferenceScan(*scan)
+		}
+	}
+
+	if action.Resolutions == nil && app.state.ResolvePasteConflicts() {
+		return true
+	}
+
+	job := statepkg.PasteJobAction{
+		Area:           app.state.Staging,
+		DestDir:        app.state.CurrentPath,
+		Resolutions:    action.Resolutions,
+		StreamsChecked: action.StreamsChecked,
+	}
+	return app.startArchiveJob(job, func(ctx context.Context, send func(statepkg.Action)) statepkg.Action {
+		return statepkg.RunPaste(ctx, job, send)
+	})
+}
+
+// handlePasteDone ends a paste job. Entries that moved are dropped from the
+// staging area as it is by now, which other instances may have changed
+// while the paste ran. A job that stopped to ask about streams pasted
+// nothing, so only its question is shown.
+func (app *Application) handlePasteDone(action statepkg.PasteDoneAction) bool {
+	if action.StreamLoss != nil {
+		app.logf("paste staged stopped for stream=%s of %s", action.StreamLoss.Stream.Label(), action.StreamLoss.Path)
+		app.archiveCancel = nil
+		if _, err := app.reducer.Reduce(app.state, action); err != nil {
+			app.state.ReportError(statepkg.ErrorSourceFiles, err)
+		}
+		return true
+	}
+	outcome := action.Result
+	app.logf("paste staged pasted=%d skipped=%d moved=%d canceled=%v err=%v", outcome.Pasted, outcome.Skipped, len(outcome.Moved), outcome.Canceled, outcome.Err)
+	app.archiveCancel = nil
+	app.operations += outcome.Pasted
+	app.recordAudit(outcome.Audit...)
+
+	app.updateStaging(func() {
+		if _, err := app.reducer.Reduce(app.state, statepkg.StagingSyncAction{Area: outcome.Unstage(app.state.Staging)}); err != nil {
+			app.state.ReportError(statepkg.ErrorSourceFiles, err)
+		}
+	})
+	if _, err := app.reducer.Reduce(app.state, action); err != nil {
+		app.state.ReportError(statepkg.ErrorSourceFiles, err)
+	}
+	return true
+}
+
+// handleRenameEntry renames an entry edited inline. Staged entries follow the
+// rename, so the shared staging file is refreshed first and written back.
+func (app *Application) handleRenameEntry(action statepkg.RenameEntryAction) bool {
+	if !action.ReferencesChecked {
+		if scan := app.state.RenameReferenceScan(action); scan != nil {
+			return app.startReferenceScan(*scan)
+		}
+	}
+	app.updateStaging(func() {
+		err := statepkg.RenameEntry(action.From, action.To)
+		app.logf("rename from=%s to=%s err=%v", action.From, action.To, err)
+		if err == nil {
+			app.operations++
+		}
+		app.recordAudit(statepkg.NewAuditEntry("rename", action.From, action.To, err))
+
+		result := statepkg.RenameResultAction{From: action.From, To: action.To, Err: err}
+		if _, err := app.reducer.Reduce(app.state, result); err != nil {
+			app.state.ReportError(statepkg.ErrorSourceFiles, err)
+		}
+	})
+	return true
+}
+
+// startReferenceScan looks for mentions of the entries of scan in the
+// background; the move or rename carries on from ReferenceScanDoneAction.
+func (app *Application) startReferenceScan(scan statepkg.ReferenceScan) bool {
+	return app.startArchiveJob(statepkg.ReferenceScanAction{Scan: scan}, func(ctx context.Context, _ func(statepkg.Action)) statepkg.Action {
+		return statepkg.RunReferenceScan(ctx, scan)
+	})
+}
+
+// startArchiveJob records action (ExtractArchivesAction, CompressAction,
+// PasteJobAction or ReferenceScanAction) as the running job and calls run in the background.
+// Progress updates are dropped while the action queue is full; the final
+// result always arrives.
+func (app *Application) startArchiveJob(action statepkg.Action, run func(ctx context.Context, send func(statepkg.Action)) statepkg.Action) bool {
+	if app.archiveCancel != nil {
+		app.state.ReportError(statepkg.ErrorSourceFiles, fmt.Errorf("%s is still running", app.state.ArchiveJob.Verb))
+		return true
+	}
+	if _, err := app.reducer.Reduce(app.state, action); err != nil {
+		app.state.ReportError(statepkg.ErrorSourceFiles, err)
+		return true
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	app.archiveCancel = cancel
+	actions := app.actionCh
+	go func() {
+		defer cancel()
+		send := func(a statepkg.Action) {
+			select {
+			case actions <- a:
+			default:
+			}
+		}
+		actions <- run(ctx, send)
+	}()
+	return true
+}
+
+// recordAudit appends file operations to the audit log.
+func (app *Application) recordAudit(entries ...statepkg.AuditEntry) {
+	if err := statepkg.AppendAuditLog(app.state.AuditFile, entries...); err != nil {
+		app.logf("audit log: %v", err)
+		app.state.ReportError(statepkg.ErrorSourceFiles, fmt.Errorf("audit log: %w", err))
+	}
+}
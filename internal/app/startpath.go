@@ -0,0 +1,58 @@
+package app
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnvStartPath is the directory or file rdir starts in when
+// Options.StartPath (a positional argument or -/--stdin-path) is empty. A
+// file starts in its parent with the file selected.
+const EnvStartPath = "RDIR_START_PATH"
+
+// ReadStartPath returns the first non-empty line of r, for
+// `echo /some/path | rdir -`.
+func ReadStartPath(r io.Reader) (string, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			return line, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", errors.New("no path on standard input")
+}
+
+// resolveStartPath turns path into the directory to open and the name to
+// select there; an empty path starts in cwd with the default selection. stat
+// looks the path up in the filesystem being browsed.
+func resolveStartPath(path, cwd string, stat func(string) (os.FileInfo, error)) (dir, name string, err error) {
+	if path == "" {
+		return cwd, "", nil
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		if home, homeErr := os.UserHomeDir(); homeErr == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cwd, path)
+	}
+	path = filepath.Clean(path)
+
+	info, err := stat(path)
+	if err != nil {
+		return "", "", fmt.Errorf("start path: %w", err)
+	}
+	if info.IsDir() {
+		return path, "", nil
+	}
+	return filepath.Dir(path), filepath.Base(path), nil
+}
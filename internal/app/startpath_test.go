@@ -0,0 +1,59 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadStartPathSkipsBlankLines(t *testing.T) {
+	got, err := ReadStartPath(strings.NewReader("\n  \n /tmp/some dir \r\n/ignored\n"))
+	if err != nil {
+		t.Fatalf("ReadStartPath: %v", err)
+	}
+	if got != "/tmp/some dir" {
+		t.Fatalf("path = %q, want %q", got, "/tmp/some dir")
+	}
+
+	if _, err := ReadStartPath(strings.NewReader("\n\n")); err == nil {
+		t.Fatalf("expected an error for empty input")
+	}
+}
+
+func TestResolveStartPath(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "sub")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(sub, "notes.txt")
+	if err := os.WriteFile(file, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path     string
+		wantDir  string
+		wantName string
+	}{
+		{"", root, ""},
+		{sub, sub, ""},
+		{"sub", sub, ""},
+		{file, sub, "notes.txt"},
+		{"sub/../sub/notes.txt", sub, "notes.txt"},
+	}
+	for _, tt := range tests {
+		dir, name, err := resolveStartPath(tt.path, root, os.Stat)
+		if err != nil {
+			t.Fatalf("resolveStartPath(%q): %v", tt.path, err)
+		}
+		if dir != tt.wantDir || name != tt.wantName {
+			t.Fatalf("resolveStartPath(%q) = (%q, %q), want (%q, %q)", tt.path, dir, name, tt.wantDir, tt.wantName)
+		}
+	}
+
+	if _, _, err := resolveStartPath("missing", root, os.Stat); err == nil {
+		t.Fatalf("expected an error for a missing path")
+	}
+}
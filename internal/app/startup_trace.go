@@ -0,0 +1,74 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// EnvTraceStartup prints how long each start-up phase took to stderr on exit
+// (same as --trace-startup).
+const EnvTraceStartup = "RDIR_TRACE_STARTUP"
+
+// processStart stands in for the process start time: package variables are
+// initialized before main runs.
+var processStart = time.Now()
+
+// StartupTraceEnabled reports whether the start-up trace was requested.
+func StartupTraceEnabled(getenv func(string) string) bool {
+	switch strings.ToLower(strings.TrimSpace(getenv(EnvTraceStartup))) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
+// startupTrace records the duration of each start-up phase. A nil trace
+// records nothing, so call sites need no checks.
+type startupTrace struct {
+	last  time.Time
+	marks []startupMark
+}
+
+type startupMark struct {
+	phase string
+	took  time.Duration
+}
+
+func newStartupTrace(enabled bool) *startupTrace {
+	if !enabled {
+		return nil
+	}
+	return &startupTrace{last: processStart}
+}
+
+// mark ends the current phase, which started at the previous mark.
+func (t *startupTrace) mark(phase string) {
+	if t == nil {
+		return
+	}
+	now := time.Now()
+	t.marks = append(t.marks, startupMark{phase: phase, took: now.Sub(t.last)})
+	t.last = now
+}
+
+func (t *startupTrace) write(w io.Writer) error {
+	if t == nil {
+		return nil
+	}
+	var total time.Duration
+	for _, m := range t.marks {
+		total += m.took
+		if _, err := fmt.Fprintf(w, "startup %-14s %8s %8s\n", m.phase, m.took.Round(time.Microsecond), total.Round(time.Microsecond)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteStartupTrace prints the start-up phases with their own and cumulative
+// durations, when the trace is enabled.
+func (app *Application) WriteStartupTrace(w io.Writer) error {
+	return app.startup.write(w)
+}
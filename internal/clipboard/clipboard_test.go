@@ -0,0 +1,117 @@
+package clipboard
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestOSC52EncodesBase64(t *testing.T) {
+	if got, want := OSC52([]byte("/tmp/a b")), "\x1b]52;c;L3RtcC9hIGI=\a"; got != want {
+		t.Fatalf("OSC52 = %q, want %q", got, want)
+	}
+}
+
+func TestSendDeliversToHelper(t *testing.T) {
+	address := filepath.Join(t.TempDir(), "clipboard.sock")
+	listener, err := Listen(address)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+
+	var mu sync.Mutex
+	var copied []string
+	go func() {
+		_ = Serve(listener, func(data []byte) error {
+			if string(data) == "fail" {
+				return errors.New("clipboard busy")
+			}
+			mu.Lock()
+			copied = append(copied, string(data))
+			mu.Unlock()
+			return nil
+		}, nil)
+	}()
+
+	if err := Send(address, []byte("/srv/data/report.csv")); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	mu.Lock()
+	got := strings.Join(copied, "|")
+	mu.Unlock()
+	if got != "/srv/data/report.csv" {
+		t.Fatalf("helper copied %q", got)
+	}
+
+	err = Send(address, []byte("fail"))
+	if err == nil || !strings.Contains(err.Error(), "clipboard busy") {
+		t.Fatalf("expected helper error to reach the sender, got %v", err)
+	}
+}
+
+func TestListenRefusesRunningHelper(t *testing.T) {
+	address := filepath.Join(t.TempDir(), "clipboard.sock")
+	listener, err := Listen(address)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+	if second, err := Listen(address); err == nil {
+		_ = second.Close()
+		t.Fatalf("expected a second helper on the same socket to fail")
+	}
+}
+
+func TestListenRefusesNonLoopbackTCP(t *testing.T) {
+	for _, address := range []string{":7522", "0.0.0.0:7522", "192.0.2.1:7522"} {
+		if listener, err := Listen(address); err == nil {
+			_ = listener.Close()
+			t.Errorf("expected Listen(%q) to be refused", address)
+		}
+	}
+	listener, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Skipf("tcp unavailable: %v", err)
+	}
+	_ = listener.Close()
+}
+
+func TestServeRefusesClientsPastLimit(t *testing.T) {
+	address := filepath.Join(t.TempDir(), "clipboard.sock")
+	listener, err := Listen(address)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+
+	release := make(chan struct{})
+	started := make(chan struct{}, MaxHelperConns)
+	go func() {
+		_ = Serve(listener, func([]byte) error {
+			started <- struct{}{}
+			<-release
+			return nil
+		}, nil)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < MaxHelperConns; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = Send(address, []byte("x"))
+		}()
+	}
+	for i := 0; i < MaxHelperConns; i++ {
+		<-started
+	}
+	err = Send(address, []byte("y"))
+	close(release)
+	wg.Wait()
+	if err == nil {
+		t.Fatalf("expected a copy past %d in flight to be refused", MaxHelperConns)
+	}
+}
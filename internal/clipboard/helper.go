@@ -0,0 +1,207 @@
+package clipboard
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EnvSocket is where rdir on a remote machine sends copied text: the remote
+// end of a socket forwarded to rdir --clipboard-helper, e.g.
+// "/tmp/rdir-clipboard.sock" or "127.0.0.1:7522".
+const EnvSocket = "RDIR_CLIPBOARD_SOCKET"
+
+// MaxHelperBytes bounds one copy accepted by the helper.
+const MaxHelperBytes = 128 << 20
+
+// MaxHelperConns bounds the copies the helper reads at once, and with
+// MaxHelperBytes the memory they hold; further clients are told it is busy.
+const MaxHelperConns = 4
+
+// The protocol is one copy per connection: the client writes the text and
+// closes its side, the helper answers "ok" or "error: <reason>" on one line.
+const (
+	replyOK    = "ok"
+	replyError = "error: "
+)
+
+const sendTimeout = 10 * time.Second
+
+// DefaultHelperAddress is the Unix socket rdir --clipboard-helper listens on
+// when no address is given.
+func DefaultHelperAddress() string {
+	dir, err := os.UserCacheDir()
+	if err != nil || dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "rdir", "clipboard.sock")
+}
+
+// network returns "unix" for socket paths and "tcp" for host:port.
+func network(address string) string {
+	if strings.ContainsAny(address, `/\`) {
+		return "unix"
+	}
+	return "tcp"
+}
+
+// Listen opens the helper's socket. A stale Unix socket left by a previous
+// helper is replaced; the new one is only accessible to the current user.
+// TCP addresses must be on the loopback interface, as anyone who can connect
+// can write to the clipboard.
+func Listen(address string) (net.Listener, error) {
+	if network(address) == "tcp" {
+		if err := checkLoopback(address); err != nil {
+			return nil, err
+		}
+		return net.Listen("tcp", address)
+	}
+	if err := os.MkdirAll(filepath.Dir(address), 0o700); err != nil {
+		return nil, err
+	}
+	if conn, err := net.DialTimeout("unix", address, time.Second); err == nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("a clipboard helper is already listening on %s", address)
+	}
+	_ = os.Remove(address)
+	listener, err := net.Listen("unix", address)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(address, 0o600); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// checkLoopback refuses TCP addresses other machines could reach.
+func checkLoopback(address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("%s is not a loopback address; listen on 127.0.0.1 and forward it with ssh -R", address)
+}
+
+// Serve answers copy requests until the listener is closed, handing each
+// text to copy (which runs the local clipboard command). logf reports each
+// request; it may be nil. Past MaxHelperConns copies in flight, new
+// clients are answered "busy" without being read.
+func Serve(listener net.Listener, copy func([]byte) error, logf func(string, ...any)) error {
+	slots := make(chan struct{}, MaxHelperConns)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		select {
+		case slots <- struct{}{}:
+		default:
+			go refuseBusy(conn, logf)
+			continue
+		}
+		go func() {
+			defer func() { <-slots }()
+			defer func() { _ = conn.Close() }()
+			err := handle(conn, copy)
+			if logf != nil {
+				if err != nil {
+					logf("copy failed: %v", err)
+				} else {
+					logf("copied")
+				}
+			}
+		}()
+	}
+}
+
+func refuseBusy(conn net.Conn, logf func(string, ...any)) {
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(time.Second))
+	_, _ = io.WriteString(conn, replyError+"busy\n")
+	if logf != nil {
+		logf("copy refused: %d copies in progress", MaxHelperConns)
+	}
+}
+
+func handle(conn net.Conn, copy func([]byte) error) error {
+	_ = conn.SetDeadline(time.Now().Add(sendTimeout))
+	data, err := io.ReadAll(io.LimitReader(conn, MaxHelperBytes+1))
+	if err == nil && len(data) > MaxHelperBytes {
+		err = fmt.Errorf("more than %d bytes", MaxHelperBytes)
+	}
+	if err == nil {
+		err = copy(data)
+	}
+	reply := replyOK
+	if err != nil {
+		reply = replyError + err.Error()
+	}
+	_, _ = io.WriteString(conn, reply+"\n")
+	return err
+}
+
+// Send hands data to the helper at address and waits for its answer.
+func Send(address string, data []byte) error {
+	conn, err := net.DialTimeout(network(address), address, sendTimeout)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(sendTimeout))
+	if _, err := conn.Write(data); err != nil {
+		return err
+	}
+	if closer, ok := conn.(interface{ CloseWrite() error }); ok {
+		if err := closer.CloseWrite(); err != nil {
+			return err
+		}
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("clipboard helper did not answer: %w", err)
+	}
+	reply = strings.TrimSpace(reply)
+	if reply != replyOK {
+		return fmt.Errorf("clipboard helper: %s", strings.TrimPrefix(reply, replyError))
+	}
+	return nil
+}
+
+// Remote reports whether rdir runs in an SSH session, where local clipboard
+// commands would reach the remote machine's clipboard, if any.
+func Remote(getenv func(string) string) bool {
+	return getenv("SSH_TTY") != "" || getenv("SSH_CONNECTION") != ""
+}
+
+// CopyRemote sends data to the helper when RDIR_CLIPBOARD_SOCKET is set,
+// and falls back to OSC 52 when that is unset or unreachable.
+func CopyRemote(getenv func(string) string, data []byte) error {
+	if address := getenv(EnvSocket); address != "" {
+		err := Send(address, data)
+		if err == nil {
+			return nil
+		}
+		if oscErr := WriteOSC52(data); oscErr != nil {
+			return fmt.Errorf("clipboard helper at %s: %v; %v", address, err, oscErr)
+		}
+		return nil
+	}
+	return WriteOSC52(data)
+}
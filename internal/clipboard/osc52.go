@@ -0,0 +1,44 @@
+// Package clipboard gets copied text to the user's clipboard when rdir runs
+// on a remote machine: through a helper on the local machine reached over a
+// forwarded socket, or with the OSC 52 terminal escape sequence.
+package clipboard
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+)
+
+// MaxOSC52Bytes bounds what is sent with OSC 52; terminals drop or truncate
+// larger sequences, usually without telling anyone.
+const MaxOSC52Bytes = 1 << 20
+
+// OSC52 returns the escape sequence that asks the terminal to put data on
+// the system clipboard. tmux passes it on with its default set-clipboard
+// setting.
+func OSC52(data []byte) string {
+	return "\x1b]52;c;" + base64.StdEncoding.EncodeToString(data) + "\a"
+}
+
+// WriteOSC52 sends data to the controlling terminal with OSC 52.
+func WriteOSC52(data []byte) error {
+	if len(data) > MaxOSC52Bytes {
+		return fmt.Errorf("%d bytes is too much for OSC 52 (limit %d); use rdir --clipboard-helper", len(data), MaxOSC52Bytes)
+	}
+	tty, err := openTerminal()
+	if err != nil {
+		return fmt.Errorf("OSC 52: %w", err)
+	}
+	defer func() { _ = tty.Close() }()
+	_, err = io.WriteString(tty, OSC52(data))
+	return err
+}
+
+func openTerminal() (*os.File, error) {
+	if runtime.GOOS == "windows" {
+		return os.OpenFile("CONOUT$", os.O_WRONLY, 0)
+	}
+	return os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+}
@@ -0,0 +1,577 @@
+package fs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ExtractCollision says what extraction does with a file that already
+// exists at the destination.
+type ExtractCollision int
+
+const (
+	ExtractSkip ExtractCollision = iota
+	ExtractOverwrite
+	ExtractRename
+	ExtractKeepNewer
+)
+
+// String describes the policy.
+func (c ExtractCollision) String() string {
+	switch c {
+	case ExtractOverwrite:
+		return "overwrite existing"
+	case ExtractRename:
+		return "rename on conflict"
+	case ExtractKeepNewer:
+		return "keep newer"
+	default:
+		return "skip existing"
+	}
+}
+
+// archiveSuffixes are the supported archive extensions, longest first so
+// ".tar.gz" wins over ".gz"-less ".tar" checks.
+var archiveSuffixes = []string{".tar.bz2", ".tar.gz", ".tbz2", ".tgz", ".tbz", ".tar", ".zip", ".jar"}
+
+// ArchiveStem returns name without its archive extension ("site.tar.gz" ->
+// "site"); ok is false when name is not a supported archive.
+func ArchiveStem(name string) (string, bool) {
+	lower := strings.ToLower(name)
+	for _, suffix := range archiveSuffixes {
+		if strings.HasSuffix(lower, suffix) && len(name) > len(suffix) {
+			return name[:len(name)-len(suffix)], true
+		}
+	}
+	return "", false
+}
+
+// ArchiveProgress reports one entry extracted from or added to an archive.
+type ArchiveProgress struct {
+	Files   int    // entries handled so far
+	Total   int    // entries in the archive, 0 when unknown (tar streams)
+	Current string // entry name
+}
+
+// ArchiveFormatSuffixes are the extensions CreateArchive can write, in the
+// order the compress prompt cycles through them.
+var ArchiveFormatSuffixes = []string{".zip", ".tar.gz"}
+
+// CreateResult counts what CreateArchive did.
+type CreateResult struct {
+	Files   int // files, directories and links added
+	Skipped int // entries left out by the skip filter
+}
+
+// ExtractResult counts what ExtractArchive did.
+type ExtractResult struct {
+	Files   int // files, directories and links written
+	Skipped int // existing files kept, and entry types that are not extracted
+	Renamed int // entries written under a new name
+	Unsafe  int // entries refused for escaping the destination
+}
+
+// archiveEntry is one member of a zip or tar archive.
+type archiveEntry struct {
+	name    string
+	mode    os.FileMode
+	modTime time.Time
+	link    string // symlink target
+	open    func() (io.ReadCloser, error)
+}
+
+// ExtractArchive unpacks the zip or tar archive src into dest, creating it
+// as needed. Entries that would land outside dest (absolute paths, "..",
+// paths through a symlink, symlinks pointing out of dest or through another
+// symlink) are refused;
+// hard links and device files are skipped. progress is called after every
+// entry and may be nil.
+func ExtractArchive(ctx context.Context, src, dest string, collision ExtractCollision, progress func(ArchiveProgress)) (ExtractResult, error) {
+	var result ExtractResult
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		return result, err
+	}
+	x := &extractor{dest: dest, collision: collision, result: &result, links: make(map[string]bool), through: make(map[string]bool)}
+	report := func(p ArchiveProgress) {
+		if progress != nil {
+			progress(p)
+		}
+	}
+
+	lower := strings.ToLower(src)
+	if strings.HasSuffix(lower, ".zip") || strings.HasSuffix(lower, ".jar") {
+		archive, err := zip.OpenReader(src)
+		if err != nil {
+			return result, err
+		}
+		defer func() { _ = archive.Close() }()
+		for i, f := range archive.File {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+			entry := archiveEntry{name: f.Name, mode: f.Mode(), modTime: f.Modified, open: f.Open}
+			if f.Mode()&os.ModeSymlink != 0 {
+				link, err := readZipLink(f)
+				if err != nil {
+					return result, err
+				}
+				entry.link = link
+			}
+			if err := x.extract(entry); err != nil {
+				return result, fmt.Errorf("%s: %w", f.Name, err)
+			}
+			report(ArchiveProgress{Files: i + 1, Total: len(archive.File), Current: f.Name})
+		}
+		return result, nil
+	}
+
+	file, err := os.Open(src)
+	if err != nil {
+		return result, err
+	}
+	defer func() { _ = file.Close() }()
+	var stream io.Reader = file
+	switch {
+	case strings.HasSuffix(lower, ".gz"), strings.HasSuffix(lower, ".tgz"):
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return result, err
+		}
+		defer func() { _ = gz.Close() }()
+		stream = gz
+	case strings.HasSuffix(lower, ".bz2"), strings.HasSuffix(lower, ".tbz2"), strings.HasSuffix(lower, ".tbz"):
+		stream = bzip2.NewReader(file)
+	}
+
+	reader := tar.NewReader(stream)
+	for count := 1; ; count++ {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		header, err := reader.Next()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return result, err
+		}
+		entry := archiveEntry{name: header.Name, mode: header.FileInfo().Mode(), modTime: header.ModTime}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			entry.mode |= os.ModeDir
+		case tar.TypeReg, tar.TypeRegA:
+			entry.open = func() (io.ReadCloser, error) { return io.NopCloser(reader), nil }
+		case tar.TypeSymlink:
+			entry.link = header.Linkname
+		default:
+			result.Skipped++
+			report(ArchiveProgress{Files: count, Current: header.Name})
+			continue
+		}
+		if err := x.extract(entry); err != nil {
+			return result, fmt.Errorf("%s: %w", header.Name, err)
+		}
+		report(ArchiveProgress{Files: count, Current: header.Name})
+	}
+}
+
+func readZipLink(f *zip.File) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = rc.Close() }()
+	data, err := io.ReadAll(io.LimitReader(rc, 4096))
+	return string(data), err
+}
+
+type extractor struct {
+	dest      string
+	collision ExtractCollision
+	result    *ExtractResult
+	links     map[string]bool // symlinks written, relative to dest
+	through   map[string]bool // directories the targets of those links pass through
+}
+
+// localPath cleans an archive member name into a path relative to the
+// destination; ok is false for names that would leave it.
+func localPath(name string) (string, bool) {
+	name = path.Clean(strings.ReplaceAll(name, `\`, "/"))
+	if name == "." || name == "/" {
+		return "", false
+	}
+	rel := filepath.FromSlash(name)
+	return rel, filepath.IsLocal(rel)
+}
+
+// throughSymlink reports whether a directory between dest and rel is a
+// symlink, which an archive could have planted to write elsewhere.
+func (x *extractor) throughSymlink(rel string) bool {
+	current := x.dest
+	parts := strings.Split(filepath.Dir(rel), string(filepath.Separator))
+	for _, part := range parts {
+		if part == "." || part == "" {
+			continue
+		}
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if err != nil {
+			return false
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// linkThrough checks a symlink at rel pointing to link. Resolved from rel's
+// directory the target must stay inside dest, and every directory on the way
+// must not be a symlink: ".." after one leaves the directory the link points
+// to, not the one its name suggests. It returns those directories, relative
+// to dest, so no symlink is written at one of them later.
+func (x *extractor) linkThrough(rel, link string) ([]string, bool) {
+	if link == "" || filepath.IsAbs(link) || filepath.VolumeName(link) != "" {
+		return nil, false
+	}
+	var resolved []string
+	if dir := filepath.Dir(rel); dir != "." {
+		resolved = strings.Split(dir, string(filepath.Separator))
+	}
+	var through []string
+	parts := strings.Split(link, string(filepath.Separator))
+	for i, part := range parts {
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			if len(resolved) == 0 {
+				return nil, false
+			}
+			resolved = resolved[:len(resolved)-1]
+			continue
+		}
+		resolved = append(resolved, part)
+		if i == len(parts)-1 {
+			break
+		}
+		dir := filepath.Join(resolved...)
+		if x.links[dir] {
+			return nil, false
+		}
+		if info, err := os.Lstat(filepath.Join(x.dest, dir)); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			return nil, false
+		}
+		through = append(through, dir)
+	}
+	return through, true
+}
+
+func (x *extractor) extract(entry archiveEntry) error {
+	rel, ok := localPath(entry.name)
+	if !ok || x.throughSymlink(rel) {
+		if ok || strings.Trim(entry.name, "./") != "" {
+			x.result.Unsafe++
+		}
+		return nil
+	}
+	target := filepath.Join(x.dest, rel)
+
+	if entry.mode.IsDir() {
+		info, err := os.Lstat(target)
+		if err == nil && info.IsDir() {
+			return nil
+		}
+		if err == nil {
+			// A file is in the way of the directory.
+			x.result.Skipped++
+			return nil
+		}
+		if err := os.MkdirAll(target, 0o755); err != nil {
+			return err
+		}
+		x.result.Files++
+		return nil
+	}
+
+	var through []string
+	if entry.link != "" {
+		var ok bool
+		through, ok = x.linkThrough(rel, filepath.FromSlash(entry.link))
+		if !ok || x.through[rel] {
+			x.result.Unsafe++
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	if info, err := os.Lstat(target); err == nil {
+		switch x.collision {
+		case ExtractSkip:
+			x.result.Skipped++
+			return nil
+		case ExtractOverwrite:
+			if info.IsDir() {
+				x.result.Skipped++
+				return nil
+			}
+			if err := os.Remove(target); err != nil {
+				return err
+			}
+		case ExtractRename:
+			target = UniqueDestination(filepath.Dir(target), filepath.Base(target))
+			x.result.Renamed++
+		case ExtractKeepNewer:
+			if info.IsDir() || !entry.modTime.After(info.ModTime()) {
+				x.result.Skipped++
+				return nil
+			}
+			if err := os.Remove(target); err != nil {
+				return err
+			}
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if entry.link != "" {
+		// A renamed link must not land where an earlier target passes either.
+		written, err := filepath.Rel(x.dest, target)
+		if err != nil || x.through[written] {
+			x.result.Unsafe++
+			return nil
+		}
+		if err := os.Symlink(filepath.FromSlash(entry.link), target); err != nil {
+			// Windows without the symlink privilege: keep going.
+			x.result.Skipped++
+			return nil
+		}
+		x.links[written] = true
+		for _, dir := range through {
+			x.through[dir] = true
+		}
+		x.result.Files++
+		return nil
+	}
+	if err := writeArchiveFile(target, entry); err != nil {
+		return err
+	}
+	x.result.Files++
+	return nil
+}
+
+func writeArchiveFile(target string, entry archiveEntry) error {
+	if entry.open == nil {
+		return nil
+	}
+	rc, err := entry.open()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rc.Close() }()
+
+	perm := entry.mode.Perm()
+	if perm == 0 {
+		perm = 0o644
+	}
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, rc); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if !entry.modTime.IsZero() {
+		_ = os.Chtimes(target, entry.modTime, entry.modTime)
+	}
+	return nil
+}
+
+// archiveItem is a file queued for CreateArchive.
+type archiveItem struct {
+	path string
+	name string // slash-separated name inside the archive
+	info os.FileInfo
+}
+
+// CreateArchive packs sources into a new archive at dest, a zip or a
+// gzip-compressed tar chosen by dest's extension. Each source is stored
+// under its base name; directories are walked without following symlinks,
+// which are stored as links. skip, when set, leaves out entries below a
+// source (the sources themselves are always added). A failed or canceled
+// run removes the partial archive.
+func CreateArchive(ctx context.Context, dest string, sources []string, skip func(path string, isDir bool) bool, progress func(ArchiveProgress)) (result CreateResult, err error) {
+	lower := strings.ToLower(dest)
+	isZip := strings.HasSuffix(lower, ".zip")
+	if !isZip && !strings.HasSuffix(lower, ".tar.gz") && !strings.HasSuffix(lower, ".tgz") {
+		return result, fmt.Errorf("%s: only .zip and .tar.gz archives can be created", filepath.Base(dest))
+	}
+
+	var items []archiveItem
+	for _, src := range sources {
+		base := filepath.Base(src)
+		walkErr := filepath.Walk(src, func(current string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if current == dest || current != src && skip != nil && skip(current, info.IsDir()) {
+				result.Skipped++
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if mode := info.Mode(); !mode.IsDir() && !mode.IsRegular() && mode&os.ModeSymlink == 0 {
+				// Sockets, devices and the like have no place in an archive.
+				result.Skipped++
+				return nil
+			}
+			rel, err := filepath.Rel(src, current)
+			if err != nil {
+				return err
+			}
+			items = append(items, archiveItem{path: current, name: path.Join(base, filepath.ToSlash(rel)), info: info})
+			return nil
+		})
+		if walkErr != nil {
+			return result, walkErr
+		}
+	}
+
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.Remove(dest)
+		}
+	}()
+
+	if isZip {
+		err = writeZipArchive(ctx, out, items, &result, progress)
+	} else {
+		err = writeTarGzArchive(ctx, out, items, &result, progress)
+	}
+	return result, err
+}
+
+func writeZipArchive(ctx context.Context, out io.Writer, items []archiveItem, result *CreateResult, progress func(ArchiveProgress)) error {
+	w := zip.NewWriter(out)
+	for i, item := range items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		header, err := zip.FileInfoHeader(item.info)
+		if err != nil {
+			return err
+		}
+		header.Name = item.name
+		if item.info.IsDir() {
+			header.Name += "/"
+		} else {
+			header.Method = zip.Deflate
+		}
+		entry, err := w.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if err := writeItemContent(entry, item); err != nil {
+			return err
+		}
+		result.Files++
+		if progress != nil {
+			progress(ArchiveProgress{Files: i + 1, Total: len(items), Current: item.name})
+		}
+	}
+	return w.Close()
+}
+
+func writeTarGzArchive(ctx context.Context, out io.Writer, items []archiveItem, result *CreateResult, progress func(ArchiveProgress)) error {
+	gz := gzip.NewWriter(out)
+	w := tar.NewWriter(gz)
+	for i, item := range items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var link string
+		if item.info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(item.path)
+			if err != nil {
+				return err
+			}
+			link = target
+		}
+		header, err := tar.FileInfoHeader(item.info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = item.name
+		if item.info.IsDir() {
+			header.Name += "/"
+		}
+		if err := w.WriteHeader(header); err != nil {
+			return err
+		}
+		if header.Typeflag == tar.TypeReg {
+			if err := writeItemContent(w, item); err != nil {
+				return err
+			}
+		}
+		result.Files++
+		if progress != nil {
+			progress(ArchiveProgress{Files: i + 1, Total: len(items), Current: item.name})
+		}
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// writeItemContent copies a regular file's content, or a symlink's target
+// (how zip stores links), to w.
+func writeItemContent(w io.Writer, item archiveItem) error {
+	mode := item.info.Mode()
+	switch {
+	case mode&os.ModeSymlink != 0:
+		target, err := os.Readlink(item.path)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, filepath.ToSlash(target))
+		return err
+	case mode.IsRegular():
+		file, err := os.Open(item.path)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = file.Close() }()
+		_, err = io.Copy(w, file)
+		return err
+	}
+	return nil
+}
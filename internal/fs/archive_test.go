@@ -0,0 +1,291 @@
+package fs
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/kk-code-lab/rdir/internal/testutil"
+)
+
+// tarEntry is a member for writeTarGz.
+type tarEntry struct {
+	header tar.Header
+	body   string
+}
+
+func writeTarGz(t *testing.T, path string, entries []tarEntry) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		h := e.header
+		h.Size = int64(len(e.body))
+		if err := tw.WriteHeader(&h); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestArchiveStem(t *testing.T) {
+	tests := []struct {
+		name string
+		stem string
+		ok   bool
+	}{
+		{"site.zip", "site", true},
+		{"site.tar.gz", "site", true},
+		{"Site.TGZ", "Site", true},
+		{"backup-1.2.tar.bz2", "backup-1.2", true},
+		{"notes.txt", "", false},
+		{".zip", "", false},
+	}
+	for _, tt := range tests {
+		stem, ok := ArchiveStem(tt.name)
+		if stem != tt.stem || ok != tt.ok {
+			t.Errorf("ArchiveStem(%q) = %q, %v; want %q, %v", tt.name, stem, ok, tt.stem, tt.ok)
+		}
+	}
+}
+
+func TestExtractArchiveZipCollisions(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "a.zip")
+	testutil.WriteZip(t, archive, map[string]string{
+		"docs/readme.txt": "new",
+		"main.go":         "package main",
+		"../escape.txt":   "nope",
+		"/abs.txt":        "nope",
+	})
+
+	tests := []struct {
+		collision ExtractCollision
+		readme    string
+		renamed   string
+	}{
+		{ExtractSkip, "old", ""},
+		{ExtractOverwrite, "new", ""},
+		{ExtractRename, "old", "new"},
+		// The archive entries are older than the file written below.
+		{ExtractKeepNewer, "old", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.collision.String(), func(t *testing.T) {
+			dest := filepath.Join(t.TempDir(), "a")
+			if err := os.MkdirAll(filepath.Join(dest, "docs"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dest, "docs", "readme.txt"), []byte("old"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			var seen []string
+			result, err := ExtractArchive(context.Background(), archive, dest, tt.collision, func(p ArchiveProgress) {
+				if p.Total != 4 {
+					t.Errorf("progress total = %d, want 4", p.Total)
+				}
+				seen = append(seen, p.Current)
+			})
+			if err != nil {
+				t.Fatalf("ExtractArchive: %v", err)
+			}
+			if len(seen) != 4 {
+				t.Fatalf("progress calls = %v", seen)
+			}
+			if result.Unsafe != 2 {
+				t.Fatalf("unsafe = %d, want 2", result.Unsafe)
+			}
+			if data, _ := os.ReadFile(filepath.Join(dest, "docs", "readme.txt")); string(data) != tt.readme {
+				t.Fatalf("readme = %q, want %q", data, tt.readme)
+			}
+			renamed, _ := os.ReadFile(filepath.Join(dest, "docs", "readme (1).txt"))
+			if string(renamed) != tt.renamed {
+				t.Fatalf("renamed copy = %q, want %q", renamed, tt.renamed)
+			}
+			if _, err := os.Stat(filepath.Join(dest, "main.go")); err != nil {
+				t.Fatalf("main.go missing: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(filepath.Dir(dest), "escape.txt")); err == nil {
+				t.Fatalf("entry escaped the destination")
+			}
+		})
+	}
+}
+
+func TestExtractArchiveTarGzRefusesEscapingLinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on Windows")
+	}
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "b.tar.gz")
+	writeTarGz(t, archive, []tarEntry{
+		{tar.Header{Name: "pkg/", Typeflag: tar.TypeDir, Mode: 0o755}, ""},
+		{tar.Header{Name: "pkg/file.txt", Typeflag: tar.TypeReg, Mode: 0o600}, "hello"},
+		{tar.Header{Name: "pkg/ok", Typeflag: tar.TypeSymlink, Linkname: "file.txt"}, ""},
+		{tar.Header{Name: "pkg/out", Typeflag: tar.TypeSymlink, Linkname: "../../etc"}, ""},
+		{tar.Header{Name: "pkg/hard", Typeflag: tar.TypeLink, Linkname: "pkg/file.txt"}, ""},
+	})
+
+	dest := filepath.Join(dir, "b")
+	result, err := ExtractArchive(context.Background(), archive, dest, ExtractSkip, nil)
+	if err != nil {
+		t.Fatalf("ExtractArchive: %v", err)
+	}
+	want := ExtractResult{Files: 3, Skipped: 1, Unsafe: 1}
+	if result != want {
+		t.Fatalf("result = %+v, want %+v", result, want)
+	}
+	info, err := os.Stat(filepath.Join(dest, "pkg", "file.txt"))
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("file.txt = %v, %v", info, err)
+	}
+	if target, err := os.Readlink(filepath.Join(dest, "pkg", "ok")); err != nil || target != "file.txt" {
+		t.Fatalf("link = %q, %v", target, err)
+	}
+	if _, err := os.Lstat(filepath.Join(dest, "pkg", "out")); err == nil {
+		t.Fatalf("escaping symlink was created")
+	}
+}
+
+func TestExtractArchiveRefusesLinksChainedOutOfDest(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on Windows")
+	}
+	link := func(name, target string) tarEntry {
+		return tarEntry{tar.Header{Name: name, Typeflag: tar.TypeSymlink, Linkname: target}, ""}
+	}
+	// Each link alone stays inside dest; together d would point to its
+	// parent, whichever comes first.
+	for name, entries := range map[string][]tarEntry{
+		"link first":   {link("e", "."), link("d", "e/..")},
+		"target first": {link("d", "e/.."), link("e", ".")},
+		"nested":       {link("a/e", ".."), link("a/d", "e/../..")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			archive := filepath.Join(dir, "c.tar.gz")
+			writeTarGz(t, archive, entries)
+			dest := filepath.Join(dir, "c")
+			result, err := ExtractArchive(context.Background(), archive, dest, ExtractSkip, nil)
+			if err != nil {
+				t.Fatalf("ExtractArchive: %v", err)
+			}
+			if result.Files != 1 || result.Unsafe != 1 {
+				t.Fatalf("result = %+v, want one link written and one refused", result)
+			}
+			for _, e := range entries {
+				resolved, err := filepath.EvalSymlinks(filepath.Join(dest, e.header.Name))
+				if err != nil {
+					continue
+				}
+				if rel, err := filepath.Rel(dest, resolved); err != nil || !filepath.IsLocal(rel) && rel != "." {
+					t.Fatalf("%s resolves to %s, outside %s", e.header.Name, resolved, dest)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractArchiveStopsWhenCanceled(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "c.zip")
+	testutil.WriteZip(t, archive, map[string]string{"a.txt": "a"})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ExtractArchive(ctx, archive, filepath.Join(dir, "c"), ExtractSkip, nil); err != context.Canceled {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestCreateArchiveRoundTrip(t *testing.T) {
+	for _, suffix := range ArchiveFormatSuffixes {
+		t.Run(suffix, func(t *testing.T) {
+			dir := t.TempDir()
+			src := filepath.Join(dir, "proj")
+			for name, content := range map[string]string{
+				"main.go":          "package main",
+				"lib/util.go":      "package lib",
+				"build/output.bin": "skip me",
+			} {
+				path := filepath.Join(src, filepath.FromSlash(name))
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			single := filepath.Join(dir, "notes.txt")
+			if err := os.WriteFile(single, []byte("notes"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			archive := filepath.Join(dir, "out"+suffix)
+			skip := func(path string, isDir bool) bool { return isDir && filepath.Base(path) == "build" }
+			var last ArchiveProgress
+			result, err := CreateArchive(context.Background(), archive, []string{src, single}, skip, func(p ArchiveProgress) { last = p })
+			if err != nil {
+				t.Fatalf("CreateArchive: %v", err)
+			}
+			// proj, proj/lib, proj/lib/util.go, proj/main.go, notes.txt
+			if result.Files != 5 || result.Skipped != 1 || last.Files != 5 || last.Total != 5 {
+				t.Fatalf("result = %+v, last progress = %+v", result, last)
+			}
+			if _, err := CreateArchive(context.Background(), archive, []string{single}, nil, nil); err == nil {
+				t.Fatalf("expected an existing archive not to be overwritten")
+			}
+
+			dest := filepath.Join(dir, "unpacked")
+			if _, err := ExtractArchive(context.Background(), archive, dest, ExtractSkip, nil); err != nil {
+				t.Fatalf("ExtractArchive: %v", err)
+			}
+			if data, _ := os.ReadFile(filepath.Join(dest, "proj", "lib", "util.go")); string(data) != "package lib" {
+				t.Fatalf("util.go = %q", data)
+			}
+			if data, _ := os.ReadFile(filepath.Join(dest, "notes.txt")); string(data) != "notes" {
+				t.Fatalf("notes.txt = %q", data)
+			}
+			if _, err := os.Stat(filepath.Join(dest, "proj", "build")); err == nil {
+				t.Fatalf("skipped directory was archived")
+			}
+		})
+	}
+}
+
+func TestCreateArchiveRemovesPartialArchiveWhenCanceled(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(src, []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	archive := filepath.Join(dir, "a.zip")
+	if _, err := CreateArchive(ctx, archive, []string{src}, nil, nil); err != context.Canceled {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if _, err := os.Stat(archive); err == nil {
+		t.Fatalf("partial archive left behind")
+	}
+	if _, err := CreateArchive(context.Background(), filepath.Join(dir, "a.rar"), []string{src}, nil, nil); err == nil {
+		t.Fatalf("expected unsupported formats to be rejected")
+	}
+}
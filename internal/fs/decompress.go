@@ -0,0 +1,360 @@
+package fs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/kk-code-lab/rdir/internal/procwatch"
+)
+
+// Compressed describes a file compressed as a whole, such as app.log.gz.
+type Compressed struct {
+	Format string // "gzip", "xz" or "zstd"
+	Size   int64  // uncompressed size, or -1 when unknown
+}
+
+// compressedFormats maps the extensions of single compressed files to their
+// format and, for formats the standard library cannot read, the command
+// that decompresses them to stdout.
+var compressedFormats = []struct {
+	suffix  string
+	format  string
+	command []string
+}{
+	{".gz", "gzip", nil},
+	{".xz", "xz", []string{"xz", "-dc", "--"}},
+	{".zst", "zstd", []string{"zstd", "-dcq", "--"}},
+}
+
+// CompressedFormat returns the format of a file compressed as a whole,
+// judged by its extension, and the path without that extension
+// ("app.log.gz" -> "app.log"). Compressed tar files are archives, not
+// single files, and are not reported.
+func CompressedFormat(path string) (format, inner string, ok bool) {
+	lower := strings.ToLower(path)
+	for _, c := range compressedFormats {
+		if !strings.HasSuffix(lower, c.suffix) {
+			continue
+		}
+		inner = path[:len(path)-len(c.suffix)]
+		if filepath.Base(inner) == "" || strings.EqualFold(filepath.Ext(inner), ".tar") {
+			return "", "", false
+		}
+		return c.format, inner, true
+	}
+	return "", "", false
+}
+
+// DecompressHead returns up to limit bytes of path's decompressed content;
+// full reports that the content ended within the limit. gzip is read in
+// process, xz and zstd need the xz and zstd commands.
+func DecompressHead(ctx context.Context, path string, limit int64) (data []byte, full bool, err error) {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	r, finish, err := decompressor(runCtx, path)
+	if err != nil {
+		return nil, false, err
+	}
+	data, full, readErr := readHead(ctx, r, limit)
+	if err := finish(cancel, full); err != nil {
+		return nil, false, err
+	}
+	if readErr != nil {
+		return nil, false, readErr
+	}
+	return data, full, nil
+}
+
+// decompressor streams path's decompressed content until ctx is canceled.
+// finish releases it, given the cancel func of ctx and whether the content
+// was read to its end, and returns why the decompression failed: a timeout
+// always, any other failure only when the content was read to its end.
+func decompressor(ctx context.Context, path string) (io.Reader, func(cancel context.CancelFunc, ended bool) error, error) {
+	lower := strings.ToLower(path)
+	for _, c := range compressedFormats {
+		if !strings.HasSuffix(lower, c.suffix) {
+			continue
+		}
+		if c.command == nil {
+			return gunzip(path)
+		}
+		return commandOutput(ctx, c.command, path)
+	}
+	return nil, nil, fmt.Errorf("%s: not a compressed file", filepath.Base(path))
+}
+
+func gunzip(path string) (io.Reader, func(context.CancelFunc, bool) error, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, err
+	}
+	finish := func(context.CancelFunc, bool) error {
+		_ = zr.Close()
+		_ = f.Close()
+		return nil
+	}
+	return zr, finish, nil
+}
+
+func commandOutput(ctx context.Context, command []string, path string) (io.Reader, func(context.CancelFunc, bool) error, error) {
+	if _, err := exec.LookPath(command[0]); err != nil {
+		return nil, nil, fmt.Errorf("%s: install %s to read it", filepath.Base(path), command[0])
+	}
+	// Check before starting the command, which runs as this user anyway,
+	// so a permission problem reads like one.
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	_ = f.Close()
+
+	cmd, done := procwatch.CommandContext(ctx, procwatch.Decompress, command[0], append(append([]string(nil), command[1:]...), path)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, err
+	}
+	finish := func(cancel context.CancelFunc, ended bool) error {
+		if !ended {
+			// Enough was read; the rest of the output is not wanted.
+			cancel()
+		}
+		waitErr := done(cmd.Wait())
+		var timeout *procwatch.TimeoutError
+		if errors.As(waitErr, &timeout) {
+			return waitErr
+		}
+		if ended && waitErr != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return errors.New(msg)
+			}
+			return fmt.Errorf("%s: %w", command[0], waitErr)
+		}
+		return nil
+	}
+	return stdout, finish, nil
+}
+
+// DecompressReader decompresses a file as its content is read, keeping what
+// was decompressed so far in memory, up to a limit. Nothing is decompressed
+// beyond what callers asked for, and Close stops the decompression.
+type DecompressReader struct {
+	mu        sync.Mutex
+	ctx       context.Context
+	cancel    context.CancelFunc
+	src       io.Reader
+	finish    func(context.CancelFunc, bool) error
+	limit     int64
+	buf       []byte
+	done      bool  // the content ended, hit the limit or failed
+	truncated bool  // the content goes on past the limit
+	err       error // why decompression failed, once done
+}
+
+// OpenDecompressed starts decompressing path for reading up to limit bytes
+// of its content. Canceling ctx or closing the reader stops it.
+func OpenDecompressed(ctx context.Context, path string, limit int64) (*DecompressReader, error) {
+	runCtx, cancel := context.WithCancel(ctx)
+	src, finish, err := decompressor(runCtx, path)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &DecompressReader{ctx: runCtx, cancel: cancel, src: src, finish: finish, limit: limit}, nil
+}
+
+// ReadAt implements io.ReaderAt, decompressing up to off+len(p) first. The
+// content ends at the limit; Truncated tells whether it went on.
+func (r *DecompressReader) ReadAt(p []byte, off int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	end := minInt64(off+int64(len(p)), r.limit)
+	for int64(len(r.buf)) < end && !r.done {
+		r.fill()
+	}
+	if off >= int64(len(r.buf)) {
+		if r.err != nil {
+			return 0, r.err
+		}
+		return 0, io.EOF
+	}
+	n := copy(p, r.buf[off:])
+	if n < len(p) {
+		if r.err != nil {
+			return n, r.err
+		}
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// fill decompresses one more chunk, reading a byte past the limit to tell
+// whether the content ends there.
+func (r *DecompressReader) fill() {
+	if err := r.ctx.Err(); err != nil {
+		r.stop(err, false)
+		return
+	}
+	want := minInt64(r.limit+1-int64(len(r.buf)), readChunkSize)
+	if free := int64(cap(r.buf) - len(r.buf)); free < want {
+		r.buf = append(r.buf, make([]byte, want)...)[:len(r.buf)]
+	}
+	n, err := r.src.Read(r.buf[len(r.buf) : int64(len(r.buf))+want])
+	r.buf = r.buf[:len(r.buf)+n]
+	switch {
+	case int64(len(r.buf)) > r.limit:
+		r.buf = r.buf[:r.limit]
+		r.truncated = true
+		r.stop(nil, false)
+	case err == io.EOF:
+		r.stop(nil, true)
+	case err != nil:
+		r.stop(err, false)
+	}
+}
+
+func (r *DecompressReader) stop(err error, ended bool) {
+	r.done = true
+	if finishErr := r.finish(r.cancel, ended); finishErr != nil {
+		err = finishErr
+	}
+	r.err = err
+	r.cancel()
+}
+
+// Truncated reports whether the content was found to go on past the limit.
+func (r *DecompressReader) Truncated() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.truncated
+}
+
+// Close stops the decompression and drops what it produced.
+func (r *DecompressReader) Close() error {
+	r.cancel()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.done {
+		r.stop(os.ErrClosed, false)
+	}
+	r.buf = nil
+	return nil
+}
+
+// readHead reads up to limit bytes from r, reading one more to tell whether
+// the content ended within the limit.
+func readHead(ctx context.Context, r io.Reader, limit int64) ([]byte, bool, error) {
+	buf := make([]byte, 0, minInt64(limit+1, readChunkSize))
+	chunk := make([]byte, readChunkSize)
+	for int64(len(buf)) <= limit {
+		if err := ctx.Err(); err != nil {
+			return nil, false, err
+		}
+		want := minInt64(limit+1-int64(len(buf)), int64(len(chunk)))
+		n, err := r.Read(chunk[:want])
+		buf = append(buf, chunk[:n]...)
+		if err == io.EOF {
+			return buf, true, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	return buf[:limit], false, nil
+}
+
+// UncompressedSize returns the uncompressed size a compressed file records
+// about itself, or -1 when it records none. gzip keeps the size modulo
+// 4 GiB of its last member, so callers should only trust a value that is
+// not smaller than what they decompressed; zstd keeps it in the first
+// frame's header when the compressor knew it; xz is not read.
+func UncompressedSize(path string) int64 {
+	format, _, ok := CompressedFormat(path)
+	if !ok {
+		return -1
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return -1
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	switch format {
+	case "gzip":
+		info, err := f.Stat()
+		if err != nil || info.Size() < 18 {
+			return -1
+		}
+		var trailer [4]byte
+		if _, err := f.ReadAt(trailer[:], info.Size()-4); err != nil {
+			return -1
+		}
+		return int64(binary.LittleEndian.Uint32(trailer[:]))
+	case "zstd":
+		var header [18]byte
+		n, _ := io.ReadFull(f, header[:])
+		return zstdContentSize(header[:n])
+	}
+	return -1
+}
+
+// zstdContentSize decodes the Frame_Content_Size field of a zstd frame
+// header (RFC 8878, section 3.1.1.1), or returns -1 when it is absent.
+func zstdContentSize(header []byte) int64 {
+	if len(header) < 5 || binary.LittleEndian.Uint32(header) != 0xFD2FB528 {
+		return -1
+	}
+	descriptor := header[4]
+	singleSegment := descriptor&0x20 != 0
+	pos := 5
+	if !singleSegment {
+		pos++ // window descriptor
+	}
+	pos += [4]int{0, 1, 2, 4}[descriptor&0x03] // dictionary ID
+	size := [4]int{0, 2, 4, 8}[descriptor>>6]
+	if size == 0 {
+		if !singleSegment {
+			return -1
+		}
+		size = 1
+	}
+	if len(header) < pos+size {
+		return -1
+	}
+	field := header[pos : pos+size]
+	switch size {
+	case 1:
+		return int64(field[0])
+	case 2:
+		return int64(binary.LittleEndian.Uint16(field)) + 256
+	case 4:
+		return int64(binary.LittleEndian.Uint32(field))
+	default:
+		v := binary.LittleEndian.Uint64(field)
+		if v > math.MaxInt64 {
+			return -1
+		}
+		return int64(v)
+	}
+}
@@ -0,0 +1,143 @@
+package fs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kk-code-lab/rdir/internal/testutil"
+)
+
+func TestCompressedFormat(t *testing.T) {
+	cases := []struct {
+		path, format, inner string
+	}{
+		{"logs/app.log.gz", "gzip", "logs/app.log"},
+		{"dump.SQL.XZ", "xz", "dump.SQL"},
+		{"data.json.zst", "zstd", "data.json"},
+		{"site.tar.gz", "", ""},
+		{"site.tar.zst", "", ""},
+		{"notes.txt", "", ""},
+	}
+	for _, tc := range cases {
+		format, inner, ok := CompressedFormat(tc.path)
+		if ok != (tc.format != "") || format != tc.format || inner != tc.inner {
+			t.Errorf("%s: got %q %q %v", tc.path, format, inner, ok)
+		}
+	}
+}
+
+func TestDecompressHeadGzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log.gz")
+	content := []byte(strings.Repeat("line of log output\n", 1000))
+	testutil.WriteGzip(t, path, content)
+
+	data, full, err := DecompressHead(context.Background(), path, 100)
+	if err != nil || full || !bytes.Equal(data, content[:100]) {
+		t.Fatalf("head = %q, full %v, err %v", data, full, err)
+	}
+	data, full, err = DecompressHead(context.Background(), path, int64(len(content)))
+	if err != nil || !full || !bytes.Equal(data, content) {
+		t.Fatalf("whole = %d bytes, full %v, err %v", len(data), full, err)
+	}
+	if got := UncompressedSize(path); got != int64(len(content)) {
+		t.Fatalf("UncompressedSize = %d, want %d", got, len(content))
+	}
+
+	if err := os.WriteFile(path, []byte("not gzip"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := DecompressHead(context.Background(), path, 100); err == nil {
+		t.Fatalf("expected an error for corrupt input")
+	}
+}
+
+func TestDecompressReaderReadsOnDemand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log.gz")
+	content := []byte(strings.Repeat("line of log output\n", 20000))
+	testutil.WriteGzip(t, path, content)
+
+	r, err := OpenDecompressed(context.Background(), path, 200000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 100)
+	if n, err := r.ReadAt(buf, 1000); err != nil || !bytes.Equal(buf[:n], content[1000:1100]) {
+		t.Fatalf("ReadAt = %q, %v", buf[:n], err)
+	}
+	if got := len(r.buf); got >= 200000 {
+		t.Fatalf("expected only the start to be decompressed, have %d bytes", got)
+	}
+	if n, err := r.ReadAt(buf, 199950); n != 50 || err != io.EOF || !bytes.Equal(buf[:n], content[199950:200000]) {
+		t.Fatalf("ReadAt at the limit = %d, %v", n, err)
+	}
+	if !r.Truncated() {
+		t.Fatal("expected content past the limit to be reported")
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ReadAt(buf, 0); err == nil {
+		t.Fatal("expected reads after Close to fail")
+	}
+}
+
+func TestDecompressHeadWithCommand(t *testing.T) {
+	content := []byte(strings.Repeat("0123456789\n", 500))
+	for _, tc := range []struct{ tool, ext string }{{"xz", ".xz"}, {"zstd", ".zst"}} {
+		t.Run(tc.tool, func(t *testing.T) {
+			if _, err := exec.LookPath(tc.tool); err != nil {
+				t.Skipf("%s not available", tc.tool)
+			}
+			src := filepath.Join(t.TempDir(), "data.txt")
+			if err := os.WriteFile(src, content, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if out, err := exec.Command(tc.tool, "-q", src).CombinedOutput(); err != nil {
+				t.Fatalf("%s: %v %s", tc.tool, err, out)
+			}
+			path := src + tc.ext
+			data, full, err := DecompressHead(context.Background(), path, 64)
+			if err != nil || full || !bytes.Equal(data, content[:64]) {
+				t.Fatalf("head = %q, full %v, err %v", data, full, err)
+			}
+			data, full, err = DecompressHead(context.Background(), path, 1<<20)
+			if err != nil || !full || !bytes.Equal(data, content) {
+				t.Fatalf("whole = %d bytes, full %v, err %v", len(data), full, err)
+			}
+			if tc.tool == "zstd" {
+				if got := UncompressedSize(path); got != int64(len(content)) {
+					t.Fatalf("UncompressedSize = %d, want %d", got, len(content))
+				}
+			}
+		})
+	}
+}
+
+func TestZstdContentSize(t *testing.T) {
+	magic := []byte{0x28, 0xB5, 0x2F, 0xFD}
+	cases := []struct {
+		name   string
+		header []byte
+		want   int64
+	}{
+		{"single segment, 1 byte", []byte{0x20, 200}, 200},
+		{"2 bytes", []byte{0x40, 0x00, 0x10, 0x00}, 16 + 256},
+		{"4 bytes with dictionary id", []byte{0x81, 0x00, 0x07, 0x00, 0x00, 0x01, 0x00}, 1 << 16},
+		{"absent", []byte{0x00, 0x00}, -1},
+	}
+	for _, tc := range cases {
+		header := append(append([]byte(nil), magic...), tc.header...)
+		if got := zstdContentSize(header); got != tc.want {
+			t.Errorf("%s: got %d, want %d", tc.name, got, tc.want)
+		}
+	}
+	if got := zstdContentSize([]byte("not zstd")); got != -1 {
+		t.Errorf("wrong magic: got %d", got)
+	}
+}
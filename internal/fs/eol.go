@@ -0,0 +1,281 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LineEndingFileLimit is the largest file whose line endings are counted or
+// converted. Both hold the whole file in memory, and the count runs before
+// the confirmation prompt, so larger files are refused.
+var LineEndingFileLimit int64 = 32 << 20
+
+// checkLineEndingSize refuses files over LineEndingFileLimit.
+func checkLineEndingSize(path string, info os.FileInfo) error {
+	if info.Size() > LineEndingFileLimit {
+		return fmt.Errorf("%s is larger than %d MB; line endings are only converted in smaller files", filepath.Base(path), LineEndingFileLimit>>20)
+	}
+	return nil
+}
+
+// LineEnding is one of the line terminators a text file can use.
+type LineEnding string
+
+const (
+	LineEndingLF   LineEnding = "lf"
+	LineEndingCRLF LineEnding = "crlf"
+	LineEndingCR   LineEnding = "cr"
+)
+
+// LineEndings counts the line terminators found in a text file.
+type LineEndings struct {
+	LF   int
+	CRLF int
+	CR   int
+}
+
+// Total returns the number of line terminators.
+func (e LineEndings) Total() int {
+	return e.LF + e.CRLF + e.CR
+}
+
+// Mixed reports whether more than one kind of terminator is used.
+func (e LineEndings) Mixed() bool {
+	kinds := 0
+	for _, n := range []int{e.LF, e.CRLF, e.CR} {
+		if n > 0 {
+			kinds++
+		}
+	}
+	return kinds > 1
+}
+
+// Dominant returns the most common terminator; ties prefer LF, then CRLF.
+func (e LineEndings) Dominant() LineEnding {
+	switch {
+	case e.LF >= e.CRLF && e.LF >= e.CR:
+		return LineEndingLF
+	case e.CRLF >= e.CR:
+		return LineEndingCRLF
+	default:
+		return LineEndingCR
+	}
+}
+
+// Count returns how many terminators of kind the file has.
+func (e LineEndings) Count(kind LineEnding) int {
+	switch kind {
+	case LineEndingLF:
+		return e.LF
+	case LineEndingCRLF:
+		return e.CRLF
+	case LineEndingCR:
+		return e.CR
+	default:
+		return 0
+	}
+}
+
+// String describes the terminators: "lf", "crlf", "cr", "mixed (crlf 3, lf 10)"
+// or "" when the content has no line breaks.
+func (e LineEndings) String() string {
+	if e.Total() == 0 {
+		return ""
+	}
+	if !e.Mixed() {
+		return string(e.Dominant())
+	}
+	parts := []string{}
+	for _, kind := range []LineEnding{LineEndingCRLF, LineEndingLF, LineEndingCR} {
+		if n := e.Count(kind); n > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", kind, n))
+		}
+	}
+	return "mixed (" + strings.Join(parts, ", ") + ")"
+}
+
+// CountLineEndings counts the terminators in content, which is read in the
+// code units of enc (bytes, or 16-bit units for UTF-16).
+func CountLineEndings(content []byte, enc UnicodeEncoding) LineEndings {
+	units := newTextUnits(content, enc)
+	var counts LineEndings
+	for i := 0; i < units.len(); i++ {
+		switch units.at(i) {
+		case '\n':
+			counts.LF++
+		case '\r':
+			if i+1 < units.len() && units.at(i+1) == '\n' {
+				counts.CRLF++
+				i++
+			} else {
+				counts.CR++
+			}
+		}
+	}
+	return counts
+}
+
+// ConvertLineEndings rewrites the file at path so every line ends with
+// target. The original is first copied next to it as "<name>.bak" (or
+// "<name> (1).bak", ...) and the new content replaces the file atomically,
+// keeping its permission bits. It returns the backup path and the number of
+// terminators that changed.
+func ConvertLineEndings(path string, target LineEnding) (string, int, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", 0, err
+	}
+	if !info.Mode().IsRegular() {
+		return "", 0, fmt.Errorf("%s is not a regular file", filepath.Base(path))
+	}
+	if err := checkLineEndingSize(path, info); err != nil {
+		return "", 0, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", 0, err
+	}
+	enc := DetectUnicodeEncoding(content)
+	counts := CountLineEndings(content, enc)
+	changed := counts.Total() - counts.Count(target)
+	if changed == 0 {
+		return "", 0, nil
+	}
+
+	dir := filepath.Dir(path)
+	backup := UniqueDestination(dir, filepath.Base(path)+".bak")
+	if err := copyFile(path, backup, info); err != nil {
+		if !errors.Is(err, os.ErrExist) {
+			_ = os.Remove(backup)
+		}
+		return "", 0, fmt.Errorf("backup %s: %w", filepath.Base(path), err)
+	}
+
+	converted := convertLineEndings(content, enc, target)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".eol-*")
+	if err != nil {
+		return backup, 0, err
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(converted); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpName)
+		return backup, 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpName)
+		return backup, 0, err
+	}
+	if err := os.Chmod(tmpName, info.Mode().Perm()); err != nil {
+		_ = os.Remove(tmpName)
+		return backup, 0, err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		_ = os.Remove(tmpName)
+		return backup, 0, err
+	}
+	return backup, changed, nil
+}
+
+func convertLineEndings(content []byte, enc UnicodeEncoding, target LineEnding) []byte {
+	units := newTextUnits(content, enc)
+	var eol []uint16
+	switch target {
+	case LineEndingCRLF:
+		eol = []uint16{'\r', '\n'}
+	case LineEndingCR:
+		eol = []uint16{'\r'}
+	default:
+		eol = []uint16{'\n'}
+	}
+
+	out := make([]byte, 0, len(content)+len(content)/16)
+	for i := 0; i < units.len(); i++ {
+		u := units.at(i)
+		switch u {
+		case '\r':
+			if i+1 < units.len() && units.at(i+1) == '\n' {
+				i++
+			}
+			fallthrough
+		case '\n':
+			for _, e := range eol {
+				out = units.appendUnit(out, e)
+			}
+		default:
+			out = units.appendUnit(out, u)
+		}
+	}
+	// An odd trailing byte of a UTF-16 file is kept as is.
+	if units.width == 2 && len(content)%2 == 1 {
+		out = append(out, content[len(content)-1])
+	}
+	return out
+}
+
+// textUnits reads content as bytes or as 16-bit UTF-16 code units. CR and LF
+// are single units in every supported encoding, and a UTF-8 continuation
+// byte can never equal either, so scanning units is enough to find them.
+type textUnits struct {
+	content   []byte
+	width     int
+	bigEndian bool
+}
+
+func newTextUnits(content []byte, enc UnicodeEncoding) textUnits {
+	switch enc {
+	case EncodingUTF16LE:
+		return textUnits{content: content, width: 2}
+	case EncodingUTF16BE:
+		return textUnits{content: content, width: 2, bigEndian: true}
+	default:
+		return textUnits{content: content, width: 1}
+	}
+}
+
+func (t textUnits) len() int {
+	return len(t.content) / t.width
+}
+
+func (t textUnits) at(i int) uint16 {
+	if t.width == 1 {
+		return uint16(t.content[i])
+	}
+	lo, hi := t.content[2*i], t.content[2*i+1]
+	if t.bigEndian {
+		lo, hi = hi, lo
+	}
+	return uint16(lo) | uint16(hi)<<8
+}
+
+func (t textUnits) appendUnit(out []byte, u uint16) []byte {
+	switch {
+	case t.width == 1:
+		return append(out, byte(u))
+	case t.bigEndian:
+		return append(out, byte(u>>8), byte(u))
+	default:
+		return append(out, byte(u), byte(u>>8))
+	}
+}
+
+// FileLineEndings counts the terminators of the whole file at path.
+// Files over LineEndingFileLimit are refused.
+func FileLineEndings(path string) (LineEndings, UnicodeEncoding, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return LineEndings{}, EncodingUnknown, err
+	}
+	if err := checkLineEndingSize(path, info); err != nil {
+		return LineEndings{}, EncodingUnknown, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return LineEndings{}, EncodingUnknown, err
+	}
+	enc := DetectUnicodeEncoding(content)
+	return CountLineEndings(content, enc), enc, nil
+}
@@ -0,0 +1,97 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCountLineEndingsReportsMixedFiles(t *testing.T) {
+	counts := CountLineEndings([]byte("a\r\nb\nc\rd\r\n"), EncodingUnknown)
+	if counts != (LineEndings{LF: 1, CRLF: 2, CR: 1}) {
+		t.Fatalf("unexpected counts %+v", counts)
+	}
+	if got := counts.String(); got != "mixed (crlf 2, lf 1, cr 1)" {
+		t.Fatalf("unexpected label %q", got)
+	}
+	if counts.Dominant() != LineEndingCRLF {
+		t.Fatalf("expected crlf to dominate, got %s", counts.Dominant())
+	}
+
+	utf16 := []byte{0xFF, 0xFE, 'a', 0, '\r', 0, '\n', 0, 'b', 0, '\n', 0}
+	if got := CountLineEndings(utf16, EncodingUTF16LE); got != (LineEndings{LF: 1, CRLF: 1}) {
+		t.Fatalf("unexpected UTF-16 counts %+v", got)
+	}
+	if got := CountLineEndings([]byte("single line"), EncodingUnknown).String(); got != "" {
+		t.Fatalf("expected no label without line breaks, got %q", got)
+	}
+}
+
+func TestConvertLineEndingsKeepsBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.txt")
+	original := "\xEF\xBB\xBFone\r\ntwo\nthree\r\n"
+	if err := os.WriteFile(path, []byte(original), 0o640); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	backup, changed, err := ConvertLineEndings(path, LineEndingLF)
+	if err != nil {
+		t.Fatalf("ConvertLineEndings: %v", err)
+	}
+	if changed != 2 || backup != filepath.Join(dir, "notes.txt.bak") {
+		t.Fatalf("unexpected result backup=%q changed=%d", backup, changed)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "\xEF\xBB\xBFone\ntwo\nthree\n" {
+		t.Fatalf("unexpected converted content %q", data)
+	}
+	saved, _ := os.ReadFile(backup)
+	if string(saved) != original {
+		t.Fatalf("backup should hold the original, got %q", saved)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o640 {
+		t.Fatalf("expected permissions to be kept: %v %v", info.Mode(), err)
+	}
+
+	backup, changed, err = ConvertLineEndings(path, LineEndingLF)
+	if err != nil || changed != 0 || backup != "" {
+		t.Fatalf("expected a no-op on a converted file, got %q %d %v", backup, changed, err)
+	}
+}
+
+func TestConvertLineEndingsUTF16BigEndian(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wide.txt")
+	content := []byte{0xFE, 0xFF, 0, 'a', 0, '\n', 0, 'b', 0, '\n'}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, _, err := ConvertLineEndings(path, LineEndingCRLF); err != nil {
+		t.Fatalf("ConvertLineEndings: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	want := []byte{0xFE, 0xFF, 0, 'a', 0, '\r', 0, '\n', 0, 'b', 0, '\r', 0, '\n'}
+	if string(data) != string(want) {
+		t.Fatalf("unexpected content % X", data)
+	}
+}
+
+func TestLineEndingsRefuseLargeFiles(t *testing.T) {
+	old := LineEndingFileLimit
+	LineEndingFileLimit = 8
+	t.Cleanup(func() { LineEndingFileLimit = old })
+
+	path := filepath.Join(t.TempDir(), "big.log")
+	if err := os.WriteFile(path, []byte("one\r\ntwo\r\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, _, err := FileLineEndings(path); err == nil {
+		t.Fatal("expected counting a file over the limit to fail")
+	}
+	if _, _, err := ConvertLineEndings(path, LineEndingLF); err == nil {
+		t.Fatal("expected converting a file over the limit to fail")
+	}
+	if _, err := os.Stat(path + ".bak"); !os.IsNotExist(err) {
+		t.Fatalf("expected no backup for a refused file, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package fs
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kk-code-lab/rdir/internal/procwatch"
+)
+
+// GitInfo describes the git checkout a file belongs to.
+type GitInfo struct {
+	Root   string // work tree root
+	Remote string // URL of the "origin" remote, "" when there is none
+	Commit string // full HEAD commit hash
+	Branch string // current branch, "" when HEAD is detached
+}
+
+// LookupGit asks git about the checkout containing dir.
+func LookupGit(dir string) (GitInfo, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return GitInfo{}, errors.New("git is not installed")
+	}
+	out, err := gitOutput(dir, "rev-parse", "--show-toplevel", "HEAD")
+	if err != nil {
+		return GitInfo{}, errors.New("not inside a git repository with commits")
+	}
+	fields := strings.Split(out, "\n")
+	if len(fields) < 2 {
+		return GitInfo{}, errors.New("not inside a git repository with commits")
+	}
+	info := GitInfo{Root: filepath.FromSlash(fields[0]), Commit: fields[1]}
+	if branch, err := gitOutput(dir, "symbolic-ref", "--quiet", "--short", "HEAD"); err == nil {
+		info.Branch = branch
+	}
+	if remote, err := gitOutput(dir, "remote", "get-url", "origin"); err == nil {
+		info.Remote = remote
+	}
+	return info, nil
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := procwatch.Run(cmd, procwatch.Query); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// GitWebURL turns a clone URL into the https address of the repository's web
+// page, e.g. "git@github.com:owner/repo.git" -> "https://github.com/owner/repo".
+// It returns "" for remotes without a host (local paths).
+func GitWebURL(remote string) string {
+	remote = strings.TrimSpace(remote)
+	var host, path string
+	if scheme, rest, ok := strings.Cut(remote, "://"); ok {
+		switch scheme {
+		case "https", "http", "ssh", "git", "git+ssh":
+		default:
+			return ""
+		}
+		host, path, _ = strings.Cut(rest, "/")
+		if _, after, found := strings.Cut(host, "@"); found {
+			host = after
+		}
+		if scheme != "https" && scheme != "http" {
+			// ssh://git@host:2222/owner/repo - the port is not the web port.
+			host, _, _ = strings.Cut(host, ":")
+		}
+	} else {
+		// scp-like syntax: [user@]host:owner/repo (a drive letter is no host)
+		hostPart, rest, found := strings.Cut(remote, ":")
+		if !found || len(hostPart) < 2 || strings.ContainsAny(hostPart, `/\`) {
+			return ""
+		}
+		if _, after, found := strings.Cut(hostPart, "@"); found {
+			hostPart = after
+		}
+		host, path = hostPart, rest
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || path == "" {
+		return ""
+	}
+	return "https://" + host + "/" + path
+}
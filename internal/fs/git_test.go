@@ -0,0 +1,21 @@
+package fs
+
+import "testing"
+
+func TestGitWebURL(t *testing.T) {
+	cases := map[string]string{
+		"git@github.com:owner/repo.git":             "https://github.com/owner/repo",
+		"https://github.com/owner/repo.git":         "https://github.com/owner/repo",
+		"https://user@gitlab.com/group/sub/repo":    "https://gitlab.com/group/sub/repo",
+		"ssh://git@git.example.com:2222/team/x.git": "https://git.example.com/team/x",
+		"/srv/git/repo.git":                         "",
+		`C:\src\repo`:                               "",
+		"file:///srv/git/repo.git":                  "",
+		"":                                          "",
+	}
+	for remote, want := range cases {
+		if got := GitWebURL(remote); got != want {
+			t.Errorf("GitWebURL(%q) = %q, want %q", remote, got, want)
+		}
+	}
+}
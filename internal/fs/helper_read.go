@@ -0,0 +1,206 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// helperReadAhead is how far the helper's output is read beyond what was
+// asked for; past that the helper blocks on its pipe until more is needed.
+const helperReadAhead = 1 << 20
+
+// HelperReader streams a file through an external command such as
+// "sudo cat", for files the user cannot open. What the command wrote so far
+// is spooled to a temp file only the user can read (removed from the
+// directory right away where the system allows it), so memory stays flat
+// however large the file is; ReadAt serves it from there, reading further
+// output only as callers ask for it.
+type HelperReader struct {
+	cmd       *exec.Cmd
+	stdout    io.ReadCloser
+	spool     *os.File
+	spoolName string        // still to remove on Close; "" once unlinked
+	exited    chan struct{} // closed once pump returned
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	size   int64 // bytes spooled so far
+	want   int64 // highest offset asked for so far
+	done   bool  // the command's output ended
+	err    error // why the command failed, once done
+	closed bool
+}
+
+// StartHelperRead runs helper with path as its last argument. The command's
+// stdin and stderr are the given terminal files so a password prompt works;
+// its output is read through the returned reader.
+func StartHelperRead(helper []string, path string, stdin, stderr *os.File) (*HelperReader, error) {
+	if len(helper) == 0 {
+		return nil, errors.New("no privileged helper configured")
+	}
+	spool, err := os.CreateTemp("", "rdir-helper-*") // mode 0600
+	if err != nil {
+		return nil, err
+	}
+	spoolName := spool.Name()
+	if runtime.GOOS != "windows" && os.Remove(spoolName) == nil {
+		spoolName = ""
+	}
+	discard := func() {
+		_ = spool.Close()
+		if spoolName != "" {
+			_ = os.Remove(spoolName)
+		}
+	}
+
+	args := append(append([]string(nil), helper[1:]...), path)
+	cmd := exec.Command(helper[0], args...)
+	cmd.Stdin = stdin
+	cmd.Stderr = stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		discard()
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		discard()
+		return nil, err
+	}
+	r := &HelperReader{cmd: cmd, stdout: stdout, spool: spool, spoolName: spoolName, exited: make(chan struct{})}
+	r.cond = sync.NewCond(&r.mu)
+	go r.pump(strings.Join(helper, " "))
+	return r, nil
+}
+
+// pump copies the command's output into the spool file, pausing while it
+// is far enough ahead of the readers. It alone reads the pipe, and
+// cmd.Wait closes it once the command exited.
+func (r *HelperReader) pump(label string) {
+	defer close(r.exited)
+	chunk := make([]byte, 64*1024)
+	var spoolErr error
+	for {
+		r.mu.Lock()
+		for !r.closed && r.size >= r.want+helperReadAhead {
+			r.cond.Wait()
+		}
+		closed, off := r.closed, r.size
+		r.mu.Unlock()
+		if closed {
+			break
+		}
+		n, err := r.stdout.Read(chunk)
+		if n > 0 {
+			if _, spoolErr = r.spool.WriteAt(chunk[:n], off); spoolErr != nil {
+				_ = r.cmd.Process.Kill()
+				break
+			}
+		}
+		r.mu.Lock()
+		r.size += int64(n)
+		r.cond.Broadcast()
+		r.mu.Unlock()
+		if err != nil {
+			break
+		}
+	}
+	waitErr := r.cmd.Wait()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.closed {
+		switch {
+		case spoolErr != nil:
+			r.err = fmt.Errorf("%s: buffering output: %w", label, spoolErr)
+		case waitErr != nil:
+			r.err = fmt.Errorf("%s: %w", label, waitErr)
+		}
+	}
+	r.done = true
+	r.cond.Broadcast()
+}
+
+// Ready waits until the command wrote something or ended, which is when
+// any password prompt is over. It returns the command's failure when it
+// ended without output.
+func (r *HelperReader) Ready() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for r.size == 0 && !r.done {
+		r.cond.Wait()
+	}
+	if r.size == 0 {
+		return r.err
+	}
+	return nil
+}
+
+// ReadAt implements io.ReaderAt, waiting for the command to write up to
+// off+len(p). Past the end of the output it returns io.EOF, or the
+// command's failure.
+func (r *HelperReader) ReadAt(p []byte, off int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	end := off + int64(len(p))
+	if end > r.want {
+		r.want = end
+		r.cond.Broadcast()
+	}
+	for r.size < end && !r.done && !r.closed {
+		r.cond.Wait()
+	}
+	if r.closed {
+		return 0, os.ErrClosed
+	}
+	if off >= r.size {
+		if r.err != nil {
+			return 0, r.err
+		}
+		return 0, io.EOF
+	}
+	avail := p
+	if end > r.size {
+		avail = p[:r.size-off]
+	}
+	n, err := r.spool.ReadAt(avail, off)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return n, err
+	}
+	if n < len(p) {
+		if r.err != nil {
+			return n, r.err
+		}
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Close stops the command and drops what it wrote. It kills the command
+// rather than closing its pipe under pump, then waits for pump to reap it
+// before removing the spool file pump writes to.
+func (r *HelperReader) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	done := r.done
+	r.cond.Broadcast()
+	r.mu.Unlock()
+	if !done && r.cmd.Process != nil {
+		_ = r.cmd.Process.Kill()
+	}
+	<-r.exited
+
+	err := r.spool.Close()
+	if r.spoolName != "" {
+		_ = os.Remove(r.spoolName)
+	}
+	return err
+}
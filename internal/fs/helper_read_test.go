@@ -0,0 +1,107 @@
+//go:build !windows
+
+package fs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"time"
+)
+
+func TestHelperReaderStreamsOutput(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not available")
+	}
+	path := filepath.Join(t.TempDir(), "data.bin")
+	content := bytes.Repeat([]byte("0123456789abcdef"), 3*helperReadAhead/16)
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := StartHelperRead([]string{"cat"}, path, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = r.Close() }()
+	if err := r.Ready(); err != nil {
+		t.Fatalf("Ready: %v", err)
+	}
+
+	head := make([]byte, 32)
+	if n, err := r.ReadAt(head, 0); err != nil || n != len(head) || !bytes.Equal(head, content[:32]) {
+		t.Fatalf("head = %q, %d, %v", head, n, err)
+	}
+	// The helper is held back until more is asked for.
+	r.mu.Lock()
+	buffered := r.size
+	r.mu.Unlock()
+	if buffered > helperReadAhead+64*1024+32 {
+		t.Fatalf("read %d bytes ahead of a 32 byte request", buffered)
+	}
+
+	tail := make([]byte, 100)
+	off := int64(len(content) - 40)
+	n, err := r.ReadAt(tail, off)
+	if n != 40 || !errors.Is(err, io.EOF) || !bytes.Equal(tail[:n], content[off:]) {
+		t.Fatalf("tail = %d bytes, %v", n, err)
+	}
+	if _, err := r.ReadAt(tail, int64(len(content))); !errors.Is(err, io.EOF) {
+		t.Fatalf("past the end: %v", err)
+	}
+	if info, err := r.spool.Stat(); err != nil || info.Mode().Perm() != 0o600 || info.Size() != int64(len(content)) {
+		t.Fatalf("expected the output spooled to a 0600 file, got %v, %v", info, err)
+	}
+	if r.spoolName != "" {
+		t.Fatalf("expected the spool file to be unlinked, found %s", r.spoolName)
+	}
+}
+
+func TestHelperReaderReportsFailedHelper(t *testing.T) {
+	if _, err := exec.LookPath("false"); err != nil {
+		t.Skip("false not available")
+	}
+	r, err := StartHelperRead([]string{"false"}, "/nonexistent", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = r.Close() }()
+	if err := r.Ready(); err == nil {
+		t.Fatalf("expected the helper's failure")
+	}
+	if _, err := StartHelperRead(nil, "/nonexistent", nil, nil); err == nil {
+		t.Fatalf("expected an error without a helper")
+	}
+}
+
+func TestHelperReaderCloseKillsBlockedHelper(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+	// sleep writes nothing, so pump sits in Read on the pipe.
+	r, err := StartHelperRead([]string{"sleep"}, "30", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := make(chan error, 1)
+	go func() { closed <- r.Close() }()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Close did not return while the helper was blocked")
+	}
+	if r.cmd.ProcessState == nil {
+		t.Fatalf("expected the helper reaped before Close returned")
+	}
+	if _, err := r.ReadAt(make([]byte, 1), 0); !errors.Is(err, os.ErrClosed) {
+		t.Fatalf("ReadAt after Close: %v", err)
+	}
+}
@@ -0,0 +1,22 @@
+package fs
+
+import "os"
+
+// LockFile takes an exclusive lock on the file at path, creating it (mode
+// 0600) when missing, and waits while another process holds it. unlock
+// releases it. Callers that replace a file by renaming lock a file next to
+// it, such as "staging.json.lock", since the rename swaps the locked file.
+func LockFile(path string) (unlock func(), err error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return func() {
+		_ = unlockFile(f)
+		_ = f.Close()
+	}, nil
+}
@@ -0,0 +1,15 @@
+//go:build !unix && !windows
+
+package fs
+
+import "os"
+
+// lockFile cannot lock on this platform; writers rely on atomic renames
+// alone.
+func lockFile(*os.File) error {
+	return nil
+}
+
+func unlockFile(*os.File) error {
+	return nil
+}
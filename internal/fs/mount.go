@@ -0,0 +1,29 @@
+package fs
+
+import "strings"
+
+// networkFSTypes are filesystem types served over the network, where every
+// stat or directory read is a round trip. FUSE types are matched without
+// their "fuse." prefix.
+var networkFSTypes = map[string]bool{
+	"nfs": true, "nfs4": true, "cifs": true, "smb": true, "smb2": true, "smb3": true,
+	"smbfs": true, "sshfs": true, "afpfs": true, "webdav": true, "davfs": true,
+	"9p": true, "afs": true, "ceph": true, "glusterfs": true, "lustre": true,
+	"rclone": true, "s3fs": true, "gcsfuse": true,
+}
+
+// NetworkMount reports whether path lives on a network filesystem and, if so,
+// its type (e.g. "nfs4", "cifs", "sshfs", "smb").
+func NetworkMount(path string) (string, bool) {
+	fsType := mountFSType(path)
+	if fsType == "" {
+		return "", false
+	}
+	return fsType, isNetworkFSType(fsType)
+}
+
+func isNetworkFSType(fsType string) bool {
+	fsType = strings.ToLower(fsType)
+	fsType = strings.TrimPrefix(fsType, "fuse.")
+	return networkFSTypes[fsType]
+}
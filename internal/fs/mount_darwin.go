@@ -0,0 +1,34 @@
+package fs
+
+import "golang.org/x/sys/unix"
+
+// readMountTable lists the mounted filesystems with getfsstat. MNT_NOWAIT
+// returns the cached statistics, so a hung network mount cannot block it.
+func readMountTable() []mountEntry {
+	n, err := unix.Getfsstat(nil, unix.MNT_NOWAIT)
+	if err != nil || n <= 0 {
+		return nil
+	}
+	buf := make([]unix.Statfs_t, n)
+	n, err = unix.Getfsstat(buf, unix.MNT_NOWAIT)
+	if err != nil {
+		return nil
+	}
+	entries := make([]mountEntry, 0, n)
+	for i := range buf[:n] {
+		entries = append(entries, mountEntry{
+			point:  cString(buf[i].Mntonname[:]),
+			fsType: cString(buf[i].Fstypename[:]),
+		})
+	}
+	return entries
+}
+
+func cString(b []byte) string {
+	for i, c := range b {
+		if c == 0 {
+			return string(b[:i])
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,48 @@
+package fs
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// readMountTable reads the mounts of this process from /proc/self/mountinfo.
+func readMountTable() []mountEntry {
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+	return parseMountInfo(f)
+}
+
+// parseMountInfo reads mountinfo lines: "id parent dev root mountpoint
+// options [optional...] - type source superoptions"; spaces in the mount
+// point are escaped as \040.
+func parseMountInfo(r io.Reader) []mountEntry {
+	var entries []mountEntry
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		sep := -1
+		for i, field := range fields {
+			if field == "-" {
+				sep = i
+				break
+			}
+		}
+		if len(fields) < 5 || sep < 0 || sep+1 >= len(fields) {
+			continue
+		}
+		entries = append(entries, mountEntry{point: unescapeMountPoint(fields[4]), fsType: fields[sep+1]})
+	}
+	return entries
+}
+
+func unescapeMountPoint(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	return strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`).Replace(s)
+}
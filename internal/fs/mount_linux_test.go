@@ -0,0 +1,41 @@
+package fs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMountFSTypeFromInfo(t *testing.T) {
+	info := strings.Join([]string{
+		"22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw",
+		"40 22 0:35 / /mnt/share rw,relatime shared:20 - nfs4 server:/export rw,vers=4.2",
+		`41 22 0:36 / /mnt/my\040files rw,nosuid - fuse.sshfs user@host:/home rw`,
+		"42 40 0:37 / /mnt/share/local rw - tmpfs tmpfs rw",
+	}, "\n")
+
+	cases := map[string]string{
+		"/home/user":             "ext4",
+		"/mnt/share/projects":    "nfs4",
+		"/mnt/sharex":            "ext4",
+		"/mnt/my files/docs":     "fuse.sshfs",
+		"/mnt/share/local/cache": "tmpfs",
+	}
+	for path, want := range cases {
+		if got := lookupMount(parseMountInfo(strings.NewReader(info)), path); got != want {
+			t.Errorf("lookupMount(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestIsNetworkFSType(t *testing.T) {
+	for _, fsType := range []string{"nfs4", "cifs", "fuse.sshfs", "smbfs", "SMB3"} {
+		if !isNetworkFSType(fsType) {
+			t.Errorf("expected %q to be a network filesystem", fsType)
+		}
+	}
+	for _, fsType := range []string{"ext4", "tmpfs", "fuse.gocryptfs", "apfs", ""} {
+		if isNetworkFSType(fsType) {
+			t.Errorf("did not expect %q to be a network filesystem", fsType)
+		}
+	}
+}
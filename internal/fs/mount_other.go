@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !windows
+
+package fs
+
+// mountFSType is not implemented on this platform; no path is treated as a
+// network mount.
+func mountFSType(string) string {
+	return ""
+}
@@ -0,0 +1,78 @@
+//go:build linux || darwin
+
+package fs
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// mountTableMaxAge is how long the mount table is used before it is read
+// again. Refreshes run in the background; lookups meanwhile use the old one.
+const mountTableMaxAge = 10 * time.Second
+
+// mountEntry is one line of the mount table.
+type mountEntry struct {
+	point  string
+	fsType string
+}
+
+// mountTable caches the system mount table, so classifying a directory is a
+// lookup rather than a read of /proc (or a statfs call) on every load.
+type mountTable struct {
+	mu         sync.Mutex
+	entries    []mountEntry
+	readAt     time.Time
+	refreshing bool
+}
+
+var mounts = &mountTable{}
+
+// fsType returns the type of the mount holding path. The first call reads
+// the table in place; later calls start a background refresh once it is
+// older than mountTableMaxAge.
+func (t *mountTable) fsType(path string) string {
+	t.mu.Lock()
+	if t.readAt.IsZero() {
+		t.entries, t.readAt = readMountTable(), time.Now()
+	} else if !t.refreshing && time.Since(t.readAt) > mountTableMaxAge {
+		t.refreshing = true
+		go t.refresh()
+	}
+	entries := t.entries
+	t.mu.Unlock()
+	return lookupMount(entries, path)
+}
+
+func (t *mountTable) refresh() {
+	entries := readMountTable()
+	t.mu.Lock()
+	t.entries, t.readAt, t.refreshing = entries, time.Now(), false
+	t.mu.Unlock()
+}
+
+// mountFSType returns the filesystem type of the mount holding path.
+func mountFSType(path string) string {
+	return mounts.fsType(path)
+}
+
+// lookupMount picks the longest mount point containing path.
+func lookupMount(entries []mountEntry, path string) string {
+	path = filepath.Clean(path)
+	best, bestType := -1, ""
+	for _, entry := range entries {
+		if !pathWithin(path, entry.point) || len(entry.point) < best {
+			continue
+		}
+		best, bestType = len(entry.point), entry.fsType
+	}
+	return bestType
+}
+
+func pathWithin(path, dir string) bool {
+	if dir == "/" || path == dir {
+		return true
+	}
+	return len(path) > len(dir) && path[:len(dir)] == dir && path[len(dir)] == '/'
+}
@@ -0,0 +1,37 @@
+//go:build linux || darwin
+
+package fs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMountTableRefreshesInBackground(t *testing.T) {
+	stale := time.Now().Add(-2 * mountTableMaxAge)
+	table := &mountTable{entries: []mountEntry{{point: "/", fsType: "nfs"}}, readAt: stale}
+
+	// A stale table still answers at once and is read again behind it.
+	if got := table.fsType("/srv/data"); got != "nfs" {
+		t.Fatalf("fsType = %q, want the cached nfs", got)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		table.mu.Lock()
+		refreshed := !table.refreshing && table.readAt.After(stale)
+		table.mu.Unlock()
+		if refreshed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("mount table was not refreshed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	fresh := &mountTable{entries: []mountEntry{{point: "/", fsType: "nfs"}}, readAt: time.Now()}
+	fresh.fsType("/srv/data")
+	if fresh.refreshing {
+		t.Fatal("a fresh table should not be read again")
+	}
+}
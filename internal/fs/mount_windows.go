@@ -0,0 +1,28 @@
+package fs
+
+import (
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// mountFSType reports "smb" for UNC paths and mapped network drives and ""
+// for everything else.
+func mountFSType(path string) string {
+	volume := filepath.VolumeName(path)
+	if strings.HasPrefix(volume, `\\`) {
+		return "smb"
+	}
+	if volume == "" {
+		return ""
+	}
+	root, err := windows.UTF16PtrFromString(volume + `\`)
+	if err != nil {
+		return ""
+	}
+	if windows.GetDriveType(root) == windows.DRIVE_REMOTE {
+		return "smb"
+	}
+	return ""
+}
@@ -0,0 +1,215 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// CopyPath copies src to dst. Directories are copied recursively, symlinks are
+// recreated (not followed) and regular files keep their permission bits. dst
+// must not exist yet.
+func CopyPath(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("%s already exists", dst)
+	}
+	if isWithin(dst, src) {
+		return fmt.Errorf("cannot copy %s into itself", src)
+	}
+	return copyEntry(src, dst, info)
+}
+
+// MovePath renames src to dst, falling back to copy+remove when the two paths
+// live on different filesystems. dst must not exist yet.
+func MovePath(src, dst string) error {
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("%s already exists", dst)
+	}
+	if isWithin(dst, src) {
+		return fmt.Errorf("cannot move %s into itself", src)
+	}
+	err := os.Rename(src, dst)
+	if err == nil {
+		return nil
+	}
+	if !isCrossDevice(err) {
+		return err
+	}
+	if err := CopyPath(src, dst); err != nil {
+		_ = os.RemoveAll(dst)
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+// UniqueDestination returns a path inside dir for name that does not exist
+// yet, appending " (1)", " (2)", ... before the extension when needed.
+func UniqueDestination(dir, name string) string {
+	candidate := filepath.Join(dir, name)
+	if _, err := os.Lstat(candidate); errors.Is(err, os.ErrNotExist) {
+		return candidate
+	}
+
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	if stem == "" {
+		// Dotfiles such as ".env" have no stem; keep the whole name.
+		stem, ext = name, ""
+	}
+	for i := 1; ; i++ {
+		candidate = filepath.Join(dir, fmt.Sprintf("%s (%d)%s", stem, i, ext))
+		if _, err := os.Lstat(candidate); errors.Is(err, os.ErrNotExist) {
+			return candidate
+		}
+	}
+}
+
+// DefaultRenamePattern names renamed copies the way UniqueDestination does.
+const DefaultRenamePattern = "{name} ({n}){ext}"
+
+// CheckRenamePattern rejects patterns that cannot name an entry in the
+// same directory.
+func CheckRenamePattern(pattern string) error {
+	name := expandRenamePattern(pattern, "name", ".ext", 1)
+	if strings.TrimSpace(name) == "" || name == "." || name == ".." {
+		return errors.New("the pattern gives an empty name")
+	}
+	if strings.ContainsAny(name, `/\`) {
+		return errors.New("the pattern must not contain path separators")
+	}
+	if !strings.Contains(pattern, "{n}") && name == "name.ext" {
+		return errors.New("the pattern keeps the name unchanged; add {n} or other text")
+	}
+	return nil
+}
+
+// PatternDestination returns a path inside dir for name that does not exist
+// yet, built from pattern: {name} is the name without its extension, {ext}
+// the extension (none for directories) and {n} a counter from 1. When the
+// pattern has no {n} and its result exists, " (n)" is added to that.
+func PatternDestination(dir, name, pattern string, isDir bool) string {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	if isDir || stem == "" {
+		stem, ext = name, ""
+	}
+	if !strings.Contains(pattern, "{n}") {
+		return UniqueDestination(dir, expandRenamePattern(pattern, stem, ext, 0))
+	}
+	for i := 1; ; i++ {
+		candidate := filepath.Join(dir, expandRenamePattern(pattern, stem, ext, i))
+		if _, err := os.Lstat(candidate); errors.Is(err, os.ErrNotExist) {
+			return candidate
+		}
+	}
+}
+
+func expandRenamePattern(pattern, stem, ext string, n int) string {
+	return strings.NewReplacer("{name}", stem, "{ext}", ext, "{n}", fmt.Sprint(n)).Replace(pattern)
+}
+
+// ReplacePath runs op (CopyPath or MovePath) from src onto the existing dst.
+// The old dst is set aside first and put back when op fails, so a failed
+// replace loses nothing.
+func ReplacePath(src, dst string, op func(src, dst string) error) error {
+	if isWithin(src, dst) {
+		return fmt.Errorf("cannot replace %s with something inside it", dst)
+	}
+	aside := UniqueDestination(filepath.Dir(dst), "."+filepath.Base(dst)+".replaced")
+	if err := os.Rename(dst, aside); err != nil {
+		return err
+	}
+	if err := op(src, dst); err != nil {
+		_ = os.RemoveAll(dst)
+		if restoreErr := os.Rename(aside, dst); restoreErr != nil {
+			return fmt.Errorf("%w (the old %s is kept as %s)", err, filepath.Base(dst), filepath.Base(aside))
+		}
+		return err
+	}
+	return os.RemoveAll(aside)
+}
+
+func copyEntry(src, dst string, info os.FileInfo) error {
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dst)
+	case info.IsDir():
+		return copyDir(src, dst, info)
+	case info.Mode().IsRegular():
+		return copyFile(src, dst, info)
+	default:
+		return fmt.Errorf("cannot copy special file %s", src)
+	}
+}
+
+func copyDir(src, dst string, info os.FileInfo) error {
+	if err := os.Mkdir(dst, info.Mode().Perm()|0o700); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		childInfo, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if err := copyEntry(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name()), childInfo); err != nil {
+			return err
+		}
+	}
+	return os.Chmod(dst, info.Mode().Perm())
+}
+
+func copyFile(src, dst string, info os.FileInfo) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = in.Close()
+	}()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := out.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	_, err = io.Copy(out, in)
+	return err
+}
+
+// isWithin reports whether path equals root or lives below it.
+func isWithin(path, root string) bool {
+	rel, err := filepath.Rel(filepath.Clean(root), filepath.Clean(path))
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+func isCrossDevice(err error) bool {
+	var linkErr *os.LinkError
+	if errors.As(err, &linkErr) {
+		return errors.Is(linkErr.Err, syscall.EXDEV)
+	}
+	return false
+}
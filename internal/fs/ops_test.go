@@ -0,0 +1,183 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyPathCopiesDirectoryTree(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	if err := os.MkdirAll(filepath.Join(src, "nested"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "nested", "a.txt"), []byte("hello"), 0o640); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	dst := filepath.Join(root, "dst")
+	if err := CopyPath(src, dst); err != nil {
+		t.Fatalf("CopyPath: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dst, "nested", "a.txt"))
+	if err != nil || string(data) != "hello" {
+		t.Fatalf("copied file = %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(src, "nested", "a.txt")); err != nil {
+		t.Fatalf("source should remain after copy: %v", err)
+	}
+}
+
+func TestCopyPathRejectsCopyIntoItself(t *testing.T) {
+	src := t.TempDir()
+	if err := CopyPath(src, filepath.Join(src, "inner")); err == nil {
+		t.Fatal("expected error when copying a directory into itself")
+	}
+}
+
+func TestMovePathRenamesAndRefusesOverwrite(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "a.txt")
+	existing := filepath.Join(root, "b.txt")
+	for _, p := range []string{src, existing} {
+		if err := os.WriteFile(p, []byte(p), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	if err := MovePath(src, existing); err == nil {
+		t.Fatal("expected error when destination exists")
+	}
+
+	dst := filepath.Join(root, "c.txt")
+	if err := MovePath(src, dst); err != nil {
+		t.Fatalf("MovePath: %v", err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Fatalf("source should be gone, stat err = %v", err)
+	}
+	if _, err := os.Stat(dst); err != nil {
+		t.Fatalf("destination missing: %v", err)
+	}
+}
+
+func TestUniqueDestinationAddsCounter(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"report.txt", "report (1).txt", ".env"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	tests := map[string]string{
+		"fresh.txt":  "fresh.txt",
+		"report.txt": "report (2).txt",
+		".env":       ".env (1)",
+	}
+	for name, want := range tests {
+		if got := filepath.Base(UniqueDestination(dir, name)); got != want {
+			t.Errorf("UniqueDestination(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestPatternDestination(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"report (1).txt", "report-copy.txt", "site-1"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name, pattern string
+		isDir         bool
+		want          string
+	}{
+		{"report.txt", DefaultRenamePattern, false, "report (2).txt"},
+		{"report.txt", "{name}-copy{ext}", false, "report-copy (1).txt"},
+		{"report.txt", "{n}_{name}{ext}", false, "1_report.txt"},
+		{"site.v2", "{name}-{n}", true, "site.v2-1"},
+		{"site", "{name}-{n}", true, "site-2"},
+		{".env", DefaultRenamePattern, false, ".env (1)"},
+	}
+	for _, tt := range tests {
+		if got := filepath.Base(PatternDestination(dir, tt.name, tt.pattern, tt.isDir)); got != tt.want {
+			t.Errorf("PatternDestination(%q, %q) = %q, want %q", tt.name, tt.pattern, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "{name}{ext}", "{name}/{n}", "  "} {
+		if err := CheckRenamePattern(bad); err == nil {
+			t.Errorf("CheckRenamePattern(%q) accepted", bad)
+		}
+	}
+	if err := CheckRenamePattern("{name}.old{ext}"); err != nil {
+		t.Errorf("CheckRenamePattern rejected a valid pattern: %v", err)
+	}
+}
+
+func TestReplacePathKeepsOldEntryOnFailure(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "src.txt")
+	dst := filepath.Join(root, "dst.txt")
+	if err := os.WriteFile(src, []byte("new"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dst, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	failing := func(src, dst string) error {
+		if err := os.WriteFile(dst, []byte("partial"), 0o644); err != nil {
+			return err
+		}
+		return os.ErrPermission
+	}
+	if err := ReplacePath(src, dst, failing); err == nil {
+		t.Fatal("expected the failing replace to report its error")
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "old" {
+		t.Fatalf("after a failed replace dst = %q, want the old content", data)
+	}
+
+	if err := ReplacePath(src, dst, MovePath); err != nil {
+		t.Fatalf("ReplacePath: %v", err)
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "new" {
+		t.Fatalf("dst = %q, want the new content", data)
+	}
+	if entries, _ := os.ReadDir(root); len(entries) != 1 {
+		t.Fatalf("expected only dst to remain, got %v", entries)
+	}
+
+	if err := ReplacePath(filepath.Join(root, "dst.txt", "x"), dst, CopyPath); err == nil {
+		t.Fatal("expected replacing an entry with its own content to be refused")
+	}
+}
+
+func TestAlternateStreamsReportsAppleDoubleFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "photo.jpg")
+	for _, p := range []string{file, filepath.Join(dir, "._photo.jpg")} {
+		if err := os.WriteFile(p, []byte("data"), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	streams := AlternateStreams(file)
+	if len(streams) != 1 || streams[0].Kind != StreamAppleDouble || streams[0].Name != "._photo.jpg" || streams[0].Size != 4 {
+		t.Fatalf("unexpected streams: %+v", streams)
+	}
+	if got := streams[0].Label(); got != "._photo.jpg (AppleDouble)" {
+		t.Fatalf("Label() = %q", got)
+	}
+	if streams := AlternateStreams(filepath.Join(dir, "._photo.jpg")); len(streams) != 0 {
+		t.Fatalf("an AppleDouble file has no sidecar of its own, got %+v", streams)
+	}
+	if !SameVolume(file, dir) {
+		t.Fatalf("expected a file and its directory to share a volume")
+	}
+}
@@ -0,0 +1,147 @@
+package fs
+
+import (
+	"strings"
+)
+
+// PathMapping rewrites paths under From to the same place under To, e.g. a
+// host directory bind-mounted into a container to where the container sees
+// it.
+type PathMapping struct {
+	From string
+	To   string
+}
+
+// Apply returns path rewritten by the mapping, if it lies under From.
+func (m PathMapping) Apply(path string) (string, bool) {
+	from := trimTrailingSeparators(m.From)
+	if from == "" || !strings.HasPrefix(path, from) {
+		return "", false
+	}
+	rest := path[len(from):]
+	if rest != "" && !isSeparator(rest[0]) && !isSeparator(from[len(from)-1]) {
+		return "", false // "/srv/app" must not match "/srv/apple"
+	}
+	rest = strings.TrimLeft(rest, `/\`)
+	to := trimTrailingSeparators(m.To)
+	if rest == "" {
+		return to, true
+	}
+	sep := "/"
+	if strings.Contains(to, `\`) && !strings.Contains(to, "/") {
+		sep = `\`
+		rest = strings.ReplaceAll(rest, "/", `\`)
+	} else {
+		rest = strings.ReplaceAll(rest, `\`, "/")
+	}
+	if strings.HasSuffix(to, sep) {
+		return to + rest, true
+	}
+	return to + sep + rest, true
+}
+
+// PathForm is a path written for one environment.
+type PathForm struct {
+	Label string
+	Path  string
+}
+
+// PathContext describes the environment rdir runs in, for TranslatePath.
+type PathContext struct {
+	Windows   bool   // running on Windows, where WSL paths live under \\wsl$
+	WSLDistro string // WSL_DISTRO_NAME when running inside WSL
+	Mappings  []PathMapping
+}
+
+// TranslatePath lists the ways path can be written for the environments
+// around rdir: the path as is first, then its WSL or Windows form and any
+// configured container mappings. Forms that come out the same are dropped.
+func TranslatePath(path string, ctx PathContext) []PathForm {
+	forms := []PathForm{{Label: "as is", Path: path}}
+	add := func(label, translated string) {
+		for _, form := range forms {
+			if form.Path == translated {
+				return
+			}
+		}
+		forms = append(forms, PathForm{Label: label, Path: translated})
+	}
+	switch {
+	case ctx.Windows:
+		if wsl, ok := WindowsToWSL(path); ok {
+			add("wsl", wsl)
+		}
+	case ctx.WSLDistro != "":
+		if win, ok := WSLToWindows(path, ctx.WSLDistro); ok {
+			add("windows", win)
+		}
+	}
+	for _, mapping := range ctx.Mappings {
+		if mapped, ok := mapping.Apply(path); ok {
+			add("mapped", mapped)
+		}
+	}
+	return forms
+}
+
+// WindowsToWSL writes a Windows path the way a WSL shell sees it: drive
+// paths move under /mnt (C:\Users → /mnt/c/Users) and \\wsl$\<distro>
+// or \\wsl.localhost\<distro> paths lose that prefix.
+func WindowsToWSL(path string) (string, bool) {
+	path = strings.ReplaceAll(path, "/", `\`)
+	if strings.HasPrefix(path, `\\`) {
+		parts := strings.SplitN(path[2:], `\`, 3)
+		if len(parts) < 2 || parts[1] == "" {
+			return "", false
+		}
+		if host := strings.ToLower(parts[0]); host != "wsl$" && host != "wsl.localhost" {
+			return "", false
+		}
+		if len(parts) == 2 {
+			return "/", true
+		}
+		return "/" + strings.ReplaceAll(strings.TrimRight(parts[2], `\`), `\`, "/"), true
+	}
+	if len(path) < 2 || path[1] != ':' || !isDriveLetter(path[0]) {
+		return "", false
+	}
+	drive := "/mnt/" + strings.ToLower(path[:1])
+	rest := strings.Trim(path[2:], `\`)
+	if rest == "" {
+		return drive, true
+	}
+	return drive + "/" + strings.ReplaceAll(rest, `\`, "/"), true
+}
+
+// WSLToWindows writes a path inside WSL the way Windows sees it: /mnt/c/...
+// becomes C:\..., anything else lives under \\wsl.localhost\<distro>.
+func WSLToWindows(path, distro string) (string, bool) {
+	if !strings.HasPrefix(path, "/") {
+		return "", false
+	}
+	if rest, ok := strings.CutPrefix(path, "/mnt/"); ok && len(rest) > 0 && isDriveLetter(rest[0]) && (len(rest) == 1 || rest[1] == '/') {
+		drive := strings.ToUpper(rest[:1]) + `:\`
+		return drive + strings.ReplaceAll(strings.Trim(rest[1:], "/"), "/", `\`), true
+	}
+	if distro == "" {
+		return "", false
+	}
+	return `\\wsl.localhost\` + distro + strings.ReplaceAll(strings.TrimRight(path, "/"), "/", `\`), true
+}
+
+func isDriveLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isSeparator(c byte) bool {
+	return c == '/' || c == '\\'
+}
+
+// trimTrailingSeparators drops trailing slashes but keeps a root such as
+// "/" or "C:\".
+func trimTrailingSeparators(path string) string {
+	for len(path) > 1 && isSeparator(path[len(path)-1]) && !(len(path) == 3 && path[1] == ':') {
+		path = path[:len(path)-1]
+	}
+	return path
+}
@@ -0,0 +1,87 @@
+package fs
+
+import "testing"
+
+func TestWindowsToWSL(t *testing.T) {
+	cases := []struct {
+		in, want string
+		ok       bool
+	}{
+		{`C:\Users\kk\notes.txt`, "/mnt/c/Users/kk/notes.txt", true},
+		{`d:\`, "/mnt/d", true},
+		{`\\wsl$\Ubuntu\home\kk\src`, "/home/kk/src", true},
+		{`\\wsl.localhost\Debian`, "/", true},
+		{`\\fileserver\share\doc`, "", false},
+		{`relative\path`, "", false},
+	}
+	for _, tc := range cases {
+		got, ok := WindowsToWSL(tc.in)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("WindowsToWSL(%q) = %q, %v; want %q, %v", tc.in, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestWSLToWindows(t *testing.T) {
+	cases := []struct {
+		in, distro, want string
+		ok               bool
+	}{
+		{"/mnt/c/Users/kk/notes.txt", "Ubuntu", `C:\Users\kk\notes.txt`, true},
+		{"/mnt/d", "Ubuntu", `D:\`, true},
+		{"/home/kk/src/", "Ubuntu", `\\wsl.localhost\Ubuntu\home\kk\src`, true},
+		{"/mnt/wsl/shared", "Ubuntu", `\\wsl.localhost\Ubuntu\mnt\wsl\shared`, true},
+		{"/home/kk", "", "", false},
+	}
+	for _, tc := range cases {
+		got, ok := WSLToWindows(tc.in, tc.distro)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("WSLToWindows(%q, %q) = %q, %v; want %q, %v", tc.in, tc.distro, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestPathMappingApply(t *testing.T) {
+	mapping := PathMapping{From: "/var/lib/docker/volumes/pg/_data/", To: "/var/lib/postgresql/data"}
+	if got, ok := mapping.Apply("/var/lib/docker/volumes/pg/_data/base/1"); !ok || got != "/var/lib/postgresql/data/base/1" {
+		t.Fatalf("Apply = %q, %v", got, ok)
+	}
+	if got, ok := mapping.Apply("/var/lib/docker/volumes/pg/_data"); !ok || got != "/var/lib/postgresql/data" {
+		t.Fatalf("Apply(root) = %q, %v", got, ok)
+	}
+	if _, ok := (PathMapping{From: "/srv/app", To: "/app"}).Apply("/srv/apple/x"); ok {
+		t.Fatalf("expected /srv/app not to match /srv/apple")
+	}
+	if got, ok := (PathMapping{From: "/", To: `C:\rootfs`}).Apply("/etc/hosts"); !ok || got != `C:\rootfs\etc\hosts` {
+		t.Fatalf("Apply to a Windows root = %q, %v", got, ok)
+	}
+}
+
+func TestTranslatePathListsEachFormOnce(t *testing.T) {
+	ctx := PathContext{
+		WSLDistro: "Ubuntu",
+		Mappings: []PathMapping{
+			{From: "/home/kk/src", To: "/workspace"},
+			{From: "/home/kk/src", To: "/workspace"},
+		},
+	}
+	forms := TranslatePath("/home/kk/src/main.go", ctx)
+	want := []PathForm{
+		{Label: "as is", Path: "/home/kk/src/main.go"},
+		{Label: "windows", Path: `\\wsl.localhost\Ubuntu\home\kk\src\main.go`},
+		{Label: "mapped", Path: "/workspace/main.go"},
+	}
+	if len(forms) != len(want) {
+		t.Fatalf("TranslatePath = %+v", forms)
+	}
+	for i := range want {
+		if forms[i] != want[i] {
+			t.Fatalf("form %d = %+v, want %+v", i, forms[i], want[i])
+		}
+	}
+
+	forms = TranslatePath(`C:\src`, PathContext{Windows: true})
+	if len(forms) != 2 || forms[1].Path != "/mnt/c/src" {
+		t.Fatalf("expected the WSL form on Windows, got %+v", forms)
+	}
+}
@@ -0,0 +1,8 @@
+package fs
+
+// OnBattery reports whether the machine is running on battery power. known
+// is false where the power source cannot be determined, e.g. on desktops
+// without a battery or on unsupported platforms.
+func OnBattery() (onBattery, known bool) {
+	return powerSource()
+}
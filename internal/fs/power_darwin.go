@@ -0,0 +1,28 @@
+package fs
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+
+	"github.com/kk-code-lab/rdir/internal/procwatch"
+)
+
+// powerSource asks pmset, whose first line reads "Now drawing from 'Battery
+// Power'" or "'AC Power'".
+func powerSource() (bool, bool) {
+	cmd := exec.Command("pmset", "-g", "batt")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := procwatch.Run(cmd, procwatch.Query); err != nil {
+		return false, false
+	}
+	first, _, _ := strings.Cut(out.String(), "\n")
+	switch {
+	case strings.Contains(first, "'Battery Power'"):
+		return true, true
+	case strings.Contains(first, "'AC Power'"):
+		return false, true
+	}
+	return false, false
+}
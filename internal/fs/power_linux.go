@@ -0,0 +1,45 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func powerSource() (bool, bool) {
+	return powerSourceFromSysfs("/sys/class/power_supply")
+}
+
+// powerSourceFromSysfs reads the power_supply class: any online mains or USB
+// supply means AC power, otherwise a discharging battery means battery power.
+func powerSourceFromSysfs(root string) (bool, bool) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return false, false
+	}
+	read := func(dir, name string) string {
+		data, err := os.ReadFile(filepath.Join(root, dir, name))
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(data))
+	}
+	hasBattery, discharging := false, false
+	for _, entry := range entries {
+		switch read(entry.Name(), "type") {
+		case "Mains", "USB":
+			if read(entry.Name(), "online") == "1" {
+				return false, true
+			}
+		case "Battery":
+			if read(entry.Name(), "scope") == "Device" {
+				continue // peripherals such as mice report their own battery
+			}
+			hasBattery = true
+			if read(entry.Name(), "status") == "Discharging" {
+				discharging = true
+			}
+		}
+	}
+	return discharging, hasBattery
+}
@@ -0,0 +1,40 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPowerSourceFromSysfs(t *testing.T) {
+	supply := func(t *testing.T, root, name string, attrs map[string]string) {
+		t.Helper()
+		dir := filepath.Join(root, name)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		for attr, value := range attrs {
+			if err := os.WriteFile(filepath.Join(dir, attr), []byte(value+"\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	desktop := t.TempDir()
+	supply(t, desktop, "hidpp_battery_0", map[string]string{"type": "Battery", "scope": "Device", "status": "Discharging"})
+	if onBattery, known := powerSourceFromSysfs(desktop); onBattery || known {
+		t.Fatalf("desktop with a wireless mouse: got %v, %v", onBattery, known)
+	}
+
+	laptop := t.TempDir()
+	supply(t, laptop, "AC", map[string]string{"type": "Mains", "online": "0"})
+	supply(t, laptop, "BAT0", map[string]string{"type": "Battery", "status": "Discharging"})
+	if onBattery, known := powerSourceFromSysfs(laptop); !onBattery || !known {
+		t.Fatalf("unplugged laptop: got %v, %v", onBattery, known)
+	}
+
+	supply(t, laptop, "AC", map[string]string{"online": "1"})
+	if onBattery, known := powerSourceFromSysfs(laptop); onBattery || !known {
+		t.Fatalf("plugged-in laptop: got %v, %v", onBattery, known)
+	}
+}
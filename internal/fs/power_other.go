@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !windows
+
+package fs
+
+// powerSource is not implemented on this platform.
+func powerSource() (bool, bool) {
+	return false, false
+}
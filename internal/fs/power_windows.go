@@ -0,0 +1,37 @@
+package fs
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var procGetSystemPowerStatus = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetSystemPowerStatus")
+
+// systemPowerStatus mirrors SYSTEM_POWER_STATUS.
+type systemPowerStatus struct {
+	ACLineStatus        byte
+	BatteryFlag         byte
+	BatteryLifePercent  byte
+	SystemStatusFlag    byte
+	BatteryLifeTime     uint32
+	BatteryFullLifeTime uint32
+}
+
+func powerSource() (bool, bool) {
+	var status systemPowerStatus
+	if ok, _, _ := procGetSystemPowerStatus.Call(uintptr(unsafe.Pointer(&status))); ok == 0 {
+		return false, false
+	}
+	const noSystemBattery = 128
+	if status.BatteryFlag&noSystemBattery != 0 {
+		return false, false
+	}
+	switch status.ACLineStatus {
+	case 0:
+		return true, true
+	case 1:
+		return false, true
+	}
+	return false, false
+}
@@ -0,0 +1,43 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+)
+
+// readDirBatchSize bounds how many entries are read between cancellation checks.
+const readDirBatchSize = 256
+
+// ReadDirContext reads the directory entries of path like os.ReadDir, checking
+// ctx between batches so huge or slow directories can be abandoned. Unlike
+// os.ReadDir the entries are returned in filesystem order.
+func ReadDirContext(ctx context.Context, path string) ([]os.DirEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var entries []os.DirEntry
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		batch, err := f.ReadDir(readDirBatchSize)
+		entries = append(entries, batch...)
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return entries, err
+		}
+	}
+}
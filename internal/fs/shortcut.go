@@ -0,0 +1,351 @@
+package fs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+)
+
+// shortcutMaxSize caps how much of a .desktop or .lnk file is parsed.
+const shortcutMaxSize = 64 * 1024
+
+// Shortcut is what a desktop shortcut file points at.
+type Shortcut struct {
+	Kind    string // "desktop" or "lnk"
+	Name    string
+	Target  string // file or directory the shortcut opens, "" when unknown
+	Exec    string // command line it runs (.desktop Exec, .lnk target plus arguments)
+	Icon    string
+	WorkDir string
+	Comment string
+}
+
+// ReadShortcut parses path when it is a Linux .desktop entry or a Windows
+// .lnk shell link.
+func ReadShortcut(path string) (Shortcut, bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".desktop" && ext != ".lnk" {
+		return Shortcut{}, false
+	}
+	data, err := ReadFileHead(path, shortcutMaxSize)
+	if err != nil {
+		return Shortcut{}, false
+	}
+	if ext == ".desktop" {
+		return parseDesktopEntry(data)
+	}
+	return parseShellLink(data)
+}
+
+// parseDesktopEntry reads the [Desktop Entry] group of a freedesktop.org
+// desktop file. The target is the URL of a Link entry, or the program an
+// Application entry runs when it is given as an absolute path.
+func parseDesktopEntry(data []byte) (Shortcut, bool) {
+	values := make(map[string]string)
+	inEntry := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			inEntry = line == "[Desktop Entry]"
+			continue
+		}
+		if !inEntry {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		// Localized keys (Name[de]) are skipped in favor of the default.
+		key = strings.TrimSpace(key)
+		if _, seen := values[key]; !seen {
+			values[key] = strings.TrimSpace(value)
+		}
+	}
+	if len(values) == 0 {
+		return Shortcut{}, false
+	}
+
+	sc := Shortcut{
+		Kind:    "desktop",
+		Name:    values["Name"],
+		Exec:    values["Exec"],
+		Icon:    values["Icon"],
+		WorkDir: values["Path"],
+		Comment: values["Comment"],
+	}
+	switch values["Type"] {
+	case "Link":
+		sc.Target = desktopURLPath(values["URL"])
+	default:
+		if program := desktopExecProgram(sc.Exec); filepath.IsAbs(program) {
+			sc.Target = program
+		} else if try := values["TryExec"]; filepath.IsAbs(try) {
+			sc.Target = try
+		}
+	}
+	return sc, true
+}
+
+// desktopURLPath turns a file:// URL or a plain path into a path; other
+// URLs have no local target.
+func desktopURLPath(raw string) string {
+	if strings.HasPrefix(raw, "/") {
+		return raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "file" || u.Path == "" {
+		return ""
+	}
+	return u.Path
+}
+
+// desktopExecProgram returns the program of an Exec line: its first word,
+// which may be double-quoted.
+func desktopExecProgram(exec string) string {
+	exec = strings.TrimSpace(exec)
+	if strings.HasPrefix(exec, `"`) {
+		end := strings.Index(exec[1:], `"`)
+		if end < 0 {
+			return ""
+		}
+		return exec[1 : end+1]
+	}
+	program, _, _ := strings.Cut(exec, " ")
+	return program
+}
+
+// Shell link (MS-SHLLINK) header flags.
+const (
+	lnkHasTargetIDList = 1 << iota
+	lnkHasLinkInfo
+	lnkHasName
+	lnkHasRelativePath
+	lnkHasWorkingDir
+	lnkHasArguments
+	lnkHasIconLocation
+	lnkIsUnicode
+)
+
+const (
+	lnkHeaderSize       = 0x4c
+	lnkVolumeIDAndPath  = 1
+	lnkNetworkAndSuffix = 2
+)
+
+var lnkCLSID = []byte{0x01, 0x14, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0xc0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46}
+
+// parseShellLink reads the target path and string data of a Windows shell
+// link. The target comes from the LinkInfo structure (local base path or
+// network share plus suffix), else from the relative path.
+func parseShellLink(data []byte) (Shortcut, bool) {
+	if len(data) < lnkHeaderSize || binary.LittleEndian.Uint32(data) != lnkHeaderSize || !bytes.Equal(data[4:20], lnkCLSID) {
+		return Shortcut{}, false
+	}
+	flags := binary.LittleEndian.Uint32(data[0x14:])
+	pos := lnkHeaderSize
+	if flags&lnkHasTargetIDList != 0 {
+		if pos+2 > len(data) {
+			return Shortcut{}, false
+		}
+		pos += 2 + int(binary.LittleEndian.Uint16(data[pos:]))
+	}
+
+	sc := Shortcut{Kind: "lnk"}
+	if flags&lnkHasLinkInfo != 0 {
+		if pos+4 > len(data) {
+			return Shortcut{}, false
+		}
+		size := int(binary.LittleEndian.Uint32(data[pos:]))
+		if size < 0x1c || pos+size > len(data) {
+			return Shortcut{}, false
+		}
+		sc.Target = linkInfoTarget(data[pos : pos+size])
+		pos += size
+	}
+
+	unicode := flags&lnkIsUnicode != 0
+	var relative, args string
+	for _, field := range []struct {
+		flag uint32
+		dst  *string
+	}{
+		{lnkHasName, &sc.Comment},
+		{lnkHasRelativePath, &relative},
+		{lnkHasWorkingDir, &sc.WorkDir},
+		{lnkHasArguments, &args},
+		{lnkHasIconLocation, &sc.Icon},
+	} {
+		if flags&field.flag == 0 {
+			continue
+		}
+		value, next, ok := lnkString(data, pos, unicode)
+		if !ok {
+			break
+		}
+		*field.dst, pos = value, next
+	}
+
+	if sc.Target == "" {
+		sc.Target = relative
+	}
+	if sc.Target == "" && sc.Comment == "" {
+		return Shortcut{}, false
+	}
+	sc.Exec = sc.Target
+	if strings.ContainsAny(sc.Exec, " \t") {
+		sc.Exec = `"` + sc.Exec + `"`
+	}
+	if args != "" {
+		sc.Exec = strings.TrimSpace(sc.Exec + " " + args)
+	}
+	return sc, true
+}
+
+// linkInfoTarget assembles the target path from a LinkInfo structure,
+// preferring the Unicode strings when present.
+func linkInfoTarget(info []byte) string {
+	headerSize := binary.LittleEndian.Uint32(info[4:])
+	flags := binary.LittleEndian.Uint32(info[8:])
+	offset := func(at int) int { return int(binary.LittleEndian.Uint32(info[at:])) }
+
+	unicode := headerSize >= 0x24 && len(info) >= 0x24
+	pick := func(ansiAt, unicodeAt int) string {
+		if unicode {
+			if s := utf16String(info, offset(unicodeAt)); s != "" {
+				return s
+			}
+		}
+		return ansiString(info, offset(ansiAt))
+	}
+	suffix := pick(24, 32)
+	if flags&lnkVolumeIDAndPath != 0 {
+		if base := pick(16, 28); base != "" {
+			return joinLinkPath(base, suffix)
+		}
+	}
+	if flags&lnkNetworkAndSuffix != 0 {
+		if share := offset(20); share > 0 && share+0x14 <= len(info) {
+			network := info[share:]
+			netName := ansiString(network, int(binary.LittleEndian.Uint32(network[8:])))
+			if binary.LittleEndian.Uint32(network[8:]) > 0x14 && len(network) >= 0x1c {
+				netName = utf16String(network, int(binary.LittleEndian.Uint32(network[20:])))
+			}
+			if netName != "" {
+				return joinLinkPath(netName, suffix)
+			}
+		}
+	}
+	return ""
+}
+
+func joinLinkPath(base, suffix string) string {
+	if suffix == "" || strings.HasSuffix(base, `\`) {
+		return base + suffix
+	}
+	return base + `\` + suffix
+}
+
+// lnkString reads a StringData entry: a character count followed by that
+// many UTF-16 or ANSI characters.
+func lnkString(data []byte, pos int, unicode bool) (string, int, bool) {
+	if pos+2 > len(data) {
+		return "", pos, false
+	}
+	count := int(binary.LittleEndian.Uint16(data[pos:]))
+	pos += 2
+	size := count
+	if unicode {
+		size *= 2
+	}
+	if pos+size > len(data) {
+		return "", pos, false
+	}
+	raw := data[pos : pos+size]
+	if !unicode {
+		return latin1(raw), pos + size, true
+	}
+	units := make([]uint16, count)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(raw[2*i:])
+	}
+	return string(utf16.Decode(units)), pos + size, true
+}
+
+// ansiString reads a NUL-terminated string at offset. ANSI strings are in
+// the creating system's code page, which the link does not record; they are
+// read as Latin-1.
+func ansiString(data []byte, offset int) string {
+	if offset <= 0 || offset >= len(data) {
+		return ""
+	}
+	end := bytes.IndexByte(data[offset:], 0)
+	if end < 0 {
+		return ""
+	}
+	return latin1(data[offset : offset+end])
+}
+
+func latin1(raw []byte) string {
+	runes := make([]rune, len(raw))
+	for i, b := range raw {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+// utf16String reads a NUL-terminated UTF-16LE string at offset.
+func utf16String(data []byte, offset int) string {
+	if offset <= 0 || offset >= len(data) {
+		return ""
+	}
+	var units []uint16
+	for i := offset; i+1 < len(data); i += 2 {
+		unit := binary.LittleEndian.Uint16(data[i:])
+		if unit == 0 {
+			return string(utf16.Decode(units))
+		}
+		units = append(units, unit)
+	}
+	return ""
+}
+
+// ShortcutTargetPath turns a shortcut's target into a path on this system:
+// Windows paths of a .lnk map to /mnt/<drive> under WSL, relative targets
+// resolve against the shortcut's directory. ok is false when the target
+// cannot be reached from here.
+func ShortcutTargetPath(sc Shortcut, shortcutPath string, ctx PathContext) (string, bool) {
+	target := sc.Target
+	if target == "" {
+		return "", false
+	}
+	if sc.Kind == "lnk" && !ctx.Windows {
+		if ctx.WSLDistro == "" {
+			return "", false
+		}
+		translated, ok := WindowsToWSL(target)
+		if !ok {
+			if strings.HasPrefix(target, `\\`) || len(target) >= 2 && target[1] == ':' {
+				return "", false
+			}
+			translated = strings.ReplaceAll(target, `\`, "/")
+		}
+		target = translated
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(shortcutPath), target)
+	}
+	if _, err := os.Lstat(target); err != nil {
+		return "", false
+	}
+	return filepath.Clean(target), true
+}
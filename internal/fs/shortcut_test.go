@@ -0,0 +1,130 @@
+package fs
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestParseDesktopEntry(t *testing.T) {
+	data := []byte(`# comment
+[Desktop Entry]
+Type=Application
+Name=Text Editor
+Name[de]=Texteditor
+Exec="/opt/My Editor/bin/editor" --new-window %U
+Icon=accessories-text-editor
+Path=/home/kk
+Comment=Edit text files
+
+[Desktop Action new]
+Exec=/usr/bin/other
+`)
+	sc, ok := parseDesktopEntry(data)
+	if !ok {
+		t.Fatalf("parseDesktopEntry failed")
+	}
+	want := Shortcut{
+		Kind:    "desktop",
+		Name:    "Text Editor",
+		Target:  "/opt/My Editor/bin/editor",
+		Exec:    `"/opt/My Editor/bin/editor" --new-window %U`,
+		Icon:    "accessories-text-editor",
+		WorkDir: "/home/kk",
+		Comment: "Edit text files",
+	}
+	if sc != want {
+		t.Fatalf("shortcut = %+v\nwant %+v", sc, want)
+	}
+
+	link, ok := parseDesktopEntry([]byte("[Desktop Entry]\nType=Link\nName=Docs\nURL=file:///srv/share/docs%20dir\n"))
+	if !ok || link.Target != "/srv/share/docs dir" {
+		t.Fatalf("link target = %+v", link)
+	}
+	web, _ := parseDesktopEntry([]byte("[Desktop Entry]\nType=Link\nURL=https://example.com\n"))
+	if web.Target != "" {
+		t.Fatalf("web links have no file target, got %q", web.Target)
+	}
+	if _, ok := parseDesktopEntry([]byte("just text\n")); ok {
+		t.Fatalf("expected files without a Desktop Entry group to be rejected")
+	}
+}
+
+// buildShellLink assembles a minimal .lnk with a LinkInfo local path and
+// Unicode string data.
+func buildShellLink(localPath, args, icon string) []byte {
+	header := make([]byte, lnkHeaderSize)
+	binary.LittleEndian.PutUint32(header, lnkHeaderSize)
+	copy(header[4:], lnkCLSID)
+	binary.LittleEndian.PutUint32(header[0x14:], lnkHasLinkInfo|lnkHasArguments|lnkHasIconLocation|lnkIsUnicode)
+
+	volume := make([]byte, 0x10)
+	binary.LittleEndian.PutUint32(volume, 0x10)
+	info := make([]byte, 0x1c)
+	info = append(info, volume...)
+	baseOffset := len(info)
+	info = append(info, append([]byte(localPath), 0)...)
+	suffixOffset := len(info)
+	info = append(info, 0)
+	binary.LittleEndian.PutUint32(info[0:], uint32(len(info)))
+	binary.LittleEndian.PutUint32(info[4:], 0x1c)
+	binary.LittleEndian.PutUint32(info[8:], lnkVolumeIDAndPath)
+	binary.LittleEndian.PutUint32(info[12:], 0x1c)
+	binary.LittleEndian.PutUint32(info[16:], uint32(baseOffset))
+	binary.LittleEndian.PutUint32(info[24:], uint32(suffixOffset))
+
+	data := append(header, info...)
+	for _, s := range []string{args, icon} {
+		units := utf16.Encode([]rune(s))
+		data = binary.LittleEndian.AppendUint16(data, uint16(len(units)))
+		for _, u := range units {
+			data = binary.LittleEndian.AppendUint16(data, u)
+		}
+	}
+	return data
+}
+
+func TestParseShellLink(t *testing.T) {
+	sc, ok := parseShellLink(buildShellLink(`C:\Program Files\Tool\tool.exe`, "--verbose", `C:\Windows\icon.ico,0`))
+	if !ok {
+		t.Fatalf("parseShellLink failed")
+	}
+	if sc.Kind != "lnk" || sc.Target != `C:\Program Files\Tool\tool.exe` {
+		t.Fatalf("target = %+v", sc)
+	}
+	if sc.Exec != `"C:\Program Files\Tool\tool.exe" --verbose` || sc.Icon != `C:\Windows\icon.ico,0` {
+		t.Fatalf("exec/icon = %q / %q", sc.Exec, sc.Icon)
+	}
+
+	truncated := buildShellLink(`C:\x.exe`, "", "")
+	if _, ok := parseShellLink(truncated[:lnkHeaderSize+8]); ok {
+		t.Fatalf("expected a truncated link to be rejected")
+	}
+	if _, ok := parseShellLink([]byte("not a link at all, just some text padding it out to the size of a header....")); ok {
+		t.Fatalf("expected non-links to be rejected")
+	}
+}
+
+func TestShortcutTargetPath(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(target, nil, 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	shortcut := filepath.Join(dir, "notes.desktop")
+
+	if got, ok := ShortcutTargetPath(Shortcut{Kind: "desktop", Target: target}, shortcut, PathContext{}); !ok || got != target {
+		t.Fatalf("absolute target = %q, %v", got, ok)
+	}
+	if _, ok := ShortcutTargetPath(Shortcut{Kind: "desktop", Target: filepath.Join(dir, "gone")}, shortcut, PathContext{}); ok {
+		t.Fatalf("expected a missing target to be unreachable")
+	}
+	if _, ok := ShortcutTargetPath(Shortcut{Kind: "lnk", Target: `C:\x.exe`}, shortcut, PathContext{}); ok && os.PathSeparator == '/' {
+		t.Fatalf("expected Windows targets to be unreachable outside Windows and WSL")
+	}
+	if got, ok := ShortcutTargetPath(Shortcut{Kind: "lnk", Target: "notes.txt"}, shortcut, PathContext{Windows: true}); !ok || got != target {
+		t.Fatalf("relative target = %q, %v", got, ok)
+	}
+}
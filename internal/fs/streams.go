@@ -0,0 +1,83 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AppleDoublePrefix starts the name of the "._" files macOS writes next to a
+// file to keep its resource fork and extended attributes on filesystems (and
+// in archives) that cannot store them.
+const AppleDoublePrefix = "._"
+
+// StreamKind tells the kinds of AltStream apart.
+type StreamKind int
+
+const (
+	StreamNTFS         StreamKind = iota // named NTFS data stream
+	StreamResourceFork                   // macOS resource fork
+	StreamAppleDouble                    // "._" file beside the entry, not part of it
+)
+
+// AltStream is data attached to a file besides its content: an NTFS
+// alternate data stream, a macOS resource fork, or the AppleDouble file
+// that carries them next to it.
+type AltStream struct {
+	Kind StreamKind
+	Name string
+	Size int64
+}
+
+// Label describes the stream for messages, e.g. "stream Zone.Identifier".
+func (s AltStream) Label() string {
+	switch s.Kind {
+	case StreamResourceFork:
+		return "resource fork"
+	case StreamAppleDouble:
+		return s.Name + " (AppleDouble)"
+	default:
+		return "stream " + s.Name
+	}
+}
+
+// AlternateStreams lists the streams attached to path. They are only shown
+// and warned about, so errors reading them yield no streams.
+func AlternateStreams(path string) []AltStream {
+	streams := nativeStreams(path)
+	dir, name := filepath.Split(path)
+	if name == "" || strings.HasPrefix(name, AppleDoublePrefix) {
+		return streams
+	}
+	sidecar := AppleDoublePrefix + name
+	if info, err := os.Lstat(filepath.Join(dir, sidecar)); err == nil && info.Mode().IsRegular() {
+		streams = append(streams, AltStream{Kind: StreamAppleDouble, Name: sidecar, Size: info.Size()})
+	}
+	return streams
+}
+
+// FindNativeStreams walks root, looking at no more than limit entries, and
+// returns the first path with native alternate streams. AppleDouble files
+// are ignored since copying the tree copies them too.
+func FindNativeStreams(root string, limit int) (string, bool) {
+	found := ""
+	visited := 0
+	_ = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		visited++
+		if visited > limit {
+			return filepath.SkipAll
+		}
+		if d.Type()&os.ModeSymlink != 0 {
+			return nil
+		}
+		if len(nativeStreams(path)) > 0 {
+			found = path
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found, found != ""
+}
@@ -0,0 +1,15 @@
+package fs
+
+import "golang.org/x/sys/unix"
+
+const resourceForkXattr = "com.apple.ResourceFork"
+
+// nativeStreams reports the resource fork, which macOS keeps as an extended
+// attribute.
+func nativeStreams(path string) []AltStream {
+	size, err := unix.Lgetxattr(path, resourceForkXattr, nil)
+	if err != nil || size <= 0 {
+		return nil
+	}
+	return []AltStream{{Kind: StreamResourceFork, Name: resourceForkXattr, Size: int64(size)}}
+}
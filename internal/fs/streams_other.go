@@ -0,0 +1,9 @@
+//go:build !darwin && !windows
+
+package fs
+
+// nativeStreams finds nothing: this platform has neither NTFS streams nor
+// resource forks, though AppleDouble files are still reported.
+func nativeStreams(string) []AltStream {
+	return nil
+}
@@ -0,0 +1,48 @@
+package fs
+
+import (
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	procFindFirstStreamW = windows.NewLazySystemDLL("kernel32.dll").NewProc("FindFirstStreamW")
+	procFindNextStreamW  = windows.NewLazySystemDLL("kernel32.dll").NewProc("FindNextStreamW")
+)
+
+// win32FindStreamData mirrors WIN32_FIND_STREAM_DATA.
+type win32FindStreamData struct {
+	StreamSize int64
+	StreamName [windows.MAX_PATH + 36]uint16
+}
+
+// nativeStreams lists the named NTFS data streams of path, e.g. the
+// Zone.Identifier stream browsers add to downloads.
+func nativeStreams(path string) []AltStream {
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return nil
+	}
+	var data win32FindStreamData
+	const findStreamInfoStandard = 0
+	handle, _, _ := procFindFirstStreamW.Call(uintptr(unsafe.Pointer(name)), findStreamInfoStandard, uintptr(unsafe.Pointer(&data)), 0)
+	if windows.Handle(handle) == windows.InvalidHandle {
+		return nil
+	}
+	defer func() { _ = windows.FindClose(windows.Handle(handle)) }()
+
+	var streams []AltStream
+	for {
+		// Names look like ":Zone.Identifier:$DATA"; "::$DATA" is the file itself.
+		stream := windows.UTF16ToString(data.StreamName[:])
+		stream = strings.TrimSuffix(strings.TrimPrefix(stream, ":"), ":$DATA")
+		if stream != "" {
+			streams = append(streams, AltStream{Kind: StreamNTFS, Name: stream, Size: data.StreamSize})
+		}
+		if ok, _, _ := procFindNextStreamW.Call(handle, uintptr(unsafe.Pointer(&data))); ok == 0 {
+			return streams
+		}
+	}
+}
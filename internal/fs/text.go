@@ -2,8 +2,8 @@ package fs
 
 import (
 	"bytes"
+	"context"
 	"io"
-	"os"
 	"path/filepath"
 	"strings"
 	"unicode/utf8"
@@ -13,6 +13,7 @@ import (
 
 const (
 	textDetectionSampleSize      = 4096
+	readChunkSize                = 64 * 1024
 	nonPrintableThresholdPercent = 30
 )
 
@@ -119,11 +120,25 @@ func IsTextFile(path string, content []byte) bool {
 
 // ReadFileHead returns up to limit bytes from the beginning of path.
 func ReadFileHead(path string, limit int64) ([]byte, error) {
+	return ReadFileHeadContext(context.Background(), path, limit)
+}
+
+// ReadFileHeadContext is ReadFileHead with cancellation: ctx is checked
+// between chunks so a slow (e.g. network) file can be abandoned mid-read.
+func ReadFileHeadContext(ctx context.Context, path string, limit int64) ([]byte, error) {
+	return ReadFileHeadFS(ctx, Local, path, limit)
+}
+
+// ReadFileHeadFS is ReadFileHeadContext reading from fsys.
+func ReadFileHeadFS(ctx context.Context, fsys FS, path string, limit int64) ([]byte, error) {
 	if limit <= 0 {
 		return nil, nil
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-	f, err := os.Open(path)
+	f, err := OrLocal(fsys).Open(path)
 	if err != nil {
 		return nil, err
 	}
@@ -131,7 +146,34 @@ func ReadFileHead(path string, limit int64) ([]byte, error) {
 		_ = f.Close()
 	}()
 
-	return io.ReadAll(io.LimitReader(f, limit))
+	if ctx.Done() == nil {
+		return io.ReadAll(io.LimitReader(f, limit))
+	}
+
+	buf := make([]byte, 0, minInt64(limit, readChunkSize))
+	chunk := make([]byte, minInt64(limit, readChunkSize))
+	for int64(len(buf)) < limit {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		want := minInt64(limit-int64(len(buf)), int64(len(chunk)))
+		n, err := f.Read(chunk[:want])
+		buf = append(buf, chunk[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
 }
 
 // ReadTextSample returns a small sample of the file for text/binary sniffing.
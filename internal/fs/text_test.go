@@ -1,6 +1,12 @@
 package fs
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
 
 func TestIsTextFileDetectsUTF16LE(t *testing.T) {
 	content := []byte{0xFF, 0xFE, 0x41, 0x00, 0x0D, 0x00, 0x0A, 0x00}
@@ -17,3 +23,56 @@ func TestNormalizeTextContentUTF16LE(t *testing.T) {
 		t.Fatalf("NormalizeTextContent returned %q, want %q", got, want)
 	}
 }
+
+func TestReadFileHeadContextHonorsLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.txt")
+	if err := os.WriteFile(path, []byte("0123456789"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	got, err := ReadFileHeadContext(ctx, path, 4)
+	if err != nil {
+		t.Fatalf("ReadFileHeadContext: %v", err)
+	}
+	if string(got) != "0123" {
+		t.Fatalf("ReadFileHeadContext = %q, want %q", got, "0123")
+	}
+}
+
+func TestReadFileHeadContextCancelled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.txt")
+	if err := os.WriteFile(path, []byte("content"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ReadFileHeadContext(ctx, path, 16); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestReadDirContext(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a", "b", "c"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	entries, err := ReadDirContext(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("ReadDirContext: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ReadDirContext(ctx, dir); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
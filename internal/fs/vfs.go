@@ -0,0 +1,137 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"io"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FS is the filesystem rdir browses: the directory loader, the preview
+// builder, the pager and global search read through it, so another backend
+// (an archive, a remote store, test fixtures) can be shown in place of the
+// local disk. Names are absolute paths in the backend's namespace, built with
+// path/filepath. File operations (paste, rename, archives) always act on the
+// local filesystem.
+type FS interface {
+	Open(name string) (File, error)
+	Stat(name string) (os.FileInfo, error)
+	Lstat(name string) (os.FileInfo, error)
+	// ReadDir lists name in the backend's order, checking ctx so a slow
+	// listing can be abandoned.
+	ReadDir(ctx context.Context, name string) ([]os.DirEntry, error)
+}
+
+// File is an open file of an FS. Backends that cannot seek or read at an
+// offset return errors.ErrUnsupported; the pager then reads sequentially.
+type File interface {
+	io.ReadSeekCloser
+	io.ReaderAt
+	Stat() (os.FileInfo, error)
+}
+
+// Local is the local filesystem, through package os.
+var Local FS = localFS{}
+
+// IsLocal reports whether fsys is the local filesystem; nil counts as local,
+// so an unset FS field means the disk.
+func IsLocal(fsys FS) bool {
+	return fsys == nil || fsys == Local
+}
+
+// OrLocal returns fsys, or Local when it is nil.
+func OrLocal(fsys FS) FS {
+	if fsys == nil {
+		return Local
+	}
+	return fsys
+}
+
+type localFS struct{}
+
+func (localFS) Open(name string) (File, error)         { return os.Open(name) }
+func (localFS) Stat(name string) (os.FileInfo, error)  { return os.Stat(name) }
+func (localFS) Lstat(name string) (os.FileInfo, error) { return os.Lstat(name) }
+func (localFS) ReadDir(ctx context.Context, name string) ([]os.DirEntry, error) {
+	return ReadDirContext(ctx, name)
+}
+
+// FromFS serves fsys as if it were mounted at root, e.g. an embed.FS or an
+// fstest.MapFS. Names outside root do not exist.
+func FromFS(fsys iofs.FS, root string) FS {
+	// A pointer, so FS values stay comparable when fsys is a map (MapFS).
+	return &mountedFS{fsys: fsys, root: filepath.Clean(root)}
+}
+
+type mountedFS struct {
+	fsys iofs.FS
+	root string
+}
+
+func (m mountedFS) rel(op, name string) (string, error) {
+	rel, err := filepath.Rel(m.root, filepath.Clean(name))
+	if err == nil {
+		rel = filepath.ToSlash(rel)
+		if iofs.ValidPath(rel) {
+			return rel, nil
+		}
+	}
+	return "", &iofs.PathError{Op: op, Path: name, Err: iofs.ErrNotExist}
+}
+
+func (m mountedFS) Open(name string) (File, error) {
+	rel, err := m.rel("open", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := m.fsys.Open(rel)
+	if err != nil {
+		return nil, err
+	}
+	return mountedFile{File: f}, nil
+}
+
+func (m mountedFS) Stat(name string) (os.FileInfo, error) {
+	rel, err := m.rel("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return iofs.Stat(m.fsys, rel)
+}
+
+// Lstat is Stat: io/fs has no portable way to inspect links themselves.
+func (m mountedFS) Lstat(name string) (os.FileInfo, error) {
+	return m.Stat(name)
+}
+
+func (m mountedFS) ReadDir(ctx context.Context, name string) ([]os.DirEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	rel, err := m.rel("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	return iofs.ReadDir(m.fsys, rel)
+}
+
+// mountedFile gives an io/fs file the optional methods File requires.
+type mountedFile struct {
+	iofs.File
+}
+
+func (f mountedFile) Seek(offset int64, whence int) (int64, error) {
+	if s, ok := f.File.(io.Seeker); ok {
+		return s.Seek(offset, whence)
+	}
+	return 0, errors.ErrUnsupported
+}
+
+func (f mountedFile) ReadAt(p []byte, off int64) (int, error) {
+	if r, ok := f.File.(io.ReaderAt); ok {
+		return r.ReadAt(p, off)
+	}
+	return 0, errors.ErrUnsupported
+}
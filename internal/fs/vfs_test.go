@@ -0,0 +1,83 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"io"
+	iofs "io/fs"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFromFSMapsNamesUnderRoot(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "mnt")
+	fsys := FromFS(fstest.MapFS{
+		"a.txt":      {Data: []byte("hello")},
+		"docs/b.txt": {Data: []byte("b")},
+	}, root)
+
+	entries, err := fsys.ReadDir(context.Background(), root)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if len(names) != 2 || names[0] != "a.txt" || names[1] != "docs" {
+		t.Fatalf("names = %v", names)
+	}
+
+	info, err := fsys.Stat(filepath.Join(root, "docs"))
+	if err != nil || !info.IsDir() {
+		t.Fatalf("Stat(docs) = %v, %v", info, err)
+	}
+
+	f, err := fsys.Open(filepath.Join(root, "a.txt"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer f.Close()
+	buf := make([]byte, 3)
+	if _, err := f.ReadAt(buf, 2); err != nil || string(buf) != "llo" {
+		t.Fatalf("ReadAt = %q, %v", buf, err)
+	}
+	if _, err := f.Seek(1, io.SeekStart); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+	rest, err := io.ReadAll(f)
+	if err != nil || string(rest) != "ello" {
+		t.Fatalf("ReadAll after Seek = %q, %v", rest, err)
+	}
+}
+
+func TestFromFSRejectsNamesOutsideRoot(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "mnt")
+	fsys := FromFS(fstest.MapFS{"a.txt": {Data: []byte("a")}}, root)
+	outside := filepath.Join(filepath.Dir(root), "a.txt")
+	if _, err := fsys.Stat(outside); !errors.Is(err, iofs.ErrNotExist) {
+		t.Fatalf("Stat outside root err = %v, want ErrNotExist", err)
+	}
+	if _, err := fsys.ReadDir(context.Background(), filepath.Dir(root)); !errors.Is(err, iofs.ErrNotExist) {
+		t.Fatalf("ReadDir outside root err = %v, want ErrNotExist", err)
+	}
+}
+
+func TestReadFileHeadFS(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "mnt")
+	fsys := FromFS(fstest.MapFS{"a.txt": {Data: []byte("hello world")}}, root)
+	data, err := ReadFileHeadFS(context.Background(), fsys, filepath.Join(root, "a.txt"), 5)
+	if err != nil || string(data) != "hello" {
+		t.Fatalf("ReadFileHeadFS = %q, %v", data, err)
+	}
+}
+
+func TestIsLocal(t *testing.T) {
+	if !IsLocal(nil) || !IsLocal(Local) {
+		t.Fatal("nil and Local should be local")
+	}
+	if IsLocal(FromFS(fstest.MapFS{}, "/")) {
+		t.Fatal("a mounted io/fs should not be local")
+	}
+}
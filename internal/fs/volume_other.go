@@ -0,0 +1,17 @@
+//go:build !unix && !windows
+
+package fs
+
+import "path/filepath"
+
+// SameVolume cannot tell volumes apart on this platform and assumes they
+// differ.
+func SameVolume(string, string) bool {
+	return false
+}
+
+// MountRoot only recognizes the filesystem root on this platform.
+func MountRoot(path string) bool {
+	path = filepath.Clean(path)
+	return filepath.Dir(path) == path
+}
@@ -0,0 +1,33 @@
+//go:build unix
+
+package fs
+
+import (
+	"path/filepath"
+	"syscall"
+)
+
+// SameVolume reports whether a and b are on the same filesystem, so a
+// rename between them keeps whatever is attached to the file.
+func SameVolume(a, b string) bool {
+	var sa, sb syscall.Stat_t
+	if syscall.Stat(a, &sa) != nil || syscall.Stat(b, &sb) != nil {
+		return false
+	}
+	return sa.Dev == sb.Dev
+}
+
+// MountRoot reports whether path is the root of a filesystem: "/" or a
+// directory on a different device than its parent.
+func MountRoot(path string) bool {
+	path = filepath.Clean(path)
+	parent := filepath.Dir(path)
+	if parent == path {
+		return true
+	}
+	var sp, sd syscall.Stat_t
+	if syscall.Stat(path, &sp) != nil || syscall.Stat(parent, &sd) != nil {
+		return false
+	}
+	return sp.Dev != sd.Dev
+}
@@ -0,0 +1,26 @@
+package fs
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// SameVolume reports whether a and b are on the same drive or share, so a
+// rename between them keeps the streams.
+func SameVolume(a, b string) bool {
+	return strings.EqualFold(filepath.VolumeName(absPath(a)), filepath.VolumeName(absPath(b)))
+}
+
+// MountRoot reports whether path is the root of a drive or share.
+func MountRoot(path string) bool {
+	abs := absPath(path)
+	volume := filepath.VolumeName(abs)
+	return volume != "" && (abs == volume || abs == volume+`\`)
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -0,0 +1,190 @@
+// Package lineedit implements the readline-style editing shared by rdir's
+// single-line text inputs: the filter, global search, footer prompts and the
+// pager's search.
+package lineedit
+
+import (
+	"unicode"
+
+	"github.com/rivo/uniseg"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Op is one editing command. The cursor movements keep the names global
+// search used for them before the inputs shared this package.
+type Op string
+
+const (
+	Left              Op = "left"
+	Right             Op = "right"
+	WordLeft          Op = "word-left"
+	WordRight         Op = "word-right"
+	Home              Op = "home"
+	End               Op = "end"
+	Backspace         Op = "backspace"
+	Delete            Op = "delete"
+	DeleteWordBack    Op = "delete-word"       // Ctrl+W, Alt+Backspace
+	DeleteWordForward Op = "delete-word-right" // Alt+D
+	KillToStart       Op = "kill-start"        // Ctrl+U
+	KillToEnd         Op = "kill-end"          // Ctrl+K
+)
+
+// Moves reports whether op only moves the cursor.
+func (op Op) Moves() bool {
+	switch op {
+	case Left, Right, WordLeft, WordRight, Home, End:
+		return true
+	}
+	return false
+}
+
+// Line is a line of input and its cursor, counted in runes before it.
+// Character moves and deletes step over whole grapheme clusters, so an
+// accented letter or an emoji with a modifier goes at once.
+type Line struct {
+	Text   []rune
+	Cursor int
+}
+
+// New returns text with the cursor at its end.
+func New(text string) Line {
+	runes := []rune(text)
+	return Line{Text: runes, Cursor: len(runes)}
+}
+
+// FromTail returns text with tail runes after the cursor. Inputs that store
+// their cursor this way keep it at the end when the text is replaced.
+func FromTail(text string, tail int) Line {
+	runes := []rune(text)
+	return Line{Text: runes, Cursor: len(runes) - min(max(tail, 0), len(runes))}
+}
+
+func (l Line) String() string {
+	return string(l.Text)
+}
+
+// Tail returns the number of runes after the cursor.
+func (l Line) Tail() int {
+	return len(l.Text) - l.clamped()
+}
+
+func (l Line) clamped() int {
+	return min(max(l.Cursor, 0), len(l.Text))
+}
+
+// Insert types runes at the cursor. The text before the cursor is composed
+// (NFC), so a dead key or input method that sends a letter and a combining
+// accent separately leaves one precomposed character.
+func (l *Line) Insert(runes ...rune) {
+	cursor := l.clamped()
+	head := make([]rune, 0, cursor+len(runes))
+	head = append(head, l.Text[:cursor]...)
+	head = []rune(norm.NFC.String(string(append(head, runes...))))
+	l.Cursor = len(head)
+	l.Text = append(head, l.Text[cursor:]...)
+}
+
+// Apply runs op and reports whether the text changed.
+func (l *Line) Apply(op Op) bool {
+	cursor := l.clamped()
+	l.Cursor = cursor
+	switch op {
+	case Left:
+		l.Cursor = previousCluster(l.Text, cursor)
+	case Right:
+		l.Cursor = nextCluster(l.Text, cursor)
+	case WordLeft:
+		l.Cursor = previousWordBoundary(l.Text, cursor)
+	case WordRight:
+		l.Cursor = nextWordBoundary(l.Text, cursor)
+	case Home:
+		l.Cursor = 0
+	case End:
+		l.Cursor = len(l.Text)
+	case Backspace:
+		return l.cut(previousCluster(l.Text, cursor), cursor)
+	case Delete:
+		return l.cut(cursor, nextCluster(l.Text, cursor))
+	case DeleteWordBack:
+		return l.cut(previousWordBoundary(l.Text, cursor), cursor)
+	case DeleteWordForward:
+		return l.cut(cursor, nextWordBoundary(l.Text, cursor))
+	case KillToStart:
+		return l.cut(0, cursor)
+	case KillToEnd:
+		return l.cut(cursor, len(l.Text))
+	}
+	return false
+}
+
+// cut removes the runes from start to end and leaves the cursor at start.
+func (l *Line) cut(start, end int) bool {
+	if start >= end {
+		return false
+	}
+	text := make([]rune, 0, len(l.Text)-(end-start))
+	text = append(text, l.Text[:start]...)
+	l.Text = append(text, l.Text[end:]...)
+	l.Cursor = start
+	return true
+}
+
+// previousCluster returns the start of the grapheme cluster before pos.
+func previousCluster(runes []rune, pos int) int {
+	start := 0
+	for _, end := range clusterEnds(runes[:pos]) {
+		if end < pos {
+			start = end
+		}
+	}
+	return start
+}
+
+// nextCluster returns the end of the grapheme cluster after pos.
+func nextCluster(runes []rune, pos int) int {
+	if ends := clusterEnds(runes[pos:]); len(ends) > 0 {
+		return pos + ends[0]
+	}
+	return pos
+}
+
+// clusterEnds returns the rune offsets where the grapheme clusters of runes
+// end.
+func clusterEnds(runes []rune) []int {
+	var ends []int
+	end := 0
+	g := uniseg.NewGraphemes(string(runes))
+	for g.Next() {
+		end += len(g.Runes())
+		ends = append(ends, end)
+	}
+	return ends
+}
+
+// isWordChar reports whether r belongs to a word for word movement and
+// deletion; punctuation such as '/', '.' and '-' separates words.
+func isWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+func previousWordBoundary(runes []rune, pos int) int {
+	i := pos - 1
+	for i >= 0 && !isWordChar(runes[i]) {
+		i--
+	}
+	for i >= 0 && isWordChar(runes[i]) {
+		i--
+	}
+	return i + 1
+}
+
+func nextWordBoundary(runes []rune, pos int) int {
+	i := pos
+	for i < len(runes) && !isWordChar(runes[i]) {
+		i++
+	}
+	for i < len(runes) && isWordChar(runes[i]) {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,74 @@
+package lineedit
+
+import "testing"
+
+func TestApply(t *testing.T) {
+	cases := []struct {
+		text    string
+		cursor  int
+		op      Op
+		want    string
+		wantPos int
+		changed bool
+	}{
+		{"foo bar", 7, Left, "foo bar", 6, false},
+		{"foo bar", 0, Left, "foo bar", 0, false},
+		{"foo bar", 7, WordLeft, "foo bar", 4, false},
+		{"foo/bar.go", 10, WordLeft, "foo/bar.go", 8, false},
+		{"foo bar", 0, WordRight, "foo bar", 3, false},
+		{"foo bar", 3, WordRight, "foo bar", 7, false},
+		{"foo bar", 4, Home, "foo bar", 0, false},
+		{"foo bar", 4, End, "foo bar", 7, false},
+		{"foo bar", 4, Backspace, "foobar", 3, true},
+		{"foo bar", 0, Backspace, "foo bar", 0, false},
+		{"foo bar", 3, Delete, "foobar", 3, true},
+		{"foo bar", 7, Delete, "foo bar", 7, false},
+		{"src/main.go", 8, DeleteWordBack, "src/.go", 4, true},
+		{"foo bar", 3, DeleteWordForward, "foo", 3, true},
+		{"foo bar", 4, KillToStart, "bar", 0, true},
+		{"foo bar", 3, KillToEnd, "foo", 3, true},
+		{"żółw", 4, WordLeft, "żółw", 0, false},
+	}
+	for _, tc := range cases {
+		line := Line{Text: []rune(tc.text), Cursor: tc.cursor}
+		changed := line.Apply(tc.op)
+		if line.String() != tc.want || line.Cursor != tc.wantPos || changed != tc.changed {
+			t.Fatalf("%q@%d %s = %q@%d (changed %v), want %q@%d (changed %v)",
+				tc.text, tc.cursor, tc.op, line.String(), line.Cursor, changed, tc.want, tc.wantPos, tc.changed)
+		}
+	}
+}
+
+func TestInsertAndTail(t *testing.T) {
+	line := FromTail("fobar", 3)
+	line.Insert('o', ' ')
+	if line.String() != "foo bar" || line.Tail() != 3 {
+		t.Fatalf("got %q with tail %d", line.String(), line.Tail())
+	}
+	if line := FromTail("abc", 10); line.Cursor != 0 {
+		t.Fatalf("expected an oversized tail to put the cursor at the start, got %d", line.Cursor)
+	}
+	if line := New("abc"); line.Tail() != 0 {
+		t.Fatalf("expected New to put the cursor at the end, got tail %d", line.Tail())
+	}
+}
+
+func TestInsertComposesAndEditsClusters(t *testing.T) {
+	line := New("caf")
+	line.Insert('e')
+	line.Insert('́') // dead key sending the accent after the letter
+	if line.String() != "café" || line.Cursor != 4 {
+		t.Fatalf("expected a composed é, got %q@%d", line.String(), line.Cursor)
+	}
+
+	line = New("ok 👍🏽")
+	line.Apply(Left)
+	if line.Cursor != 3 {
+		t.Fatalf("expected ← to step over the emoji and its modifier, got %d", line.Cursor)
+	}
+	line.Apply(End)
+	line.Apply(Backspace)
+	if line.String() != "ok " {
+		t.Fatalf("expected Backspace to remove the whole emoji, got %q", line.String())
+	}
+}
@@ -0,0 +1,287 @@
+// Package procwatch supervises external commands that can hang: each kind of
+// command gets a time limit, after which it is asked to stop (TERM) and,
+// when it ignores that for a grace period, killed.
+package procwatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EnvTimeout configures the limits: a duration for every timed kind, and/or
+// comma-separated "kind=duration" pairs, e.g. "clipboard=3s,open=30s,kill=1s".
+// "off" or 0 removes a limit; "kill" is the grace period between TERM and
+// KILL.
+const EnvTimeout = "RDIR_CMD_TIMEOUT"
+
+// Kind names a group of call sites sharing a limit.
+type Kind string
+
+const (
+	Clipboard Kind = "clipboard" // clipboard copy commands
+	Open      Kind = "open"      // the system opener
+	Hook      Kind = "hook"      // RDIR_HOOK_* commands
+	Query     Kind = "query"     // short status queries: git, pmset
+	// Decompress covers decompressors (xz, zstd, bzip2) feeding previews
+	// and the pager.
+	Decompress Kind = "decompress"
+	// Interactive commands (editor, shell, pager) own the terminal until the
+	// user leaves them; they are never timed out.
+	Interactive Kind = "interactive"
+)
+
+const (
+	DefaultClipboardTimeout  = 5 * time.Second
+	DefaultHookTimeout       = 5 * time.Second
+	DefaultQueryTimeout      = 5 * time.Second
+	DefaultDecompressTimeout = 30 * time.Second
+	DefaultGrace             = 2 * time.Second
+)
+
+// Policy is how long a command may run and how long it gets to exit after
+// TERM. A zero Timeout means no limit.
+type Policy struct {
+	Timeout time.Duration
+	Grace   time.Duration
+}
+
+// Config holds the limit of each timed kind and the shared grace period.
+type Config struct {
+	Timeouts map[Kind]time.Duration
+	Grace    time.Duration
+}
+
+// DefaultConfig limits clipboard commands, hooks, queries and
+// decompressors; the opener is not timed out because some openers stay in
+// the foreground running the application.
+func DefaultConfig() Config {
+	return Config{
+		Timeouts: map[Kind]time.Duration{
+			Clipboard:  DefaultClipboardTimeout,
+			Hook:       DefaultHookTimeout,
+			Open:       0,
+			Query:      DefaultQueryTimeout,
+			Decompress: DefaultDecompressTimeout,
+		},
+		Grace: DefaultGrace,
+	}
+}
+
+var timedKinds = []Kind{Clipboard, Open, Hook, Query, Decompress}
+
+// Load reads RDIR_CMD_TIMEOUT on top of the defaults. Invalid entries are
+// skipped and reported.
+func Load(getenv func(string) string) (Config, error) {
+	cfg := DefaultConfig()
+	var problems []string
+	for _, item := range strings.Split(getenv(EnvTimeout), ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		key, value, found := strings.Cut(item, "=")
+		if !found {
+			value = key
+		}
+		d, ok := parseLimit(strings.TrimSpace(value))
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%q", item))
+			continue
+		}
+		switch key = strings.TrimSpace(key); {
+		case !found:
+			for _, kind := range timedKinds {
+				cfg.Timeouts[kind] = d
+			}
+		case key == "kill":
+			if d == 0 {
+				problems = append(problems, fmt.Sprintf("%q (kill needs a duration)", item))
+				continue
+			}
+			cfg.Grace = d
+		case cfg.known(Kind(key)):
+			cfg.Timeouts[Kind(key)] = d
+		default:
+			problems = append(problems, fmt.Sprintf("%q (unknown command kind)", item))
+		}
+	}
+	if len(problems) > 0 {
+		return cfg, fmt.Errorf("ignoring %s entries: %s (use 5s, clipboard|open|hook|query|decompress=<duration>|off or kill=<duration>)", EnvTimeout, strings.Join(problems, ", "))
+	}
+	return cfg, nil
+}
+
+func (c Config) known(kind Kind) bool {
+	_, ok := c.Timeouts[kind]
+	return ok
+}
+
+func parseLimit(value string) (time.Duration, bool) {
+	if value == "off" || value == "0" {
+		return 0, true
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, false
+	}
+	return d, true
+}
+
+// String lists the limits, e.g. for rdir --doctor.
+func (c Config) String() string {
+	kinds := make([]string, 0, len(c.Timeouts))
+	for kind := range c.Timeouts {
+		kinds = append(kinds, string(kind))
+	}
+	sort.Strings(kinds)
+	parts := make([]string, 0, len(kinds)+1)
+	for _, kind := range kinds {
+		limit := "off"
+		if d := c.Timeouts[Kind(kind)]; d > 0 {
+			limit = d.String()
+		}
+		parts = append(parts, kind+"="+limit)
+	}
+	parts = append(parts, "kill="+c.Grace.String())
+	return strings.Join(parts, ", ")
+}
+
+var (
+	configMu sync.RWMutex
+	config   = DefaultConfig()
+)
+
+// Set installs cfg as the limits used by Run and Wait.
+func Set(cfg Config) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	config = cfg
+}
+
+// PolicyFor returns the configured policy of kind.
+func PolicyFor(kind Kind) Policy {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return Policy{Timeout: config.Timeouts[kind], Grace: config.Grace}
+}
+
+// WithTimeout returns the configured grace period with its own limit, for
+// commands configured elsewhere (e.g. the formatter timeout).
+func WithTimeout(timeout time.Duration) Policy {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return Policy{Timeout: timeout, Grace: config.Grace}
+}
+
+// TimeoutError reports a command stopped for running too long.
+type TimeoutError struct {
+	Command string
+	Timeout time.Duration
+	Killed  bool // it ignored TERM and was killed
+}
+
+func (e *TimeoutError) Error() string {
+	how := "stopped it"
+	if e.Killed {
+		how = "killed it"
+	}
+	return fmt.Sprintf("%s did not finish within %s; %s", e.Command, e.Timeout, how)
+}
+
+// Run starts cmd and waits for it under the policy of kind.
+func Run(cmd *exec.Cmd, kind Kind) error {
+	return RunPolicy(cmd, PolicyFor(kind))
+}
+
+// RunPolicy starts cmd and waits for it under p.
+func RunPolicy(cmd *exec.Cmd, p Policy) error {
+	if p.Timeout > 0 && cmd.WaitDelay == 0 {
+		// A child that outlives cmd must not keep Wait blocked on its pipes.
+		cmd.WaitDelay = p.grace()
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return WaitPolicy(cmd, p)
+}
+
+// CommandContext is exec.CommandContext for commands whose output is read
+// while they run: past the limit of kind (or when ctx ends) the command is
+// sent TERM, and killed after the grace period. Pass the error of
+// cmd.Wait through done, which releases the limit and reports a
+// *TimeoutError when the limit stopped the command.
+func CommandContext(ctx context.Context, kind Kind, name string, args ...string) (cmd *exec.Cmd, done func(error) error) {
+	p := PolicyFor(kind)
+	limitCtx, cancel := ctx, context.CancelFunc(func() {})
+	if p.Timeout > 0 {
+		limitCtx, cancel = context.WithTimeout(ctx, p.Timeout)
+	}
+	cmd = exec.CommandContext(limitCtx, name, args...)
+	cmd.Cancel = func() error {
+		if terminate(cmd.Process) != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = p.grace()
+	return cmd, func(err error) error {
+		defer cancel()
+		if err != nil && ctx.Err() == nil && errors.Is(limitCtx.Err(), context.DeadlineExceeded) {
+			return &TimeoutError{Command: filepath.Base(name), Timeout: p.Timeout}
+		}
+		return err
+	}
+}
+
+// Wait waits for the started cmd under the policy of kind.
+func Wait(cmd *exec.Cmd, kind Kind) error {
+	return WaitPolicy(cmd, PolicyFor(kind))
+}
+
+// WaitPolicy waits for the started cmd. When it overruns p.Timeout it is
+// sent TERM, then killed if it is still running after p.Grace; the result
+// is then a *TimeoutError.
+func WaitPolicy(cmd *exec.Cmd, p Policy) error {
+	if p.Timeout <= 0 {
+		return cmd.Wait()
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	limit := time.NewTimer(p.Timeout)
+	defer limit.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-limit.C:
+	}
+
+	stopped := &TimeoutError{Command: filepath.Base(cmd.Path), Timeout: p.Timeout}
+	if terminate(cmd.Process) == nil {
+		grace := time.NewTimer(p.grace())
+		defer grace.Stop()
+		select {
+		case <-done:
+			return stopped
+		case <-grace.C:
+		}
+	}
+	stopped.Killed = true
+	_ = cmd.Process.Kill()
+	<-done
+	return stopped
+}
+
+func (p Policy) grace() time.Duration {
+	if p.Grace > 0 {
+		return p.Grace
+	}
+	return DefaultGrace
+}
@@ -0,0 +1,112 @@
+package procwatch
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	env := func(value string) func(string) string {
+		return func(key string) string {
+			if key == EnvTimeout {
+				return value
+			}
+			return ""
+		}
+	}
+
+	cfg, err := Load(env(""))
+	if err != nil || cfg.Timeouts[Clipboard] != DefaultClipboardTimeout || cfg.Timeouts[Open] != 0 || cfg.Grace != DefaultGrace {
+		t.Fatalf("defaults = %+v, %v", cfg, err)
+	}
+
+	cfg, err = Load(env("10s, open=off, clipboard=3s, kill=500ms"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Timeouts[Clipboard] != 3*time.Second || cfg.Timeouts[Hook] != 10*time.Second || cfg.Timeouts[Open] != 0 || cfg.Grace != 500*time.Millisecond {
+		t.Fatalf("config = %+v", cfg)
+	}
+	if got := cfg.String(); got != "clipboard=3s, decompress=10s, hook=10s, open=off, query=10s, kill=500ms" {
+		t.Fatalf("String() = %q", got)
+	}
+
+	cfg, err = Load(env("editor=1s,clipboard=soon,kill=0"))
+	if err == nil {
+		t.Fatalf("expected invalid entries to be reported")
+	}
+	if cfg.Timeouts[Clipboard] != DefaultClipboardTimeout || cfg.Grace != DefaultGrace {
+		t.Fatalf("invalid entries changed the config: %+v", cfg)
+	}
+}
+
+func TestRunPolicyStopsOverrunningCommands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/sh and TERM")
+	}
+	policy := Policy{Timeout: 50 * time.Millisecond, Grace: 100 * time.Millisecond}
+
+	if err := RunPolicy(exec.Command("/bin/sh", "-c", "exit 3"), policy); err == nil {
+		t.Fatalf("expected the exit status to be returned")
+	} else if errors.As(err, new(*TimeoutError)) {
+		t.Fatalf("a quick failure is not a timeout: %v", err)
+	}
+
+	var stopped *TimeoutError
+	err := RunPolicy(exec.Command("/bin/sh", "-c", "sleep 10"), policy)
+	if !errors.As(err, &stopped) || stopped.Killed || stopped.Command != "sh" {
+		t.Fatalf("expected TERM to stop the command, got %v", err)
+	}
+
+	start := time.Now()
+	err = RunPolicy(exec.Command("/bin/sh", "-c", "trap '' TERM; while :; do sleep 0.01; done"), policy)
+	if !errors.As(err, &stopped) || !stopped.Killed {
+		t.Fatalf("expected a command ignoring TERM to be killed, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("stopping took %s", elapsed)
+	}
+}
+
+func TestRunWithoutTimeoutWaits(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/sh")
+	}
+	if err := RunPolicy(exec.Command("/bin/sh", "-c", "sleep 0.1"), Policy{}); err != nil {
+		t.Fatalf("RunPolicy: %v", err)
+	}
+}
+
+func TestCommandContextStopsAtKindLimit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/sh")
+	}
+	defer Set(DefaultConfig())
+	cfg := DefaultConfig()
+	cfg.Timeouts[Decompress] = 50 * time.Millisecond
+	cfg.Grace = 100 * time.Millisecond
+	Set(cfg)
+
+	cmd, done := CommandContext(context.Background(), Decompress, "/bin/sh", "-c", "sleep 10")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	var stopped *TimeoutError
+	if err := done(cmd.Wait()); !errors.As(err, &stopped) || stopped.Command != "sh" {
+		t.Fatalf("expected the decompress limit to stop the command, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd, done = CommandContext(ctx, Decompress, "/bin/sh", "-c", "sleep 10")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if err := done(cmd.Wait()); err == nil || errors.As(err, &stopped) {
+		t.Fatalf("expected a canceled command to fail without a timeout, got %v", err)
+	}
+}
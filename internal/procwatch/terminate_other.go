@@ -0,0 +1,14 @@
+//go:build !unix
+
+package procwatch
+
+import (
+	"errors"
+	"os"
+)
+
+// terminate cannot ask politely on this platform (Windows has no TERM for
+// console processes), so the caller kills p right away.
+func terminate(*os.Process) error {
+	return errors.New("terminate not supported")
+}
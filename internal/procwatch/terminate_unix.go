@@ -0,0 +1,13 @@
+//go:build unix
+
+package procwatch
+
+import (
+	"os"
+	"syscall"
+)
+
+// terminate asks p to exit.
+func terminate(p *os.Process) error {
+	return p.Signal(syscall.SIGTERM)
+}
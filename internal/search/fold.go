@@ -0,0 +1,81 @@
+package search
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// diacriticFolds maps Latin letters with diacritics to their base letter
+// (é→e, Ü→U, İ→I). It covers Latin-1 Supplement, Latin Extended-A/B and
+// Latin Extended Additional, where file names in European and Vietnamese
+// locales get their accents.
+var diacriticFolds = buildDiacriticFolds()
+
+// Letters whose stroke or ligature is not a combining mark, so canonical
+// decomposition leaves them alone.
+var strokedLetters = map[rune]rune{
+	'ł': 'l', 'Ł': 'L', 'ø': 'o', 'Ø': 'O', 'đ': 'd', 'Đ': 'D',
+	'ħ': 'h', 'Ħ': 'H', 'ı': 'i', 'ŧ': 't', 'Ŧ': 'T', 'ƀ': 'b', 'ƚ': 'l',
+}
+
+func buildDiacriticFolds() map[rune]rune {
+	folds := make(map[rune]rune)
+	for _, block := range [][2]rune{{0x00C0, 0x024F}, {0x1E00, 0x1EFF}} {
+		for r := block[0]; r <= block[1]; r++ {
+			decomposed := norm.NFD.String(string(r))
+			base, size := utf8.DecodeRuneInString(decomposed)
+			if size == len(decomposed) || base >= utf8.RuneSelf {
+				continue
+			}
+			marksOnly := true
+			for _, mark := range decomposed[size:] {
+				if !unicode.Is(unicode.Mn, mark) {
+					marksOnly = false
+					break
+				}
+			}
+			if marksOnly {
+				folds[r] = base
+			}
+		}
+	}
+	for r, base := range strokedLetters {
+		folds[r] = base
+	}
+	return folds
+}
+
+// FoldDiacritic returns the base letter of r, or r itself. It maps one rune
+// to one rune, so match positions computed on folded text line up with the
+// original name for highlighting.
+func FoldDiacritic(r rune) rune {
+	if r < utf8.RuneSelf {
+		return r
+	}
+	if base, ok := diacriticFolds[r]; ok {
+		return base
+	}
+	return r
+}
+
+// FoldDiacritics folds every rune of s with FoldDiacritic.
+func FoldDiacritics(s string) string {
+	for i, r := range s {
+		if FoldDiacritic(r) != r {
+			out := []rune(s[:i])
+			for _, r := range s[i:] {
+				out = append(out, FoldDiacritic(r))
+			}
+			return string(out)
+		}
+	}
+	return s
+}
+
+func foldDiacriticRunes(runes []rune) {
+	for i, r := range runes {
+		runes[i] = FoldDiacritic(r)
+	}
+}
@@ -0,0 +1,64 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFoldDiacritics(t *testing.T) {
+	cases := map[string]string{
+		"Über":         "Uber",
+		"café résumé":  "cafe resume",
+		"Łódź":         "Lodz",
+		"İstanbul":     "Istanbul",
+		"Tiếng Việt":   "Tieng Viet",
+		"straße":       "straße",
+		"plain-ascii1": "plain-ascii1",
+		"日本語":          "日本語",
+	}
+	for input, want := range cases {
+		if got := FoldDiacritics(input); got != want {
+			t.Errorf("FoldDiacritics(%q) = %q, want %q", input, got, want)
+		}
+		if len([]rune(FoldDiacritics(input))) != len([]rune(input)) {
+			t.Errorf("FoldDiacritics(%q) changed the rune count", input)
+		}
+	}
+}
+
+func TestGlobalSearchFoldsDiacritics(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"Über.txt", "uber-notes.md", "other.txt"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	searcher := NewGlobalSearcher(root, false, nil)
+	searcher.SetFoldDiacritics(true)
+	searcher.buildIndex(time.Now())
+
+	got := map[string]bool{}
+	for _, result := range searcher.searchIndex("uber", false) {
+		got[result.FileName] = true
+	}
+	if !got["Über.txt"] || !got["uber-notes.md"] || got["other.txt"] {
+		t.Fatalf("unexpected results %v", got)
+	}
+
+	// Smart case still applies: an uppercase query only matches uppercase.
+	got = map[string]bool{}
+	for _, result := range searcher.searchIndex("Uber", true) {
+		got[result.FileName] = true
+	}
+	if !got["Über.txt"] || got["uber-notes.md"] {
+		t.Fatalf("case-sensitive folded query gave %v", got)
+	}
+
+	searcher.SetFoldDiacritics(false)
+	if results := searcher.searchIndex("uber.txt", false); len(results) != 0 {
+		t.Fatalf("expected no match without folding, got %d", len(results))
+	}
+}
@@ -6,13 +6,14 @@ import (
 	"strconv"
 	"sync"
 	"time"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
 )
 
 const (
 	maxDisplayResults         = 10000
 	defaultMaxIndexResults    = 1000000
 	envMaxIndexResults        = "RDIR_INDEX_MAX_RESULTS"
-	envIndexMaxWorkers        = "RDIR_INDEX_MAX_WORKERS"
 	indexProgressInterval     = 150 * time.Millisecond
 	batchIntervalFast         = 75 * time.Millisecond
 	batchIntervalSlow         = 200 * time.Millisecond
@@ -41,10 +42,13 @@ type indexObserver struct {
 type GlobalSearcher struct {
 	matcher        *FuzzyMatcher
 	rootPath       string
+	fsys           fsutil.FS
 	ignoreProvider *ignoreProvider
 	hideHidden     bool
+	foldDiacritics bool
 
 	maxIndexResults int
+	indexWorkers    int // 0: workers.Count(workers.TaskIndex)
 	progress        IndexTelemetry
 	progressCb      func(IndexTelemetry)
 
@@ -55,6 +59,7 @@ type GlobalSearcher struct {
 	cache            *searchCache
 	indexGen         int
 	indexReady       bool
+	indexTruncated   bool // the build stopped at maxIndexResults
 	indexErr         error
 	indexBuilding    bool
 	indexWatchers    map[int]chan indexSnapshot
@@ -64,10 +69,22 @@ type GlobalSearcher struct {
 	cancelMu sync.Mutex
 	cancel   context.CancelFunc
 	token    int
+
+	// lifetime is the parent of every context the searcher creates (index
+	// build, query streams); Close cancels it.
+	lifetime context.Context
+	stop     context.CancelFunc
 }
 
 // NewGlobalSearcher creates a new global searcher from a root path.
 func NewGlobalSearcher(rootPath string, hideHidden bool, progressCb func(IndexTelemetry)) *GlobalSearcher {
+	return NewGlobalSearcherFS(fsutil.Local, rootPath, hideHidden, progressCb)
+}
+
+// NewGlobalSearcherFS is NewGlobalSearcher walking fsys. Global ignore files
+// (core.excludesFile, ~/.gitignore) are still read from the local disk.
+func NewGlobalSearcherFS(fsys fsutil.FS, rootPath string, hideHidden bool, progressCb func(IndexTelemetry)) *GlobalSearcher {
+	fsys = fsutil.OrLocal(fsys)
 	maxIndexResults := parseEnvInt(envMaxIndexResults, defaultMaxIndexResults)
 	if maxIndexResults < maxDisplayResults {
 		maxIndexResults = maxDisplayResults
@@ -80,10 +97,14 @@ func NewGlobalSearcher(rootPath string, hideHidden bool, progressCb func(IndexTe
 		Disabled:        false,
 	}
 
+	lifetime, stop := context.WithCancel(context.Background())
 	gs := &GlobalSearcher{
+		lifetime:        lifetime,
+		stop:            stop,
 		matcher:         NewFuzzyMatcher(),
 		rootPath:        rootPath,
-		ignoreProvider:  newIgnoreProvider(rootPath),
+		fsys:            fsys,
+		ignoreProvider:  newIgnoreProvider(fsys, rootPath),
 		hideHidden:      hideHidden,
 		maxIndexResults: maxIndexResults,
 		progress:        progress,
@@ -95,6 +116,54 @@ func NewGlobalSearcher(rootPath string, hideHidden bool, progressCb func(IndexTe
 	return gs
 }
 
+// SetFoldDiacritics makes queries ignore diacritics, so "uber" matches
+// "Über". Call it before the first search.
+func (gs *GlobalSearcher) SetFoldDiacritics(on bool) {
+	gs.foldDiacritics = on
+}
+
+// SetIndexBudget caps the index build at workers goroutines and maxFiles
+// files; zero keeps the default. Call it before the first search or
+// WarmIndex.
+func (gs *GlobalSearcher) SetIndexBudget(workers, maxFiles int) {
+	gs.indexMu.Lock()
+	defer gs.indexMu.Unlock()
+	if workers > 0 {
+		gs.indexWorkers = workers
+	}
+	if maxFiles > 0 {
+		gs.maxIndexResults = max(min(maxFiles, gs.maxIndexResults), maxDisplayResults)
+		gs.progress.MaxIndexResults = gs.maxIndexResults
+	}
+}
+
+// WarmIndex starts building the index without running a query, so a later
+// search can answer from it at once.
+func (gs *GlobalSearcher) WarmIndex() {
+	gs.ensureIndexStream()
+}
+
+// IndexComplete reports whether the index is built and holds every file
+// under the root rather than stopping at the file limit.
+func (gs *GlobalSearcher) IndexComplete() bool {
+	gs.indexMu.Lock()
+	defer gs.indexMu.Unlock()
+	return gs.indexReady && !gs.indexTruncated && gs.indexErr == nil
+}
+
+// IndexTruncated reports whether the index was built but stopped at the
+// file limit, so the tree is too large to index in full.
+func (gs *GlobalSearcher) IndexTruncated() bool {
+	gs.indexMu.Lock()
+	defer gs.indexMu.Unlock()
+	return gs.indexReady && gs.indexTruncated
+}
+
+// FoldsDiacritics reports whether queries ignore diacritics.
+func (gs *GlobalSearcher) FoldsDiacritics() bool {
+	return gs.foldDiacritics
+}
+
 // SearchRecursive performs a blocking search by delegating to the async pipeline.
 func (gs *GlobalSearcher) SearchRecursive(query string, caseSensitive bool) []GlobalSearchResult {
 	done := make(chan []GlobalSearchResult, 1)
@@ -130,11 +199,27 @@ func (gs *GlobalSearcher) CachedResults(query string, caseSensitive bool) ([]Glo
 	return gs.lookupCache(query, caseSensitive)
 }
 
-// CancelOngoingSearch stops any in-flight work.
+// CancelOngoingSearch stops the in-flight query; the index build keeps going.
 func (gs *GlobalSearcher) CancelOngoingSearch() {
 	gs.cancelOngoingSearch()
 }
 
+// Close cancels the in-flight query and any index build. The searcher must
+// not be used afterwards.
+func (gs *GlobalSearcher) Close() {
+	gs.cancelOngoingSearch()
+	if gs.stop != nil {
+		gs.stop()
+	}
+}
+
+func (gs *GlobalSearcher) lifetimeContext() context.Context {
+	if gs.lifetime == nil {
+		return context.Background()
+	}
+	return gs.lifetime
+}
+
 // UsingIndex reports whether the searcher can currently answer queries from the index.
 func (gs *GlobalSearcher) UsingIndex() bool {
 	gs.indexMu.Lock()
@@ -294,6 +379,7 @@ func (gs *GlobalSearcher) lookupCache(query string, caseSensitive bool) ([]Globa
 		rootPath: gs.rootPath,
 		query:    normalizeCacheQuery(query, caseSensitive),
 		caseSens: caseSensitive,
+		fold:     gs.foldDiacritics,
 		indexGen: gs.indexGeneration(),
 	}
 	return gs.cache.get(key)
@@ -307,6 +393,7 @@ func (gs *GlobalSearcher) storeCache(query string, caseSensitive bool, results [
 		rootPath: gs.rootPath,
 		query:    normalizeCacheQuery(query, caseSensitive),
 		caseSens: caseSensitive,
+		fold:     gs.foldDiacritics,
 		indexGen: gs.indexGeneration(),
 	}
 	gs.cache.put(key, results)
@@ -9,6 +9,13 @@ const streamingEmitThreshold = 64
 
 // SearchRecursiveAsync performs global search asynchronously by streaming index updates.
 func (gs *GlobalSearcher) SearchRecursiveAsync(query string, caseSensitive bool, callback func(results []GlobalSearchResult, isDone bool, inProgress bool)) {
+	gs.SearchRecursiveAsyncContext(context.Background(), query, caseSensitive, callback)
+}
+
+// SearchRecursiveAsyncContext is SearchRecursiveAsync bound to parent: the
+// stream stops (without a final callback) once parent is cancelled or its
+// deadline passes. Starting a new search still cancels the previous one.
+func (gs *GlobalSearcher) SearchRecursiveAsyncContext(parent context.Context, query string, caseSensitive bool, callback func(results []GlobalSearchResult, isDone bool, inProgress bool)) {
 	gs.cancelOngoingSearch()
 
 	if cached, ok := gs.lookupCache(query, caseSensitive); ok {
@@ -26,9 +33,15 @@ func (gs *GlobalSearcher) SearchRecursiveAsync(query string, caseSensitive bool,
 	}
 
 	tokens, matchAll := prepareQueryTokens(query, caseSensitive)
+	gs.foldQueryTokens(tokens)
 	gs.orderTokens(tokens)
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancelCtx := context.WithCancel(parent)
+	stopWithSearcher := context.AfterFunc(gs.lifetimeContext(), cancelCtx)
+	cancel := func() {
+		stopWithSearcher()
+		cancelCtx()
+	}
 	token := gs.setCancel(cancel)
 
 	gs.ensureIndexStream()
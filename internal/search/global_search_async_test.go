@@ -1,6 +1,8 @@
 package search
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -107,3 +109,62 @@ func TestSearchRecursiveAsyncReadyIndexSingleCallback(t *testing.T) {
 		t.Fatalf("expected exactly one callback, got %d", callbacks)
 	}
 }
+
+func TestSearchRecursiveAsyncContextStopsOnCancel(t *testing.T) {
+	root := t.TempDir()
+	for i := 0; i < 50; i++ {
+		name := fmt.Sprintf("file-%03d.txt", i)
+		if err := os.WriteFile(filepath.Join(root, name), []byte("data"), 0o644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	searcher := NewGlobalSearcher(root, false, nil)
+	defer searcher.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := make(chan struct{}, 1)
+	searcher.SearchRecursiveAsyncContext(ctx, "file", false, func(results []GlobalSearchResult, isDone bool, inProgress bool) {
+		select {
+		case called <- struct{}{}:
+		default:
+		}
+	})
+
+	select {
+	case <-called:
+		t.Fatalf("expected no callback after parent context was cancelled")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestGlobalSearcherCloseEndsIndexBuild(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("data"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	searcher := NewGlobalSearcher(root, false, nil)
+	searcher.Close()
+	searcher.ensureIndexStream()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		searcher.indexMu.Lock()
+		building, ready, err := searcher.indexBuilding, searcher.indexReady, searcher.indexErr
+		searcher.indexMu.Unlock()
+		if !building {
+			if ready {
+				t.Fatalf("closed searcher should not publish a ready index")
+			}
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("expected context.Canceled index error, got %v", err)
+			}
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("index build did not stop after Close")
+}
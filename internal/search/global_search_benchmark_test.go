@@ -233,7 +233,7 @@ func BenchmarkIndexCandidatesAND(b *testing.B) {
 	}
 
 	b.Setenv(envMaxIndexResults, strconv.Itoa(1_000_000))
-	b.Setenv(envIndexMaxWorkers, "2") // keep indexing predictable for benchmarks
+	b.Setenv("RDIR_WORKERS_INDEX", "2") // keep indexing predictable for benchmarks
 
 	searcher := NewGlobalSearcher(root, false, nil)
 	_ = searcher.SearchRecursive("warmup", false)
@@ -353,7 +353,7 @@ func BenchmarkIgnoreProviderMatcherFor(b *testing.B) {
 		b.ReportAllocs()
 		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			provider := newIgnoreProvider(root)
+			provider := newIgnoreProvider(nil, root)
 			for _, key := range keys {
 				provider.MatcherFor(key)
 			}
@@ -362,7 +362,7 @@ func BenchmarkIgnoreProviderMatcherFor(b *testing.B) {
 
 	b.Run("Warm", func(b *testing.B) {
 		b.ReportAllocs()
-		provider := newIgnoreProvider(root)
+		provider := newIgnoreProvider(nil, root)
 		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
 			for _, key := range keys {
@@ -9,6 +9,7 @@ type cacheKey struct {
 	rootPath string
 	query    string
 	caseSens bool
+	fold     bool
 	indexGen int
 }
 
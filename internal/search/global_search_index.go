@@ -5,7 +5,6 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
-	"runtime"
 	"sort"
 	"strings"
 	"sync"
@@ -14,6 +13,7 @@ import (
 	"unicode/utf8"
 
 	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+	"github.com/kk-code-lab/rdir/internal/workers"
 )
 
 type indexedEntry struct {
@@ -54,6 +54,7 @@ func parseIndexSpanMode() spanRequest {
 
 func (gs *GlobalSearcher) searchIndex(query string, caseSensitive bool) []GlobalSearchResult {
 	tokens, matchAll := prepareQueryTokens(query, caseSensitive)
+	gs.foldQueryTokens(tokens)
 	gs.orderTokens(tokens)
 	entries := gs.snapshotEntries(0, -1)
 	if matchAll {
@@ -207,7 +208,7 @@ func (gs *GlobalSearcher) indexCandidates(tokens []queryToken, entries []indexed
 }
 
 func (gs *GlobalSearcher) buildIndex(start time.Time) {
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(gs.lifetimeContext())
 	defer cancel()
 
 	tracker := newProgressTracker(start, indexProgressInterval, gs.emitProgress)
@@ -223,22 +224,13 @@ func (gs *GlobalSearcher) buildIndex(start time.Time) {
 	gs.broadcastSnapshotLocked()
 	gs.indexMu.Unlock()
 
-	workerCount := runtime.NumCPU() - 1
-	if workerCount < 2 {
-		workerCount = 2
-	}
-	defaultMaxWorkers := 8
-	maxWorkers := parseEnvInt(envIndexMaxWorkers, 0)
-	if maxWorkers > 0 {
-		if maxWorkers < 1 {
-			maxWorkers = 1
-		}
-		if maxWorkers < workerCount {
-			workerCount = maxWorkers
-		}
-	} else {
-		workerCount = clampInt(workerCount, 2, defaultMaxWorkers)
+	workerCount := workers.Count(workers.TaskIndex)
+	gs.indexMu.Lock()
+	if gs.indexWorkers > 0 {
+		workerCount = min(workerCount, gs.indexWorkers)
 	}
+	maxResults := gs.maxIndexResults
+	gs.indexMu.Unlock()
 
 	dirBuffer := clampInt(workerCount*8, 32, 1024)
 	fileBuffer := clampInt(workerCount*64, 512, 16384)
@@ -265,10 +257,11 @@ func (gs *GlobalSearcher) buildIndex(start time.Time) {
 		dirKey := normalizeDirKey(relDir)
 		dirMatcher := gs.ignoreProvider.MatcherFor(dirKey)
 
-		entriesDir, err := os.ReadDir(dir)
+		entriesDir, err := gs.fsys.ReadDir(ctx, dir)
 		if err != nil {
 			return nil
 		}
+		sort.Slice(entriesDir, func(i, j int) bool { return entriesDir[i].Name() < entriesDir[j].Name() })
 
 		childDirs := make([]string, 0, len(entriesDir))
 		for _, entry := range entriesDir {
@@ -328,7 +321,9 @@ func (gs *GlobalSearcher) buildIndex(start time.Time) {
 				} else {
 					select {
 					case <-ctx.Done():
-						closeDirJobs()
+						// Don't close dirJobs here: other workers (or the root
+						// seeder) may still be mid-send. They all select on
+						// ctx.Done, so returning is enough to drain the pool.
 						return
 					case dir, ok = <-dirJobs:
 						if !ok {
@@ -399,7 +394,7 @@ func (gs *GlobalSearcher) buildIndex(start time.Time) {
 
 		totalFiles++
 		tracker.update(totalFiles)
-		if totalFiles >= gs.maxIndexResults {
+		if totalFiles >= maxResults {
 			cancel()
 		}
 	}
@@ -407,11 +402,24 @@ func (gs *GlobalSearcher) buildIndex(start time.Time) {
 	workerWG.Wait()
 	tracker.flush(totalFiles)
 
+	if err := gs.lifetimeContext().Err(); err != nil {
+		// The searcher was closed; wake observers so their streams end instead
+		// of publishing a partial index as complete.
+		gs.indexMu.Lock()
+		gs.indexBuilding = false
+		gs.indexErr = err
+		gs.broadcastSnapshotLocked()
+		gs.indexMu.Unlock()
+		progressDebugf("buildIndex cancelled total=%d", totalFiles)
+		return
+	}
+
 	finished := time.Now()
 
 	gs.indexMu.Lock()
 	gs.indexReady = true
 	gs.indexBuilding = false
+	gs.indexTruncated = totalFiles >= maxResults
 	gs.indexErr = nil
 	gs.indexTotalFiles = totalFiles
 	gs.pendingBroadcast = 0
@@ -585,6 +593,11 @@ func makeRuneBitset(lower string) runeBitset {
 		if idx := runeBitIndex(r); idx >= 0 {
 			bits.set(idx)
 		}
+		if base := FoldDiacritic(r); base != r {
+			if idx := runeBitIndex(base); idx >= 0 {
+				bits.set(idx)
+			}
+		}
 	}
 	return bits
 }
@@ -595,16 +608,22 @@ func runeKeysForPath(lower string) []rune {
 	}
 	seen := make(map[rune]struct{})
 	keys := make([]rune, 0, 8)
-	for _, r := range lower {
+	add := func(r rune) {
 		if !isRuneIndexable(r) {
-			continue
+			return
 		}
 		if _, ok := seen[r]; ok {
-			continue
+			return
 		}
 		seen[r] = struct{}{}
 		keys = append(keys, r)
 	}
+	// Letters with diacritics are also filed under their base letter, so a
+	// folded query finds them.
+	for _, r := range lower {
+		add(r)
+		add(FoldDiacritic(r))
+	}
 	return keys
 }
 
@@ -245,3 +245,33 @@ func TestIndexCandidatesRequireAllTokenRunes(t *testing.T) {
 		t.Fatalf("expected foo-bar only, got %#v", got)
 	}
 }
+
+func TestIndexBudgetLeavesTruncatedIndexIncomplete(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(root, name), nil, 0o644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	searcher := NewGlobalSearcher(root, false, nil)
+	searcher.SetIndexBudget(1, 1)
+	if searcher.maxIndexResults != maxDisplayResults || searcher.indexWorkers != 1 {
+		t.Fatalf("budget = %d workers, %d files; want 1 and the %d floor", searcher.indexWorkers, searcher.maxIndexResults, maxDisplayResults)
+	}
+	searcher.WarmIndex()
+	deadline := time.Now().Add(5 * time.Second)
+	for !searcher.IndexComplete() {
+		if time.Now().After(deadline) {
+			t.Fatal("warm index did not complete")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	truncated := NewGlobalSearcher(root, false, nil)
+	truncated.maxIndexResults = 2
+	truncated.buildIndex(time.Now())
+	if truncated.IndexComplete() {
+		t.Fatal("an index stopped at the file limit should not count as complete")
+	}
+}
@@ -32,6 +32,9 @@ func (gs *GlobalSearcher) matchTokens(tokens []queryToken, relPath string, caseS
 	fold := !caseSensitive
 	pathRunes, pathBuf := acquireRunes(relPath, fold)
 	defer releaseRunes(pathBuf)
+	if gs.foldDiacritics {
+		foldDiacriticRunes(pathRunes)
+	}
 
 	pathScore, pathDetails, ok := gs.aggregateTokenMatches(tokens, relPath, pathRunes, spanMode)
 	if !ok {
@@ -48,6 +51,9 @@ func (gs *GlobalSearcher) matchTokens(tokens []queryToken, relPath string, caseS
 	}
 	if filename != "" {
 		fileRunes, fileBuf := acquireRunes(filename, fold)
+		if gs.foldDiacritics {
+			foldDiacriticRunes(fileRunes)
+		}
 		fileOffset := len(pathRunes) - len(fileRunes)
 		if fileOffset < 0 {
 			fileOffset = 0
@@ -126,6 +132,19 @@ func prepareQueryTokens(query string, caseSensitive bool) ([]queryToken, bool) {
 	return tokens, false
 }
 
+// foldQueryTokens strips diacritics from the tokens when folding is on; the
+// candidate prefilter and the matcher then see the same base letters.
+func (gs *GlobalSearcher) foldQueryTokens(tokens []queryToken) {
+	if !gs.foldDiacritics {
+		return
+	}
+	for i := range tokens {
+		tokens[i].pattern = FoldDiacritics(tokens[i].pattern)
+		tokens[i].folded = FoldDiacritics(tokens[i].folded)
+		foldDiacriticRunes(tokens[i].runes)
+	}
+}
+
 // orderTokens reorders tokens to run the most selective ones first.
 // With an index in place we approximate selectivity using rune bucket sizes,
 // falling back to length-based ordering when index stats are unavailable.
@@ -2,26 +2,31 @@ package search
 
 import (
 	"bufio"
+	"context"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
 	"sync"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
 )
 
 type ignoreProvider struct {
+	fsys  fsutil.FS // the searched tree; global ignore files are always local
 	root  string
 	cache sync.Map // map[string]*GitignoreMatcher
 }
 
-func newIgnoreProvider(root string) *ignoreProvider {
+func newIgnoreProvider(fsys fsutil.FS, root string) *ignoreProvider {
 	provider := &ignoreProvider{
+		fsys: fsutil.OrLocal(fsys),
 		root: root,
 	}
 
 	base := NewGitignoreMatcher()
 	provider.applyGlobalPatterns(base)
-	provider.addPatternFileIfExists(base, filepath.Join(root, ".git", "info", "exclude"), root)
+	provider.addPatternFileIfExists(provider.fsys, base, filepath.Join(root, ".git", "info", "exclude"), root)
 	provider.applyDirectoryPatterns(base, root)
 	provider.cache.Store(".", base)
 
@@ -54,15 +59,15 @@ func (p *ignoreProvider) fullPathFromKey(key string) string {
 }
 
 func (p *ignoreProvider) applyDirectoryPatterns(matcher *GitignoreMatcher, dir string) {
-	info, err := os.Stat(dir)
+	info, err := p.fsys.Stat(dir)
 	if err != nil || !info.IsDir() {
 		return
 	}
 
 	// Lowest priority first so later files can override with negations.
-	p.addPatternFileIfExists(matcher, filepath.Join(dir, ".gitignore"), dir)
-	p.addPatternFileIfExists(matcher, filepath.Join(dir, ".ignore"), dir)
-	p.addPatternFileIfExists(matcher, filepath.Join(dir, ".rdirignore"), dir)
+	p.addPatternFileIfExists(p.fsys, matcher, filepath.Join(dir, ".gitignore"), dir)
+	p.addPatternFileIfExists(p.fsys, matcher, filepath.Join(dir, ".ignore"), dir)
+	p.addPatternFileIfExists(p.fsys, matcher, filepath.Join(dir, ".rdirignore"), dir)
 }
 
 func (p *ignoreProvider) applyGlobalPatterns(matcher *GitignoreMatcher) {
@@ -75,7 +80,7 @@ func (p *ignoreProvider) applyGlobalPatterns(matcher *GitignoreMatcher) {
 		if _, ok := seen[candidate]; ok {
 			return
 		}
-		if p.addPatternFileIfExists(matcher, candidate, p.root) {
+		if p.addPatternFileIfExists(fsutil.Local, matcher, candidate, p.root) {
 			seen[candidate] = struct{}{}
 		}
 	}
@@ -89,17 +94,17 @@ func (p *ignoreProvider) applyGlobalPatterns(matcher *GitignoreMatcher) {
 	}
 }
 
-func (p *ignoreProvider) addPatternFileIfExists(matcher *GitignoreMatcher, filePath string, base string) bool {
+func (p *ignoreProvider) addPatternFileIfExists(fsys fsutil.FS, matcher *GitignoreMatcher, filePath string, base string) bool {
 	if filePath == "" {
 		return false
 	}
 
-	info, err := os.Stat(filePath)
+	info, err := fsys.Stat(filePath)
 	if err != nil || info.IsDir() {
 		return false
 	}
 
-	data, err := os.ReadFile(filePath)
+	data, err := fsutil.ReadFileHeadFS(context.Background(), fsys, filePath, info.Size())
 	if err != nil || len(data) == 0 {
 		return false
 	}
@@ -110,7 +115,7 @@ func (p *ignoreProvider) addPatternFileIfExists(matcher *GitignoreMatcher, fileP
 
 func (p *ignoreProvider) coreExcludesFile() string {
 	configPath := filepath.Join(p.root, ".git", "config")
-	file, err := os.Open(configPath)
+	file, err := p.fsys.Open(configPath)
 	if err != nil {
 		return ""
 	}
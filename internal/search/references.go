@@ -0,0 +1,222 @@
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+)
+
+// ReferenceLimits bounds a reference scan so it stays quick on large trees.
+type ReferenceLimits struct {
+	MaxFiles    int   // text files read before the scan stops
+	MaxFileSize int64 // larger files are skipped
+	MaxHits     int   // hits collected before the scan stops
+}
+
+// DefaultReferenceLimits is what a scan before a move uses.
+var DefaultReferenceLimits = ReferenceLimits{MaxFiles: 5000, MaxFileSize: 1 << 20, MaxHits: 200}
+
+// ReferenceHit is one line mentioning a name.
+type ReferenceHit struct {
+	Path   string // absolute path of the mentioning file
+	Line   int    // 1-based
+	Column int    // 1-based byte offset of the name in the line
+	Name   string
+	Text   string
+}
+
+// ReferenceReport is the outcome of FindReferences.
+type ReferenceReport struct {
+	Hits      []ReferenceHit
+	Files     int  // files with at least one hit
+	Scanned   int  // text files read
+	Truncated bool // a limit stopped the scan early
+	Canceled  bool // ctx ended the scan early
+}
+
+// ReferenceRoot returns the directory a reference scan for entries of dir
+// covers: the enclosing git work tree, or dir itself outside one.
+func ReferenceRoot(dir string) string {
+	dir = filepath.Clean(dir)
+	for current := dir; ; {
+		if _, err := os.Lstat(filepath.Join(current, ".git")); err == nil {
+			return current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir
+		}
+		current = parent
+	}
+}
+
+// FindReferences looks for names in the text files under root, the way a
+// rename would need them updated: a name only counts when it is not part of a
+// longer file name ("log.txt" does not match "changelog.txt"). Paths in skip
+// (and everything below them) are not read, nor are .git and the files the
+// gitignore rules of global search exclude. The walk stops once ctx ends.
+func FindReferences(ctx context.Context, root string, names []string, skip []string, limits ReferenceLimits) ReferenceReport {
+	var report ReferenceReport
+	names = uniqueNames(names)
+	if len(names) == 0 {
+		return report
+	}
+	skipped := make(map[string]struct{}, len(skip))
+	for _, path := range skip {
+		skipped[filepath.Clean(path)] = struct{}{}
+	}
+	ignore := newIgnoreProvider(fsutil.Local, root)
+
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() && path != root {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if ctx.Err() != nil {
+			report.Canceled = true
+			return fs.SkipAll
+		}
+		if path == root {
+			return nil
+		}
+		if _, ok := skipped[path]; ok || ignoredReferencePath(ignore, root, path, d) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		if report.Scanned >= limits.MaxFiles || len(report.Hits) >= limits.MaxHits {
+			report.Truncated = true
+			return fs.SkipAll
+		}
+		hits, scanned := scanReferences(path, names, limits)
+		if !scanned {
+			return nil
+		}
+		report.Scanned++
+		if len(hits) > 0 {
+			report.Files++
+			if room := limits.MaxHits - len(report.Hits); len(hits) > room {
+				hits = hits[:room]
+				report.Truncated = true
+			}
+			report.Hits = append(report.Hits, hits...)
+		}
+		return nil
+	})
+	return report
+}
+
+func ignoredReferencePath(ignore *ignoreProvider, root, path string, d fs.DirEntry) bool {
+	return ignoredProjectPath(ignore, root, path, d.IsDir())
+}
+
+// IgnoreFilter reports which paths under root a project walk leaves out:
+// .git, system clutter and whatever the .gitignore rules match. Paths
+// outside root are never ignored.
+func IgnoreFilter(root string) func(path string, isDir bool) bool {
+	ignore := newIgnoreProvider(fsutil.Local, root)
+	return func(path string, isDir bool) bool {
+		if rel, err := filepath.Rel(root, path); err != nil || !filepath.IsLocal(rel) {
+			return false
+		}
+		return ignoredProjectPath(ignore, root, path, isDir)
+	}
+}
+
+func ignoredProjectPath(ignore *ignoreProvider, root, path string, isDir bool) bool {
+	name := filepath.Base(path)
+	if isDir && name == ".git" {
+		return true
+	}
+	if fsutil.ShouldHideFromListing(path, name) {
+		return true
+	}
+	rel, err := filepath.Rel(root, filepath.Dir(path))
+	if err != nil {
+		return false
+	}
+	return ignore.MatcherFor(normalizeDirKey(rel)).MatchWithType(path, isDir)
+}
+
+// scanReferences reads one file and returns its hits; scanned is false for
+// files that are too large, unreadable or not text.
+func scanReferences(path string, names []string, limits ReferenceLimits) (hits []ReferenceHit, scanned bool) {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > limits.MaxFileSize {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !fsutil.IsTextFile(path, data) {
+		return nil, false
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), int(limits.MaxFileSize)+1)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		for _, name := range names {
+			if col := indexName(text, name); col >= 0 {
+				hits = append(hits, ReferenceHit{Path: path, Line: line, Column: col + 1, Name: name, Text: text})
+				if len(hits) >= limits.MaxHits {
+					return hits, true
+				}
+			}
+		}
+	}
+	return hits, true
+}
+
+// indexName returns the byte offset of the first occurrence of name in text
+// that is not part of a longer file name, or -1.
+func indexName(text, name string) int {
+	for offset := 0; offset < len(text); {
+		idx := strings.Index(text[offset:], name)
+		if idx < 0 {
+			return -1
+		}
+		start := offset + idx
+		end := start + len(name)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		// A dot may follow ("see build.log.") but not precede: ".build.log"
+		// and "old.build.log" are other files.
+		if (start == 0 || !isNameRune(before) && before != '.') && (end == len(text) || !isNameRune(after)) {
+			return start
+		}
+		offset = start + 1
+	}
+	return -1
+}
+
+func isNameRune(r rune) bool {
+	return r == '_' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func uniqueNames(names []string) []string {
+	seen := make(map[string]struct{}, len(names))
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		out = append(out, name)
+	}
+	return out
+}
@@ -0,0 +1,93 @@
+package search
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIndexNameSkipsLongerFileNames(t *testing.T) {
+	cases := []struct {
+		text, name string
+		want       int
+	}{
+		{"see build.log", "build.log", 4},
+		{"build.log.", "build.log", 0},
+		{`import "./lib/util.js"`, "util.js", 14},
+		{"changelog.txt", "log.txt", -1},
+		{"old-build.log and build.log", "build.log", 18},
+		{".build.log", "build.log", -1},
+		{"build.logs", "build.log", -1},
+	}
+	for _, tc := range cases {
+		if got := indexName(tc.text, tc.name); got != tc.want {
+			t.Errorf("indexName(%q, %q) = %d, want %d", tc.text, tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestFindReferencesHonorsIgnoreSkipAndLimits(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	write(".gitignore", "dist/\n")
+	write("README.md", "Helpers live in util.go.\r\nSee also util.go and xutil.go\n")
+	write("cmd/main.go", "package main // uses util.go\n")
+	write("dist/bundle.js", "util.go\n")
+	write(".git/HEAD", "util.go\n")
+	write("util.go", "// util.go\n")
+	write("image.png", "util.go\n")
+
+	report := FindReferences(context.Background(), root, []string{"util.go"}, []string{filepath.Join(root, "util.go")}, DefaultReferenceLimits)
+	if report.Files != 2 || len(report.Hits) != 3 || report.Truncated {
+		t.Fatalf("report = %+v, want 3 hits in 2 files", report)
+	}
+	hit := report.Hits[0]
+	if hit.Path != filepath.Join(root, "README.md") || hit.Line != 1 || hit.Column != 17 || hit.Text != "Helpers live in util.go." {
+		t.Fatalf("first hit = %+v", hit)
+	}
+
+	limited := FindReferences(context.Background(), root, []string{"util.go"}, nil, ReferenceLimits{MaxFiles: 10, MaxFileSize: 1 << 20, MaxHits: 1})
+	if len(limited.Hits) != 1 || !limited.Truncated {
+		t.Fatalf("limited report = %+v, want 1 hit and truncated", limited)
+	}
+}
+
+func TestReferenceRootFindsWorkTree(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if got := ReferenceRoot(nested); got != nested {
+		t.Fatalf("ReferenceRoot without .git = %q, want %q", got, nested)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".git"), []byte("gitdir: elsewhere\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if got := ReferenceRoot(nested); got != root {
+		t.Fatalf("ReferenceRoot = %q, want %q", got, root)
+	}
+}
+
+func TestFindReferencesStopsWhenCanceled(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "README.md"), []byte("see util.go\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report := FindReferences(ctx, root, []string{"util.go"}, nil, DefaultReferenceLimits)
+	if !report.Canceled || len(report.Hits) != 0 {
+		t.Fatalf("report = %+v, want a canceled scan without hits", report)
+	}
+}
@@ -0,0 +1,242 @@
+// Package share lets other processes follow an rdir session read-only. The
+// sharing session listens on a Unix socket and sends every follower one JSON
+// object per line, each a complete snapshot of where the session is; a
+// follower connecting late gets the latest snapshot first.
+package share
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxLine bounds one snapshot line read by a follower.
+const maxLine = 1 << 20
+
+// writeTimeout drops followers that stop reading.
+const writeTimeout = time.Second
+
+// followerQueue bounds the snapshots waiting for one follower; when it is
+// full the oldest is dropped, as each snapshot replaces the one before.
+const followerQueue = 8
+
+// Dir is where sharing sessions put their sockets, one per process.
+func Dir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil || dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "rdir", "share")
+}
+
+// SocketPath returns the socket a sharing session with the given pid uses.
+func SocketPath(pid int) string {
+	return filepath.Join(Dir(), strconv.Itoa(pid)+".sock")
+}
+
+// Server sends snapshots to connected followers.
+type Server struct {
+	listener net.Listener
+	path     string
+
+	mu        sync.Mutex
+	followers map[*follower]struct{}
+	last      []byte // latest snapshot with its newline
+	closed    bool
+}
+
+// follower is one connection and the snapshots queued for it. Its own
+// goroutine writes them, so a slow follower never blocks Publish.
+type follower struct {
+	conn   net.Conn
+	frames chan []byte
+}
+
+// queue hands line to the writer without blocking, dropping the oldest
+// queued snapshot when the queue is full. Callers hold Server.mu.
+func (f *follower) queue(line []byte) {
+	for {
+		select {
+		case f.frames <- line:
+			return
+		default:
+		}
+		select {
+		case <-f.frames:
+		default:
+		}
+	}
+}
+
+// Listen starts sharing on path, which only the current user can open.
+func Listen(path string) (*Server, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	_ = os.Remove(path)
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	s := &Server{listener: listener, path: path, followers: make(map[*follower]struct{})}
+	go s.accept()
+	return s, nil
+}
+
+// Path returns the socket path followers connect to.
+func (s *Server) Path() string {
+	return s.path
+}
+
+func (s *Server) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		f := &follower{conn: conn, frames: make(chan []byte, followerQueue)}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		if s.last != nil {
+			f.queue(s.last)
+		}
+		s.followers[f] = struct{}{}
+		s.mu.Unlock()
+		go s.write(f)
+	}
+}
+
+// write sends f its queued snapshots until Close or a failed write, which
+// disconnects it.
+func (s *Server) write(f *follower) {
+	for line := range f.frames {
+		_ = f.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if _, err := f.conn.Write(line); err != nil {
+			s.mu.Lock()
+			delete(s.followers, f)
+			s.mu.Unlock()
+			_ = f.conn.Close()
+			return
+		}
+	}
+}
+
+// Followers returns how many followers are connected.
+func (s *Server) Followers() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.followers)
+}
+
+// Publish queues line (without the trailing newline) for every follower
+// unless it repeats the previous one. It never waits for the network:
+// followers that fall behind miss snapshots, and ones that stop reading are
+// disconnected by their writer.
+func (s *Server) Publish(line []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed || (s.last != nil && bytes.Equal(line, s.last[:len(s.last)-1])) {
+		return
+	}
+	// A fresh copy per line: queued snapshots are shared with the writers.
+	s.last = append(append(make([]byte, 0, len(line)+1), line...), '\n')
+	for f := range s.followers {
+		f.queue(s.last)
+	}
+}
+
+// Close stops sharing, disconnects followers and removes the socket.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for f := range s.followers {
+		close(f.frames)
+		_ = f.conn.Close()
+	}
+	s.followers = nil
+	s.mu.Unlock()
+	err := s.listener.Close()
+	_ = os.Remove(s.path)
+	return err
+}
+
+// Resolve turns a --follow argument into a socket path: a path is used as
+// is, a number is a sharing process id, and "" picks the only session
+// sharing right now.
+func Resolve(target string) (string, error) {
+	if target != "" {
+		if _, err := strconv.Atoi(target); err == nil {
+			return filepath.Join(Dir(), target+".sock"), nil
+		}
+		return target, nil
+	}
+	matches, _ := filepath.Glob(filepath.Join(Dir(), "*.sock"))
+	var live []string
+	for _, path := range matches {
+		conn, err := net.DialTimeout("unix", path, time.Second)
+		if err != nil {
+			// Left behind by a session that did not exit cleanly.
+			_ = os.Remove(path)
+			continue
+		}
+		_ = conn.Close()
+		live = append(live, path)
+	}
+	switch len(live) {
+	case 0:
+		return "", errors.New("no rdir session is sharing (start one with rdir --share)")
+	case 1:
+		return live[0], nil
+	default:
+		return "", fmt.Errorf("%d rdir sessions are sharing; pass the pid or socket, e.g. rdir --follow %s", len(live), live[0])
+	}
+}
+
+// Follower reads snapshots from a sharing session.
+type Follower struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+}
+
+// Dial connects to the sharing session at path.
+func Dial(path string) (*Follower, error) {
+	conn, err := net.DialTimeout("unix", path, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), maxLine)
+	return &Follower{conn: conn, scanner: scanner}, nil
+}
+
+// Next blocks until the next snapshot line arrives. It returns an error
+// when the session ends or Close is called.
+func (f *Follower) Next() ([]byte, error) {
+	if f.scanner.Scan() {
+		return f.scanner.Bytes(), nil
+	}
+	if err := f.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, errors.New("the shared session ended")
+}
+
+// Close disconnects from the session.
+func (f *Follower) Close() error {
+	return f.conn.Close()
+}
@@ -0,0 +1,73 @@
+package share
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFollowerReceivesLatestAndNewSnapshots(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s.sock")
+	server, err := Listen(path)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	defer func() { _ = server.Close() }()
+
+	server.Publish([]byte(`{"path":"/a"}`))
+	follower, err := Dial(path)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer func() { _ = follower.Close() }()
+
+	line, err := follower.Next()
+	if err != nil || string(line) != `{"path":"/a"}` {
+		t.Fatalf("first snapshot = %q, %v", line, err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for server.Followers() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	server.Publish([]byte(`{"path":"/a"}`)) // repeated, not sent
+	server.Publish([]byte(`{"path":"/b"}`))
+	line, err = follower.Next()
+	if err != nil || string(line) != `{"path":"/b"}` {
+		t.Fatalf("second snapshot = %q, %v", line, err)
+	}
+
+	_ = server.Close()
+	if _, err := follower.Next(); err == nil {
+		t.Fatalf("expected an error once the session stops sharing")
+	}
+}
+
+func TestPublishDoesNotWaitForStalledFollower(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s.sock")
+	server, err := Listen(path)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	defer func() { _ = server.Close() }()
+
+	stalled, err := Dial(path) // never reads
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer func() { _ = stalled.Close() }()
+	deadline := time.Now().Add(2 * time.Second)
+	for server.Followers() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	big := make([]byte, 64<<10)
+	start := time.Now()
+	for i := 0; i < 200; i++ {
+		big[0] = byte(i)
+		server.Publish(big)
+	}
+	if elapsed := time.Since(start); elapsed > writeTimeout/2 {
+		t.Fatalf("Publish waited %v for a follower that does not read", elapsed)
+	}
+}
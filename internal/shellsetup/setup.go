@@ -43,6 +43,7 @@ func PrintSetup(shellOverride string, cfg Config) {
 
     result_file="$TMPDIR/rdir_result_$$.txt"
     RDIR_RESULT_FILE="$result_file" command %s "$@"
+    rdir_status=$?
     if [ -f "$result_file" ] && [ ! -L "$result_file" ] && [ -O "$result_file" ]; then
         dest=$(cat "$result_file" 2>/dev/null)
         rm -f "$result_file"
@@ -52,6 +53,7 @@ func PrintSetup(shellOverride string, cfg Config) {
     else
         rm -f "$result_file" 2>/dev/null
     fi
+    return $rdir_status
 }
 `, quoted, quoted)
 	case "fish":
@@ -63,6 +65,7 @@ func PrintSetup(shellOverride string, cfg Config) {
 
     set result_file "$TMPDIR/rdir_result_$fish_pid.txt"
     env RDIR_RESULT_FILE="$result_file" command %s $argv
+    set rdir_status $status
     if test -f "$result_file" -a ! -L "$result_file" -a -O "$result_file"
         set dest (cat "$result_file" 2>/dev/null)
         if test -d "$dest" 2>/dev/null
@@ -70,6 +73,7 @@ func PrintSetup(shellOverride string, cfg Config) {
         end
     end
     rm -f "$result_file" 2>/dev/null
+    return $rdir_status
 end
 `, quoted, quoted)
 	case "pwsh", "powershell":
@@ -83,6 +87,7 @@ end
     $envVars = @{ RDIR_RESULT_FILE = (Join-Path $env:TEMP "rdir_result_$PID.txt") }
     $process = Start-Process -FilePath %s -NoNewWindow -PassThru -Environment $envVars
     $process.WaitForExit()
+    $rdirStatus = $process.ExitCode
 
     $resultFile = $envVars.RDIR_RESULT_FILE
     try {
@@ -95,6 +100,7 @@ end
     } finally {
         Remove-Item $resultFile -ErrorAction SilentlyContinue
     }
+    $global:LASTEXITCODE = $rdirStatus
 }
 `, quoted, quoted)
 	case "tcsh", "csh":
@@ -102,17 +108,18 @@ end
 	case "cmd":
 		fmt.Printf(`:: Save as rdir.cmd and run "call rdir.cmd" from cmd.exe sessions.
 @echo off
-if "%%~1"==""
-(
-    for /f "delims=" %%%%d in ('%s') do (
-        if not "%%%%d"=="" cd /d "%%%%d"
-    )
-    exit /b 0
-) else (
-    set "RDIR_RESULT_FILE=%%TEMP%%\rdir_result_%%RANDOM%%.txt"
-    %s %%*
-    exit /b %%errorlevel%%
+if "%%~1"=="" goto browse
+%s %%*
+exit /b %%errorlevel%%
+:browse
+set "RDIR_RESULT_FILE=%%TEMP%%\rdir_result_%%RANDOM%%.txt"
+%s
+set "RDIR_STATUS=%%errorlevel%%"
+if exist "%%RDIR_RESULT_FILE%%" (
+    for /f "usebackq delims=" %%%%d in ("%%RDIR_RESULT_FILE%%") do if exist "%%%%d\" cd /d "%%%%d"
+    del "%%RDIR_RESULT_FILE%%" >nul 2>&1
 )
+set "RDIR_RESULT_FILE=" & set "RDIR_STATUS=" & exit /b %%RDIR_STATUS%%
 `, quoted, quoted)
 	default:
 		fmt.Printf(`rdir() {
@@ -123,6 +130,7 @@ if "%%~1"==""
 
     result_file="$TMPDIR/rdir_result_$$.txt"
     RDIR_RESULT_FILE="$result_file" command %s "$@"
+    rdir_status=$?
     if [ -f "$result_file" ] && [ ! -L "$result_file" ] && [ -O "$result_file" ]; then
         dest=$(cat "$result_file" 2>/dev/null)
         rm -f "$result_file"
@@ -132,6 +140,7 @@ if "%%~1"==""
     else
         rm -f "$result_file" 2>/dev/null
     fi
+    return $rdir_status
 }
 `, quoted, quoted)
 	}
@@ -1,6 +1,12 @@
 package state
 
-import "os"
+import (
+	"os"
+	"time"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+	"github.com/kk-code-lab/rdir/internal/lineedit"
+)
 
 // Action is the base interface for all state mutations
 type Action interface{}
@@ -17,6 +23,22 @@ type GoToHistoryAction struct {
 	Direction string // "back" or "forward"
 }
 
+// GoToSiblingAction moves to the next/previous directory of the parent.
+type GoToSiblingAction struct {
+	Direction string // "next" or "prev"
+}
+
+// ParentPaneSelectAction picks entry Index of the parent pane
+// (AppState.ParentEntries): a sibling directory opens in place of the
+// current one, anything else is selected in the parent.
+type ParentPaneSelectAction struct {
+	Index int
+}
+
+// GoToTargetAction selects the target of the selected symlink, .desktop
+// entry or .lnk shortcut in its directory.
+type GoToTargetAction struct{}
+
 // ===== FILTER ACTIONS =====
 
 type FilterStartAction struct{}
@@ -24,9 +46,17 @@ type FilterCharAction struct {
 	Char rune
 }
 type FilterBackspaceAction struct{}
+
+// FilterEditAction moves the filter cursor or edits the query around it.
+type FilterEditAction struct {
+	Op lineedit.Op
+}
 type FilterResetQueryAction struct{}
 type FilterClearAction struct{}
 
+// FilterToggleScoresAction shows or hides each filter match's score.
+type FilterToggleScoresAction struct{}
+
 // ===== SCROLL ACTIONS =====
 
 type ScrollUpAction struct{}
@@ -49,7 +79,56 @@ type ResizeAction struct {
 }
 
 type YankPathAction struct{}
+
+// YankPathAsAction opens the menu of forms of the selected path (WSL,
+// Windows, container mappings) to copy one of them.
+type YankPathAsAction struct{}
+
+// PathChoiceSelectAction copies form Index of the open PathChoice.
+type PathChoiceSelectAction struct {
+	Index int
+}
+
+// PathChoiceCancelAction closes the PathChoice menu.
+type PathChoiceCancelAction struct{}
 type ToggleHiddenFilesAction struct{}
+
+// ToggleListGridAction switches the file list between a single column and
+// the column layout (G).
+type ToggleListGridAction struct{}
+
+// ListWidthAction reports the list panel width of the last frame, which
+// decides how many grid columns fit.
+type ListWidthAction struct {
+	Width int
+}
+
+// GridMoveAction moves the selection by Columns grid columns (←/→ while
+// the list is shown in columns).
+type GridMoveAction struct {
+	Columns int
+}
+
+// ToggleEcoModeAction turns eco mode on or off for the rest of the session.
+type ToggleEcoModeAction struct{}
+
+// PowerSourceAction reports the power source found by a periodic check.
+// Known is false when the platform cannot tell; the app then stops checking.
+type PowerSourceAction struct {
+	OnBattery bool
+	Known     bool
+}
+
+// FollowSnapshotAction mirrors a snapshot received from the session this
+// one follows (rdir --follow).
+type FollowSnapshotAction struct {
+	Snapshot FollowSnapshot
+}
+
+// FollowEndedAction reports that the followed session stopped sharing; the
+// follower becomes interactive again.
+type FollowEndedAction struct{}
+
 type OpenEditorAction struct{}
 type RefreshDirectoryAction struct{}
 type OpenPagerAction struct{}
@@ -60,6 +139,286 @@ type GoToPathAction struct {
 type HelpToggleAction struct{}
 type HelpHideAction struct{}
 
+// AuditViewOpenAction shows the recent file operations from the audit log.
+type AuditViewOpenAction struct{}
+
+// AuditViewScrollAction scrolls the audit view by Delta entries; Page scrolls
+// by Delta screens.
+type AuditViewScrollAction struct {
+	Delta int
+	Page  bool
+}
+
+// AuditViewCloseAction hides the audit view.
+type AuditViewCloseAction struct{}
+
+// ===== STAGING ACTIONS =====
+
+// StageCutAction toggles the selected entry in the staging area for a move.
+type StageCutAction struct{}
+
+// StageCopyAction toggles the selected entry in the staging area for a copy.
+type StageCopyAction struct{}
+type ClearStagingAction struct{}
+
+// PasteStagedAction moves/copies the staged paths into the current directory.
+// Confirmed skips the questions before the paste; ReferencesChecked only the
+// one about text files that mention the moved names; ScopeConfirmed the
+// typed-path check for protected directories; StreamsChecked the one the
+// paste job asks when alternate streams would be left behind. Resolutions
+// holds the decisions for names that are taken; nil asks about them first.
+type PasteStagedAction struct {
+	Confirmed         bool
+	ReferencesChecked bool
+	ScopeConfirmed    bool
+	StreamsChecked    bool
+	Resolutions       *ConflictResolutions
+}
+
+// ConflictChoiceAction decides the current item of the conflict dialog, or
+// it and all remaining ones when All is set.
+type ConflictChoiceAction struct {
+	Choice ConflictChoice
+	All    bool
+}
+
+// ConflictBackAction returns to the previous conflict to change its decision.
+type ConflictBackAction struct{}
+
+// ConflictPatternAction edits the pattern used for renamed entries.
+type ConflictPatternAction struct{}
+
+// ConflictConfirmAction carries out the decisions shown in the summary.
+type ConflictConfirmAction struct{}
+
+// ConflictCancelAction closes the conflict dialog without doing anything.
+type ConflictCancelAction struct{}
+
+// StagingSyncAction replaces the staging area with the shared copy on disk.
+type StagingSyncAction struct {
+	Area StagingArea
+}
+
+// StagingPasteResultAction installs the outcome of a paste and refreshes the
+// listing, selecting SelectName when it is non-empty.
+type StagingPasteResultAction struct {
+	Area       StagingArea
+	SelectName string
+	Err        error
+}
+
+// PasteJobAction shows a paste as the running job; the app runs it in the
+// background and ends it with PasteDoneAction. Unless StreamsChecked is set,
+// the job first looks for alternate streams the paste would leave behind.
+type PasteJobAction struct {
+	Area           StagingArea
+	DestDir        string
+	Resolutions    *ConflictResolutions
+	StreamsChecked bool
+}
+
+// PasteDoneAction ends a paste job with its outcome. StreamLoss is set when
+// the job stopped before pasting to ask about a stream it would leave behind;
+// Resolutions are the job's, for the paste the question replays.
+type PasteDoneAction struct {
+	Result      PasteResult
+	StreamLoss  *StreamLoss
+	Resolutions *ConflictResolutions
+}
+
+// ===== TYPE-AHEAD ACTIONS =====
+
+// TypeAheadStartAction enters type-ahead mode: typed letters select the next
+// entry whose name starts with them, without filtering the list.
+type TypeAheadStartAction struct{}
+
+// TypeAheadCharAction extends the type-ahead prefix; At is the key time used
+// to reset the prefix after a pause.
+type TypeAheadCharAction struct {
+	Char rune
+	At   time.Time
+}
+type TypeAheadExitAction struct{}
+
+// ===== MARK & TAB ACTIONS =====
+
+// ToggleMarkAction marks/unmarks the selected entry and moves the selection down.
+type ToggleMarkAction struct{}
+type ClearMarksAction struct{}
+
+// MarkSummaryAction delivers the marked-entries summary counted in the
+// background.
+type MarkSummaryAction struct {
+	Summary MarkSummary
+}
+
+// TabNewAction opens a new tab at the current location.
+type TabNewAction struct{}
+type TabCloseAction struct{}
+type TabNextAction struct{}
+type TabPrevAction struct{}
+
+// OpenMarkedInTabsAction opens every marked directory in its own tab.
+// Confirmed skips the prompt shown when many tabs would open at once.
+type OpenMarkedInTabsAction struct {
+	Confirmed bool
+}
+
+// SelectPatternStartAction opens the prompt that marks entries of the
+// current view matching a glob or /regex/.
+type SelectPatternStartAction struct{}
+
+// RevealStartAction prompts for the exact name of an entry to select,
+// showing it even when it is hidden.
+type RevealStartAction struct{}
+
+// PrivilegedReadAction (S) opens an unreadable file in the pager through
+// the privileged helper. Without a Path it checks the selection and asks for
+// confirmation; the confirmed action carries the file and the app runs the
+// helper.
+type PrivilegedReadAction struct {
+	Path string
+}
+
+// NormalizeLineEndingsAction converts the selected file to a single kind of
+// line ending. Without a Path it inspects the selection and asks for
+// confirmation; the confirmed action carries the file and target, and the
+// app performs the rewrite (keeping a .bak copy).
+type NormalizeLineEndingsAction struct {
+	Path   string
+	Target fsutil.LineEnding
+}
+
+// ScopeConfirmStartAction asks for Scope, a protected directory (the
+// filesystem root, the home directory or a mount root), to be typed out
+// before Then runs.
+type ScopeConfirmStartAction struct {
+	Scope string
+	Verb  string
+	Then  Action
+}
+
+// CommandErrorAction reports an external command that failed in the
+// background, e.g. an opener stopped for running too long.
+type CommandErrorAction struct {
+	Err error
+}
+
+// ErrorRetryAction repeats what failed in the error banner shown and
+// dismisses it.
+type ErrorRetryAction struct{}
+
+// ErrorDismissAction hides the error banner shown, revealing the next one.
+type ErrorDismissAction struct{}
+
+// ExtractStartAction prompts for where to extract the selected or marked
+// archives.
+type ExtractStartAction struct{}
+
+// ExtractArchivesAction extracts each archive into its own folder under
+// Dest. The app runs it in the background and reports through
+// ArchiveProgressAction and ArchiveDoneAction.
+type ExtractArchivesAction struct {
+	Targets []ExtractTarget
+	Dest    string
+}
+
+// CompressStartAction prompts for the name of an archive holding the marked
+// entries or the selection.
+type CompressStartAction struct{}
+
+// CompressAction packs Sources into the new archive Archive (.zip or
+// .tar.gz). Like ExtractArchivesAction it runs in the background.
+type CompressAction struct {
+	Sources []string
+	Archive string
+}
+
+// ArchiveProgressAction reports the entry being handled in step Index of
+// the running archive job.
+type ArchiveProgressAction struct {
+	Index    int
+	Progress fsutil.ArchiveProgress
+}
+
+// ArchiveCancelAction stops the running archive job.
+type ArchiveCancelAction struct{}
+
+// ArchiveDoneAction ends an archive job, selecting SelectName in Dest.
+type ArchiveDoneAction struct {
+	Dest       string
+	SelectName string
+	Files      int
+	Audit      []AuditEntry
+	Err        error
+}
+
+// ===== PROMPT ACTIONS =====
+
+type PromptCharAction struct {
+	Char rune
+}
+type PromptBackspaceAction struct{}
+
+// PromptEditAction moves the prompt cursor or edits the input around it.
+type PromptEditAction struct {
+	Op lineedit.Op
+}
+
+// PromptCycleModeAction switches the select-pattern prompt between
+// mark, unmark and keep-only, the extract prompt between collision
+// policies and the compress prompt between archive formats.
+type PromptCycleModeAction struct{}
+type PromptSubmitAction struct{}
+type PromptCancelAction struct{}
+
+// IndexWarmStartAction warms the global search index for the current
+// directory after the app has been idle for IdleIndex.After.
+type IndexWarmStartAction struct {
+	At time.Time
+}
+
+// IndexWarmCancelAction stops an unfinished warm-up; the app sends it on
+// every key or mouse event.
+type IndexWarmCancelAction struct{}
+
+// RenameStartAction edits the selected entry's name in place (F2).
+type RenameStartAction struct{}
+
+// RenameCharAction types into the inline rename, replacing the selection.
+type RenameCharAction struct {
+	Char rune
+}
+
+// RenameEditAction moves the inline rename cursor or edits around it.
+type RenameEditAction struct {
+	Op lineedit.Op
+}
+
+type RenameSubmitAction struct{}
+type RenameCancelAction struct{}
+
+// RenameEntryAction renames From to To; the app runs it and reports back
+// with RenameResultAction.
+type RenameEntryAction struct {
+	From string
+	To   string
+
+	ReferencesChecked bool // the RDIR_MOVE_REFS scan already ran
+}
+
+// RenameResultAction reloads the directory after a rename and selects the
+// entry under its new name.
+type RenameResultAction struct {
+	From string
+	To   string
+	Err  error
+}
+
+// ConfirmAcceptAction answers yes to the pending confirmation.
+type ConfirmAcceptAction struct{}
+type ConfirmCancelAction struct{}
+
 // DirectoryLoadResultAction installs results from the async directory loader.
 type DirectoryLoadResultAction struct {
 	Token   int
@@ -68,6 +427,15 @@ type DirectoryLoadResultAction struct {
 	Err     error
 }
 
+// ParentEntriesLoadedAction installs the parent pane listing read by the
+// async directory loader.
+type ParentEntriesLoadedAction struct {
+	Token   int
+	Path    string
+	Entries []FileEntry
+	Err     error
+}
+
 // ===== PREVIEW ACTIONS =====
 
 type PreviewEnterFullScreenAction struct{}
@@ -79,6 +447,10 @@ type PreviewScrollPageDownAction struct{}
 type PreviewScrollToStartAction struct{}
 type PreviewScrollToEndAction struct{}
 type TogglePreviewWrapAction struct{}
+
+// PreviewReloadAction builds the preview of the selection again after it
+// failed.
+type PreviewReloadAction struct{}
 type PreviewLoadStartAction struct {
 	Token int
 }
@@ -101,7 +473,13 @@ type GlobalSearchDeleteAction struct{}
 type GlobalSearchDeleteWordAction struct{}
 type GlobalSearchResetQueryAction struct{}
 type GlobalSearchMoveCursorAction struct {
-	Direction string // "left", "right", "home", "end"
+	Direction string // a cursor movement lineedit.Op: "left", "word-right", "home"...
+}
+
+// GlobalSearchEditAction applies the editing keys that have no action of
+// their own, such as Ctrl+U and Ctrl+K.
+type GlobalSearchEditAction struct {
+	Op lineedit.Op
 }
 type GlobalSearchClearAction struct{}
 type GlobalSearchNavigateAction struct {
@@ -126,7 +504,17 @@ type GlobalSearchResultsAction struct {
 	Phase      SearchStatus
 }
 
+// GlobalSearchCycleSortAction switches the result order between score,
+// path, newest and largest.
+type GlobalSearchCycleSortAction struct{}
+
+// GlobalSearchStatsAction delivers lazily fetched size/mtime for results
+// that were found without stat data.
+type GlobalSearchStatsAction struct {
+	Stats map[string]searchStat
+}
+
 // ===== APPLICATION ACTIONS =====
 
 type QuitAction struct{}          // q - return to original directory
-type QuitAndChangeAction struct{} // x - change to current directory
+type QuitAndChangeAction struct{} // Q - change to current directory
@@ -0,0 +1,89 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EnvAmbiguousWidth says how many cells the terminal gives East Asian
+// ambiguous-width characters: narrow (1), wide (2) or auto.
+const EnvAmbiguousWidth = "RDIR_AMBIGUOUS_WIDTH"
+
+// AmbiguousWidth is the RDIR_AMBIGUOUS_WIDTH setting.
+type AmbiguousWidth int
+
+const (
+	// AmbiguousAuto follows RUNEWIDTH_EASTASIAN when it is set; otherwise a
+	// CJK locale asks the terminal (see ResolveAmbiguousWidth) and any
+	// other locale is narrow.
+	AmbiguousAuto AmbiguousWidth = iota
+	AmbiguousNarrow
+	AmbiguousWide
+)
+
+func (w AmbiguousWidth) String() string {
+	switch w {
+	case AmbiguousNarrow:
+		return "narrow"
+	case AmbiguousWide:
+		return "wide"
+	default:
+		return "auto"
+	}
+}
+
+// LoadAmbiguousWidth reads RDIR_AMBIGUOUS_WIDTH. Invalid values fall back to
+// auto and are reported.
+func LoadAmbiguousWidth(getenv func(string) string) (AmbiguousWidth, error) {
+	switch raw := strings.ToLower(strings.TrimSpace(getenv(EnvAmbiguousWidth))); raw {
+	case "", "auto":
+		return AmbiguousAuto, nil
+	case "narrow", "1":
+		return AmbiguousNarrow, nil
+	case "wide", "2":
+		return AmbiguousWide, nil
+	default:
+		return AmbiguousAuto, fmt.Errorf("ignoring %s=%q (use narrow, wide or auto)", EnvAmbiguousWidth, raw)
+	}
+}
+
+// ResolveAmbiguousWidth settles auto from the environment alone. needsProbe
+// is true when only the terminal can tell (a CJK locale without
+// RUNEWIDTH_EASTASIAN); wide is then the guess to use if it does not answer.
+func ResolveAmbiguousWidth(setting AmbiguousWidth, getenv func(string) string) (wide, needsProbe bool) {
+	switch setting {
+	case AmbiguousNarrow:
+		return false, false
+	case AmbiguousWide:
+		return true, false
+	}
+	if env := strings.TrimSpace(getenv("RUNEWIDTH_EASTASIAN")); env != "" {
+		return env == "1", false
+	}
+	if cjkLocale(getenv) {
+		return true, true
+	}
+	return false, false
+}
+
+// cjkLocale reports whether the locale in effect (LC_ALL, LC_CTYPE, LANG) is
+// Chinese, Japanese or Korean, where terminals often draw ambiguous-width
+// characters wide.
+func cjkLocale(getenv func(string) string) bool {
+	locale := ""
+	for _, key := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale = strings.TrimSpace(getenv(key)); locale != "" {
+			break
+		}
+	}
+	locale = strings.ToLower(locale)
+	if strings.Contains(locale, "@cjk_narrow") {
+		return false
+	}
+	for _, prefix := range []string{"ja", "ko", "zh"} {
+		if locale == prefix || strings.HasPrefix(locale, prefix+"_") || strings.HasPrefix(locale, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package state
+
+import "testing"
+
+func TestLoadAmbiguousWidth(t *testing.T) {
+	cases := []struct {
+		value string
+		want  AmbiguousWidth
+		err   bool
+	}{
+		{"", AmbiguousAuto, false},
+		{"Wide", AmbiguousWide, false},
+		{"narrow", AmbiguousNarrow, false},
+		{"2", AmbiguousWide, false},
+		{"double", AmbiguousAuto, true},
+	}
+	for _, tc := range cases {
+		got, err := LoadAmbiguousWidth(func(string) string { return tc.value })
+		if got != tc.want || (err != nil) != tc.err {
+			t.Fatalf("LoadAmbiguousWidth(%q) = %v, %v", tc.value, got, err)
+		}
+	}
+}
+
+func TestResolveAmbiguousWidth(t *testing.T) {
+	cases := []struct {
+		setting          AmbiguousWidth
+		env              map[string]string
+		wide, needsProbe bool
+	}{
+		{AmbiguousAuto, map[string]string{"LANG": "en_US.UTF-8"}, false, false},
+		{AmbiguousAuto, map[string]string{"LANG": "ja_JP.UTF-8"}, true, true},
+		{AmbiguousAuto, map[string]string{"LANG": "en_US.UTF-8", "LC_ALL": "zh_CN.GBK"}, true, true},
+		{AmbiguousAuto, map[string]string{"LANG": "ko_KR.UTF-8@cjk_narrow"}, false, false},
+		{AmbiguousAuto, map[string]string{"LANG": "ja_JP.UTF-8", "RUNEWIDTH_EASTASIAN": "0"}, false, false},
+		{AmbiguousAuto, map[string]string{"RUNEWIDTH_EASTASIAN": "1"}, true, false},
+		{AmbiguousNarrow, map[string]string{"LANG": "ja_JP.UTF-8"}, false, false},
+		{AmbiguousWide, map[string]string{}, true, false},
+	}
+	for _, tc := range cases {
+		wide, needsProbe := ResolveAmbiguousWidth(tc.setting, func(key string) string { return tc.env[key] })
+		if wide != tc.wide || needsProbe != tc.needsProbe {
+			t.Fatalf("ResolveAmbiguousWidth(%v, %v) = %v, %v", tc.setting, tc.env, wide, needsProbe)
+		}
+	}
+}
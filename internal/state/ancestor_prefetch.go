@@ -0,0 +1,181 @@
+package state
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+)
+
+const (
+	// ancestorPrefetchDepth is how many levels above the current directory
+	// are read ahead of time (parent and grandparent).
+	ancestorPrefetchDepth = 2
+	// ancestorListingLimit bounds the number of cached ancestor listings.
+	ancestorListingLimit = 8
+)
+
+// ancestorListing is a directory listing read in the background together
+// with the directory mtime observed before the read.
+type ancestorListing struct {
+	modTime time.Time
+	entries []FileEntry
+	used    time.Time
+}
+
+// ancestorListingCache holds prefetched parent/grandparent listings so going
+// up does not wait for a (possibly slow) directory read. Entries are only
+// used while the directory mtime is unchanged.
+type ancestorListingCache struct {
+	mu      sync.Mutex
+	entries map[string]ancestorListing
+	cancel  context.CancelFunc
+}
+
+var ancestorListings = &ancestorListingCache{}
+
+func (c *ancestorListingCache) store(path string, modTime time.Time, entries []FileEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]ancestorListing)
+	}
+	if _, ok := c.entries[path]; !ok && len(c.entries) >= ancestorListingLimit {
+		oldest := ""
+		for p, e := range c.entries {
+			if oldest == "" || e.used.Before(c.entries[oldest].used) {
+				oldest = p
+			}
+		}
+		delete(c.entries, oldest)
+	}
+	c.entries[path] = ancestorListing{modTime: modTime, entries: entries, used: time.Now()}
+}
+
+// lookup returns a copy of the cached listing for path if the directory has
+// not been modified since it was read. Stale entries are dropped.
+func (c *ancestorListingCache) lookup(path string) ([]FileEntry, bool) {
+	c.mu.Lock()
+	listing, ok := c.entries[path]
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	info, err := os.Stat(path)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil || !info.ModTime().Equal(listing.modTime) {
+		delete(c.entries, path)
+		return nil, false
+	}
+	listing.used = time.Now()
+	c.entries[path] = listing
+
+	entries := make([]FileEntry, len(listing.entries))
+	copy(entries, listing.entries)
+	return entries, true
+}
+
+// peek returns a copy of the cached listing for path without checking it
+// against the directory, for showing while it is read again.
+func (c *ancestorListingCache) peek(path string) ([]FileEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	listing, ok := c.entries[path]
+	if !ok {
+		return nil, false
+	}
+	entries := make([]FileEntry, len(listing.entries))
+	copy(entries, listing.entries)
+	return entries, true
+}
+
+// prefetch reads the ancestors of dirPath in the background, cancelling any
+// prefetch still running for a previous directory.
+func (c *ancestorListingCache) prefetch(dirPath string) {
+	var targets []string
+	current := filepath.Clean(dirPath)
+	for i := 0; i < ancestorPrefetchDepth; i++ {
+		parent := filepath.Dir(current)
+		if parent == current {
+			break
+		}
+		targets = append(targets, parent)
+		current = parent
+	}
+	if len(targets) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), directoryLoadTimeout)
+	c.mu.Lock()
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.cancel = cancel
+	c.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		for _, target := range targets {
+			if ctx.Err() != nil {
+				return
+			}
+			// Stat before reading: a change during the read leaves an older
+			// mtime behind, so the listing is treated as stale on use.
+			info, err := os.Stat(target)
+			if err != nil {
+				return
+			}
+			if cached, ok := c.peekModTime(target); ok && cached.Equal(info.ModTime()) {
+				continue
+			}
+			entries, err := readDirectoryEntries(ctx, fsutil.Local, target)
+			if err != nil {
+				return
+			}
+			c.store(target, info.ModTime(), entries)
+		}
+	}()
+}
+
+func (c *ancestorListingCache) peekModTime(path string) (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	listing, ok := c.entries[path]
+	return listing.modTime, ok
+}
+
+// prefetchAncestors starts reading the parent and grandparent of the current
+// directory. It only runs when directories load asynchronously from the local
+// filesystem; synchronous setups (tests, initial load) read on demand.
+func (s *AppState) prefetchAncestors() {
+	if s.DirectoryLoader == nil || !s.listsLocalDisk() || s.CurrentPath == "" || !s.pathProfile().PrefetchAncestors {
+		return
+	}
+	ancestorListings.prefetch(s.CurrentPath)
+}
+
+// changeToAncestor switches to dirPath using a prefetched listing when one is
+// still fresh, falling back to a regular (possibly async) directory load.
+func (r *StateReducer) changeToAncestor(state *AppState, dirPath string) (bool, error) {
+	dirPath = filepath.Clean(dirPath)
+	if state.DirectoryLoader == nil || !state.listsLocalDisk() {
+		return r.changeDirectoryWithStatus(state, dirPath)
+	}
+	entries, ok := ancestorListings.lookup(dirPath)
+	if !ok {
+		return r.changeDirectoryWithStatus(state, dirPath)
+	}
+
+	if prevToken := state.ActiveDirectoryLoadToken(); prevToken != 0 {
+		state.DirectoryLoader.Cancel(prevToken)
+		r.dropDirectoryCallbacks(prevToken)
+	}
+	applyDirectoryEntries(state, dirPath, entries)
+	return false, nil
+}
@@ -0,0 +1,166 @@
+package state
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+type recordingDirectoryLoader struct {
+	started []string
+}
+
+func (l *recordingDirectoryLoader) Start(req DirectoryLoadRequest) {
+	l.started = append(l.started, req.Path)
+}
+
+func (l *recordingDirectoryLoader) Cancel(int) {}
+
+func primeAncestorListing(t *testing.T, dir string) {
+	t.Helper()
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := readDirectoryEntries(context.Background(), nil, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ancestorListings.store(dir, info.ModTime(), entries)
+}
+
+func TestGoUpUsesFreshPrefetchedListing(t *testing.T) {
+	root := t.TempDir()
+	child := filepath.Join(root, "child")
+	if err := os.Mkdir(child, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "file.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	primeAncestorListing(t, root)
+
+	loader := &recordingDirectoryLoader{}
+	state := &AppState{
+		CurrentPath:     child,
+		DirectoryLoader: loader,
+		ScreenHeight:    24,
+		ScreenWidth:     80,
+	}
+	state.SetDispatch(func(Action) {})
+
+	reducer := NewStateReducer()
+	if _, err := reducer.Reduce(state, GoUpAction{}); err != nil {
+		t.Fatalf("GoUpAction: %v", err)
+	}
+	if slices.Contains(loader.started, root) {
+		t.Fatalf("expected no load of %s, got %v", root, loader.started)
+	}
+	if state.CurrentPath != root || len(state.Files) != 2 {
+		t.Fatalf("expected %s with 2 entries, got %s with %d", root, state.CurrentPath, len(state.Files))
+	}
+	if file := state.getCurrentFile(); file == nil || file.Name != "child" {
+		t.Fatalf("expected child to be selected, got %+v", file)
+	}
+}
+
+func TestGoUpReloadsWhenPrefetchedListingIsStale(t *testing.T) {
+	root := t.TempDir()
+	child := filepath.Join(root, "child")
+	if err := os.Mkdir(child, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	primeAncestorListing(t, root)
+
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(root, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := &recordingDirectoryLoader{}
+	state := &AppState{
+		CurrentPath:     child,
+		DirectoryLoader: loader,
+		ScreenHeight:    24,
+		ScreenWidth:     80,
+	}
+	state.SetDispatch(func(Action) {})
+
+	reducer := NewStateReducer()
+	if _, err := reducer.Reduce(state, GoUpAction{}); err != nil {
+		t.Fatalf("GoUpAction: %v", err)
+	}
+	if len(loader.started) == 0 || loader.started[0] != root {
+		t.Fatalf("expected a directory load of %s, got %v", root, loader.started)
+	}
+}
+
+type queuedDirectoryLoader struct {
+	requests []DirectoryLoadRequest
+}
+
+func (l *queuedDirectoryLoader) Start(req DirectoryLoadRequest) {
+	l.requests = append(l.requests, req)
+}
+
+func (l *queuedDirectoryLoader) Cancel(int) {}
+
+func TestParentEntriesLoadThroughAsyncLoader(t *testing.T) {
+	root := t.TempDir()
+	child := filepath.Join(root, "child")
+	if err := os.Mkdir(child, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "file.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := &queuedDirectoryLoader{}
+	state := &AppState{CurrentPath: child, DirectoryLoader: loader}
+	var dispatched []Action
+	state.SetDispatch(func(a Action) { dispatched = append(dispatched, a) })
+
+	state.updateParentEntries()
+	if len(state.ParentEntries) != 0 {
+		t.Fatalf("expected no synchronous parent listing, got %d entries", len(state.ParentEntries))
+	}
+	if len(loader.requests) != 1 || loader.requests[0].Path != root {
+		t.Fatalf("expected one load of %s, got %+v", root, loader.requests)
+	}
+
+	req := loader.requests[0]
+	entries, err := req.Read(context.Background(), req.Path)
+	req.Callback(DirectoryLoadResult{Token: req.Token, Path: req.Path, Entries: entries, Err: err})
+	if len(dispatched) != 1 {
+		t.Fatalf("expected the listing to arrive as an action, got %v", dispatched)
+	}
+	if _, err := NewStateReducer().Reduce(state, dispatched[0]); err != nil {
+		t.Fatal(err)
+	}
+	if len(state.ParentEntries) != 2 || state.ParentEntries[0].Name != "child" {
+		t.Fatalf("expected child and file.txt, got %+v", state.ParentEntries)
+	}
+
+	// The listing read above is cached: the next update shows it at once and
+	// only revalidates it in the background.
+	state.updateParentEntries()
+	if len(state.ParentEntries) != 2 {
+		t.Fatalf("expected the cached listing right away, got %+v", state.ParentEntries)
+	}
+
+	later := time.Now().Add(time.Hour)
+	if err := os.WriteFile(filepath.Join(root, "new.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(root, later, later); err != nil {
+		t.Fatal(err)
+	}
+	req = loader.requests[len(loader.requests)-1]
+	entries, err = req.Read(context.Background(), req.Path)
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("expected a changed mtime to read the directory again, got %d entries (%v)", len(entries), err)
+	}
+}
@@ -0,0 +1,93 @@
+package state
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+)
+
+// archiveProgressInterval throttles progress updates sent to the UI.
+const archiveProgressInterval = 100 * time.Millisecond
+
+// ArchiveJob is an extraction, compression, paste or reference scan running
+// in the background.
+type ArchiveJob struct {
+	Verb      string   // "extracting", "compressing", "moving", "copying" or "checking references to"
+	Names     []string // archive (or entry) handled at each step
+	Index     int      // step in progress
+	Progress  fsutil.ArchiveProgress
+	Canceling bool
+}
+
+// Status returns the footer line describing the job.
+func (j *ArchiveJob) Status() string {
+	if j.Canceling {
+		return "canceling…"
+	}
+	var b strings.Builder
+	b.WriteString(j.Verb)
+	if j.Index < len(j.Names) {
+		b.WriteString(" ")
+		b.WriteString(textutil.SanitizeTerminalText(j.Names[j.Index]))
+	}
+	if len(j.Names) > 1 {
+		fmt.Fprintf(&b, " (%d/%d)", j.Index+1, len(j.Names))
+	}
+	if j.Progress.Files > 0 {
+		if j.Progress.Total > 0 {
+			fmt.Fprintf(&b, " %d/%d", j.Progress.Files, j.Progress.Total)
+		} else {
+			fmt.Fprintf(&b, " %d", j.Progress.Files)
+		}
+		b.WriteString(": ")
+		// Entry names inside an archive are whatever its author wrote.
+		b.WriteString(textutil.SanitizeTerminalText(j.Progress.Current))
+	}
+	return b.String()
+}
+
+// throttledProgress returns a progress callback for step index that sends
+// at most one ArchiveProgressAction per archiveProgressInterval.
+func throttledProgress(index int, send func(Action)) func(fsutil.ArchiveProgress) {
+	var last time.Time
+	return func(p fsutil.ArchiveProgress) {
+		if now := time.Now(); now.Sub(last) >= archiveProgressInterval {
+			last = now
+			send(ArchiveProgressAction{Index: index, Progress: p})
+		}
+	}
+}
+
+// finishArchiveJob ends the job and shows Dest with SelectName selected.
+func (r *StateReducer) finishArchiveJob(state *AppState, a ArchiveDoneAction) (*AppState, error) {
+	state.ArchiveJob = nil
+	if a.Err != nil {
+		state.ReportError(ErrorSourceFiles, a.Err)
+	}
+	if a.SelectName == "" {
+		return state, nil
+	}
+	if filepath.Clean(a.Dest) != filepath.Clean(state.CurrentPath) {
+		return r.openAndSelect(state, a.Dest, a.SelectName)
+	}
+
+	snapshot := captureRefreshSnapshot(state)
+	loading, err := r.changeDirectoryWithStatus(state, state.CurrentPath)
+	if err != nil {
+		return state, err
+	}
+	selectName := a.SelectName
+	post := func(r *StateReducer, state *AppState) error {
+		applyRefreshSnapshot(state, snapshot)
+		if idx := findFileIndexByName(state.Files, selectName); idx >= 0 {
+			state.SelectedIndex = idx
+			state.updateScrollVisibility()
+		}
+		return r.generatePreview(state)
+	}
+	return r.completeDirectoryChange(state, loading, post)
+}
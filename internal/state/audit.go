@@ -0,0 +1,221 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EnvAuditLog overrides where file operations are logged. Set it to "off" to
+// disable the log.
+const EnvAuditLog = "RDIR_AUDIT_LOG"
+
+const (
+	// auditMaxBytes is the size past which the log is rotated to <name>.1.
+	auditMaxBytes = 1 << 20
+	// auditKeepFiles is how many rotated files are kept (<name>.1 to .N).
+	auditKeepFiles = 3
+	// auditViewMaxEntries caps how many operations the audit view loads.
+	auditViewMaxEntries = 500
+)
+
+// AuditEntry is one file operation in the audit log, stored as a JSON line.
+type AuditEntry struct {
+	Time   time.Time `json:"time"`
+	User   string    `json:"user"`
+	Host   string    `json:"host,omitempty"`
+	Op     string    `json:"op"` // move, copy, line-endings
+	Src    string    `json:"src"`
+	Dst    string    `json:"dst,omitempty"`
+	Result string    `json:"result"` // "ok" or the error
+}
+
+// NewAuditEntry records op on src (and dst) with the outcome err; the user
+// and host are filled in when it is written.
+func NewAuditEntry(op, src, dst string, err error) AuditEntry {
+	result := "ok"
+	if err != nil {
+		result = err.Error()
+	}
+	return AuditEntry{Time: time.Now(), Op: op, Src: src, Dst: dst, Result: result}
+}
+
+// DefaultAuditFile returns the audit log location, or "" when logging is
+// disabled.
+func DefaultAuditFile(getenv func(string) string) string {
+	if path := getenv(EnvAuditLog); path != "" {
+		if path == "off" {
+			return ""
+		}
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil || dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "rdir", "audit.log")
+}
+
+// AppendAuditLog appends entries to the log at path, filling in the user and
+// host, after rotating the log when it has grown past auditMaxBytes. Each
+// entry is a single write to a file opened for appending, so instances
+// sharing the log do not interleave lines.
+func AppendAuditLog(path string, entries ...AuditEntry) error {
+	if path == "" || len(entries) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() >= auditMaxBytes {
+		rotateAuditLog(path)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	name, host := auditIdentity()
+	for _, entry := range entries {
+		if entry.User == "" {
+			entry.User = name
+		}
+		if entry.Host == "" {
+			entry.Host = host
+		}
+		line, err := json.Marshal(entry)
+		if err != nil {
+			_ = file.Close()
+			return err
+		}
+		if _, err := file.Write(append(line, '\n')); err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
+
+// rotateAuditLog shifts path.N-1 to path.N down to path itself, dropping the
+// oldest file.
+func rotateAuditLog(path string) {
+	_ = os.Remove(rotatedAuditFile(path, auditKeepFiles))
+	for i := auditKeepFiles - 1; i >= 1; i-- {
+		_ = os.Rename(rotatedAuditFile(path, i), rotatedAuditFile(path, i+1))
+	}
+	_ = os.Rename(path, rotatedAuditFile(path, 1))
+}
+
+func rotatedAuditFile(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
+
+func auditIdentity() (name, host string) {
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if name == "" {
+		name = os.Getenv("USER")
+	}
+	if name == "" {
+		name = os.Getenv("USERNAME")
+	}
+	host, _ = os.Hostname()
+	return name, host
+}
+
+// ReadAuditLog returns up to limit entries, newest first, from the log at
+// path and its rotated files. Lines that do not parse are skipped.
+func ReadAuditLog(path string, limit int) ([]AuditEntry, error) {
+	var entries []AuditEntry
+	for n := 0; n <= auditKeepFiles && len(entries) < limit; n++ {
+		name := path
+		if n > 0 {
+			name = rotatedAuditFile(path, n)
+		}
+		// Rotation keeps each file near auditMaxBytes.
+		data, err := os.ReadFile(name)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return entries, err
+		}
+		var chunk []AuditEntry
+		for _, line := range bytes.Split(data, []byte{'\n'}) {
+			var entry AuditEntry
+			if json.Unmarshal(line, &entry) == nil && entry.Op != "" {
+				chunk = append(chunk, entry)
+			}
+		}
+		for i := len(chunk) - 1; i >= 0 && len(entries) < limit; i-- {
+			entries = append(entries, chunk[i])
+		}
+	}
+	return entries, nil
+}
+
+// AuditView is the list of recent file operations shown over the UI.
+type AuditView struct {
+	Entries []AuditEntry
+	Scroll  int
+	Err     string
+}
+
+// openAuditView loads the recent operations for the audit view.
+func (s *AppState) openAuditView() {
+	view := &AuditView{}
+	if s.AuditFile == "" {
+		view.Err = "the audit log is off (" + EnvAuditLog + ")"
+	} else if entries, err := ReadAuditLog(s.AuditFile, auditViewMaxEntries); err != nil {
+		view.Entries = entries
+		view.Err = err.Error()
+	} else {
+		view.Entries = entries
+	}
+	s.AuditView = view
+}
+
+// scrollAuditView moves the audit view by delta rows, keeping a screen's
+// worth of entries in view.
+func (s *AppState) scrollAuditView(delta int) {
+	view := s.AuditView
+	if view == nil {
+		return
+	}
+	maxScroll := max(len(view.Entries)-s.auditViewRows(), 0)
+	view.Scroll = max(min(view.Scroll+delta, maxScroll), 0)
+}
+
+// auditViewRows is how many entries fit in the audit view: the screen minus
+// its title, blank line and footer.
+func (s *AppState) auditViewRows() int {
+	return max(s.ScreenHeight-3, 1)
+}
+
+// FormatAuditEntry renders an entry as one line of the audit view.
+func FormatAuditEntry(entry AuditEntry) string {
+	var b strings.Builder
+	b.WriteString(entry.Time.Local().Format("2006-01-02 15:04:05"))
+	b.WriteString("  ")
+	b.WriteString(entry.User)
+	b.WriteString("  ")
+	b.WriteString(entry.Op)
+	b.WriteString("  ")
+	b.WriteString(entry.Src)
+	if entry.Dst != "" {
+		b.WriteString(" → ")
+		b.WriteString(entry.Dst)
+	}
+	if entry.Result != "ok" {
+		b.WriteString("  failed: ")
+		b.WriteString(entry.Result)
+	}
+	return b.String()
+}
@@ -0,0 +1,123 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditLogAppendsRotatesAndReadsNewestFirst(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rdir", "audit.log")
+	first := NewAuditEntry("move", "/srv/a.txt", "/srv/done/a.txt", nil)
+	second := NewAuditEntry("copy", "/srv/b.txt", "/srv/done/b.txt", errors.New("permission denied"))
+	if err := AppendAuditLog(path, first, second); err != nil {
+		t.Fatalf("AppendAuditLog: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.Mode().Perm()&0o077 != 0 && os.PathSeparator == '/' {
+		t.Fatalf("audit log should be private, mode %v", info.Mode())
+	}
+
+	entries, err := ReadAuditLog(path, 10)
+	if err != nil {
+		t.Fatalf("ReadAuditLog: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Op != "copy" || entries[1].Op != "move" {
+		t.Fatalf("entries = %+v, want copy then move", entries)
+	}
+	if entries[0].Result != "permission denied" || entries[1].Result != "ok" || entries[1].User == "" {
+		t.Fatalf("unexpected results or user: %+v", entries)
+	}
+
+	// A log past the size limit moves to .1 before the next append.
+	padding := strings.Repeat("x", auditMaxBytes)
+	if err := os.WriteFile(path, []byte(padding+"\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := AppendAuditLog(path, first); err != nil {
+		t.Fatalf("AppendAuditLog after limit: %v", err)
+	}
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Fatalf("expected rotated log: %v", err)
+	}
+	entries, err = ReadAuditLog(path, 10)
+	if err != nil {
+		t.Fatalf("ReadAuditLog after rotation: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Src != "/srv/a.txt" {
+		t.Fatalf("entries after rotation = %+v", entries)
+	}
+}
+
+func TestPasteStagingRecordsAuditEntries(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "a.txt")
+	dest := t.TempDir()
+	if err := os.WriteFile(src, []byte("a"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	missing := filepath.Join(filepath.Dir(src), "gone.txt")
+
+	result := PasteStaging(StagingArea{Mode: StagingCut, Paths: []string{src, missing}}, dest, nil)
+	if len(result.Audit) != 2 {
+		t.Fatalf("audit = %+v, want two entries", result.Audit)
+	}
+	if got := result.Audit[0]; got.Op != "move" || got.Src != src || got.Dst != filepath.Join(dest, "a.txt") || got.Result != "ok" {
+		t.Fatalf("first entry = %+v", got)
+	}
+	if got := result.Audit[1]; got.Src != missing || got.Result == "ok" {
+		t.Fatalf("failed move should be recorded with its error, got %+v", got)
+	}
+}
+
+func TestAuditViewScrollsWithinEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	var entries []AuditEntry
+	for i := 0; i < 20; i++ {
+		entry := NewAuditEntry("copy", "/src", "/dst", nil)
+		entry.Time = time.Date(2026, 1, 1, 0, i, 0, 0, time.UTC)
+		entries = append(entries, entry)
+	}
+	if err := AppendAuditLog(path, entries...); err != nil {
+		t.Fatalf("AppendAuditLog: %v", err)
+	}
+
+	reducer := NewStateReducer()
+	state := &AppState{AuditFile: path, ScreenHeight: 13}
+	_, _ = reducer.Reduce(state, AuditViewOpenAction{})
+	if state.AuditView == nil || len(state.AuditView.Entries) != 20 || state.AuditView.Err != "" {
+		t.Fatalf("audit view = %+v", state.AuditView)
+	}
+	if !state.AuditView.Entries[0].Time.Equal(entries[19].Time) {
+		t.Fatalf("newest entry should come first, got %v", state.AuditView.Entries[0].Time)
+	}
+
+	_, _ = reducer.Reduce(state, AuditViewScrollAction{Delta: 1, Page: true})
+	if state.AuditView.Scroll != 10 {
+		t.Fatalf("page down scroll = %d, want 10", state.AuditView.Scroll)
+	}
+	_, _ = reducer.Reduce(state, AuditViewScrollAction{Delta: 1, Page: true})
+	if state.AuditView.Scroll != 10 {
+		t.Fatalf("scroll should stop with the last page in view, got %d", state.AuditView.Scroll)
+	}
+	_, _ = reducer.Reduce(state, AuditViewScrollAction{Delta: -3})
+	if state.AuditView.Scroll != 7 {
+		t.Fatalf("scroll up = %d, want 7", state.AuditView.Scroll)
+	}
+
+	_, _ = reducer.Reduce(state, AuditViewCloseAction{})
+	if state.AuditView != nil {
+		t.Fatalf("expected the view to close")
+	}
+
+	state.AuditFile = ""
+	_, _ = reducer.Reduce(state, AuditViewOpenAction{})
+	if state.AuditView == nil || !strings.Contains(state.AuditView.Err, EnvAuditLog) {
+		t.Fatalf("expected a note that the log is off, got %+v", state.AuditView)
+	}
+}
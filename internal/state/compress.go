@@ -0,0 +1,138 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+	search "github.com/kk-code-lab/rdir/internal/search"
+)
+
+// startCompress (C) asks for the name of an archive holding the marked
+// entries, or the selected one.
+func (s *AppState) startCompress() error {
+	if s.ArchiveJob != nil {
+		return errors.New(s.ArchiveJob.Verb + " is still running")
+	}
+	if err := s.RequireLocalDisk("compress entries"); err != nil {
+		return err
+	}
+	sources := s.MarkedPaths()
+	if len(sources) == 0 {
+		file := s.getCurrentFile()
+		if file == nil || file.Name == ".." {
+			return errors.New("select or mark entries to compress")
+		}
+		sources = []string{s.getCurrentFilePath()}
+	}
+	s.openPrompt(PromptCompress)
+	s.Prompt.Paths = sources
+	s.Prompt.Input = defaultArchiveName(sources, s.CurrentPath) + fsutil.ArchiveFormatSuffixes[0]
+	return nil
+}
+
+// defaultArchiveName names the archive after a single entry (without its
+// extension) or after the directory holding several.
+func defaultArchiveName(sources []string, dir string) string {
+	if len(sources) != 1 {
+		return filepath.Base(dir)
+	}
+	name := filepath.Base(sources[0])
+	if info, err := os.Stat(sources[0]); err == nil && !info.IsDir() {
+		if stem := strings.TrimSuffix(name, filepath.Ext(name)); stem != "" {
+			return stem
+		}
+	}
+	return name
+}
+
+// cycleArchiveFormat swaps the format extension typed into the compress
+// prompt for the next one.
+func cycleArchiveFormat(input string) string {
+	lower := strings.ToLower(input)
+	formats := fsutil.ArchiveFormatSuffixes
+	for i, suffix := range formats {
+		if strings.HasSuffix(lower, suffix) {
+			return input[:len(input)-len(suffix)] + formats[(i+1)%len(formats)]
+		}
+	}
+	return input + formats[0]
+}
+
+// submitCompress checks the archive name typed into the compress prompt and
+// hands the job to the app.
+func (s *AppState) submitCompress(prompt *TextPrompt) error {
+	name := strings.TrimSpace(prompt.Input)
+	if name == "" {
+		return errors.New("enter an archive name")
+	}
+	archive := expandHome(name)
+	if !filepath.IsAbs(archive) {
+		archive = filepath.Join(s.CurrentPath, archive)
+	}
+	archive = filepath.Clean(archive)
+	lower := strings.ToLower(archive)
+	if !strings.HasSuffix(lower, ".zip") && !strings.HasSuffix(lower, ".tar.gz") && !strings.HasSuffix(lower, ".tgz") {
+		return errors.New("end the name with .zip or .tar.gz (Tab switches)")
+	}
+	if _, err := os.Lstat(archive); err == nil {
+		return fmt.Errorf("%s already exists", filepath.Base(archive))
+	}
+	if info, err := os.Stat(filepath.Dir(archive)); err != nil || !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", filepath.Dir(archive))
+	}
+	action := CompressAction{Sources: prompt.Paths, Archive: archive}
+	if s.confirmProtectedScope("compress", prompt.Paths, action) {
+		return nil
+	}
+	if dispatch := s.getDispatch(); dispatch != nil {
+		dispatch(action)
+	}
+	return nil
+}
+
+// compressSkipFilter leaves out what the project of each source ignores
+// (its git work tree's .gitignore rules, .git itself, system clutter).
+func compressSkipFilter(sources []string) func(path string, isDir bool) bool {
+	var filters []func(string, bool) bool
+	seen := make(map[string]struct{})
+	for _, src := range sources {
+		root := search.ReferenceRoot(filepath.Dir(src))
+		if _, ok := seen[root]; ok {
+			continue
+		}
+		seen[root] = struct{}{}
+		filters = append(filters, search.IgnoreFilter(root))
+	}
+	return func(path string, isDir bool) bool {
+		for _, ignored := range filters {
+			if ignored(path, isDir) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RunCompression packs a.Sources into a.Archive, sending throttled
+// ArchiveProgressAction updates through send, and returns the result to
+// dispatch once it is done.
+func RunCompression(ctx context.Context, a CompressAction, send func(Action)) ArchiveDoneAction {
+	done := ArchiveDoneAction{Dest: filepath.Dir(a.Archive)}
+	send(ArchiveProgressAction{})
+	result, err := fsutil.CreateArchive(ctx, a.Archive, a.Sources, compressSkipFilter(a.Sources), throttledProgress(0, send))
+	for _, src := range a.Sources {
+		done.Audit = append(done.Audit, NewAuditEntry("compress", src, a.Archive, err))
+	}
+	done.Files = result.Files
+	if err != nil {
+		done.Err = fmt.Errorf("compress %s: %w", filepath.Base(a.Archive), err)
+		return done
+	}
+	done.SelectName = filepath.Base(a.Archive)
+	return done
+}
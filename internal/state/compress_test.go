@@ -0,0 +1,93 @@
+package state
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+)
+
+func TestCompressPromptPacksMarkedEntriesWithoutIgnoredFiles(t *testing.T) {
+	root := t.TempDir()
+	writeFixtureFiles(t, root, map[string]string{
+		".gitignore":     "*.log\n",
+		"app/main.go":    "package main",
+		"app/debug.log":  "noise",
+		"README.md":      "readme",
+		".git/HEAD":      "ref: refs/heads/main\n",
+		"other/skip.txt": "",
+	})
+	state, reducer := loadFixtureState(t, root)
+	var dispatched []Action
+	state.SetDispatch(func(a Action) { dispatched = append(dispatched, a) })
+	state.toggleMark(filepath.Join(root, "app"))
+	state.toggleMark(filepath.Join(root, "README.md"))
+
+	if _, err := reducer.Reduce(state, CompressStartAction{}); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	want := filepath.Base(root) + ".zip"
+	if state.Prompt == nil || state.Prompt.Input != want {
+		t.Fatalf("prompt = %+v, want input %q", state.Prompt, want)
+	}
+	state.Prompt.Input = "bundle.zip"
+	for _, action := range []Action{PromptCycleModeAction{}, PromptSubmitAction{}} {
+		if _, err := reducer.Reduce(state, action); err != nil {
+			t.Fatalf("%T: %v", action, err)
+		}
+	}
+	if len(dispatched) != 1 {
+		t.Fatalf("dispatched = %#v", dispatched)
+	}
+	job, ok := dispatched[0].(CompressAction)
+	if !ok || job.Archive != filepath.Join(root, "bundle.tar.gz") || len(job.Sources) != 2 {
+		t.Fatalf("job = %#v", dispatched[0])
+	}
+
+	state.SetDispatch(nil)
+	if _, err := reducer.Reduce(state, job); err != nil {
+		t.Fatalf("compress: %v", err)
+	}
+	done := RunCompression(context.Background(), job, func(Action) {})
+	if done.Err != nil || done.SelectName != "bundle.tar.gz" || len(done.Audit) != 2 {
+		t.Fatalf("done = %+v", done)
+	}
+	if _, err := reducer.Reduce(state, done); err != nil {
+		t.Fatalf("done: %v", err)
+	}
+	if file := state.getCurrentFile(); state.ArchiveJob != nil || file == nil || file.Name != "bundle.tar.gz" {
+		t.Fatalf("expected the archive selected, got %+v", file)
+	}
+
+	dest := filepath.Join(t.TempDir(), "out")
+	if _, err := fsutil.ExtractArchive(context.Background(), job.Archive, dest, fsutil.ExtractSkip, nil); err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "app", "main.go")); err != nil {
+		t.Fatalf("main.go missing: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "app", "debug.log")); err == nil {
+		t.Fatalf("ignored file was archived")
+	}
+
+	// The name is taken now.
+	state.openPrompt(PromptCompress)
+	state.Prompt.Input = "bundle.tar.gz"
+	if _, err := reducer.Reduce(state, PromptSubmitAction{}); err != nil || state.Prompt == nil || state.Prompt.Err == "" {
+		t.Fatalf("expected an existing archive to keep the prompt open, got %+v", state.Prompt)
+	}
+}
+
+func TestCycleArchiveFormat(t *testing.T) {
+	for input, want := range map[string]string{
+		"site.zip":    "site.tar.gz",
+		"site.tar.gz": "site.zip",
+		"site":        "site.zip",
+	} {
+		if got := cycleArchiveFormat(input); got != want {
+			t.Errorf("cycleArchiveFormat(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package state
+
+// ConfirmRequest is a yes/no question shown in the footer. Answering yes
+// replays Action through the reducer; anything else drops it.
+type ConfirmRequest struct {
+	Prompt string
+	Action Action
+}
+
+func (s *AppState) requestConfirm(prompt string, action Action) {
+	s.PendingConfirm = &ConfirmRequest{Prompt: prompt, Action: action}
+}
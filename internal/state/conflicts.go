@@ -0,0 +1,212 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+)
+
+// ConflictChoice is what to do with an entry whose destination exists.
+type ConflictChoice int
+
+const (
+	ConflictRename ConflictChoice = iota
+	ConflictSkip
+	ConflictOverwrite
+	ConflictKeepNewer
+)
+
+// String names the choice for the conflict dialog.
+func (c ConflictChoice) String() string {
+	switch c {
+	case ConflictSkip:
+		return "skip"
+	case ConflictOverwrite:
+		return "overwrite"
+	case ConflictKeepNewer:
+		return "keep newer"
+	default:
+		return "rename"
+	}
+}
+
+// Conflict is an entry about to land on an existing path.
+type Conflict struct {
+	Source string // entry being pasted or extracted
+	Dest   string // existing path it would replace
+
+	SourceSize    int64
+	SourceModTime time.Time
+	SourceIsDir   bool
+	DestSize      int64
+	DestModTime   time.Time
+	DestIsDir     bool
+}
+
+// newConflict describes src landing on the existing dest.
+func newConflict(src, dest string) Conflict {
+	c := Conflict{Source: src, Dest: dest}
+	if info, err := os.Lstat(src); err == nil {
+		c.SourceSize, c.SourceModTime, c.SourceIsDir = info.Size(), info.ModTime(), info.IsDir()
+	}
+	if info, err := os.Lstat(dest); err == nil {
+		c.DestSize, c.DestModTime, c.DestIsDir = info.Size(), info.ModTime(), info.IsDir()
+	}
+	return c
+}
+
+// ConflictResolutions holds the decisions made in the conflict dialog,
+// keyed by source path. Sources without a decision are renamed.
+type ConflictResolutions struct {
+	Choices map[string]ConflictChoice
+	Pattern string // for renamed entries, see fsutil.PatternDestination
+}
+
+// Resolve returns the choice made for src.
+func (r *ConflictResolutions) Resolve(src string) ConflictChoice {
+	if r == nil {
+		return ConflictRename
+	}
+	return r.Choices[src]
+}
+
+// renamePattern returns the pattern for renamed entries.
+func (r *ConflictResolutions) renamePattern() string {
+	if r == nil || r.Pattern == "" {
+		return fsutil.DefaultRenamePattern
+	}
+	return r.Pattern
+}
+
+// ConflictDialog steps through conflicts one at a time; once every item has
+// a decision it shows their summary until confirmed.
+type ConflictDialog struct {
+	Verb    string // "paste" or "extract"
+	Items   []Conflict
+	Choices []ConflictChoice
+	Index   int // item being decided; len(Items) shows the summary
+	Pattern string
+
+	resume func(ConflictResolutions) Action
+}
+
+// Summary reports whether every item has a decision.
+func (d *ConflictDialog) Summary() bool {
+	return d.Index >= len(d.Items)
+}
+
+// Counts returns how many items got each choice.
+func (d *ConflictDialog) Counts() map[ConflictChoice]int {
+	counts := make(map[ConflictChoice]int)
+	for _, choice := range d.Choices {
+		counts[choice]++
+	}
+	return counts
+}
+
+// openConflicts shows the conflict dialog for items. Confirming it turns
+// the decisions into the action resume returns.
+func (s *AppState) openConflicts(verb string, items []Conflict, resume func(ConflictResolutions) Action) {
+	s.clearTypeAhead()
+	s.Conflicts = &ConflictDialog{
+		Verb:    verb,
+		Items:   items,
+		Choices: make([]ConflictChoice, len(items)),
+		Pattern: fsutil.DefaultRenamePattern,
+		resume:  resume,
+	}
+}
+
+// decideConflict records choice for the current item, or for it and every
+// later one when all is set, and moves on.
+func (s *AppState) decideConflict(choice ConflictChoice, all bool) {
+	d := s.Conflicts
+	if d == nil || d.Summary() {
+		return
+	}
+	if !all {
+		d.Choices[d.Index] = choice
+		d.Index++
+		return
+	}
+	for i := d.Index; i < len(d.Items); i++ {
+		d.Choices[i] = choice
+	}
+	d.Index = len(d.Items)
+}
+
+// conflictBack returns to the previous item to change its decision.
+func (s *AppState) conflictBack() {
+	if d := s.Conflicts; d != nil && d.Index > 0 {
+		d.Index--
+	}
+}
+
+// FinishConflicts closes the conflict dialog and returns the action that
+// carries out its decisions, or nil while items are undecided.
+func (s *AppState) FinishConflicts() Action {
+	d := s.Conflicts
+	if d == nil || !d.Summary() {
+		return nil
+	}
+	s.Conflicts = nil
+	resolutions := ConflictResolutions{Choices: make(map[string]ConflictChoice, len(d.Items)), Pattern: d.Pattern}
+	for i, item := range d.Items {
+		resolutions.Choices[item.Source] = d.Choices[i]
+	}
+	return d.resume(resolutions)
+}
+
+// editConflictPattern opens the prompt for the rename pattern.
+func (s *AppState) editConflictPattern() {
+	if s.Conflicts == nil {
+		return
+	}
+	s.openPrompt(PromptRenamePattern)
+	s.Prompt.Input = s.Conflicts.Pattern
+}
+
+// submitRenamePattern applies the pattern typed into the prompt.
+func (s *AppState) submitRenamePattern(prompt *TextPrompt) error {
+	if s.Conflicts == nil {
+		return nil
+	}
+	if err := fsutil.CheckRenamePattern(prompt.Input); err != nil {
+		return err
+	}
+	s.Conflicts.Pattern = prompt.Input
+	return nil
+}
+
+// pasteConflicts lists the staged entries whose name already exists in
+// destDir. Copies into their own directory always get a fresh name and are
+// not asked about.
+func pasteConflicts(area StagingArea, destDir string) []Conflict {
+	var conflicts []Conflict
+	for _, src := range area.Paths {
+		if filepath.Dir(src) == filepath.Clean(destDir) {
+			continue
+		}
+		dst := filepath.Join(destDir, filepath.Base(src))
+		if _, err := os.Lstat(dst); err == nil {
+			conflicts = append(conflicts, newConflict(src, dst))
+		}
+	}
+	return conflicts
+}
+
+// ResolvePasteConflicts opens the conflict dialog when staged entries would
+// land on existing ones. It reports whether it did; confirming the dialog
+// replays PasteStagedAction with the decisions.
+func (s *AppState) ResolvePasteConflicts() bool {
+	conflicts := pasteConflicts(s.Staging, s.CurrentPath)
+	if len(conflicts) == 0 {
+		return false
+	}
+	s.openConflicts("paste", conflicts, func(r ConflictResolutions) Action {
+		return PasteStagedAction{Confirmed: true, ReferencesChecked: true, Resolutions: &r}
+	})
+	return true
+}
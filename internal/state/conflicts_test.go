@@ -0,0 +1,117 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConflictDialogStepsAndSummarizes(t *testing.T) {
+	src, dest := t.TempDir(), t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "c.txt", "d.txt"} {
+		writeSizedFile(t, filepath.Join(src, name), 1)
+		writeSizedFile(t, filepath.Join(dest, name), 2)
+	}
+	state := &AppState{CurrentPath: dest}
+	state.Staging = StagingArea{Mode: StagingCopy, Paths: []string{
+		filepath.Join(src, "a.txt"), filepath.Join(src, "b.txt"), filepath.Join(src, "c.txt"), filepath.Join(src, "d.txt"),
+	}}
+	if !state.ResolvePasteConflicts() || len(state.Conflicts.Items) != 4 {
+		t.Fatalf("expected four conflicts, got %+v", state.Conflicts)
+	}
+
+	reducer := NewStateReducer()
+	for _, action := range []Action{
+		ConflictChoiceAction{Choice: ConflictSkip},
+		ConflictChoiceAction{Choice: ConflictOverwrite},
+		ConflictBackAction{},
+		ConflictChoiceAction{Choice: ConflictKeepNewer},
+		ConflictChoiceAction{Choice: ConflictOverwrite, All: true},
+	} {
+		if _, err := reducer.Reduce(state, action); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dialog := state.Conflicts
+	if !dialog.Summary() {
+		t.Fatalf("expected the summary after deciding all, index %d", dialog.Index)
+	}
+	counts := dialog.Counts()
+	if counts[ConflictSkip] != 1 || counts[ConflictKeepNewer] != 1 || counts[ConflictOverwrite] != 2 {
+		t.Fatalf("counts = %v", counts)
+	}
+
+	// The pattern prompt rejects patterns that cannot name an entry.
+	for _, action := range []Action{ConflictPatternAction{}, PromptBackspaceAction{}, PromptCharAction{Char: '/'}, PromptSubmitAction{}} {
+		if _, err := reducer.Reduce(state, action); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if state.Prompt == nil || state.Prompt.Err == "" {
+		t.Fatalf("expected the pattern to be refused, prompt %+v", state.Prompt)
+	}
+	state.Prompt.Input = "{name}.{n}{ext}"
+	if _, err := reducer.Reduce(state, PromptSubmitAction{}); err != nil {
+		t.Fatal(err)
+	}
+	if state.Prompt != nil || dialog.Pattern != "{name}.{n}{ext}" {
+		t.Fatalf("pattern = %q, prompt %+v", dialog.Pattern, state.Prompt)
+	}
+
+	paste, ok := state.FinishConflicts().(PasteStagedAction)
+	if !ok || state.Conflicts != nil || paste.Resolutions == nil {
+		t.Fatalf("resumed = %#v", paste)
+	}
+	r := paste.Resolutions
+	if r.Resolve(filepath.Join(src, "a.txt")) != ConflictSkip || r.Resolve(filepath.Join(src, "d.txt")) != ConflictOverwrite || r.Pattern != "{name}.{n}{ext}" {
+		t.Fatalf("resolutions = %+v", r)
+	}
+}
+
+func TestPasteStagingFollowsResolutions(t *testing.T) {
+	src, dest := t.TempDir(), t.TempDir()
+	names := []string{"skip.txt", "over.txt", "rename.txt", "newer.txt", "older.txt", "fresh.txt"}
+	area := StagingArea{Mode: StagingCut}
+	for _, name := range names {
+		path := filepath.Join(src, name)
+		writeSizedFile(t, path, 3)
+		area.Paths = append(area.Paths, path)
+		if name != "fresh.txt" {
+			writeSizedFile(t, filepath.Join(dest, name), 1)
+		}
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(dest, "newer.txt"), past, past); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(src, "older.txt"), past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	resolutions := &ConflictResolutions{Pattern: "{name}-{n}{ext}", Choices: map[string]ConflictChoice{
+		filepath.Join(src, "skip.txt"):   ConflictSkip,
+		filepath.Join(src, "over.txt"):   ConflictOverwrite,
+		filepath.Join(src, "rename.txt"): ConflictRename,
+		filepath.Join(src, "newer.txt"):  ConflictKeepNewer,
+		filepath.Join(src, "older.txt"):  ConflictKeepNewer,
+	}}
+	result := PasteStaging(area, dest, resolutions)
+	if result.Err != nil || result.Pasted != 4 || result.Skipped != 2 {
+		t.Fatalf("result = %+v", result)
+	}
+	sizes := map[string]int64{
+		"skip.txt": 1, "over.txt": 3, "rename.txt": 1, "rename-1.txt": 3,
+		"newer.txt": 3, "older.txt": 1, "fresh.txt": 3,
+	}
+	for name, want := range sizes {
+		info, err := os.Stat(filepath.Join(dest, name))
+		if err != nil || info.Size() != want {
+			t.Errorf("%s: size %v, err %v; want %d", name, info, err, want)
+		}
+	}
+	// Skipped entries stay staged for another try.
+	if len(result.Remaining.Paths) != 2 || !result.Remaining.Contains(filepath.Join(src, "skip.txt")) {
+		t.Fatalf("remaining = %+v", result.Remaining)
+	}
+}
@@ -0,0 +1,75 @@
+package state
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EnvMinContrast sets the lowest contrast ratio text may have against its
+// background, as a WCAG ratio from 1 to 21; off keeps colors as chosen.
+const EnvMinContrast = "RDIR_MIN_CONTRAST"
+
+// EnvBackground says whether the terminal background is dark or light, for
+// terminals that do not report it through COLORFGBG.
+const EnvBackground = "RDIR_BACKGROUND"
+
+// DefaultMinContrast is the WCAG minimum for large text and interface
+// elements; it only lifts colors that are hard to read.
+const DefaultMinContrast = 3.0
+
+// Background is the terminal's background as far as rdir can tell.
+type Background int
+
+const (
+	BackgroundUnknown Background = iota
+	BackgroundDark
+	BackgroundLight
+)
+
+// LoadMinContrast reads RDIR_MIN_CONTRAST. Invalid values keep the default
+// and are reported.
+func LoadMinContrast(getenv func(string) string) (float64, error) {
+	raw := strings.ToLower(strings.TrimSpace(getenv(EnvMinContrast)))
+	switch raw {
+	case "":
+		return DefaultMinContrast, nil
+	case "off", "0", "false", "no":
+		return 0, nil
+	}
+	ratio, err := strconv.ParseFloat(raw, 64)
+	if err != nil || ratio < 1 || ratio > 21 {
+		return DefaultMinContrast, fmt.Errorf("ignoring invalid %s %q (use a ratio from 1 to 21, or off)", EnvMinContrast, raw)
+	}
+	return ratio, nil
+}
+
+// DetectBackground reads RDIR_BACKGROUND, falling back to the background
+// color index some terminals export in COLORFGBG ("15;0"). Colors on the
+// terminal's own background are only adjusted when it is known.
+func DetectBackground(getenv func(string) string) (Background, error) {
+	switch raw := strings.ToLower(strings.TrimSpace(getenv(EnvBackground))); raw {
+	case "":
+	case "dark":
+		return BackgroundDark, nil
+	case "light":
+		return BackgroundLight, nil
+	default:
+		return colorFGBGBackground(getenv), fmt.Errorf("ignoring %s=%q (use dark or light)", EnvBackground, raw)
+	}
+	return colorFGBGBackground(getenv), nil
+}
+
+func colorFGBGBackground(getenv func(string) string) Background {
+	fields := strings.Split(getenv("COLORFGBG"), ";")
+	index, err := strconv.Atoi(strings.TrimSpace(fields[len(fields)-1]))
+	if err != nil || index < 0 || index > 15 {
+		return BackgroundUnknown
+	}
+	// The light entries of the 16-color palette: white, grey and the bright
+	// colors except bright black.
+	if index == 7 || index >= 9 {
+		return BackgroundLight
+	}
+	return BackgroundDark
+}
@@ -0,0 +1,44 @@
+package state
+
+import "testing"
+
+func TestLoadMinContrast(t *testing.T) {
+	cases := []struct {
+		value string
+		want  float64
+		err   bool
+	}{
+		{"", DefaultMinContrast, false},
+		{"4.5", 4.5, false},
+		{"off", 0, false},
+		{"0.5", DefaultMinContrast, true},
+		{"bright", DefaultMinContrast, true},
+	}
+	for _, tc := range cases {
+		got, err := LoadMinContrast(func(string) string { return tc.value })
+		if got != tc.want || (err != nil) != tc.err {
+			t.Fatalf("LoadMinContrast(%q) = %v, %v", tc.value, got, err)
+		}
+	}
+}
+
+func TestDetectBackground(t *testing.T) {
+	cases := []struct {
+		env  map[string]string
+		want Background
+		err  bool
+	}{
+		{map[string]string{}, BackgroundUnknown, false},
+		{map[string]string{"COLORFGBG": "15;0"}, BackgroundDark, false},
+		{map[string]string{"COLORFGBG": "0;default;15"}, BackgroundLight, false},
+		{map[string]string{"COLORFGBG": "0;default"}, BackgroundUnknown, false},
+		{map[string]string{EnvBackground: "light", "COLORFGBG": "15;0"}, BackgroundLight, false},
+		{map[string]string{EnvBackground: "grey", "COLORFGBG": "15;0"}, BackgroundDark, true},
+	}
+	for _, tc := range cases {
+		got, err := DetectBackground(func(key string) string { return tc.env[key] })
+		if got != tc.want || (err != nil) != tc.err {
+			t.Fatalf("DetectBackground(%v) = %v, %v", tc.env, got, err)
+		}
+	}
+}
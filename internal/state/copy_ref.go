@@ -0,0 +1,128 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+)
+
+// EnvCopyRef sets the template the pager's "copy reference" action fills in,
+// e.g. "{path}:{line}" or "{url}".
+const EnvCopyRef = "RDIR_COPY_REF"
+
+// DefaultCopyRefTemplate copies the absolute path and line number.
+const DefaultCopyRefTemplate = "{path}:{line}"
+
+var copyRefPlaceholder = regexp.MustCompile(`\{[a-z]+\}`)
+
+// copyRefGitPlaceholders need the file's git checkout.
+var copyRefGitPlaceholders = map[string]bool{
+	"{relpath}": true,
+	"{url}":     true,
+	"{remote}":  true,
+	"{commit}":  true,
+	"{branch}":  true,
+}
+
+// LoadCopyRefTemplate reads RDIR_COPY_REF. Placeholders are {path}, {name},
+// {line}, and from the git checkout {relpath}, {remote} (web URL), {commit},
+// {branch} and {url} (the file's permalink on the web host). A template with
+// unknown placeholders falls back to the default.
+func LoadCopyRefTemplate(getenv func(string) string) (string, error) {
+	template := strings.TrimSpace(getenv(EnvCopyRef))
+	if template == "" {
+		return DefaultCopyRefTemplate, nil
+	}
+	var unknown []string
+	for _, name := range copyRefPlaceholder.FindAllString(template, -1) {
+		switch {
+		case name == "{path}", name == "{name}", name == "{line}", copyRefGitPlaceholders[name]:
+		default:
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return DefaultCopyRefTemplate, fmt.Errorf("ignoring %s: unknown placeholders %s (use {path}, {name}, {line}, {relpath}, {remote}, {commit}, {branch} or {url})", EnvCopyRef, strings.Join(unknown, ", "))
+	}
+	return template, nil
+}
+
+// CopyRefNeedsGit reports whether template uses git placeholders.
+func CopyRefNeedsGit(template string) bool {
+	for _, name := range copyRefPlaceholder.FindAllString(template, -1) {
+		if copyRefGitPlaceholders[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpandCopyRef fills in template for line (1-based) of the file at path.
+// git is only consulted when the template needs it.
+func ExpandCopyRef(template, path string, line int, git func(dir string) (fsutil.GitInfo, error)) (string, error) {
+	if template == "" {
+		template = DefaultCopyRefTemplate
+	}
+	values := map[string]string{
+		"{path}": path,
+		"{name}": filepath.Base(path),
+		"{line}": strconv.Itoa(line),
+	}
+	if CopyRefNeedsGit(template) {
+		info, err := git(filepath.Dir(path))
+		if err != nil {
+			return "", err
+		}
+		// git reports the work tree with symlinks resolved, so compare it
+		// with the file's resolved path (macOS's /tmp is /private/tmp).
+		rel, err := filepath.Rel(resolveSymlinks(info.Root), resolveSymlinks(path))
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", fmt.Errorf("%s is outside the git work tree", filepath.Base(path))
+		}
+		rel = filepath.ToSlash(rel)
+		web := fsutil.GitWebURL(info.Remote)
+		values["{relpath}"] = rel
+		values["{remote}"] = web
+		values["{commit}"] = info.Commit
+		values["{branch}"] = info.Branch
+		if web != "" {
+			values["{url}"] = fmt.Sprintf("%s/blob/%s/%s#L%d", web, info.Commit, escapeURLPath(rel), line)
+		}
+		for _, name := range []string{"{remote}", "{url}"} {
+			if strings.Contains(template, name) && values[name] == "" {
+				return "", errors.New("no web URL for the origin remote")
+			}
+		}
+		if strings.Contains(template, "{branch}") && info.Branch == "" {
+			return "", errors.New("HEAD is detached; {branch} is unavailable")
+		}
+	}
+	return copyRefPlaceholder.ReplaceAllStringFunc(template, func(name string) string {
+		return values[name]
+	}), nil
+}
+
+// resolveSymlinks returns path with symlinks resolved, or path itself when
+// it cannot be resolved.
+func resolveSymlinks(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
+
+// escapeURLPath escapes each segment of a slash-separated path for use in a
+// URL, so names with spaces, "#" or "?" do not break the link.
+func escapeURLPath(rel string) string {
+	segments := strings.Split(rel, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
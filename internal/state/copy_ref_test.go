@@ -0,0 +1,84 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+)
+
+func TestLoadCopyRefTemplate(t *testing.T) {
+	got, err := LoadCopyRefTemplate(func(string) string { return "" })
+	if err != nil || got != DefaultCopyRefTemplate {
+		t.Fatalf("default: got %q, %v", got, err)
+	}
+	got, err = LoadCopyRefTemplate(func(string) string { return "{relpath}#{line}" })
+	if err != nil || got != "{relpath}#{line}" {
+		t.Fatalf("custom: got %q, %v", got, err)
+	}
+	got, err = LoadCopyRefTemplate(func(string) string { return "{file}:{line}" })
+	if err == nil || got != DefaultCopyRefTemplate {
+		t.Fatalf("unknown placeholder: got %q, %v", got, err)
+	}
+}
+
+func TestExpandCopyRef(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "work", "repo")
+	path := filepath.Join(root, "cmd", "main.go")
+	git := func(string) (fsutil.GitInfo, error) {
+		return fsutil.GitInfo{Root: root, Remote: "git@github.com:acme/repo.git", Commit: "abc123", Branch: "main"}, nil
+	}
+
+	got, err := ExpandCopyRef("", path, 42, nil)
+	if err != nil || got != path+":42" {
+		t.Fatalf("default: got %q, %v", got, err)
+	}
+	got, err = ExpandCopyRef("{url}", path, 7, git)
+	if err != nil || got != "https://github.com/acme/repo/blob/abc123/cmd/main.go#L7" {
+		t.Fatalf("url: got %q, %v", got, err)
+	}
+	got, err = ExpandCopyRef("{remote}/tree/{branch}/{relpath}", path, 7, git)
+	if err != nil || got != "https://github.com/acme/repo/tree/main/cmd/main.go" {
+		t.Fatalf("branch: got %q, %v", got, err)
+	}
+
+	odd := filepath.Join(root, "docs", "a b#1?.md")
+	got, err = ExpandCopyRef("{url} {relpath}", odd, 3, git)
+	if err != nil || got != "https://github.com/acme/repo/blob/abc123/docs/a%20b%231%3F.md#L3 docs/a b#1?.md" {
+		t.Fatalf("escaped url: got %q, %v", got, err)
+	}
+
+	noRepo := func(string) (fsutil.GitInfo, error) { return fsutil.GitInfo{}, errors.New("not a repo") }
+	if _, err := ExpandCopyRef("{url}", path, 1, noRepo); err == nil {
+		t.Fatalf("expected an error outside a repository")
+	}
+	local := func(string) (fsutil.GitInfo, error) { return fsutil.GitInfo{Root: root, Commit: "abc123"}, nil }
+	if _, err := ExpandCopyRef("{url}", path, 1, local); err == nil {
+		t.Fatalf("expected an error without a web remote")
+	}
+}
+
+func TestExpandCopyRefResolvesSymlinkedPaths(t *testing.T) {
+	target := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(target, "pkg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(target, "pkg", "a.go")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(t.TempDir(), "checkout")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	git := func(string) (fsutil.GitInfo, error) {
+		resolved, _ := filepath.EvalSymlinks(target)
+		return fsutil.GitInfo{Root: resolved, Commit: "abc123"}, nil
+	}
+	got, err := ExpandCopyRef("{relpath}", filepath.Join(link, "pkg", "a.go"), 1, git)
+	if err != nil || got != "pkg/a.go" {
+		t.Fatalf("got %q, %v", got, err)
+	}
+}
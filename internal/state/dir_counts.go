@@ -0,0 +1,81 @@
+package state
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// dirCountCacheLimit bounds how many directories keep a cached entry count.
+const dirCountCacheLimit = 4096
+
+// dirCount is the entry count of a directory as of its modification time.
+// Adding, removing or renaming entries bumps the directory mtime, which
+// invalidates the cached count.
+type dirCount struct {
+	modTime time.Time
+	total   int
+	hidden  int
+}
+
+// dirCountCache remembers entry counts of previewed directories so revisits
+// can show "N items" before the listing is read again. It is shared by the
+// preview loader goroutines and the reducer.
+type dirCountCache struct {
+	mu      sync.Mutex
+	entries map[string]dirCount
+}
+
+var dirEntryCounts = &dirCountCache{}
+
+func (c *dirCountCache) store(path string, modTime time.Time, total, hidden int) {
+	path = filepath.Clean(path)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]dirCount)
+	}
+	if _, ok := c.entries[path]; !ok && len(c.entries) >= dirCountCacheLimit {
+		// Evict an arbitrary entry; counts are cheap to rebuild.
+		for victim := range c.entries {
+			delete(c.entries, victim)
+			break
+		}
+	}
+	c.entries[path] = dirCount{modTime: modTime, total: total, hidden: hidden}
+}
+
+// lookup returns the visible entry count for path when the cached value was
+// recorded for the same mtime.
+func (c *dirCountCache) lookup(path string, modTime time.Time, hideHidden bool) (int, bool) {
+	path = filepath.Clean(path)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[path]
+	if !ok || !entry.modTime.Equal(modTime) {
+		return 0, false
+	}
+	if hideHidden {
+		return entry.total - entry.hidden, true
+	}
+	return entry.total, true
+}
+
+// PreviewItemCount returns the number of entries to report for the selected
+// directory: the loaded preview when available, otherwise a cached count that
+// is still valid for the directory's mtime. The entry's mtime is the
+// directory's own (from Lstat) like the cached one; symlinked directories
+// have no cached count.
+func (s *AppState) PreviewItemCount() (int, bool) {
+	file := s.getCurrentFile()
+	if file == nil || !file.IsDir {
+		return 0, false
+	}
+	if s.PreviewData != nil && s.PreviewData.IsDir && s.PreviewData.Name == file.Name {
+		return len(s.PreviewData.DirEntries), true
+	}
+	if file.IsSymlink {
+		return 0, false
+	}
+	return dirEntryCounts.lookup(entryPath(s, file), file.Modified, s.HideHiddenFiles)
+}
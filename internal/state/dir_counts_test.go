@@ -0,0 +1,91 @@
+package state
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDirCountCacheInvalidatesOnMtimeChange(t *testing.T) {
+	cache := &dirCountCache{}
+	mtime := time.Unix(1700000000, 0)
+	cache.store("/tmp/dir", mtime, 5, 2)
+
+	if got, ok := cache.lookup("/tmp/dir/", mtime, false); !ok || got != 5 {
+		t.Fatalf("lookup = %d,%v, want 5,true", got, ok)
+	}
+	if got, ok := cache.lookup("/tmp/dir", mtime, true); !ok || got != 3 {
+		t.Fatalf("lookup hiding hidden = %d,%v, want 3,true", got, ok)
+	}
+	if _, ok := cache.lookup("/tmp/dir", mtime.Add(time.Second), false); ok {
+		t.Fatalf("expected stale mtime to miss")
+	}
+}
+
+func TestPreviewItemCountUsesCacheBeforePreviewLoads(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "sub")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a", "b", ".hidden"} {
+		if err := os.WriteFile(filepath.Join(sub, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := buildPreviewData(context.Background(), nil, sub, true); err != nil {
+		t.Fatalf("buildPreviewData: %v", err)
+	}
+	info, err := os.Stat(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newState := func(modified time.Time) *AppState {
+		return &AppState{
+			CurrentPath:     root,
+			HideHiddenFiles: true,
+			Files:           []FileEntry{{Name: "sub", IsDir: true, Modified: modified}},
+		}
+	}
+	if got, ok := newState(info.ModTime()).PreviewItemCount(); !ok || got != 2 {
+		t.Fatalf("PreviewItemCount = %d,%v, want 2,true", got, ok)
+	}
+	if _, ok := newState(info.ModTime().Add(-time.Minute)).PreviewItemCount(); ok {
+		t.Fatalf("expected count to be invalidated when the mtime differs")
+	}
+}
+
+func TestPreviewItemCountSkipsSymlinkedDirectories(t *testing.T) {
+	root := t.TempDir()
+	target := filepath.Join(root, "target")
+	if err := os.Mkdir(target, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(target, "a"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(root, "link")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	if _, _, err := buildPreviewData(context.Background(), nil, link, false); err != nil {
+		t.Fatalf("buildPreviewData: %v", err)
+	}
+	linfo, err := os.Lstat(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := dirEntryCounts.lookup(link, linfo.ModTime(), false); ok {
+		t.Fatal("expected no count cached under the link's own mtime")
+	}
+	state := &AppState{
+		CurrentPath: root,
+		Files:       []FileEntry{{Name: "link", IsDir: true, IsSymlink: true, Modified: linfo.ModTime()}},
+	}
+	if _, ok := state.PreviewItemCount(); ok {
+		t.Fatal("expected no cached count for a symlinked directory")
+	}
+}
@@ -2,7 +2,10 @@ package state
 
 import (
 	"context"
-	"sync"
+	"fmt"
+	"time"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
 )
 
 // DirectoryLoader performs directory reads asynchronously.
@@ -11,10 +14,15 @@ type DirectoryLoader interface {
 	Cancel(token int)
 }
 
-// DirectoryLoadRequest describes a directory read to perform.
+// DirectoryLoadRequest describes a directory read to perform. Timeout
+// overrides the default deadline when positive; Read replaces the listing
+// of FS (nil: the local filesystem) when set.
 type DirectoryLoadRequest struct {
 	Token    int
 	Path     string
+	Timeout  time.Duration
+	FS       fsutil.FS
+	Read     EntryReader
 	Callback func(DirectoryLoadResult)
 }
 
@@ -28,14 +36,11 @@ type DirectoryLoadResult struct {
 
 // NewAsyncDirectoryLoader constructs the default goroutine-based loader.
 func NewAsyncDirectoryLoader() DirectoryLoader {
-	return &asyncDirectoryLoader{
-		jobs: make(map[int]context.CancelFunc),
-	}
+	return &asyncDirectoryLoader{jobs: newLoadJobs()}
 }
 
 type asyncDirectoryLoader struct {
-	mu   sync.Mutex
-	jobs map[int]context.CancelFunc
+	jobs *loadJobs
 }
 
 func (l *asyncDirectoryLoader) Start(req DirectoryLoadRequest) {
@@ -43,24 +48,27 @@ func (l *asyncDirectoryLoader) Start(req DirectoryLoadRequest) {
 		return
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	l.mu.Lock()
-	l.jobs[req.Token] = cancel
-	l.mu.Unlock()
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = directoryLoadTimeout
+	}
+	ctx, release := l.jobs.begin(req.Token, timeout)
 
 	go func() {
-		defer func() {
-			l.mu.Lock()
-			delete(l.jobs, req.Token)
-			l.mu.Unlock()
-		}()
-
-		entries, err := readDirectoryEntries(req.Path)
+		defer release()
 
-		select {
-		case <-ctx.Done():
+		var entries []FileEntry
+		var err error
+		if req.Read != nil {
+			entries, err = req.Read(ctx, req.Path)
+		} else {
+			entries, err = readDirectoryEntries(ctx, req.FS, req.Path)
+		}
+		if loadCancelled(ctx) {
 			return
-		default:
+		}
+		if err == context.DeadlineExceeded {
+			err = fmt.Errorf("reading %s timed out after %s", req.Path, timeout)
 		}
 
 		req.Callback(DirectoryLoadResult{
@@ -73,10 +81,5 @@ func (l *asyncDirectoryLoader) Start(req DirectoryLoadRequest) {
 }
 
 func (l *asyncDirectoryLoader) Cancel(token int) {
-	l.mu.Lock()
-	if cancel, ok := l.jobs[token]; ok {
-		cancel()
-		delete(l.jobs, token)
-	}
-	l.mu.Unlock()
+	l.jobs.cancel(token)
 }
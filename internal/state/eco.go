@@ -0,0 +1,45 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EnvEco controls eco mode, which trims background work: "auto" (default)
+// turns it on while the machine runs on battery, "on" and "off" force it.
+const EnvEco = "RDIR_ECO"
+
+// EcoMode is the configured eco mode. Toggling eco mode (Z) switches it to
+// EcoOn or EcoOff for the rest of the session.
+type EcoMode int
+
+const (
+	EcoAuto EcoMode = iota
+	EcoOn
+	EcoOff
+)
+
+// ecoPreviewDebounce is the minimum preview debounce in eco mode, so holding
+// j/k does not start a preview load for every entry passed over.
+const ecoPreviewDebounce = 250 * time.Millisecond
+
+// LoadEcoMode reads RDIR_ECO; an unknown value keeps "auto" and is reported
+// in the returned error.
+func LoadEcoMode(getenv func(string) string) (EcoMode, error) {
+	switch value := strings.ToLower(strings.TrimSpace(getenv(EnvEco))); value {
+	case "", "auto":
+		return EcoAuto, nil
+	case "on", "1", "true", "yes":
+		return EcoOn, nil
+	case "off", "0", "false", "no":
+		return EcoOff, nil
+	default:
+		return EcoAuto, fmt.Errorf("ignoring %s=%q (use auto, on or off)", EnvEco, value)
+	}
+}
+
+// updateEco recomputes EcoActive from the mode and the last power source.
+func (s *AppState) updateEco() {
+	s.EcoActive = s.Eco == EcoOn || (s.Eco == EcoAuto && s.OnBattery)
+}
@@ -0,0 +1,48 @@
+package state
+
+import "testing"
+
+func TestLoadEcoMode(t *testing.T) {
+	cases := map[string]EcoMode{"": EcoAuto, "auto": EcoAuto, "ON": EcoOn, "off": EcoOff}
+	for value, want := range cases {
+		got, err := LoadEcoMode(func(string) string { return value })
+		if err != nil || got != want {
+			t.Errorf("LoadEcoMode(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+	if got, err := LoadEcoMode(func(string) string { return "sometimes" }); err == nil || got != EcoAuto {
+		t.Errorf("expected an error and auto for an unknown value, got %v, %v", got, err)
+	}
+}
+
+func TestEcoModeFollowsBatteryUntilToggled(t *testing.T) {
+	state := &AppState{}
+	reducer := NewStateReducer()
+	reduce := func(action Action) {
+		t.Helper()
+		if _, err := reducer.Reduce(state, action); err != nil {
+			t.Fatalf("reduce %T: %v", action, err)
+		}
+	}
+
+	reduce(PowerSourceAction{OnBattery: true})
+	if !state.EcoActive {
+		t.Fatalf("auto eco mode should turn on on battery")
+	}
+	profile := state.pathProfile()
+	if profile.PrefetchAncestors || profile.PreviewDebounce < ecoPreviewDebounce || !profile.StatSymlinkTargets {
+		t.Fatalf("unexpected eco profile %+v", profile)
+	}
+
+	reduce(ToggleEcoModeAction{})
+	if state.EcoActive || state.Eco != EcoOff {
+		t.Fatalf("toggle should leave eco mode, got active=%v mode=%v", state.EcoActive, state.Eco)
+	}
+	reduce(PowerSourceAction{OnBattery: true})
+	if state.EcoActive {
+		t.Fatalf("a manual choice should outlast power source updates")
+	}
+	if state.pathProfile() != defaultPathProfile {
+		t.Fatalf("expected the default profile outside eco mode")
+	}
+}
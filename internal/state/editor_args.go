@@ -0,0 +1,85 @@
+package state
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// editorLineStyles maps editor executables to how they take a line number
+// when the command has no {line} placeholder.
+var editorLineStyles = map[string]string{
+	"vi":     "+{line} {file}",
+	"vim":    "+{line} {file}",
+	"nvim":   "+{line} {file}",
+	"gvim":   "+{line} {file}",
+	"view":   "+{line} {file}",
+	"nano":   "+{line} {file}",
+	"pico":   "+{line} {file}",
+	"emacs":  "+{line} {file}",
+	"micro":  "+{line} {file}",
+	"kak":    "+{line} {file}",
+	"joe":    "+{line} {file}",
+	"mg":     "+{line} {file}",
+	"code":   "--goto {file}:{line}",
+	"codium": "--goto {file}:{line}",
+	"cursor": "--goto {file}:{line}",
+	"subl":   "{file}:{line}",
+	"hx":     "{file}:{line}",
+	"helix":  "{file}:{line}",
+	"zed":    "{file}:{line}",
+}
+
+// EditorArgs builds the editor invocation for file. Arguments of the editor
+// command (VISUAL/EDITOR) may contain {file} and {line} placeholders, e.g.
+// "code --goto {file}:{line}"; without {file} the path is appended. line is
+// 1-based, or 0 when there is no particular line (placeholders then get 1).
+// Commands without placeholders for a known editor open at line when it is
+// set, otherwise they just get the file.
+func EditorArgs(editorCmd []string, file string, line int) []string {
+	if len(editorCmd) == 0 {
+		return nil
+	}
+	args := []string{editorCmd[0]}
+	rest := editorCmd[1:]
+	if !hasEditorPlaceholder(rest) {
+		style := editorLineStyles[editorName(editorCmd[0])]
+		if line <= 0 || style == "" {
+			return append(append(args, rest...), file)
+		}
+		rest = append(append([]string(nil), rest...), strings.Fields(style)...)
+	}
+
+	lineText := "1"
+	if line > 0 {
+		lineText = strconv.Itoa(line)
+	}
+	replacer := strings.NewReplacer("{file}", file, "{line}", lineText)
+	sawFile := false
+	for _, arg := range rest {
+		sawFile = sawFile || strings.Contains(arg, "{file}")
+		args = append(args, replacer.Replace(arg))
+	}
+	if !sawFile {
+		args = append(args, file)
+	}
+	return args
+}
+
+func hasEditorPlaceholder(args []string) bool {
+	for _, arg := range args {
+		if strings.Contains(arg, "{file}") || strings.Contains(arg, "{line}") {
+			return true
+		}
+	}
+	return false
+}
+
+// editorName is the executable's base name without a Windows extension.
+func editorName(cmd string) string {
+	name := strings.ToLower(filepath.Base(strings.ReplaceAll(cmd, `\`, "/")))
+	for _, ext := range []string{".exe", ".cmd", ".bat"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name
+}
@@ -0,0 +1,27 @@
+package state
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditorArgs(t *testing.T) {
+	cases := []struct {
+		cmd  []string
+		line int
+		want []string
+	}{
+		{[]string{"/usr/bin/vim"}, 0, []string{"/usr/bin/vim", "a.go"}},
+		{[]string{"/usr/bin/vim"}, 12, []string{"/usr/bin/vim", "+12", "a.go"}},
+		{[]string{"code.cmd", "--wait"}, 7, []string{"code.cmd", "--wait", "--goto", "a.go:7"}},
+		{[]string{"hx"}, 3, []string{"hx", "a.go:3"}},
+		{[]string{"ed"}, 3, []string{"ed", "a.go"}},
+		{[]string{"myedit", "-l", "{line}"}, 9, []string{"myedit", "-l", "9", "a.go"}},
+		{[]string{"myedit", "{file}#{line}"}, 0, []string{"myedit", "a.go#1"}},
+	}
+	for _, tc := range cases {
+		if got := EditorArgs(tc.cmd, "a.go", tc.line); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("EditorArgs(%q, %d) = %q, want %q", tc.cmd, tc.line, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package state
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// EnterBehavior describes what Enter/→ does when the selection is a file.
+type EnterBehavior string
+
+const (
+	EnterPager  EnterBehavior = "pager"  // fullscreen preview pager (default)
+	EnterEditor EnterBehavior = "editor" // $VISUAL / $EDITOR
+	EnterOpen   EnterBehavior = "open"   // OS handler (open, xdg-open, start)
+	EnterPrint  EnterBehavior = "print"  // quit and print the path to stdout
+)
+
+const (
+	// EnvEnter sets the default Enter behavior for files.
+	EnvEnter = "RDIR_ENTER"
+	// EnvEnterExt holds per-extension overrides, e.g. "md=editor,pdf:open".
+	EnvEnterExt = "RDIR_ENTER_EXT"
+)
+
+// EnterConfig resolves the Enter behavior per file.
+type EnterConfig struct {
+	Default EnterBehavior
+	ByExt   map[string]EnterBehavior // lower-case extension without the dot
+}
+
+// LoadEnterConfig reads RDIR_ENTER and RDIR_ENTER_EXT. Invalid values are
+// skipped and reported in the returned error; the config is always usable.
+func LoadEnterConfig(getenv func(string) string) (EnterConfig, error) {
+	cfg := EnterConfig{Default: EnterPager}
+	var problems []string
+
+	if raw := strings.TrimSpace(getenv(EnvEnter)); raw != "" {
+		if b, ok := parseEnterBehavior(raw); ok {
+			cfg.Default = b
+		} else {
+			problems = append(problems, fmt.Sprintf("%s=%q", EnvEnter, raw))
+		}
+	}
+
+	for _, item := range strings.Split(getenv(EnvEnterExt), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		ext, value, found := strings.Cut(item, "=")
+		if !found {
+			ext, value, found = strings.Cut(item, ":")
+		}
+		ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+		b, ok := parseEnterBehavior(value)
+		if !found || ext == "" || !ok {
+			problems = append(problems, fmt.Sprintf("%s entry %q", EnvEnterExt, item))
+			continue
+		}
+		if cfg.ByExt == nil {
+			cfg.ByExt = make(map[string]EnterBehavior)
+		}
+		cfg.ByExt[ext] = b
+	}
+
+	if len(problems) > 0 {
+		return cfg, fmt.Errorf("ignoring invalid Enter config: %s (use pager, editor, open or print)", strings.Join(problems, ", "))
+	}
+	return cfg, nil
+}
+
+func parseEnterBehavior(raw string) (EnterBehavior, bool) {
+	switch b := EnterBehavior(strings.ToLower(strings.TrimSpace(raw))); b {
+	case EnterPager, EnterEditor, EnterOpen, EnterPrint:
+		return b, true
+	}
+	return "", false
+}
+
+// For returns the behavior for a file name.
+func (c EnterConfig) For(name string) EnterBehavior {
+	if ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(name), ".")); ext != "" {
+		if b, ok := c.ByExt[ext]; ok {
+			return b
+		}
+	}
+	if c.Default == "" {
+		return EnterPager
+	}
+	return c.Default
+}
+
+// Picks reports whether Enter prints paths by default, which makes rdir act
+// as a file picker. An extension that prints does not: leaving without
+// opening such a file is a normal exit, not a cancelled pick.
+func (c EnterConfig) Picks() bool {
+	return c.Default == EnterPrint
+}
+
+// PrintsPaths reports whether Enter prints paths to stdout for some files.
+func (c EnterConfig) PrintsPaths() bool {
+	if c.Default == EnterPrint {
+		return true
+	}
+	for _, b := range c.ByExt {
+		if b == EnterPrint {
+			return true
+		}
+	}
+	return false
+}
+
+// Overrides lists the per-extension overrides as "ext→behavior", sorted.
+func (c EnterConfig) Overrides() []string {
+	out := make([]string, 0, len(c.ByExt))
+	for ext, b := range c.ByExt {
+		out = append(out, fmt.Sprintf(".%s→%s", ext, b))
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Description returns a short help text for the behavior.
+func (b EnterBehavior) Description() string {
+	switch b {
+	case EnterEditor:
+		return "open in editor"
+	case EnterOpen:
+		return "open with system handler"
+	case EnterPrint:
+		return "quit and print path"
+	default:
+		return "preview in pager"
+	}
+}
@@ -0,0 +1,38 @@
+package state
+
+import "testing"
+
+func TestLoadEnterConfigDefaultsToPager(t *testing.T) {
+	cfg, err := LoadEnterConfig(func(string) string { return "" })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.For("notes.txt"); got != EnterPager {
+		t.Fatalf("For(notes.txt) = %q, want pager", got)
+	}
+}
+
+func TestLoadEnterConfigAppliesExtensionOverrides(t *testing.T) {
+	env := map[string]string{
+		EnvEnter:    "Editor",
+		EnvEnterExt: ".PDF=open, png:open, log=pager, bogus=launch, =print",
+	}
+	cfg, err := LoadEnterConfig(func(key string) string { return env[key] })
+	if err == nil {
+		t.Fatal("expected invalid entries to be reported")
+	}
+
+	tests := map[string]EnterBehavior{
+		"main.go":    EnterEditor,
+		"report.pdf": EnterOpen,
+		"shot.PNG":   EnterOpen,
+		"app.log":    EnterPager,
+		"x.bogus":    EnterEditor,
+		"Makefile":   EnterEditor,
+	}
+	for name, want := range tests {
+		if got := cfg.For(name); got != want {
+			t.Errorf("For(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/kk-code-lab/rdir/internal/procwatch"
+)
+
+// ErrorSource is the part of rdir an error came from. Each keeps its own
+// banner, so a failing preview does not hide a directory that would not
+// load. Sources are listed in the order their banners are shown.
+type ErrorSource int
+
+const (
+	ErrorSourceDirectory ErrorSource = iota // listing a directory
+	ErrorSourceFiles                        // paste, rename and other file operations
+	ErrorSourcePreview                      // building the preview
+	ErrorSourceGeneral                      // settings, clipboard, external commands
+	errorSourceCount
+)
+
+// String names the source in the banner; general errors have no name.
+func (s ErrorSource) String() string {
+	switch s {
+	case ErrorSourceDirectory:
+		return "directory"
+	case ErrorSourceFiles:
+		return "file operation"
+	case ErrorSourcePreview:
+		return "preview"
+	default:
+		return ""
+	}
+}
+
+// ErrorKind classifies an error by what the user can do about it.
+type ErrorKind int
+
+const (
+	ErrorKindOther ErrorKind = iota
+	ErrorKindPermission
+	ErrorKindNotFound
+	ErrorKindTimeout
+	ErrorKindIO
+)
+
+// String names the kind in the banner.
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorKindPermission:
+		return "permission denied"
+	case ErrorKindNotFound:
+		return "not found"
+	case ErrorKindTimeout:
+		return "timed out"
+	case ErrorKindIO:
+		return "I/O error"
+	default:
+		return "error"
+	}
+}
+
+// ClassifyError returns the kind of err.
+func ClassifyError(err error) ErrorKind {
+	var timeout *procwatch.TimeoutError
+	var timer interface{ Timeout() bool }
+	var pathErr *fs.PathError
+	var linkErr *os.LinkError
+	var syscallErr *os.SyscallError
+	switch {
+	case err == nil:
+		return ErrorKindOther
+	case errors.Is(err, fs.ErrPermission):
+		return ErrorKindPermission
+	case errors.Is(err, fs.ErrNotExist):
+		return ErrorKindNotFound
+	case errors.As(err, &timeout), errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &timer) && timer.Timeout():
+		return ErrorKindTimeout
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, syscall.EIO),
+		errors.As(err, &pathErr), errors.As(err, &linkErr), errors.As(err, &syscallErr):
+		return ErrorKindIO
+	default:
+		return ErrorKindOther
+	}
+}
+
+// ErrorBannerAction is something the banner offers to do about its error.
+type ErrorBannerAction int
+
+const (
+	ErrorActionRetry ErrorBannerAction = iota
+	ErrorActionGoUp
+	ErrorActionReadAsRoot
+	ErrorActionDismiss
+)
+
+// ErrorBanner is the latest error of one source.
+type ErrorBanner struct {
+	Source ErrorSource
+	Kind   ErrorKind
+	Err    error
+	Path   string // what failed, when known
+
+	retry Action // repeats what failed; nil when it cannot be repeated
+}
+
+// CanRetry reports whether what failed can be repeated.
+func (b *ErrorBanner) CanRetry() bool {
+	return b.retry != nil
+}
+
+// ErrorBanners holds the banner of each source.
+type ErrorBanners [errorSourceCount]*ErrorBanner
+
+// Err returns the error of source, or nil.
+func (b *ErrorBanners) Err(source ErrorSource) error {
+	if banner := b[source]; banner != nil {
+		return banner.Err
+	}
+	return nil
+}
+
+// Count returns how many sources have an error.
+func (b *ErrorBanners) Count() int {
+	n := 0
+	for _, banner := range b {
+		if banner != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Top returns the banner shown first, or nil.
+func (b *ErrorBanners) Top() *ErrorBanner {
+	for _, banner := range b {
+		if banner != nil {
+			return banner
+		}
+	}
+	return nil
+}
+
+// holds reports whether err is already shown by a banner.
+func (b *ErrorBanners) holds(err error) bool {
+	for _, banner := range b {
+		if banner != nil && errors.Is(banner.Err, err) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReportError shows err in the banner of source, replacing its previous
+// error. A nil err, or one another banner already shows, is ignored.
+func (s *AppState) ReportError(source ErrorSource, err error) {
+	s.reportError(source, err, "", nil)
+}
+
+// reportError shows err for path in the banner of source; retry repeats
+// what failed.
+func (s *AppState) reportError(source ErrorSource, err error, path string, retry Action) {
+	if err == nil || s.Errors.holds(err) {
+		return
+	}
+	s.Errors[source] = &ErrorBanner{
+		Source: source,
+		Kind:   ClassifyError(err),
+		Err:    err,
+		Path:   path,
+		retry:  retry,
+	}
+}
+
+// reportDirectoryError shows that path could not be listed. Retrying reloads
+// the current directory or goes to path again.
+func (s *AppState) reportDirectoryError(err error, path string) {
+	var retry Action = GoToPathAction{Path: path}
+	if filepath.Clean(path) == filepath.Clean(s.CurrentPath) {
+		retry = RefreshDirectoryAction{}
+	}
+	s.reportError(ErrorSourceDirectory, err, path, retry)
+}
+
+// reportPreviewError shows that the preview of path could not be built.
+func (s *AppState) reportPreviewError(err error, path string) {
+	s.reportError(ErrorSourcePreview, err, path, PreviewReloadAction{})
+}
+
+// ClearError removes the banner of source.
+func (s *AppState) ClearError(source ErrorSource) {
+	s.Errors[source] = nil
+}
+
+// ErrorBanner returns the banner to show, or nil when there is none or the
+// footer is busy with search, a prompt or an overlay. Its actions are
+// only bound while it is shown.
+func (s *AppState) ErrorBanner() *ErrorBanner {
+	if s.FilterActive || s.GlobalSearchActive || s.TypeAheadActive || s.PreviewFullScreen ||
+		s.HelpVisible || s.AuditView != nil || s.Conflicts != nil || s.PendingConfirm != nil ||
+		s.Prompt != nil || s.Rename != nil || s.PathChoice != nil || s.ArchiveJob != nil {
+		return nil
+	}
+	return s.Errors.Top()
+}
+
+// ErrorActions lists what banner offers, ending with dismiss.
+func (s *AppState) ErrorActions(banner *ErrorBanner) []ErrorBannerAction {
+	if banner == nil {
+		return nil
+	}
+	var actions []ErrorBannerAction
+	if banner.CanRetry() {
+		actions = append(actions, ErrorActionRetry)
+	}
+	if banner.Source == ErrorSourceDirectory && banner.Path != "" &&
+		filepath.Clean(banner.Path) == filepath.Clean(s.CurrentPath) &&
+		filepath.Dir(s.CurrentPath) != s.CurrentPath {
+		actions = append(actions, ErrorActionGoUp)
+	}
+	if banner.Kind == ErrorKindPermission && banner.Source != ErrorSourceDirectory && len(s.PrivilegedHelper) > 0 {
+		if file := s.getCurrentFile(); file != nil && !file.IsDir {
+			actions = append(actions, ErrorActionReadAsRoot)
+		}
+	}
+	return append(actions, ErrorActionDismiss)
+}
+
+// dismissError hides the banner shown, revealing the next one.
+func (s *AppState) dismissError() {
+	if banner := s.Errors.Top(); banner != nil {
+		s.Errors[banner.Source] = nil
+	}
+}
+
+// RetryError dismisses the banner shown and returns the action that repeats
+// what failed, or nil when it cannot be repeated.
+func (s *AppState) RetryError() Action {
+	banner := s.ErrorBanner()
+	if banner == nil || !banner.CanRetry() {
+		return nil
+	}
+	s.Errors[banner.Source] = nil
+	return banner.retry
+}
@@ -0,0 +1,148 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/kk-code-lab/rdir/internal/procwatch"
+)
+
+func TestClassifyError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want ErrorKind
+	}{
+		{&fs.PathError{Op: "open", Path: "/x", Err: fs.ErrPermission}, ErrorKindPermission},
+		{fmt.Errorf("cannot read directory /x: %w", &fs.PathError{Op: "open", Path: "/x", Err: fs.ErrNotExist}), ErrorKindNotFound},
+		{&procwatch.TimeoutError{Command: "xdg-open"}, ErrorKindTimeout},
+		{fmt.Errorf("list: %w", context.DeadlineExceeded), ErrorKindTimeout},
+		{&fs.PathError{Op: "read", Path: "/x", Err: errors.New("input/output error")}, ErrorKindIO},
+		{errors.New("no shell command available"), ErrorKindOther},
+	}
+	for _, tc := range cases {
+		if got := ClassifyError(tc.err); got != tc.want {
+			t.Errorf("ClassifyError(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestErrorBannersKeepOneErrorPerSource(t *testing.T) {
+	state := &AppState{}
+	previewErr := errors.New("preview failed")
+	dirErr := errors.New("listing failed")
+
+	state.ReportError(ErrorSourcePreview, previewErr)
+	state.ReportError(ErrorSourceDirectory, dirErr)
+	state.ReportError(ErrorSourceGeneral, dirErr)
+
+	if got := state.Errors.Count(); got != 2 {
+		t.Fatalf("expected an error already shown not to be reported again, got %d banners", got)
+	}
+	if top := state.ErrorBanner(); top == nil || top.Err != dirErr {
+		t.Fatalf("expected the directory error first, got %+v", top)
+	}
+
+	state.dismissError()
+	if top := state.ErrorBanner(); top == nil || top.Err != previewErr {
+		t.Fatalf("expected the preview error after dismissing, got %+v", top)
+	}
+
+	state.FilterActive = true
+	if state.ErrorBanner() != nil {
+		t.Fatal("expected no banner while filtering")
+	}
+}
+
+func TestDirectoryLoadErrorSurvivesPreviewAndClearsOnLoad(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	state := &AppState{CurrentPath: dir, ScreenHeight: 24, ScreenWidth: 80}
+	reducer := NewStateReducer()
+
+	missing := filepath.Join(dir, "gone")
+	if err := reducer.changeDirectory(state, missing); err == nil {
+		t.Fatal("expected changing into a missing directory to fail")
+	}
+	banner := state.Errors[ErrorSourceDirectory]
+	if banner == nil || banner.Kind != ErrorKindNotFound || banner.Path != missing {
+		t.Fatalf("expected a not-found directory banner for %s, got %+v", missing, banner)
+	}
+	if retry, ok := banner.retry.(GoToPathAction); !ok || retry.Path != missing {
+		t.Fatalf("expected retry to go to %s again, got %#v", missing, banner.retry)
+	}
+
+	state.ReportError(ErrorSourcePreview, errors.New("preview failed"))
+	if top := state.ErrorBanner(); top == nil || top.Source != ErrorSourceDirectory {
+		t.Fatalf("expected the preview error not to hide the directory error, got %+v", top)
+	}
+
+	if err := reducer.changeDirectory(state, dir); err != nil {
+		t.Fatalf("change directory: %v", err)
+	}
+	if state.Errors[ErrorSourceDirectory] != nil {
+		t.Fatal("expected a successful load to clear the directory banner")
+	}
+}
+
+func TestErrorActionsForCurrentDirectory(t *testing.T) {
+	dir := t.TempDir()
+	state := &AppState{CurrentPath: dir}
+	state.reportDirectoryError(&fs.PathError{Op: "open", Path: dir, Err: fs.ErrNotExist}, dir)
+
+	banner := state.ErrorBanner()
+	if _, ok := banner.retry.(RefreshDirectoryAction); !ok {
+		t.Fatalf("expected retry to refresh the current directory, got %#v", banner.retry)
+	}
+	want := []ErrorBannerAction{ErrorActionRetry, ErrorActionGoUp, ErrorActionDismiss}
+	if got := state.ErrorActions(banner); !slices.Equal(got, want) {
+		t.Fatalf("ErrorActions() = %v, want %v", got, want)
+	}
+}
+
+func TestErrorActionsOfferPrivilegedReadForPermissionErrors(t *testing.T) {
+	state := &AppState{
+		CurrentPath:      "/tmp",
+		Files:            []FileEntry{{Name: "secret.txt"}},
+		PrivilegedHelper: []string{"sudo", "cat"},
+	}
+	state.ReportError(ErrorSourceGeneral, &fs.PathError{Op: "open", Path: "/tmp/secret.txt", Err: fs.ErrPermission})
+
+	want := []ErrorBannerAction{ErrorActionReadAsRoot, ErrorActionDismiss}
+	if got := state.ErrorActions(state.ErrorBanner()); !slices.Equal(got, want) {
+		t.Fatalf("ErrorActions() = %v, want %v", got, want)
+	}
+}
+
+func TestErrorRetryReplaysFailedAction(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "later")
+	state := &AppState{CurrentPath: dir, ScreenHeight: 24, ScreenWidth: 80}
+	reducer := NewStateReducer()
+	if err := reducer.changeDirectory(state, dir); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if err := reducer.changeDirectory(state, sub); err == nil {
+		t.Fatal("expected a missing directory to fail")
+	}
+
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reducer.Reduce(state, ErrorRetryAction{}); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if state.CurrentPath != sub {
+		t.Fatalf("expected retry to enter %s, got %s", sub, state.CurrentPath)
+	}
+	if state.Errors.Count() != 0 {
+		t.Fatalf("expected no banner after a successful retry, got %d", state.Errors.Count())
+	}
+}
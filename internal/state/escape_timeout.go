@@ -0,0 +1,35 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EnvEscTimeout sets how long the pager waits for the rest of an escape
+// sequence after a lone ESC, e.g. "250ms" on a slow SSH link.
+const EnvEscTimeout = "RDIR_ESC_TIMEOUT"
+
+const (
+	// DefaultEscapeTimeout matches vim's usual ttimeoutlen.
+	DefaultEscapeTimeout = 100 * time.Millisecond
+	maxEscapeTimeout     = 2 * time.Second
+)
+
+// LoadEscapeTimeout reads RDIR_ESC_TIMEOUT, a duration up to 2s; 0 makes a
+// lone ESC act immediately. Invalid values fall back to the default and are
+// reported.
+func LoadEscapeTimeout(getenv func(string) string) (time.Duration, error) {
+	raw := strings.TrimSpace(getenv(EnvEscTimeout))
+	if raw == "" {
+		return DefaultEscapeTimeout, nil
+	}
+	if raw == "0" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(raw)
+	if err != nil || timeout < 0 || timeout > maxEscapeTimeout {
+		return DefaultEscapeTimeout, fmt.Errorf("ignoring invalid %s %q (use a duration such as 100ms, at most %s)", EnvEscTimeout, raw, maxEscapeTimeout)
+	}
+	return timeout, nil
+}
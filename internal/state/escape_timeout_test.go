@@ -0,0 +1,27 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadEscapeTimeout(t *testing.T) {
+	cases := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", DefaultEscapeTimeout, false},
+		{"250ms", 250 * time.Millisecond, false},
+		{"0", 0, false},
+		{"5s", DefaultEscapeTimeout, true},
+		{"-1ms", DefaultEscapeTimeout, true},
+		{"slow", DefaultEscapeTimeout, true},
+	}
+	for _, tc := range cases {
+		got, err := LoadEscapeTimeout(func(string) string { return tc.value })
+		if got != tc.want || (err != nil) != tc.wantErr {
+			t.Errorf("LoadEscapeTimeout(%q) = %s, %v; want %s, err=%v", tc.value, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+)
+
+// selectedArchives returns the marked archives, or the selected entry when
+// nothing marked is an archive.
+func (s *AppState) selectedArchives() ([]string, error) {
+	var archives []string
+	for _, path := range s.MarkedPaths() {
+		if _, ok := fsutil.ArchiveStem(filepath.Base(path)); ok {
+			if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+				archives = append(archives, path)
+			}
+		}
+	}
+	if len(archives) > 0 {
+		return archives, nil
+	}
+	file := s.getCurrentFile()
+	if file == nil || file.IsDir {
+		return nil, errors.New("select or mark an archive (.zip, .tar, .tar.gz, .tar.bz2) to extract")
+	}
+	if _, ok := fsutil.ArchiveStem(file.Name); !ok {
+		return nil, fmt.Errorf("%s is not an archive rdir can extract", file.Name)
+	}
+	return []string{s.getCurrentFilePath()}, nil
+}
+
+// startExtract (U) asks where to extract the selected or marked archives,
+// starting from the current directory.
+func (s *AppState) startExtract() error {
+	if s.ArchiveJob != nil {
+		return errors.New(s.ArchiveJob.Verb + " is still running")
+	}
+	if err := s.RequireLocalDisk("extract archives"); err != nil {
+		return err
+	}
+	archives, err := s.selectedArchives()
+	if err != nil {
+		return err
+	}
+	s.openPrompt(PromptExtract)
+	s.Prompt.Input = s.CurrentPath
+	s.Prompt.Paths = archives
+	return nil
+}
+
+// submitExtract checks the destination typed into the extract prompt and
+// hands the job to the app.
+func (s *AppState) submitExtract(prompt *TextPrompt) error {
+	dest := strings.TrimSpace(prompt.Input)
+	if dest == "" {
+		return errors.New("enter a destination directory")
+	}
+	dest = expandHome(dest)
+	if !filepath.IsAbs(dest) {
+		dest = filepath.Join(s.CurrentPath, dest)
+	}
+	dest = filepath.Clean(dest)
+	if info, err := os.Stat(dest); err == nil && !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dest)
+	}
+	dispatch := s.getDispatch()
+	if dispatch == nil {
+		return nil
+	}
+	targets := make([]ExtractTarget, len(prompt.Paths))
+	var conflicts []Conflict
+	for i, archive := range prompt.Paths {
+		targets[i] = ExtractTarget{Archive: archive, Folder: ExtractFolder(archive, dest), Collision: fsutil.ExtractSkip}
+		if _, err := os.Lstat(targets[i].Folder); err == nil {
+			conflicts = append(conflicts, newConflict(archive, targets[i].Folder))
+		}
+	}
+	if len(conflicts) == 0 {
+		dispatch(ExtractArchivesAction{Targets: targets, Dest: dest})
+		return nil
+	}
+	s.openConflicts("extract", conflicts, func(r ConflictResolutions) Action {
+		return resolveExtractTargets(targets, dest, r)
+	})
+	return nil
+}
+
+// ExtractTarget is one archive of an extraction, the folder it goes into and
+// what happens to files that already exist there.
+type ExtractTarget struct {
+	Archive   string
+	Folder    string
+	Collision fsutil.ExtractCollision
+}
+
+// resolveExtractTargets applies the conflict decisions to targets whose
+// folder exists: skipped archives are dropped, renamed ones get a fresh
+// folder and the others extract into the existing one. It returns nil when
+// nothing is left to extract.
+func resolveExtractTargets(targets []ExtractTarget, dest string, r ConflictResolutions) Action {
+	var resolved []ExtractTarget
+	for _, target := range targets {
+		if _, err := os.Lstat(target.Folder); err != nil {
+			resolved = append(resolved, target)
+			continue
+		}
+		switch r.Resolve(target.Archive) {
+		case ConflictSkip:
+			continue
+		case ConflictOverwrite:
+			target.Collision = fsutil.ExtractOverwrite
+		case ConflictKeepNewer:
+			target.Collision = fsutil.ExtractKeepNewer
+		default:
+			target.Folder = fsutil.PatternDestination(dest, filepath.Base(target.Folder), r.renamePattern(), true)
+		}
+		resolved = append(resolved, target)
+	}
+	if len(resolved) == 0 {
+		return nil
+	}
+	return ExtractArchivesAction{Targets: resolved, Dest: dest}
+}
+
+// expandHome expands a leading ~ to the home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// ExtractFolder returns the folder an archive is extracted into: dest/<stem>.
+func ExtractFolder(archive, dest string) string {
+	stem, ok := fsutil.ArchiveStem(filepath.Base(archive))
+	if !ok {
+		stem = filepath.Base(archive)
+	}
+	return filepath.Join(dest, stem)
+}
+
+// RunExtraction extracts each target of a into its folder, sending
+// throttled ArchiveProgressAction updates through send. It stops at the
+// first failing archive or when ctx is canceled, and returns the result to
+// dispatch once it is done.
+func RunExtraction(ctx context.Context, a ExtractArchivesAction, send func(Action)) ArchiveDoneAction {
+	done := ArchiveDoneAction{Dest: a.Dest}
+	var unsafe int
+	for i, target := range a.Targets {
+		archive, folder := target.Archive, target.Folder
+		send(ArchiveProgressAction{Index: i})
+		result, err := fsutil.ExtractArchive(ctx, archive, folder, target.Collision, throttledProgress(i, send))
+		done.Audit = append(done.Audit, NewAuditEntry("extract", archive, folder, err))
+		done.Files += result.Files
+		unsafe += result.Unsafe
+		if done.SelectName == "" {
+			done.SelectName = filepath.Base(folder)
+		}
+		if err != nil {
+			done.Err = fmt.Errorf("extract %s: %w", filepath.Base(archive), err)
+			return done
+		}
+	}
+	if unsafe > 0 {
+		done.Err = fmt.Errorf("extract: refused %d entries that would land outside the destination", unsafe)
+	}
+	return done
+}
@@ -0,0 +1,106 @@
+package state
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+	"github.com/kk-code-lab/rdir/internal/testutil"
+)
+
+func TestExtractPromptExtractsMarkedArchivesAndSelectsFolder(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteZip(t, filepath.Join(root, "site.zip"), map[string]string{"index.html": "", "css/app.css": ""})
+	testutil.WriteZip(t, filepath.Join(root, "docs.zip"), map[string]string{"guide.md": ""})
+	if err := os.WriteFile(filepath.Join(root, "notes.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// An existing folder asks what to do; renaming picks "docs (1)".
+	if err := os.Mkdir(filepath.Join(root, "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	state, reducer := loadFixtureState(t, root)
+	var dispatched []Action
+	state.SetDispatch(func(a Action) { dispatched = append(dispatched, a) })
+	state.toggleMark(filepath.Join(root, "docs.zip"))
+	state.toggleMark(filepath.Join(root, "site.zip"))
+	state.toggleMark(filepath.Join(root, "notes.txt"))
+
+	for _, action := range []Action{ExtractStartAction{}, PromptSubmitAction{}} {
+		if _, err := reducer.Reduce(state, action); err != nil {
+			t.Fatalf("%T: %v", action, err)
+		}
+	}
+	if state.Prompt != nil {
+		t.Fatalf("expected prompt to close, got %+v", state.Prompt)
+	}
+	if len(dispatched) != 0 || state.Conflicts == nil || len(state.Conflicts.Items) != 1 {
+		t.Fatalf("expected the conflict dialog, got %#v / %+v", dispatched, state.Conflicts)
+	}
+	if _, err := reducer.Reduce(state, ConflictChoiceAction{Choice: ConflictRename}); err != nil {
+		t.Fatal(err)
+	}
+	job, ok := state.FinishConflicts().(ExtractArchivesAction)
+	if !ok || job.Dest != root || len(job.Targets) != 2 || state.Conflicts != nil {
+		t.Fatalf("job = %#v", job)
+	}
+	if folder := job.Targets[0].Folder; filepath.Base(folder) != "docs (1)" || job.Targets[0].Collision != fsutil.ExtractSkip {
+		t.Fatalf("docs target = %+v", job.Targets[0])
+	}
+
+	state.SetDispatch(nil)
+	if _, err := reducer.Reduce(state, job); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	var progress int
+	done := RunExtraction(context.Background(), job, func(a Action) {
+		if _, ok := a.(ArchiveProgressAction); ok {
+			progress++
+		}
+	})
+	if done.Err != nil || done.Files != 3 || len(done.Audit) != 2 || progress < 2 {
+		t.Fatalf("done = %+v, progress = %d", done, progress)
+	}
+	if done.SelectName != "docs (1)" {
+		t.Fatalf("select = %q", done.SelectName)
+	}
+	if _, err := os.Stat(filepath.Join(root, "site", "css", "app.css")); err != nil {
+		t.Fatalf("site not extracted: %v", err)
+	}
+
+	if _, err := reducer.Reduce(state, done); err != nil {
+		t.Fatalf("done: %v", err)
+	}
+	if state.ArchiveJob != nil {
+		t.Fatalf("expected extraction to end")
+	}
+	if file := state.getCurrentFile(); file == nil || file.Name != "docs (1)" {
+		t.Fatalf("expected extracted folder selected, got %+v", file)
+	}
+}
+
+func TestExtractStartRejectsNonArchives(t *testing.T) {
+	state := &AppState{Files: []FileEntry{{Name: "notes.txt"}}}
+	if err := state.startExtract(); err == nil || state.Prompt != nil {
+		t.Fatalf("expected an error without a prompt, got %v / %+v", err, state.Prompt)
+	}
+}
+
+func TestArchiveJobStatusSanitizesEntryNames(t *testing.T) {
+	job := &ArchiveJob{
+		Verb:     "extracting",
+		Names:    []string{"evil\x1b]0;title\a.zip"},
+		Progress: fsutil.ArchiveProgress{Files: 3, Current: "dir/\x1b[2Jclear.txt"},
+	}
+	status := job.Status()
+	if strings.ContainsRune(status, '\x1b') || strings.ContainsRune(status, '\a') {
+		t.Fatalf("expected control characters to be neutralized, got %q", status)
+	}
+	if !strings.Contains(status, "clear.txt") {
+		t.Fatalf("expected the entry name to stay readable, got %q", status)
+	}
+}
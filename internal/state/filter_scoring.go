@@ -0,0 +1,144 @@
+package state
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	search "github.com/kk-code-lab/rdir/internal/search"
+)
+
+// EnvFilterScoring tunes the filter's (/) scores and ordering, e.g.
+// "prefix=1,dir=0.5,ext=0.8,order=score".
+const EnvFilterScoring = "RDIR_FILTER_SCORING"
+
+// FilterScoring holds the adjustments added on top of the fuzzy match score.
+// The zero value keeps plain fuzzy scores and directory order.
+type FilterScoring struct {
+	PrefixBonus float64 // per token that matches at the start of the name
+	DirBonus    float64 // for directories
+	ExtPenalty  float64 // per token that only matches inside the extension
+	RankByScore bool    // order results by score instead of directory order
+}
+
+// FilterScore is one entry's score broken down for the debug overlay. Prefix
+// and Ext are averaged over the query tokens like Match.
+type FilterScore struct {
+	Match  float64
+	Prefix float64
+	Dir    float64
+	Ext    float64 // negative or zero
+	Total  float64
+}
+
+// LoadFilterScoring reads RDIR_FILTER_SCORING: comma-separated prefix=, dir=
+// and ext= weights and order=score|name. Invalid entries are skipped and
+// reported in the returned error.
+func LoadFilterScoring(getenv func(string) string) (FilterScoring, error) {
+	var cfg FilterScoring
+	var problems []string
+	for _, item := range strings.Split(getenv(EnvFilterScoring), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, found := strings.Cut(item, "=")
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.ToLower(strings.TrimSpace(value))
+		if !found {
+			problems = append(problems, fmt.Sprintf("%q", item))
+			continue
+		}
+		if key == "order" {
+			switch value {
+			case "score":
+				cfg.RankByScore = true
+			case "name", "dir", "directory":
+				cfg.RankByScore = false
+			default:
+				problems = append(problems, fmt.Sprintf("%q", item))
+			}
+			continue
+		}
+		weight, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%q", item))
+			continue
+		}
+		switch key {
+		case "prefix":
+			cfg.PrefixBonus = weight
+		case "dir":
+			cfg.DirBonus = weight
+		case "ext":
+			cfg.ExtPenalty = weight
+		default:
+			problems = append(problems, fmt.Sprintf("%q", item))
+		}
+	}
+	if len(problems) > 0 {
+		return cfg, fmt.Errorf("ignoring invalid %s entries: %s (use prefix=, dir=, ext=<number> or order=score|name)", EnvFilterScoring, strings.Join(problems, ", "))
+	}
+	return cfg, nil
+}
+
+func (c FilterScoring) adjusts() bool {
+	return c.PrefixBonus != 0 || c.DirBonus != 0 || c.ExtPenalty != 0
+}
+
+// score applies the adjustments to a match of tokens against target, the
+// (lower-cased unless case-sensitive, maybe folded) name of file.
+func (c FilterScoring) score(file *FileEntry, target string, tokens []filterToken, match float64, matcher *search.FuzzyMatcher) FilterScore {
+	result := FilterScore{Match: match}
+	if len(tokens) > 0 {
+		stem := ""
+		if c.ExtPenalty != 0 && !file.IsDir {
+			stem = strings.TrimSuffix(target, filepath.Ext(target))
+		}
+		for _, token := range tokens {
+			if c.PrefixBonus != 0 && strings.HasPrefix(target, token.pattern) {
+				result.Prefix += c.PrefixBonus
+			}
+			if stem != "" && stem != target {
+				if _, inStem := matcher.Match(token.pattern, stem); !inStem {
+					result.Ext -= c.ExtPenalty
+				}
+			}
+		}
+		result.Prefix /= float64(len(tokens))
+		result.Ext /= float64(len(tokens))
+	}
+	if file.IsDir {
+		result.Dir = c.DirBonus
+	}
+	result.Total = result.Match + result.Prefix + result.Dir + result.Ext
+	return result
+}
+
+// rankFilterMatches orders the filter results by descending score, keeping
+// directory order between equal scores.
+func (s *AppState) rankFilterMatches() {
+	order := make([]int, len(s.FilterMatches))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return s.FilterMatches[order[a]].Score > s.FilterMatches[order[b]].Score
+	})
+	matches := make([]FuzzyMatch, len(order))
+	indices := make([]int, len(order))
+	for i, from := range order {
+		matches[i] = s.FilterMatches[from]
+		indices[i] = s.FilteredIndices[from]
+	}
+	s.FilterMatches = matches
+	s.FilteredIndices = indices
+}
+
+// FilterScoreFor returns the score breakdown of Files[fileIdx] while the
+// filter's score overlay (Ctrl+D) is on.
+func (s *AppState) FilterScoreFor(fileIdx int) (FilterScore, bool) {
+	score, ok := s.filterScores[fileIdx]
+	return score, ok
+}
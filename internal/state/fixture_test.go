@@ -0,0 +1,57 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newFixtureState creates names in a temp directory and loads it. A name
+// ending in "/" is a directory; a file holds its own name.
+func newFixtureState(t *testing.T, names ...string) (*AppState, *StateReducer, string) {
+	t.Helper()
+	root := t.TempDir()
+	for _, name := range names {
+		path := filepath.Join(root, name)
+		if strings.HasSuffix(name, "/") {
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	state, reducer := loadFixtureState(t, root)
+	return state, reducer, root
+}
+
+// writeFixtureFiles writes files (slash-separated names to contents) below
+// root, creating their directories.
+func writeFixtureFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// loadFixtureState loads dir into a state with a 100x30 screen.
+func loadFixtureState(t *testing.T, dir string) (*AppState, *StateReducer) {
+	t.Helper()
+	state := &AppState{ScreenHeight: 30, ScreenWidth: 100}
+	if err := LoadDirectory(state, dir); err != nil {
+		t.Fatalf("LoadDirectory: %v", err)
+	}
+	return state, NewStateReducer()
+}
@@ -0,0 +1,45 @@
+package state
+
+import "errors"
+
+// FollowSnapshot is what a shared session (rdir --share) sends its
+// followers: enough to show the same directory and selection.
+type FollowSnapshot struct {
+	Path       string `json:"path"`
+	Selected   string `json:"selected,omitempty"`
+	ShowHidden bool   `json:"show_hidden,omitempty"`
+}
+
+// FollowSnapshot describes where the session is for followers.
+func (s *AppState) FollowSnapshot() FollowSnapshot {
+	snap := FollowSnapshot{Path: s.CurrentPath, ShowHidden: !s.HideHiddenFiles}
+	if file := s.getCurrentFile(); file != nil {
+		snap.Selected = file.Name
+	}
+	return snap
+}
+
+// errFollowEnded is shown when the followed session stops sharing.
+var errFollowEnded = errors.New("the followed session ended; rdir is interactive again")
+
+// applyFollowSnapshot mirrors the followed session: its hidden-files
+// setting, directory and selected entry.
+func (r *StateReducer) applyFollowSnapshot(state *AppState, snap FollowSnapshot) (*AppState, error) {
+	if snap.Path == "" {
+		return state, nil
+	}
+	if state.HideHiddenFiles == snap.ShowHidden {
+		state.HideHiddenFiles = !snap.ShowHidden
+		state.clearReveal()
+		state.invalidateDisplayFilesCache()
+	}
+	if snap.Path != state.navigationPath() {
+		return r.openTabLocation(state, Tab{Path: snap.Path, SelectName: snap.Selected})
+	}
+	if idx := findFileIndexByName(state.Files, snap.Selected); idx >= 0 && idx != state.SelectedIndex {
+		state.SelectedIndex = idx
+		r.ensureSelectionVisible(state)
+		return state, r.generatePreview(state)
+	}
+	return state, nil
+}
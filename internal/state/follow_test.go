@@ -0,0 +1,42 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFollowSnapshotMirrorsDirectoryAndSelection(t *testing.T) {
+	state, reducer, root := newTabsTestState(t, 2)
+	sub := filepath.Join(root, "d1")
+	for _, name := range []string{"a.txt", "b.txt", ".env"} {
+		if err := os.WriteFile(filepath.Join(sub, name), nil, 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	if _, err := reducer.Reduce(state, FollowSnapshotAction{Snapshot: FollowSnapshot{Path: sub, Selected: "b.txt"}}); err != nil {
+		t.Fatalf("follow: %v", err)
+	}
+	if state.CurrentPath != sub {
+		t.Fatalf("expected to follow into %s, at %s", sub, state.CurrentPath)
+	}
+	if got := state.FollowSnapshot(); got.Selected != "b.txt" || got.ShowHidden {
+		t.Fatalf("expected b.txt selected with hidden files off, got %+v", got)
+	}
+
+	if _, err := reducer.Reduce(state, FollowSnapshotAction{Snapshot: FollowSnapshot{Path: sub, Selected: ".env", ShowHidden: true}}); err != nil {
+		t.Fatalf("follow: %v", err)
+	}
+	if got := state.FollowSnapshot(); got.Selected != ".env" || !got.ShowHidden {
+		t.Fatalf("expected the hidden .env selected, got %+v", got)
+	}
+
+	state.Following = "/tmp/s.sock"
+	if _, err := reducer.Reduce(state, FollowEndedAction{}); err != nil {
+		t.Fatalf("follow ended: %v", err)
+	}
+	if state.Following != "" || state.Errors.Err(ErrorSourceGeneral) == nil {
+		t.Fatalf("expected following to stop with a message, got %q, %v", state.Following, state.Errors.Err(ErrorSourceGeneral))
+	}
+}
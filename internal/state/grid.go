@@ -0,0 +1,122 @@
+package state
+
+import "github.com/kk-code-lab/rdir/internal/textutil"
+
+const (
+	// gridPrefixWidth covers the marker, icon and space drawn before a name.
+	gridPrefixWidth = 3
+	// gridGap separates neighbouring grid columns.
+	gridGap = 2
+)
+
+// ListGrid is how the file list is laid out: Columns of Rows entries, filled
+// top to bottom and then left to right like ls -C. A single column is the
+// plain list.
+type ListGrid struct {
+	Columns     int
+	Rows        int
+	ColumnWidth int
+}
+
+// Capacity is the number of entries one screen of the grid shows.
+func (g ListGrid) Capacity() int {
+	return g.Columns * g.Rows
+}
+
+// ListGrid computes the list layout for the current entries. The grid falls
+// back to a single column when it is off, when names are too long for two
+// columns to fit the list panel, or when there is nothing to spread out.
+func (s *AppState) ListGrid() ListGrid {
+	rows := s.visibleLines()
+	single := ListGrid{Columns: 1, Rows: rows, ColumnWidth: s.ListWidth}
+	if !s.ListGridEnabled || s.ListWidth <= 0 || rows <= 0 {
+		return single
+	}
+	if s.displayFilesDirty || s.displayFilesCache == nil {
+		s.getDisplayFiles()
+	}
+	count := len(s.displayFilesCache)
+	columns := (s.ListWidth + gridGap) / (gridPrefixWidth + s.displayNameWidth + gridGap)
+	if columns < 2 || count < 2 {
+		return single
+	}
+	if count <= columns*rows {
+		// Everything fits: balance the columns the way ls -C does.
+		rows = (count + columns - 1) / columns
+		columns = (count + rows - 1) / rows
+	}
+	return ListGrid{Columns: columns, Rows: rows, ColumnWidth: s.ListWidth / columns}
+}
+
+// widestName returns the display width of the longest name in files.
+func widestName(files []FileEntry) int {
+	widest := 0
+	for i := range files {
+		if width := textutil.DisplayWidth(textutil.SanitizeTerminalText(files[i].Name)); width > widest {
+			widest = width
+		}
+	}
+	return widest
+}
+
+// scrollGrid scrolls the grid by whole columns so that displayIdx is shown,
+// or centred when center is set.
+func (s *AppState) scrollGrid(grid ListGrid, displayIdx int, center bool) {
+	column := displayIdx / grid.Rows
+	first := s.ScrollOffset / grid.Rows
+	switch {
+	case center:
+		first = column - grid.Columns/2
+	case column < first:
+		first = column
+	case column >= first+grid.Columns:
+		first = column - grid.Columns + 1
+	}
+	total := (len(s.displayFilesCache) + grid.Rows - 1) / grid.Rows
+	if first > total-grid.Columns {
+		first = total - grid.Columns
+	}
+	if first < 0 {
+		first = 0
+	}
+	s.ScrollOffset = first * grid.Rows
+}
+
+// gridMoveTarget returns the display index reached by moving the selection
+// by columns grid columns, or false when there is no column that way. A
+// shorter last column is entered at its final entry.
+func (s *AppState) gridMoveTarget(columns int) (int, bool) {
+	grid := s.ListGrid()
+	idx := s.getDisplaySelectedIndex()
+	if grid.Columns < 2 || idx < 0 {
+		return 0, false
+	}
+	count := len(s.displayFilesCache)
+	target := idx + columns*grid.Rows
+	if target < 0 {
+		return 0, false
+	}
+	if target >= count {
+		if idx/grid.Rows+columns > (count-1)/grid.Rows {
+			return 0, false
+		}
+		target = count - 1
+	}
+	return target, true
+}
+
+// CanMoveInGrid reports whether ←/→ move the selection between grid columns
+// instead of leaving or entering directories.
+func (s *AppState) CanMoveInGrid(columns int) bool {
+	_, ok := s.gridMoveTarget(columns)
+	return ok
+}
+
+// listPageSize is how far PgUp/PgDn move the selection: a screen of rows,
+// or a screen of the grid.
+func (s *AppState) listPageSize() int {
+	if grid := s.ListGrid(); grid.Columns > 1 {
+		return grid.Capacity()
+	}
+	return s.visibleLines()
+}
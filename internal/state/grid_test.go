@@ -0,0 +1,121 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func gridTestState(count, width, height int) *AppState {
+	files := make([]FileEntry, count)
+	for i := range files {
+		files[i] = FileEntry{Name: fmt.Sprintf("f%02d", i)}
+	}
+	return &AppState{
+		CurrentPath:     "/test",
+		Files:           files,
+		ScreenHeight:    height,
+		ScreenWidth:     80,
+		ListWidth:       width,
+		ListGridEnabled: true,
+	}
+}
+
+func TestListGridLayout(t *testing.T) {
+	// 21 visible rows; cells are 3 (prefix) + 3 (name) + 2 (gap) wide.
+	state := gridTestState(10, 40, 24)
+	if got, want := state.ListGrid(), (ListGrid{Columns: 5, Rows: 2, ColumnWidth: 8}); got != want {
+		t.Fatalf("balanced grid = %+v, want %+v", got, want)
+	}
+
+	state.ListGridEnabled = false
+	if got := state.ListGrid(); got.Columns != 1 || got.Rows != 21 {
+		t.Fatalf("disabled grid = %+v, want one column", got)
+	}
+
+	narrow := gridTestState(10, 12, 24)
+	if got := narrow.ListGrid(); got.Columns != 1 {
+		t.Fatalf("narrow grid = %+v, want one column", got)
+	}
+
+	long := gridTestState(10, 40, 24)
+	long.Files[3].Name = strings.Repeat("n", 30)
+	long.invalidateDisplayFilesCache()
+	if got := long.ListGrid(); got.Columns != 1 {
+		t.Fatalf("grid with a long name = %+v, want one column", got)
+	}
+
+	// Taller lists fill every visible row.
+	tall := gridTestState(48, 40, 8)
+	if got, want := tall.ListGrid(), (ListGrid{Columns: 5, Rows: 5, ColumnWidth: 8}); got != want {
+		t.Fatalf("scrolling grid = %+v, want %+v", got, want)
+	}
+}
+
+func TestGridMovesBetweenColumns(t *testing.T) {
+	// Five columns of five rows on screen, ten columns in total; the last
+	// one holds f45..f47.
+	state := gridTestState(48, 40, 8)
+	reducer := NewStateReducer()
+	move := func(columns int) {
+		t.Helper()
+		if _, err := reducer.Reduce(state, GridMoveAction{Columns: columns}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	move(1)
+	if state.SelectedIndex != 5 {
+		t.Fatalf("→ from f00 selected %d, want 5", state.SelectedIndex)
+	}
+	move(-1)
+	if state.SelectedIndex != 0 || state.CanMoveInGrid(-1) {
+		t.Fatalf("← back selected %d, can move left = %v", state.SelectedIndex, state.CanMoveInGrid(-1))
+	}
+
+	state.SelectedIndex = 44
+	move(1)
+	if state.SelectedIndex != 47 {
+		t.Fatalf("→ into the shorter last column selected %d, want 47", state.SelectedIndex)
+	}
+	if state.ScrollOffset != 25 {
+		t.Fatalf("scroll offset = %d, want 25 (columns 5-9)", state.ScrollOffset)
+	}
+	if state.CanMoveInGrid(1) {
+		t.Fatalf("expected no column right of the last one")
+	}
+
+	if _, err := reducer.Reduce(state, ScrollPageUpAction{}); err != nil {
+		t.Fatal(err)
+	}
+	if state.SelectedIndex != 22 || state.ScrollOffset != 20 {
+		t.Fatalf("page up selected %d at offset %d, want 22 at 20", state.SelectedIndex, state.ScrollOffset)
+	}
+
+	state.ListGridEnabled = false
+	if state.CanMoveInGrid(1) {
+		t.Fatalf("expected ←/→ to keep their meaning in one column")
+	}
+}
+
+func TestToggleListGridAlignsScroll(t *testing.T) {
+	state := gridTestState(48, 40, 8)
+	state.ListGridEnabled = false
+	state.SelectedIndex = 13
+	state.ScrollOffset = 9
+	reducer := NewStateReducer()
+
+	if _, err := reducer.Reduce(state, ToggleListGridAction{}); err != nil {
+		t.Fatal(err)
+	}
+	if !state.ListGridEnabled || state.ScrollOffset != 5 {
+		t.Fatalf("grid on = %v at offset %d, want true at 5", state.ListGridEnabled, state.ScrollOffset)
+	}
+
+	if _, err := reducer.Reduce(state, ListWidthAction{Width: 12}); err != nil {
+		t.Fatal(err)
+	}
+	if grid := state.ListGrid(); grid.Columns != 1 || state.ScrollOffset != 9 {
+		t.Fatalf("narrowed to %+v at offset %d, want one column at 9", grid, state.ScrollOffset)
+	}
+}
@@ -0,0 +1,174 @@
+package state
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	search "github.com/kk-code-lab/rdir/internal/search"
+)
+
+// EnvIdleIndex turns on warming the global search index while rdir sits
+// idle: "on", "after=30s,workers=1,files=200000", a bare duration, or "off".
+const EnvIdleIndex = "RDIR_IDLE_INDEX"
+
+// IdleIndexMaxAge is how long a warmed index stays usable. An older one is
+// warmed again the next time the app goes idle.
+const IdleIndexMaxAge = 10 * time.Minute
+
+// idleIndexAfter is the idle time before warming once RDIR_IDLE_INDEX turns
+// it on without saying how long.
+const idleIndexAfter = 30 * time.Second
+
+// IdleIndexConfig is the RDIR_IDLE_INDEX setting. After is zero when warming
+// is off.
+type IdleIndexConfig struct {
+	After    time.Duration // idle time before warming starts
+	Workers  int           // goroutines walking the tree
+	MaxFiles int           // stop (and leave the index unused) past this many files
+}
+
+// DefaultIdleIndex leaves warming off: walking whatever directory rdir was
+// left in costs disk and battery for a search that may never come. Turned
+// on, it uses one worker, so the walk stays in the background of whatever
+// else the machine is doing.
+var DefaultIdleIndex = IdleIndexConfig{Workers: 1, MaxFiles: 200000}
+
+// Enabled reports whether idle warming is on.
+func (c IdleIndexConfig) Enabled() bool {
+	return c.After > 0
+}
+
+// LoadIdleIndexConfig reads RDIR_IDLE_INDEX: on, off, or comma-separated
+// after=<duration>, workers=<n> and files=<n> (a bare duration sets after).
+// Any setting but off turns warming on, after 30s unless it says otherwise.
+// Invalid entries keep their default and are reported in the returned error.
+func LoadIdleIndexConfig(getenv func(string) string) (IdleIndexConfig, error) {
+	cfg := DefaultIdleIndex
+	raw := strings.TrimSpace(getenv(EnvIdleIndex))
+	switch strings.ToLower(raw) {
+	case "", "off", "0", "false", "no":
+		return IdleIndexConfig{}, nil
+	case "on", "1", "true", "yes":
+		cfg.After = idleIndexAfter
+		return cfg, nil
+	}
+	cfg.After = idleIndexAfter
+	var problems []string
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, found := strings.Cut(item, "=")
+		if !found {
+			key, value = "after", item
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		switch key {
+		case "after":
+			if d, err := time.ParseDuration(value); err == nil && d > 0 {
+				cfg.After = d
+				continue
+			}
+		case "workers", "files":
+			if n, err := strconv.Atoi(value); err == nil && n > 0 {
+				if key == "workers" {
+					cfg.Workers = n
+				} else {
+					cfg.MaxFiles = n
+				}
+				continue
+			}
+		}
+		problems = append(problems, fmt.Sprintf("%q", item))
+	}
+	if len(problems) > 0 {
+		return cfg, fmt.Errorf("ignoring invalid %s entries: %s (use after=<duration>, workers=<n>, files=<n> or off)", EnvIdleIndex, strings.Join(problems, ", "))
+	}
+	return cfg, nil
+}
+
+// warmIndex is a global search index built while the app was idle, waiting
+// for the next search from its root.
+type warmIndex struct {
+	searcher *GlobalSearcher
+	started  time.Time
+}
+
+// sameTree reports whether the index covers what a search would start from.
+func (w *warmIndex) sameTree(s *AppState) bool {
+	gs := w.searcher
+	return gs.RootPath() == s.CurrentPath && gs.HideHidden() == s.HideHiddenFiles &&
+		gs.FoldsDiacritics() == s.FoldDiacritics
+}
+
+func (w *warmIndex) matches(s *AppState, now time.Time) bool {
+	return w.sameTree(s) && now.Sub(w.started) < IdleIndexMaxAge
+}
+
+// startIndexWarm builds the index for the directory global search would start
+// from, within the configured budget. It does nothing in eco mode, on slow
+// paths, during a search, while a warm-up for it is walking or fresh, or for
+// a directory that outgrew the file cap before.
+func (s *AppState) startIndexWarm(now time.Time) {
+	if !s.IdleIndex.Enabled() || s.EcoActive || s.SlowPath || s.GlobalSearchActive || s.CurrentPath == "" {
+		return
+	}
+	if s.CurrentPath == s.warmTooLarge {
+		return
+	}
+	if w := s.warmIndex; w != nil {
+		switch {
+		case w.sameTree(s) && w.searcher.IndexTruncated():
+			s.warmTooLarge = s.CurrentPath
+			s.dropIndexWarm()
+			return
+		case w.matches(s, now), w.sameTree(s) && !w.searcher.IndexComplete():
+			return
+		}
+		s.dropIndexWarm()
+	}
+	searcher := search.NewGlobalSearcherFS(s.FS, s.CurrentPath, s.HideHiddenFiles, nil)
+	searcher.SetFoldDiacritics(s.FoldDiacritics)
+	searcher.SetIndexBudget(s.IdleIndex.Workers, s.IdleIndex.MaxFiles)
+	searcher.WarmIndex()
+	s.warmIndex = &warmIndex{searcher: searcher, started: now}
+}
+
+// cancelIndexWarm stops a warm-up still walking the tree; a finished index
+// is kept for the next search, unless it stopped at the file cap, which
+// also keeps its directory from being warmed again.
+func (s *AppState) cancelIndexWarm() {
+	w := s.warmIndex
+	if w == nil || w.searcher.IndexComplete() {
+		return
+	}
+	if w.searcher.IndexTruncated() {
+		s.warmTooLarge = w.searcher.RootPath()
+	}
+	s.dropIndexWarm()
+}
+
+func (s *AppState) dropIndexWarm() {
+	if s.warmIndex != nil {
+		s.warmIndex.searcher.Close()
+		s.warmIndex = nil
+	}
+}
+
+// takeWarmSearcher hands a complete, fresh warm index for the current
+// directory to global search; anything else is discarded.
+func (s *AppState) takeWarmSearcher(now time.Time) *GlobalSearcher {
+	w := s.warmIndex
+	if w == nil {
+		return nil
+	}
+	if !w.matches(s, now) || !w.searcher.IndexComplete() {
+		s.dropIndexWarm()
+		return nil
+	}
+	s.warmIndex = nil
+	return w.searcher
+}
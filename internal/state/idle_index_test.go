@@ -0,0 +1,154 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadIdleIndexConfig(t *testing.T) {
+	onByDefault := IdleIndexConfig{After: 30 * time.Second, Workers: 1, MaxFiles: 200000}
+	cases := []struct {
+		value   string
+		want    IdleIndexConfig
+		wantErr bool
+	}{
+		{"", IdleIndexConfig{}, false},
+		{"off", IdleIndexConfig{}, false},
+		{"on", onByDefault, false},
+		{"2m", IdleIndexConfig{After: 2 * time.Minute, Workers: 1, MaxFiles: 200000}, false},
+		{"workers=2", IdleIndexConfig{After: 30 * time.Second, Workers: 2, MaxFiles: 200000}, false},
+		{"after=5s, workers=2, files=5000", IdleIndexConfig{After: 5 * time.Second, Workers: 2, MaxFiles: 5000}, false},
+		{"after=soon,workers=0", onByDefault, true},
+	}
+	for _, tc := range cases {
+		got, err := LoadIdleIndexConfig(func(string) string { return tc.value })
+		if got != tc.want || (err != nil) != tc.wantErr {
+			t.Errorf("%q: got %+v, %v; want %+v, error %v", tc.value, got, err, tc.want, tc.wantErr)
+		}
+		if err != nil && !strings.Contains(err.Error(), EnvIdleIndex) {
+			t.Errorf("%q: error %q should name %s", tc.value, err, EnvIdleIndex)
+		}
+	}
+}
+
+func idleIndexState(t *testing.T) (*AppState, string) {
+	t.Helper()
+	root := t.TempDir()
+	for _, name := range []string{"alpha.go", "beta.go"} {
+		if err := os.WriteFile(filepath.Join(root, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	state := &AppState{ScreenHeight: 30, ScreenWidth: 100, IdleIndex: IdleIndexConfig{After: time.Second, Workers: 1, MaxFiles: 200000}}
+	if err := LoadDirectory(state, root); err != nil {
+		t.Fatalf("LoadDirectory: %v", err)
+	}
+	return state, root
+}
+
+func waitForWarmIndex(t *testing.T, state *AppState) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !state.warmIndex.searcher.IndexComplete() {
+		if time.Now().After(deadline) {
+			t.Fatal("warm index did not complete")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestGlobalSearchAdoptsWarmIndex(t *testing.T) {
+	state, root := idleIndexState(t)
+	reducer := NewStateReducer()
+
+	if _, err := reducer.Reduce(state, IndexWarmStartAction{At: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if state.warmIndex == nil || state.warmIndex.searcher.RootPath() != root {
+		t.Fatalf("warm index = %+v, want one for %s", state.warmIndex, root)
+	}
+	warmed := state.warmIndex.searcher
+	waitForWarmIndex(t, state)
+	// A finished index survives input.
+	if _, err := reducer.Reduce(state, IndexWarmCancelAction{}); err != nil || state.warmIndex == nil {
+		t.Fatalf("cancel dropped a finished index (err %v)", err)
+	}
+
+	if _, err := reducer.Reduce(state, GlobalSearchStartAction{}); err != nil {
+		t.Fatal(err)
+	}
+	if state.GlobalSearcher != warmed || state.warmIndex != nil {
+		t.Fatal("global search did not take over the warm index")
+	}
+	if !state.GlobalSearchIndexStatus.Ready {
+		t.Fatalf("index status = %+v, want ready", state.GlobalSearchIndexStatus)
+	}
+}
+
+func TestIndexWarmSkipsAndDiscards(t *testing.T) {
+	state, _ := idleIndexState(t)
+	reducer := NewStateReducer()
+
+	state.EcoActive = true
+	state.startIndexWarm(time.Now())
+	if state.warmIndex != nil {
+		t.Fatal("eco mode should not warm the index")
+	}
+	state.EcoActive = false
+
+	state.startIndexWarm(time.Now())
+	waitForWarmIndex(t, state)
+	if got := state.takeWarmSearcher(time.Now().Add(IdleIndexMaxAge)); got != nil || state.warmIndex != nil {
+		t.Fatal("an expired warm index should be discarded")
+	}
+
+	state.startIndexWarm(time.Now())
+	waitForWarmIndex(t, state)
+	state.CurrentPath = filepath.Dir(state.CurrentPath)
+	if _, err := reducer.Reduce(state, GlobalSearchStartAction{}); err != nil {
+		t.Fatal(err)
+	}
+	if state.warmIndex != nil || state.GlobalSearcher == nil || state.GlobalSearcher.RootPath() != state.CurrentPath {
+		t.Fatal("a warm index for another directory should be dropped for a fresh search")
+	}
+	state.GlobalSearcher.Close()
+}
+
+func TestIndexWarmStopsOnceTheTreeIsIndexed(t *testing.T) {
+	state, _ := idleIndexState(t)
+
+	state.startIndexWarm(time.Now())
+	waitForWarmIndex(t, state)
+	warmed := state.warmIndex.searcher
+	state.startIndexWarm(time.Now().Add(time.Minute))
+	if state.warmIndex == nil || state.warmIndex.searcher != warmed {
+		t.Fatal("a complete, fresh index should not be warmed again")
+	}
+	state.dropIndexWarm()
+
+	// A tree past the file cap is not walked again after its first try. The
+	// cap never drops below what one search shows.
+	for i := 0; i < 10001; i++ {
+		if err := os.WriteFile(filepath.Join(state.CurrentPath, fmt.Sprintf("f%05d", i)), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	state.IdleIndex.MaxFiles = 1
+	state.startIndexWarm(time.Now())
+	deadline := time.Now().Add(5 * time.Second)
+	for !state.warmIndex.searcher.IndexTruncated() {
+		if time.Now().After(deadline) {
+			t.Fatal("warm index did not stop at the cap")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	state.cancelIndexWarm()
+	state.startIndexWarm(time.Now())
+	if state.warmIndex != nil {
+		t.Fatal("a directory past the file cap should not be warmed again")
+	}
+}
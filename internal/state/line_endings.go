@@ -0,0 +1,63 @@
+package state
+
+import (
+	"fmt"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+)
+
+// lineEndingTarget picks what to normalize to: the dominant terminator of a
+// mixed file, LF for a file that consistently uses CRLF or CR.
+func lineEndingTarget(counts fsutil.LineEndings) (fsutil.LineEnding, bool) {
+	if counts.Total() == 0 {
+		return "", false
+	}
+	target := fsutil.LineEndingLF
+	if counts.Mixed() {
+		target = counts.Dominant()
+	}
+	return target, counts.Count(target) < counts.Total()
+}
+
+// planLineEndingFix inspects the selected file and asks to convert its line
+// endings, replaying NormalizeLineEndingsAction with the file and target.
+func (s *AppState) planLineEndingFix() error {
+	file := s.getCurrentFile()
+	if file == nil || file.IsDir || !file.Mode.IsRegular() {
+		return fmt.Errorf("select a text file to normalize line endings")
+	}
+	if err := s.RequireLocalDisk("normalize line endings"); err != nil {
+		return err
+	}
+	path := s.getCurrentFilePath()
+	sample, err := fsutil.ReadTextSample(path)
+	if err != nil {
+		return err
+	}
+	if !fsutil.IsTextFile(path, sample) {
+		return fmt.Errorf("%s is not a text file", file.Name)
+	}
+	counts, _, err := fsutil.FileLineEndings(path)
+	if err != nil {
+		return err
+	}
+	target, needed := lineEndingTarget(counts)
+	if !needed {
+		if counts.Total() == 0 {
+			return fmt.Errorf("%s has no line breaks", file.Name)
+		}
+		return fmt.Errorf("%s already uses %s line endings", file.Name, counts)
+	}
+	name := textutil.SanitizeTerminalText(file.Name)
+	prompt := fmt.Sprintf("Convert %s from %s to %s line endings (keeps a .bak)?", name, counts, target)
+	for _, stream := range fsutil.AlternateStreams(path) {
+		if stream.Kind != fsutil.StreamAppleDouble {
+			// The rewrite and the backup copy only carry the file's content.
+			prompt = fmt.Sprintf("Convert %s from %s to %s line endings (keeps a .bak, drops its %s)?", name, counts, target, textutil.SanitizeTerminalText(stream.Label()))
+			break
+		}
+	}
+	s.requestConfirm(prompt, NormalizeLineEndingsAction{Path: path, Target: target})
+	return nil
+}
@@ -0,0 +1,57 @@
+package state
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+)
+
+func TestNormalizeLineEndingsAsksBeforeConverting(t *testing.T) {
+	root := t.TempDir()
+	writeFixtureFiles(t, root, map[string]string{
+		"mixed.txt": "a\r\nb\r\nc\n",
+		"unix.txt":  "a\nb\n",
+		"dos.txt":   "a\r\nb\r\n",
+	})
+	state, reducer := loadFixtureState(t, root)
+	selectName := func(name string) {
+		t.Helper()
+		idx := findFileIndexByName(state.Files, name)
+		if idx < 0 {
+			t.Fatalf("missing %s", name)
+		}
+		state.SelectedIndex = idx
+	}
+
+	selectName("mixed.txt")
+	if _, err := reducer.Reduce(state, NormalizeLineEndingsAction{}); err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if state.PendingConfirm == nil || !strings.Contains(state.PendingConfirm.Prompt, "from mixed (crlf 2, lf 1) to crlf line endings") {
+		t.Fatalf("unexpected confirmation %+v", state.PendingConfirm)
+	}
+	want := NormalizeLineEndingsAction{Path: filepath.Join(root, "mixed.txt"), Target: fsutil.LineEndingCRLF}
+	if state.PendingConfirm.Action != want {
+		t.Fatalf("unexpected pending action %+v", state.PendingConfirm.Action)
+	}
+	state.PendingConfirm = nil
+
+	selectName("dos.txt")
+	if _, err := reducer.Reduce(state, NormalizeLineEndingsAction{}); err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if state.PendingConfirm == nil || state.PendingConfirm.Action.(NormalizeLineEndingsAction).Target != fsutil.LineEndingLF {
+		t.Fatalf("expected a CRLF file to be offered LF, got %+v", state.PendingConfirm)
+	}
+	state.PendingConfirm = nil
+
+	selectName("unix.txt")
+	if _, err := reducer.Reduce(state, NormalizeLineEndingsAction{}); err == nil || !strings.Contains(err.Error(), "already uses lf") {
+		t.Fatalf("expected an LF file to need nothing, got %v", err)
+	}
+	if state.PendingConfirm != nil {
+		t.Fatalf("no confirmation expected for an LF file")
+	}
+}
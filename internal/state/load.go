@@ -1,10 +1,12 @@
 package state
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
 	"golang.org/x/text/unicode/norm"
 )
 
@@ -17,7 +19,7 @@ func LoadDirectory(state *AppState, path ...string) error {
 		dirPath = state.CurrentPath
 	}
 
-	entries, err := readDirectoryEntries(dirPath)
+	entries, err := state.readEntries(context.Background(), dirPath)
 	if err != nil {
 		return fmt.Errorf("cannot read directory %s: %w", dirPath, err)
 	}
@@ -26,14 +28,48 @@ func LoadDirectory(state *AppState, path ...string) error {
 	return nil
 }
 
-func readDirectoryEntries(dirPath string) ([]FileEntry, error) {
-	entries, err := os.ReadDir(dirPath)
+// EntryReader lists the entries of dir for the file list and the parent
+// pane. Programs embedding rdir set AppState.ReadEntries to list another
+// backend; previews and file operations still use the local filesystem.
+type EntryReader func(ctx context.Context, dir string) ([]FileEntry, error)
+
+func (s *AppState) readEntries(ctx context.Context, dir string) ([]FileEntry, error) {
+	if s.ReadEntries != nil {
+		return s.ReadEntries(ctx, dir)
+	}
+	return readDirectoryEntries(ctx, s.FS, dir)
+}
+
+// listsLocalDisk reports whether listings come from the local filesystem,
+// which the ancestor prefetch cache and every operation that changes or
+// reads files through the os package assume.
+func (s *AppState) listsLocalDisk() bool {
+	return s.ReadEntries == nil && fsutil.IsLocal(s.FS)
+}
+
+// RequireLocalDisk refuses what (e.g. "paste") when the listing does not
+// come from the local filesystem, where its paths would mean other files.
+func (s *AppState) RequireLocalDisk(what string) error {
+	if s.listsLocalDisk() {
+		return nil
+	}
+	return fmt.Errorf("cannot %s outside the local filesystem", what)
+}
+
+func readDirectoryEntries(ctx context.Context, fsys fsutil.FS, dirPath string) ([]FileEntry, error) {
+	fsys = fsutil.OrLocal(fsys)
+	entries, err := fsys.ReadDir(ctx, dirPath)
 	if err != nil {
 		return nil, err
 	}
 
 	visibleEntries := make([]FileEntry, 0, len(entries))
 	for _, e := range entries {
+		// Info/Stat below may block on slow mounts; bail out between entries.
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		info, err := e.Info()
 		if err != nil {
 			continue
@@ -51,7 +87,7 @@ func readDirectoryEntries(dirPath string) ([]FileEntry, error) {
 
 		// For symlinks, check if target is a directory
 		if isSymlink {
-			targetInfo, err := os.Stat(fullPath)
+			targetInfo, err := fsys.Stat(fullPath)
 			if err == nil {
 				isDir = targetInfo.IsDir()
 			}
@@ -74,7 +110,12 @@ func readDirectoryEntries(dirPath string) ([]FileEntry, error) {
 }
 
 func applyDirectoryEntries(state *AppState, dirPath string, entries []FileEntry) {
+	if dirPath != state.CurrentPath {
+		state.clearReveal()
+	}
 	state.CurrentPath = dirPath
+	state.ClearError(ErrorSourceDirectory)
+	state.updateSlowPath()
 	state.Files = entries
 
 	state.sortFiles()
@@ -84,4 +125,5 @@ func applyDirectoryEntries(state *AppState, dirPath string, entries []FileEntry)
 	state.PreviewData = nil
 	state.resetPreviewScroll()
 	state.clearDirectoryLoadingState()
+	state.prefetchAncestors()
 }
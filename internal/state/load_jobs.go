@@ -0,0 +1,67 @@
+package state
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// directoryLoadTimeout bounds a single directory read (stat-heavy on slow mounts).
+	directoryLoadTimeout = 30 * time.Second
+	// previewLoadTimeout bounds preview generation for the selected entry.
+	previewLoadTimeout = 10 * time.Second
+)
+
+// loadJobs tracks the cancel functions of in-flight loader jobs keyed by the
+// request token so a newer request (or an explicit Cancel) can stop older work.
+type loadJobs struct {
+	mu   sync.Mutex
+	jobs map[int]context.CancelFunc
+}
+
+func newLoadJobs() *loadJobs {
+	return &loadJobs{jobs: make(map[int]context.CancelFunc)}
+}
+
+// begin registers a job and returns its context plus a release func that must
+// be called once the job finishes. A non-positive timeout disables the deadline.
+func (j *loadJobs) begin(token int, timeout time.Duration) (context.Context, func()) {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+
+	j.mu.Lock()
+	j.jobs[token] = cancel
+	j.mu.Unlock()
+
+	release := func() {
+		cancel()
+		j.mu.Lock()
+		delete(j.jobs, token)
+		j.mu.Unlock()
+	}
+	return ctx, release
+}
+
+func (j *loadJobs) cancel(token int) {
+	j.mu.Lock()
+	if cancel, ok := j.jobs[token]; ok {
+		cancel()
+		delete(j.jobs, token)
+	}
+	j.mu.Unlock()
+}
+
+// loadCancelled reports whether ctx was cancelled by a newer request, as
+// opposed to hitting its deadline; cancelled results are dropped silently
+// while deadline errors are surfaced to the user.
+func loadCancelled(ctx context.Context) bool {
+	return ctx.Err() == context.Canceled
+}
@@ -0,0 +1,74 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadJobsCancelStopsContext(t *testing.T) {
+	jobs := newLoadJobs()
+	ctx, release := jobs.begin(1, time.Minute)
+	defer release()
+
+	jobs.cancel(1)
+	<-ctx.Done()
+	if !loadCancelled(ctx) {
+		t.Fatalf("expected cancelled context, got %v", ctx.Err())
+	}
+}
+
+func TestLoadJobsTimeoutIsNotCancellation(t *testing.T) {
+	jobs := newLoadJobs()
+	ctx, release := jobs.begin(1, time.Millisecond)
+	defer release()
+
+	<-ctx.Done()
+	if loadCancelled(ctx) {
+		t.Fatalf("deadline should not be reported as cancellation")
+	}
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", ctx.Err())
+	}
+}
+
+func TestAsyncDirectoryLoaderReportsTimeout(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), nil, 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	loader := NewAsyncDirectoryLoader()
+	results := make(chan DirectoryLoadResult, 1)
+	loader.Start(DirectoryLoadRequest{
+		Token:    1,
+		Path:     dir,
+		Timeout:  time.Nanosecond,
+		Callback: func(res DirectoryLoadResult) { results <- res },
+	})
+
+	select {
+	case res := <-results:
+		if res.Err == nil {
+			t.Fatalf("expected timeout error")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("loader did not report timeout")
+	}
+}
+
+func TestBuildPreviewDataCancelled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(path, []byte("hello"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := buildPreviewData(ctx, nil, path, true); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,339 @@
+package state
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+	"github.com/kk-code-lab/rdir/internal/workers"
+)
+
+const (
+	// markTotalsCacheLimit bounds how many marked paths keep cached totals.
+	markTotalsCacheLimit = 1024
+	// markSummaryTop is how many extensions and largest entries are listed.
+	markSummaryTop = 5
+)
+
+// MarkSummary describes the marked entries. The preview panel shows it
+// instead of the selected entry while two or more entries are marked.
+type MarkSummary struct {
+	Count      int   // marked entries
+	Files      int   // files, including those inside marked directories
+	Dirs       int   // directories, marked ones included
+	Size       int64 // combined size of all files
+	Unreadable int   // entries that could not be read
+	Loading    bool  // totals of some entries are still being counted
+
+	Extensions []MarkExtension // largest combined size first
+	Largest    []MarkItem      // marked entries, largest first
+
+	key string
+}
+
+// MarkExtension totals the files sharing an extension ("" for none).
+type MarkExtension struct {
+	Ext   string
+	Files int
+	Size  int64
+}
+
+// MarkItem is a marked entry with its size (the contents of a directory).
+type MarkItem struct {
+	Path  string
+	IsDir bool
+	Size  int64
+}
+
+// markTotals is what one marked path adds to the summary, as of the mtime
+// of the path itself.
+type markTotals struct {
+	modTime    time.Time
+	isDir      bool
+	size       int64
+	files      int
+	dirs       int
+	unreadable int
+	exts       map[string]MarkExtension
+}
+
+// markTotalsCache remembers the totals of marked paths so marking one more
+// entry only counts that entry. It is shared by the counting goroutines and
+// the reducer.
+type markTotalsCache struct {
+	mu      sync.Mutex
+	entries map[string]markTotals
+}
+
+var markedTotals = &markTotalsCache{}
+
+func (c *markTotalsCache) store(path string, totals markTotals) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]markTotals)
+	}
+	if _, ok := c.entries[path]; !ok && len(c.entries) >= markTotalsCacheLimit {
+		// Evict an arbitrary entry; totals can be counted again.
+		for victim := range c.entries {
+			delete(c.entries, victim)
+			break
+		}
+	}
+	c.entries[path] = totals
+}
+
+// lookup returns the cached totals of path while its mtime in fsys is
+// unchanged.
+func (c *markTotalsCache) lookup(fsys fsutil.FS, path string) (markTotals, bool) {
+	info, err := fsutil.OrLocal(fsys).Lstat(path)
+	if err != nil {
+		return markTotals{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	totals, ok := c.entries[path]
+	if !ok || !totals.modTime.Equal(info.ModTime()) {
+		return markTotals{}, false
+	}
+	return totals, true
+}
+
+// countMarkTotals walks path in fsys (without following symlinks) and
+// totals what it holds. Unreadable entries are counted and skipped.
+// Directories are walked on up to workers.Count(TaskDiskUsage) goroutines.
+func countMarkTotals(ctx context.Context, fsys fsutil.FS, path string) (markTotals, error) {
+	fsys = fsutil.OrLocal(fsys)
+	totals := markTotals{exts: make(map[string]MarkExtension)}
+	info, err := fsys.Lstat(path)
+	if err != nil {
+		totals.unreadable = 1
+		return totals, nil
+	}
+	totals.modTime = info.ModTime()
+	totals.isDir = info.IsDir()
+	if !totals.isDir {
+		totals.addFile(info.Name(), info.Size())
+		return totals, nil
+	}
+	w := &diskUsageWalk{
+		ctx:   ctx,
+		fsys:  fsys,
+		spare: make(chan struct{}, workers.Count(workers.TaskDiskUsage)-1),
+		total: &totals,
+	}
+	w.dir(path)
+	w.wg.Wait()
+	return totals, ctx.Err()
+}
+
+// addFile counts a file of size bytes under its extension.
+func (t *markTotals) addFile(name string, size int64) {
+	ext := strings.ToLower(filepath.Ext(name))
+	if len(ext) == len(name) {
+		// Dotfiles such as .bashrc have no extension.
+		ext = ""
+	}
+	entry := t.exts[ext]
+	entry.Ext = ext
+	entry.Files++
+	entry.Size += size
+	t.exts[ext] = entry
+	t.files++
+	t.size += size
+}
+
+// add merges the counts of other into t.
+func (t *markTotals) add(other *markTotals) {
+	t.files += other.files
+	t.dirs += other.dirs
+	t.size += other.size
+	t.unreadable += other.unreadable
+	for ext, e := range other.exts {
+		merged := t.exts[ext]
+		merged.Ext = ext
+		merged.Files += e.Files
+		merged.Size += e.Size
+		t.exts[ext] = merged
+	}
+}
+
+// diskUsageWalk counts a directory tree. A subdirectory gets its own
+// goroutine while one of the spare slots is free and is walked inline
+// otherwise, so the walk never uses more goroutines than it was given.
+type diskUsageWalk struct {
+	ctx   context.Context
+	fsys  fsutil.FS
+	spare chan struct{}
+	wg    sync.WaitGroup
+
+	mu    sync.Mutex
+	total *markTotals
+}
+
+// dir counts path and what is below it, then adds that to the total.
+func (w *diskUsageWalk) dir(path string) {
+	part := markTotals{exts: make(map[string]MarkExtension)}
+	w.walk(&part, path)
+	w.mu.Lock()
+	w.total.add(&part)
+	w.mu.Unlock()
+}
+
+func (w *diskUsageWalk) walk(t *markTotals, dir string) {
+	t.dirs++
+	entries, err := w.fsys.ReadDir(w.ctx, dir)
+	if w.ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		t.unreadable++
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			child := filepath.Join(dir, entry.Name())
+			select {
+			case w.spare <- struct{}{}:
+				w.wg.Add(1)
+				go func() {
+					defer func() {
+						<-w.spare
+						w.wg.Done()
+					}()
+					w.dir(child)
+				}()
+			default:
+				w.walk(t, child)
+			}
+			if w.ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			t.unreadable++
+			continue
+		}
+		t.addFile(entry.Name(), info.Size())
+	}
+}
+
+// summarizeMarks merges the totals of paths into a summary.
+func summarizeMarks(key string, paths []string, totals map[string]markTotals) MarkSummary {
+	summary := MarkSummary{Count: len(paths), key: key}
+	exts := make(map[string]MarkExtension)
+	for _, path := range paths {
+		t, ok := totals[path]
+		if !ok {
+			summary.Loading = true
+			continue
+		}
+		summary.Files += t.files
+		summary.Dirs += t.dirs
+		summary.Size += t.size
+		summary.Unreadable += t.unreadable
+		for ext, e := range t.exts {
+			merged := exts[ext]
+			merged.Ext = ext
+			merged.Files += e.Files
+			merged.Size += e.Size
+			exts[ext] = merged
+		}
+		if !t.modTime.IsZero() {
+			summary.Largest = append(summary.Largest, MarkItem{Path: path, IsDir: t.isDir, Size: t.size})
+		}
+	}
+	for _, e := range exts {
+		summary.Extensions = append(summary.Extensions, e)
+	}
+	sort.Slice(summary.Extensions, func(i, j int) bool {
+		a, b := summary.Extensions[i], summary.Extensions[j]
+		if a.Size != b.Size {
+			return a.Size > b.Size
+		}
+		return a.Ext < b.Ext
+	})
+	sort.SliceStable(summary.Largest, func(i, j int) bool {
+		return summary.Largest[i].Size > summary.Largest[j].Size
+	})
+	if len(summary.Extensions) > markSummaryTop {
+		summary.Extensions = summary.Extensions[:markSummaryTop]
+	}
+	if len(summary.Largest) > markSummaryTop {
+		summary.Largest = summary.Largest[:markSummaryTop]
+	}
+	return summary
+}
+
+// refreshMarkSummary updates MarkSummary after the marks changed: straight
+// from cached totals when every marked path has them, otherwise counting
+// the rest in the background.
+func (s *AppState) refreshMarkSummary() {
+	if s.markSummaryCancel != nil {
+		s.markSummaryCancel()
+		s.markSummaryCancel = nil
+	}
+	paths := s.MarkedPaths()
+	if len(paths) < 2 {
+		s.MarkSummary = nil
+		return
+	}
+	key := strings.Join(paths, "\x00")
+	if s.MarkSummary != nil && s.MarkSummary.key == key && !s.MarkSummary.Loading {
+		return
+	}
+
+	totals := make(map[string]markTotals, len(paths))
+	var missing []string
+	for _, path := range paths {
+		if t, ok := markedTotals.lookup(s.FS, path); ok {
+			totals[path] = t
+		} else {
+			missing = append(missing, path)
+		}
+	}
+	dispatch := s.getDispatch()
+	if len(missing) == 0 || dispatch == nil {
+		for _, path := range missing {
+			t, _ := countMarkTotals(context.Background(), s.FS, path)
+			markedTotals.store(path, t)
+			totals[path] = t
+		}
+		summary := summarizeMarks(key, paths, totals)
+		s.MarkSummary = &summary
+		return
+	}
+
+	partial := summarizeMarks(key, paths, totals)
+	s.MarkSummary = &partial
+	ctx, cancel := context.WithCancel(context.Background())
+	s.markSummaryCancel = cancel
+	fsys := s.FS
+	go func() {
+		for _, path := range missing {
+			t, err := countMarkTotals(ctx, fsys, path)
+			if err != nil {
+				return
+			}
+			markedTotals.store(path, t)
+			totals[path] = t
+		}
+		dispatch(MarkSummaryAction{Summary: summarizeMarks(key, paths, totals)})
+	}()
+}
+
+// applyMarkSummary takes a summary counted in the background unless the
+// marks changed in the meantime.
+func (s *AppState) applyMarkSummary(summary MarkSummary) {
+	if s.MarkSummary == nil || s.MarkSummary.key != summary.key {
+		return
+	}
+	s.markSummaryCancel = nil
+	s.MarkSummary = &summary
+}
@@ -0,0 +1,134 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kk-code-lab/rdir/internal/workers"
+)
+
+func writeSizedFile(t *testing.T, path string, size int) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Repeat("x", size)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMarkSummaryTotals(t *testing.T) {
+	dir := t.TempDir()
+	writeSizedFile(t, filepath.Join(dir, "src", "main.go"), 300)
+	writeSizedFile(t, filepath.Join(dir, "src", "util.GO"), 200)
+	writeSizedFile(t, filepath.Join(dir, "src", "lib", ".keep"), 1)
+	writeSizedFile(t, filepath.Join(dir, "notes.txt"), 50)
+	writeSizedFile(t, filepath.Join(dir, "big.bin"), 1000)
+
+	state := &AppState{CurrentPath: dir}
+	state.toggleMark(filepath.Join(dir, "src"))
+	state.refreshMarkSummary()
+	if state.MarkSummary != nil {
+		t.Fatalf("expected no summary for a single mark")
+	}
+	state.toggleMark(filepath.Join(dir, "notes.txt"))
+	state.toggleMark(filepath.Join(dir, "big.bin"))
+	state.refreshMarkSummary()
+
+	summary := state.MarkSummary
+	if summary == nil {
+		t.Fatalf("expected a summary for three marks")
+	}
+	if summary.Count != 3 || summary.Files != 5 || summary.Dirs != 2 || summary.Size != 1551 || summary.Loading {
+		t.Fatalf("summary = %+v", summary)
+	}
+	wantExts := []MarkExtension{{".bin", 1, 1000}, {".go", 2, 500}, {".txt", 1, 50}, {"", 1, 1}}
+	if len(summary.Extensions) != len(wantExts) {
+		t.Fatalf("extensions = %+v", summary.Extensions)
+	}
+	for i, want := range wantExts {
+		if summary.Extensions[i] != want {
+			t.Fatalf("extension %d = %+v, want %+v", i, summary.Extensions[i], want)
+		}
+	}
+	if got := summary.Largest; len(got) != 3 || filepath.Base(got[0].Path) != "big.bin" || !got[1].IsDir || got[1].Size != 501 {
+		t.Fatalf("largest = %+v", got)
+	}
+
+	state.clearMarks()
+	if state.MarkSummary != nil {
+		t.Fatalf("expected clearing the marks to drop the summary")
+	}
+}
+
+func TestMarkSummaryCountsInBackground(t *testing.T) {
+	dir := t.TempDir()
+	writeSizedFile(t, filepath.Join(dir, "a", "one.txt"), 10)
+	writeSizedFile(t, filepath.Join(dir, "b", "two.txt"), 20)
+
+	done := make(chan Action, 1)
+	state := &AppState{CurrentPath: dir}
+	state.SetDispatch(func(a Action) { done <- a })
+	state.toggleMark(filepath.Join(dir, "a"))
+	state.toggleMark(filepath.Join(dir, "b"))
+	state.refreshMarkSummary()
+	if state.MarkSummary == nil || !state.MarkSummary.Loading {
+		t.Fatalf("expected a pending summary, got %+v", state.MarkSummary)
+	}
+
+	var action Action
+	select {
+	case action = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("summary was never delivered")
+	}
+	if _, err := NewStateReducer().Reduce(state, action); err != nil {
+		t.Fatal(err)
+	}
+	if state.MarkSummary.Loading || state.MarkSummary.Size != 30 {
+		t.Fatalf("summary = %+v", state.MarkSummary)
+	}
+
+	// Unmarking and marking again reuses the cached totals.
+	state.toggleMark(filepath.Join(dir, "b"))
+	state.refreshMarkSummary()
+	state.toggleMark(filepath.Join(dir, "b"))
+	state.refreshMarkSummary()
+	if state.MarkSummary == nil || state.MarkSummary.Loading || state.MarkSummary.Size != 30 {
+		t.Fatalf("expected the cached summary at once, got %+v", state.MarkSummary)
+	}
+
+	// A summary for marks that changed meanwhile is dropped.
+	stale := *state.MarkSummary
+	state.toggleMark(filepath.Join(dir, "b"))
+	state.refreshMarkSummary()
+	state.applyMarkSummary(stale)
+	if state.MarkSummary != nil {
+		t.Fatalf("expected the stale summary to be ignored")
+	}
+}
+
+func TestMarkTotalsWalkSubdirectoriesInParallel(t *testing.T) {
+	dir := t.TempDir()
+	for i := range 6 {
+		for j := range 3 {
+			writeSizedFile(t, filepath.Join(dir, fmt.Sprintf("d%d", i), fmt.Sprintf("s%d", j), "f.txt"), 10)
+		}
+	}
+	t.Cleanup(func() { workers.SetTaskLimit(workers.TaskDiskUsage, 0) })
+	for _, n := range []int{1, 4} {
+		workers.SetTaskLimit(workers.TaskDiskUsage, n)
+		totals, err := countMarkTotals(context.Background(), nil, dir)
+		if err != nil {
+			t.Fatalf("%d workers: %v", n, err)
+		}
+		if totals.files != 18 || totals.dirs != 25 || totals.size != 180 || totals.exts[".txt"].Files != 18 {
+			t.Fatalf("%d workers: totals = %+v", n, totals)
+		}
+	}
+}
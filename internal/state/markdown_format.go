@@ -48,7 +48,31 @@ func FormatMarkdownPreviewFromData(preview *PreviewData, tableWidth int, maxLine
 	return FormatMarkdownPreview(preview.TextLines, tableWidth, maxLinesPerCell, wrap)
 }
 
+// FormatMarkdownPreviewRows is FormatMarkdownPreviewFromData for a view that
+// shows only the first rows lines: blocks past them are not rendered, so a
+// reflow after a resize costs the visible part of the document. rows <= 0
+// renders everything.
+func FormatMarkdownPreviewRows(preview *PreviewData, tableWidth int, maxLinesPerCell int, wrap bool, rows int) ([][]StyledTextSegment, []TextLineMetadata) {
+	if preview == nil {
+		return nil, nil
+	}
+	var doc markdownDocument
+	if preview.markdownDoc != nil {
+		doc = *preview.markdownDoc
+	} else {
+		doc = parseMarkdown(stripMarkdownFrontmatter(preview.TextLines))
+	}
+	segments := renderMarkdownSegmentsLimit(doc, markdownPreviewOptions(tableWidth, maxLinesPerCell, wrap), rows)
+	return segments, textLineMetadataFromSegments(segments)
+}
+
 func formatMarkdownPreviewWithDoc(doc markdownDocument, tableWidth int, maxLinesPerCell int, wrap bool) ([][]StyledTextSegment, []TextLineMetadata) {
+	segments := renderMarkdownSegmentsWithDoc(doc, markdownPreviewOptions(tableWidth, maxLinesPerCell, wrap))
+	meta := textLineMetadataFromSegments(segments)
+	return segments, meta
+}
+
+func markdownPreviewOptions(tableWidth int, maxLinesPerCell int, wrap bool) markdownRenderOptions {
 	opts := defaultMarkdownRenderOptions()
 	if tableWidth > 1 {
 		opts.tableOpts.MaxWidth = tableWidth - 1
@@ -61,9 +85,7 @@ func formatMarkdownPreviewWithDoc(doc markdownDocument, tableWidth int, maxLines
 		opts.tableOpts.MaxLinesPerCell = maxLinesPerCell
 	}
 	opts.tableOpts.Ellipsis = "…"
-	segments := renderMarkdownSegmentsWithDoc(doc, opts)
-	meta := textLineMetadataFromSegments(segments)
-	return segments, meta
+	return opts
 }
 
 func segmentsToTextLines(lines [][]StyledTextSegment) []string {
@@ -7,8 +7,17 @@ import (
 )
 
 func renderMarkdownSegments(doc markdownDocument, opts markdownRenderOptions) [][]StyledTextSegment {
+	return renderMarkdownSegmentsLimit(doc, opts, 0)
+}
+
+// renderMarkdownSegmentsLimit stops after the block that reaches limit lines;
+// limit <= 0 renders the whole document.
+func renderMarkdownSegmentsLimit(doc markdownDocument, opts markdownRenderOptions, limit int) [][]StyledTextSegment {
 	var lines [][]StyledTextSegment
 	for idx, block := range doc.blocks {
+		if limit > 0 && len(lines) >= limit {
+			break
+		}
 		rendered := renderBlockSegments(block, 0, opts)
 		if idx > 0 && len(rendered) > 0 && len(lines) > 0 && len(lines[len(lines)-1]) != 0 {
 			lines = append(lines, nil)
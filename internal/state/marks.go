@@ -0,0 +1,51 @@
+package state
+
+import "sort"
+
+// Marks are keyed by on-disk path so they survive filtering, re-sorting and
+// leaving the directory.
+
+// IsMarked reports whether an entry of the current listing is marked.
+func (s *AppState) IsMarked(file *FileEntry) bool {
+	if file == nil || len(s.marks) == 0 {
+		return false
+	}
+	return s.marks[entryPath(s, file)]
+}
+
+// MarkCount returns how many paths are marked.
+func (s *AppState) MarkCount() int {
+	return len(s.marks)
+}
+
+// MarkedPaths returns the marked paths in sorted order.
+func (s *AppState) MarkedPaths() []string {
+	if len(s.marks) == 0 {
+		return nil
+	}
+	paths := make([]string, 0, len(s.marks))
+	for path := range s.marks {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (s *AppState) toggleMark(path string) {
+	if path == "" {
+		return
+	}
+	if s.marks[path] {
+		delete(s.marks, path)
+		return
+	}
+	if s.marks == nil {
+		s.marks = make(map[string]bool)
+	}
+	s.marks[path] = true
+}
+
+func (s *AppState) clearMarks() {
+	s.marks = nil
+	s.refreshMarkSummary()
+}
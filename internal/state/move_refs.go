@@ -0,0 +1,173 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	search "github.com/kk-code-lab/rdir/internal/search"
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+)
+
+// EnvMoveReferences makes pasting a cut or renaming an entry look for text
+// files that mention the old names first (1, true or yes).
+const EnvMoveReferences = "RDIR_MOVE_REFS"
+
+// MoveReferencesEnabled reports whether the scan before a move or rename was
+// requested.
+func MoveReferencesEnabled(getenv func(string) string) bool {
+	switch strings.ToLower(strings.TrimSpace(getenv(EnvMoveReferences))) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
+// ReferenceScan looks for text that mentions entries about to be moved or
+// renamed, such as imports and links the change may break. It runs in the
+// background like an archive job.
+type ReferenceScan struct {
+	Verb    string   // "move" or "rename", for the question
+	Paths   []string // entries that change name or place
+	DestDir string   // for a paste; entries already there do not move
+	Then    Action   // carries on once nothing mentions them or the user agrees
+}
+
+// ReferenceScanAction shows the scan as the running job; the app runs it.
+type ReferenceScanAction struct {
+	Scan ReferenceScan
+}
+
+// ReferenceScanDoneAction ends a reference scan with its report.
+type ReferenceScanDoneAction struct {
+	Scan   ReferenceScan
+	Report search.ReferenceReport
+}
+
+// PasteReferenceScan returns the scan to run before pasting a cut, or nil
+// when RDIR_MOVE_REFS is off or the staged entries are copied.
+func (s *AppState) PasteReferenceScan() *ReferenceScan {
+	if !s.MoveReferences || s.Staging.Mode != StagingCut {
+		return nil
+	}
+	return &ReferenceScan{
+		Verb:    "move",
+		Paths:   append([]string(nil), s.Staging.Paths...),
+		DestDir: s.CurrentPath,
+		Then:    PasteStagedAction{ReferencesChecked: true},
+	}
+}
+
+// RenameReferenceScan returns the scan to run before rename, or nil when
+// RDIR_MOVE_REFS is off.
+func (s *AppState) RenameReferenceScan(rename RenameEntryAction) *ReferenceScan {
+	if !s.MoveReferences {
+		return nil
+	}
+	rename.ReferencesChecked = true
+	return &ReferenceScan{Verb: "rename", Paths: []string{rename.From}, Then: rename}
+}
+
+// RunReferenceScan scans the project of each entry (its git work tree, or
+// its directory) for text that mentions the entry's name. It stops early
+// when ctx is canceled.
+func RunReferenceScan(ctx context.Context, scan ReferenceScan) ReferenceScanDoneAction {
+	return ReferenceScanDoneAction{Scan: scan, Report: findMoveReferences(ctx, scan, search.DefaultReferenceLimits)}
+}
+
+// FinishReferenceScan ends the scan job. When text mentions the entries,
+// the hits go to the references file and a question names how many files
+// need a look; confirming replays Then. It returns Then to run at once when
+// nothing mentions them, and nil otherwise (also when the scan was
+// canceled, which drops the move).
+func (s *AppState) FinishReferenceScan(done ReferenceScanDoneAction) Action {
+	s.ArchiveJob = nil
+	report := done.Report
+	if report.Canceled {
+		return nil
+	}
+	if len(report.Hits) == 0 {
+		return done.Scan.Then
+	}
+
+	entries := make([]string, 0, len(report.Hits))
+	for _, hit := range report.Hits {
+		entries = append(entries, fmt.Sprintf("%s:%d:%d:%s", hit.Path, hit.Line, hit.Column, hit.Text))
+	}
+	where := ""
+	target := s.ReferencesFile
+	if target == "" {
+		target = DefaultReferencesFile()
+	}
+	if err := WriteQuickfixFile(target, entries); err == nil {
+		where = "; listed in " + target
+	}
+
+	subject := "moved names are"
+	if done.Scan.Verb == "rename" {
+		subject = "the old name is"
+	}
+	if names := referencedNames(report.Hits); len(names) == 1 {
+		subject = names[0] + " is"
+	}
+	files := fmt.Sprintf("%d files", report.Files)
+	if report.Files == 1 {
+		files = "1 file"
+	}
+	if report.Truncated {
+		files = "at least " + files
+	}
+	prompt := fmt.Sprintf("%s mentioned in %s%s; %s anyway?", textutil.SanitizeTerminalText(subject), files, textutil.SanitizeTerminalText(where), done.Scan.Verb)
+	s.requestConfirm(prompt, done.Scan.Then)
+	return nil
+}
+
+// findMoveReferences runs one scan per project root over the entries of
+// scan that change, leaving those entries themselves out.
+func findMoveReferences(ctx context.Context, scan ReferenceScan, limits search.ReferenceLimits) search.ReferenceReport {
+	var roots []string
+	names := make(map[string][]string)
+	for _, src := range scan.Paths {
+		dir := filepath.Dir(src)
+		if scan.DestDir != "" && dir == filepath.Clean(scan.DestDir) {
+			continue
+		}
+		root := search.ReferenceRoot(dir)
+		if _, ok := names[root]; !ok {
+			roots = append(roots, root)
+		}
+		names[root] = append(names[root], filepath.Base(src))
+	}
+
+	var report search.ReferenceReport
+	for i, root := range roots {
+		part := search.FindReferences(ctx, root, names[root], scan.Paths, limits)
+		report.Hits = append(report.Hits, part.Hits...)
+		report.Files += part.Files
+		report.Scanned += part.Scanned
+		report.Truncated = report.Truncated || part.Truncated
+		if part.Canceled {
+			report.Canceled = true
+			break
+		}
+		limits.MaxHits -= len(part.Hits)
+		if limits.MaxHits <= 0 {
+			report.Truncated = report.Truncated || i < len(roots)-1
+			break
+		}
+	}
+	return report
+}
+
+func referencedNames(hits []search.ReferenceHit) []string {
+	var names []string
+	seen := make(map[string]struct{})
+	for _, hit := range hits {
+		if _, ok := seen[hit.Name]; !ok {
+			seen[hit.Name] = struct{}{}
+			names = append(names, hit.Name)
+		}
+	}
+	return names
+}
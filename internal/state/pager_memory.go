@@ -0,0 +1,176 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+)
+
+// EnvPagerStateFile overrides where per-file pager state is remembered. Set it
+// to "off" to disable persistence.
+const EnvPagerStateFile = "RDIR_PAGER_STATE_FILE"
+
+// pagerMemoryMaxEntries caps how many files are remembered; the least
+// recently viewed ones are dropped first.
+const pagerMemoryMaxEntries = 200
+
+// PagerFileState is what the pager remembers about one file between sessions.
+type PagerFileState struct {
+	Wrap       bool      `json:"wrap"`
+	Raw        bool      `json:"raw,omitempty"`
+	ANSI       bool      `json:"ansi,omitempty"`
+	Line       int       `json:"line,omitempty"`
+	WrapOffset int       `json:"wrap_offset,omitempty"`
+	Query      string    `json:"query,omitempty"`
+	UsedAt     time.Time `json:"used_at"`
+}
+
+// PagerMemory holds remembered pager state keyed by cleaned file path.
+type PagerMemory struct {
+	path    string
+	entries map[string]PagerFileState
+	dirty   bool
+}
+
+// DefaultPagerStateFile returns the pager state location, or "" when
+// persistence is disabled.
+func DefaultPagerStateFile(getenv func(string) string) string {
+	if path := getenv(EnvPagerStateFile); path != "" {
+		if path == "off" {
+			return ""
+		}
+		return path
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil || dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "rdir", "pager_state.json")
+}
+
+// LoadPagerMemory reads remembered pager state from path. A missing file
+// yields an empty memory; a corrupt one yields an empty memory and an error.
+func LoadPagerMemory(path string) (*PagerMemory, error) {
+	mem := &PagerMemory{path: path, entries: make(map[string]PagerFileState)}
+	if path == "" {
+		return mem, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return mem, nil
+	}
+	if err != nil {
+		return mem, err
+	}
+	if err := json.Unmarshal(data, &mem.entries); err != nil {
+		mem.entries = make(map[string]PagerFileState)
+		return mem, fmt.Errorf("invalid pager state file %s: %w", path, err)
+	}
+	return mem, nil
+}
+
+// Lookup returns the remembered state for file.
+func (m *PagerMemory) Lookup(file string) (PagerFileState, bool) {
+	if m == nil || file == "" {
+		return PagerFileState{}, false
+	}
+	st, ok := m.entries[filepath.Clean(file)]
+	return st, ok
+}
+
+// Remember records st for file, stamping it as most recently used and
+// evicting the oldest entries beyond the cap.
+func (m *PagerMemory) Remember(file string, st PagerFileState) {
+	if m == nil || file == "" {
+		return
+	}
+	if st.Line < 0 {
+		st.Line = 0
+	}
+	if st.WrapOffset < 0 || !st.Wrap {
+		st.WrapOffset = 0
+	}
+	if st.UsedAt.IsZero() {
+		st.UsedAt = time.Now()
+	}
+	m.entries[filepath.Clean(file)] = st
+	m.dirty = true
+	m.evict()
+}
+
+func (m *PagerMemory) evict() {
+	if len(m.entries) <= pagerMemoryMaxEntries {
+		return
+	}
+	keys := make([]string, 0, len(m.entries))
+	for k := range m.entries {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return m.entries[keys[i]].UsedAt.Before(m.entries[keys[j]].UsedAt)
+	})
+	for _, k := range keys[:len(keys)-pagerMemoryMaxEntries] {
+		delete(m.entries, k)
+	}
+}
+
+// merge takes the entries of other that were used more recently than this
+// memory's own.
+func (m *PagerMemory) merge(other *PagerMemory) {
+	for file, st := range other.entries {
+		if cur, ok := m.entries[file]; !ok || st.UsedAt.After(cur.UsedAt) {
+			m.entries[file] = st
+		}
+	}
+	m.evict()
+}
+
+// Save atomically writes the memory back to disk when it changed. Another
+// rdir may have saved since the memory was loaded, so under a lock on
+// path+".lock" the file is read again and, per file, the entry used last
+// is kept.
+func (m *PagerMemory) Save() error {
+	if m == nil || m.path == "" || !m.dirty {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0o700); err != nil {
+		return err
+	}
+	unlock, err := fsutil.LockFile(m.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if disk, err := LoadPagerMemory(m.path); err == nil {
+		m.merge(disk)
+	}
+	data, err := json.Marshal(m.entries)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(m.path), ".pager-state-*.json")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpName)
+		return err
+	}
+	if err := os.Rename(tmpName, m.path); err != nil {
+		return err
+	}
+	m.dirty = false
+	return nil
+}
@@ -0,0 +1,68 @@
+package state
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPagerMemoryRoundTripAndEviction(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "pager_state.json")
+	mem, err := LoadPagerMemory(file)
+	if err != nil {
+		t.Fatalf("load missing file: %v", err)
+	}
+
+	base := time.Now().Add(-time.Hour)
+	for i := 0; i < pagerMemoryMaxEntries+5; i++ {
+		mem.Remember(fmt.Sprintf("/logs/%d.log", i), PagerFileState{Line: i, UsedAt: base.Add(time.Duration(i) * time.Second)})
+	}
+	mem.Remember("/logs/app.log", PagerFileState{Wrap: true, Raw: true, Line: 42, WrapOffset: 2, Query: "ERROR"})
+	if err := mem.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	loaded, err := LoadPagerMemory(file)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if len(loaded.entries) != pagerMemoryMaxEntries {
+		t.Fatalf("expected %d entries after eviction, got %d", pagerMemoryMaxEntries, len(loaded.entries))
+	}
+	if _, ok := loaded.Lookup("/logs/0.log"); ok {
+		t.Fatalf("expected the least recently used entry to be evicted")
+	}
+	st, ok := loaded.Lookup("/logs/./app.log")
+	if !ok || !st.Wrap || !st.Raw || st.Line != 42 || st.WrapOffset != 2 || st.Query != "ERROR" {
+		t.Fatalf("unexpected restored state: %+v (found=%v)", st, ok)
+	}
+}
+
+func TestPagerMemorySaveMergesConcurrentSessions(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "pager_state.json")
+	first, _ := LoadPagerMemory(file)
+	second, _ := LoadPagerMemory(file)
+	now := time.Now()
+
+	first.Remember("/a.log", PagerFileState{Line: 1, UsedAt: now.Add(-time.Minute)})
+	first.Remember("/shared.log", PagerFileState{Line: 10, UsedAt: now})
+	if err := first.Save(); err != nil {
+		t.Fatalf("save first: %v", err)
+	}
+	second.Remember("/b.log", PagerFileState{Line: 2, UsedAt: now})
+	second.Remember("/shared.log", PagerFileState{Line: 20, UsedAt: now.Add(-time.Minute)})
+	if err := second.Save(); err != nil {
+		t.Fatalf("save second: %v", err)
+	}
+
+	loaded, err := LoadPagerMemory(file)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	for path, line := range map[string]int{"/a.log": 1, "/b.log": 2, "/shared.log": 10} {
+		if st, ok := loaded.Lookup(path); !ok || st.Line != line {
+			t.Errorf("%s: got %+v (found=%v), want line %d", path, st, ok, line)
+		}
+	}
+}
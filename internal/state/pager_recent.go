@@ -0,0 +1,50 @@
+package state
+
+import (
+	"context"
+	"path/filepath"
+)
+
+// pagerRecentMax caps the pager's list of recently viewed files, one per
+// digit key.
+const pagerRecentMax = 9
+
+// NotePagerFile moves path to the front of the files viewed in the pager.
+func (s *AppState) NotePagerFile(path string) {
+	if path == "" {
+		return
+	}
+	path = filepath.Clean(path)
+	recent := make([]string, 0, pagerRecentMax)
+	recent = append(recent, path)
+	for _, p := range s.PagerRecent {
+		if p != path && len(recent) < pagerRecentMax {
+			recent = append(recent, p)
+		}
+	}
+	s.PagerRecent = recent
+}
+
+// ShowPagerFile replaces the preview with one of path for the fullscreen
+// pager, leaving the browser's directory and selection alone. The scroll
+// position starts at the top so the pager can restore the one it
+// remembers for the file.
+func (r *StateReducer) ShowPagerFile(state *AppState, path string) error {
+	if state.PreviewLoading {
+		r.cancelPreviewLoad(state)
+	}
+	state.cancelPreviewDebounceTimer()
+	state.clearPreviewPendingLoad()
+
+	preview, info, err := buildPreviewData(context.Background(), state.FS, path, state.HideHiddenFiles)
+	if err != nil {
+		return err
+	}
+	state.clearPreviewLoadingState()
+	r.applyPreviewToState(state, preview, info, true, path)
+	state.PreviewScrollOffset = 0
+	state.PreviewWrapOffset = 0
+	state.PreviewBinaryByteOffset = 0
+	state.PreviewFullScreen = true
+	return nil
+}
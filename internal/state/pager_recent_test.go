@@ -0,0 +1,24 @@
+package state
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestNotePagerFileOrdersAndCaps(t *testing.T) {
+	s := &AppState{}
+	s.NotePagerFile("/a")
+	s.NotePagerFile("/b")
+	s.NotePagerFile("/a/")
+	if want := []string{"/a", "/b"}; !reflect.DeepEqual(s.PagerRecent, want) {
+		t.Fatalf("PagerRecent = %#v, want %#v", s.PagerRecent, want)
+	}
+
+	for i := 0; i < 12; i++ {
+		s.NotePagerFile(fmt.Sprintf("/f%d", i))
+	}
+	if len(s.PagerRecent) != pagerRecentMax || s.PagerRecent[0] != "/f11" || s.PagerRecent[pagerRecentMax-1] != "/f3" {
+		t.Fatalf("unexpected capped list: %#v", s.PagerRecent)
+	}
+}
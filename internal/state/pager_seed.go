@@ -0,0 +1,36 @@
+package state
+
+import "path/filepath"
+
+// PagerSearchSeed asks the next pager session on Path to start with a search
+// for Query, focused on the first hit at or after Line (0-based). It is meant
+// for features that already know where a match is (e.g. a content search),
+// so the pager opens on the matching line instead of the top of the file.
+type PagerSearchSeed struct {
+	Path  string
+	Query string
+	Line  int
+}
+
+// SeedPagerSearch records a search for the next pager opened on path.
+func (s *AppState) SeedPagerSearch(path, query string, line int) {
+	if path == "" || query == "" {
+		s.pagerSearchSeed = nil
+		return
+	}
+	if line < 0 {
+		line = 0
+	}
+	s.pagerSearchSeed = &PagerSearchSeed{Path: filepath.Clean(path), Query: query, Line: line}
+}
+
+// TakePagerSearchSeed returns and clears the seed when it targets path. A
+// seed for another file is dropped, so it never leaks into a later session.
+func (s *AppState) TakePagerSearchSeed(path string) (PagerSearchSeed, bool) {
+	seed := s.pagerSearchSeed
+	s.pagerSearchSeed = nil
+	if seed == nil || seed.Path != filepath.Clean(path) {
+		return PagerSearchSeed{}, false
+	}
+	return *seed, true
+}
@@ -0,0 +1,54 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+)
+
+// EnvPathMap lists host=container path prefixes, comma-separated, offered as
+// an extra form when copying a path (Y), e.g. for bind mounts:
+// "/var/lib/docker/volumes/pg/_data=/var/lib/postgresql/data".
+const EnvPathMap = "RDIR_PATH_MAP"
+
+// LoadPathMappings reads RDIR_PATH_MAP. Malformed entries are skipped and
+// reported.
+func LoadPathMappings(getenv func(string) string) ([]fsutil.PathMapping, error) {
+	raw := strings.TrimSpace(getenv(EnvPathMap))
+	if raw == "" {
+		return nil, nil
+	}
+	var mappings []fsutil.PathMapping
+	var errs []error
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		from, to, ok := strings.Cut(entry, "=")
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if !ok || from == "" || to == "" {
+			errs = append(errs, fmt.Errorf("ignoring invalid %s entry %q (use host=container)", EnvPathMap, entry))
+			continue
+		}
+		mappings = append(mappings, fsutil.PathMapping{From: from, To: to})
+	}
+	return mappings, errors.Join(errs...)
+}
+
+// PathChoice is the footer menu Y opens: the selected path written for each
+// environment, copied by number.
+type PathChoice struct {
+	Forms []fsutil.PathForm
+}
+
+// openPathChoice offers the forms of the selected entry's path.
+func (s *AppState) openPathChoice() error {
+	if s.getCurrentFile() == nil {
+		return fmt.Errorf("nothing selected to copy")
+	}
+	s.PathChoice = &PathChoice{Forms: fsutil.TranslatePath(s.getCurrentFilePath(), s.PathContext)}
+	return nil
+}
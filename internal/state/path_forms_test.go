@@ -0,0 +1,52 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+)
+
+func TestLoadPathMappings(t *testing.T) {
+	getenv := func(string) string {
+		return " /srv/app=/app , broken, =/x, /data = /mnt/data "
+	}
+	mappings, err := LoadPathMappings(getenv)
+	want := []fsutil.PathMapping{{From: "/srv/app", To: "/app"}, {From: "/data", To: "/mnt/data"}}
+	if len(mappings) != len(want) || mappings[0] != want[0] || mappings[1] != want[1] {
+		t.Fatalf("mappings = %+v, want %+v", mappings, want)
+	}
+	if err == nil {
+		t.Fatalf("expected the malformed entries to be reported")
+	}
+
+	if mappings, err := LoadPathMappings(func(string) string { return "" }); mappings != nil || err != nil {
+		t.Fatalf("expected no mappings by default, got %+v, %v", mappings, err)
+	}
+}
+
+func TestYankPathAsOpensPathChoice(t *testing.T) {
+	dir := t.TempDir()
+	state := &AppState{
+		CurrentPath: dir,
+		Files:       []FileEntry{{Name: "main.go"}},
+		PathContext: fsutil.PathContext{Mappings: []fsutil.PathMapping{{From: dir, To: "/workspace"}}},
+	}
+	reducer := NewStateReducer()
+	if _, err := reducer.Reduce(state, YankPathAsAction{}); err != nil {
+		t.Fatalf("YankPathAsAction: %v", err)
+	}
+	if state.PathChoice == nil || len(state.PathChoice.Forms) != 2 {
+		t.Fatalf("expected two forms, got %+v", state.PathChoice)
+	}
+	if got := state.PathChoice.Forms[0].Path; got != filepath.Join(dir, "main.go") {
+		t.Fatalf("first form = %q", got)
+	}
+	if got := state.PathChoice.Forms[1].Path; got != "/workspace/main.go" {
+		t.Fatalf("mapped form = %q", got)
+	}
+
+	if _, err := reducer.Reduce(state, PathChoiceCancelAction{}); err != nil || state.PathChoice != nil {
+		t.Fatalf("expected cancel to close the menu, got %+v, %v", state.PathChoice, err)
+	}
+}
@@ -1,6 +1,8 @@
 package state
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"sort"
@@ -9,8 +11,12 @@ import (
 	"golang.org/x/text/unicode/norm"
 )
 
-func buildPreviewData(filePath string, hideHidden bool) (*PreviewData, os.FileInfo, error) {
-	info, err := os.Stat(filePath)
+func buildPreviewData(ctx context.Context, fsys fsutil.FS, filePath string, hideHidden bool) (*PreviewData, os.FileInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	fsys = fsutil.OrLocal(fsys)
+	info, err := fsys.Stat(filePath)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -25,21 +31,44 @@ func buildPreviewData(filePath string, hideHidden bool) (*PreviewData, os.FileIn
 	}
 
 	if info.IsDir() {
-		loadDirectoryPreview(preview, filePath, hideHidden)
+		if total, hidden, ok := loadDirectoryPreview(ctx, fsys, preview, filePath, hideHidden); ok {
+			// The listing takes entry mtimes from Lstat, so the count is
+			// keyed on the same call. A symlink's own mtime does not change
+			// with its target's entries, so counts behind links are not
+			// kept.
+			if linfo, err := fsys.Lstat(filePath); err == nil && linfo.Mode()&os.ModeSymlink == 0 {
+				dirEntryCounts.store(filePath, linfo.ModTime(), total, hidden)
+			}
+		}
 	} else {
-		loadFilePreview(preview, filePath, info)
+		loadFilePreview(ctx, fsys, preview, filePath, info)
+		if fsutil.IsLocal(fsys) {
+			preview.AltStreams = fsutil.AlternateStreams(filePath)
+			if sc, ok := fsutil.ReadShortcut(filePath); ok {
+				preview.Shortcut = &sc
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
 	}
 
 	return preview, info, nil
 }
 
-func loadDirectoryPreview(preview *PreviewData, filePath string, hideHidden bool) {
-	entries, err := os.ReadDir(filePath)
+// loadDirectoryPreview fills preview.DirEntries and reports the total and
+// hidden entry counts; ok is false when the listing could not be completed.
+func loadDirectoryPreview(ctx context.Context, fsys fsutil.FS, preview *PreviewData, filePath string, hideHidden bool) (total, hidden int, ok bool) {
+	entries, err := fsys.ReadDir(ctx, filePath)
 	if err != nil {
-		return
+		return 0, 0, false
 	}
 
 	for _, e := range entries {
+		if ctx.Err() != nil {
+			return 0, 0, false
+		}
+
 		entryInfo, err := e.Info()
 		if err != nil {
 			continue
@@ -48,7 +77,7 @@ func loadDirectoryPreview(preview *PreviewData, filePath string, hideHidden bool
 		isDir := e.IsDir()
 		isSymlink := (entryInfo.Mode() & os.ModeSymlink) != 0
 		if isSymlink {
-			targetInfo, err := os.Stat(filepath.Join(filePath, e.Name()))
+			targetInfo, err := fsys.Stat(filepath.Join(filePath, e.Name()))
 			if err == nil {
 				isDir = targetInfo.IsDir()
 			}
@@ -64,8 +93,12 @@ func loadDirectoryPreview(preview *PreviewData, filePath string, hideHidden bool
 			Mode:      entryInfo.Mode(),
 		}
 
-		if hideHidden && entry.IsHidden() {
-			continue
+		total++
+		if entry.IsHidden() {
+			hidden++
+			if hideHidden {
+				continue
+			}
 		}
 
 		preview.DirEntries = append(preview.DirEntries, entry)
@@ -77,23 +110,35 @@ func loadDirectoryPreview(preview *PreviewData, filePath string, hideHidden bool
 		}
 		return preview.DirEntries[i].Name < preview.DirEntries[j].Name
 	})
+	return total, hidden, true
 }
 
-func loadFilePreview(preview *PreviewData, filePath string, info os.FileInfo) {
-	content, err := fsutil.ReadFileHead(filePath, previewByteLimit)
+// loadFilePreview formats the head of filePath. Decompression and external
+// formatters hand the path to other programs, so they only run on the local
+// filesystem.
+func loadFilePreview(ctx context.Context, fsys fsutil.FS, preview *PreviewData, filePath string, info os.FileInfo) {
+	local := fsutil.IsLocal(fsys)
+	if local && loadCompressedPreview(ctx, preview, filePath, info) {
+		return
+	}
+	content, err := fsutil.ReadFileHeadFS(ctx, fsys, filePath, previewByteLimit)
 	if err != nil {
+		preview.Unreadable = errors.Is(err, os.ErrPermission)
 		return
 	}
 
-	ctx := previewFormatContext{
+	formatCtx := previewFormatContext{
 		path:    filePath,
 		info:    info,
 		content: content,
 	}
 	for _, formatter := range previewFormatters {
-		if formatter.CanHandle(ctx) {
-			formatter.Format(ctx, preview)
+		if formatter.CanHandle(formatCtx) {
+			formatter.Format(formatCtx, preview)
 			break
 		}
 	}
+	if local {
+		applyExternalFormatter(ctx, formatCtx, preview)
+	}
 }
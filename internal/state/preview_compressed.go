@@ -0,0 +1,60 @@
+package state
+
+import (
+	"context"
+	"os"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+)
+
+// decompressedInfo reports the uncompressed size of a compressed file to
+// the formatters, which compare it with the content they were given.
+type decompressedInfo struct {
+	os.FileInfo
+	size int64
+}
+
+func (i decompressedInfo) Size() int64 { return i.size }
+
+// loadCompressedPreview previews a file compressed as a whole (app.log.gz)
+// by its decompressed text. It reports false, leaving the file to the
+// usual preview, when the file is not compressed, cannot be decompressed
+// or does not hold text.
+func loadCompressedPreview(ctx context.Context, preview *PreviewData, filePath string, info os.FileInfo) bool {
+	format, inner, ok := fsutil.CompressedFormat(filePath)
+	if !ok {
+		return false
+	}
+	content, full, err := fsutil.DecompressHead(ctx, filePath, previewByteLimit)
+	if err != nil || !fsutil.IsTextFile(inner, content) {
+		return false
+	}
+
+	size := int64(len(content))
+	if !full {
+		size = fsutil.UncompressedSize(filePath)
+		if size <= int64(len(content)) {
+			size = -1
+		}
+	}
+	preview.Compressed = &fsutil.Compressed{Format: format, Size: size}
+
+	// Formatters pick the view by the inner name (notes.md.gz is markdown)
+	// and see content shorter than the size as truncated.
+	known := size
+	if known < 0 {
+		known = int64(len(content)) + 1
+	}
+	formatCtx := previewFormatContext{
+		path:    inner,
+		info:    decompressedInfo{FileInfo: info, size: known},
+		content: content,
+	}
+	for _, formatter := range previewFormatters {
+		if formatter.CanHandle(formatCtx) {
+			formatter.Format(formatCtx, preview)
+			break
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package state
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kk-code-lab/rdir/internal/testutil"
+)
+
+func TestCompressedPreviewShowsDecompressedText(t *testing.T) {
+	dir := t.TempDir()
+	notes := filepath.Join(dir, "notes.md.gz")
+	testutil.WriteGzip(t, notes, []byte("# Title\n\nbody text\n"))
+
+	preview, _, err := buildPreviewData(context.Background(), nil, notes, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Compressed == nil || preview.Compressed.Format != "gzip" || preview.Compressed.Size != 19 {
+		t.Fatalf("compressed = %+v", preview.Compressed)
+	}
+	if preview.TextTruncated || preview.FormattedKind != "markdown" || preview.TextLines[0] != "# Title" {
+		t.Fatalf("preview = %+v", preview)
+	}
+
+	// Beyond the preview limit the text is truncated and the size comes
+	// from the gzip trailer.
+	log := filepath.Join(dir, "app.log.gz")
+	content := strings.Repeat("2024-01-01 request served\n", int(previewByteLimit)/26+100)
+	testutil.WriteGzip(t, log, []byte(content))
+	preview, _, err = buildPreviewData(context.Background(), nil, log, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !preview.TextTruncated || preview.Compressed == nil || preview.Compressed.Size != int64(len(content)) {
+		t.Fatalf("truncated = %v, compressed = %+v", preview.TextTruncated, preview.Compressed)
+	}
+	if preview.TextBytesRead != previewByteLimit {
+		t.Fatalf("read %d bytes, want %d", preview.TextBytesRead, previewByteLimit)
+	}
+
+	// Content that is not text keeps the usual preview of the file.
+	blob := filepath.Join(dir, "blob.gz")
+	testutil.WriteGzip(t, blob, []byte("\x00\x01\x02binary"))
+	preview, _, err = buildPreviewData(context.Background(), nil, blob, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Compressed != nil || len(preview.BinaryInfo.Lines) == 0 {
+		t.Fatalf("expected a binary preview of the compressed file, got %+v", preview)
+	}
+}
@@ -0,0 +1,126 @@
+package state
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// EnvPreviewExt sets pager view defaults per extension, e.g.
+// "md=wrap,json=raw,log=nowrap" or "md=wrap+formatted".
+const EnvPreviewExt = "RDIR_PREVIEW_EXT"
+
+// PreviewDefaults holds the per-extension wrap and formatted/raw defaults.
+// Toggling a view in the pager updates the entry for that extension, so the
+// choice sticks for the rest of the session.
+type PreviewDefaults struct {
+	ByExt map[string]PreviewDefault // lower-case extension without the dot
+}
+
+// PreviewDefault is one extension's defaults; the Has fields say which of
+// them are configured, the others follow the global setting.
+type PreviewDefault struct {
+	HasWrap bool
+	Wrap    bool
+	HasRaw  bool
+	Raw     bool
+}
+
+// LoadPreviewDefaults reads RDIR_PREVIEW_EXT: comma-separated "ext=options"
+// entries where options are wrap, nowrap, raw or formatted joined by "+".
+// Invalid entries are skipped and reported in the returned error.
+func LoadPreviewDefaults(getenv func(string) string) (PreviewDefaults, error) {
+	var cfg PreviewDefaults
+	var problems []string
+
+	for _, item := range strings.Split(getenv(EnvPreviewExt), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		ext, value, found := strings.Cut(item, "=")
+		ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+		if !found || ext == "" {
+			problems = append(problems, fmt.Sprintf("%q", item))
+			continue
+		}
+		def := cfg.ByExt[ext]
+		valid := true
+		for _, option := range strings.Split(value, "+") {
+			switch strings.ToLower(strings.TrimSpace(option)) {
+			case "wrap":
+				def.HasWrap, def.Wrap = true, true
+			case "nowrap", "no-wrap":
+				def.HasWrap, def.Wrap = true, false
+			case "raw":
+				def.HasRaw, def.Raw = true, true
+			case "formatted", "fmt":
+				def.HasRaw, def.Raw = true, false
+			default:
+				valid = false
+			}
+		}
+		if !valid {
+			problems = append(problems, fmt.Sprintf("%q", item))
+			continue
+		}
+		if cfg.ByExt == nil {
+			cfg.ByExt = make(map[string]PreviewDefault)
+		}
+		cfg.ByExt[ext] = def
+	}
+
+	if len(problems) > 0 {
+		return cfg, fmt.Errorf("ignoring invalid %s entries: %s (use ext=wrap, nowrap, raw or formatted)", EnvPreviewExt, strings.Join(problems, ", "))
+	}
+	return cfg, nil
+}
+
+func previewDefaultsExt(name string) string {
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(name), "."))
+}
+
+// WrapFor returns whether the pager wraps name, or fallback when its
+// extension has no wrap default.
+func (d PreviewDefaults) WrapFor(name string, fallback bool) bool {
+	if def, ok := d.ByExt[previewDefaultsExt(name)]; ok && def.HasWrap {
+		return def.Wrap
+	}
+	return fallback
+}
+
+// RawFor returns whether the pager prefers the raw view for name, or
+// fallback when its extension has no formatted/raw default.
+func (d PreviewDefaults) RawFor(name string, fallback bool) bool {
+	if def, ok := d.ByExt[previewDefaultsExt(name)]; ok && def.HasRaw {
+		return def.Raw
+	}
+	return fallback
+}
+
+// SetWrap records an in-session wrap toggle for name's extension. It reports
+// false when the extension has no wrap default, leaving the global setting
+// to the caller.
+func (d PreviewDefaults) SetWrap(name string, on bool) bool {
+	ext := previewDefaultsExt(name)
+	def, ok := d.ByExt[ext]
+	if !ok || !def.HasWrap {
+		return false
+	}
+	def.Wrap = on
+	d.ByExt[ext] = def
+	return true
+}
+
+// SetRaw records an in-session formatted/raw toggle for name's extension,
+// like SetWrap.
+func (d PreviewDefaults) SetRaw(name string, raw bool) bool {
+	ext := previewDefaultsExt(name)
+	def, ok := d.ByExt[ext]
+	if !ok || !def.HasRaw {
+		return false
+	}
+	def.Raw = raw
+	d.ByExt[ext] = def
+	return true
+}
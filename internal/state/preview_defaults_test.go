@@ -0,0 +1,37 @@
+package state
+
+import "testing"
+
+func TestLoadPreviewDefaults(t *testing.T) {
+	cfg, err := LoadPreviewDefaults(func(key string) string {
+		if key == EnvPreviewExt {
+			return "md=wrap+formatted, .JSON=raw, log=nowrap, txt=sideways, =raw"
+		}
+		return ""
+	})
+	if err == nil {
+		t.Fatalf("expected invalid entries to be reported")
+	}
+	if !cfg.WrapFor("README.md", false) || cfg.RawFor("README.md", true) {
+		t.Fatalf("md defaults not applied: %+v", cfg.ByExt["md"])
+	}
+	if !cfg.RawFor("data.json", false) || !cfg.WrapFor("data.json", true) {
+		t.Fatalf("json should be raw and keep the global wrap: %+v", cfg.ByExt["json"])
+	}
+	if cfg.WrapFor("app.log", true) {
+		t.Fatalf("log should not wrap")
+	}
+	if _, ok := cfg.ByExt["txt"]; ok {
+		t.Fatalf("invalid entry should be skipped")
+	}
+}
+
+func TestPreviewDefaultsSetOnlyTouchesConfiguredExtensions(t *testing.T) {
+	cfg, _ := LoadPreviewDefaults(func(string) string { return "log=nowrap" })
+	if !cfg.SetWrap("b.log", true) || !cfg.WrapFor("a.log", false) {
+		t.Fatalf("toggle should update the log default for the session")
+	}
+	if cfg.SetRaw("b.log", true) || cfg.SetWrap("main.go", true) {
+		t.Fatalf("unconfigured settings should be left to the caller")
+	}
+}
@@ -0,0 +1,216 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kk-code-lab/rdir/internal/procwatch"
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+)
+
+// EnvFormatters routes formatted previews through external tools, e.g.
+// "auto" or "md=glow,diff=delta,*=bat,timeout=3s".
+const EnvFormatters = "RDIR_FORMATTERS"
+
+// DefaultFormatterTimeout bounds a single external formatter run.
+const DefaultFormatterTimeout = 2 * time.Second
+
+// ExternalFormatterConfig maps lowercase extensions (without the dot, "*"
+// for any text file) to a resolved external formatter.
+type ExternalFormatterConfig struct {
+	ByExt   map[string]ExternalFormatter
+	Timeout time.Duration
+}
+
+// ExternalFormatter is one supported tool and the executable it runs.
+type ExternalFormatter struct {
+	Name string // "bat", "glow" or "delta"
+	Path string
+}
+
+// externalFormatterNames lists the supported tools with the executables tried
+// for each (Debian ships bat as batcat).
+var externalFormatterNames = map[string][]string{
+	"bat":   {"bat", "batcat"},
+	"glow":  {"glow"},
+	"delta": {"delta"},
+}
+
+// autoFormatterRoutes is what "auto" enables, for the tools that are installed.
+var autoFormatterRoutes = []struct{ ext, tool string }{
+	{"md", "glow"},
+	{"markdown", "glow"},
+	{"diff", "delta"},
+	{"patch", "delta"},
+	{"*", "bat"},
+}
+
+// externalFormatters is set once at startup, before any preview loads.
+var externalFormatters ExternalFormatterConfig
+
+// SetExternalFormatters installs the configuration used by preview loading.
+func SetExternalFormatters(cfg ExternalFormatterConfig) {
+	externalFormatters = cfg
+}
+
+// LoadExternalFormatters reads RDIR_FORMATTERS: "auto", "off", or
+// comma-separated "ext=tool" routes ("*" matches any text file) plus an
+// optional "timeout=<duration>". Tools are bat, glow and delta; routes to
+// tools that are not installed are reported and skipped.
+func LoadExternalFormatters(getenv func(string) string, lookPath func(string) (string, error)) (ExternalFormatterConfig, error) {
+	cfg := ExternalFormatterConfig{Timeout: DefaultFormatterTimeout}
+	resolved := map[string]string{}
+	resolve := func(tool string) (string, bool) {
+		if path, ok := resolved[tool]; ok {
+			return path, path != ""
+		}
+		resolved[tool] = ""
+		for _, candidate := range externalFormatterNames[tool] {
+			if path, err := lookPath(candidate); err == nil && path != "" {
+				resolved[tool] = path
+				break
+			}
+		}
+		return resolved[tool], resolved[tool] != ""
+	}
+	route := func(ext, tool string, path string) {
+		if cfg.ByExt == nil {
+			cfg.ByExt = make(map[string]ExternalFormatter)
+		}
+		cfg.ByExt[ext] = ExternalFormatter{Name: tool, Path: path}
+	}
+
+	var problems []string
+	for _, item := range strings.Split(getenv(EnvFormatters), ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		switch item {
+		case "", "off", "none":
+			continue
+		case "auto":
+			for _, r := range autoFormatterRoutes {
+				if path, ok := resolve(r.tool); ok {
+					route(r.ext, r.tool, path)
+				}
+			}
+			continue
+		}
+		key, value, found := strings.Cut(item, "=")
+		key, value = strings.TrimPrefix(strings.TrimSpace(key), "."), strings.TrimSpace(value)
+		if !found || key == "" {
+			problems = append(problems, fmt.Sprintf("%q", item))
+			continue
+		}
+		if key == "timeout" {
+			timeout, err := time.ParseDuration(value)
+			if err != nil || timeout <= 0 {
+				problems = append(problems, fmt.Sprintf("%q", item))
+				continue
+			}
+			cfg.Timeout = timeout
+			continue
+		}
+		if _, ok := externalFormatterNames[value]; !ok {
+			problems = append(problems, fmt.Sprintf("%q (unknown tool)", item))
+			continue
+		}
+		path, ok := resolve(value)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%q (%s not found)", item, value))
+			continue
+		}
+		route(key, value, path)
+	}
+
+	if len(problems) > 0 {
+		return cfg, fmt.Errorf("ignoring %s entries: %s (use auto, off, ext=bat|glow|delta or timeout=2s)", EnvFormatters, strings.Join(problems, ", "))
+	}
+	return cfg, nil
+}
+
+// formatterFor picks the tool for path, preferring an exact extension route.
+func (cfg ExternalFormatterConfig) formatterFor(path string) (ExternalFormatter, bool) {
+	if len(cfg.ByExt) == 0 {
+		return ExternalFormatter{}, false
+	}
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	if f, ok := cfg.ByExt[ext]; ok && ext != "" {
+		return f, true
+	}
+	f, ok := cfg.ByExt["*"]
+	return f, ok
+}
+
+// formatterArgs returns the command line for tool; delta reads the file on
+// stdin, the others get its path.
+func formatterArgs(f ExternalFormatter, path string) (args []string, stdin bool) {
+	switch f.Name {
+	case "glow":
+		return []string{f.Path, "--style", "dark", "--width", "100", path}, false
+	case "delta":
+		return []string{f.Path, "--paging=never", "--width=variable"}, true
+	default:
+		return []string{f.Path, "--color=always", "--style=plain", "--paging=never", "--wrap=never", path}, false
+	}
+}
+
+var externalFormatterCommand = exec.CommandContext
+
+// applyExternalFormatter replaces the internal formatted view with the
+// configured tool's output. Anything the tool cannot do in time, or a file
+// the internal formatters only show partially, keeps the internal result.
+func applyExternalFormatter(ctx context.Context, fctx previewFormatContext, preview *PreviewData) {
+	f, ok := externalFormatters.formatterFor(fctx.path)
+	if !ok || preview == nil || preview.IsDir || len(preview.BinaryInfo.Lines) > 0 || len(preview.TextLines) == 0 {
+		return
+	}
+	if preview.TextTruncated || fctx.info == nil || fctx.info.Size() > formattedPreviewMaxBytes {
+		return
+	}
+
+	timeout := externalFormatters.Timeout
+	if timeout <= 0 {
+		timeout = DefaultFormatterTimeout
+	}
+
+	args, stdin := formatterArgs(f, fctx.path)
+	cmd := externalFormatterCommand(ctx, args[0], args[1:]...)
+	if stdin {
+		cmd.Stdin = bytes.NewReader(fctx.content)
+	}
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err := procwatch.RunPolicy(cmd, procwatch.WithTimeout(timeout))
+	var overrun *procwatch.TimeoutError
+	if errors.As(err, &overrun) {
+		preview.FormatterStatus = overrun.Error()
+	}
+	if err != nil || ctx.Err() != nil {
+		return
+	}
+	text := strings.TrimRight(strings.ReplaceAll(out.String(), "\r\n", "\n"), "\n")
+	if text == "" {
+		return
+	}
+
+	raw := strings.Split(text, "\n")
+	colored := make([]string, len(raw))
+	plain := make([]string, len(raw))
+	for i, line := range raw {
+		line = textutil.ExpandTabs(line, textutil.DefaultTabWidth)
+		colored[i] = textutil.SanitizeTerminalTextKeepSGR(line)
+		plain[i] = textutil.StripSGR(colored[i])
+	}
+	preview.FormattedANSILines = colored
+	preview.FormattedTextLines = plain
+	preview.FormattedTextLineMeta = textLineMetadataFromLines(plain)
+	preview.FormattedSegments = nil
+	preview.FormattedSegmentLineMeta = nil
+	preview.FormattedKind = f.Name
+	preview.FormattedUnavailableReason = ""
+}
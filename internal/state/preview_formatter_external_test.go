@@ -0,0 +1,145 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadExternalFormatters(t *testing.T) {
+	installed := map[string]string{"batcat": "/usr/bin/batcat", "glow": "/usr/bin/glow"}
+	lookPath := func(name string) (string, error) {
+		if path, ok := installed[name]; ok {
+			return path, nil
+		}
+		return "", errors.New("not found")
+	}
+	env := func(value string) func(string) string {
+		return func(string) string { return value }
+	}
+
+	cfg, err := LoadExternalFormatters(env(""), lookPath)
+	if err != nil || len(cfg.ByExt) != 0 {
+		t.Fatalf("default should be off, got %+v, %v", cfg, err)
+	}
+
+	cfg, err = LoadExternalFormatters(env("auto"), lookPath)
+	if err != nil {
+		t.Fatalf("auto: %v", err)
+	}
+	if f, _ := cfg.formatterFor("README.md"); f.Name != "glow" {
+		t.Fatalf("expected glow for markdown, got %+v", f)
+	}
+	if f, _ := cfg.formatterFor("main.go"); f.Name != "bat" || f.Path != "/usr/bin/batcat" {
+		t.Fatalf("expected batcat for code, got %+v", f)
+	}
+	if f, _ := cfg.formatterFor("fix.patch"); f.Name != "bat" {
+		t.Fatalf("expected bat when delta is missing, got %+v", f)
+	}
+
+	cfg, err = LoadExternalFormatters(env("go=bat, .diff=delta, timeout=500ms, md=vim"), lookPath)
+	if err == nil || !strings.Contains(err.Error(), "delta not found") || !strings.Contains(err.Error(), "unknown tool") {
+		t.Fatalf("expected problems to be reported, got %v", err)
+	}
+	if cfg.Timeout != 500*time.Millisecond {
+		t.Fatalf("expected the timeout to apply, got %v", cfg.Timeout)
+	}
+	if _, ok := cfg.formatterFor("notes.txt"); ok {
+		t.Fatalf("expected no route for txt")
+	}
+	if f, ok := cfg.formatterFor("main.go"); !ok || f.Name != "bat" {
+		t.Fatalf("expected bat for go, got %+v", f)
+	}
+}
+
+func TestExternalFormatterReplacesFormattedView(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	defer SetExternalFormatters(ExternalFormatterConfig{})
+	SetExternalFormatters(ExternalFormatterConfig{
+		ByExt:   map[string]ExternalFormatter{"go": {Name: "bat", Path: "bat"}},
+		Timeout: 5 * time.Second,
+	})
+	orig := externalFormatterCommand
+	defer func() { externalFormatterCommand = orig }()
+
+	output := "\x1b[38;5;203mpackage\x1b[0m main\x1b]0;title\x07\n"
+	externalFormatterCommand = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		cmd := exec.CommandContext(ctx, os.Args[0], "-test.run=TestFormatterHelperProcess")
+		cmd.Env = append(os.Environ(), "GO_WANT_HELPER_PROCESS=1", "HELPER_OUTPUT="+output)
+		return cmd
+	}
+
+	preview, _, err := buildPreviewData(context.Background(), nil, path, true)
+	if err != nil {
+		t.Fatalf("buildPreviewData: %v", err)
+	}
+	if preview.FormattedKind != "bat" || len(preview.FormattedANSILines) != 1 {
+		t.Fatalf("expected bat output, got kind %q lines %q", preview.FormattedKind, preview.FormattedANSILines)
+	}
+	if got := preview.FormattedANSILines[0]; !strings.HasPrefix(got, "\x1b[38;5;203mpackage") || strings.Contains(got, "\x1b]") {
+		t.Fatalf("expected colors kept and other escapes neutralized, got %q", got)
+	}
+	if got := preview.FormattedTextLines[0]; strings.Contains(got, "\x1b") || !strings.HasPrefix(got, "package main") {
+		t.Fatalf("expected plain formatted text, got %q", got)
+	}
+
+	externalFormatterCommand = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		return exec.CommandContext(ctx, filepath.Join(dir, "missing-tool"))
+	}
+	preview, _, err = buildPreviewData(context.Background(), nil, path, true)
+	if err != nil {
+		t.Fatalf("buildPreviewData: %v", err)
+	}
+	if preview.FormattedKind != "" || len(preview.FormattedANSILines) != 0 {
+		t.Fatalf("expected the internal preview when the tool fails, got %q", preview.FormattedKind)
+	}
+}
+
+func TestExternalFormatterTimeoutIsReported(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	defer SetExternalFormatters(ExternalFormatterConfig{})
+	SetExternalFormatters(ExternalFormatterConfig{
+		ByExt:   map[string]ExternalFormatter{"go": {Name: "bat", Path: "bat"}},
+		Timeout: 50 * time.Millisecond,
+	})
+	orig := externalFormatterCommand
+	defer func() { externalFormatterCommand = orig }()
+	externalFormatterCommand = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		cmd := exec.CommandContext(ctx, os.Args[0], "-test.run=TestFormatterHelperProcess")
+		cmd.Env = append(os.Environ(), "GO_WANT_HELPER_PROCESS=1", "HELPER_SLEEP=10s")
+		return cmd
+	}
+
+	preview, _, err := buildPreviewData(context.Background(), nil, path, true)
+	if err != nil {
+		t.Fatalf("buildPreviewData: %v", err)
+	}
+	if preview.FormattedKind == "bat" || !strings.Contains(preview.FormatterStatus, "did not finish within 50ms") {
+		t.Fatalf("expected the internal preview and a timeout status, got kind %q status %q", preview.FormattedKind, preview.FormatterStatus)
+	}
+}
+
+func TestFormatterHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	if d, err := time.ParseDuration(os.Getenv("HELPER_SLEEP")); err == nil {
+		time.Sleep(d)
+	}
+	fmt.Print(os.Getenv("HELPER_OUTPUT"))
+	os.Exit(0)
+}
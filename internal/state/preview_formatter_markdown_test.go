@@ -620,3 +620,26 @@ func diffLines(want, got []string) string {
 	}
 	return b.String()
 }
+
+func TestFormatMarkdownPreviewRowsRendersOnlyLeadingBlocks(t *testing.T) {
+	var source []string
+	for i := 0; i < 50; i++ {
+		source = append(source, fmt.Sprintf("## Section %d", i), "", "body", "")
+	}
+	preview := &PreviewData{TextLines: source}
+	full, _ := FormatMarkdownPreview(source, 80, 0, true)
+
+	partial, meta := FormatMarkdownPreviewRows(preview, 80, 0, true, 10)
+	if len(partial) < 10 || len(partial) >= len(full) {
+		t.Fatalf("expected a prefix of at least 10 of %d lines, got %d", len(full), len(partial))
+	}
+	if len(meta) != len(partial) {
+		t.Fatalf("metadata for %d lines, want %d", len(meta), len(partial))
+	}
+	if diff := diffLines(segmentsToTextLines(full[:len(partial)]), segmentsToTextLines(partial)); diff != "" {
+		t.Fatalf("prefix differs from the full layout:\n%s", diff)
+	}
+	if all, _ := FormatMarkdownPreviewRows(preview, 80, 0, true, 0); len(all) != len(full) {
+		t.Fatalf("rows=0 rendered %d lines, want %d", len(all), len(full))
+	}
+}
@@ -23,6 +23,7 @@ func (textPreviewFormatter) Format(ctx previewFormatContext, preview *PreviewDat
 		preview.TextLines = lines
 		preview.TextLineMeta = meta
 		preview.HiddenFormattingDetected = containsFormattingRunes(lines)
+		preview.LineEndings = fsutil.CountLineEndings(ctx.content, encoding)
 		preview.LineCount = len(lines)
 		preview.TextCharCount = charCount
 		preview.TextTruncated = truncated
@@ -40,6 +41,7 @@ func (textPreviewFormatter) Format(ctx previewFormatContext, preview *PreviewDat
 	preview.TextLines = lines
 	preview.TextLineMeta = meta
 	preview.HiddenFormattingDetected = containsFormattingRunes(lines)
+	preview.LineEndings = fsutil.CountLineEndings(ctx.content, encoding)
 	preview.LineCount = len(lines)
 	preview.TextCharCount = charCount
 	preview.TextTruncated = truncated
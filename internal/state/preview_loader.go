@@ -2,8 +2,11 @@ package state
 
 import (
 	"context"
+	"fmt"
 	"os"
-	"sync"
+	"time"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
 )
 
 // PreviewLoader performs preview generation asynchronously.
@@ -12,11 +15,14 @@ type PreviewLoader interface {
 	Cancel(token int)
 }
 
-// PreviewLoadRequest describes the preview to build.
+// PreviewLoadRequest describes the preview to build. Timeout overrides the
+// default deadline when positive.
 type PreviewLoadRequest struct {
 	Token      int
 	Path       string
 	HideHidden bool
+	FS         fsutil.FS // nil is the local filesystem
+	Timeout    time.Duration
 	Callback   func(PreviewLoadResult)
 }
 
@@ -31,14 +37,11 @@ type PreviewLoadResult struct {
 
 // NewAsyncPreviewLoader constructs the default goroutine-based preview loader.
 func NewAsyncPreviewLoader() PreviewLoader {
-	return &asyncPreviewLoader{
-		jobs: make(map[int]context.CancelFunc),
-	}
+	return &asyncPreviewLoader{jobs: newLoadJobs()}
 }
 
 type asyncPreviewLoader struct {
-	mu   sync.Mutex
-	jobs map[int]context.CancelFunc
+	jobs *loadJobs
 }
 
 func (l *asyncPreviewLoader) Start(req PreviewLoadRequest) {
@@ -46,24 +49,21 @@ func (l *asyncPreviewLoader) Start(req PreviewLoadRequest) {
 		return
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	l.mu.Lock()
-	l.jobs[req.Token] = cancel
-	l.mu.Unlock()
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = previewLoadTimeout
+	}
+	ctx, release := l.jobs.begin(req.Token, timeout)
 
 	go func() {
-		defer func() {
-			l.mu.Lock()
-			delete(l.jobs, req.Token)
-			l.mu.Unlock()
-		}()
+		defer release()
 
-		data, info, err := buildPreviewData(req.Path, req.HideHidden)
-
-		select {
-		case <-ctx.Done():
+		data, info, err := buildPreviewData(ctx, req.FS, req.Path, req.HideHidden)
+		if loadCancelled(ctx) {
 			return
-		default:
+		}
+		if err == context.DeadlineExceeded {
+			err = fmt.Errorf("preview of %s timed out after %s", req.Path, timeout)
 		}
 
 		req.Callback(PreviewLoadResult{
@@ -77,10 +77,5 @@ func (l *asyncPreviewLoader) Start(req PreviewLoadRequest) {
 }
 
 func (l *asyncPreviewLoader) Cancel(token int) {
-	l.mu.Lock()
-	if cancel, ok := l.jobs[token]; ok {
-		cancel()
-		delete(l.jobs, token)
-	}
-	l.mu.Unlock()
+	l.jobs.cancel(token)
 }
@@ -0,0 +1,103 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+	"golang.org/x/text/unicode/norm"
+)
+
+// EnvPrivilegedHelper names a command that prints a file given as its last
+// argument with more rights than rdir has, e.g. "sudo cat" or "doas cat".
+// Unset or "off" leaves unreadable files unreadable.
+const EnvPrivilegedHelper = "RDIR_PRIVILEGED_HELPER"
+
+// PrivilegedHelperCommand splits the configured helper into its arguments,
+// or returns nil when none is set.
+func PrivilegedHelperCommand(getenv func(string) string) []string {
+	value := strings.TrimSpace(getenv(EnvPrivilegedHelper))
+	if value == "" || strings.EqualFold(value, "off") {
+		return nil
+	}
+	return strings.Fields(value)
+}
+
+// privilegedReadTarget returns the selected file when it is a regular file
+// rdir cannot open.
+func (s *AppState) privilegedReadTarget() (string, error) {
+	file := s.getCurrentFile()
+	if file == nil || file.IsDir {
+		return "", errors.New("select a file to read with the privileged helper")
+	}
+	path := s.getCurrentFilePath()
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%s is not a regular file", file.Name)
+	}
+	f, err := os.Open(path)
+	if err == nil {
+		_ = f.Close()
+		return "", fmt.Errorf("%s is readable; open it with → or P", file.Name)
+	}
+	if !errors.Is(err, os.ErrPermission) {
+		return "", err
+	}
+	return path, nil
+}
+
+// confirmPrivilegedRead (S) asks before reading the selected unreadable file
+// through the privileged helper; confirming hands the read to the app.
+func (s *AppState) confirmPrivilegedRead() error {
+	if len(s.PrivilegedHelper) == 0 {
+		return fmt.Errorf("set %s (e.g. \"sudo cat\") to read files you cannot open", EnvPrivilegedHelper)
+	}
+	if err := s.RequireLocalDisk("read files as another user"); err != nil {
+		return err
+	}
+	path, err := s.privilegedReadTarget()
+	if err != nil {
+		return err
+	}
+	prompt := fmt.Sprintf("Read %s as another user with `%s`?", textutil.SanitizeTerminalText(filepath.Base(path)), textutil.SanitizeTerminalText(strings.Join(s.PrivilegedHelper, " ")))
+	s.requestConfirm(prompt, PrivilegedReadAction{Path: path})
+	return nil
+}
+
+// PrivilegedPreview builds the preview of a file from the head of its
+// content as printed by helper. Reader streams the rest to the pager.
+func PrivilegedPreview(path string, content []byte, reader io.ReaderAt, helper string) (*PreviewData, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	preview := &PreviewData{
+		Name:       norm.NFC.String(info.Name()),
+		Size:       info.Size(),
+		Modified:   info.ModTime(),
+		Mode:       info.Mode(),
+		Privileged: helper,
+		Reader:     reader,
+	}
+	formatCtx := previewFormatContext{path: path, info: info, content: content}
+	for _, formatter := range previewFormatters {
+		if formatter.CanHandle(formatCtx) {
+			formatter.Format(formatCtx, preview)
+			break
+		}
+	}
+	return preview, nil
+}
+
+// PrivilegedPreviewHead is how much of the helper's output the preview is
+// built from, like the preview of a readable file.
+func PrivilegedPreviewHead() int64 {
+	return previewByteLimit
+}
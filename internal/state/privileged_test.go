@@ -0,0 +1,81 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPrivilegedHelperCommand(t *testing.T) {
+	cases := map[string][]string{
+		"":                nil,
+		"off":             nil,
+		" OFF ":           nil,
+		"sudo cat":        {"sudo", "cat"},
+		"  doas  cat -- ": {"doas", "cat", "--"},
+	}
+	for value, want := range cases {
+		got := PrivilegedHelperCommand(func(string) string { return value })
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: got %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestConfirmPrivilegedReadChecksHelperAndTarget(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secret.conf")
+	if err := os.WriteFile(path, []byte("key=value\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	state := &AppState{CurrentPath: dir, Files: []FileEntry{{Name: "secret.conf"}}}
+
+	reducer := NewStateReducer()
+	if _, err := reducer.Reduce(state, PrivilegedReadAction{}); err == nil || !strings.Contains(err.Error(), EnvPrivilegedHelper) {
+		t.Fatalf("expected a hint to configure the helper, got %v", err)
+	}
+
+	state.PrivilegedHelper = []string{"sudo", "cat"}
+	if _, err := reducer.Reduce(state, PrivilegedReadAction{}); err == nil || !strings.Contains(err.Error(), "is readable") {
+		t.Fatalf("expected a readable file to be refused, got %v", err)
+	}
+	if state.PendingConfirm != nil {
+		t.Fatalf("unexpected question %+v", state.PendingConfirm)
+	}
+
+	if os.Geteuid() == 0 {
+		t.Skip("root can open any file")
+	}
+	if err := os.Chmod(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Open(path); !errors.Is(err, os.ErrPermission) {
+		t.Skip("file permissions are not enforced here")
+	}
+	if _, err := reducer.Reduce(state, PrivilegedReadAction{}); err != nil {
+		t.Fatal(err)
+	}
+	if state.PendingConfirm == nil || state.PendingConfirm.Action != (PrivilegedReadAction{Path: path}) {
+		t.Fatalf("expected the read to be confirmed first, got %+v", state.PendingConfirm)
+	}
+	if want := "Read secret.conf as another user with `sudo cat`?"; state.PendingConfirm.Prompt != want {
+		t.Fatalf("prompt = %q, want %q", state.PendingConfirm.Prompt, want)
+	}
+}
+
+func TestPrivilegedPreviewFormatsHelperOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	preview, err := PrivilegedPreview(path, []byte("first\nsecond\n"), nil, "sudo cat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Privileged != "sudo cat" || len(preview.TextLines) != 2 || preview.TextLines[1] != "second" {
+		t.Fatalf("preview = %+v", preview)
+	}
+}
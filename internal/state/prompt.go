@@ -0,0 +1,115 @@
+package state
+
+import (
+	"fmt"
+
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+)
+
+// PromptKind identifies what a footer text prompt is asking for.
+type PromptKind string
+
+const (
+	PromptSelectPattern PromptKind = "select-pattern"
+	PromptReveal        PromptKind = "reveal"
+	PromptExtract       PromptKind = "extract"
+	PromptCompress      PromptKind = "compress"
+	PromptRenamePattern PromptKind = "rename-pattern"
+	PromptConfirmScope  PromptKind = "confirm-scope"
+)
+
+// TextPrompt is a single-line input shown in the footer. Submitting it runs
+// the kind-specific handler; Err keeps the prompt open with a message.
+type TextPrompt struct {
+	Kind       PromptKind
+	Input      string
+	Tail       int // runes of Input after the cursor
+	SelectMode SelectMode
+	Paths      []string // extract: archives to unpack; compress: entries to pack
+	Scope      string   // confirm-scope: the protected path to type
+	Verb       string   // confirm-scope: what Then does to Scope
+	Then       Action   // confirm-scope: runs once Scope is typed
+	Err        string
+}
+
+// Label returns the text shown before the prompt input.
+func (p *TextPrompt) Label() string {
+	switch p.Kind {
+	case PromptSelectPattern:
+		return p.SelectMode.String() + " matching (glob or /regex/):"
+	case PromptReveal:
+		return "reveal entry named:"
+	case PromptExtract:
+		subject := "archive"
+		if len(p.Paths) > 1 {
+			subject = fmt.Sprintf("%d archives", len(p.Paths))
+		}
+		return "extract " + subject + " into:"
+	case PromptCompress:
+		subject := "1 entry"
+		if len(p.Paths) != 1 {
+			subject = fmt.Sprintf("%d entries", len(p.Paths))
+		}
+		return "compress " + subject + " into archive:"
+	case PromptRenamePattern:
+		return "rename pattern:"
+	case PromptConfirmScope:
+		return "type " + textutil.SanitizeTerminalText(p.Scope) + " to " + p.Verb + ":"
+	default:
+		return string(p.Kind) + ":"
+	}
+}
+
+func (s *AppState) openPrompt(kind PromptKind) {
+	s.clearTypeAhead()
+	s.Prompt = &TextPrompt{Kind: kind}
+}
+
+func (r *StateReducer) submitPrompt(state *AppState) (*AppState, error) {
+	prompt := state.Prompt
+	if prompt == nil {
+		return state, nil
+	}
+	switch prompt.Kind {
+	case PromptSelectPattern:
+		if prompt.Input == "" {
+			state.Prompt = nil
+			return state, nil
+		}
+		if _, err := state.applySelectPattern(prompt.Input, prompt.SelectMode); err != nil {
+			prompt.Err = err.Error()
+			return state, nil
+		}
+	case PromptReveal:
+		if prompt.Input == "" {
+			state.Prompt = nil
+			return state, nil
+		}
+		if err := r.revealEntry(state, prompt.Input); err != nil {
+			prompt.Err = err.Error()
+			return state, nil
+		}
+	case PromptExtract:
+		if err := state.submitExtract(prompt); err != nil {
+			prompt.Err = err.Error()
+			return state, nil
+		}
+	case PromptCompress:
+		if err := state.submitCompress(prompt); err != nil {
+			prompt.Err = err.Error()
+			return state, nil
+		}
+	case PromptRenamePattern:
+		if err := state.submitRenamePattern(prompt); err != nil {
+			prompt.Err = err.Error()
+			return state, nil
+		}
+	case PromptConfirmScope:
+		if err := state.submitScopeConfirm(prompt); err != nil {
+			prompt.Err = err.Error()
+			return state, nil
+		}
+	}
+	state.Prompt = nil
+	return state, nil
+}
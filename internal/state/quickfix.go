@@ -0,0 +1,54 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnvQuickfix says where the pager's quickfix export goes: a file path, or
+// "-" to print the entries to stdout when rdir exits.
+const EnvQuickfix = "RDIR_QUICKFIX"
+
+// QuickfixStdout is the RDIR_QUICKFIX value that prints the export on exit.
+const QuickfixStdout = "-"
+
+// DefaultQuickfixFile returns the quickfix export target: RDIR_QUICKFIX, or
+// rdir/quickfix.txt in the user cache directory. A nil getenv skips
+// RDIR_QUICKFIX.
+func DefaultQuickfixFile(getenv func(string) string) string {
+	if getenv != nil {
+		if path := strings.TrimSpace(getenv(EnvQuickfix)); path != "" {
+			return path
+		}
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil || dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "rdir", "quickfix.txt")
+}
+
+// WriteQuickfixFile replaces the file at path with entries, one per line.
+func WriteQuickfixFile(path string, entries []string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, entry := range entries {
+		b.WriteString(entry)
+		b.WriteByte('\n')
+	}
+	return os.WriteFile(path, []byte(b.String()), 0o600)
+}
+
+// DefaultReferencesFile returns where the text found by a scan before a move
+// or rename is listed: rdir/references.txt in the user cache directory, kept
+// apart from the quickfix export so it never overwrites it.
+func DefaultReferencesFile() string {
+	dir, err := os.UserCacheDir()
+	if err != nil || dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "rdir", "references.txt")
+}
@@ -0,0 +1,31 @@
+package state
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EnvReadingWidth sets the column the pager's reading mode reflows markdown
+// prose to.
+const EnvReadingWidth = "RDIR_READING_WIDTH"
+
+const (
+	// DefaultReadingWidth is a comfortable line length for prose.
+	DefaultReadingWidth = 80
+	minReadingWidth     = 20
+)
+
+// LoadReadingWidth reads RDIR_READING_WIDTH, a column count of at least 20.
+// Invalid values fall back to the default and are reported.
+func LoadReadingWidth(getenv func(string) string) (int, error) {
+	raw := strings.TrimSpace(getenv(EnvReadingWidth))
+	if raw == "" {
+		return DefaultReadingWidth, nil
+	}
+	width, err := strconv.Atoi(raw)
+	if err != nil || width < minReadingWidth {
+		return DefaultReadingWidth, fmt.Errorf("ignoring invalid %s %q (use a column count of at least %d)", EnvReadingWidth, raw, minReadingWidth)
+	}
+	return width, nil
+}
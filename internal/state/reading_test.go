@@ -0,0 +1,22 @@
+package state
+
+import "testing"
+
+func TestLoadReadingWidth(t *testing.T) {
+	cases := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"", DefaultReadingWidth, false},
+		{" 72 ", 72, false},
+		{"10", DefaultReadingWidth, true},
+		{"wide", DefaultReadingWidth, true},
+	}
+	for _, tc := range cases {
+		got, err := LoadReadingWidth(func(string) string { return tc.value })
+		if got != tc.want || (err != nil) != tc.wantErr {
+			t.Errorf("LoadReadingWidth(%q) = %d, %v; want %d, err=%v", tc.value, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package state
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 	"unicode/utf8"
 
 	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+	"github.com/kk-code-lab/rdir/internal/lineedit"
 	searchpkg "github.com/kk-code-lab/rdir/internal/search"
 	textutil "github.com/kk-code-lab/rdir/internal/textutil"
 	unicodeenc "golang.org/x/text/encoding/unicode"
@@ -404,6 +406,7 @@ func (r *StateReducer) changeDirectoryWithStatus(state *AppState, path string) (
 	dispatch := state.getDispatch()
 	if loader == nil || dispatch == nil {
 		if err := LoadDirectory(state, dirPath); err != nil {
+			state.reportDirectoryError(err, dirPath)
 			return false, err
 		}
 		return false, nil
@@ -421,6 +424,8 @@ func (r *StateReducer) changeDirectoryWithStatus(state *AppState, path string) (
 	loader.Start(DirectoryLoadRequest{
 		Token: token,
 		Path:  dirPath,
+		FS:    state.FS,
+		Read:  state.ReadEntries,
 		Callback: func(result DirectoryLoadResult) {
 			dispatch(DirectoryLoadResultAction(result))
 		},
@@ -445,46 +450,6 @@ func updateCaseSensitivityOnAppend(current bool, ch rune) bool {
 	return unicode.IsUpper(ch)
 }
 
-func isSearchWordChar(r rune) bool {
-	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
-}
-
-func previousWordBoundary(runes []rune, pos int) int {
-	if pos <= 0 {
-		return 0
-	}
-	if pos > len(runes) {
-		pos = len(runes)
-	}
-
-	i := pos - 1
-	for i >= 0 && !isSearchWordChar(runes[i]) {
-		i--
-	}
-	for i >= 0 && isSearchWordChar(runes[i]) {
-		i--
-	}
-	return i + 1
-}
-
-func nextWordBoundary(runes []rune, pos int) int {
-	if pos >= len(runes) {
-		return len(runes)
-	}
-	if pos < 0 {
-		pos = 0
-	}
-
-	i := pos
-	for i < len(runes) && !isSearchWordChar(runes[i]) {
-		i++
-	}
-	for i < len(runes) && isSearchWordChar(runes[i]) {
-		i++
-	}
-	return i
-}
-
 func (r *StateReducer) triggerGlobalSearch(state *AppState) {
 	state.GlobalSearchInProgress = true
 
@@ -500,11 +465,12 @@ func (r *StateReducer) triggerGlobalSearch(state *AppState) {
 	}
 
 	searcher := state.GlobalSearcher
-	if searcher == nil || searcher.RootPath() != state.GlobalSearchRootPath || searcher.HideHidden() != state.HideHiddenFiles {
+	if searcher == nil || searcher.RootPath() != state.GlobalSearchRootPath || searcher.HideHidden() != state.HideHiddenFiles || searcher.FoldsDiacritics() != state.FoldDiacritics {
 		if searcher != nil {
-			searcher.CancelOngoingSearch()
+			searcher.Close()
 		}
-		searcher = searchpkg.NewGlobalSearcher(state.GlobalSearchRootPath, state.HideHiddenFiles, progressFn)
+		searcher = searchpkg.NewGlobalSearcherFS(state.FS, state.GlobalSearchRootPath, state.HideHiddenFiles, progressFn)
+		searcher.SetFoldDiacritics(state.FoldDiacritics)
 		state.GlobalSearcher = searcher
 	}
 
@@ -547,7 +513,7 @@ func (r *StateReducer) triggerGlobalSearch(state *AppState) {
 			return
 		}
 
-		state.GlobalSearchResults = resultsCopy
+		state.installGlobalSearchResults(resultsCopy)
 		state.GlobalSearchInProgress = inProgress
 		if phase != SearchStatusIdle {
 			state.GlobalSearchStatus = phase
@@ -579,11 +545,13 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 		// If no selection yet (in filter mode with -1), start at 0
 		if displayIdx < 0 {
 			displayIdx = 0
-		} else if displayIdx >= len(displayFiles)-1 {
-			// Already at last item, nothing to do
-			return state, nil
 		} else {
-			displayIdx++
+			next, moved := wrapIndex(displayIdx, 1, len(displayFiles), state.Wrap.List)
+			if !moved {
+				// Already at last item, nothing to do
+				return state, nil
+			}
+			displayIdx = next
 		}
 
 		state.setDisplaySelectedIndex(displayIdx)
@@ -602,11 +570,12 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 		if displayIdx < 0 {
 			displayIdx = len(displayFiles) - 1
 		} else {
-			if displayIdx == 0 {
+			next, moved := wrapIndex(displayIdx, -1, len(displayFiles), state.Wrap.List)
+			if !moved {
 				// Already at first item
 				return state, nil
 			}
-			displayIdx--
+			displayIdx = next
 		}
 
 		state.setDisplaySelectedIndex(displayIdx)
@@ -667,8 +636,8 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 		// Find which directory we came from
 		currentDirName := filepath.Base(currentPath)
 
-		// Navigate to parent
-		loading, err := r.changeDirectoryWithStatus(state, parent)
+		// Navigate to parent, reusing the prefetched listing when fresh
+		loading, err := r.changeToAncestor(state, parent)
 		if err != nil {
 			return state, err
 		}
@@ -782,6 +751,18 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 
 		return r.completeDirectoryChange(state, loading, post)
 
+	case GoToTargetAction:
+		return r.goToTarget(state)
+
+	case GoToSiblingAction:
+		if a.Direction == "prev" {
+			return r.goToSibling(state, -1)
+		}
+		return r.goToSibling(state, 1)
+
+	case ParentPaneSelectAction:
+		return r.selectParentEntry(state, a.Index)
+
 	case GoToHistoryAction:
 		switch a.Direction {
 		case "back":
@@ -867,7 +848,7 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 		state.clearDirectoryLoadingState()
 
 		if a.Err != nil {
-			state.LastError = a.Err
+			state.reportDirectoryError(a.Err, a.Path)
 			r.dropDirectoryCallbacks(a.Token)
 			return state, nil
 		}
@@ -883,6 +864,13 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 		}
 		return state, nil
 
+	case ParentEntriesLoadedAction:
+		state.applyParentEntries(a)
+		return state, nil
+
+	case PreviewReloadAction:
+		return state, r.generatePreview(state)
+
 	case PreviewLoadStartAction:
 		pendingToken, pendingPath, pendingReset := state.previewPendingLoad()
 		if pendingToken == 0 || pendingToken != a.Token || pendingPath == "" {
@@ -895,7 +883,7 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 		loader := state.PreviewLoader
 		dispatch := state.getDispatch()
 		if loader == nil || dispatch == nil {
-			preview, info, err := buildPreviewData(pendingPath, state.HideHiddenFiles)
+			preview, info, err := buildPreviewData(context.Background(), state.FS, pendingPath, state.HideHiddenFiles)
 			if err != nil {
 				state.PreviewData = nil
 				state.PreviewPath = ""
@@ -917,7 +905,7 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 		// If we have a fresh cache entry, show it immediately after debounce while
 		// the loader refreshes in the background.
 		if entry := state.getCurrentFile(); entry != nil {
-			if info := fileInfoFromEntry(entry); info != nil {
+			if info := fileInfoFromEntry(entry, state.pathProfile().StatSymlinkTargets); info != nil {
 				if cached, ok := state.getCachedFilePreview(pendingPath, info); ok {
 					r.applyPreviewToState(state, cached, info, pendingReset, pendingPath)
 				}
@@ -928,6 +916,7 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 			Token:      pendingToken,
 			Path:       pendingPath,
 			HideHidden: state.HideHiddenFiles,
+			FS:         state.FS,
 			Callback: func(result PreviewLoadResult) {
 				dispatch(PreviewLoadResultAction{
 					Token:   result.Token,
@@ -949,7 +938,7 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 		state.clearPreviewLoadingState()
 
 		if a.Err != nil {
-			state.LastError = a.Err
+			state.reportPreviewError(a.Err, a.Path)
 			state.PreviewData = nil
 			state.PreviewPath = ""
 			state.resetPreviewScroll()
@@ -969,6 +958,7 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 		}
 		state.FilterActive = true
 		state.FilterQuery = ""
+		state.FilterTail = 0
 		state.FilterCaseSensitive = false
 		if wasActive {
 			state.SelectedIndex = -1 // Reset when restarting filter
@@ -986,49 +976,19 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 
 	case FilterCharAction:
 		if state.FilterActive {
-			prevSelectedIndex := state.SelectedIndex
-			prevDisplayIdx := state.getDisplaySelectedIndex()
-			prevTokenCount := countFilterTokens(state.FilterQuery)
-
-			state.FilterQuery += string(a.Char)
-			state.FilterCaseSensitive = updateCaseSensitivityOnAppend(state.FilterCaseSensitive, a.Char)
-			if prevTokenCount == 0 && countFilterTokens(state.FilterQuery) > 0 {
-				prevSelectedIndex = -1
-				prevDisplayIdx = -1
-			}
-			state.recomputeFilter()
-			state.retainSelectionAfterFilterChange(prevSelectedIndex, prevDisplayIdx)
-			state.ScrollOffset = 0
-			state.updateScrollVisibility()
+			state.insertFilterRune(a.Char)
 		}
 		return state, r.generatePreview(state)
 
 	case FilterBackspaceAction:
 		if state.FilterActive && len(state.FilterQuery) > 0 {
-			prevSelectedIndex := state.SelectedIndex
-			prevDisplayIdx := state.getDisplaySelectedIndex()
+			state.editFilter(lineedit.Backspace)
+		}
+		return state, r.generatePreview(state)
 
-			runes := []rune(state.FilterQuery)
-			runes = runes[:len(runes)-1]
-			state.FilterQuery = string(runes)
-			state.FilterCaseSensitive = queryHasUppercase(state.FilterQuery)
-			if state.FilterQuery == "" {
-				// When query becomes empty, stay in filter mode (don't clearFilter)
-				// Show all files like FilterStartAction does
-				state.FilteredIndices = make([]int, len(state.Files))
-				for i := range state.Files {
-					state.FilteredIndices[i] = i
-				}
-				state.FilterMatches = nil
-				state.invalidateDisplayFilesCache()
-				state.retainSelectionAfterFilterChange(prevSelectedIndex, prevDisplayIdx)
-				state.FilterCaseSensitive = false
-			} else {
-				state.recomputeFilter()
-				state.retainSelectionAfterFilterChange(prevSelectedIndex, prevDisplayIdx)
-			}
-			state.ScrollOffset = 0
-			state.updateScrollVisibility()
+	case FilterEditAction:
+		if state.FilterActive {
+			state.editFilter(a.Op)
 		}
 		return state, r.generatePreview(state)
 
@@ -1038,6 +998,7 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 			prevDisplayIdx := state.getDisplaySelectedIndex()
 
 			state.FilterQuery = ""
+			state.FilterTail = 0
 			state.FilterCaseSensitive = false
 			state.FilteredIndices = make([]int, len(state.Files))
 			for i := range state.Files {
@@ -1053,6 +1014,17 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 		}
 		return state, r.generatePreview(state)
 
+	case FilterToggleScoresAction:
+		state.FilterScoreDebug = !state.FilterScoreDebug
+		if state.FilterActive {
+			prevSelectedIndex := state.SelectedIndex
+			prevDisplayIdx := state.getDisplaySelectedIndex()
+			state.recomputeFilter()
+			state.retainSelectionAfterFilterChange(prevSelectedIndex, prevDisplayIdx)
+			state.updateScrollVisibility()
+		}
+		return state, nil
+
 	case FilterClearAction:
 		// Only clear filter if filter is active
 		if state.FilterActive {
@@ -1106,7 +1078,7 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 			return state, nil
 		}
 
-		visibleLines := state.visibleLines()
+		visibleLines := state.listPageSize()
 		if visibleLines <= 0 {
 			return state, nil
 		}
@@ -1130,7 +1102,7 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 			return state, nil
 		}
 
-		visibleLines := state.visibleLines()
+		visibleLines := state.listPageSize()
 		if visibleLines <= 0 {
 			return state, nil
 		}
@@ -1171,6 +1143,15 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 		state.updateScrollVisibility()
 		return state, r.generatePreview(state)
 
+	case GridMoveAction:
+		target, ok := state.gridMoveTarget(a.Columns)
+		if !ok {
+			return state, nil
+		}
+		state.setDisplaySelectedIndex(target)
+		state.updateScrollVisibility()
+		return state, r.generatePreview(state)
+
 	case MouseSelectAction:
 		displayFiles := state.getDisplayFiles()
 		if a.DisplayIndex < 0 || a.DisplayIndex >= len(displayFiles) {
@@ -1228,13 +1209,14 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 		return state, nil
 
 	case PreviewScrollUpAction:
-		if state.PreviewFullScreen && state.PreviewData != nil {
+		// Also used from the main view (J/K, Ctrl+Y/E) to scroll the inline preview.
+		if state.PreviewData != nil {
 			state.scrollPreviewBy(-1)
 		}
 		return state, nil
 
 	case PreviewScrollDownAction:
-		if state.PreviewFullScreen && state.PreviewData != nil {
+		if state.PreviewData != nil {
 			state.scrollPreviewBy(1)
 		}
 		return state, nil
@@ -1315,172 +1297,35 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 		state.GlobalSearchIndexStatus = IndexTelemetry{}
 
 		if state.GlobalSearcher != nil {
-			state.GlobalSearcher.CancelOngoingSearch()
+			state.GlobalSearcher.Close()
 		}
-		state.GlobalSearcher = nil
+		state.GlobalSearcher = state.takeWarmSearcher(time.Now())
 
 		r.triggerGlobalSearch(state)
 		return state, nil
 
 	case GlobalSearchCharAction:
-		if state.GlobalSearchActive {
-			prevResults := state.GlobalSearchResults
-			prevQuery := state.CleanGlobalSearchQuery()
-			state.clearDesiredGlobalSearchSelection()
-			state.clearGlobalSearchPendingIndex()
-			runes := []rune(state.GlobalSearchQuery)
-			cursor := state.GlobalSearchCursorPos
-			if cursor < 0 {
-				cursor = 0
-			}
-			if cursor > len(runes) {
-				cursor = len(runes)
-			}
-
-			var buffer []rune
-			buffer = append(buffer, runes[:cursor]...)
-			buffer = append(buffer, a.Char)
-			buffer = append(buffer, runes[cursor:]...)
-
-			state.setGlobalSearchQuery(string(buffer))
-			state.GlobalSearchCursorPos = cursor + 1
-			state.GlobalSearchCaseSensitive = queryHasUppercase(state.GlobalSearchQuery)
-
-			if state.CleanGlobalSearchQuery() == "" {
-				state.GlobalSearchCaseSensitive = false
-			}
-
-			r.applyLocalSearchPreview(state, prevResults, prevQuery)
-			r.triggerGlobalSearch(state)
-		}
+		r.insertGlobalSearchRune(state, a.Char)
 		return state, nil
 
 	case GlobalSearchBackspaceAction:
-		if state.GlobalSearchActive && len(state.CleanGlobalSearchQuery()) > 0 {
-			prevResults := state.GlobalSearchResults
-			prevQuery := state.CleanGlobalSearchQuery()
-			state.clearDesiredGlobalSearchSelection()
-			state.clearGlobalSearchPendingIndex()
-			runes := []rune(state.GlobalSearchQuery)
-			cursor := state.GlobalSearchCursorPos
-			if cursor < 0 {
-				cursor = 0
-			}
-			if cursor > len(runes) {
-				cursor = len(runes)
-			}
-
-			if len(runes) == 0 || cursor == 0 {
-				return state, nil
-			}
-
-			buffer := append([]rune{}, runes[:cursor-1]...)
-			buffer = append(buffer, runes[cursor:]...)
-
-			state.setGlobalSearchQuery(string(buffer))
-			newCursor := cursor - 1
-			if newCursor < 0 {
-				newCursor = 0
-			}
-			state.GlobalSearchCursorPos = newCursor
-			state.GlobalSearchCaseSensitive = queryHasUppercase(state.GlobalSearchQuery)
-			if state.CleanGlobalSearchQuery() == "" {
-				state.GlobalSearchCaseSensitive = false
-			}
-
-			r.applyLocalSearchPreview(state, prevResults, prevQuery)
-			r.triggerGlobalSearch(state)
-		}
+		r.editGlobalSearch(state, lineedit.Backspace)
 		return state, nil
 
 	case GlobalSearchDeleteAction:
-		if state.GlobalSearchActive && len(state.CleanGlobalSearchQuery()) > 0 {
-			prevResults := state.GlobalSearchResults
-			prevQuery := state.CleanGlobalSearchQuery()
-			state.clearDesiredGlobalSearchSelection()
-			state.clearGlobalSearchPendingIndex()
-			runes := []rune(state.GlobalSearchQuery)
-			cursor := state.GlobalSearchCursorPos
-			if cursor < 0 {
-				cursor = 0
-			}
-			if cursor >= len(runes) {
-				return state, nil
-			}
-
-			buffer := append([]rune{}, runes[:cursor]...)
-			buffer = append(buffer, runes[cursor+1:]...)
-
-			state.setGlobalSearchQuery(string(buffer))
-			state.GlobalSearchCaseSensitive = queryHasUppercase(state.GlobalSearchQuery)
-			if state.CleanGlobalSearchQuery() == "" {
-				state.GlobalSearchCaseSensitive = false
-			}
-
-			r.applyLocalSearchPreview(state, prevResults, prevQuery)
-			r.triggerGlobalSearch(state)
-		}
+		r.editGlobalSearch(state, lineedit.Delete)
 		return state, nil
 
 	case GlobalSearchDeleteWordAction:
-		if state.GlobalSearchActive && len(state.CleanGlobalSearchQuery()) > 0 {
-			state.clearDesiredGlobalSearchSelection()
-			state.clearGlobalSearchPendingIndex()
-			runes := []rune(state.GlobalSearchQuery)
-			cursor := state.GlobalSearchCursorPos
-			if cursor < 0 {
-				cursor = 0
-			}
-			if cursor > len(runes) {
-				cursor = len(runes)
-			}
-			if cursor == 0 {
-				return state, nil
-			}
-
-			start := previousWordBoundary(runes, cursor)
-			if start < 0 {
-				start = 0
-			}
-
-			buffer := append([]rune{}, runes[:start]...)
-			buffer = append(buffer, runes[cursor:]...)
-
-			state.setGlobalSearchQuery(string(buffer))
-			state.GlobalSearchCursorPos = start
-			state.GlobalSearchCaseSensitive = queryHasUppercase(state.GlobalSearchQuery)
-			if state.CleanGlobalSearchQuery() == "" {
-				state.GlobalSearchCaseSensitive = false
-			}
-
-			r.triggerGlobalSearch(state)
-		}
+		r.editGlobalSearch(state, lineedit.DeleteWordBack)
 		return state, nil
 
 	case GlobalSearchMoveCursorAction:
-		if state.GlobalSearchActive {
-			state.clearDesiredGlobalSearchSelection()
-			state.clearGlobalSearchPendingIndex()
-			runes := []rune(state.GlobalSearchQuery)
-			switch a.Direction {
-			case "left":
-				if state.GlobalSearchCursorPos > 0 {
-					state.GlobalSearchCursorPos--
-				}
-			case "right":
-				if state.GlobalSearchCursorPos < len(runes) {
-					state.GlobalSearchCursorPos++
-				}
-			case "word-left":
-				state.GlobalSearchCursorPos = previousWordBoundary(runes, state.GlobalSearchCursorPos)
-			case "word-right":
-				state.GlobalSearchCursorPos = nextWordBoundary(runes, state.GlobalSearchCursorPos)
-			case "home":
-				state.GlobalSearchCursorPos = 0
-			case "end":
-				state.GlobalSearchCursorPos = len(runes)
-			}
-		}
+		r.editGlobalSearch(state, lineedit.Op(a.Direction))
+		return state, nil
+
+	case GlobalSearchEditAction:
+		r.editGlobalSearch(state, a.Op)
 		return state, nil
 
 	case GlobalSearchResetQueryAction:
@@ -1517,8 +1362,9 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 		prevResults := state.GlobalSearchResults
 		prevIndex := state.GlobalSearchIndex
 
-		state.GlobalSearchResults = make([]GlobalSearchResult, len(a.Results))
-		copy(state.GlobalSearchResults, a.Results)
+		results := make([]GlobalSearchResult, len(a.Results))
+		copy(results, a.Results)
+		state.installGlobalSearchResults(results)
 		state.GlobalSearchInProgress = a.InProgress
 		if a.Phase != SearchStatusIdle {
 			state.GlobalSearchStatus = a.Phase
@@ -1532,15 +1378,31 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 		state.applyPendingGlobalSearchIndex()
 		return state, nil
 
+	case GlobalSearchCycleSortAction:
+		if state.GlobalSearchActive {
+			state.clearDesiredGlobalSearchSelection()
+			state.clearGlobalSearchPendingIndex()
+			state.cycleGlobalSearchSort()
+		}
+		return state, nil
+
+	case GlobalSearchStatsAction:
+		state.storeSearchStats(a.Stats)
+		if state.GlobalSearchActive && state.GlobalSearchSort.needsStat() && len(state.GlobalSearchResults) > 0 {
+			state.resortGlobalSearchResults()
+		}
+		return state, nil
+
 	case GlobalSearchNavigateAction:
 		if state.GlobalSearchActive && len(state.GlobalSearchResults) > 0 {
 			state.clearDesiredGlobalSearchSelection()
 			state.clearGlobalSearchPendingIndex()
-			if a.Direction == "up" && state.GlobalSearchIndex > 0 {
-				state.GlobalSearchIndex--
-				state.updateGlobalSearchScroll()
-			} else if a.Direction == "down" && state.GlobalSearchIndex < len(state.GlobalSearchResults)-1 {
-				state.GlobalSearchIndex++
+			delta := 1
+			if a.Direction == "up" {
+				delta = -1
+			}
+			if next, moved := wrapIndex(state.GlobalSearchIndex, delta, len(state.GlobalSearchResults), state.Wrap.Search); moved {
+				state.GlobalSearchIndex = next
 				state.updateGlobalSearchScroll()
 			}
 		}
@@ -1614,6 +1476,7 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 	case GlobalSearchOpenAction:
 		if state.GlobalSearchActive && state.GlobalSearchIndex >= 0 && state.GlobalSearchIndex < len(state.GlobalSearchResults) {
 			result := state.GlobalSearchResults[state.GlobalSearchIndex]
+			query := state.GlobalSearchQuery
 
 			// Save current selection before navigating
 			r.selectionHistory[state.CurrentPath] = state.SelectedIndex
@@ -1640,6 +1503,11 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 
 				// Close global search after navigating
 				state.clearGlobalSearch(false)
+				// Opening the hit in the pager starts on the query's first
+				// mention in the file.
+				if !result.FileEntry.IsDir {
+					state.SeedPagerSearch(result.FilePath, strings.TrimSpace(query), 0)
+				}
 				return r.generatePreview(state)
 			}
 
@@ -1647,6 +1515,38 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 		}
 		return state, nil
 
+	case ToggleListGridAction:
+		state.ListGridEnabled = !state.ListGridEnabled
+		state.updateScrollVisibility()
+		return state, nil
+
+	case ListWidthAction:
+		state.ListWidth = a.Width
+		state.updateScrollVisibility()
+		return state, nil
+
+	case ToggleEcoModeAction:
+		if state.EcoActive {
+			state.Eco = EcoOff
+		} else {
+			state.Eco = EcoOn
+		}
+		state.updateEco()
+		return state, nil
+
+	case PowerSourceAction:
+		state.OnBattery = a.OnBattery
+		state.updateEco()
+		return state, nil
+
+	case FollowSnapshotAction:
+		return r.applyFollowSnapshot(state, a.Snapshot)
+
+	case FollowEndedAction:
+		state.Following = ""
+		state.ReportError(ErrorSourceGeneral, errFollowEnded)
+		return state, nil
+
 	case ToggleHiddenFilesAction:
 		// IMPORTANT: Remember display position BEFORE toggle
 		// This is needed for fuzzy search, which may reorder files
@@ -1665,6 +1565,7 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 		}
 
 		// Toggle and recompute filter
+		state.clearReveal()
 		state.HideHiddenFiles = !state.HideHiddenFiles
 		state.recomputeFilter()
 		state.updateParentEntries()
@@ -1844,6 +1745,372 @@ func (r *StateReducer) Reduce(state *AppState, action Action) (*AppState, error)
 		}
 		return state, nil
 
+	case StageCutAction:
+		file := state.getCurrentFile()
+		if file == nil {
+			return state, nil
+		}
+		state.Staging.toggle(StagingCut, entryPath(state, file))
+		state.updateScrollVisibility()
+		return state, nil
+
+	case StageCopyAction:
+		file := state.getCurrentFile()
+		if file == nil {
+			return state, nil
+		}
+		state.Staging.toggle(StagingCopy, entryPath(state, file))
+		state.updateScrollVisibility()
+		return state, nil
+
+	case ClearStagingAction:
+		state.Staging = StagingArea{}
+		return state, nil
+
+	case StagingSyncAction:
+		state.Staging = a.Area
+		state.updateScrollVisibility()
+		return state, nil
+
+	case StagingPasteResultAction:
+		state.Staging = a.Area
+		if a.Err != nil {
+			state.ReportError(ErrorSourceFiles, a.Err)
+		}
+
+		snapshot := captureRefreshSnapshot(state)
+		loading, err := r.changeDirectoryWithStatus(state, state.CurrentPath)
+		if err != nil {
+			return state, err
+		}
+
+		selectName := a.SelectName
+		post := func(r *StateReducer, state *AppState) error {
+			applyRefreshSnapshot(state, snapshot)
+			if selectName != "" {
+				if idx := findFileIndexByName(state.Files, selectName); idx >= 0 {
+					state.SelectedIndex = idx
+					state.updateScrollVisibility()
+				}
+			}
+			return r.generatePreview(state)
+		}
+
+		return r.completeDirectoryChange(state, loading, post)
+
+	case TypeAheadStartAction:
+		state.clearTypeAhead()
+		state.TypeAheadActive = true
+		return state, nil
+
+	case TypeAheadCharAction:
+		if !state.TypeAheadActive {
+			return state, nil
+		}
+		if state.applyTypeAheadChar(a.Char, a.At) {
+			return state, r.generatePreview(state)
+		}
+		return state, nil
+
+	case TypeAheadExitAction:
+		state.clearTypeAhead()
+		return state, nil
+
+	case ToggleMarkAction:
+		file := state.getCurrentFile()
+		if file == nil {
+			return state, nil
+		}
+		state.toggleMark(entryPath(state, file))
+		state.refreshMarkSummary()
+		displayIdx := state.getDisplaySelectedIndex()
+		if displayIdx < len(state.getDisplayFiles())-1 {
+			state.setDisplaySelectedIndex(displayIdx + 1)
+			state.updateScrollVisibility()
+			return state, r.generatePreview(state)
+		}
+		return state, nil
+
+	case ClearMarksAction:
+		state.clearMarks()
+		return state, nil
+
+	case MarkSummaryAction:
+		state.applyMarkSummary(a.Summary)
+		return state, nil
+
+	case TabNewAction:
+		return r.newTab(state)
+
+	case TabCloseAction:
+		return r.closeTab(state)
+
+	case TabNextAction:
+		return r.cycleTab(state, 1)
+
+	case TabPrevAction:
+		return r.cycleTab(state, -1)
+
+	case OpenMarkedInTabsAction:
+		return r.openMarkedInTabs(state, a.Confirmed)
+
+	case ConfirmAcceptAction:
+		pending := state.PendingConfirm
+		state.PendingConfirm = nil
+		if pending == nil || pending.Action == nil {
+			return state, nil
+		}
+		return r.Reduce(state, pending.Action)
+
+	case ConfirmCancelAction:
+		state.PendingConfirm = nil
+		return state, nil
+
+	case YankPathAsAction:
+		return state, state.openPathChoice()
+
+	case PathChoiceCancelAction:
+		state.PathChoice = nil
+		return state, nil
+
+	case ConflictChoiceAction:
+		state.decideConflict(a.Choice, a.All)
+		return state, nil
+
+	case ConflictBackAction:
+		state.conflictBack()
+		return state, nil
+
+	case ConflictPatternAction:
+		state.editConflictPattern()
+		return state, nil
+
+	case ConflictConfirmAction:
+		resumed := state.FinishConflicts()
+		if resumed == nil {
+			return state, nil
+		}
+		return r.Reduce(state, resumed)
+
+	case ConflictCancelAction:
+		state.Conflicts = nil
+		return state, nil
+
+	case AuditViewOpenAction:
+		state.openAuditView()
+		return state, nil
+
+	case AuditViewScrollAction:
+		delta := a.Delta
+		if a.Page {
+			delta *= state.auditViewRows()
+		}
+		state.scrollAuditView(delta)
+		return state, nil
+
+	case AuditViewCloseAction:
+		state.AuditView = nil
+		return state, nil
+
+	case SelectPatternStartAction:
+		state.openPrompt(PromptSelectPattern)
+		return state, nil
+
+	case NormalizeLineEndingsAction:
+		return state, state.planLineEndingFix()
+
+	case PrivilegedReadAction:
+		return state, state.confirmPrivilegedRead()
+
+	case RevealStartAction:
+		state.openPrompt(PromptReveal)
+		return state, nil
+
+	case ExtractStartAction:
+		return state, state.startExtract()
+
+	case ExtractArchivesAction:
+		names := make([]string, len(a.Targets))
+		for i, target := range a.Targets {
+			names[i] = filepath.Base(target.Archive)
+		}
+		state.ArchiveJob = &ArchiveJob{Verb: "extracting", Names: names}
+		return state, nil
+
+	case CompressStartAction:
+		return state, state.startCompress()
+
+	case CommandErrorAction:
+		state.ReportError(ErrorSourceGeneral, a.Err)
+		return state, nil
+
+	case ErrorRetryAction:
+		retry := state.RetryError()
+		if retry == nil {
+			return state, nil
+		}
+		return r.Reduce(state, retry)
+
+	case ErrorDismissAction:
+		state.dismissError()
+		return state, nil
+
+	case ScopeConfirmStartAction:
+		state.openScopeConfirm(a)
+		return state, nil
+
+	case CompressAction:
+		state.ArchiveJob = &ArchiveJob{Verb: "compressing", Names: []string{filepath.Base(a.Archive)}}
+		return state, nil
+
+	case ArchiveProgressAction:
+		if state.ArchiveJob != nil {
+			state.ArchiveJob.Index = a.Index
+			state.ArchiveJob.Progress = a.Progress
+		}
+		return state, nil
+
+	case ArchiveCancelAction:
+		if state.ArchiveJob != nil {
+			state.ArchiveJob.Canceling = true
+		}
+		return state, nil
+
+	case ArchiveDoneAction:
+		return r.finishArchiveJob(state, a)
+
+	case PasteJobAction:
+		names := make([]string, len(a.Area.Paths))
+		for i, path := range a.Area.Paths {
+			names[i] = filepath.Base(path)
+		}
+		verb := "copying"
+		if a.Area.Mode == StagingCut {
+			verb = "moving"
+		}
+		state.ArchiveJob = &ArchiveJob{Verb: verb, Names: names}
+		return state, nil
+
+	case PasteDoneAction:
+		state.ArchiveJob = nil
+		if a.StreamLoss != nil {
+			state.confirmPasteStreamLoss(*a.StreamLoss, a.Resolutions)
+			return state, nil
+		}
+		return r.Reduce(state, StagingPasteResultAction{
+			Area:       a.Result.Unstage(state.Staging),
+			SelectName: a.Result.SelectName,
+			Err:        a.Result.Err,
+		})
+
+	case ReferenceScanAction:
+		names := make([]string, len(a.Scan.Paths))
+		for i, path := range a.Scan.Paths {
+			names[i] = filepath.Base(path)
+		}
+		state.ArchiveJob = &ArchiveJob{Verb: "checking references to", Names: names}
+		return state, nil
+
+	case ReferenceScanDoneAction:
+		next := state.FinishReferenceScan(a)
+		if next == nil {
+			return state, nil
+		}
+		return r.Reduce(state, next)
+
+	case PromptCharAction:
+		if state.Prompt != nil {
+			state.Prompt.insert(a.Char)
+		}
+		return state, nil
+
+	case PromptBackspaceAction:
+		if state.Prompt != nil {
+			state.Prompt.edit(lineedit.Backspace)
+		}
+		return state, nil
+
+	case PromptEditAction:
+		if state.Prompt != nil {
+			state.Prompt.edit(a.Op)
+		}
+		return state, nil
+
+	case PromptCycleModeAction:
+		if state.Prompt != nil && state.Prompt.Kind == PromptSelectPattern {
+			state.Prompt.SelectMode = state.Prompt.SelectMode.Next()
+		}
+		if state.Prompt != nil && state.Prompt.Kind == PromptCompress {
+			state.Prompt.Input = cycleArchiveFormat(state.Prompt.Input)
+			state.Prompt.Tail = 0
+			state.Prompt.Err = ""
+		}
+		return state, nil
+
+	case PromptSubmitAction:
+		return r.submitPrompt(state)
+
+	case PromptCancelAction:
+		state.Prompt = nil
+		return state, nil
+
+	case IndexWarmStartAction:
+		state.startIndexWarm(a.At)
+		return state, nil
+
+	case IndexWarmCancelAction:
+		state.cancelIndexWarm()
+		return state, nil
+
+	case RenameStartAction:
+		return state, state.startInlineRename()
+
+	case RenameCharAction:
+		if state.Rename != nil {
+			state.Rename.insert(a.Char)
+		}
+		return state, nil
+
+	case RenameEditAction:
+		if state.Rename != nil {
+			state.Rename.edit(a.Op)
+		}
+		return state, nil
+
+	case RenameSubmitAction:
+		state.submitInlineRename()
+		return state, nil
+
+	case RenameCancelAction:
+		state.Rename = nil
+		return state, nil
+
+	case RenameResultAction:
+		selectName := filepath.Base(a.From)
+		if a.Err != nil {
+			state.reportError(ErrorSourceFiles, a.Err, a.From, RenameEntryAction{From: a.From, To: a.To})
+		} else {
+			state.renamePaths(a.From, a.To)
+			selectName = filepath.Base(a.To)
+		}
+
+		snapshot := captureRefreshSnapshot(state)
+		loading, err := r.changeDirectoryWithStatus(state, state.CurrentPath)
+		if err != nil {
+			return state, err
+		}
+
+		post := func(r *StateReducer, state *AppState) error {
+			applyRefreshSnapshot(state, snapshot)
+			if idx := findFileIndexByName(state.Files, selectName); idx >= 0 {
+				state.SelectedIndex = idx
+				state.updateScrollVisibility()
+			}
+			return r.generatePreview(state)
+		}
+
+		return r.completeDirectoryChange(state, loading, post)
+
 	default:
 		return state, fmt.Errorf("unknown action: %T", action)
 	}
@@ -1857,7 +2124,7 @@ func (r *StateReducer) applyLocalSearchPreview(state *AppState, prevResults []Gl
 
 	if state.GlobalSearcher != nil {
 		if cached, ok := state.GlobalSearcher.CachedResults(query, state.GlobalSearchCaseSensitive); ok && len(cached) > 0 {
-			state.GlobalSearchResults = cloneResults(cached)
+			state.installGlobalSearchResults(cloneResults(cached))
 			state.GlobalSearchInProgress = true
 			state.GlobalSearchStatus = SearchStatusIndex
 			state.clampGlobalSearchSelection()
@@ -1877,7 +2144,7 @@ func (r *StateReducer) applyLocalSearchPreview(state *AppState, prevResults []Gl
 	if len(filtered) == 0 {
 		return
 	}
-	state.GlobalSearchResults = filtered
+	state.installGlobalSearchResults(filtered)
 	state.GlobalSearchInProgress = true
 	state.GlobalSearchStatus = SearchStatusIndex
 	state.clampGlobalSearchSelection()
@@ -1949,13 +2216,13 @@ func (r *StateReducer) ensureSelectionVisible(state *AppState) {
 	if state.SelectedIndex < 0 || state.SelectedIndex >= len(state.Files) {
 		return
 	}
-	if !state.Files[state.SelectedIndex].IsHidden() {
+	if !state.concealed(&state.Files[state.SelectedIndex]) {
 		return
 	}
 
 	// Prefer the closest visible file above the current selection
 	for i := state.SelectedIndex - 1; i >= 0; i-- {
-		if !state.Files[i].IsHidden() {
+		if !state.concealed(&state.Files[i]) {
 			state.SelectedIndex = i
 			return
 		}
@@ -1963,7 +2230,7 @@ func (r *StateReducer) ensureSelectionVisible(state *AppState) {
 
 	// If none above, search forward
 	for i := state.SelectedIndex + 1; i < len(state.Files); i++ {
-		if !state.Files[i].IsHidden() {
+		if !state.concealed(&state.Files[i]) {
 			state.SelectedIndex = i
 			return
 		}
@@ -2045,6 +2312,7 @@ func applyRefreshSnapshot(state *AppState, snap refreshSnapshot) {
 	if snap.prevFilter.active {
 		state.FilterActive = true
 		state.FilterQuery = snap.prevFilter.query
+		state.FilterTail = 0
 		state.FilterCaseSensitive = snap.prevFilter.caseSensitive
 		state.FilterSavedIndex = snap.prevFilter.savedIndex
 		state.recomputeFilter()
@@ -2072,6 +2340,7 @@ func (r *StateReducer) applyPreviewToState(state *AppState, preview *PreviewData
 	if state == nil {
 		return
 	}
+	state.ClearError(ErrorSourcePreview)
 	if preview == nil {
 		state.PreviewData = nil
 		state.PreviewPath = ""
@@ -2130,6 +2399,7 @@ func (r *StateReducer) generatePreview(state *AppState) error {
 	file := state.getCurrentFile()
 	if file == nil {
 		r.cancelPreviewLoad(state)
+		state.ClearError(ErrorSourcePreview)
 		state.PreviewData = nil
 		state.resetPreviewScroll()
 		return nil
@@ -2145,7 +2415,7 @@ func (r *StateReducer) generatePreview(state *AppState) error {
 	dispatch := state.getDispatch()
 	if loader == nil || dispatch == nil {
 		// Synchronous path: reuse cache immediately if it matches before doing any work.
-		if info := fileInfoFromEntry(file); info != nil {
+		if info := fileInfoFromEntry(file, state.pathProfile().StatSymlinkTargets); info != nil {
 			if cached, ok := state.getCachedFilePreview(filePath, info); ok {
 				r.applyPreviewToState(state, cached, info, resetScroll, filePath)
 				return nil
@@ -2153,7 +2423,7 @@ func (r *StateReducer) generatePreview(state *AppState) error {
 		}
 
 		r.cancelPreviewLoad(state)
-		preview, info, err := buildPreviewData(filePath, state.HideHiddenFiles)
+		preview, info, err := buildPreviewData(context.Background(), state.FS, filePath, state.HideHiddenFiles)
 		if err != nil {
 			state.PreviewData = nil
 			state.PreviewPath = ""
@@ -2206,7 +2476,7 @@ func (r *StateReducer) generatePreview(state *AppState) error {
 
 	token := state.nextPreviewLoadToken()
 	state.setPreviewPendingLoad(token, filePath, resetScroll)
-	state.previewDebounceTimer = time.AfterFunc(previewDebounceDelay, func() {
+	state.previewDebounceTimer = time.AfterFunc(state.pathProfile().PreviewDebounce, func() {
 		dispatch(PreviewLoadStartAction{Token: token})
 	})
 	return nil
@@ -2217,6 +2487,12 @@ func (r *StateReducer) GeneratePreview(state *AppState) error {
 	return r.generatePreview(state)
 }
 
+// RevealEntry selects the entry called name in the current directory, e.g.
+// the file rdir was started on.
+func (r *StateReducer) RevealEntry(state *AppState, name string) error {
+	return r.revealEntry(state, name)
+}
+
 // EnsurePreviewCurrent forces the preview to match the currently selected file,
 // bypassing debounce/async gaps. Useful when the user opens the fullscreen
 // pager immediately after moving the cursor.
@@ -2240,11 +2516,11 @@ func (r *StateReducer) EnsurePreviewCurrent(state *AppState) error {
 	state.cancelPreviewDebounceTimer()
 	state.clearPreviewPendingLoad()
 
-	preview, info, err := buildPreviewData(filePath, state.HideHiddenFiles)
+	preview, info, err := buildPreviewData(context.Background(), state.FS, filePath, state.HideHiddenFiles)
 	if err != nil {
 		state.PreviewData = nil
 		state.resetPreviewScroll()
-		state.LastError = err
+		state.reportPreviewError(err, filePath)
 		return err
 	}
 
@@ -777,3 +777,90 @@ func TestFilterBackspaceFromNoMatchesToMatches(t *testing.T) {
 		}
 	}
 }
+
+func TestFilterFoldsDiacriticsWhenEnabled(t *testing.T) {
+	newState := func(fold bool) *AppState {
+		return &AppState{
+			CurrentPath:    "/test",
+			Files:          []FileEntry{{Name: "Über.txt"}, {Name: "café.md"}, {Name: "notes.txt"}},
+			FilterActive:   true,
+			FoldDiacritics: fold,
+		}
+	}
+	names := func(state *AppState, query string, caseSensitive bool) []string {
+		state.FilterQuery = query
+		state.FilterCaseSensitive = caseSensitive
+		state.recomputeFilter()
+		var out []string
+		for _, idx := range state.FilteredIndices {
+			out = append(out, state.Files[idx].Name)
+		}
+		return out
+	}
+
+	state := newState(true)
+	if got := names(state, "uber", false); len(got) != 1 || got[0] != "Über.txt" {
+		t.Fatalf("expected uber to match Über.txt, got %v", got)
+	}
+	if got := names(state, "cafe", false); len(got) != 1 || got[0] != "café.md" {
+		t.Fatalf("expected cafe to match café.md, got %v", got)
+	}
+	if got := names(state, "uBer", true); len(got) != 0 {
+		t.Fatalf("smart case should still require matching case, got %v", got)
+	}
+	if got := names(newState(false), "uber", false); len(got) != 0 {
+		t.Fatalf("expected no match without folding, got %v", got)
+	}
+	if !FoldDiacriticsEnabled(func(string) string { return "yes" }) || FoldDiacriticsEnabled(func(string) string { return "" }) {
+		t.Fatalf("unexpected FoldDiacriticsEnabled result")
+	}
+}
+
+func TestLoadFilterScoring(t *testing.T) {
+	cfg, err := LoadFilterScoring(func(string) string { return "prefix=1.5, dir=0.5, ext=2, order=score, bogus=1, dir=x" })
+	if err == nil || !strings.Contains(err.Error(), `"bogus=1"`) || !strings.Contains(err.Error(), `"dir=x"`) {
+		t.Fatalf("expected invalid entries to be reported, got %v", err)
+	}
+	want := FilterScoring{PrefixBonus: 1.5, DirBonus: 0.5, ExtPenalty: 2, RankByScore: true}
+	if cfg != want {
+		t.Fatalf("got %+v, want %+v", cfg, want)
+	}
+}
+
+func TestFilterScoringRanksAndExplainsMatches(t *testing.T) {
+	state := &AppState{
+		CurrentPath: "/test",
+		Files: []FileEntry{
+			{Name: "main.go"},
+			{Name: "golden.txt"},
+			{Name: "go", IsDir: true},
+		},
+		FilterScoring: FilterScoring{PrefixBonus: 1, DirBonus: 0.5, ExtPenalty: 5, RankByScore: true},
+		ScreenHeight:  24,
+		ScreenWidth:   80,
+	}
+	reducer := NewStateReducer()
+	for _, action := range []Action{FilterStartAction{}, FilterCharAction{Char: 'g'}, FilterCharAction{Char: 'o'}} {
+		if _, err := reducer.Reduce(state, action); err != nil {
+			t.Fatalf("reduce %T: %v", action, err)
+		}
+	}
+
+	if got := state.FilteredIndices; len(got) != 3 || got[0] != 2 || got[1] != 1 || got[2] != 0 {
+		t.Fatalf("expected dir, prefix match, then extension-only match; got %v", got)
+	}
+	if _, ok := state.FilterScoreFor(0); ok {
+		t.Fatalf("scores should only be kept while the overlay is on")
+	}
+
+	if _, err := reducer.Reduce(state, FilterToggleScoresAction{}); err != nil {
+		t.Fatalf("toggle scores: %v", err)
+	}
+	score, ok := state.FilterScoreFor(0)
+	if !ok || score.Ext != -5 || score.Prefix != 0 || score.Total != score.Match-5 {
+		t.Fatalf("unexpected breakdown for main.go: %+v (%v)", score, ok)
+	}
+	if score, _ := state.FilterScoreFor(2); score.Dir != 0.5 || score.Prefix != 1 {
+		t.Fatalf("unexpected breakdown for go/: %+v", score)
+	}
+}
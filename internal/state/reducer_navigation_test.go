@@ -2,6 +2,8 @@ package state
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -443,3 +445,105 @@ func TestAppStartNavigation(t *testing.T) {
 		t.Errorf("After UP: expected SelectedIndex=0 (file0), got %d", state.SelectedIndex)
 	}
 }
+
+func TestGoToSiblingFollowsParentOrder(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"2024-01", "2024-02", "2024-03"} {
+		if err := os.Mkdir(filepath.Join(root, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "notes.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	state := &AppState{ScreenHeight: 24, ScreenWidth: 80}
+	if err := LoadDirectory(state, filepath.Join(root, "2024-01")); err != nil {
+		t.Fatalf("LoadDirectory: %v", err)
+	}
+	reducer := NewStateReducer()
+
+	steps := []struct {
+		direction string
+		want      string
+	}{
+		{"prev", "2024-01"}, // already first
+		{"next", "2024-02"},
+		{"next", "2024-03"},
+		{"next", "2024-03"}, // notes.txt is not a directory
+		{"prev", "2024-02"},
+	}
+	for _, step := range steps {
+		if _, err := reducer.Reduce(state, GoToSiblingAction{Direction: step.direction}); err != nil {
+			t.Fatalf("GoToSiblingAction(%s): %v", step.direction, err)
+		}
+		if got := filepath.Base(state.CurrentPath); got != step.want {
+			t.Fatalf("after %s: at %s, want %s", step.direction, got, step.want)
+		}
+	}
+}
+
+func TestParentPaneSelectAction(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"alpha", "beta"} {
+		if err := os.Mkdir(filepath.Join(root, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "notes.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	state := &AppState{ScreenHeight: 24, ScreenWidth: 80}
+	if err := LoadDirectory(state, filepath.Join(root, "alpha")); err != nil {
+		t.Fatalf("LoadDirectory: %v", err)
+	}
+	reducer := NewStateReducer()
+	parentIndex := func(name string) int {
+		for idx, entry := range state.ParentEntries {
+			if entry.Name == name {
+				return idx
+			}
+		}
+		t.Fatalf("%s missing from the parent pane", name)
+		return -1
+	}
+
+	if _, err := reducer.Reduce(state, ParentPaneSelectAction{Index: parentIndex("beta")}); err != nil {
+		t.Fatalf("select sibling: %v", err)
+	}
+	if state.CurrentPath != filepath.Join(root, "beta") {
+		t.Fatalf("expected the sibling to open, at %s", state.CurrentPath)
+	}
+	if _, _, current := state.ParentPaneWindow(10); current != parentIndex("beta") {
+		t.Fatalf("expected the parent pane to mark beta, got index %d", current)
+	}
+
+	if _, err := reducer.Reduce(state, ParentPaneSelectAction{Index: parentIndex("notes.txt")}); err != nil {
+		t.Fatalf("select file: %v", err)
+	}
+	if state.CurrentPath != root {
+		t.Fatalf("expected a file to open the parent, at %s", state.CurrentPath)
+	}
+	if file := state.getCurrentFile(); file == nil || file.Name != "notes.txt" {
+		t.Fatalf("expected notes.txt selected in the parent, got %+v", file)
+	}
+}
+
+func TestParentPaneWindowCentresCurrent(t *testing.T) {
+	state := &AppState{CurrentPath: filepath.FromSlash("/p/e")}
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		state.ParentEntries = append(state.ParentEntries, FileEntry{Name: name, IsDir: true})
+	}
+	if start, end, current := state.ParentPaneWindow(3); start != 3 || end != 6 || current != 4 {
+		t.Fatalf("got window %d-%d current %d, want 3-6 current 4", start, end, current)
+	}
+	state.CurrentPath = filepath.FromSlash("/p/g")
+	if start, end, _ := state.ParentPaneWindow(3); start != 4 || end != 7 {
+		t.Fatalf("expected the window to stop at the end, got %d-%d", start, end)
+	}
+	state.CurrentPath = filepath.FromSlash("/p/gone")
+	if start, _, current := state.ParentPaneWindow(3); start != 0 || current != -1 {
+		t.Fatalf("expected an unlisted directory to show the top, got start %d current %d", start, current)
+	}
+}
@@ -1,6 +1,7 @@
 package state
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -32,7 +33,7 @@ func TestGeneratePreviewDebounceAppliesLatestSelection(t *testing.T) {
 		t.Fatalf("write b: %v", err)
 	}
 
-	entries, err := readDirectoryEntries(dir)
+	entries, err := readDirectoryEntries(context.Background(), nil, dir)
 	if err != nil {
 		t.Fatalf("read entries: %v", err)
 	}
@@ -95,7 +96,7 @@ func TestGeneratePreviewDebounceAppliesLatestSelection(t *testing.T) {
 	}
 
 	// Complete load with the second file only.
-	data, info, err := buildPreviewData(loader.lastReq.Path, loader.lastReq.HideHidden)
+	data, info, err := buildPreviewData(context.Background(), nil, loader.lastReq.Path, loader.lastReq.HideHidden)
 	if err != nil {
 		t.Fatalf("build preview: %v", err)
 	}
@@ -112,3 +113,31 @@ func TestGeneratePreviewDebounceAppliesLatestSelection(t *testing.T) {
 		t.Fatalf("expected preview of b.txt, got %+v", state.PreviewData)
 	}
 }
+
+func TestInlinePreviewScrollIsClampedToContent(t *testing.T) {
+	lines := make([]string, 30)
+	state := &AppState{
+		ScreenHeight: 12,
+		ScreenWidth:  80,
+		PreviewData:  &PreviewData{TextLines: lines},
+	}
+	reducer := NewStateReducer()
+
+	if _, err := reducer.Reduce(state, PreviewScrollUpAction{}); err != nil {
+		t.Fatal(err)
+	}
+	if state.PreviewScrollOffset != 0 {
+		t.Fatalf("expected offset to stay at 0, got %d", state.PreviewScrollOffset)
+	}
+	for i := 0; i < 50; i++ {
+		if _, err := reducer.Reduce(state, PreviewScrollDownAction{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := 30 - state.previewVisibleLines(); state.PreviewScrollOffset != want {
+		t.Fatalf("expected offset clamped to %d, got %d", want, state.PreviewScrollOffset)
+	}
+	if state.PreviewFullScreen {
+		t.Fatalf("inline scrolling must not enter fullscreen")
+	}
+}
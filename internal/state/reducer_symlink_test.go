@@ -3,6 +3,7 @@ package state
 import (
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 )
 
@@ -477,3 +478,58 @@ func TestGetSymlinkTarget_NotSymlink(t *testing.T) {
 		t.Errorf("getSymlinkTarget should return empty string for non-symlink, got '%s'", target)
 	}
 }
+
+func TestGoToTargetSelectsSymlinkAndShortcutTargets(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks and file:// paths of .desktop entries are Unix-specific")
+	}
+	root := t.TempDir()
+	docs := filepath.Join(root, "docs")
+	links := filepath.Join(root, "links")
+	for _, dir := range []string{docs, links} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	for _, name := range []string{"a.md", "b.md", "guide.md"} {
+		if err := os.WriteFile(filepath.Join(docs, name), nil, 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := os.Symlink(filepath.Join("..", "docs", "guide.md"), filepath.Join(links, "guide-link")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	desktop := "[Desktop Entry]\nType=Link\nName=Notes\nURL=file://" + filepath.ToSlash(filepath.Join(docs, "b.md")) + "\n"
+	if err := os.WriteFile(filepath.Join(links, "notes.desktop"), []byte(desktop), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	reducer := NewStateReducer()
+	for _, tc := range []struct{ entry, want string }{
+		{"guide-link", "guide.md"},
+		{"notes.desktop", "b.md"},
+	} {
+		state := &AppState{ScreenHeight: 24, ScreenWidth: 80}
+		if err := LoadDirectory(state, links); err != nil {
+			t.Fatalf("LoadDirectory: %v", err)
+		}
+		state.SelectedIndex = findFileIndexByName(state.Files, tc.entry)
+		if _, err := reducer.Reduce(state, GoToTargetAction{}); err != nil {
+			t.Fatalf("%s: GoToTargetAction: %v", tc.entry, err)
+		}
+		if state.CurrentPath != docs {
+			t.Fatalf("%s: current path = %q, want %q", tc.entry, state.CurrentPath, docs)
+		}
+		if file := state.CurrentFile(); file == nil || file.Name != tc.want {
+			t.Fatalf("%s: selected %+v, want %s", tc.entry, file, tc.want)
+		}
+	}
+
+	state := &AppState{ScreenHeight: 24, ScreenWidth: 80}
+	if err := LoadDirectory(state, docs); err != nil {
+		t.Fatalf("LoadDirectory: %v", err)
+	}
+	if _, err := reducer.Reduce(state, GoToTargetAction{}); err == nil {
+		t.Fatalf("expected an error for a plain file")
+	}
+}
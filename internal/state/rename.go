@@ -0,0 +1,215 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kk-code-lab/rdir/internal/lineedit"
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+)
+
+// InlineRename is the selected entry's name being edited in place in the
+// list (F2). It opens with the name without its extension selected, so
+// typing replaces the stem and keeps the extension.
+type InlineRename struct {
+	Path  string // entry being renamed
+	Input string
+	Tail  int // runes of Input after the cursor
+	// SelStart and SelEnd are the selected runes of Input; the selection is
+	// empty when they are equal. Typing or deleting replaces it, and moving
+	// the cursor drops it.
+	SelStart int
+	SelEnd   int
+	Err      string
+}
+
+// Line returns the input and its cursor for editing.
+func (r *InlineRename) Line() lineedit.Line {
+	return lineedit.FromTail(r.Input, r.Tail)
+}
+
+// Selection returns the selected run of Input as rune offsets; ok is false
+// when nothing is selected.
+func (r *InlineRename) Selection() (start, end int, ok bool) {
+	if r.SelStart >= r.SelEnd {
+		return 0, 0, false
+	}
+	n := len([]rune(r.Input))
+	return min(r.SelStart, n), min(r.SelEnd, n), true
+}
+
+func (r *InlineRename) set(line lineedit.Line) {
+	r.Input = line.String()
+	r.Tail = line.Tail()
+	r.SelStart, r.SelEnd = 0, 0
+}
+
+// deleteSelection removes the selected runes, leaving the cursor where they
+// were, and reports whether there was a selection.
+func (r *InlineRename) deleteSelection() bool {
+	start, end, ok := r.Selection()
+	if !ok {
+		return false
+	}
+	runes := []rune(r.Input)
+	line := lineedit.Line{Text: append(runes[:start:start], runes[end:]...), Cursor: start}
+	r.set(line)
+	return true
+}
+
+func (r *InlineRename) insert(ch rune) {
+	r.deleteSelection()
+	line := r.Line()
+	line.Insert(ch)
+	r.set(line)
+	r.Err = ""
+}
+
+func (r *InlineRename) edit(op lineedit.Op) {
+	start, end, selected := r.Selection()
+	switch {
+	case selected && (op == lineedit.Backspace || op == lineedit.Delete):
+		r.deleteSelection()
+		r.Err = ""
+		return
+	case selected && op == lineedit.Left:
+		r.set(lineedit.Line{Text: []rune(r.Input), Cursor: start})
+		return
+	case selected && op == lineedit.Right:
+		r.set(lineedit.Line{Text: []rune(r.Input), Cursor: end})
+		return
+	}
+	line := r.Line()
+	changed := line.Apply(op)
+	r.set(line)
+	if changed {
+		r.Err = ""
+	}
+}
+
+// renameStem returns how many runes of name come before its extension: the
+// part selected when renaming starts. Directories and dotfiles such as
+// ".env" select the whole name, as UniqueDestination treats them.
+func renameStem(name string, isDir bool) int {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	if isDir || stem == "" {
+		stem = name
+	}
+	return len([]rune(stem))
+}
+
+// startInlineRename opens the inline editor on the selected entry.
+func (s *AppState) startInlineRename() error {
+	file := s.CurrentFile()
+	if file == nil {
+		return nil
+	}
+	if err := s.RequireLocalDisk("rename entries"); err != nil {
+		return err
+	}
+	s.clearTypeAhead()
+	stem := renameStem(file.Name, file.IsDir)
+	runes := len([]rune(file.Name))
+	s.Rename = &InlineRename{
+		Path:     s.CurrentFilePath(),
+		Input:    file.Name,
+		Tail:     runes - stem,
+		SelStart: 0,
+		SelEnd:   stem,
+	}
+	return nil
+}
+
+// submitInlineRename checks the new name and hands the rename to the app.
+// Problems keep the editor open with a message.
+func (s *AppState) submitInlineRename() {
+	rename := s.Rename
+	if rename == nil {
+		return
+	}
+	oldName := filepath.Base(rename.Path)
+	target, err := renameTarget(rename.Path, rename.Input)
+	if err != nil {
+		rename.Err = err.Error()
+		return
+	}
+	s.Rename = nil
+	if rename.Input == oldName {
+		return
+	}
+	if dispatch := s.getDispatch(); dispatch != nil {
+		dispatch(RenameEntryAction{From: rename.Path, To: target})
+	}
+}
+
+// renameTarget validates name as the new name of the entry at path and
+// returns the new path. Another entry already using the name is refused; a
+// change of case only, on a filesystem that ignores case, is not a collision.
+func renameTarget(path, name string) (string, error) {
+	switch {
+	case strings.TrimSpace(name) == "":
+		return "", errors.New("enter a name")
+	case name == "." || name == "..":
+		return "", fmt.Errorf("%q is not a valid name", name)
+	case strings.ContainsAny(name, `/\`):
+		return "", errors.New("the name must not contain path separators")
+	case strings.ContainsRune(name, 0):
+		return "", errors.New("the name must not contain NUL characters")
+	}
+	target := filepath.Join(filepath.Dir(path), name)
+	if target == path {
+		return target, nil
+	}
+	existing, err := os.Lstat(target)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return target, nil
+	case err != nil:
+		return "", err
+	}
+	if current, err := os.Lstat(path); err == nil && os.SameFile(current, existing) {
+		return target, nil
+	}
+	return "", fmt.Errorf("%s already exists", textutil.SanitizeTerminalText(name))
+}
+
+// RenameEntry renames from to to, refusing to replace an entry created since
+// the name was checked.
+func RenameEntry(from, to string) error {
+	if _, err := renameTarget(from, filepath.Base(to)); err != nil {
+		return err
+	}
+	return os.Rename(from, to)
+}
+
+// renamePaths points marks and staged entries at from, or inside it, to
+// their new place under to.
+func (s *AppState) renamePaths(from, to string) {
+	moved := func(path string) (string, bool) {
+		if path == from {
+			return to, true
+		}
+		if rest, ok := strings.CutPrefix(path, from+string(filepath.Separator)); ok {
+			return filepath.Join(to, rest), true
+		}
+		return path, false
+	}
+	var marked []string
+	for path := range s.marks {
+		if _, ok := moved(path); ok {
+			marked = append(marked, path)
+		}
+	}
+	for _, path := range marked {
+		renamed, _ := moved(path)
+		delete(s.marks, path)
+		s.marks[renamed] = true
+	}
+	for i, path := range s.Staging.Paths {
+		s.Staging.Paths[i], _ = moved(path)
+	}
+}
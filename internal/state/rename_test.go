@@ -0,0 +1,147 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kk-code-lab/rdir/internal/lineedit"
+)
+
+func selectByName(t *testing.T, state *AppState, name string) {
+	t.Helper()
+	idx := findFileIndexByName(state.Files, name)
+	if idx < 0 {
+		t.Fatalf("%s not listed", name)
+	}
+	state.SelectedIndex = idx
+}
+
+func TestInlineRenameSelectsStemAndTypingKeepsExtension(t *testing.T) {
+	state, reducer, root := newFixtureState(t, "notes.tar.gz", "src/", ".env")
+	var dispatched []Action
+	state.SetDispatch(func(a Action) { dispatched = append(dispatched, a) })
+
+	selectByName(t, state, "notes.tar.gz")
+	if _, err := reducer.Reduce(state, RenameStartAction{}); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if start, end, ok := state.Rename.Selection(); !ok || start != 0 || end != len("notes.tar") {
+		t.Fatalf("selection = %d..%d (%v), want the stem", start, end, ok)
+	}
+	for _, ch := range "todo" {
+		if _, err := reducer.Reduce(state, RenameCharAction{Char: ch}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if state.Rename.Input != "todo.gz" {
+		t.Fatalf("input = %q, want todo.gz", state.Rename.Input)
+	}
+	if _, err := reducer.Reduce(state, RenameSubmitAction{}); err != nil {
+		t.Fatal(err)
+	}
+	if state.Rename != nil || len(dispatched) != 1 {
+		t.Fatalf("rename = %+v, dispatched = %#v", state.Rename, dispatched)
+	}
+	want := RenameEntryAction{From: filepath.Join(root, "notes.tar.gz"), To: filepath.Join(root, "todo.gz")}
+	if dispatched[0] != want {
+		t.Fatalf("dispatched %#v, want %#v", dispatched[0], want)
+	}
+
+	for _, name := range []string{"src", ".env"} {
+		selectByName(t, state, name)
+		if _, err := reducer.Reduce(state, RenameStartAction{}); err != nil {
+			t.Fatal(err)
+		}
+		if _, end, _ := state.Rename.Selection(); end != len(name) {
+			t.Fatalf("%s: selection ends at %d, want the whole name", name, end)
+		}
+		state.Rename = nil
+	}
+}
+
+func TestInlineRenameCursorKeysDropSelection(t *testing.T) {
+	rename := &InlineRename{Input: "report.md", Tail: 3, SelEnd: 6}
+	rename.edit(lineedit.Right)
+	if _, _, ok := rename.Selection(); ok || rename.Tail != 3 {
+		t.Fatalf("after Right: %+v, want cursor at the selection end", rename)
+	}
+	rename.edit(lineedit.Home)
+	rename.insert('q')
+	if rename.Input != "qreport.md" {
+		t.Fatalf("input = %q", rename.Input)
+	}
+
+	rename = &InlineRename{Input: "report.md", Tail: 3, SelEnd: 6}
+	rename.edit(lineedit.Backspace)
+	if rename.Input != ".md" || rename.Tail != 3 {
+		t.Fatalf("after Backspace: %+v, want the stem deleted", rename)
+	}
+}
+
+func TestInlineRenameRefusesBadNames(t *testing.T) {
+	state, reducer, _ := newFixtureState(t, "a.txt", "b.txt")
+	dispatched := 0
+	state.SetDispatch(func(Action) { dispatched++ })
+	selectByName(t, state, "a.txt")
+
+	for _, input := range []string{"b.txt", "", "..", "x/y"} {
+		if _, err := reducer.Reduce(state, RenameStartAction{}); err != nil {
+			t.Fatal(err)
+		}
+		state.Rename.Input, state.Rename.Tail = input, 0
+		if _, err := reducer.Reduce(state, RenameSubmitAction{}); err != nil {
+			t.Fatal(err)
+		}
+		if state.Rename == nil || state.Rename.Err == "" {
+			t.Fatalf("%q: rename = %+v, want it kept open with an error", input, state.Rename)
+		}
+		state.Rename = nil
+	}
+	if dispatched != 0 {
+		t.Fatalf("dispatched %d renames", dispatched)
+	}
+}
+
+func TestRenameEntryAllowsCaseOnlyChange(t *testing.T) {
+	dir := t.TempDir()
+	from := filepath.Join(dir, "readme.md")
+	if err := os.WriteFile(from, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	to := filepath.Join(dir, "README.md")
+	if err := RenameEntry(from, to); err != nil {
+		t.Fatalf("RenameEntry: %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 || entries[0].Name() != "README.md" {
+		t.Fatalf("entries = %v (%v)", entries, err)
+	}
+}
+
+func TestRenameResultMovesMarksAndStagingAndSelectsNewName(t *testing.T) {
+	state, reducer, root := newFixtureState(t, "old/", "other.txt")
+	inner := filepath.Join(root, "old", "inner.txt")
+	if err := os.WriteFile(inner, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	state.toggleMark(filepath.Join(root, "old"))
+	state.Staging = StagingArea{Mode: StagingCopy, Paths: []string{inner, filepath.Join(root, "other.txt")}}
+
+	from, to := filepath.Join(root, "old"), filepath.Join(root, "new")
+	if err := RenameEntry(from, to); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reducer.Reduce(state, RenameResultAction{From: from, To: to}); err != nil {
+		t.Fatalf("result: %v", err)
+	}
+	if file := state.CurrentFile(); file == nil || file.Name != "new" {
+		t.Fatalf("selected %+v, want new", file)
+	}
+	if !state.IsMarked(state.CurrentFile()) {
+		t.Fatal("mark did not follow the rename")
+	}
+	if got := state.Staging.Paths[0]; got != filepath.Join(to, "inner.txt") {
+		t.Fatalf("staged path = %s", got)
+	}
+}
@@ -0,0 +1,46 @@
+package state
+
+import (
+	"fmt"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// concealed reports whether an entry is left out of the view: hidden entries
+// while HideHiddenFiles is on, except the one revealed by name.
+func (s *AppState) concealed(file *FileEntry) bool {
+	if !s.HideHiddenFiles || !file.IsHidden() {
+		return false
+	}
+	return s.RevealedName == "" || file.Name != s.RevealedName
+}
+
+// clearReveal hides a previously revealed entry again.
+func (s *AppState) clearReveal() {
+	if s.RevealedName == "" {
+		return
+	}
+	s.RevealedName = ""
+	s.invalidateDisplayFilesCache()
+}
+
+// revealEntry selects the entry with exactly name in the current directory.
+// A hidden entry is shown on its own while hidden files stay off; it stays
+// visible until the directory changes or hidden files are toggled.
+func (r *StateReducer) revealEntry(state *AppState, name string) error {
+	name = norm.NFC.String(name)
+	idx := findFileIndexByName(state.Files, name)
+	if idx < 0 {
+		return fmt.Errorf("no entry named %q", name)
+	}
+
+	state.clearFilter()
+	state.clearReveal()
+	if state.HideHiddenFiles && state.Files[idx].IsHidden() {
+		state.RevealedName = name
+		state.invalidateDisplayFilesCache()
+	}
+	state.SelectedIndex = idx
+	state.centerScrollOnSelection()
+	return r.generatePreview(state)
+}
@@ -0,0 +1,74 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRevealSelectsHiddenEntryWithoutShowingOthers(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{".env", ".git", "main.go"} {
+		path := filepath.Join(root, name)
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		if name[0] == '.' {
+			if err := markHiddenForTest(path); err != nil {
+				t.Fatalf("mark hidden: %v", err)
+			}
+		}
+	}
+	state := &AppState{ScreenHeight: 30, ScreenWidth: 100, HideHiddenFiles: true}
+	if err := LoadDirectory(state, root); err != nil {
+		t.Fatalf("LoadDirectory: %v", err)
+	}
+	reducer := NewStateReducer()
+
+	actions := []Action{RevealStartAction{}}
+	for _, ch := range ".env" {
+		actions = append(actions, PromptCharAction{Char: ch})
+	}
+	actions = append(actions, PromptSubmitAction{})
+	for _, action := range actions {
+		if _, err := reducer.Reduce(state, action); err != nil {
+			t.Fatalf("%T: %v", action, err)
+		}
+	}
+
+	if state.Prompt != nil {
+		t.Fatalf("expected prompt to close, got %+v", state.Prompt)
+	}
+	if file := state.getCurrentFile(); file == nil || file.Name != ".env" {
+		t.Fatalf("expected .env selected, got %+v", file)
+	}
+	if got := len(state.getDisplayFiles()); got != 2 {
+		t.Fatalf("expected .env and main.go visible, got %d entries", got)
+	}
+	if !state.HideHiddenFiles {
+		t.Fatalf("reveal must not flip the hidden-files toggle")
+	}
+
+	if _, err := reducer.Reduce(state, ToggleHiddenFilesAction{}); err != nil {
+		t.Fatalf("toggle hidden: %v", err)
+	}
+	if _, err := reducer.Reduce(state, ToggleHiddenFilesAction{}); err != nil {
+		t.Fatalf("toggle hidden: %v", err)
+	}
+	if state.RevealedName != "" || len(state.getDisplayFiles()) != 1 {
+		t.Fatalf("expected reveal to be dropped after toggling, got %q", state.RevealedName)
+	}
+}
+
+func TestRevealUnknownNameKeepsPromptOpen(t *testing.T) {
+	state := &AppState{ScreenHeight: 30, ScreenWidth: 100, Files: []FileEntry{{Name: "a"}}}
+	reducer := NewStateReducer()
+	state.openPrompt(PromptReveal)
+	state.Prompt.Input = "missing"
+	if _, err := reducer.Reduce(state, PromptSubmitAction{}); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if state.Prompt == nil || state.Prompt.Err == "" {
+		t.Fatalf("expected prompt error, got %+v", state.Prompt)
+	}
+}
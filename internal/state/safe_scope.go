@@ -0,0 +1,111 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+)
+
+// protectedScope reports why a recursive operation on path needs the path
+// typed out first: it is the filesystem root, the home directory or the
+// root of a mount.
+func protectedScope(path string) (string, bool) {
+	path = filepath.Clean(path)
+	if filepath.Dir(path) == path {
+		return "the filesystem root", true
+	}
+	if home, err := os.UserHomeDir(); err == nil && home != "" && samePath(path, filepath.Clean(home)) {
+		return "your home directory", true
+	}
+	if fsutil.MountRoot(path) {
+		return "a mount root", true
+	}
+	return "", false
+}
+
+func samePath(a, b string) bool {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// confirmProtectedScope asks twice before verb runs on the first protected
+// path in paths: a yes/no question, then a prompt where the path must be
+// typed out. then runs once both are answered. It reports whether it asked.
+func (s *AppState) confirmProtectedScope(verb string, paths []string, then Action) bool {
+	for _, path := range paths {
+		reason, ok := protectedScope(path)
+		if !ok {
+			continue
+		}
+		prompt := fmt.Sprintf("%s %s (%s) recursively? You will type the path to confirm",
+			capitalize(verb), textutil.SanitizeTerminalText(path), reason)
+		s.requestConfirm(prompt, ScopeConfirmStartAction{Scope: filepath.Clean(path), Verb: verb, Then: then})
+		return true
+	}
+	return false
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// openScopeConfirm shows the prompt asking for the protected path typed out.
+func (s *AppState) openScopeConfirm(a ScopeConfirmStartAction) {
+	s.openPrompt(PromptConfirmScope)
+	s.Prompt.Scope = a.Scope
+	s.Prompt.Verb = a.Verb
+	s.Prompt.Then = a.Then
+}
+
+// submitScopeConfirm runs the guarded action when the input names the
+// protected path exactly; trailing separators are ignored.
+func (s *AppState) submitScopeConfirm(prompt *TextPrompt) error {
+	input := strings.TrimSpace(prompt.Input)
+	if input == "" || filepath.Clean(input) != prompt.Scope {
+		return fmt.Errorf("type %s exactly to %s, or Esc to cancel", textutil.SanitizeTerminalText(prompt.Scope), prompt.Verb)
+	}
+	if dispatch := s.getDispatch(); dispatch != nil && prompt.Then != nil {
+		dispatch(prompt.Then)
+	}
+	return nil
+}
+
+// ConfirmProtectedPaste guards a paste that would move or copy a protected
+// directory, or overwrite one at the destination. Confirming both steps
+// replays the paste with ScopeConfirmed set. It reports whether it asked.
+func (s *AppState) ConfirmProtectedPaste(action PasteStagedAction) bool {
+	if action.ScopeConfirmed {
+		return false
+	}
+	verb := "copy"
+	if s.Staging.Mode == StagingCut {
+		verb = "move"
+	}
+	if action.Resolutions == nil {
+		if action.Confirmed || action.ReferencesChecked {
+			// A later question of a paste whose sources were checked already.
+			return false
+		}
+		return s.confirmProtectedScope(verb, s.Staging.Paths, PasteStagedAction{ScopeConfirmed: true})
+	}
+
+	var replaced []string
+	for _, src := range s.Staging.Paths {
+		switch action.Resolutions.Resolve(src) {
+		case ConflictOverwrite, ConflictKeepNewer:
+			replaced = append(replaced, filepath.Join(s.CurrentPath, filepath.Base(src)))
+		}
+	}
+	action.ScopeConfirmed = true
+	return s.confirmProtectedScope("overwrite", replaced, action)
+}
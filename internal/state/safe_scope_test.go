@@ -0,0 +1,117 @@
+package state
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProtectedScope(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	root := filepath.VolumeName(home) + string(filepath.Separator)
+	for _, tt := range []struct {
+		path string
+		want bool
+	}{
+		{root, true},
+		{home, true},
+		{home + string(filepath.Separator), true},
+		{filepath.Join(home, "projects"), false},
+	} {
+		if _, got := protectedScope(tt.path); got != tt.want {
+			t.Fatalf("protectedScope(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestPasteOfHomeNeedsTypedPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	state := &AppState{ScreenHeight: 30, ScreenWidth: 100, CurrentPath: t.TempDir()}
+	state.Staging = StagingArea{Mode: StagingCut, Paths: []string{home}}
+	reducer := NewStateReducer()
+	var dispatched []Action
+	state.SetDispatch(func(a Action) { dispatched = append(dispatched, a) })
+
+	if !state.ConfirmProtectedPaste(PasteStagedAction{}) {
+		t.Fatalf("expected the paste to be guarded")
+	}
+	if state.PendingConfirm == nil || !strings.Contains(state.PendingConfirm.Prompt, "home directory") {
+		t.Fatalf("confirm = %+v", state.PendingConfirm)
+	}
+	if _, err := reducer.Reduce(state, ConfirmAcceptAction{}); err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	if state.Prompt == nil || state.Prompt.Kind != PromptConfirmScope {
+		t.Fatalf("prompt = %+v, want the typed confirmation", state.Prompt)
+	}
+
+	state.Prompt.Input = "~"
+	if _, err := reducer.Reduce(state, PromptSubmitAction{}); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if state.Prompt == nil || state.Prompt.Err == "" || len(dispatched) != 0 {
+		t.Fatalf("expected a mismatch to keep the prompt open, got %+v dispatched=%v", state.Prompt, dispatched)
+	}
+
+	state.Prompt.Input = home + string(filepath.Separator)
+	if _, err := reducer.Reduce(state, PromptSubmitAction{}); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if state.Prompt != nil || len(dispatched) != 1 {
+		t.Fatalf("prompt = %+v, dispatched = %#v", state.Prompt, dispatched)
+	}
+	replay, ok := dispatched[0].(PasteStagedAction)
+	if !ok || !replay.ScopeConfirmed {
+		t.Fatalf("replay = %#v", dispatched[0])
+	}
+	if state.ConfirmProtectedPaste(replay) {
+		t.Fatalf("the confirmed paste should not be asked about again")
+	}
+}
+
+func TestPasteOverwritingHomeIsGuarded(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	src := filepath.Join(t.TempDir(), filepath.Base(home))
+	state := &AppState{CurrentPath: filepath.Dir(home)}
+	state.Staging = StagingArea{Mode: StagingCopy, Paths: []string{src}}
+	keep := &ConflictResolutions{Choices: map[string]ConflictChoice{src: ConflictSkip}}
+	if state.ConfirmProtectedPaste(PasteStagedAction{Confirmed: true, Resolutions: keep}) {
+		t.Fatalf("skipping the conflict should not be guarded")
+	}
+	overwrite := &ConflictResolutions{Choices: map[string]ConflictChoice{src: ConflictOverwrite}}
+	if !state.ConfirmProtectedPaste(PasteStagedAction{Confirmed: true, Resolutions: overwrite}) {
+		t.Fatalf("overwriting the home directory should be guarded")
+	}
+	start, ok := state.PendingConfirm.Action.(ScopeConfirmStartAction)
+	if !ok || start.Scope != home || start.Verb != "overwrite" {
+		t.Fatalf("confirm action = %#v", state.PendingConfirm.Action)
+	}
+	if replay, ok := start.Then.(PasteStagedAction); !ok || !replay.ScopeConfirmed || replay.Resolutions != overwrite {
+		t.Fatalf("replay = %#v", start.Then)
+	}
+}
+
+func TestSelectPatternRefusesMarkingAllOfHome(t *testing.T) {
+	state, _, home := newFixtureState(t, "a.txt", "b.txt", "notes.md")
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	if _, err := state.applySelectPattern("*", SelectAdd); err == nil {
+		t.Fatalf("expected * in the home directory to be refused")
+	}
+	if len(state.marks) != 0 {
+		t.Fatalf("marks = %v, want none", state.marks)
+	}
+	if n, err := state.applySelectPattern("*.txt", SelectAdd); err != nil || n != 2 {
+		t.Fatalf("*.txt: n=%d err=%v", n, err)
+	}
+}
@@ -0,0 +1,262 @@
+package state
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+)
+
+// SearchSortMode orders the global search result list.
+type SearchSortMode int
+
+const (
+	SearchSortScore    SearchSortMode = iota // match ranking from the searcher
+	SearchSortPath                           // full path, A→Z
+	SearchSortModified                       // newest first
+	SearchSortSize                           // largest first
+)
+
+func (m SearchSortMode) String() string {
+	switch m {
+	case SearchSortPath:
+		return "path"
+	case SearchSortModified:
+		return "newest"
+	case SearchSortSize:
+		return "largest"
+	default:
+		return "score"
+	}
+}
+
+// Next cycles score → path → newest → largest.
+func (m SearchSortMode) Next() SearchSortMode {
+	return (m + 1) % 4
+}
+
+func (m SearchSortMode) needsStat() bool {
+	return m == SearchSortModified || m == SearchSortSize
+}
+
+// searchStat is size/mtime fetched for results that came from the directory
+// walker, which does not stat files.
+type searchStat struct {
+	size    int64
+	modTime time.Time
+}
+
+// installGlobalSearchResults shows results, fresh from the searcher in
+// score order, under the active sort mode. The ranked list is kept without
+// a copy since it is only ever replaced, never sorted in place. Other modes
+// sort only the results not shown before and merge them into the list
+// already sorted, so a streamed batch does not re-sort everything found.
+func (s *AppState) installGlobalSearchResults(results []GlobalSearchResult) {
+	prev := s.GlobalSearchResults
+	s.globalSearchRanked = results
+	mode := s.GlobalSearchSort
+	if mode == SearchSortScore || len(results) < 2 {
+		s.GlobalSearchResults = results
+		return
+	}
+	if mode.needsStat() {
+		s.fillSearchStats(results)
+	}
+
+	index := make(map[string]int, len(results))
+	for i := range results {
+		index[results[i].FilePath] = i
+	}
+	shown := make([]bool, len(results))
+	merged := make([]GlobalSearchResult, 0, len(results))
+	for i := range prev {
+		if idx, ok := index[prev[i].FilePath]; ok && !shown[idx] {
+			shown[idx] = true
+			merged = append(merged, results[idx])
+		}
+	}
+	for i := 1; i < len(merged); i++ {
+		if searchResultLess(mode, &merged[i], &merged[i-1]) {
+			// Sort keys changed under the shown list (e.g. stats arrived).
+			s.GlobalSearchResults = cloneResults(results)
+			s.sortGlobalSearchResults()
+			return
+		}
+	}
+
+	var added []GlobalSearchResult
+	for i := range results {
+		if !shown[i] {
+			added = append(added, results[i])
+		}
+	}
+	sort.SliceStable(added, func(i, j int) bool {
+		return searchResultLess(mode, &added[i], &added[j])
+	})
+
+	out := make([]GlobalSearchResult, 0, len(results))
+	i, j := 0, 0
+	for i < len(merged) && j < len(added) {
+		if searchResultLess(mode, &added[j], &merged[i]) {
+			out = append(out, added[j])
+			j++
+		} else {
+			out = append(out, merged[i])
+			i++
+		}
+	}
+	out = append(out, merged[i:]...)
+	s.GlobalSearchResults = append(out, added[j:]...)
+}
+
+// cycleGlobalSearchSort switches to the next sort mode, keeping the selected
+// result selected.
+func (s *AppState) cycleGlobalSearchSort() {
+	s.GlobalSearchSort = s.GlobalSearchSort.Next()
+	if len(s.GlobalSearchResults) == 0 {
+		return
+	}
+	s.resortGlobalSearchResults()
+}
+
+func (s *AppState) resortGlobalSearchResults() {
+	selected := ""
+	if idx := s.GlobalSearchIndex; idx >= 0 && idx < len(s.GlobalSearchResults) {
+		selected = s.GlobalSearchResults[idx].FilePath
+	}
+	if s.GlobalSearchSort == SearchSortScore {
+		s.GlobalSearchResults = s.globalSearchRanked
+	} else {
+		s.GlobalSearchResults = cloneResults(s.globalSearchRanked)
+		s.sortGlobalSearchResults()
+	}
+	for idx, result := range s.GlobalSearchResults {
+		if result.FilePath == selected {
+			s.GlobalSearchIndex = idx
+			break
+		}
+	}
+	s.clampGlobalSearchSelection()
+}
+
+// sortGlobalSearchResults sorts GlobalSearchResults in place; it must not
+// share its backing array with globalSearchRanked.
+func (s *AppState) sortGlobalSearchResults() {
+	mode := s.GlobalSearchSort
+	if mode == SearchSortScore || len(s.GlobalSearchResults) < 2 {
+		return
+	}
+	results := s.GlobalSearchResults
+	if mode.needsStat() {
+		s.fillSearchStats(results)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return searchResultLess(mode, &results[i], &results[j])
+	})
+}
+
+// searchResultLess orders two results under mode; results whose mtime is not
+// known yet go last when sorting by date or size.
+func searchResultLess(mode SearchSortMode, x, y *GlobalSearchResult) bool {
+	a, b := &x.FileEntry, &y.FileEntry
+	switch mode {
+	case SearchSortPath:
+		return strings.ToLower(x.FilePath) < strings.ToLower(y.FilePath)
+	case SearchSortModified:
+		if a.Modified.IsZero() != b.Modified.IsZero() {
+			return !a.Modified.IsZero()
+		}
+		return a.Modified.After(b.Modified)
+	case SearchSortSize:
+		if a.Modified.IsZero() != b.Modified.IsZero() {
+			return !a.Modified.IsZero()
+		}
+		return a.Size > b.Size
+	}
+	return false
+}
+
+// fillSearchStats copies known size/mtime into results that lack them and
+// requests the rest.
+func (s *AppState) fillSearchStats(results []GlobalSearchResult) {
+	fill := func() []string {
+		var missing []string
+		for i := range results {
+			entry := &results[i].FileEntry
+			if !entry.Modified.IsZero() {
+				continue
+			}
+			if stat, ok := s.searchStats[results[i].FilePath]; ok {
+				entry.Size = stat.size
+				entry.Modified = stat.modTime
+				continue
+			}
+			missing = append(missing, results[i].FilePath)
+		}
+		return missing
+	}
+	if missing := fill(); len(missing) > 0 && s.requestSearchStats(missing) {
+		fill()
+	}
+}
+
+// requestSearchStats fetches size/mtime for paths in the background; results
+// arrive as GlobalSearchStatsAction. Without a dispatcher it stats inline and
+// reports true.
+func (s *AppState) requestSearchStats(paths []string) bool {
+	var pending []string
+	for _, path := range paths {
+		if !s.searchStatsInFlight[path] {
+			pending = append(pending, path)
+		}
+	}
+	if len(pending) == 0 {
+		return false
+	}
+
+	dispatch := s.getDispatch()
+	if dispatch == nil {
+		s.storeSearchStats(statSearchPaths(s.FS, pending))
+		return true
+	}
+
+	if s.searchStatsInFlight == nil {
+		s.searchStatsInFlight = make(map[string]bool)
+	}
+	for _, path := range pending {
+		s.searchStatsInFlight[path] = true
+	}
+	fsys := s.FS
+	go func() {
+		dispatch(GlobalSearchStatsAction{Stats: statSearchPaths(fsys, pending)})
+	}()
+	return false
+}
+
+// statSearchPaths reads size and mtime of paths through fsys, the backend
+// the results were listed from.
+func statSearchPaths(fsys fsutil.FS, paths []string) map[string]searchStat {
+	fsys = fsutil.OrLocal(fsys)
+	stats := make(map[string]searchStat, len(paths))
+	for _, path := range paths {
+		info, err := fsys.Lstat(path)
+		if err != nil {
+			// Keep a zero entry so vanished files are not retried.
+			stats[path] = searchStat{}
+			continue
+		}
+		stats[path] = searchStat{size: info.Size(), modTime: info.ModTime()}
+	}
+	return stats
+}
+
+func (s *AppState) storeSearchStats(stats map[string]searchStat) {
+	if s.searchStats == nil {
+		s.searchStats = make(map[string]searchStat, len(stats))
+	}
+	for path, stat := range stats {
+		s.searchStats[path] = stat
+		delete(s.searchStatsInFlight, path)
+	}
+}
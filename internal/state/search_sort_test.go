@@ -0,0 +1,116 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestGlobalSearchSortCyclesAndKeepsSelection(t *testing.T) {
+	now := time.Now()
+	results := []GlobalSearchResult{
+		{FilePath: "/r/b.txt", Score: 3, FileEntry: FileEntry{Name: "b.txt", Size: 10, Modified: now.Add(-time.Hour)}},
+		{FilePath: "/r/a.txt", Score: 2, FileEntry: FileEntry{Name: "a.txt", Size: 30, Modified: now.Add(-2 * time.Hour)}},
+		{FilePath: "/r/c.txt", Score: 1, FileEntry: FileEntry{Name: "c.txt", Size: 20, Modified: now}},
+	}
+	state := &AppState{GlobalSearchActive: true, ScreenHeight: 30}
+	reducer := NewStateReducer()
+	if _, err := reducer.Reduce(state, GlobalSearchResultsAction{Results: results}); err != nil {
+		t.Fatalf("results: %v", err)
+	}
+	state.GlobalSearchIndex = 1 // a.txt
+
+	order := func() []string {
+		var names []string
+		for _, r := range state.GlobalSearchResults {
+			names = append(names, r.FileEntry.Name)
+		}
+		return names
+	}
+	want := map[SearchSortMode][]string{
+		SearchSortPath:     {"a.txt", "b.txt", "c.txt"},
+		SearchSortModified: {"c.txt", "b.txt", "a.txt"},
+		SearchSortSize:     {"a.txt", "c.txt", "b.txt"},
+		SearchSortScore:    {"b.txt", "a.txt", "c.txt"},
+	}
+	for _, mode := range []SearchSortMode{SearchSortPath, SearchSortModified, SearchSortSize, SearchSortScore} {
+		if _, err := reducer.Reduce(state, GlobalSearchCycleSortAction{}); err != nil {
+			t.Fatalf("cycle: %v", err)
+		}
+		if state.GlobalSearchSort != mode {
+			t.Fatalf("expected mode %v, got %v", mode, state.GlobalSearchSort)
+		}
+		got := order()
+		for i := range got {
+			if got[i] != want[mode][i] {
+				t.Fatalf("%v order = %v, want %v", mode, got, want[mode])
+			}
+		}
+		if sel := state.GlobalSearchResults[state.GlobalSearchIndex].FileEntry.Name; sel != "a.txt" {
+			t.Fatalf("%v: selection moved to %s", mode, sel)
+		}
+	}
+}
+
+func TestGlobalSearchSortFetchesMissingStats(t *testing.T) {
+	root := t.TempDir()
+	small := filepath.Join(root, "small")
+	big := filepath.Join(root, "big")
+	if err := os.WriteFile(small, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(big, make([]byte, 100), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	state := &AppState{GlobalSearchActive: true, GlobalSearchSort: SearchSortSize, ScreenHeight: 30}
+	reducer := NewStateReducer()
+	results := []GlobalSearchResult{{FilePath: small}, {FilePath: big}}
+	if _, err := reducer.Reduce(state, GlobalSearchResultsAction{Results: results}); err != nil {
+		t.Fatalf("results: %v", err)
+	}
+	if got := state.GlobalSearchResults[0].FilePath; got != big {
+		t.Fatalf("expected largest first after lazy stat, got %s", got)
+	}
+	if state.GlobalSearchResults[0].FileEntry.Size != 100 {
+		t.Fatalf("expected stat data filled in, got %+v", state.GlobalSearchResults[0].FileEntry)
+	}
+}
+
+func TestGlobalSearchSortMergesStreamedBatches(t *testing.T) {
+	state := &AppState{GlobalSearchActive: true, ScreenHeight: 30}
+	reducer := NewStateReducer()
+	batch := func(paths ...string) []GlobalSearchResult {
+		var results []GlobalSearchResult
+		for i, path := range paths {
+			results = append(results, GlobalSearchResult{FilePath: path, Score: float64(len(paths) - i)})
+		}
+		return results
+	}
+
+	if _, err := reducer.Reduce(state, GlobalSearchResultsAction{Results: batch("/r/d", "/r/b"), InProgress: true}); err != nil {
+		t.Fatal(err)
+	}
+	if &state.GlobalSearchResults[0] != &state.globalSearchRanked[0] {
+		t.Fatal("expected score order to show the ranked results without a copy")
+	}
+
+	state.GlobalSearchSort = SearchSortPath
+	state.resortGlobalSearchResults()
+	if _, err := reducer.Reduce(state, GlobalSearchResultsAction{Results: batch("/r/c", "/r/d", "/r/a", "/r/b", "/r/e")}); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range state.GlobalSearchResults {
+		got = append(got, r.FilePath)
+	}
+	want := []string{"/r/a", "/r/b", "/r/c", "/r/d", "/r/e"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("path order = %v, want %v", got, want)
+	}
+	if state.globalSearchRanked[0].FilePath != "/r/c" {
+		t.Fatalf("expected the ranked list left in score order, got %v", state.globalSearchRanked)
+	}
+}
@@ -0,0 +1,123 @@
+package state
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+)
+
+// SelectMode describes how entries matching a pattern combine with the
+// existing marks.
+type SelectMode int
+
+const (
+	SelectAdd       SelectMode = iota // mark matches
+	SelectSubtract                    // unmark matches
+	SelectIntersect                   // keep only marks that also match
+)
+
+func (m SelectMode) String() string {
+	switch m {
+	case SelectSubtract:
+		return "unmark"
+	case SelectIntersect:
+		return "keep marks"
+	default:
+		return "mark"
+	}
+}
+
+// Next cycles add → subtract → intersect.
+func (m SelectMode) Next() SelectMode {
+	return (m + 1) % 3
+}
+
+// compileNamePattern turns a prompt pattern into a name matcher. "/expr/" is
+// a regular expression, anything else a shell glob. Matching is
+// case-insensitive unless the pattern contains an uppercase letter.
+func compileNamePattern(pattern string) (func(string) bool, error) {
+	caseSensitive := queryHasUppercase(pattern)
+
+	if len(pattern) >= 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		expr := pattern[1 : len(pattern)-1]
+		if !caseSensitive {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+		return re.MatchString, nil
+	}
+
+	if !caseSensitive {
+		pattern = strings.ToLower(pattern)
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid glob: %w", err)
+	}
+	return func(name string) bool {
+		if !caseSensitive {
+			name = strings.ToLower(name)
+		}
+		ok, _ := filepath.Match(pattern, name)
+		return ok
+	}, nil
+}
+
+// applySelectPattern updates the marks from the entries of the current view
+// whose names match pattern and returns how many entries matched.
+func (s *AppState) applySelectPattern(pattern string, mode SelectMode) (int, error) {
+	match, err := compileNamePattern(pattern)
+	if err != nil {
+		return 0, err
+	}
+
+	matched := make(map[string]bool)
+	for _, file := range s.getDisplayFiles() {
+		if match(file.Name) {
+			matched[entryPath(s, &file)] = true
+		}
+	}
+	if err := s.checkBatchScope(pattern, mode, len(matched)); err != nil {
+		return 0, err
+	}
+
+	switch mode {
+	case SelectAdd:
+		for path := range matched {
+			if !s.marks[path] {
+				s.toggleMark(path)
+			}
+		}
+	case SelectSubtract:
+		for path := range matched {
+			delete(s.marks, path)
+		}
+	case SelectIntersect:
+		for path := range s.marks {
+			if !matched[path] {
+				delete(s.marks, path)
+			}
+		}
+	}
+	s.refreshMarkSummary()
+	return len(matched), nil
+}
+
+// checkBatchScope refuses a pattern that marks every entry of a protected
+// directory (the filesystem root, the home directory or a mount root): the
+// marks would stand for the whole directory in a paste or compress.
+func (s *AppState) checkBatchScope(pattern string, mode SelectMode, matched int) error {
+	if mode != SelectAdd || matched < 2 || matched < len(s.getDisplayFiles()) {
+		return nil
+	}
+	reason, ok := protectedScope(s.CurrentPath)
+	if !ok {
+		return nil
+	}
+	return fmt.Errorf("%s matches everything in %s (%s); mark entries individually", pattern, textutil.SanitizeTerminalText(s.CurrentPath), reason)
+}
@@ -0,0 +1,69 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func submitSelectPattern(t *testing.T, state *AppState, reducer *StateReducer, pattern string, cycles int) {
+	t.Helper()
+	actions := []Action{SelectPatternStartAction{}}
+	for i := 0; i < cycles; i++ {
+		actions = append(actions, PromptCycleModeAction{})
+	}
+	for _, ch := range pattern {
+		actions = append(actions, PromptCharAction{Char: ch})
+	}
+	actions = append(actions, PromptSubmitAction{})
+	for _, action := range actions {
+		if _, err := reducer.Reduce(state, action); err != nil {
+			t.Fatalf("%T: %v", action, err)
+		}
+	}
+}
+
+func TestSelectPatternAddSubtractIntersect(t *testing.T) {
+	state, reducer, root := newFixtureState(t, "a.tmp", "b.TMP", "c.go", "d.go")
+	path := func(name string) string { return filepath.Join(root, name) }
+
+	submitSelectPattern(t, state, reducer, "*.tmp", 0)
+	if got := state.MarkedPaths(); len(got) != 2 || got[0] != path("a.tmp") || got[1] != path("b.TMP") {
+		t.Fatalf("after add: %v", got)
+	}
+
+	submitSelectPattern(t, state, reducer, "/^c/", 0)
+	submitSelectPattern(t, state, reducer, "b*", 1)
+	if got := state.MarkedPaths(); len(got) != 2 || got[0] != path("a.tmp") || got[1] != path("c.go") {
+		t.Fatalf("after subtract: %v", got)
+	}
+
+	submitSelectPattern(t, state, reducer, "/\\.go$/", 2)
+	if got := state.MarkedPaths(); len(got) != 1 || got[0] != path("c.go") {
+		t.Fatalf("after intersect: %v", got)
+	}
+	if state.Prompt != nil {
+		t.Fatalf("expected prompt to close after submit")
+	}
+}
+
+func TestSelectPatternUppercaseIsCaseSensitive(t *testing.T) {
+	state, reducer, _ := newFixtureState(t, "a.tmp", "b.TMP")
+	submitSelectPattern(t, state, reducer, "*.TMP", 0)
+	if state.MarkCount() != 1 {
+		t.Fatalf("expected only b.TMP marked, got %v", state.MarkedPaths())
+	}
+}
+
+func TestSelectPatternInvalidRegexKeepsPromptOpen(t *testing.T) {
+	state, reducer, _ := newFixtureState(t, "a.tmp")
+	submitSelectPattern(t, state, reducer, "/(/", 0)
+	if state.Prompt == nil || state.Prompt.Err == "" {
+		t.Fatalf("expected prompt to stay open with an error, got %+v", state.Prompt)
+	}
+	if state.MarkCount() != 0 {
+		t.Fatalf("expected no marks, got %v", state.MarkedPaths())
+	}
+	if _, err := reducer.Reduce(state, PromptCancelAction{}); err != nil || state.Prompt != nil {
+		t.Fatalf("expected cancel to close prompt")
+	}
+}
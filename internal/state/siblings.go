@@ -0,0 +1,118 @@
+package state
+
+import (
+	"path/filepath"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// siblingDirectory returns the directory next to the current one in the
+// parent's listing (delta +1 for next, -1 for previous), using the parent
+// entries shown in the sidebar so hidden-file rules and ordering match.
+func (s *AppState) siblingDirectory(delta int) (string, bool) {
+	current := s.navigationPath()
+	parent := filepath.Dir(current)
+	if parent == current || parent != filepath.Dir(s.CurrentPath) {
+		return "", false
+	}
+	name := filepath.Base(current)
+
+	var dirs []FileEntry
+	pos := -1
+	for _, entry := range s.ParentEntries {
+		if !entry.IsDir {
+			continue
+		}
+		if entry.Name == name {
+			pos = len(dirs)
+		}
+		dirs = append(dirs, entry)
+	}
+	if pos < 0 {
+		return "", false
+	}
+
+	next := pos + delta
+	if next < 0 || next >= len(dirs) {
+		return "", false
+	}
+	return filepath.Join(parent, dirs[next].Name), true
+}
+
+// goToSibling switches to the next/previous sibling directory, restoring the
+// selection remembered for it.
+func (r *StateReducer) goToSibling(state *AppState, delta int) (*AppState, error) {
+	target, ok := state.siblingDirectory(delta)
+	if !ok {
+		return state, nil
+	}
+	return r.openSibling(state, target)
+}
+
+// openSibling opens target in place of the current directory.
+func (r *StateReducer) openSibling(state *AppState, target string) (*AppState, error) {
+	r.selectionHistory[state.CurrentPath] = state.SelectedIndex
+
+	loading, err := r.changeDirectoryWithStatus(state, target)
+	if err != nil {
+		return state, err
+	}
+
+	post := func(r *StateReducer, state *AppState) error {
+		state.clearGlobalSearch(false)
+
+		if savedIdx, ok := r.selectionHistory[target]; ok && savedIdx < len(state.Files) {
+			state.SelectedIndex = savedIdx
+			r.ensureSelectionVisible(state)
+		}
+
+		state.centerScrollOnSelection()
+		r.addToHistory(state, target)
+		return r.generatePreview(state)
+	}
+
+	return r.completeDirectoryChange(state, loading, post)
+}
+
+// ParentPaneWindow returns the range of ParentEntries the parent pane shows
+// in rows rows, centred on the directory being shown, and that directory's
+// index, or -1 when it is not listed. While a sibling is loading it is the
+// one marked, so the pane follows the selection at once.
+func (s *AppState) ParentPaneWindow(rows int) (start, end, current int) {
+	entries := s.ParentEntries
+	name := norm.NFC.String(filepath.Base(s.CurrentPath))
+	if next := s.navigationPath(); filepath.Dir(next) == filepath.Dir(s.CurrentPath) {
+		name = norm.NFC.String(filepath.Base(next))
+	}
+	current = -1
+	for idx, entry := range entries {
+		if entry.Name == name {
+			current = idx
+			break
+		}
+	}
+
+	rows = max(rows, 1)
+	if len(entries) > rows {
+		start = min(max(current-rows/2, 0), len(entries)-rows)
+	}
+	return start, min(start+rows, len(entries)), current
+}
+
+// selectParentEntry acts on entry idx of the parent pane: a sibling
+// directory opens in place of the current one, and anything else, the
+// current directory included, goes up to the parent with it selected.
+func (r *StateReducer) selectParentEntry(state *AppState, idx int) (*AppState, error) {
+	if idx < 0 || idx >= len(state.ParentEntries) {
+		return state, nil
+	}
+	parent := filepath.Dir(state.CurrentPath)
+	if parent == state.CurrentPath {
+		return state, nil
+	}
+	entry := state.ParentEntries[idx]
+	if _, _, current := state.ParentPaneWindow(1); idx != current && entry.IsDir {
+		return r.openSibling(state, filepath.Join(parent, entry.Name))
+	}
+	return r.openAndSelect(state, parent, entry.Name)
+}
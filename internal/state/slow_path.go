@@ -0,0 +1,104 @@
+package state
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+)
+
+// EnvSlowPaths controls the slow-path profile: "auto" (default) detects
+// network mounts, "off" disables it, and absolute paths are always treated as
+// slow, e.g. "auto,/mnt/archive".
+const EnvSlowPaths = "RDIR_SLOW_PATHS"
+
+// SlowPathConfig says which directories get the slow-path profile.
+type SlowPathConfig struct {
+	Detect   bool     // look up the mount type of every directory entered
+	Prefixes []string // cleaned absolute paths that are always slow
+}
+
+// PathProfile is the set of behaviors that change on slow (network) paths.
+type PathProfile struct {
+	PrefetchAncestors  bool          // read parent listings in the background
+	PreviewDebounce    time.Duration // delay before loading the selection's preview
+	StatSymlinkTargets bool          // stat symlink targets on the UI goroutine
+}
+
+var (
+	defaultPathProfile = PathProfile{PrefetchAncestors: true, PreviewDebounce: previewDebounceDelay, StatSymlinkTargets: true}
+	slowPathProfile    = PathProfile{PreviewDebounce: 400 * time.Millisecond}
+)
+
+var networkMount = fsutil.NetworkMount
+
+// LoadSlowPathConfig reads RDIR_SLOW_PATHS. Invalid entries are skipped and
+// reported in the returned error.
+func LoadSlowPathConfig(getenv func(string) string) (SlowPathConfig, error) {
+	cfg := SlowPathConfig{Detect: true}
+	var problems []string
+	for _, item := range strings.Split(getenv(EnvSlowPaths), ",") {
+		item = strings.TrimSpace(item)
+		switch strings.ToLower(item) {
+		case "":
+			continue
+		case "auto":
+			cfg.Detect = true
+			continue
+		case "off", "none":
+			cfg = SlowPathConfig{}
+			continue
+		}
+		if !filepath.IsAbs(item) {
+			problems = append(problems, fmt.Sprintf("%q", item))
+			continue
+		}
+		cfg.Prefixes = append(cfg.Prefixes, filepath.Clean(item))
+	}
+	if len(problems) > 0 {
+		return cfg, fmt.Errorf("ignoring invalid %s entries: %s (use auto, off or absolute paths)", EnvSlowPaths, strings.Join(problems, ", "))
+	}
+	return cfg, nil
+}
+
+// classify returns a label for dir when it is slow: the network filesystem
+// type, or "slow" for a configured prefix.
+func (c SlowPathConfig) classify(dir string) (string, bool) {
+	if dir == "" {
+		return "", false
+	}
+	dir = filepath.Clean(dir)
+	for _, prefix := range c.Prefixes {
+		if rel, err := filepath.Rel(prefix, dir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "slow", true
+		}
+	}
+	if c.Detect {
+		return networkMount(dir)
+	}
+	return "", false
+}
+
+// updateSlowPath re-classifies the current directory after it changes.
+func (s *AppState) updateSlowPath() {
+	s.SlowPathKind, s.SlowPath = s.SlowPaths.classify(s.CurrentPath)
+}
+
+// pathProfile returns the behaviors for the current directory. Eco mode
+// additionally stops prefetching and stretches the preview debounce.
+func (s *AppState) pathProfile() PathProfile {
+	if s == nil {
+		return defaultPathProfile
+	}
+	profile := defaultPathProfile
+	if s.SlowPath {
+		profile = slowPathProfile
+	}
+	if s.EcoActive {
+		profile.PrefetchAncestors = false
+		profile.PreviewDebounce = max(profile.PreviewDebounce, ecoPreviewDebounce)
+	}
+	return profile
+}
@@ -0,0 +1,53 @@
+package state
+
+import "testing"
+
+func TestLoadSlowPathConfig(t *testing.T) {
+	cfg, err := LoadSlowPathConfig(func(string) string { return "" })
+	if err != nil || !cfg.Detect || len(cfg.Prefixes) != 0 {
+		t.Fatalf("default config = %+v, %v", cfg, err)
+	}
+	cfg, err = LoadSlowPathConfig(func(string) string { return "off, /mnt/archive/, relative" })
+	if err == nil {
+		t.Fatalf("expected relative path to be reported")
+	}
+	if cfg.Detect || len(cfg.Prefixes) != 1 || cfg.Prefixes[0] != "/mnt/archive" {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+}
+
+func TestSlowPathProfileFollowsCurrentDirectory(t *testing.T) {
+	original := networkMount
+	defer func() { networkMount = original }()
+	networkMount = func(path string) (string, bool) {
+		if path == "/net/home" {
+			return "nfs4", true
+		}
+		return "ext4", false
+	}
+
+	state := &AppState{SlowPaths: SlowPathConfig{Detect: true, Prefixes: []string{"/mnt/archive"}}}
+	cases := []struct {
+		dir  string
+		slow bool
+		kind string
+	}{
+		{"/net/home", true, "nfs4"},
+		{"/mnt/archive/2019", true, "slow"},
+		{"/mnt/archived", false, ""},
+		{"/home/user", false, ""},
+	}
+	for _, tc := range cases {
+		applyDirectoryEntries(state, tc.dir, nil)
+		if state.SlowPath != tc.slow || (tc.slow && state.SlowPathKind != tc.kind) {
+			t.Fatalf("%s: slow=%v kind=%q", tc.dir, state.SlowPath, state.SlowPathKind)
+		}
+		profile := state.pathProfile()
+		if tc.slow && (profile.PrefetchAncestors || profile.StatSymlinkTargets || profile.PreviewDebounce <= previewDebounceDelay) {
+			t.Fatalf("%s: expected the conservative profile, got %+v", tc.dir, profile)
+		}
+		if !tc.slow && profile != defaultPathProfile {
+			t.Fatalf("%s: expected the default profile, got %+v", tc.dir, profile)
+		}
+	}
+}
@@ -0,0 +1,429 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+)
+
+// StagingMode describes what pasting the staged paths will do.
+type StagingMode string
+
+const (
+	StagingNone StagingMode = ""
+	StagingCut  StagingMode = "cut"
+	StagingCopy StagingMode = "copy"
+)
+
+// stagingPanelMaxEntries caps how many staged paths the panel lists.
+const stagingPanelMaxEntries = 4
+
+// EnvStagingFile overrides where the staging area is shared between instances.
+const EnvStagingFile = "RDIR_STAGING_FILE"
+
+// StagingArea is the internal "clipboard" of paths waiting to be pasted.
+type StagingArea struct {
+	Mode  StagingMode `json:"mode"`
+	Paths []string    `json:"paths"`
+}
+
+// Empty reports whether nothing is staged.
+func (a StagingArea) Empty() bool {
+	return len(a.Paths) == 0
+}
+
+// Contains reports whether path is staged.
+func (a StagingArea) Contains(path string) bool {
+	for _, p := range a.Paths {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// toggle stages path in mode, or unstages it when it is already staged in the
+// same mode. Switching modes starts a fresh set.
+func (a *StagingArea) toggle(mode StagingMode, path string) {
+	if path == "" {
+		return
+	}
+	if a.Mode != mode {
+		a.Mode = mode
+		a.Paths = nil
+	}
+	for i, p := range a.Paths {
+		if p == path {
+			a.Paths = append(a.Paths[:i], a.Paths[i+1:]...)
+			if len(a.Paths) == 0 {
+				a.Mode = StagingNone
+			}
+			return
+		}
+	}
+	a.Paths = append(a.Paths, path)
+}
+
+// entryPath returns the on-disk path of an entry in the current listing.
+func entryPath(state *AppState, file *FileEntry) string {
+	if file.FullPath != "" {
+		return file.FullPath
+	}
+	return filepath.Join(state.CurrentPath, file.Name)
+}
+
+// IsStaged reports whether an entry of the current listing is staged.
+func (s *AppState) IsStaged(file *FileEntry) bool {
+	if file == nil || s.Staging.Empty() {
+		return false
+	}
+	return s.Staging.Contains(entryPath(s, file))
+}
+
+// StagingPanelRows reports how many rows at the bottom of the file list the
+// staging panel occupies (title plus one row per listed path). The panel never
+// takes more than half of the list so browsing stays usable.
+func (s *AppState) StagingPanelRows() int {
+	if s.Staging.Empty() || s.GlobalSearchActive {
+		return 0
+	}
+	rows := 1 + min(len(s.Staging.Paths), stagingPanelMaxEntries)
+	if limit := s.listRows() / 2; rows > limit {
+		rows = limit
+	}
+	if rows < 0 {
+		return 0
+	}
+	return rows
+}
+
+// DefaultStagingFile returns the shared staging file location, or "" when
+// RDIR_STAGING_FILE is "off" and staged entries stay in this process.
+func DefaultStagingFile(getenv func(string) string) string {
+	if path := getenv(EnvStagingFile); path != "" {
+		if path == "off" {
+			return ""
+		}
+		return path
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil || dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "rdir", "staging.json")
+}
+
+// LoadStagingFile reads the shared staging area. A missing file yields an
+// empty area; paths that no longer exist are dropped.
+func LoadStagingFile(path string) (StagingArea, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return StagingArea{}, nil
+	}
+	if err != nil {
+		return StagingArea{}, err
+	}
+
+	var area StagingArea
+	if err := json.Unmarshal(data, &area); err != nil {
+		return StagingArea{}, fmt.Errorf("invalid staging file %s: %w", path, err)
+	}
+	if area.Mode != StagingCut && area.Mode != StagingCopy {
+		return StagingArea{}, nil
+	}
+
+	existing := area.Paths[:0]
+	for _, p := range area.Paths {
+		if _, err := os.Lstat(p); err == nil {
+			existing = append(existing, p)
+		}
+	}
+	area.Paths = existing
+	if len(area.Paths) == 0 {
+		area.Mode = StagingNone
+	}
+	return area, nil
+}
+
+// SaveStagingFile atomically replaces the shared staging file; an empty area
+// removes it.
+func SaveStagingFile(path string, area StagingArea) error {
+	if area.Empty() {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(area)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".staging-*.json")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpName)
+		return err
+	}
+	return os.Rename(tmpName, path)
+}
+
+// UpdateStagingFile applies update to the shared staging area while holding
+// a lock on it from reading the file until the result is written back, so
+// instances changing it at once do not drop each other's entries. A file
+// that cannot be read is replaced, and its error returned.
+func UpdateStagingFile(path string, update func(StagingArea) StagingArea) (StagingArea, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return StagingArea{}, err
+	}
+	unlock, err := fsutil.LockFile(path + ".lock")
+	if err != nil {
+		return StagingArea{}, err
+	}
+	defer unlock()
+
+	area, loadErr := LoadStagingFile(path)
+	area = update(area)
+	if err := SaveStagingFile(path, area); err != nil {
+		return area, err
+	}
+	return area, loadErr
+}
+
+// streamScanLimit caps how many entries of a staged directory are checked
+// for alternate streams before a paste.
+const streamScanLimit = 2000
+
+// StreamLoss names an alternate data stream or resource fork a paste would
+// leave behind: copies never keep them, and moves only do within one volume.
+// AppleDouble files are only kept when staged too. Path is relative to the
+// staged entry's directory.
+type StreamLoss struct {
+	Path   string
+	Stream fsutil.AltStream
+}
+
+// confirmPasteStreamLoss asks whether to paste despite loss; confirming
+// replays the paste with every earlier question answered.
+func (s *AppState) confirmPasteStreamLoss(loss StreamLoss, resolutions *ConflictResolutions) {
+	var prompt string
+	if loss.Stream.Kind == fsutil.StreamAppleDouble {
+		prompt = fmt.Sprintf("Paste leaves %s behind; paste anyway?", textutil.SanitizeTerminalText(loss.Stream.Label()))
+	} else {
+		prompt = fmt.Sprintf("Paste drops the %s of %s; paste anyway?", textutil.SanitizeTerminalText(loss.Stream.Label()), textutil.SanitizeTerminalText(loss.Path))
+	}
+	s.requestConfirm(prompt, PasteStagedAction{
+		Confirmed:         true,
+		ReferencesChecked: true,
+		ScopeConfirmed:    true,
+		StreamsChecked:    true,
+		Resolutions:       resolutions,
+	})
+}
+
+// pasteStreamLoss finds the first staged path, or path inside a staged
+// directory, whose stream pasting into destDir would lose. It stops early
+// once ctx is canceled.
+func pasteStreamLoss(ctx context.Context, area StagingArea, destDir string) (StreamLoss, bool) {
+	for _, src := range area.Paths {
+		if ctx.Err() != nil {
+			break
+		}
+		dir := filepath.Dir(src)
+		if area.Mode == StagingCut && dir == filepath.Clean(destDir) {
+			continue
+		}
+		renamed := area.Mode == StagingCut && fsutil.SameVolume(dir, destDir)
+		for _, stream := range fsutil.AlternateStreams(src) {
+			if stream.Kind == fsutil.StreamAppleDouble {
+				if !area.Contains(filepath.Join(dir, stream.Name)) {
+					return StreamLoss{Path: filepath.Base(src), Stream: stream}, true
+				}
+				continue
+			}
+			if !renamed {
+				return StreamLoss{Path: filepath.Base(src), Stream: stream}, true
+			}
+		}
+		if renamed {
+			continue
+		}
+		if info, err := os.Lstat(src); err != nil || !info.IsDir() {
+			continue
+		}
+		if path, ok := fsutil.FindNativeStreams(src, streamScanLimit); ok {
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				rel = path
+			}
+			if streams := fsutil.AlternateStreams(path); len(streams) > 0 {
+				return StreamLoss{Path: rel, Stream: streams[0]}, true
+			}
+		}
+	}
+	return StreamLoss{}, false
+}
+
+// PasteResult describes the outcome of PasteStaging.
+type PasteResult struct {
+	Remaining  StagingArea // cut entries that moved are removed, copies stay staged
+	Moved      []string    // cut entries that moved, to unstage
+	SelectName string      // first pasted entry, for selection
+	Pasted     int         // entries moved or copied successfully
+	Skipped    int         // entries left alone because their name was taken
+	Canceled   bool        // stopped before every entry was pasted
+	Err        error       // first error encountered
+	Audit      []AuditEntry
+}
+
+// Unstage drops the entries that moved from area, the staging area as it is
+// now rather than when the paste started.
+func (r PasteResult) Unstage(area StagingArea) StagingArea {
+	if len(r.Moved) == 0 {
+		return area
+	}
+	moved := make(map[string]struct{}, len(r.Moved))
+	for _, path := range r.Moved {
+		moved[path] = struct{}{}
+	}
+	kept := StagingArea{Mode: area.Mode}
+	for _, path := range area.Paths {
+		if _, ok := moved[path]; !ok {
+			kept.Paths = append(kept.Paths, path)
+		}
+	}
+	if len(kept.Paths) == 0 {
+		kept.Mode = StagingNone
+	}
+	return kept
+}
+
+// PasteStaging moves or copies every staged path into destDir. Entries whose
+// name is taken follow resolutions; without any they get a fresh name.
+func PasteStaging(area StagingArea, destDir string, resolutions *ConflictResolutions) PasteResult {
+	return pasteStaging(context.Background(), area, destDir, resolutions, nil)
+}
+
+// RunPaste pastes a.Area into a.DestDir, sending an ArchiveProgressAction
+// through send before each entry. Canceling ctx stops it between entries;
+// the rest stay staged. Unless a.StreamsChecked is set, a stream the paste
+// would leave behind stops it first and comes back as StreamLoss.
+func RunPaste(ctx context.Context, a PasteJobAction, send func(Action)) PasteDoneAction {
+	if !a.StreamsChecked {
+		if loss, ok := pasteStreamLoss(ctx, a.Area, a.DestDir); ok {
+			return PasteDoneAction{StreamLoss: &loss, Resolutions: a.Resolutions}
+		}
+	}
+	return PasteDoneAction{Result: pasteStaging(ctx, a.Area, a.DestDir, a.Resolutions, send)}
+}
+
+func pasteStaging(ctx context.Context, area StagingArea, destDir string, resolutions *ConflictResolutions, send func(Action)) PasteResult {
+	remaining := StagingArea{Mode: area.Mode}
+	var moved []string
+	firstName := ""
+	pasted, skipped := 0, 0
+	canceled := false
+	var firstErr error
+	var audit []AuditEntry
+
+	op, verb := fsutil.CopyPath, "copy"
+	if area.Mode == StagingCut {
+		op, verb = fsutil.MovePath, "move"
+	}
+	for i, src := range area.Paths {
+		if canceled || ctx.Err() != nil {
+			canceled = true
+			remaining.Paths = append(remaining.Paths, src)
+			continue
+		}
+		if send != nil {
+			send(ArchiveProgressAction{Index: i})
+		}
+		if area.Mode == StagingCut && filepath.Dir(src) == filepath.Clean(destDir) {
+			// Moving into the same directory is a no-op; keep it staged.
+			remaining.Paths = append(remaining.Paths, src)
+			continue
+		}
+
+		dst, replace, ok := pasteTarget(src, destDir, resolutions)
+		if !ok {
+			skipped++
+			remaining.Paths = append(remaining.Paths, src)
+			continue
+		}
+		var err error
+		if replace {
+			err = fsutil.ReplacePath(src, dst, op)
+		} else {
+			err = op(src, dst)
+		}
+		audit = append(audit, NewAuditEntry(verb, src, dst, err))
+
+		if err != nil {
+			remaining.Paths = append(remaining.Paths, src)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("paste %s: %w", filepath.Base(src), err)
+			}
+			continue
+		}
+		pasted++
+		if area.Mode == StagingCopy {
+			remaining.Paths = append(remaining.Paths, src)
+		} else {
+			moved = append(moved, src)
+		}
+		if firstName == "" {
+			firstName = filepath.Base(dst)
+		}
+	}
+
+	if len(remaining.Paths) == 0 {
+		remaining.Mode = StagingNone
+	}
+	return PasteResult{Remaining: remaining, Moved: moved, SelectName: firstName, Pasted: pasted, Skipped: skipped, Canceled: canceled, Err: firstErr, Audit: audit}
+}
+
+// pasteTarget returns where src goes in destDir and whether that replaces
+// an existing entry; ok is false when src is skipped.
+func pasteTarget(src, destDir string, resolutions *ConflictResolutions) (dst string, replace, ok bool) {
+	name := filepath.Base(src)
+	dst = filepath.Join(destDir, name)
+	existing, err := os.Lstat(dst)
+	if err != nil {
+		return dst, false, true
+	}
+	if filepath.Dir(src) == filepath.Clean(destDir) {
+		return fsutil.UniqueDestination(destDir, name), false, true
+	}
+	switch resolutions.Resolve(src) {
+	case ConflictSkip:
+		return dst, false, false
+	case ConflictOverwrite:
+		return dst, true, true
+	case ConflictKeepNewer:
+		info, err := os.Lstat(src)
+		if err != nil || !info.ModTime().After(existing.ModTime()) {
+			return dst, false, false
+		}
+		return dst, true, true
+	default:
+		return fsutil.PatternDestination(destDir, name, resolutions.renamePattern(), existing.IsDir()), false, true
+	}
+}
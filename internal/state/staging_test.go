@@ -0,0 +1,366 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestStageActionsToggleAndSwitchMode(t *testing.T) {
+	reducer := NewStateReducer()
+	state := &AppState{
+		CurrentPath:  "/tmp/work",
+		Files:        []FileEntry{{Name: "a.txt"}, {Name: "b.txt"}},
+		ScreenHeight: 40,
+	}
+
+	if _, err := reducer.Reduce(state, StageCutAction{}); err != nil {
+		t.Fatalf("stage cut: %v", err)
+	}
+	if state.Staging.Mode != StagingCut || !state.Staging.Contains("/tmp/work/a.txt") {
+		t.Fatalf("unexpected staging after cut: %+v", state.Staging)
+	}
+	if !state.IsStaged(&state.Files[0]) || state.IsStaged(&state.Files[1]) {
+		t.Fatalf("IsStaged mismatch: %+v", state.Staging)
+	}
+
+	if _, err := reducer.Reduce(state, StageCutAction{}); err != nil {
+		t.Fatalf("unstage cut: %v", err)
+	}
+	if !state.Staging.Empty() || state.Staging.Mode != StagingNone {
+		t.Fatalf("expected toggling again to unstage, got %+v", state.Staging)
+	}
+
+	_, _ = reducer.Reduce(state, StageCutAction{})
+	state.SelectedIndex = 1
+	_, _ = reducer.Reduce(state, StageCopyAction{})
+	if state.Staging.Mode != StagingCopy || len(state.Staging.Paths) != 1 || !state.Staging.Contains("/tmp/work/b.txt") {
+		t.Fatalf("switching mode should start a fresh set, got %+v", state.Staging)
+	}
+
+	_, _ = reducer.Reduce(state, ClearStagingAction{})
+	if !state.Staging.Empty() {
+		t.Fatalf("expected clear to empty staging, got %+v", state.Staging)
+	}
+}
+
+func TestStagingPanelShrinksVisibleLines(t *testing.T) {
+	state := &AppState{ScreenHeight: 30}
+	before := state.visibleLines()
+
+	state.Staging = StagingArea{Mode: StagingCut, Paths: []string{"/a", "/b", "/c", "/d", "/e", "/f"}}
+	if got := state.StagingPanelRows(); got != 1+stagingPanelMaxEntries {
+		t.Fatalf("StagingPanelRows() = %d, want %d", got, 1+stagingPanelMaxEntries)
+	}
+	if got := state.visibleLines(); got != before-1-stagingPanelMaxEntries {
+		t.Fatalf("visibleLines() = %d, want %d", got, before-1-stagingPanelMaxEntries)
+	}
+
+	state.GlobalSearchActive = true
+	if got := state.StagingPanelRows(); got != 0 {
+		t.Fatalf("panel should hide during global search, got %d rows", got)
+	}
+}
+
+func TestStagingFileRoundTripDropsMissingPaths(t *testing.T) {
+	dir := t.TempDir()
+	kept := filepath.Join(dir, "kept.txt")
+	if err := os.WriteFile(kept, nil, 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	file := filepath.Join(dir, "state", "staging.json")
+
+	area := StagingArea{Mode: StagingCopy, Paths: []string{kept, filepath.Join(dir, "gone.txt")}}
+	if err := SaveStagingFile(file, area); err != nil {
+		t.Fatalf("SaveStagingFile: %v", err)
+	}
+	loaded, err := LoadStagingFile(file)
+	if err != nil {
+		t.Fatalf("LoadStagingFile: %v", err)
+	}
+	if loaded.Mode != StagingCopy || len(loaded.Paths) != 1 || loaded.Paths[0] != kept {
+		t.Fatalf("unexpected loaded area: %+v", loaded)
+	}
+
+	if err := SaveStagingFile(file, StagingArea{}); err != nil {
+		t.Fatalf("SaveStagingFile(empty): %v", err)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Fatalf("empty staging should remove the file, stat err = %v", err)
+	}
+}
+
+func TestPasteStagingMovesAndCopies(t *testing.T) {
+	srcDir := t.TempDir()
+	destDir := t.TempDir()
+	moved := filepath.Join(srcDir, "moved.txt")
+	copied := filepath.Join(srcDir, "copied.txt")
+	for _, p := range []string{moved, copied} {
+		if err := os.WriteFile(p, []byte("x"), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	result := PasteStaging(StagingArea{Mode: StagingCut, Paths: []string{moved}}, destDir, nil)
+	if result.Err != nil {
+		t.Fatalf("paste cut: %v", result.Err)
+	}
+	if !result.Remaining.Empty() || result.SelectName != "moved.txt" || result.Pasted != 1 {
+		t.Fatalf("unexpected cut result: %+v", result)
+	}
+	if _, err := os.Stat(moved); !os.IsNotExist(err) {
+		t.Fatalf("cut source should be gone, stat err = %v", err)
+	}
+
+	area := StagingArea{Mode: StagingCopy, Paths: []string{copied}}
+	_ = PasteStaging(area, destDir, nil)
+	result = PasteStaging(area, destDir, nil)
+	if result.Err != nil {
+		t.Fatalf("paste copy: %v", result.Err)
+	}
+	if result.SelectName != "copied (1).txt" {
+		t.Fatalf("second copy should get a unique name, got %q", result.SelectName)
+	}
+	if len(result.Remaining.Paths) != 1 || result.Remaining.Mode != StagingCopy {
+		t.Fatalf("copies should stay staged, got %+v", result.Remaining)
+	}
+}
+
+func TestUpdateStagingFileKeepsConcurrentChanges(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "state", "staging.json")
+	var paths []string
+	for i := 0; i < 8; i++ {
+		p := filepath.Join(dir, fmt.Sprintf("f%d.txt", i))
+		if err := os.WriteFile(p, nil, 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		paths = append(paths, p)
+	}
+
+	var wg sync.WaitGroup
+	for _, p := range paths {
+		wg.Add(1)
+		go func(p string) {
+			defer wg.Done()
+			_, err := UpdateStagingFile(file, func(area StagingArea) StagingArea {
+				area.toggle(StagingCopy, p)
+				return area
+			})
+			if err != nil {
+				t.Errorf("UpdateStagingFile: %v", err)
+			}
+		}(p)
+	}
+	wg.Wait()
+
+	area, err := LoadStagingFile(file)
+	if err != nil {
+		t.Fatalf("LoadStagingFile: %v", err)
+	}
+	if len(area.Paths) != len(paths) {
+		t.Fatalf("expected every instance's entry to survive, got %v", area.Paths)
+	}
+}
+
+func TestRunPasteStopsWhenCanceledAndUnstagesOnlyMovedEntries(t *testing.T) {
+	srcDir := t.TempDir()
+	destDir := t.TempDir()
+	first := filepath.Join(srcDir, "first.txt")
+	second := filepath.Join(srcDir, "second.txt")
+	for _, p := range []string{first, second} {
+		if err := os.WriteFile(p, []byte("x"), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var progress []int
+	send := func(a Action) {
+		if p, ok := a.(ArchiveProgressAction); ok {
+			progress = append(progress, p.Index)
+			cancel()
+		}
+	}
+	job := PasteJobAction{Area: StagingArea{Mode: StagingCut, Paths: []string{first, second}}, DestDir: destDir}
+	done := RunPaste(ctx, job, send)
+	if !done.Result.Canceled || done.Result.Pasted != 1 || len(progress) != 1 {
+		t.Fatalf("expected one entry moved before the cancel, got %+v (progress %v)", done.Result, progress)
+	}
+	if _, err := os.Stat(second); err != nil {
+		t.Fatalf("expected the second entry to stay put: %v", err)
+	}
+
+	// Another instance staged a file while the paste ran.
+	other := filepath.Join(srcDir, "other.txt")
+	now := StagingArea{Mode: StagingCut, Paths: []string{first, second, other}}
+	kept := done.Result.Unstage(now)
+	if len(kept.Paths) != 2 || kept.Contains(first) || !kept.Contains(other) {
+		t.Fatalf("expected only the moved entry unstaged, got %+v", kept)
+	}
+}
+
+func TestPasteJobAsksForAppleDoubleFiles(t *testing.T) {
+	srcDir := t.TempDir()
+	destDir := t.TempDir()
+	photo := filepath.Join(srcDir, "photo.jpg")
+	sidecar := filepath.Join(srcDir, "._photo.jpg")
+	for _, p := range []string{photo, sidecar} {
+		if err := os.WriteFile(p, []byte("x"), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	resolutions := &ConflictResolutions{}
+	job := PasteJobAction{Area: StagingArea{Mode: StagingCopy, Paths: []string{photo}}, DestDir: destDir, Resolutions: resolutions}
+	done := RunPaste(context.Background(), job, nil)
+	if done.StreamLoss == nil || done.Result.Pasted != 0 {
+		t.Fatalf("expected the job to stop before pasting, got %+v", done)
+	}
+	if _, err := os.Stat(filepath.Join(destDir, "photo.jpg")); !os.IsNotExist(err) {
+		t.Fatalf("expected nothing pasted yet, stat err=%v", err)
+	}
+
+	state := &AppState{CurrentPath: destDir, Staging: job.Area, ArchiveJob: &ArchiveJob{Verb: "copying"}}
+	if _, err := NewStateReducer().Reduce(state, done); err != nil {
+		t.Fatalf("reduce: %v", err)
+	}
+	if state.ArchiveJob != nil {
+		t.Fatalf("expected the job cleared")
+	}
+	want := PasteStagedAction{Confirmed: true, ReferencesChecked: true, ScopeConfirmed: true, StreamsChecked: true, Resolutions: resolutions}
+	if state.PendingConfirm == nil || state.PendingConfirm.Action != want {
+		t.Fatalf("expected a checked paste to be replayed, got %+v", state.PendingConfirm)
+	}
+	if want := "Paste leaves ._photo.jpg (AppleDouble) behind; paste anyway?"; state.PendingConfirm.Prompt != want {
+		t.Fatalf("prompt = %q, want %q", state.PendingConfirm.Prompt, want)
+	}
+
+	job.StreamsChecked = true
+	if done := RunPaste(context.Background(), job, nil); done.StreamLoss != nil || done.Result.Pasted != 1 {
+		t.Fatalf("expected a checked job to paste, got %+v", done)
+	}
+
+	area := StagingArea{Mode: StagingCopy, Paths: []string{photo, sidecar}}
+	if _, ok := pasteStreamLoss(context.Background(), area, t.TempDir()); ok {
+		t.Fatalf("expected no loss when the AppleDouble file is staged too")
+	}
+
+	// Moving into the directory the file is already in pastes nothing.
+	area = StagingArea{Mode: StagingCut, Paths: []string{photo}}
+	if _, ok := pasteStreamLoss(context.Background(), area, srcDir); ok {
+		t.Fatalf("expected no loss for a no-op move")
+	}
+}
+
+func TestReferenceScanWritesReportApartFromQuickfix(t *testing.T) {
+	project := t.TempDir()
+	destDir := t.TempDir()
+	config := filepath.Join(project, "config.yaml")
+	files := map[string]string{
+		config:                               "port: 80\n",
+		filepath.Join(project, "main.go"):    "// reads config.yaml\n",
+		filepath.Join(project, "README.md"):  "Edit config.yaml, not myconfig.yaml.\n",
+		filepath.Join(project, "notes.txt"):  "nothing here\n",
+		filepath.Join(project, ".gitignore"): "",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	quickfix := filepath.Join(t.TempDir(), "quickfix.txt")
+	if err := os.WriteFile(quickfix, []byte("kept\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	report := filepath.Join(t.TempDir(), "references.txt")
+
+	state := &AppState{
+		CurrentPath:    destDir,
+		QuickfixFile:   quickfix,
+		ReferencesFile: report,
+		Staging:        StagingArea{Mode: StagingCut, Paths: []string{config}},
+	}
+	if state.PasteReferenceScan() != nil {
+		t.Fatalf("expected no scan unless RDIR_MOVE_REFS is set")
+	}
+
+	state.MoveReferences = true
+	scan := state.PasteReferenceScan()
+	if scan == nil {
+		t.Fatalf("expected a scan before moving")
+	}
+	state.ArchiveJob = &ArchiveJob{Verb: "checking references to"}
+	if next := state.FinishReferenceScan(RunReferenceScan(context.Background(), *scan)); next != nil {
+		t.Fatalf("expected a question rather than %T when other files mention the moved name", next)
+	}
+	if state.ArchiveJob != nil || state.PendingConfirm == nil {
+		t.Fatalf("expected the job to end in a question, got job %+v", state.ArchiveJob)
+	}
+	if state.PendingConfirm.Action != (PasteStagedAction{ReferencesChecked: true}) {
+		t.Fatalf("expected the paste to be replayed past the scan, got %+v", state.PendingConfirm.Action)
+	}
+	if want := "config.yaml is mentioned in 2 files; listed in " + report + "; move anyway?"; state.PendingConfirm.Prompt != want {
+		t.Fatalf("prompt = %q, want %q", state.PendingConfirm.Prompt, want)
+	}
+	data, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	want := filepath.Join(project, "README.md") + ":1:6:Edit config.yaml, not myconfig.yaml.\n" +
+		filepath.Join(project, "main.go") + ":1:10:// reads config.yaml\n"
+	if string(data) != want {
+		t.Fatalf("report = %q, want %q", data, want)
+	}
+	if data, _ := os.ReadFile(quickfix); string(data) != "kept\n" {
+		t.Fatalf("expected the quickfix file to be left alone, got %q", data)
+	}
+
+	state.PendingConfirm = nil
+	state.Staging.Mode = StagingCopy
+	if state.PasteReferenceScan() != nil {
+		t.Fatalf("expected copies not to be scanned")
+	}
+}
+
+func TestReferenceScanBeforeRename(t *testing.T) {
+	project := t.TempDir()
+	old := filepath.Join(project, "util.go")
+	for path, content := range map[string]string{old: "package x\n", filepath.Join(project, "notes.txt"): "nothing here\n"} {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rename := RenameEntryAction{From: old, To: filepath.Join(project, "helpers.go")}
+	state := &AppState{CurrentPath: project, MoveReferences: true, ReferencesFile: filepath.Join(t.TempDir(), "references.txt")}
+
+	scan := state.RenameReferenceScan(rename)
+	if scan == nil {
+		t.Fatal("expected a scan before renaming")
+	}
+	next := state.FinishReferenceScan(RunReferenceScan(context.Background(), *scan))
+	rename.ReferencesChecked = true
+	if next != rename {
+		t.Fatalf("expected the rename to go ahead when nothing mentions it, got %#v", next)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if next := state.FinishReferenceScan(RunReferenceScan(ctx, *scan)); next != nil || state.PendingConfirm != nil {
+		t.Fatalf("expected a canceled scan to drop the rename, got %#v", next)
+	}
+}
+
+func TestDefaultStagingFileReadsGetenv(t *testing.T) {
+	env := map[string]string{EnvStagingFile: "/tmp/staged.json"}
+	if got := DefaultStagingFile(func(key string) string { return env[key] }); got != "/tmp/staged.json" {
+		t.Fatalf("DefaultStagingFile = %q", got)
+	}
+	env[EnvStagingFile] = "off"
+	if got := DefaultStagingFile(func(key string) string { return env[key] }); got != "" {
+		t.Fatalf("expected off to disable the shared file, got %q", got)
+	}
+}
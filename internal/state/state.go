@@ -1,6 +1,8 @@
 package state
 
 import (
+	"context"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -43,10 +45,12 @@ type PreviewData struct {
 	TextLineMeta               []TextLineMetadata
 	FormattedTextLines         []string
 	FormattedTextLineMeta      []TextLineMetadata
+	FormattedANSILines         []string // FormattedTextLines with an external formatter's colors
 	FormattedSegments          [][]StyledTextSegment
 	FormattedSegmentLineMeta   []TextLineMetadata
 	FormattedKind              string
 	FormattedUnavailableReason string
+	FormatterStatus            string // why the external formatter's output is missing, e.g. it timed out
 	TextCharCount              int
 	TextTruncated              bool
 	TextBytesRead              int64
@@ -55,8 +59,15 @@ type PreviewData struct {
 	BinaryInfo                 BinaryPreview
 	DirEntries                 []FileEntry
 	HiddenFormattingDetected   bool
+	LineEndings                fsutil.LineEndings // counted over the bytes read for the preview
+	AltStreams                 []fsutil.AltStream // NTFS streams, resource fork, AppleDouble file
+	Shortcut                   *fsutil.Shortcut   // .desktop entry or .lnk shell link
+	Compressed                 *fsutil.Compressed // set when the text shown was decompressed (app.log.gz)
 	MarkdownFrontmatter        map[string]any
 	MarkdownFrontmatterRaw     string
+	Unreadable                 bool        // opening the file was not permitted
+	Privileged                 string      // helper that printed the content (RDIR_PRIVILEGED_HELPER)
+	Reader                     io.ReaderAt // where the pager streams Privileged content from
 
 	markdownDoc *markdownDocument
 }
@@ -87,25 +98,47 @@ type AppState struct {
 
 	// Directory loading
 	DirectoryLoader          DirectoryLoader
+	ReadEntries              EntryReader // lists directories; nil lists FS
+	FS                       fsutil.FS   // filesystem browsed and previewed; nil is the local disk
 	DirectoryLoading         bool
 	DirectoryLoadingPath     string
 	activeDirectoryLoadToken int
 	directoryLoadSeq         int
+	parentLoadToken          int    // async read of ParentEntries in flight
+	parentEntriesPath        string // directory ParentEntries were listed from
+
+	// Hidden entry shown by exact name while HideHiddenFiles is on
+	RevealedName string
 
 	// Selection & viewport
 	SelectedIndex int
 	ScrollOffset  int
 
+	// Column layout (ls -C style), toggled with G; ListWidth is the list
+	// panel width of the last frame, which decides how many columns fit
+	ListGridEnabled bool
+	ListWidth       int
+
 	// Filtering
 	FilterActive        bool
 	FilterQuery         string
+	FilterTail          int          // runes of FilterQuery after the cursor
 	FilteredIndices     []int        // Indices into Files array
 	FilterMatches       []FuzzyMatch // Match metadata aligned with FilteredIndices order
 	FilterSavedIndex    int          // Saved selection index before entering filter mode
 	FilterCaseSensitive bool
+	FoldDiacritics      bool          // filter and global search ignore diacritics (RDIR_FOLD_DIACRITICS)
+	FilterScoring       FilterScoring // score weights and order (RDIR_FILTER_SCORING)
+	FilterScoreDebug    bool          // list shows each match's score (Ctrl+D in the filter)
+	filterScores        map[int]FilterScore
 	filterMatcher       *FuzzyMatcher
 	fileLowerNames      []string
 
+	// Type-ahead jump (select by typed name prefix, no filtering)
+	TypeAheadActive bool
+	TypeAheadPrefix string
+	typeAheadLast   time.Time
+
 	// Global search
 	GlobalSearchActive               bool
 	GlobalSearchQuery                string
@@ -128,6 +161,10 @@ type AppState struct {
 	LastGlobalSearchIndex            int
 	LastGlobalSearchScroll           int
 	LastGlobalSearchSelectionPath    string
+	GlobalSearchSort                 SearchSortMode
+	globalSearchRanked               []GlobalSearchResult // results in searcher order, before GlobalSearchSort
+	searchStats                      map[string]searchStat
+	searchStatsInFlight              map[string]bool
 	dispatchAction                   func(Action)
 
 	// Hidden files
@@ -142,6 +179,15 @@ type AppState struct {
 	PreviewWrapOffset       int
 	PreviewBinaryByteOffset int64
 	PreviewPreferRaw        bool
+	PreviewDefaults         PreviewDefaults // per-extension wrap/raw (RDIR_PREVIEW_EXT)
+	PreviewANSIColors       bool            // pager renders SGR colors found in the file
+	PreviewHideScrollbar    bool
+	PreviewHexUTF8          bool          // hex view decodes UTF-8 in its ASCII column
+	PreviewReadingMode      bool          // pager reflows markdown prose to ReadingWidth
+	ReadingWidth            int           // reading mode column (RDIR_READING_WIDTH)
+	EscapeTimeout           time.Duration // pager wait for the rest of an escape sequence (RDIR_ESC_TIMEOUT)
+	MinContrast             float64       // lowest text/background contrast ratio, 0 when off (RDIR_MIN_CONTRAST)
+	Background              Background    // terminal background (RDIR_BACKGROUND, COLORFGBG)
 	previewCache            map[string]previewCacheEntry
 	previewScrollHistory    map[string]previewScrollPosition
 	previewDebounceTimer    *time.Timer
@@ -149,6 +195,9 @@ type AppState struct {
 	previewPendingPath      string
 	previewPendingReset     bool
 
+	pagerSearchSeed *PagerSearchSeed
+	PagerRecent     []string // files viewed in the pager this session, most recent first
+
 	PreviewLoader          PreviewLoader
 	PreviewLoading         bool
 	PreviewLoadingPath     string
@@ -165,16 +214,94 @@ type AppState struct {
 	ClipboardAvailable bool      // Whether clipboard command is available
 	LastYankTime       time.Time // Time of last successful yank (for flash effect)
 	EditorAvailable    bool      // Whether an editor command is available for 'e'
+	EditorStatus       string    // Where the editor came from, or why there is none
+
+	// Slow (network) paths (RDIR_SLOW_PATHS)
+	SlowPaths    SlowPathConfig
+	SlowPath     bool   // current directory uses the slow-path profile
+	SlowPathKind string // filesystem type shown in the header, e.g. "nfs4"
+
+	// Eco mode (RDIR_ECO): less background work, on battery or when toggled
+	Eco       EcoMode
+	OnBattery bool // last power source check found the machine on battery
+	EcoActive bool
+
+	// Global search index warmed while idle (RDIR_IDLE_INDEX)
+	IdleIndex    IdleIndexConfig
+	warmIndex    *warmIndex
+	warmTooLarge string // directory whose warm-up stopped at the file cap
+
+	// Session sharing: the socket this session shares on (rdir --share) and
+	// the one it follows read-only (rdir --follow).
+	SharingPath string
+	Following   string
+
+	// Enter/→ on files (RDIR_ENTER, RDIR_ENTER_EXT)
+	Enter EnterConfig
+
+	// Wrap-around navigation per area (RDIR_WRAP)
+	Wrap WrapConfig
+
+	// How long names are shortened per column (RDIR_TRUNCATE)
+	Truncate TruncateConfig
+
+	// Pager "copy reference" template (RDIR_COPY_REF)
+	CopyRefTemplate string
+
+	// Where copied paths may be pasted: WSL/Windows and RDIR_PATH_MAP
+	PathContext fsutil.PathContext
+
+	// Pager quickfix export target (RDIR_QUICKFIX); with "-" the entries
+	// are kept in QuickfixOutput and printed to stdout on exit
+	QuickfixFile   string
+	QuickfixOutput []string
+
+	// Look for mentions of moved names before a paste or rename
+	// (RDIR_MOVE_REFS); the hits are listed in ReferencesFile
+	MoveReferences bool
+	ReferencesFile string
+
+	// Command that reads files rdir cannot open (RDIR_PRIVILEGED_HELPER)
+	PrivilegedHelper []string
+
+	// Log of file operations (RDIR_AUDIT_LOG); "" when it is off
+	AuditFile string
+
+	// Cut/copy staging area (shared with other instances via a staging file)
+	Staging StagingArea
+
+	// Marked entries (keyed by path)
+	marks map[string]bool
+
+	// Summary of the marked entries while two or more are marked, shown in
+	// the preview panel; markSummaryCancel stops a count in progress
+	MarkSummary       *MarkSummary
+	markSummaryCancel context.CancelFunc
+
+	// Tabs (empty means a single implicit tab at CurrentPath)
+	Tabs      []Tab
+	ActiveTab int
 
 	// UI overlays
-	HelpVisible bool
+	HelpVisible    bool
+	PendingConfirm *ConfirmRequest
+	Prompt         *TextPrompt
+	Rename         *InlineRename
+	PathChoice     *PathChoice
+	AuditView      *AuditView
+	Conflicts      *ConflictDialog
+
+	// Archive extraction or compression running in the background
+	ArchiveJob *ArchiveJob
 
-	// Error state
-	LastError error
+	// Latest error of each source, shown in the footer banner
+	Errors ErrorBanners
 
 	// Display files cache (optimization to reduce allocations)
 	displayFilesCache []FileEntry
-	displayFilesDirty bool // True if cache is invalid
+	displayFilesDirty bool      // True if cache is invalid
+	displayStats      ViewStats // Aggregates of displayFilesCache, rebuilt with it
+	displayNameWidth  int       // Widest name in displayFilesCache, for the grid
 }
 
 type filterToken struct {
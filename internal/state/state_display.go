@@ -28,7 +28,7 @@ func (s *AppState) getDisplayFiles() []FileEntry {
 	if s.HideHiddenFiles {
 		var visible []FileEntry
 		for _, f := range files {
-			if !f.IsHidden() {
+			if !s.concealed(&f) {
 				visible = append(visible, f)
 			}
 		}
@@ -37,6 +37,8 @@ func (s *AppState) getDisplayFiles() []FileEntry {
 
 	s.displayFilesCache = files
 	s.displayFilesDirty = false
+	s.displayStats = computeViewStats(files)
+	s.displayNameWidth = widestName(files)
 
 	result := make([]FileEntry, len(files))
 	copy(result, files)
@@ -62,14 +64,14 @@ func (s *AppState) getDisplaySelectedIndex() int {
 						if idx == s.SelectedIndex {
 							return visibleCount
 						}
-						if !s.Files[idx].IsHidden() {
+						if !s.concealed(&s.Files[idx]) {
 							visibleCount++
 						}
 					}
 				}
 				return displayIdx
 			}
-			if !s.HideHiddenFiles || !s.Files[fileIdx].IsHidden() {
+			if !s.concealed(&s.Files[fileIdx]) {
 				displayIdx++
 			}
 		}
@@ -77,12 +79,12 @@ func (s *AppState) getDisplaySelectedIndex() int {
 	}
 
 	if s.HideHiddenFiles {
-		if s.Files[s.SelectedIndex].IsHidden() {
+		if s.concealed(&s.Files[s.SelectedIndex]) {
 			return -1
 		}
 		displayIdx := 0
 		for i := 0; i < s.SelectedIndex; i++ {
-			if !s.Files[i].IsHidden() {
+			if !s.concealed(&s.Files[i]) {
 				displayIdx++
 			}
 		}
@@ -106,7 +108,7 @@ func (s *AppState) getActualIndexFromDisplayIndex(displayIdx int) int {
 	if s.FilterActive && s.HideHiddenFiles {
 		visibleCount := 0
 		for _, fileIdx := range s.FilteredIndices {
-			if !s.Files[fileIdx].IsHidden() {
+			if !s.concealed(&s.Files[fileIdx]) {
 				if visibleCount == displayIdx {
 					return fileIdx
 				}
@@ -120,7 +122,7 @@ func (s *AppState) getActualIndexFromDisplayIndex(displayIdx int) int {
 	} else if s.HideHiddenFiles {
 		visibleCount := 0
 		for i := 0; i < len(s.Files); i++ {
-			if !s.Files[i].IsHidden() {
+			if !s.concealed(&s.Files[i]) {
 				if visibleCount == displayIdx {
 					return i
 				}
@@ -143,7 +145,7 @@ func (s *AppState) setDisplaySelectedIndex(displayIdx int) {
 		if s.HideHiddenFiles {
 			visibleCount := 0
 			for _, fileIdx := range s.FilteredIndices {
-				if s.Files[fileIdx].IsHidden() {
+				if s.concealed(&s.Files[fileIdx]) {
 					continue
 				}
 				if visibleCount == displayIdx {
@@ -163,7 +165,7 @@ func (s *AppState) setDisplaySelectedIndex(displayIdx int) {
 	if s.HideHiddenFiles {
 		visibleCount := 0
 		for i := 0; i < len(s.Files); i++ {
-			if !s.Files[i].IsHidden() {
+			if !s.concealed(&s.Files[i]) {
 				if visibleCount == displayIdx {
 					s.SelectedIndex = i
 					return
@@ -183,6 +185,10 @@ func (s *AppState) updateScrollVisibility() {
 	if displayIdx < 0 {
 		return
 	}
+	if grid := s.ListGrid(); grid.Columns > 1 {
+		s.scrollGrid(grid, displayIdx, false)
+		return
+	}
 
 	if displayIdx < s.ScrollOffset {
 		s.ScrollOffset = displayIdx
@@ -210,6 +216,10 @@ func (s *AppState) centerScrollOnSelection() {
 	if displayIdx < 0 {
 		return
 	}
+	if grid := s.ListGrid(); grid.Columns > 1 {
+		s.scrollGrid(grid, displayIdx, true)
+		return
+	}
 
 	s.ScrollOffset = displayIdx - visibleLines/2
 
@@ -228,13 +238,22 @@ func (s *AppState) centerScrollOnSelection() {
 
 // visibleLines returns the number of rows available for the list, mirroring the renderer's layout.
 func (s *AppState) visibleLines() int {
+	visibleLines := s.listRows() - s.StagingPanelRows()
+	if visibleLines < 0 {
+		return 0
+	}
+	return visibleLines
+}
+
+// listRows is the height of the list area before bottom panels are carved out.
+func (s *AppState) listRows() int {
 	listStartY := 1
 	if s.FilterActive || s.GlobalSearchActive {
 		listStartY = 2
 	}
-	visibleLines := s.ScreenHeight - 2 - listStartY
-	if visibleLines < 0 {
+	rows := s.ScreenHeight - 2 - listStartY
+	if rows < 0 {
 		return 0
 	}
-	return visibleLines
+	return rows
 }
@@ -7,7 +7,21 @@ import (
 	search "github.com/kk-code-lab/rdir/internal/search"
 )
 
+// EnvFoldDiacritics makes the filter and global search ignore diacritics, so
+// "uber" matches "Über" (1, true or yes).
+const EnvFoldDiacritics = "RDIR_FOLD_DIACRITICS"
+
+// FoldDiacriticsEnabled reports whether diacritic folding was requested.
+func FoldDiacriticsEnabled(getenv func(string) string) bool {
+	switch strings.ToLower(strings.TrimSpace(getenv(EnvFoldDiacritics))) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
 func (s *AppState) recomputeFilter() {
+	s.filterScores = nil
 	if !s.FilterActive {
 		s.FilteredIndices = nil
 		s.FilterMatches = nil
@@ -16,6 +30,13 @@ func (s *AppState) recomputeFilter() {
 	}
 
 	tokens := prepareFilterTokens(s.FilterQuery, s.FilterCaseSensitive)
+	if s.FoldDiacritics {
+		for i := range tokens {
+			tokens[i].pattern = search.FoldDiacritics(tokens[i].pattern)
+			tokens[i].folded = search.FoldDiacritics(tokens[i].folded)
+			tokens[i].runes = []rune(tokens[i].pattern)
+		}
+	}
 	if len(tokens) == 0 {
 		indices := s.FilteredIndices[:0]
 		if cap(indices) < len(s.Files) {
@@ -36,15 +57,40 @@ func (s *AppState) recomputeFilter() {
 
 	s.ensureLowerNames()
 
+	scoring := s.FilterScoring
+	adjust := scoring.adjusts() || s.FilterScoreDebug
+	if s.FilterScoreDebug {
+		s.filterScores = make(map[int]FilterScore)
+	}
+
 	matches := s.FilterMatches[:0]
 	indices := s.FilteredIndices[:0]
 	for idx, file := range s.Files {
-		lowerName := ""
+		name, lowerName := file.Name, ""
 		if idx < len(s.fileLowerNames) {
 			lowerName = s.fileLowerNames[idx]
 		}
-		score, matched := matchFilterTokens(file.Name, lowerName, tokens, s.FilterCaseSensitive, s.filterMatcher)
+		if s.FoldDiacritics {
+			// One rune maps to one rune, so the folded name matches at the
+			// same positions as the original.
+			name, lowerName = search.FoldDiacritics(name), search.FoldDiacritics(lowerName)
+		}
+		score, matched := matchFilterTokens(name, lowerName, tokens, s.FilterCaseSensitive, s.filterMatcher)
 		if matched {
+			if adjust {
+				target := name
+				if !s.FilterCaseSensitive {
+					target = lowerName
+					if target == "" {
+						target = strings.ToLower(name)
+					}
+				}
+				detail := scoring.score(&s.Files[idx], target, tokens, score, s.filterMatcher)
+				score = detail.Total
+				if s.filterScores != nil {
+					s.filterScores[idx] = detail
+				}
+			}
 			matches = append(matches, FuzzyMatch{FileIndex: idx, Score: score})
 			indices = append(indices, idx)
 		}
@@ -52,6 +98,9 @@ func (s *AppState) recomputeFilter() {
 
 	s.FilterMatches = matches
 	s.FilteredIndices = indices
+	if scoring.RankByScore {
+		s.rankFilterMatches()
+	}
 	s.invalidateDisplayFilesCache()
 }
 
@@ -95,6 +144,7 @@ func (s *AppState) displayIndexForFile(fileIdx int) int {
 func (s *AppState) clearFilter() {
 	s.FilterActive = false
 	s.FilterQuery = ""
+	s.FilterTail = 0
 	s.FilterCaseSensitive = false
 	s.FilteredIndices = nil
 	s.FilterMatches = nil
@@ -46,6 +46,9 @@ func (s *AppState) clearGlobalSearch(forgetMemory bool) {
 	s.GlobalSearchCursorPos = 0
 	s.GlobalSearchCaseSensitive = false
 	s.GlobalSearchResults = nil
+	s.globalSearchRanked = nil
+	s.searchStats = nil
+	s.searchStatsInFlight = nil
 	s.GlobalSearchIndex = 0
 	s.GlobalSearchScroll = 0
 	s.GlobalSearchInProgress = false
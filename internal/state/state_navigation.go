@@ -1,6 +1,7 @@
 package state
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"sort"
@@ -12,20 +13,169 @@ import (
 // shouldHideFromListingFn mirrors fs.ShouldHideFromListing but is overridable in tests.
 var shouldHideFromListingFn = fsutil.ShouldHideFromListing
 
+// updateParentEntries lists the parent of the current directory for the
+// parent pane. With an async loader the read runs in the background and
+// ParentEntriesLoadedAction installs it; until then the pane keeps a cached
+// listing of the same directory, if there is one.
 func (s *AppState) updateParentEntries() {
+	s.cancelParentLoad()
 	parentPath := filepath.Dir(s.CurrentPath)
 	if parentPath == "" || parentPath == s.CurrentPath {
 		s.ParentEntries = nil
+		s.parentEntriesPath = ""
 		return
 	}
 
 	currentName := norm.NFC.String(filepath.Base(s.CurrentPath))
+	if s.startParentLoad(parentPath, currentName) {
+		return
+	}
 
-	entries, err := os.ReadDir(parentPath)
+	var parentFiles []FileEntry
+	var err error
+	if s.ReadEntries != nil {
+		parentFiles, err = s.ReadEntries(context.Background(), parentPath)
+	} else {
+		parentFiles, err = readParentEntries(context.Background(), fsutil.OrLocal(s.FS), parentPath, currentName)
+	}
 	if err != nil {
+		parentFiles = nil
+	}
+	s.setParentEntries(parentPath, currentName, parentFiles)
+}
+
+// setParentEntries sorts and filters the listing of parentPath into the
+// parent pane.
+func (s *AppState) setParentEntries(parentPath, currentName string, parentFiles []FileEntry) {
+	sort.Slice(parentFiles, func(i, j int) bool {
+		if parentFiles[i].IsDir != parentFiles[j].IsDir {
+			return parentFiles[i].IsDir
+		}
+		return parentFiles[i].Name < parentFiles[j].Name
+	})
+
+	if s.HideHiddenFiles {
+		filtered := parentFiles[:0]
+		for _, entry := range parentFiles {
+			if entry.IsHidden() && entry.Name != currentName {
+				continue
+			}
+			filtered = append(filtered, entry)
+		}
+		parentFiles = filtered
+	}
+
+	s.ParentEntries = parentFiles
+	s.parentEntriesPath = parentPath
+}
+
+// startParentLoad reads parentPath through the async directory loader and
+// reports whether it did. A cached listing that names the current directory
+// is shown at once; the read checks it against the directory mtime.
+func (s *AppState) startParentLoad(parentPath, currentName string) bool {
+	loader := s.DirectoryLoader
+	dispatch := s.getDispatch()
+	if loader == nil || dispatch == nil {
+		return false
+	}
+
+	if s.listsLocalDisk() {
+		if entries, ok := ancestorListings.peek(parentPath); ok && hasEntryNamed(entries, currentName) {
+			s.setParentEntries(parentPath, currentName, entries)
+		}
+	}
+	if s.parentEntriesPath != parentPath {
 		s.ParentEntries = nil
+	}
+
+	local := s.listsLocalDisk()
+	fsys := fsutil.OrLocal(s.FS)
+	read := s.ReadEntries
+	token := s.nextDirectoryLoadToken()
+	s.parentLoadToken = token
+	loader.Start(DirectoryLoadRequest{
+		Token: token,
+		Path:  parentPath,
+		FS:    s.FS,
+		Read: func(ctx context.Context, path string) ([]FileEntry, error) {
+			switch {
+			case read != nil:
+				return read(ctx, path)
+			case local:
+				return readCachedParentEntries(ctx, path, currentName)
+			default:
+				return readParentEntries(ctx, fsys, path, currentName)
+			}
+		},
+		Callback: func(result DirectoryLoadResult) {
+			dispatch(ParentEntriesLoadedAction(result))
+		},
+	})
+	return true
+}
+
+// cancelParentLoad stops the parent pane read still in flight.
+func (s *AppState) cancelParentLoad() {
+	if s.parentLoadToken != 0 && s.DirectoryLoader != nil {
+		s.DirectoryLoader.Cancel(s.parentLoadToken)
+	}
+	s.parentLoadToken = 0
+}
+
+// applyParentEntries installs a parent pane listing read in the background,
+// unless the current directory moved on since it was requested.
+func (s *AppState) applyParentEntries(a ParentEntriesLoadedAction) {
+	if a.Token == 0 || a.Token != s.parentLoadToken {
 		return
 	}
+	s.parentLoadToken = 0
+	if a.Path != filepath.Dir(s.CurrentPath) {
+		return
+	}
+	entries := a.Entries
+	if a.Err != nil {
+		entries = nil
+	}
+	s.setParentEntries(a.Path, norm.NFC.String(filepath.Base(s.CurrentPath)), entries)
+}
+
+// readCachedParentEntries lists a local parent directory through the
+// ancestor cache, which drops a listing once the directory mtime changes,
+// and stores what it reads there. Cached listings skip entries hidden from
+// listings, so the directory is read again when the current one is missing.
+func readCachedParentEntries(ctx context.Context, parentPath, currentName string) ([]FileEntry, error) {
+	if entries, ok := ancestorListings.lookup(parentPath); ok && hasEntryNamed(entries, currentName) {
+		return entries, nil
+	}
+	info, err := os.Stat(parentPath)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := readDirectoryEntries(ctx, fsutil.Local, parentPath)
+	if err != nil {
+		return nil, err
+	}
+	ancestorListings.store(parentPath, info.ModTime(), entries)
+	if hasEntryNamed(entries, currentName) {
+		return entries, nil
+	}
+	return readParentEntries(ctx, fsutil.Local, parentPath, currentName)
+}
+
+func hasEntryNamed(entries []FileEntry, name string) bool {
+	for _, entry := range entries {
+		if entry.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func readParentEntries(ctx context.Context, fsys fsutil.FS, parentPath, currentName string) ([]FileEntry, error) {
+	entries, err := fsys.ReadDir(ctx, parentPath)
+	if err != nil {
+		return nil, err
+	}
 
 	parentFiles := make([]FileEntry, 0, len(entries))
 	for _, e := range entries {
@@ -45,7 +195,7 @@ func (s *AppState) updateParentEntries() {
 		isSymlink := (info.Mode() & os.ModeSymlink) != 0
 
 		if isSymlink {
-			targetInfo, err := os.Stat(fullPath)
+			targetInfo, err := fsys.Stat(fullPath)
 			if err == nil {
 				isDir = targetInfo.IsDir()
 			}
@@ -62,26 +212,7 @@ func (s *AppState) updateParentEntries() {
 		}
 		parentFiles = append(parentFiles, entry)
 	}
-
-	sort.Slice(parentFiles, func(i, j int) bool {
-		if parentFiles[i].IsDir != parentFiles[j].IsDir {
-			return parentFiles[i].IsDir
-		}
-		return parentFiles[i].Name < parentFiles[j].Name
-	})
-
-	if s.HideHiddenFiles {
-		filtered := parentFiles[:0]
-		for _, entry := range parentFiles {
-			if entry.IsHidden() && entry.Name != currentName {
-				continue
-			}
-			filtered = append(filtered, entry)
-		}
-		parentFiles = filtered
-	}
-
-	s.ParentEntries = parentFiles
+	return parentFiles, nil
 }
 
 func (s *AppState) RefreshParentEntries() {
@@ -101,6 +232,7 @@ func (s *AppState) resetViewport() {
 	s.SelectedIndex = 0
 	s.ScrollOffset = 0
 	s.clearFilter()
+	s.clearTypeAhead()
 
 	if s.HideHiddenFiles && len(s.Files) > 0 && s.Files[0].IsHidden() {
 		for i, f := range s.Files {
@@ -20,6 +20,9 @@ func clonePreviewData(src *PreviewData) *PreviewData {
 	if len(src.FormattedTextLines) > 0 {
 		copyData.FormattedTextLines = append([]string(nil), src.FormattedTextLines...)
 	}
+	if len(src.FormattedANSILines) > 0 {
+		copyData.FormattedANSILines = append([]string(nil), src.FormattedANSILines...)
+	}
 	if len(src.FormattedTextLineMeta) > 0 {
 		copyData.FormattedTextLineMeta = append([]TextLineMetadata(nil), src.FormattedTextLineMeta...)
 	}
@@ -225,11 +228,14 @@ func (e entryFileInfo) ModTime() time.Time { return e.modTime }
 func (e entryFileInfo) IsDir() bool        { return e.isDir }
 func (e entryFileInfo) Sys() interface{}   { return nil }
 
-func fileInfoFromEntry(entry *FileEntry) os.FileInfo {
+// fileInfoFromEntry describes entry for the preview cache check. With
+// statTarget a symlink is described by its target; slow paths skip that stat
+// and leave it to the preview loader goroutine.
+func fileInfoFromEntry(entry *FileEntry, statTarget bool) os.FileInfo {
 	if entry == nil {
 		return nil
 	}
-	if entry.IsSymlink && entry.FullPath != "" {
+	if statTarget && entry.IsSymlink && entry.FullPath != "" {
 		if targetInfo, err := os.Stat(entry.FullPath); err == nil {
 			return targetInfo
 		}
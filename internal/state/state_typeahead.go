@@ -0,0 +1,98 @@
+package state
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// typeAheadTimeout is how long the type-ahead prefix survives between keys.
+const typeAheadTimeout = time.Second
+
+// applyTypeAheadChar extends (or restarts) the type-ahead prefix with ch and
+// selects the matching entry. It reports whether the selection changed.
+func (s *AppState) applyTypeAheadChar(ch rune, at time.Time) bool {
+	if !s.typeAheadLast.IsZero() && at.Sub(s.typeAheadLast) > typeAheadTimeout {
+		s.TypeAheadPrefix = ""
+	}
+	s.typeAheadLast = at
+
+	prefix := s.TypeAheadPrefix + string(ch)
+	s.TypeAheadPrefix = prefix
+
+	displayFiles := s.getDisplayFiles()
+	if len(displayFiles) == 0 {
+		return false
+	}
+	current := s.getDisplaySelectedIndex()
+	if current < 0 {
+		current = 0
+	}
+
+	// A fresh prefix moves past the current entry so repeated presses of the
+	// same letter step through entries; a longer prefix may stay where it is.
+	start := current
+	if utf8.RuneCountInString(prefix) == 1 {
+		start = current + 1
+	}
+	idx := findDisplayIndexWithPrefix(displayFiles, prefix, start)
+
+	// Typing the same letter repeatedly ("aaa") cycles through entries that
+	// start with it when nothing matches the literal prefix.
+	if idx < 0 && isRepeatedRune(prefix) {
+		first, _ := utf8.DecodeRuneInString(prefix)
+		idx = findDisplayIndexWithPrefix(displayFiles, string(first), current+1)
+	}
+	if idx < 0 || idx == current {
+		return false
+	}
+	s.setDisplaySelectedIndex(idx)
+	s.updateScrollVisibility()
+	return true
+}
+
+func (s *AppState) clearTypeAhead() {
+	s.TypeAheadActive = false
+	s.TypeAheadPrefix = ""
+	s.typeAheadLast = time.Time{}
+}
+
+// findDisplayIndexWithPrefix returns the first entry at or after start
+// (wrapping around) whose name starts with prefix, ignoring case.
+func findDisplayIndexWithPrefix(files []FileEntry, prefix string, start int) int {
+	n := len(files)
+	for i := 0; i < n; i++ {
+		idx := (start + i) % n
+		if hasFoldedPrefix(files[idx].Name, prefix) {
+			return idx
+		}
+	}
+	return -1
+}
+
+// hasFoldedPrefix compares rune-wise so names whose case-folded prefix has a
+// different byte length than prefix (e.g. non-ASCII letters) still match.
+func hasFoldedPrefix(name, prefix string) bool {
+	count := utf8.RuneCountInString(prefix)
+	i := 0
+	for pos := range name {
+		if i == count {
+			return strings.EqualFold(name[:pos], prefix)
+		}
+		i++
+	}
+	return i == count && strings.EqualFold(name, prefix)
+}
+
+func isRepeatedRune(s string) bool {
+	first, size := utf8.DecodeRuneInString(s)
+	if size == len(s) {
+		return false
+	}
+	for _, r := range s {
+		if r != first {
+			return false
+		}
+	}
+	return true
+}
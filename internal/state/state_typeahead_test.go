@@ -0,0 +1,73 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func typeAheadState() *AppState {
+	return &AppState{
+		CurrentPath: "/test",
+		Files: []FileEntry{
+			{Name: "alpha"},
+			{Name: "Beta"},
+			{Name: "bravo"},
+			{Name: "bz"},
+			{Name: "Ölfarbe"},
+		},
+		ScreenHeight: 24,
+		ScreenWidth:  80,
+	}
+}
+
+func typeAhead(t *testing.T, reducer *StateReducer, state *AppState, chars string, at time.Time) {
+	t.Helper()
+	for _, ch := range chars {
+		if _, err := reducer.Reduce(state, TypeAheadCharAction{Char: ch, At: at}); err != nil {
+			t.Fatalf("type-ahead %q: %v", ch, err)
+		}
+	}
+}
+
+func TestTypeAheadSelectsByPrefixIgnoringCase(t *testing.T) {
+	state := typeAheadState()
+	reducer := NewStateReducer()
+	_, _ = reducer.Reduce(state, TypeAheadStartAction{})
+
+	now := time.Now()
+	typeAhead(t, reducer, state, "br", now)
+	if state.SelectedIndex != 2 {
+		t.Fatalf("expected bravo (2), got %d", state.SelectedIndex)
+	}
+	if state.TypeAheadPrefix != "br" {
+		t.Fatalf("expected prefix br, got %q", state.TypeAheadPrefix)
+	}
+	if state.FilterActive || len(state.getDisplayFiles()) != len(state.Files) {
+		t.Fatal("type-ahead must not filter the list")
+	}
+
+	typeAhead(t, reducer, state, "ö", now.Add(2*typeAheadTimeout))
+	if state.SelectedIndex != 4 || state.TypeAheadPrefix != "ö" {
+		t.Fatalf("expected prefix reset after timeout and Ölfarbe selected, got %d %q", state.SelectedIndex, state.TypeAheadPrefix)
+	}
+}
+
+func TestTypeAheadRepeatedLetterCycles(t *testing.T) {
+	state := typeAheadState()
+	reducer := NewStateReducer()
+	_, _ = reducer.Reduce(state, TypeAheadStartAction{})
+
+	now := time.Now()
+	want := []int{1, 2, 3, 1}
+	for i, idx := range want {
+		typeAhead(t, reducer, state, "b", now)
+		if state.SelectedIndex != idx {
+			t.Fatalf("press %d: expected %d, got %d", i+1, idx, state.SelectedIndex)
+		}
+	}
+
+	_, _ = reducer.Reduce(state, TypeAheadExitAction{})
+	if state.TypeAheadActive || state.TypeAheadPrefix != "" {
+		t.Fatal("exit should clear type-ahead")
+	}
+}
@@ -0,0 +1,188 @@
+package state
+
+import (
+	"fmt"
+	"path/filepath"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+)
+
+const (
+	// maxTabs caps how many tabs can be open at once.
+	maxTabs = 9
+	// tabOpenConfirmThreshold is how many tabs can be opened in one go before
+	// asking for confirmation.
+	tabOpenConfirmThreshold = 4
+)
+
+// Tab is a saved location. The active tab's entry is refreshed whenever the
+// user switches away from it; the live location is always CurrentPath.
+type Tab struct {
+	Path       string
+	SelectName string
+}
+
+// TabCount reports how many tabs are open (at least one).
+func (s *AppState) TabCount() int {
+	if len(s.Tabs) == 0 {
+		return 1
+	}
+	return len(s.Tabs)
+}
+
+// TabLabel returns a short name for tab i, using the live path for the
+// active tab.
+func (s *AppState) TabLabel(i int) string {
+	path := s.CurrentPath
+	if i != s.ActiveTab && i >= 0 && i < len(s.Tabs) {
+		path = s.Tabs[i].Path
+	}
+	name := filepath.Base(path)
+	if name == "" || name == "." {
+		return path
+	}
+	return name
+}
+
+// saveActiveTab records the current location in the active tab, creating the
+// implicit first tab on demand.
+func (s *AppState) saveActiveTab() {
+	current := Tab{Path: s.CurrentPath}
+	if file := s.getCurrentFile(); file != nil {
+		current.SelectName = file.Name
+	}
+	if len(s.Tabs) == 0 {
+		s.Tabs = []Tab{current}
+		s.ActiveTab = 0
+		return
+	}
+	if s.ActiveTab >= 0 && s.ActiveTab < len(s.Tabs) {
+		s.Tabs[s.ActiveTab] = current
+	}
+}
+
+// markedDirectories returns the marked paths that are directories.
+func (s *AppState) markedDirectories() []string {
+	var dirs []string
+	fsys := fsutil.OrLocal(s.FS)
+	for _, path := range s.MarkedPaths() {
+		if info, err := fsys.Stat(path); err == nil && info.IsDir() {
+			dirs = append(dirs, path)
+		}
+	}
+	return dirs
+}
+
+// switchTab makes tab idx active and navigates to its saved location.
+func (r *StateReducer) switchTab(state *AppState, idx int) (*AppState, error) {
+	if idx < 0 || idx >= len(state.Tabs) || idx == state.ActiveTab {
+		return state, nil
+	}
+	state.saveActiveTab()
+	state.ActiveTab = idx
+	return r.openTabLocation(state, state.Tabs[idx])
+}
+
+func (r *StateReducer) openTabLocation(state *AppState, tab Tab) (*AppState, error) {
+	if tab.Path == state.navigationPath() {
+		return state, nil
+	}
+
+	r.selectionHistory[state.CurrentPath] = state.SelectedIndex
+
+	loading, err := r.changeDirectoryWithStatus(state, tab.Path)
+	if err != nil {
+		return state, err
+	}
+
+	post := func(r *StateReducer, state *AppState) error {
+		state.clearGlobalSearch(false)
+		if idx := findFileIndexByName(state.Files, tab.SelectName); idx >= 0 {
+			state.SelectedIndex = idx
+			r.ensureSelectionVisible(state)
+		}
+		state.centerScrollOnSelection()
+		r.addToHistory(state, tab.Path)
+		return r.generatePreview(state)
+	}
+
+	return r.completeDirectoryChange(state, loading, post)
+}
+
+func (r *StateReducer) newTab(state *AppState) (*AppState, error) {
+	if state.TabCount() >= maxTabs {
+		return state, fmt.Errorf("tab limit reached (%d)", maxTabs)
+	}
+	state.saveActiveTab()
+	state.Tabs = append(state.Tabs, state.Tabs[state.ActiveTab])
+	state.ActiveTab = len(state.Tabs) - 1
+	return state, nil
+}
+
+func (r *StateReducer) closeTab(state *AppState) (*AppState, error) {
+	if len(state.Tabs) <= 1 {
+		return state, nil
+	}
+	closed := state.ActiveTab
+	state.Tabs = append(state.Tabs[:closed], state.Tabs[closed+1:]...)
+	next := closed
+	if next >= len(state.Tabs) {
+		next = len(state.Tabs) - 1
+	}
+	state.ActiveTab = next
+	target := state.Tabs[next]
+	if len(state.Tabs) == 1 {
+		state.Tabs = nil
+		state.ActiveTab = 0
+	}
+	return r.openTabLocation(state, target)
+}
+
+func (r *StateReducer) cycleTab(state *AppState, delta int) (*AppState, error) {
+	n := len(state.Tabs)
+	if n <= 1 {
+		return state, nil
+	}
+	return r.switchTab(state, ((state.ActiveTab+delta)%n+n)%n)
+}
+
+// openMarkedInTabs opens every marked directory in its own tab, capped at
+// maxTabs and confirmed when more than tabOpenConfirmThreshold would open.
+func (r *StateReducer) openMarkedInTabs(state *AppState, confirmed bool) (*AppState, error) {
+	dirs := state.markedDirectories()
+	if len(dirs) == 0 {
+		return state, fmt.Errorf("no marked directories to open")
+	}
+
+	room := maxTabs - state.TabCount()
+	if room <= 0 {
+		return state, fmt.Errorf("tab limit reached (%d)", maxTabs)
+	}
+	skipped := 0
+	if len(dirs) > room {
+		skipped = len(dirs) - room
+		dirs = dirs[:room]
+	}
+
+	if len(dirs) > tabOpenConfirmThreshold && !confirmed {
+		prompt := fmt.Sprintf("Open %d tabs?", len(dirs))
+		if skipped > 0 {
+			prompt = fmt.Sprintf("Open %d tabs (%d over the limit skipped)?", len(dirs), skipped)
+		}
+		state.requestConfirm(prompt, OpenMarkedInTabsAction{Confirmed: true})
+		return state, nil
+	}
+
+	state.saveActiveTab()
+	first := len(state.Tabs)
+	for _, dir := range dirs {
+		state.Tabs = append(state.Tabs, Tab{Path: dir})
+	}
+	state.clearMarks()
+	if skipped > 0 {
+		state.ReportError(ErrorSourceGeneral, fmt.Errorf("opened %d tabs, skipped %d over the limit of %d", len(dirs), skipped, maxTabs))
+	}
+
+	state.ActiveTab = first
+	return r.openTabLocation(state, state.Tabs[first])
+}
@@ -0,0 +1,118 @@
+package state
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+// newTabsTestState loads a directory holding d0..d<dirCount-1> and file.txt.
+func newTabsTestState(t *testing.T, dirCount int) (*AppState, *StateReducer, string) {
+	t.Helper()
+	names := []string{"file.txt"}
+	for i := 0; i < dirCount; i++ {
+		names = append(names, fmt.Sprintf("d%d/", i))
+	}
+	return newFixtureState(t, names...)
+}
+
+func markAll(t *testing.T, state *AppState, reducer *StateReducer) {
+	t.Helper()
+	state.SelectedIndex = 0
+	for range state.Files {
+		if _, err := reducer.Reduce(state, ToggleMarkAction{}); err != nil {
+			t.Fatalf("toggle mark: %v", err)
+		}
+	}
+}
+
+func TestToggleMarkAdvancesSelection(t *testing.T) {
+	state, reducer, root := newTabsTestState(t, 2)
+
+	if _, err := reducer.Reduce(state, ToggleMarkAction{}); err != nil {
+		t.Fatalf("toggle mark: %v", err)
+	}
+	if state.SelectedIndex != 1 {
+		t.Fatalf("expected selection to advance, got %d", state.SelectedIndex)
+	}
+	if paths := state.MarkedPaths(); len(paths) != 1 || paths[0] != filepath.Join(root, "d0") {
+		t.Fatalf("unexpected marks: %v", paths)
+	}
+
+	if _, err := reducer.Reduce(state, ClearMarksAction{}); err != nil {
+		t.Fatalf("clear marks: %v", err)
+	}
+	if state.MarkCount() != 0 {
+		t.Fatalf("expected marks cleared, got %d", state.MarkCount())
+	}
+}
+
+func TestOpenMarkedInTabsOpensOnlyDirectories(t *testing.T) {
+	state, reducer, root := newTabsTestState(t, 2)
+	markAll(t, state, reducer)
+
+	if _, err := reducer.Reduce(state, OpenMarkedInTabsAction{}); err != nil {
+		t.Fatalf("open marked: %v", err)
+	}
+	if len(state.Tabs) != 3 {
+		t.Fatalf("expected origin tab plus two directory tabs, got %+v", state.Tabs)
+	}
+	if state.ActiveTab != 1 || state.CurrentPath != filepath.Join(root, "d0") {
+		t.Fatalf("expected first new tab active, got tab %d at %s", state.ActiveTab, state.CurrentPath)
+	}
+	if state.MarkCount() != 0 {
+		t.Fatal("marks should be cleared after opening tabs")
+	}
+
+	if _, err := reducer.Reduce(state, TabPrevAction{}); err != nil {
+		t.Fatalf("prev tab: %v", err)
+	}
+	if state.ActiveTab != 0 || state.CurrentPath != root {
+		t.Fatalf("expected origin tab, got tab %d at %s", state.ActiveTab, state.CurrentPath)
+	}
+}
+
+func TestOpenMarkedInTabsConfirmsAndCaps(t *testing.T) {
+	state, reducer, _ := newTabsTestState(t, maxTabs+2)
+	markAll(t, state, reducer)
+
+	if _, err := reducer.Reduce(state, OpenMarkedInTabsAction{}); err != nil {
+		t.Fatalf("open marked: %v", err)
+	}
+	if state.PendingConfirm == nil {
+		t.Fatal("expected confirmation above the threshold")
+	}
+	if len(state.Tabs) != 0 {
+		t.Fatalf("tabs should not open before confirming, got %d", len(state.Tabs))
+	}
+
+	if _, err := reducer.Reduce(state, ConfirmAcceptAction{}); err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+	if state.PendingConfirm != nil {
+		t.Fatal("confirmation should be cleared")
+	}
+	if len(state.Tabs) != maxTabs {
+		t.Fatalf("expected tabs capped at %d, got %d", maxTabs, len(state.Tabs))
+	}
+	if state.Errors.Err(ErrorSourceGeneral) == nil {
+		t.Fatal("expected skipped directories to be reported")
+	}
+}
+
+func TestCloseTabReturnsToNeighbour(t *testing.T) {
+	state, reducer, root := newTabsTestState(t, 1)
+
+	if _, err := reducer.Reduce(state, TabNewAction{}); err != nil {
+		t.Fatalf("new tab: %v", err)
+	}
+	if _, err := reducer.Reduce(state, EnterDirectoryAction{}); err != nil {
+		t.Fatalf("enter: %v", err)
+	}
+	if _, err := reducer.Reduce(state, TabCloseAction{}); err != nil {
+		t.Fatalf("close tab: %v", err)
+	}
+	if len(state.Tabs) != 0 || state.CurrentPath != root {
+		t.Fatalf("expected single implicit tab at root, got %+v at %s", state.Tabs, state.CurrentPath)
+	}
+}
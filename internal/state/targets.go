@@ -0,0 +1,97 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+)
+
+// selectedTarget returns what the selected entry points at: the path a
+// symlink holds (one level, relative to the link) or the target of a
+// .desktop or .lnk shortcut.
+func (s *AppState) selectedTarget() (string, error) {
+	file := s.getCurrentFile()
+	if file == nil {
+		return "", errors.New("nothing selected")
+	}
+	// Links and shortcuts are read from the local disk.
+	if err := s.RequireLocalDisk("follow links"); err != nil {
+		return "", err
+	}
+	path := s.getCurrentFilePath()
+	if file.IsSymlink {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return "", err
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		if _, err := os.Lstat(target); err != nil {
+			return "", fmt.Errorf("%s points to %s, which does not exist", file.Name, target)
+		}
+		return filepath.Clean(target), nil
+	}
+
+	var sc fsutil.Shortcut
+	if s.PreviewData != nil && s.PreviewData.Shortcut != nil && s.PreviewPath == path {
+		sc = *s.PreviewData.Shortcut
+	} else if parsed, ok := fsutil.ReadShortcut(path); ok {
+		sc = parsed
+	} else {
+		return "", fmt.Errorf("%s is not a symlink or shortcut", file.Name)
+	}
+	target, ok := fsutil.ShortcutTargetPath(sc, path, s.PathContext)
+	if !ok {
+		if sc.Target == "" {
+			return "", fmt.Errorf("%s does not point to a file", file.Name)
+		}
+		return "", fmt.Errorf("cannot reach %s from here", sc.Target)
+	}
+	return target, nil
+}
+
+// goToTarget (g) opens the directory holding the selected entry's target
+// and selects it there.
+func (r *StateReducer) goToTarget(state *AppState) (*AppState, error) {
+	target, err := state.selectedTarget()
+	if err != nil {
+		return state, err
+	}
+	dir, name := filepath.Dir(target), filepath.Base(target)
+	if dir == target {
+		// The target is a filesystem root; open it instead.
+		dir, name = target, ""
+	}
+	return r.openAndSelect(state, dir, name)
+}
+
+// openAndSelect opens dir and selects the entry called name there (revealing
+// it when hidden); an empty name keeps the default selection.
+func (r *StateReducer) openAndSelect(state *AppState, dir, name string) (*AppState, error) {
+	if dir == filepath.Clean(state.CurrentPath) {
+		if name == "" {
+			return state, nil
+		}
+		return state, r.revealEntry(state, name)
+	}
+
+	r.selectionHistory[state.CurrentPath] = state.SelectedIndex
+	loading, err := r.changeDirectoryWithStatus(state, dir)
+	if err != nil {
+		return state, err
+	}
+
+	post := func(r *StateReducer, state *AppState) error {
+		state.clearGlobalSearch(false)
+		r.addToHistory(state, dir)
+		if name == "" {
+			return r.generatePreview(state)
+		}
+		return r.revealEntry(state, name)
+	}
+	return r.completeDirectoryChange(state, loading, post)
+}
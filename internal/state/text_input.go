@@ -0,0 +1,121 @@
+package state
+
+import "github.com/kk-code-lab/rdir/internal/lineedit"
+
+// FilterLine returns the filter query with its cursor.
+func (s *AppState) FilterLine() lineedit.Line {
+	return lineedit.FromTail(s.FilterQuery, s.FilterTail)
+}
+
+// editFilter applies op to the filter query and refilters when the text
+// changed.
+func (s *AppState) editFilter(op lineedit.Op) {
+	line := s.FilterLine()
+	if !line.Apply(op) {
+		s.FilterTail = line.Tail()
+		return
+	}
+	s.setFilterLine(line, queryHasUppercase(line.String()))
+}
+
+// insertFilterRune types ch at the filter cursor. An uppercase letter makes
+// the filter case-sensitive until the query is edited down again.
+func (s *AppState) insertFilterRune(ch rune) {
+	line := s.FilterLine()
+	line.Insert(ch)
+	s.setFilterLine(line, updateCaseSensitivityOnAppend(s.FilterCaseSensitive, ch))
+}
+
+// setFilterLine replaces the filter query and refilters, keeping the
+// selection on the same entry where it can. An emptied query stays in
+// filter mode and lists every entry.
+func (s *AppState) setFilterLine(line lineedit.Line, caseSensitive bool) {
+	prevSelectedIndex := s.SelectedIndex
+	prevDisplayIdx := s.getDisplaySelectedIndex()
+	prevTokenCount := countFilterTokens(s.FilterQuery)
+
+	s.FilterQuery = line.String()
+	s.FilterTail = line.Tail()
+	s.FilterCaseSensitive = caseSensitive
+	if prevTokenCount == 0 && countFilterTokens(s.FilterQuery) > 0 {
+		prevSelectedIndex = -1
+		prevDisplayIdx = -1
+	}
+	if s.FilterQuery == "" {
+		s.FilteredIndices = make([]int, len(s.Files))
+		for i := range s.Files {
+			s.FilteredIndices[i] = i
+		}
+		s.FilterMatches = nil
+		s.invalidateDisplayFilesCache()
+		s.retainSelectionAfterFilterChange(prevSelectedIndex, prevDisplayIdx)
+		s.FilterCaseSensitive = false
+	} else {
+		s.recomputeFilter()
+		s.retainSelectionAfterFilterChange(prevSelectedIndex, prevDisplayIdx)
+	}
+	s.ScrollOffset = 0
+	s.updateScrollVisibility()
+}
+
+// Line returns the prompt input with its cursor.
+func (p *TextPrompt) Line() lineedit.Line {
+	return lineedit.FromTail(p.Input, p.Tail)
+}
+
+func (p *TextPrompt) edit(op lineedit.Op) {
+	line := p.Line()
+	if line.Apply(op) {
+		p.Err = ""
+	}
+	p.Input = line.String()
+	p.Tail = line.Tail()
+}
+
+func (p *TextPrompt) insert(ch rune) {
+	line := p.Line()
+	line.Insert(ch)
+	p.Input = line.String()
+	p.Tail = line.Tail()
+	p.Err = ""
+}
+
+// editGlobalSearch applies op to the global search query. Any key in the
+// input drops a pending restore of the previous selection; edits search
+// again.
+func (r *StateReducer) editGlobalSearch(state *AppState, op lineedit.Op) {
+	if !state.GlobalSearchActive {
+		return
+	}
+	state.clearDesiredGlobalSearchSelection()
+	state.clearGlobalSearchPendingIndex()
+	line := lineedit.Line{Text: []rune(state.GlobalSearchQuery), Cursor: state.GlobalSearchCursorPos}
+	prevResults := state.GlobalSearchResults
+	prevQuery := state.CleanGlobalSearchQuery()
+	if !line.Apply(op) {
+		state.GlobalSearchCursorPos = line.Cursor
+		return
+	}
+	r.setGlobalSearchLine(state, line, prevResults, prevQuery)
+}
+
+func (r *StateReducer) insertGlobalSearchRune(state *AppState, ch rune) {
+	if !state.GlobalSearchActive {
+		return
+	}
+	state.clearDesiredGlobalSearchSelection()
+	state.clearGlobalSearchPendingIndex()
+	line := lineedit.Line{Text: []rune(state.GlobalSearchQuery), Cursor: state.GlobalSearchCursorPos}
+	prevResults := state.GlobalSearchResults
+	prevQuery := state.CleanGlobalSearchQuery()
+	line.Insert(ch)
+	r.setGlobalSearchLine(state, line, prevResults, prevQuery)
+}
+
+func (r *StateReducer) setGlobalSearchLine(state *AppState, line lineedit.Line, prevResults []GlobalSearchResult, prevQuery string) {
+	state.setGlobalSearchQuery(line.String())
+	state.GlobalSearchCursorPos = line.Cursor
+	state.GlobalSearchCaseSensitive = queryHasUppercase(state.GlobalSearchQuery)
+	r.applyLocalSearchPreview(state, prevResults, prevQuery)
+	r.triggerGlobalSearch(state)
+}
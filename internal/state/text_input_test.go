@@ -0,0 +1,61 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/kk-code-lab/rdir/internal/lineedit"
+)
+
+func TestFilterEditsAtCursor(t *testing.T) {
+	state := &AppState{
+		CurrentPath:  "/test",
+		Files:        []FileEntry{{Name: "main.go"}, {Name: "notes.md"}},
+		ScreenHeight: 24,
+		ScreenWidth:  80,
+	}
+	reducer := NewStateReducer()
+	actions := []Action{FilterStartAction{}}
+	for _, ch := range "mgo" {
+		actions = append(actions, FilterCharAction{Char: ch})
+	}
+	actions = append(actions,
+		FilterEditAction{Op: lineedit.Left},
+		FilterEditAction{Op: lineedit.Left},
+		FilterCharAction{Char: '.'},
+	)
+	for _, action := range actions {
+		if _, err := reducer.Reduce(state, action); err != nil {
+			t.Fatalf("%T: %v", action, err)
+		}
+	}
+	if state.FilterQuery != "m.go" || state.FilterTail != 2 {
+		t.Fatalf("expected m.go with the cursor before go, got %q tail %d", state.FilterQuery, state.FilterTail)
+	}
+
+	if _, err := reducer.Reduce(state, FilterEditAction{Op: lineedit.KillToStart}); err != nil {
+		t.Fatalf("kill to start: %v", err)
+	}
+	if state.FilterQuery != "go" || state.FilterTail != 2 {
+		t.Fatalf("expected Ctrl+U to leave go, got %q tail %d", state.FilterQuery, state.FilterTail)
+	}
+	if got := len(state.getDisplayFiles()); got != 1 {
+		t.Fatalf("expected the edited query to refilter to one entry, got %d", got)
+	}
+}
+
+func TestPromptEditsAtCursor(t *testing.T) {
+	state := &AppState{Prompt: &TextPrompt{Kind: PromptReveal, Input: "readme", Err: "no match"}}
+	reducer := NewStateReducer()
+	for _, action := range []Action{
+		PromptEditAction{Op: lineedit.Home},
+		PromptCharAction{Char: '.'},
+		PromptEditAction{Op: lineedit.KillToEnd},
+	} {
+		if _, err := reducer.Reduce(state, action); err != nil {
+			t.Fatalf("%T: %v", action, err)
+		}
+	}
+	if state.Prompt.Input != "." || state.Prompt.Tail != 0 || state.Prompt.Err != "" {
+		t.Fatalf("unexpected prompt %+v", state.Prompt)
+	}
+}
@@ -0,0 +1,77 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EnvTruncate picks how names too long for a column are shortened, for all
+// columns ("end") or per column ("list=middle,sidebar=end").
+const EnvTruncate = "RDIR_TRUNCATE"
+
+// TruncateMode says where a name that does not fit is cut.
+type TruncateMode int
+
+const (
+	// TruncateMiddle keeps the start and the extension: "quarterl…inal.pdf".
+	TruncateMiddle TruncateMode = iota
+	// TruncateEnd keeps the start only: "quarterly-repo…".
+	TruncateEnd
+)
+
+// TruncateConfig holds the truncation mode of each column that shows names.
+type TruncateConfig struct {
+	List    TruncateMode // file list
+	Sidebar TruncateMode // parent directory column
+	Preview TruncateMode // directory preview
+	Search  TruncateMode // global search results (paths)
+}
+
+// LoadTruncateConfig reads RDIR_TRUNCATE: "middle" or "end" for every
+// column, or comma-separated "column=mode" entries where column is list,
+// sidebar, preview, search or all. Entries apply in order on top of middle
+// truncation everywhere.
+func LoadTruncateConfig(getenv func(string) string) (TruncateConfig, error) {
+	var cfg TruncateConfig
+	var problems []string
+
+	for _, item := range strings.Split(getenv(EnvTruncate), ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		column, value, found := strings.Cut(item, "=")
+		if !found {
+			column, value = "all", item
+		}
+		var mode TruncateMode
+		switch strings.TrimSpace(value) {
+		case "middle":
+			mode = TruncateMiddle
+		case "end":
+			mode = TruncateEnd
+		default:
+			problems = append(problems, fmt.Sprintf("%q", item))
+			continue
+		}
+		switch strings.TrimSpace(column) {
+		case "list":
+			cfg.List = mode
+		case "sidebar":
+			cfg.Sidebar = mode
+		case "preview":
+			cfg.Preview = mode
+		case "search":
+			cfg.Search = mode
+		case "all":
+			cfg = TruncateConfig{List: mode, Sidebar: mode, Preview: mode, Search: mode}
+		default:
+			problems = append(problems, fmt.Sprintf("%q", item))
+		}
+	}
+
+	if len(problems) > 0 {
+		return cfg, fmt.Errorf("ignoring invalid %s entries: %s (use middle or end, optionally per list, sidebar, preview or search)", EnvTruncate, strings.Join(problems, ", "))
+	}
+	return cfg, nil
+}
@@ -0,0 +1,28 @@
+package state
+
+import "testing"
+
+func TestLoadTruncateConfig(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    TruncateConfig
+		wantErr bool
+	}{
+		{raw: "", want: TruncateConfig{}},
+		{raw: "end", want: TruncateConfig{List: TruncateEnd, Sidebar: TruncateEnd, Preview: TruncateEnd, Search: TruncateEnd}},
+		{raw: "end, list=middle", want: TruncateConfig{Sidebar: TruncateEnd, Preview: TruncateEnd, Search: TruncateEnd}},
+		{raw: "SEARCH=End", want: TruncateConfig{Search: TruncateEnd}},
+		{raw: "sidebar=end,footer=end,list=left", want: TruncateConfig{Sidebar: TruncateEnd}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := LoadTruncateConfig(func(key string) string {
+			if key == EnvTruncate {
+				return tt.raw
+			}
+			return ""
+		})
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Fatalf("LoadTruncateConfig(%q) = %+v, %v; want %+v, err=%v", tt.raw, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
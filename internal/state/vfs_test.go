@@ -0,0 +1,104 @@
+package state
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+)
+
+func TestListingAndPreviewReadThroughFS(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "mnt")
+	fsys := fsutil.FromFS(fstest.MapFS{
+		"notes.txt":    {Data: []byte("first\nsecond\n")},
+		"docs/a.md":    {Data: []byte("# A\n")},
+		"docs/b/c.txt": {Data: []byte("c")},
+	}, root)
+
+	entries, err := readDirectoryEntries(context.Background(), fsys, root)
+	if err != nil {
+		t.Fatalf("readDirectoryEntries: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("entries = %+v", entries)
+	}
+	for _, e := range entries {
+		if want := filepath.Join(root, e.Name); e.FullPath != want {
+			t.Fatalf("FullPath = %q, want %q", e.FullPath, want)
+		}
+	}
+
+	data, _, err := buildPreviewData(context.Background(), fsys, filepath.Join(root, "notes.txt"), false)
+	if err != nil {
+		t.Fatalf("file preview: %v", err)
+	}
+	if len(data.TextLines) < 2 || data.TextLines[0] != "first" || data.TextLines[1] != "second" {
+		t.Fatalf("TextLines = %q", data.TextLines)
+	}
+
+	data, _, err = buildPreviewData(context.Background(), fsys, filepath.Join(root, "docs"), false)
+	if err != nil {
+		t.Fatalf("directory preview: %v", err)
+	}
+	if len(data.DirEntries) != 2 {
+		t.Fatalf("DirEntries = %+v", data.DirEntries)
+	}
+}
+
+func TestFileOperationsRefusedOutsideLocalDisk(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "mnt")
+	state := &AppState{
+		FS:               fsutil.FromFS(fstest.MapFS{"a.zip": {Data: []byte("PK")}}, root),
+		CurrentPath:      root,
+		Files:            []FileEntry{{Name: "a.zip", FullPath: filepath.Join(root, "a.zip")}},
+		PrivilegedHelper: []string{"sudo", "cat"},
+	}
+	for name, start := range map[string]func() error{
+		"rename":       state.startInlineRename,
+		"extract":      state.startExtract,
+		"compress":     state.startCompress,
+		"line endings": state.planLineEndingFix,
+		"read as root": state.confirmPrivilegedRead,
+		"paste":        func() error { return state.RequireLocalDisk("paste") },
+		"go to target": func() error { _, err := state.selectedTarget(); return err },
+	} {
+		if err := start(); err == nil || !strings.Contains(err.Error(), "outside the local filesystem") {
+			t.Errorf("%s: err = %v, want a refusal", name, err)
+		}
+	}
+	if state.Prompt != nil || state.Rename != nil || state.PendingConfirm != nil {
+		t.Fatalf("expected nothing to open, got prompt %+v rename %+v confirm %+v", state.Prompt, state.Rename, state.PendingConfirm)
+	}
+}
+
+func TestSearchStatsAndMarkTotalsReadThroughFS(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "mnt")
+	fsys := fsutil.FromFS(fstest.MapFS{
+		"notes.txt":    {Data: []byte("12345")},
+		"docs/a.md":    {Data: []byte("# A\n")},
+		"docs/b/c.txt": {Data: []byte("c")},
+	}, root)
+	notes, docs := filepath.Join(root, "notes.txt"), filepath.Join(root, "docs")
+
+	if stat := statSearchPaths(fsys, []string{notes})[notes]; stat.size != 5 {
+		t.Fatalf("search stat size = %d, want 5", stat.size)
+	}
+
+	totals, err := countMarkTotals(context.Background(), fsys, docs)
+	if err != nil {
+		t.Fatalf("countMarkTotals: %v", err)
+	}
+	if totals.files != 2 || totals.dirs != 2 || totals.size != 5 || totals.unreadable != 0 {
+		t.Fatalf("totals = %+v, want 2 files in 2 directories, 5 bytes", totals)
+	}
+
+	state := &AppState{FS: fsys, CurrentPath: root}
+	state.toggleMark(notes)
+	state.toggleMark(docs)
+	if dirs := state.markedDirectories(); len(dirs) != 1 || dirs[0] != docs {
+		t.Fatalf("markedDirectories = %q, want [%s]", dirs, docs)
+	}
+}
@@ -0,0 +1,31 @@
+package state
+
+// ViewStats summarises the current directory view for the status bar.
+type ViewStats struct {
+	Total       int   // entries in the directory
+	Shown       int   // entries left after filters and hidden-file rules
+	ListedBytes int64 // combined size of the shown files (directories excluded)
+	Marked      int   // marked paths, across directories
+}
+
+func computeViewStats(files []FileEntry) ViewStats {
+	stats := ViewStats{Shown: len(files)}
+	for i := range files {
+		if !files[i].IsDir {
+			stats.ListedBytes += files[i].Size
+		}
+	}
+	return stats
+}
+
+// ViewStats returns aggregates of the current view. Shown and ListedBytes are
+// computed when the display list is rebuilt, so repeated calls are cheap.
+func (s *AppState) ViewStats() ViewStats {
+	if s.displayFilesDirty || s.displayFilesCache == nil {
+		s.getDisplayFiles()
+	}
+	stats := s.displayStats
+	stats.Total = len(s.Files)
+	stats.Marked = s.MarkCount()
+	return stats
+}
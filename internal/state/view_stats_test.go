@@ -0,0 +1,32 @@
+package state
+
+import "testing"
+
+func TestViewStatsFollowFilterAndHiddenFiles(t *testing.T) {
+	state := &AppState{
+		HideHiddenFiles: true,
+		Files: []FileEntry{
+			{Name: "dir", IsDir: true, Size: 4096},
+			{Name: ".env", Size: 10},
+			{Name: "a.go", Size: 100},
+			{Name: "b.txt", Size: 200},
+		},
+	}
+	state.toggleMark("/x/a.go")
+
+	stats := state.ViewStats()
+	if stats.Total != 4 || stats.Shown != 3 || stats.ListedBytes != 300 || stats.Marked != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	reducer := NewStateReducer()
+	for _, action := range []Action{FilterStartAction{}, FilterCharAction{Char: '.'}, FilterCharAction{Char: 'g'}, FilterCharAction{Char: 'o'}} {
+		if _, err := reducer.Reduce(state, action); err != nil {
+			t.Fatalf("%T: %v", action, err)
+		}
+	}
+	stats = state.ViewStats()
+	if stats.Shown != 1 || stats.ListedBytes != 100 {
+		t.Fatalf("expected filtered stats for a.go only, got %+v", stats)
+	}
+}
@@ -0,0 +1,81 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EnvWrap toggles wrap-around navigation per area, e.g. "list,search" or
+// "all,no-pager".
+const EnvWrap = "RDIR_WRAP"
+
+// WrapConfig controls whether moving past either end wraps to the other.
+type WrapConfig struct {
+	List   bool // file list selection
+	Search bool // global search results
+	Pager  bool // cycling pager search hits
+}
+
+// DefaultWrapConfig keeps the list and search results clamped and lets pager
+// search hits cycle, matching the behaviour before wrapping was configurable.
+func DefaultWrapConfig() WrapConfig {
+	return WrapConfig{Pager: true}
+}
+
+// LoadWrapConfig reads RDIR_WRAP: a comma-separated list of "list",
+// "search", "pager", "all" or "none"; a "no-" prefix turns an area off.
+// Entries apply in order on top of the defaults.
+func LoadWrapConfig(getenv func(string) string) (WrapConfig, error) {
+	cfg := DefaultWrapConfig()
+	var problems []string
+
+	for _, item := range strings.Split(getenv(EnvWrap), ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		on := true
+		if rest, found := strings.CutPrefix(item, "no-"); found {
+			item, on = rest, false
+		}
+		switch item {
+		case "list":
+			cfg.List = on
+		case "search":
+			cfg.Search = on
+		case "pager":
+			cfg.Pager = on
+		case "all":
+			cfg = WrapConfig{List: on, Search: on, Pager: on}
+		case "none":
+			cfg = WrapConfig{}
+		default:
+			problems = append(problems, fmt.Sprintf("%q", item))
+		}
+	}
+
+	if len(problems) > 0 {
+		return cfg, fmt.Errorf("ignoring invalid %s entries: %s (use list, search, pager, all or none)", EnvWrap, strings.Join(problems, ", "))
+	}
+	return cfg, nil
+}
+
+// wrapIndex moves idx by delta within [0, n). Past either end it wraps when
+// wrap is set and otherwise stays put; ok is false when nothing moved.
+func wrapIndex(idx, delta, n int, wrap bool) (int, bool) {
+	if n <= 0 {
+		return idx, false
+	}
+	next := idx + delta
+	if next >= 0 && next < n {
+		return next, true
+	}
+	if !wrap {
+		return idx, false
+	}
+	next %= n
+	if next < 0 {
+		next += n
+	}
+	return next, next != idx
+}
@@ -0,0 +1,80 @@
+package state
+
+import "testing"
+
+func TestLoadWrapConfig(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    WrapConfig
+		wantErr bool
+	}{
+		{raw: "", want: WrapConfig{Pager: true}},
+		{raw: "list, search", want: WrapConfig{List: true, Search: true, Pager: true}},
+		{raw: "all,no-pager", want: WrapConfig{List: true, Search: true}},
+		{raw: "none,LIST", want: WrapConfig{List: true}},
+		{raw: "list,sideways", want: WrapConfig{List: true, Pager: true}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := LoadWrapConfig(func(key string) string {
+			if key == EnvWrap {
+				return tt.raw
+			}
+			return ""
+		})
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Fatalf("LoadWrapConfig(%q) = %+v, %v; want %+v, err=%v", tt.raw, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestListNavigationWrapsWhenEnabled(t *testing.T) {
+	newState := func(wrap bool) *AppState {
+		return &AppState{
+			CurrentPath:   "/test",
+			Files:         []FileEntry{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+			SelectedIndex: 2,
+			ScreenHeight:  24,
+			ScreenWidth:   80,
+			Wrap:          WrapConfig{List: wrap},
+		}
+	}
+	reducer := NewStateReducer()
+
+	clamped := newState(false)
+	if _, err := reducer.Reduce(clamped, NavigateDownAction{}); err != nil {
+		t.Fatal(err)
+	}
+	if clamped.SelectedIndex != 2 {
+		t.Fatalf("expected selection to stay at the end, got %d", clamped.SelectedIndex)
+	}
+
+	wrapped := newState(true)
+	if _, err := reducer.Reduce(wrapped, NavigateDownAction{}); err != nil {
+		t.Fatal(err)
+	}
+	if wrapped.SelectedIndex != 0 {
+		t.Fatalf("expected j at the end to wrap to 0, got %d", wrapped.SelectedIndex)
+	}
+	if _, err := reducer.Reduce(wrapped, NavigateUpAction{}); err != nil {
+		t.Fatal(err)
+	}
+	if wrapped.SelectedIndex != 2 {
+		t.Fatalf("expected k at the start to wrap to the end, got %d", wrapped.SelectedIndex)
+	}
+}
+
+func TestGlobalSearchNavigationWrapsWhenEnabled(t *testing.T) {
+	state := &AppState{
+		GlobalSearchActive:  true,
+		GlobalSearchResults: []GlobalSearchResult{{FilePath: "/a"}, {FilePath: "/b"}},
+		ScreenHeight:        24,
+		Wrap:                WrapConfig{Search: true},
+	}
+	reducer := NewStateReducer()
+	if _, err := reducer.Reduce(state, GlobalSearchNavigateAction{Direction: "up"}); err != nil {
+		t.Fatal(err)
+	}
+	if state.GlobalSearchIndex != 1 {
+		t.Fatalf("expected wrap to last result, got %d", state.GlobalSearchIndex)
+	}
+}
@@ -0,0 +1,134 @@
+package testui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) { Main(m) }
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestListingNavigationAndQuit(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"alpha.txt":      "a\n",
+		"beta.txt":       "b\n",
+		"docs/guide.md":  "# Guide\n",
+		"docs/intro.txt": "hello\n",
+	})
+	term := Start(t, Options{Dir: dir})
+	term.WaitFor("alpha.txt")
+	term.WaitFor("beta.txt")
+
+	// Directories sort first, so docs is selected; Enter goes into it.
+	term.Press(Enter)
+	term.WaitFor("guide.md")
+	term.WaitFor("intro.txt")
+
+	term.Type("Q")
+	if code := term.WaitExit(); code != 0 {
+		t.Fatalf("exit status %d", code)
+	}
+	if got, want := term.ResultPath(), filepath.Join(dir, "docs"); got != want {
+		t.Fatalf("result path %q, want %q", got, want)
+	}
+}
+
+func TestFilterNarrowsListing(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"report.csv": "x\n",
+		"readme.md":  "y\n",
+		"main.go":    "package main\n",
+	})
+	term := Start(t, Options{Dir: dir})
+	term.WaitFor("main.go")
+
+	term.Type("/")
+	term.Type("csv")
+	term.WaitForGone("main.go")
+	term.WaitFor("report.csv")
+
+	term.Press(Esc)
+	term.WaitFor("main.go")
+}
+
+func TestPagerShowsFileAndSearches(t *testing.T) {
+	var content strings.Builder
+	for i := 1; i <= 200; i++ {
+		if i == 150 {
+			content.WriteString("needle in the haystack\n")
+			continue
+		}
+		content.WriteString("filler line\n")
+	}
+	dir := writeTree(t, map[string]string{"log.txt": content.String()})
+	term := Start(t, Options{Dir: dir})
+	term.WaitFor("log.txt")
+
+	term.Press(Right)
+	term.WaitFor("filler line")
+	term.WaitForGone("needle")
+
+	term.Type("/needle")
+	term.Press(Enter)
+	term.WaitFor("needle in the haystack")
+
+	// q leaves the pager for the file list, whose footer lists the keys.
+	term.Type("q")
+	term.WaitFor("navigate")
+	term.WaitForGone("lines (")
+}
+
+func TestFollowMirrorsSharedSession(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"src/main.go":  "package main\n",
+		"src/util.go":  "package main\n",
+		"zz-notes.txt": "notes\n",
+	})
+	host := Start(t, Options{Dir: dir, Env: []string{"RDIR_SHARE=1"}})
+	host.WaitFor("shared")
+
+	// The follower finds the host's socket in the shared cache directory.
+	follower := Start(t, Options{Dir: t.TempDir(), Env: []string{
+		"XDG_CACHE_HOME=" + filepath.Join(host.Home(), ".cache"),
+		"RDIR_FOLLOW=auto",
+	}})
+	follower.WaitFor("following")
+	follower.WaitFor("zz-notes.txt")
+
+	host.Press(Enter)
+	host.WaitFor("util.go")
+	follower.WaitFor("util.go")
+
+	// Navigation keys do nothing in the follower.
+	follower.Press(Left)
+	host.Press(Down)
+	follower.WaitUntil("selection to follow the host", func(lines []string) bool {
+		return strings.Contains(strings.Join(lines, "\n"), "package main")
+	})
+	follower.WaitFor("util.go")
+
+	host.Type("q")
+	host.WaitExit()
+	// Once the host is gone the follower takes input again.
+	follower.WaitForGone("following")
+	follower.Press(Left)
+	follower.WaitFor("zz-notes.txt")
+	follower.Type("q")
+	follower.WaitExit()
+}
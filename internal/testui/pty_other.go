@@ -0,0 +1,23 @@
+//go:build !linux
+
+package testui
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+var errNoPTY = errors.New("testui: pseudo-terminals are only supported on Linux")
+
+func openPTY() (controller, terminal *os.File, err error) {
+	return nil, nil, errNoPTY
+}
+
+func setPTYSize(*os.File, int, int) error {
+	return errNoPTY
+}
+
+func childAttr() *syscall.SysProcAttr {
+	return nil
+}
@@ -0,0 +1,428 @@
+// Package testui runs the real rdir binary on a pseudo-terminal for
+// end-to-end tests: it types keys the way a terminal would send them and
+// reads back the screen through a small terminal emulator, so tests can
+// assert on what a user would see in the file list, the pager or a search.
+//
+//	term := testui.Start(t, testui.Options{Dir: dir})
+//	term.WaitFor("notes.txt")
+//	term.Type("/note")
+//	term.Press(testui.Enter)
+//
+// Pseudo-terminals are only wired up on Linux; Start skips the test
+// elsewhere.
+package testui
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Key sequences as an xterm sends them in normal cursor mode.
+const (
+	Enter     = "\r"
+	Esc       = "\x1b"
+	Tab       = "\t"
+	Backspace = "\x7f"
+	Up        = "\x1b[A"
+	Down      = "\x1b[B"
+	Right     = "\x1b[C"
+	Left      = "\x1b[D"
+	Home      = "\x1b[H"
+	End       = "\x1b[F"
+	PageUp    = "\x1b[5~"
+	PageDown  = "\x1b[6~"
+)
+
+// Ctrl returns the control character for letter, e.g. Ctrl('s').
+func Ctrl(letter byte) string {
+	return string([]byte{letter & 0x1f})
+}
+
+// typeDelay separates keystrokes sent by Type and Press.
+const typeDelay = 10 * time.Millisecond
+
+// DefaultTimeout bounds WaitFor and the wait for rdir to exit.
+const DefaultTimeout = 5 * time.Second
+
+// Options configures a session.
+type Options struct {
+	Dir    string   // working directory rdir starts in (required)
+	Env    []string // extra KEY=value entries, applied after the defaults
+	Width  int      // terminal columns, 100 when zero
+	Height int      // terminal rows, 30 when zero
+}
+
+// Terminal is a running rdir session.
+type Terminal struct {
+	t       testing.TB
+	cmd     *exec.Cmd
+	pty     *os.File
+	home    string
+	timeout time.Duration
+
+	mu      sync.Mutex
+	screen  *vt
+	changed chan struct{} // closed and replaced on every write from rdir
+	output  bytes.Buffer  // raw output, for failure messages
+
+	done    chan struct{}
+	exitErr error
+}
+
+// Start builds rdir (once per test binary) and runs it on a new
+// pseudo-terminal in opts.Dir. HOME and the cache and config directories
+// point into a temporary directory so sessions do not share state with the
+// user's or each other's. The session is stopped when the test ends.
+func Start(t testing.TB, opts Options) *Terminal {
+	t.Helper()
+	if opts.Dir == "" {
+		t.Fatalf("testui: Options.Dir is required")
+	}
+	if opts.Width <= 0 {
+		opts.Width = 100
+	}
+	if opts.Height <= 0 {
+		opts.Height = 30
+	}
+
+	controller, terminal, err := openPTY()
+	if err != nil {
+		t.Skipf("testui: %v", err)
+	}
+	if err := setPTYSize(controller, opts.Width, opts.Height); err != nil {
+		_ = controller.Close()
+		_ = terminal.Close()
+		t.Fatalf("testui: set pty size: %v", err)
+	}
+
+	binary := buildBinary(t)
+	home := t.TempDir()
+	cmd := exec.Command(binary)
+	cmd.Dir = opts.Dir
+	cmd.Env = append(sessionEnv(home), opts.Env...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = terminal, terminal, terminal
+	cmd.SysProcAttr = childAttr()
+	if err := cmd.Start(); err != nil {
+		_ = controller.Close()
+		_ = terminal.Close()
+		t.Fatalf("testui: start rdir: %v", err)
+	}
+	_ = terminal.Close()
+
+	term := &Terminal{
+		t:       t,
+		cmd:     cmd,
+		pty:     controller,
+		home:    home,
+		timeout: DefaultTimeout,
+		screen:  newVT(opts.Width, opts.Height),
+		changed: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go term.readLoop()
+	go func() {
+		term.exitErr = cmd.Wait()
+		close(term.done)
+	}()
+	t.Cleanup(term.stop)
+	return term
+}
+
+// sessionEnv is the environment every session starts from: only what a
+// terminal program needs, with the user's state directories swapped out.
+func sessionEnv(home string) []string {
+	env := []string{
+		"HOME=" + home,
+		"XDG_CACHE_HOME=" + filepath.Join(home, ".cache"),
+		"XDG_CONFIG_HOME=" + filepath.Join(home, ".config"),
+		"TERM=xterm-256color",
+		"LANG=C.UTF-8",
+		"LC_ALL=C.UTF-8",
+		"TZ=UTC",
+		"RDIR_RESULT_FILE=" + filepath.Join(home, "rdir_result.txt"),
+		"RDIR_ECO=off",
+	}
+	for _, key := range []string{"PATH", "TMPDIR"} {
+		if value, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+value)
+		}
+	}
+	return env
+}
+
+func (term *Terminal) readLoop() {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := term.pty.Read(buf)
+		if n > 0 {
+			term.mu.Lock()
+			_, _ = term.screen.Write(buf[:n])
+			term.output.Write(buf[:n])
+			close(term.changed)
+			term.changed = make(chan struct{})
+			term.mu.Unlock()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// SetTimeout changes how long WaitFor and WaitExit wait.
+func (term *Terminal) SetTimeout(d time.Duration) {
+	term.timeout = d
+}
+
+// Home returns the temporary HOME the session runs with.
+func (term *Terminal) Home() string {
+	return term.home
+}
+
+// Type sends text one character at a time with a short pause, like a user
+// typing; rdir reads keys individually, and text sent in one burst would
+// look like a paste.
+func (term *Terminal) Type(text string) {
+	term.t.Helper()
+	for _, r := range text {
+		term.write(string(r))
+		time.Sleep(typeDelay)
+	}
+}
+
+// Paste sends text in a single write.
+func (term *Terminal) Paste(text string) {
+	term.t.Helper()
+	term.write(text)
+}
+
+// Press sends one or more keys, e.g. Press(testui.Down, testui.Enter).
+// Keys are written separately with a short pause, so an Esc is not merged
+// with the next key into an Alt sequence.
+func (term *Terminal) Press(keys ...string) {
+	term.t.Helper()
+	for i, key := range keys {
+		if i > 0 {
+			time.Sleep(typeDelay)
+		}
+		term.write(key)
+	}
+}
+
+func (term *Terminal) write(s string) {
+	term.t.Helper()
+	if _, err := term.pty.Write([]byte(s)); err != nil {
+		term.t.Fatalf("testui: write %q: %v", s, err)
+	}
+}
+
+// Resize changes the terminal size, which rdir sees as SIGWINCH.
+func (term *Terminal) Resize(width, height int) {
+	term.t.Helper()
+	term.mu.Lock()
+	term.screen.resize(width, height)
+	term.mu.Unlock()
+	if err := setPTYSize(term.pty, width, height); err != nil {
+		term.t.Fatalf("testui: resize: %v", err)
+	}
+}
+
+// Lines returns the visible screen, one string per row with trailing spaces
+// removed.
+func (term *Terminal) Lines() []string {
+	term.mu.Lock()
+	defer term.mu.Unlock()
+	return term.screen.lines()
+}
+
+// Screen returns the visible screen as one string.
+func (term *Terminal) Screen() string {
+	return strings.Join(term.Lines(), "\n")
+}
+
+// Line returns row y of the screen (0 is the top).
+func (term *Terminal) Line(y int) string {
+	lines := term.Lines()
+	if y < 0 || y >= len(lines) {
+		return ""
+	}
+	return lines[y]
+}
+
+// WaitFor waits until text appears on the screen and returns the row it is
+// on. The test fails with the last screen if it does not show up in time.
+func (term *Terminal) WaitFor(text string) int {
+	term.t.Helper()
+	row := -1
+	if !term.waitUntil(func(lines []string) bool {
+		for y, line := range lines {
+			if strings.Contains(line, text) {
+				row = y
+				return true
+			}
+		}
+		return false
+	}) {
+		term.t.Fatalf("testui: %q did not appear within %s; screen:\n%s", text, term.timeout, term.Screen())
+	}
+	return row
+}
+
+// WaitForGone waits until text is no longer on the screen.
+func (term *Terminal) WaitForGone(text string) {
+	term.t.Helper()
+	if !term.waitUntil(func(lines []string) bool {
+		return !strings.Contains(strings.Join(lines, "\n"), text)
+	}) {
+		term.t.Fatalf("testui: %q still on screen after %s; screen:\n%s", text, term.timeout, term.Screen())
+	}
+}
+
+// WaitUntil waits until cond holds for the screen lines.
+func (term *Terminal) WaitUntil(description string, cond func(lines []string) bool) {
+	term.t.Helper()
+	if !term.waitUntil(cond) {
+		term.t.Fatalf("testui: %s did not happen within %s; screen:\n%s", description, term.timeout, term.Screen())
+	}
+}
+
+func (term *Terminal) waitUntil(cond func(lines []string) bool) bool {
+	deadline := time.NewTimer(term.timeout)
+	defer deadline.Stop()
+	for {
+		term.mu.Lock()
+		lines := term.screen.lines()
+		changed := term.changed
+		term.mu.Unlock()
+		if cond(lines) {
+			return true
+		}
+		select {
+		case <-changed:
+		case <-term.done:
+			// Drain what rdir wrote before exiting, then check once more.
+			time.Sleep(20 * time.Millisecond)
+			return cond(term.Lines())
+		case <-deadline.C:
+			return false
+		}
+	}
+}
+
+// WaitExit waits for rdir to exit and returns its exit status.
+func (term *Terminal) WaitExit() int {
+	term.t.Helper()
+	select {
+	case <-term.done:
+	case <-time.After(term.timeout):
+		term.t.Fatalf("testui: rdir did not exit within %s; screen:\n%s", term.timeout, term.Screen())
+	}
+	var exitErr *exec.ExitError
+	if errors.As(term.exitErr, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	if term.exitErr != nil {
+		term.t.Fatalf("testui: rdir: %v", term.exitErr)
+	}
+	return 0
+}
+
+// ResultPath returns the directory rdir wrote for shell integration on exit.
+func (term *Terminal) ResultPath() string {
+	data, err := os.ReadFile(filepath.Join(term.home, "rdir_result.txt"))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func (term *Terminal) stop() {
+	select {
+	case <-term.done:
+	default:
+		_ = term.cmd.Process.Kill()
+		<-term.done
+	}
+	_ = term.pty.Close()
+	if term.t.Failed() {
+		term.mu.Lock()
+		raw := term.output.Bytes()
+		if len(raw) > 4096 {
+			raw = raw[len(raw)-4096:]
+		}
+		term.t.Logf("testui: last output from rdir: %q", raw)
+		term.mu.Unlock()
+	}
+}
+
+var (
+	buildOnce   sync.Once
+	buildPath   string
+	buildErr    error
+	buildOutput []byte
+)
+
+// Main runs the tests of a package that uses Start and removes the rdir
+// binary built for them afterwards. Call it from TestMain:
+//
+//	func TestMain(m *testing.M) { testui.Main(m) }
+func Main(m *testing.M) {
+	code := m.Run()
+	if buildPath != "" {
+		_ = os.RemoveAll(filepath.Dir(buildPath))
+	}
+	os.Exit(code)
+}
+
+// buildBinary compiles ./cmd/rdir into a temporary directory, which Main
+// removes when the tests are done.
+func buildBinary(t testing.TB) string {
+	t.Helper()
+	buildOnce.Do(func() {
+		root, err := moduleRoot()
+		if err != nil {
+			buildErr = err
+			return
+		}
+		dir, err := os.MkdirTemp("", "rdir-testui-")
+		if err != nil {
+			buildErr = err
+			return
+		}
+		buildPath = filepath.Join(dir, "rdir")
+		cmd := exec.Command("go", "build", "-o", buildPath, "./cmd/rdir")
+		cmd.Dir = root
+		buildOutput, buildErr = cmd.CombinedOutput()
+	})
+	if buildErr != nil {
+		t.Fatalf("testui: build rdir: %v\n%s", buildErr, buildOutput)
+	}
+	return buildPath
+}
+
+// moduleRoot finds the directory holding go.mod from this file's location.
+func moduleRoot() (string, error) {
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		return "", errors.New("cannot locate the testui package source")
+	}
+	dir := filepath.Dir(file)
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no go.mod above %s", file)
+		}
+		dir = parent
+	}
+}
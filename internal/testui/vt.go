@@ -0,0 +1,415 @@
+package testui
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// vt is a minimal VT100/xterm emulator: enough of the cursor movement,
+// erase, scroll and alternate-screen sequences tcell and the pager emit to
+// reconstruct the visible text. Colors and attributes are dropped.
+type vt struct {
+	width, height int
+	cells         [][]rune
+	main          [][]rune // primary buffer while the alternate screen is up
+	x, y          int
+	savedX        int
+	savedY        int
+	top, bottom   int // scroll region, inclusive rows
+	pending       bool
+	lastRune      rune
+
+	state   vtState
+	params  []byte
+	partial []byte // incomplete UTF-8 sequence carried between writes
+}
+
+type vtState int
+
+const (
+	vtGround vtState = iota
+	vtEscape
+	vtCSI
+	vtOSC
+	vtOSCEscape
+	vtCharset
+	vtDCS
+)
+
+// wideFill marks the right half of a double-width character.
+const wideFill = -1
+
+func newVT(width, height int) *vt {
+	t := &vt{width: width, height: height}
+	t.cells = t.blank()
+	t.top, t.bottom = 0, height-1
+	return t
+}
+
+func (t *vt) blank() [][]rune {
+	rows := make([][]rune, t.height)
+	for i := range rows {
+		rows[i] = t.blankRow()
+	}
+	return rows
+}
+
+func (t *vt) blankRow() []rune {
+	row := make([]rune, t.width)
+	for i := range row {
+		row[i] = ' '
+	}
+	return row
+}
+
+// Write feeds terminal output to the emulator.
+func (t *vt) Write(p []byte) (int, error) {
+	data := p
+	if len(t.partial) > 0 {
+		data = append(t.partial, p...)
+		t.partial = nil
+	}
+	for len(data) > 0 {
+		b := data[0]
+		if t.state != vtGround || b < utf8.RuneSelf {
+			t.byte(b)
+			data = data[1:]
+			continue
+		}
+		if !utf8.FullRune(data) {
+			t.partial = append([]byte(nil), data...)
+			break
+		}
+		r, size := utf8.DecodeRune(data)
+		t.print(r)
+		data = data[size:]
+	}
+	return len(p), nil
+}
+
+func (t *vt) byte(b byte) {
+	switch t.state {
+	case vtGround:
+		switch b {
+		case 0x1b:
+			t.state = vtEscape
+		case '\r':
+			t.x, t.pending = 0, false
+		case '\n', '\v', '\f':
+			t.lineFeed()
+		case '\b':
+			if t.x > 0 {
+				t.x--
+			}
+			t.pending = false
+		case '\t':
+			t.x = min((t.x/8+1)*8, t.width-1)
+		default:
+			if b >= 0x20 && b != 0x7f {
+				t.print(rune(b))
+			}
+		}
+	case vtEscape:
+		t.state = vtGround
+		switch b {
+		case '[':
+			t.state, t.params = vtCSI, t.params[:0]
+		case ']':
+			t.state = vtOSC
+		case 'P', '_', '^':
+			t.state = vtDCS
+		case '(', ')', '*', '+':
+			t.state = vtCharset
+		case '7':
+			t.savedX, t.savedY = t.x, t.y
+		case '8':
+			t.x, t.y, t.pending = t.savedX, t.savedY, false
+		case 'D':
+			t.lineFeed()
+		case 'E':
+			t.x = 0
+			t.lineFeed()
+		case 'M':
+			if t.y == t.top {
+				t.scrollDown(1)
+			} else if t.y > 0 {
+				t.y--
+			}
+		case 'c':
+			*t = *newVT(t.width, t.height)
+		}
+	case vtCharset:
+		t.state = vtGround
+	case vtOSC, vtDCS:
+		switch b {
+		case 0x07:
+			t.state = vtGround
+		case 0x1b:
+			t.state = vtOSCEscape
+		}
+	case vtOSCEscape:
+		t.state = vtGround
+		if b != '\\' {
+			t.byte(b)
+		}
+	case vtCSI:
+		if b >= 0x40 && b <= 0x7e {
+			t.state = vtGround
+			t.csi(b, string(t.params))
+			return
+		}
+		t.params = append(t.params, b)
+	}
+}
+
+func (t *vt) print(r rune) {
+	w := runewidth.RuneWidth(r)
+	if w == 0 {
+		return
+	}
+	if t.pending || t.x+w > t.width {
+		t.x, t.pending = 0, false
+		t.lineFeed()
+	}
+	t.cells[t.y][t.x] = r
+	if w == 2 {
+		t.cells[t.y][t.x+1] = wideFill
+	}
+	t.lastRune = r
+	t.x += w
+	if t.x >= t.width {
+		t.x, t.pending = t.width-1, true
+	}
+}
+
+func (t *vt) lineFeed() {
+	t.pending = false
+	if t.y == t.bottom {
+		t.scrollUp(1)
+		return
+	}
+	if t.y < t.height-1 {
+		t.y++
+	}
+}
+
+func (t *vt) scrollUp(n int) {
+	for ; n > 0; n-- {
+		copy(t.cells[t.top:t.bottom], t.cells[t.top+1:t.bottom+1])
+		t.cells[t.bottom] = t.blankRow()
+	}
+}
+
+func (t *vt) scrollDown(n int) {
+	for ; n > 0; n-- {
+		copy(t.cells[t.top+1:t.bottom+1], t.cells[t.top:t.bottom])
+		t.cells[t.top] = t.blankRow()
+	}
+}
+
+func (t *vt) csi(final byte, params string) {
+	private := strings.HasPrefix(params, "?") || strings.HasPrefix(params, ">") || strings.HasPrefix(params, "=")
+	if private {
+		params = params[1:]
+	}
+	args := parseParams(params)
+	arg := func(i, def int) int {
+		if i < len(args) && args[i] > 0 {
+			return args[i]
+		}
+		return def
+	}
+	if private {
+		if final == 'h' || final == 'l' {
+			for _, mode := range args {
+				if mode == 1049 || mode == 1047 || mode == 47 {
+					t.altScreen(final == 'h')
+				}
+			}
+		}
+		return
+	}
+
+	t.pending = final == 'm' && t.pending
+	switch final {
+	case 'A':
+		t.y = max(t.y-arg(0, 1), 0)
+	case 'B', 'e':
+		t.y = min(t.y+arg(0, 1), t.height-1)
+	case 'C', 'a':
+		t.x = min(t.x+arg(0, 1), t.width-1)
+	case 'D':
+		t.x = max(t.x-arg(0, 1), 0)
+	case 'E':
+		t.x, t.y = 0, min(t.y+arg(0, 1), t.height-1)
+	case 'F':
+		t.x, t.y = 0, max(t.y-arg(0, 1), 0)
+	case 'G', '`':
+		t.x = clamp(arg(0, 1)-1, t.width-1)
+	case 'd':
+		t.y = clamp(arg(0, 1)-1, t.height-1)
+	case 'H', 'f':
+		t.y = clamp(arg(0, 1)-1, t.height-1)
+		t.x = clamp(arg(1, 1)-1, t.width-1)
+	case 'J':
+		t.eraseDisplay(arg(0, 0))
+	case 'K':
+		t.eraseLine(arg(0, 0))
+	case 'X':
+		row := t.cells[t.y]
+		for i := t.x; i < min(t.x+arg(0, 1), t.width); i++ {
+			row[i] = ' '
+		}
+	case 'P':
+		row := t.cells[t.y]
+		n := min(arg(0, 1), t.width-t.x)
+		copy(row[t.x:], row[t.x+n:])
+		for i := t.width - n; i < t.width; i++ {
+			row[i] = ' '
+		}
+	case '@':
+		row := t.cells[t.y]
+		n := min(arg(0, 1), t.width-t.x)
+		copy(row[t.x+n:], row[t.x:t.width-n])
+		for i := t.x; i < t.x+n; i++ {
+			row[i] = ' '
+		}
+	case 'L', 'M':
+		if t.y < t.top || t.y > t.bottom {
+			return
+		}
+		top := t.top
+		t.top = t.y
+		if final == 'L' {
+			t.scrollDown(arg(0, 1))
+		} else {
+			t.scrollUp(arg(0, 1))
+		}
+		t.top = top
+	case 'S':
+		t.scrollUp(arg(0, 1))
+	case 'T':
+		t.scrollDown(arg(0, 1))
+	case 'b':
+		if t.lastRune != 0 {
+			for i := 0; i < arg(0, 1); i++ {
+				t.print(t.lastRune)
+			}
+		}
+	case 'r':
+		top, bottom := arg(0, 1)-1, arg(1, t.height)-1
+		if top < bottom && bottom < t.height {
+			t.top, t.bottom = top, bottom
+		}
+		t.x, t.y = 0, 0
+	case 's':
+		t.savedX, t.savedY = t.x, t.y
+	case 'u':
+		t.x, t.y = t.savedX, t.savedY
+	}
+}
+
+func (t *vt) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		t.eraseLine(0)
+		for y := t.y + 1; y < t.height; y++ {
+			t.cells[y] = t.blankRow()
+		}
+	case 1:
+		t.eraseLine(1)
+		for y := 0; y < t.y; y++ {
+			t.cells[y] = t.blankRow()
+		}
+	case 2, 3:
+		t.cells = t.blank()
+	}
+}
+
+func (t *vt) eraseLine(mode int) {
+	row := t.cells[t.y]
+	from, to := t.x, t.width
+	switch mode {
+	case 1:
+		from, to = 0, t.x+1
+	case 2:
+		from = 0
+	}
+	for i := from; i < min(to, t.width); i++ {
+		row[i] = ' '
+	}
+}
+
+func (t *vt) altScreen(on bool) {
+	if on == (t.main != nil) {
+		return
+	}
+	if on {
+		t.main = t.cells
+		t.savedX, t.savedY = t.x, t.y
+		t.cells = t.blank()
+		return
+	}
+	t.cells, t.main = t.main, nil
+	t.x, t.y = t.savedX, t.savedY
+}
+
+func (t *vt) resize(width, height int) {
+	resizeRows := func(rows [][]rune) [][]rune {
+		out := make([][]rune, height)
+		for y := range out {
+			out[y] = make([]rune, width)
+			for x := range out[y] {
+				out[y][x] = ' '
+				if y < len(rows) && x < len(rows[y]) {
+					out[y][x] = rows[y][x]
+				}
+			}
+		}
+		return out
+	}
+	t.cells = resizeRows(t.cells)
+	if t.main != nil {
+		t.main = resizeRows(t.main)
+	}
+	t.width, t.height = width, height
+	t.top, t.bottom = 0, height-1
+	t.x, t.y = clamp(t.x, width-1), clamp(t.y, height-1)
+}
+
+// lines returns the screen as text, one string per row with trailing blanks
+// trimmed.
+func (t *vt) lines() []string {
+	out := make([]string, t.height)
+	var b strings.Builder
+	for y, row := range t.cells {
+		b.Reset()
+		for _, r := range row {
+			if r != wideFill {
+				b.WriteRune(r)
+			}
+		}
+		out[y] = strings.TrimRight(b.String(), " ")
+	}
+	return out
+}
+
+func parseParams(s string) []int {
+	if s == "" {
+		return nil
+	}
+	fields := strings.Split(strings.ReplaceAll(s, ":", ";"), ";")
+	args := make([]int, 0, len(fields))
+	for _, f := range fields {
+		n, _ := strconv.Atoi(f)
+		args = append(args, n)
+	}
+	return args
+}
+
+func clamp(v, hi int) int {
+	return max(0, min(v, hi))
+}
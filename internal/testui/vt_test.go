@@ -0,0 +1,53 @@
+package testui
+
+import "testing"
+
+func TestVTCursorAndErase(t *testing.T) {
+	term := newVT(10, 3)
+	_, _ = term.Write([]byte("hello\r\nworld\x1b[1;3Hxy\x1b[2;2H\x1b[K"))
+	lines := term.lines()
+	if lines[0] != "hexyo" || lines[1] != "w" || lines[2] != "" {
+		t.Fatalf("unexpected screen %q", lines)
+	}
+}
+
+func TestVTAlternateScreenRestoresMain(t *testing.T) {
+	term := newVT(10, 2)
+	_, _ = term.Write([]byte("shell$"))
+	_, _ = term.Write([]byte("\x1b[?1049h\x1b[Hpager"))
+	if got := term.lines()[0]; got != "pager" {
+		t.Fatalf("alternate screen shows %q", got)
+	}
+	_, _ = term.Write([]byte("\x1b[?1049l"))
+	if got := term.lines()[0]; got != "shell$" {
+		t.Fatalf("main screen shows %q after leaving the alternate screen", got)
+	}
+}
+
+func TestVTWideRunesAndSplitUTF8(t *testing.T) {
+	term := newVT(10, 1)
+	data := []byte("日本x")
+	_, _ = term.Write(data[:2])
+	_, _ = term.Write(data[2:])
+	if got := term.lines()[0]; got != "日本x" {
+		t.Fatalf("got %q", got)
+	}
+	_, _ = term.Write([]byte("\x1b[1;6H"))
+	_, _ = term.Write([]byte("a\x1b[3b"))
+	if got := term.lines()[0]; got != "日本xaaaa" {
+		t.Fatalf("repeat: got %q", got)
+	}
+}
+
+func TestVTScrollRegion(t *testing.T) {
+	term := newVT(5, 4)
+	_, _ = term.Write([]byte("top\r\n1\r\n2\r\nbot"))
+	_, _ = term.Write([]byte("\x1b[2;3r\x1b[3;1H\n"))
+	lines := term.lines()
+	want := []string{"top", "2", "", "bot"}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Fatalf("line %d: got %q, want %q (screen %q)", i, lines[i], want[i], lines)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package textutil
+
+import (
+	"sync/atomic"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/uniseg"
+)
+
+// ambiguousWide is whether East Asian ambiguous-width characters (box
+// drawing, Greek and Cyrillic letters, "…", "·" and the like) take two cells.
+var ambiguousWide atomic.Bool
+
+// SetAmbiguousWide makes DisplayWidth, and the go-runewidth tables tcell lays
+// cells out with, count ambiguous-width characters as two cells (wide) or
+// one. Call it before the screen is initialized; it applies to the whole
+// process.
+func SetAmbiguousWide(wide bool) {
+	ambiguousWide.Store(wide)
+	runewidth.DefaultCondition.EastAsianWidth = wide
+}
+
+// AmbiguousWide reports the setting made by SetAmbiguousWide.
+func AmbiguousWide() bool {
+	return ambiguousWide.Load()
+}
+
+// wideDisplayWidth is DisplayWidth with ambiguous-width clusters counted as
+// two cells.
+func wideDisplayWidth(text string) int {
+	width := 0
+	state := -1
+	for len(text) > 0 {
+		var cluster string
+		var w int
+		cluster, text, w, state = uniseg.FirstGraphemeClusterInString(text, state)
+		if w == 1 {
+			if r, _ := utf8.DecodeRuneInString(cluster); r >= utf8.RuneSelf && runewidth.IsAmbiguousWidth(r) {
+				w = 2
+			}
+		}
+		width += w
+	}
+	return width
+}
@@ -24,3 +24,32 @@ func TestDisplayWidthGraphemeClusters(t *testing.T) {
 		})
 	}
 }
+
+func TestDisplayWidthAmbiguous(t *testing.T) {
+	defer SetAmbiguousWide(false)
+	tests := []struct {
+		text         string
+		narrow, wide int
+	}{
+		{"abc", 3, 3},
+		{"·", 1, 2},
+		{"…", 1, 2},
+		{"─┼─", 3, 6},
+		{"αβγ", 3, 6},
+		{"日本", 4, 4},
+		{"⚠️", 2, 2},
+		{"a·b", 3, 4},
+	}
+	for _, wide := range []bool{false, true} {
+		SetAmbiguousWide(wide)
+		for _, tt := range tests {
+			want := tt.narrow
+			if wide {
+				want = tt.wide
+			}
+			if got := DisplayWidth(tt.text); got != want {
+				t.Fatalf("wide=%v DisplayWidth(%q)=%d want %d", wide, tt.text, got, want)
+			}
+		}
+	}
+}
@@ -102,7 +102,91 @@ func HasFormattingRunes(text string) bool {
 	return false
 }
 
+// FormattingRuneLabel returns the visible label (for example "⟪RLO⟫") that
+// sanitizing substitutes for a bidi or zero-width formatting rune.
+func FormattingRuneLabel(r rune) (string, bool) {
+	label, ok := formattingRuneLabels[r]
+	return label, ok
+}
+
 func isFormattingRune(r rune) bool {
 	_, ok := formattingRuneLabels[r]
 	return ok
 }
+
+// maxSGRParamBytes bounds the parameter string of a kept SGR sequence so a
+// malformed file cannot smuggle a huge escape through.
+const maxSGRParamBytes = 64
+
+// SanitizeTerminalTextKeepSGR is SanitizeTerminalText for text that may carry
+// its own colors (build logs, script output): complete SGR sequences
+// (ESC [ digits/;/: m) are kept, every other escape or control character is
+// replaced exactly as SanitizeTerminalText does, so cursor movement, screen
+// clearing, OSC titles and the like can never reach the terminal.
+func SanitizeTerminalTextKeepSGR(text string) string {
+	if !strings.ContainsRune(text, '\x1b') {
+		return SanitizeTerminalText(text)
+	}
+	var b strings.Builder
+	b.Grow(len(text))
+	for len(text) > 0 {
+		esc := strings.IndexByte(text, '\x1b')
+		if esc == -1 {
+			b.WriteString(SanitizeTerminalText(text))
+			break
+		}
+		b.WriteString(SanitizeTerminalText(text[:esc]))
+		text = text[esc:]
+		if n := sgrSequenceLen(text); n > 0 {
+			b.WriteString(text[:n])
+			text = text[n:]
+			continue
+		}
+		b.WriteByte('?')
+		text = text[1:]
+	}
+	return b.String()
+}
+
+// sgrSequenceLen returns the length of the SGR sequence at the start of text,
+// or 0 when text does not start with one.
+func sgrSequenceLen(text string) int {
+	if len(text) < 3 || text[0] != '\x1b' || text[1] != '[' {
+		return 0
+	}
+	for i := 2; i < len(text) && i-2 <= maxSGRParamBytes; i++ {
+		switch c := text[i]; {
+		case c == 'm':
+			return i + 1
+		case c >= '0' && c <= '9', c == ';', c == ':':
+		default:
+			return 0
+		}
+	}
+	return 0
+}
+
+// StripSGR removes complete SGR sequences, leaving the text they color.
+func StripSGR(text string) string {
+	if !strings.ContainsRune(text, '\x1b') {
+		return text
+	}
+	var b strings.Builder
+	b.Grow(len(text))
+	for len(text) > 0 {
+		esc := strings.IndexByte(text, '\x1b')
+		if esc == -1 {
+			b.WriteString(text)
+			break
+		}
+		b.WriteString(text[:esc])
+		text = text[esc:]
+		if n := sgrSequenceLen(text); n > 0 {
+			text = text[n:]
+			continue
+		}
+		b.WriteByte(text[0])
+		text = text[1:]
+	}
+	return b.String()
+}
@@ -69,3 +69,30 @@ func containsRune(s string, target rune) bool {
 	}
 	return false
 }
+
+func TestSanitizeTerminalTextKeepSGR(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "plain", input: "ok", want: "ok"},
+		{name: "colors kept", input: "\x1b[1;31mFAIL\x1b[0m done", want: "\x1b[1;31mFAIL\x1b[0m done"},
+		{name: "truecolor kept", input: "\x1b[38;2;1;2;3mx\x1b[m", want: "\x1b[38;2;1;2;3mx\x1b[m"},
+		{name: "cursor movement", input: "a\x1b[2Jb\x1b[5;1Hc", want: "a?[2Jb?[5;1Hc"},
+		{name: "private mode", input: "\x1b[?25l", want: "?[?25l"},
+		{name: "osc title", input: "\x1b]0;pwned\x07x", want: "?]0;pwned?x"},
+		{name: "unterminated", input: "x\x1b[31", want: "x?[31"},
+		{name: "controls around", input: "\x1b[32mok\r\n", want: "\x1b[32mok  "},
+	}
+	for _, tc := range cases {
+		if got := SanitizeTerminalTextKeepSGR(tc.input); got != tc.want {
+			t.Fatalf("%s: expected %q, got %q", tc.name, tc.want, got)
+		}
+	}
+
+	long := "\x1b[" + strings.Repeat("1;", maxSGRParamBytes) + "m"
+	if got := SanitizeTerminalTextKeepSGR(long); got[0] != '?' {
+		t.Fatalf("expected an oversized SGR sequence to be neutralized, got %q", got)
+	}
+}
@@ -2,6 +2,7 @@ package textutil
 
 import (
 	"strings"
+	"unicode/utf8"
 
 	"github.com/mattn/go-runewidth"
 	"github.com/rivo/uniseg"
@@ -36,7 +37,20 @@ func ExpandTabs(text string, tabWidth int) string {
 	return builder.String()
 }
 
-// DisplayWidth reports the printable width of text accounting for wide runes.
+// DisplayWidth reports the printable width of text accounting for wide runes
+// and, with SetAmbiguousWide, ambiguous-width ones.
 func DisplayWidth(text string) int {
+	if ambiguousWide.Load() && !isASCII(text) {
+		return wideDisplayWidth(text)
+	}
 	return uniseg.StringWidth(text)
 }
+
+func isASCII(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if text[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package textutil
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+// Ellipsis marks where text was cut.
+const Ellipsis = "…"
+
+// nameTailStem is how many cells of a name's stem stay visible before its
+// extension, so "report-01.pdf" and "report-02.pdf" remain distinct.
+const nameTailStem = 4
+
+// MiddleCut finds the run of text to replace with Ellipsis so that the rest
+// fits in maxWidth cells, keeping up to tailWidth cells at the end and as
+// much of the start as fits. start and end are rune offsets of the removed
+// run, on grapheme boundaries; ok is false when text already fits.
+func MiddleCut(text string, maxWidth, tailWidth int) (start, end int, ok bool) {
+	if DisplayWidth(text) <= maxWidth {
+		return 0, 0, false
+	}
+	type cluster struct{ runes, width int }
+	var clusters []cluster
+	total := 0
+	g := uniseg.NewGraphemes(text)
+	for g.Next() {
+		n := len(g.Runes())
+		clusters = append(clusters, cluster{runes: n, width: max(DisplayWidth(g.Str()), 1)})
+		total += n
+	}
+
+	available := maxWidth - DisplayWidth(Ellipsis)
+	if available <= 0 {
+		return 0, total, true
+	}
+	tailWidth = min(max(tailWidth, 0), available)
+
+	end, used := total, 0
+	for i := len(clusters) - 1; i >= 0 && used+clusters[i].width <= tailWidth; i-- {
+		used += clusters[i].width
+		end -= clusters[i].runes
+	}
+	headWidth := available - used
+	used = 0
+	for _, c := range clusters {
+		if used+c.width > headWidth || start+c.runes > end {
+			break
+		}
+		used += c.width
+		start += c.runes
+	}
+	return start, end, true
+}
+
+// TruncateMiddle shortens text to maxWidth cells by replacing its middle
+// with Ellipsis, keeping up to tailWidth cells of the end.
+func TruncateMiddle(text string, maxWidth, tailWidth int) string {
+	if maxWidth <= 0 {
+		return ""
+	}
+	start, end, ok := MiddleCut(text, maxWidth, tailWidth)
+	if !ok {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:start]) + Ellipsis + string(runes[end:])
+}
+
+// NameTailWidth is how much of the end of a file name middle truncation
+// keeps: the extension plus a few cells of the stem, or a third of the
+// width for names without a short extension. At least a third of the width
+// is left for the start.
+func NameTailWidth(name string, maxWidth int) int {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	if ext == "" || stem == "" || len([]rune(ext)) > 8 {
+		return maxWidth / 3
+	}
+	available := maxWidth - DisplayWidth(Ellipsis)
+	return min(DisplayWidth(ext)+min(nameTailStem, max(maxWidth/4, 1)), available-available/3)
+}
+
+// TruncateName cuts the middle out of a file name that is wider than
+// maxWidth, keeping its start and extension: "quarterly-report-final.pdf"
+// becomes "quarterl…inal.pdf".
+func TruncateName(name string, maxWidth int) string {
+	return TruncateMiddle(name, maxWidth, NameTailWidth(name, maxWidth))
+}
@@ -0,0 +1,41 @@
+package textutil
+
+import "testing"
+
+func TestTruncateName(t *testing.T) {
+	tests := []struct {
+		name  string
+		width int
+		want  string
+	}{
+		{"short.txt", 20, "short.txt"},
+		{"quarterly-report-final.pdf", 17, "quarterl…inal.pdf"},
+		{"a-very-long-directory-name", 12, "a-very-…name"},
+		{"日本語のファイル名.txt", 12, "日本…名.txt"},
+		{"x.verylongextension", 10, "x.very…ion"},
+		{"anything", 1, "…"},
+		{"anything", 0, ""},
+	}
+	for _, tt := range tests {
+		got := TruncateName(tt.name, tt.width)
+		if got != tt.want {
+			t.Errorf("TruncateName(%q, %d) = %q, want %q", tt.name, tt.width, got, tt.want)
+		}
+		if tt.width > 0 && DisplayWidth(got) > tt.width {
+			t.Errorf("TruncateName(%q, %d) = %q is %d cells wide", tt.name, tt.width, got, DisplayWidth(got))
+		}
+	}
+}
+
+func TestMiddleCutKeepsGraphemesWhole(t *testing.T) {
+	text := "👨‍👩‍👧👨‍👩‍👧👨‍👩‍👧👨‍👩‍👧"
+	start, end, ok := MiddleCut(text, 5, 2)
+	if !ok {
+		t.Fatalf("expected a cut")
+	}
+	runes := []rune(text)
+	got := string(runes[:start]) + Ellipsis + string(runes[end:])
+	if got != "👨‍👩‍👧…👨‍👩‍👧" {
+		t.Fatalf("got %q (cut %d..%d)", got, start, end)
+	}
+}
@@ -13,6 +13,15 @@ type InputHandler struct {
 	state      *statepkg.AppState // Reference to current state for mode checking
 }
 
+// conflictChoiceKeys maps the conflict dialog keys to choices; the
+// upper-case key applies the choice to all remaining items.
+var conflictChoiceKeys = map[rune]statepkg.ConflictChoice{
+	's': statepkg.ConflictSkip,
+	'o': statepkg.ConflictOverwrite,
+	'r': statepkg.ConflictRename,
+	'n': statepkg.ConflictKeepNewer,
+}
+
 // NewInputHandler creates a new input handler
 func NewInputHandler(actionChan chan statepkg.Action) *InputHandler {
 	return &InputHandler{
@@ -49,6 +58,13 @@ func (ih *InputHandler) processKeyEvent(ev *tcell.EventKey) bool {
 	previewFullScreen := ih.state != nil && ih.state.PreviewFullScreen
 	previewAvailable := ih.state != nil && ih.state.PreviewData != nil
 
+	if direction, ok := historyKey(ev); ok && (ih.state == nil || !modalOpen(ih.state)) {
+		if action := HistoryAction(ih.state, direction); action != nil {
+			ih.actionChan <- action
+		}
+		return true
+	}
+
 	if helpVisible {
 		switch ev.Key() {
 		case tcell.KeyCtrlC:
@@ -68,6 +84,165 @@ func (ih *InputHandler) processKeyEvent(ev *tcell.EventKey) bool {
 		}
 	}
 
+	if ih.state != nil && ih.state.AuditView != nil {
+		switch ev.Key() {
+		case tcell.KeyCtrlC:
+			ih.actionChan <- statepkg.QuitAction{}
+			return false
+		case tcell.KeyUp:
+			ih.actionChan <- statepkg.AuditViewScrollAction{Delta: -1}
+		case tcell.KeyDown:
+			ih.actionChan <- statepkg.AuditViewScrollAction{Delta: 1}
+		case tcell.KeyPgUp:
+			ih.actionChan <- statepkg.AuditViewScrollAction{Delta: -1, Page: true}
+		case tcell.KeyPgDn:
+			ih.actionChan <- statepkg.AuditViewScrollAction{Delta: 1, Page: true}
+		case tcell.KeyHome:
+			ih.actionChan <- statepkg.AuditViewScrollAction{Delta: -len(ih.state.AuditView.Entries)}
+		case tcell.KeyEnd:
+			ih.actionChan <- statepkg.AuditViewScrollAction{Delta: len(ih.state.AuditView.Entries)}
+		case tcell.KeyEscape:
+			ih.actionChan <- statepkg.AuditViewCloseAction{}
+		case tcell.KeyRune:
+			switch ev.Rune() {
+			case 'A', 'q', 'Q':
+				ih.actionChan <- statepkg.AuditViewCloseAction{}
+			case 'k':
+				ih.actionChan <- statepkg.AuditViewScrollAction{Delta: -1}
+			case 'j':
+				ih.actionChan <- statepkg.AuditViewScrollAction{Delta: 1}
+			}
+		}
+		return true
+	}
+
+	if ih.state != nil && ih.state.PendingConfirm != nil {
+		switch {
+		case ev.Key() == tcell.KeyCtrlC:
+			ih.actionChan <- statepkg.QuitAction{}
+			return false
+		case ev.Key() == tcell.KeyEnter, ev.Key() == tcell.KeyRune && (ev.Rune() == 'y' || ev.Rune() == 'Y'):
+			ih.actionChan <- statepkg.ConfirmAcceptAction{}
+		default:
+			ih.actionChan <- statepkg.ConfirmCancelAction{}
+		}
+		return true
+	}
+
+	if ih.state != nil && ih.state.PathChoice != nil {
+		switch {
+		case ev.Key() == tcell.KeyCtrlC:
+			ih.actionChan <- statepkg.QuitAction{}
+			return false
+		case ev.Key() == tcell.KeyRune && ev.Rune() >= '1' && int(ev.Rune()-'1') < len(ih.state.PathChoice.Forms):
+			ih.actionChan <- statepkg.PathChoiceSelectAction{Index: int(ev.Rune() - '1')}
+		default:
+			ih.actionChan <- statepkg.PathChoiceCancelAction{}
+		}
+		return true
+	}
+
+	if ih.state != nil && ih.state.Prompt != nil {
+		if op, ok := lineEditOp(ev); ok {
+			ih.actionChan <- statepkg.PromptEditAction{Op: op}
+			return true
+		}
+		switch ev.Key() {
+		case tcell.KeyCtrlC:
+			ih.actionChan <- statepkg.QuitAction{}
+			return false
+		case tcell.KeyEscape:
+			ih.actionChan <- statepkg.PromptCancelAction{}
+		case tcell.KeyEnter:
+			ih.actionChan <- statepkg.PromptSubmitAction{}
+		case tcell.KeyTab:
+			ih.actionChan <- statepkg.PromptCycleModeAction{}
+		case tcell.KeyRune:
+			ih.actionChan <- statepkg.PromptCharAction{Char: ev.Rune()}
+		}
+		return true
+	}
+
+	if ih.state != nil && ih.state.Rename != nil {
+		if op, ok := lineEditOp(ev); ok {
+			ih.actionChan <- statepkg.RenameEditAction{Op: op}
+			return true
+		}
+		switch ev.Key() {
+		case tcell.KeyCtrlC:
+			ih.actionChan <- statepkg.QuitAction{}
+			return false
+		case tcell.KeyEscape:
+			ih.actionChan <- statepkg.RenameCancelAction{}
+		case tcell.KeyEnter:
+			ih.actionChan <- statepkg.RenameSubmitAction{}
+		case tcell.KeyRune:
+			ih.actionChan <- statepkg.RenameCharAction{Char: ev.Rune()}
+		}
+		return true
+	}
+
+	if ih.state != nil && ih.state.Conflicts != nil {
+		switch ev.Key() {
+		case tcell.KeyCtrlC:
+			ih.actionChan <- statepkg.QuitAction{}
+			return false
+		case tcell.KeyEscape:
+			ih.actionChan <- statepkg.ConflictCancelAction{}
+		case tcell.KeyEnter:
+			if ih.state.Conflicts.Summary() {
+				ih.actionChan <- statepkg.ConflictConfirmAction{}
+			} else {
+				// Keep the current decision (rename unless changed) and move on.
+				d := ih.state.Conflicts
+				ih.actionChan <- statepkg.ConflictChoiceAction{Choice: d.Choices[d.Index]}
+			}
+		case tcell.KeyLeft, tcell.KeyBackspace, tcell.KeyBackspace2:
+			ih.actionChan <- statepkg.ConflictBackAction{}
+		case tcell.KeyRune:
+			if choice, ok := conflictChoiceKeys[unicode.ToLower(ev.Rune())]; ok {
+				ih.actionChan <- statepkg.ConflictChoiceAction{Choice: choice, All: unicode.IsUpper(ev.Rune())}
+				break
+			}
+			switch ev.Rune() {
+			case 'p':
+				ih.actionChan <- statepkg.ConflictPatternAction{}
+			case 'u':
+				ih.actionChan <- statepkg.ConflictBackAction{}
+			case 'q':
+				ih.actionChan <- statepkg.ConflictCancelAction{}
+			}
+		}
+		return true
+	}
+
+	if ih.state != nil && ih.state.TypeAheadActive {
+		if ev.Key() == tcell.KeyRune && ev.Modifiers()&(tcell.ModCtrl|tcell.ModAlt) == 0 {
+			ih.actionChan <- statepkg.TypeAheadCharAction{Char: ev.Rune(), At: ev.When()}
+			return true
+		}
+		ih.actionChan <- statepkg.TypeAheadExitAction{}
+		if ev.Key() == tcell.KeyEscape {
+			return true
+		}
+		// Any other key leaves type-ahead and keeps its normal meaning.
+	}
+
+	if inSearchMode && !previewFullScreen {
+		if op, ok := lineEditOp(ev); ok {
+			var action statepkg.Action
+			if inGlobalSearch {
+				action = globalSearchEditAction(op)
+			} else {
+				action = filterEditAction(ev, op, ih.state.FilterLine())
+			}
+			if action != nil {
+				ih.actionChan <- action
+				return true
+			}
+		}
+	}
+
 	// Handle special keys first
 	switch ev.Key() {
 	case tcell.KeyEscape:
@@ -81,6 +256,10 @@ func (ih *InputHandler) processKeyEvent(ev *tcell.EventKey) bool {
 			}
 		} else if inFilterMode {
 			ih.actionChan <- statepkg.FilterClearAction{}
+		} else if ih.state != nil && ih.state.ArchiveJob != nil {
+			ih.actionChan <- statepkg.ArchiveCancelAction{}
+		} else if ih.state != nil && ih.state.ErrorBanner() != nil {
+			ih.actionChan <- statepkg.ErrorDismissAction{}
 		}
 		return true
 
@@ -119,15 +298,7 @@ func (ih *InputHandler) processKeyEvent(ev *tcell.EventKey) bool {
 		return true
 
 	case tcell.KeyRight:
-		if inGlobalSearch {
-			if ev.Modifiers()&tcell.ModCtrl != 0 {
-				ih.actionChan <- statepkg.GlobalSearchMoveCursorAction{Direction: "word-right"}
-			} else {
-				ih.actionChan <- statepkg.GlobalSearchMoveCursorAction{Direction: "right"}
-			}
-			return true
-		}
-		if previewFullScreen {
+		if previewFullScreen || inGlobalSearch {
 			return true
 		}
 
@@ -146,17 +317,15 @@ func (ih *InputHandler) processKeyEvent(ev *tcell.EventKey) bool {
 			return true
 		}
 
+		if ih.state != nil && ih.state.CanMoveInGrid(1) {
+			ih.actionChan <- statepkg.GridMoveAction{Columns: 1}
+			return true
+		}
 		ih.actionChan <- statepkg.RightArrowAction{}
 		return true
 
 	case tcell.KeyLeft:
-		if inGlobalSearch {
-			if ev.Modifiers()&tcell.ModCtrl != 0 {
-				ih.actionChan <- statepkg.GlobalSearchMoveCursorAction{Direction: "word-left"}
-			} else {
-				ih.actionChan <- statepkg.GlobalSearchMoveCursorAction{Direction: "left"}
-			}
-		} else if previewFullScreen {
+		if previewFullScreen {
 			ih.actionChan <- statepkg.PreviewExitFullScreenAction{}
 		} else if inFilterMode {
 			queryEmpty := true
@@ -168,6 +337,8 @@ func (ih *InputHandler) processKeyEvent(ev *tcell.EventKey) bool {
 			} else {
 				ih.actionChan <- statepkg.FilterResetQueryAction{}
 			}
+		} else if ih.state != nil && ih.state.CanMoveInGrid(-1) {
+			ih.actionChan <- statepkg.GridMoveAction{Columns: -1}
 		} else if !inSearchMode {
 			ih.actionChan <- statepkg.GoUpAction{}
 		}
@@ -196,9 +367,7 @@ func (ih *InputHandler) processKeyEvent(ev *tcell.EventKey) bool {
 	case tcell.KeyHome:
 		if previewFullScreen {
 			ih.actionChan <- statepkg.PreviewScrollToStartAction{}
-		} else if inGlobalSearch {
-			ih.actionChan <- statepkg.GlobalSearchMoveCursorAction{Direction: "home"}
-		} else {
+		} else if !inGlobalSearch {
 			ih.actionChan <- statepkg.ScrollToStartAction{}
 		}
 		return true
@@ -206,44 +375,58 @@ func (ih *InputHandler) processKeyEvent(ev *tcell.EventKey) bool {
 	case tcell.KeyEnd:
 		if previewFullScreen {
 			ih.actionChan <- statepkg.PreviewScrollToEndAction{}
-		} else if inGlobalSearch {
-			ih.actionChan <- statepkg.GlobalSearchMoveCursorAction{Direction: "end"}
-		} else {
+		} else if !inGlobalSearch {
 			ih.actionChan <- statepkg.ScrollToEndAction{}
 		}
 		return true
 
-	case tcell.KeyBackspace, tcell.KeyBackspace2:
-		if inGlobalSearch {
-			ih.actionChan <- statepkg.GlobalSearchBackspaceAction{}
-		} else if inFilterMode {
-			ih.actionChan <- statepkg.FilterBackspaceAction{}
+	case tcell.KeyBackspace, tcell.KeyBackspace2, tcell.KeyDelete:
+		// Text inputs took these above.
+		return true
+
+	case tcell.KeyTab:
+		if !inSearchMode && !previewFullScreen {
+			ih.actionChan <- statepkg.TabNextAction{}
 		}
 		return true
 
-	case tcell.KeyDelete:
-		if inGlobalSearch {
-			ih.actionChan <- statepkg.GlobalSearchDeleteAction{}
-		} else if inFilterMode {
-			ih.actionChan <- statepkg.FilterBackspaceAction{}
+	case tcell.KeyBacktab:
+		if !inSearchMode && !previewFullScreen {
+			ih.actionChan <- statepkg.TabPrevAction{}
 		}
 		return true
 
-	case tcell.KeyCtrlA:
-		if inGlobalSearch {
-			ih.actionChan <- statepkg.GlobalSearchMoveCursorAction{Direction: "home"}
+	case tcell.KeyF2:
+		if !inGlobalSearch && !previewFullScreen {
+			ih.actionChan <- statepkg.RenameStartAction{}
 		}
 		return true
 
+	case tcell.KeyCtrlA:
+		return true
+
 	case tcell.KeyCtrlE:
-		if inGlobalSearch {
-			ih.actionChan <- statepkg.GlobalSearchMoveCursorAction{Direction: "end"}
+		ih.actionChan <- statepkg.PreviewScrollDownAction{}
+		return true
+
+	case tcell.KeyCtrlY:
+		if !inGlobalSearch {
+			ih.actionChan <- statepkg.PreviewScrollUpAction{}
 		}
 		return true
 
 	case tcell.KeyCtrlW:
+		return true
+
+	case tcell.KeyCtrlS:
 		if inGlobalSearch {
-			ih.actionChan <- statepkg.GlobalSearchDeleteWordAction{}
+			ih.actionChan <- statepkg.GlobalSearchCycleSortAction{}
+		}
+		return true
+
+	case tcell.KeyCtrlD:
+		if inFilterMode {
+			ih.actionChan <- statepkg.FilterToggleScoresAction{}
 		}
 		return true
 
@@ -251,24 +434,12 @@ func (ih *InputHandler) processKeyEvent(ev *tcell.EventKey) bool {
 		r := ev.Rune()
 		if ev.Modifiers()&tcell.ModCtrl != 0 {
 			switch r {
-			case 'a', 'A':
-				if inGlobalSearch {
-					ih.actionChan <- statepkg.GlobalSearchMoveCursorAction{Direction: "home"}
-					return true
-				}
 			case 'e', 'E':
-				if inGlobalSearch {
-					ih.actionChan <- statepkg.GlobalSearchMoveCursorAction{Direction: "end"}
-					return true
-				}
-			case 'w', 'W':
-				if inGlobalSearch {
-					ih.actionChan <- statepkg.GlobalSearchDeleteWordAction{}
-					return true
-				}
-			case 'h', 'H':
-				if inFilterMode {
-					ih.actionChan <- statepkg.FilterBackspaceAction{}
+				ih.actionChan <- statepkg.PreviewScrollDownAction{}
+				return true
+			case 'y', 'Y':
+				if !inGlobalSearch {
+					ih.actionChan <- statepkg.PreviewScrollUpAction{}
 					return true
 				}
 			}
@@ -304,18 +475,128 @@ func (ih *InputHandler) processKeyEvent(ev *tcell.EventKey) bool {
 				ih.actionChan <- statepkg.QuitAction{}
 				return false
 
+			case 'Q':
+				ih.actionChan <- statepkg.QuitAndChangeAction{}
+				return false
+
 			case 'x':
 				if previewFullScreen {
 					ih.actionChan <- statepkg.PreviewExitFullScreenAction{}
 					return true
 				}
-				ih.actionChan <- statepkg.QuitAndChangeAction{}
-				return false
+				ih.actionChan <- statepkg.StageCutAction{}
+				return true
+
+			case 'c':
+				if !previewFullScreen {
+					ih.actionChan <- statepkg.StageCopyAction{}
+				}
+				return true
+
+			case 'X':
+				if !previewFullScreen {
+					ih.actionChan <- statepkg.ClearStagingAction{}
+				}
+				return true
+
+			case 'p':
+				if !previewFullScreen {
+					ih.actionChan <- statepkg.PasteStagedAction{}
+				}
+				return true
+
+			case ' ':
+				if !previewFullScreen {
+					ih.actionChan <- statepkg.ToggleMarkAction{}
+				}
+				return true
+
+			case 'u':
+				if !previewFullScreen {
+					ih.actionChan <- statepkg.ClearMarksAction{}
+				}
+				return true
+
+			case 't':
+				if !previewFullScreen {
+					ih.actionChan <- statepkg.TabNewAction{}
+				}
+				return true
+
+			case 'T':
+				if !previewFullScreen {
+					ih.actionChan <- statepkg.TabCloseAction{}
+				}
+				return true
+
+			case 'O':
+				if !previewFullScreen {
+					ih.actionChan <- statepkg.OpenMarkedInTabsAction{}
+				}
+				return true
+
+			case '\'':
+				if !previewFullScreen {
+					ih.actionChan <- statepkg.TypeAheadStartAction{}
+				}
+				return true
+
+			case '*':
+				if !previewFullScreen {
+					ih.actionChan <- statepkg.SelectPatternStartAction{}
+				}
+				return true
+
+			case 'H':
+				if !previewFullScreen {
+					ih.actionChan <- statepkg.RevealStartAction{}
+				}
+				return true
+
+			case 'L':
+				if !previewFullScreen {
+					ih.actionChan <- statepkg.NormalizeLineEndingsAction{}
+				}
+				return true
+
+			case 'A':
+				if !previewFullScreen {
+					ih.actionChan <- statepkg.AuditViewOpenAction{}
+				}
+				return true
+
+			case 'U':
+				if !previewFullScreen {
+					ih.actionChan <- statepkg.ExtractStartAction{}
+				}
+				return true
+
+			case 'C':
+				if !previewFullScreen {
+					ih.actionChan <- statepkg.CompressStartAction{}
+				}
+				return true
+
+			case 'J':
+				ih.actionChan <- statepkg.PreviewScrollDownAction{}
+				return true
+
+			case 'K':
+				ih.actionChan <- statepkg.PreviewScrollUpAction{}
+				return true
 
 			case '?':
 				ih.actionChan <- statepkg.HelpToggleAction{}
 				return true
 
+			case 'G':
+				ih.actionChan <- statepkg.ToggleListGridAction{}
+				return true
+
+			case 'Z':
+				ih.actionChan <- statepkg.ToggleEcoModeAction{}
+				return true
+
 			case '.':
 				if previewFullScreen {
 					return true
@@ -333,6 +614,10 @@ func (ih *InputHandler) processKeyEvent(ev *tcell.EventKey) bool {
 				ih.actionChan <- statepkg.OpenPagerAction{}
 				return true
 
+			case 'S':
+				ih.actionChan <- statepkg.PrivilegedReadAction{}
+				return true
+
 			case '/':
 				ih.actionChan <- statepkg.FilterStartAction{}
 				return true
@@ -349,14 +634,30 @@ func (ih *InputHandler) processKeyEvent(ev *tcell.EventKey) bool {
 				ih.actionChan <- statepkg.GoToHistoryAction{Direction: "forward"}
 				return true
 
+			case '{':
+				ih.actionChan <- statepkg.GoToSiblingAction{Direction: "prev"}
+				return true
+
+			case '}':
+				ih.actionChan <- statepkg.GoToSiblingAction{Direction: "next"}
+				return true
+
 			case 'y':
 				ih.actionChan <- statepkg.YankPathAction{}
 				return true
 
+			case 'Y':
+				ih.actionChan <- statepkg.YankPathAsAction{}
+				return true
+
 			case '~':
 				ih.actionChan <- statepkg.GoHomeAction{}
 				return true
 
+			case 'g':
+				ih.actionChan <- statepkg.GoToTargetAction{}
+				return true
+
 			case 'e', 'E':
 				if ih.state != nil && ih.state.EditorAvailable {
 					ih.actionChan <- statepkg.OpenEditorAction{}
@@ -364,7 +665,13 @@ func (ih *InputHandler) processKeyEvent(ev *tcell.EventKey) bool {
 				return true
 
 			case 'r', 'R':
-				ih.actionChan <- statepkg.RefreshDirectoryAction{}
+				var action statepkg.Action = statepkg.RefreshDirectoryAction{}
+				if ih.state != nil {
+					if banner := ih.state.ErrorBanner(); banner != nil && banner.CanRetry() {
+						action = statepkg.ErrorRetryAction{}
+					}
+				}
+				ih.actionChan <- action
 				return true
 
 			case '!':
@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/gdamore/tcell/v2"
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+	"github.com/kk-code-lab/rdir/internal/lineedit"
 	statepkg "github.com/kk-code-lab/rdir/internal/state"
 )
 
@@ -495,6 +497,7 @@ func TestInputHandlerLeftArrowResetsFilterQuery(t *testing.T) {
 	state := &statepkg.AppState{
 		FilterActive: true,
 		FilterQuery:  "abc",
+		FilterTail:   3, // cursor at the start of the query
 	}
 	handler.SetState(state)
 
@@ -784,3 +787,456 @@ func TestInputHandlerTildeTriggersGoHome(t *testing.T) {
 		t.Fatal("Expected GoHomeAction for tilde key")
 	}
 }
+
+func TestInputHandlerGTriggersGoToTarget(t *testing.T) {
+	actionChan := make(chan statepkg.Action, 1)
+	handler := NewInputHandler(actionChan)
+	handler.SetState(&statepkg.AppState{})
+
+	handler.ProcessEvent(tcell.NewEventKey(tcell.KeyRune, 'g', 0))
+	select {
+	case action := <-actionChan:
+		if _, ok := action.(statepkg.GoToTargetAction); !ok {
+			t.Fatalf("Expected GoToTargetAction, got %T", action)
+		}
+	default:
+		t.Fatal("Expected GoToTargetAction for g")
+	}
+}
+
+func TestArchiveKeys(t *testing.T) {
+	actionChan := make(chan statepkg.Action, 1)
+	handler := NewInputHandler(actionChan)
+	handler.SetState(&statepkg.AppState{})
+	handler.ProcessEvent(tcell.NewEventKey(tcell.KeyRune, 'U', 0))
+	if action := <-actionChan; action != (statepkg.ExtractStartAction{}) {
+		t.Fatalf("U: got %#v, want ExtractStartAction", action)
+	}
+	handler.ProcessEvent(tcell.NewEventKey(tcell.KeyRune, 'C', 0))
+	if action := <-actionChan; action != (statepkg.CompressStartAction{}) {
+		t.Fatalf("C: got %#v, want CompressStartAction", action)
+	}
+
+	handler.SetState(&statepkg.AppState{ArchiveJob: &statepkg.ArchiveJob{}})
+	handler.ProcessEvent(tcell.NewEventKey(tcell.KeyEscape, 0, 0))
+	if action := <-actionChan; action != (statepkg.ArchiveCancelAction{}) {
+		t.Fatalf("Esc: got %#v, want ArchiveCancelAction", action)
+	}
+}
+
+func TestGridKeys(t *testing.T) {
+	actionChan := make(chan statepkg.Action, 1)
+	handler := NewInputHandler(actionChan)
+	files := []statepkg.FileEntry{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}}
+	state := &statepkg.AppState{Files: files, ScreenHeight: 24, ListWidth: 40}
+	handler.SetState(state)
+
+	handler.ProcessEvent(tcell.NewEventKey(tcell.KeyRune, 'G', 0))
+	if action := <-actionChan; action != (statepkg.ToggleListGridAction{}) {
+		t.Fatalf("G: got %#v, want ToggleListGridAction", action)
+	}
+
+	handler.ProcessEvent(tcell.NewEventKey(tcell.KeyRight, 0, 0))
+	if action := <-actionChan; action != (statepkg.RightArrowAction{}) {
+		t.Fatalf("→ in one column: got %#v, want RightArrowAction", action)
+	}
+
+	// Four one-letter names spread over four columns of one row.
+	state.ListGridEnabled = true
+	handler.ProcessEvent(tcell.NewEventKey(tcell.KeyRight, 0, 0))
+	if action := <-actionChan; action != (statepkg.GridMoveAction{Columns: 1}) {
+		t.Fatalf("→ in the grid: got %#v, want GridMoveAction", action)
+	}
+	handler.ProcessEvent(tcell.NewEventKey(tcell.KeyLeft, 0, 0))
+	if action := <-actionChan; action != (statepkg.GoUpAction{}) {
+		t.Fatalf("← in the first column: got %#v, want GoUpAction", action)
+	}
+}
+
+func TestConflictDialogKeys(t *testing.T) {
+	actionChan := make(chan statepkg.Action, 1)
+	handler := NewInputHandler(actionChan)
+	dialog := &statepkg.ConflictDialog{
+		Items:   make([]statepkg.Conflict, 2),
+		Choices: []statepkg.ConflictChoice{statepkg.ConflictRename, statepkg.ConflictOverwrite},
+		Index:   1,
+	}
+	handler.SetState(&statepkg.AppState{Conflicts: dialog})
+
+	tests := []struct {
+		key  tcell.Key
+		r    rune
+		want statepkg.Action
+	}{
+		{tcell.KeyRune, 's', statepkg.ConflictChoiceAction{Choice: statepkg.ConflictSkip}},
+		{tcell.KeyRune, 'N', statepkg.ConflictChoiceAction{Choice: statepkg.ConflictKeepNewer, All: true}},
+		{tcell.KeyEnter, 0, statepkg.ConflictChoiceAction{Choice: statepkg.ConflictOverwrite}},
+		{tcell.KeyRune, 'p', statepkg.ConflictPatternAction{}},
+		{tcell.KeyLeft, 0, statepkg.ConflictBackAction{}},
+		{tcell.KeyEscape, 0, statepkg.ConflictCancelAction{}},
+	}
+	for _, tt := range tests {
+		handler.ProcessEvent(tcell.NewEventKey(tt.key, tt.r, 0))
+		if action := <-actionChan; action != tt.want {
+			t.Fatalf("key %v %q: got %#v, want %#v", tt.key, tt.r, action, tt.want)
+		}
+	}
+
+	dialog.Index = 2
+	handler.ProcessEvent(tcell.NewEventKey(tcell.KeyEnter, 0, 0))
+	if action := <-actionChan; action != (statepkg.ConflictConfirmAction{}) {
+		t.Fatalf("Enter on the summary: got %#v, want ConflictConfirmAction", action)
+	}
+}
+
+func TestPathChoiceKeys(t *testing.T) {
+	actionChan := make(chan statepkg.Action, 1)
+	handler := NewInputHandler(actionChan)
+	handler.SetState(&statepkg.AppState{})
+	handler.ProcessEvent(tcell.NewEventKey(tcell.KeyRune, 'Y', 0))
+	if action := <-actionChan; action != (statepkg.YankPathAsAction{}) {
+		t.Fatalf("Y: got %#v, want YankPathAsAction", action)
+	}
+
+	state := &statepkg.AppState{PathChoice: &statepkg.PathChoice{Forms: make([]fsutil.PathForm, 2)}}
+	tests := []struct {
+		key  rune
+		want statepkg.Action
+	}{
+		{key: '2', want: statepkg.PathChoiceSelectAction{Index: 1}},
+		{key: '3', want: statepkg.PathChoiceCancelAction{}},
+		{key: 'q', want: statepkg.PathChoiceCancelAction{}},
+	}
+	for _, tt := range tests {
+		actionChan := make(chan statepkg.Action, 1)
+		handler := NewInputHandler(actionChan)
+		handler.SetState(state)
+		handler.ProcessEvent(tcell.NewEventKey(tcell.KeyRune, tt.key, 0))
+		select {
+		case action := <-actionChan:
+			if action != tt.want {
+				t.Fatalf("key %q: got %#v, want %#v", tt.key, action, tt.want)
+			}
+		default:
+			t.Fatalf("key %q: no action", tt.key)
+		}
+	}
+}
+
+func TestAuditViewKeys(t *testing.T) {
+	actionChan := make(chan statepkg.Action, 1)
+	handler := NewInputHandler(actionChan)
+	handler.SetState(&statepkg.AppState{})
+	handler.ProcessEvent(tcell.NewEventKey(tcell.KeyRune, 'A', 0))
+	if action := <-actionChan; action != (statepkg.AuditViewOpenAction{}) {
+		t.Fatalf("A: got %#v, want AuditViewOpenAction", action)
+	}
+
+	state := &statepkg.AppState{AuditView: &statepkg.AuditView{Entries: make([]statepkg.AuditEntry, 5)}}
+	tests := []struct {
+		ev   *tcell.EventKey
+		want statepkg.Action
+	}{
+		{ev: tcell.NewEventKey(tcell.KeyDown, 0, 0), want: statepkg.AuditViewScrollAction{Delta: 1}},
+		{ev: tcell.NewEventKey(tcell.KeyPgUp, 0, 0), want: statepkg.AuditViewScrollAction{Delta: -1, Page: true}},
+		{ev: tcell.NewEventKey(tcell.KeyEnd, 0, 0), want: statepkg.AuditViewScrollAction{Delta: 5}},
+		{ev: tcell.NewEventKey(tcell.KeyEscape, 0, 0), want: statepkg.AuditViewCloseAction{}},
+		{ev: tcell.NewEventKey(tcell.KeyRune, 'A', 0), want: statepkg.AuditViewCloseAction{}},
+	}
+	for _, tt := range tests {
+		actionChan := make(chan statepkg.Action, 1)
+		handler := NewInputHandler(actionChan)
+		handler.SetState(state)
+		handler.ProcessEvent(tt.ev)
+		select {
+		case action := <-actionChan:
+			if action != tt.want {
+				t.Fatalf("key %v: got %#v, want %#v", tt.ev.Name(), action, tt.want)
+			}
+		default:
+			t.Fatalf("key %v: no action", tt.ev.Name())
+		}
+	}
+}
+
+func TestStagingKeysInNormalMode(t *testing.T) {
+	tests := []struct {
+		key  rune
+		want statepkg.Action
+	}{
+		{key: 'x', want: statepkg.StageCutAction{}},
+		{key: 'c', want: statepkg.StageCopyAction{}},
+		{key: 'p', want: statepkg.PasteStagedAction{}},
+		{key: 'X', want: statepkg.ClearStagingAction{}},
+	}
+
+	for _, tt := range tests {
+		actionChan := make(chan statepkg.Action, 1)
+		handler := NewInputHandler(actionChan)
+		handler.SetState(&statepkg.AppState{})
+
+		handler.ProcessEvent(tcell.NewEventKey(tcell.KeyRune, tt.key, 0))
+
+		select {
+		case action := <-actionChan:
+			if action != tt.want {
+				t.Fatalf("key %q: expected %T, got %T", tt.key, tt.want, action)
+			}
+		default:
+			t.Fatalf("key %q: expected an action", tt.key)
+		}
+	}
+}
+
+func TestShiftQQuitsAndChangesDirectory(t *testing.T) {
+	actionChan := make(chan statepkg.Action, 1)
+	handler := NewInputHandler(actionChan)
+	handler.SetState(&statepkg.AppState{})
+
+	if keepRunning := handler.ProcessEvent(tcell.NewEventKey(tcell.KeyRune, 'Q', 0)); keepRunning {
+		t.Fatal("expected Q to stop the event loop")
+	}
+	select {
+	case action := <-actionChan:
+		if _, ok := action.(statepkg.QuitAndChangeAction); !ok {
+			t.Fatalf("expected QuitAndChangeAction, got %T", action)
+		}
+	default:
+		t.Fatal("expected QuitAndChangeAction for Q")
+	}
+}
+
+func TestPendingConfirmCapturesKeys(t *testing.T) {
+	tests := []struct {
+		ev   *tcell.EventKey
+		want statepkg.Action
+	}{
+		{ev: tcell.NewEventKey(tcell.KeyRune, 'y', 0), want: statepkg.ConfirmAcceptAction{}},
+		{ev: tcell.NewEventKey(tcell.KeyEnter, 0, 0), want: statepkg.ConfirmAcceptAction{}},
+		{ev: tcell.NewEventKey(tcell.KeyRune, 'q', 0), want: statepkg.ConfirmCancelAction{}},
+		{ev: tcell.NewEventKey(tcell.KeyEscape, 0, 0), want: statepkg.ConfirmCancelAction{}},
+	}
+
+	for _, tt := range tests {
+		actionChan := make(chan statepkg.Action, 1)
+		handler := NewInputHandler(actionChan)
+		handler.SetState(&statepkg.AppState{PendingConfirm: &statepkg.ConfirmRequest{Prompt: "Open 5 tabs?"}})
+
+		if keepRunning := handler.ProcessEvent(tt.ev); !keepRunning {
+			t.Fatalf("%v: confirmation keys must not quit", tt.ev.Name())
+		}
+		select {
+		case action := <-actionChan:
+			if action != tt.want {
+				t.Fatalf("%v: expected %T, got %T", tt.ev.Name(), tt.want, action)
+			}
+		default:
+			t.Fatalf("%v: expected an action", tt.ev.Name())
+		}
+	}
+}
+
+func TestTypeAheadCapturesRunesAndExitsOnOtherKeys(t *testing.T) {
+	actionChan := make(chan statepkg.Action, 2)
+	handler := NewInputHandler(actionChan)
+	handler.SetState(&statepkg.AppState{TypeAheadActive: true})
+
+	handler.ProcessEvent(tcell.NewEventKey(tcell.KeyRune, 'q', 0))
+	if action, ok := (<-actionChan).(statepkg.TypeAheadCharAction); !ok || action.Char != 'q' {
+		t.Fatalf("expected TypeAheadCharAction for 'q', got %#v", action)
+	}
+
+	handler.ProcessEvent(tcell.NewEventKey(tcell.KeyDown, 0, 0))
+	if _, ok := (<-actionChan).(statepkg.TypeAheadExitAction); !ok {
+		t.Fatal("expected TypeAheadExitAction before navigation")
+	}
+	if _, ok := (<-actionChan).(statepkg.NavigateDownAction); !ok {
+		t.Fatal("expected the arrow key to keep navigating")
+	}
+}
+
+func TestPromptCapturesKeys(t *testing.T) {
+	actionChan := make(chan statepkg.Action, 1)
+	handler := NewInputHandler(actionChan)
+	handler.SetState(&statepkg.AppState{Prompt: &statepkg.TextPrompt{Kind: statepkg.PromptSelectPattern}})
+
+	handler.ProcessEvent(tcell.NewEventKey(tcell.KeyRune, 'q', 0))
+	if action, ok := (<-actionChan).(statepkg.PromptCharAction); !ok || action.Char != 'q' {
+		t.Fatalf("expected PromptCharAction for 'q', got %#v", action)
+	}
+	handler.ProcessEvent(tcell.NewEventKey(tcell.KeyTab, 0, 0))
+	if _, ok := (<-actionChan).(statepkg.PromptCycleModeAction); !ok {
+		t.Fatal("expected Tab to cycle the select mode")
+	}
+	handler.ProcessEvent(tcell.NewEventKey(tcell.KeyEnter, 0, 0))
+	if _, ok := (<-actionChan).(statepkg.PromptSubmitAction); !ok {
+		t.Fatal("expected Enter to submit the prompt")
+	}
+}
+
+func TestRenameKeys(t *testing.T) {
+	actionChan := make(chan statepkg.Action, 1)
+	handler := NewInputHandler(actionChan)
+	handler.SetState(&statepkg.AppState{})
+	handler.ProcessEvent(tcell.NewEventKey(tcell.KeyF2, 0, 0))
+	if action := <-actionChan; action != (statepkg.RenameStartAction{}) {
+		t.Fatalf("F2: got %#v, want RenameStartAction", action)
+	}
+
+	handler.SetState(&statepkg.AppState{Rename: &statepkg.InlineRename{Input: "notes.txt"}})
+	handler.ProcessEvent(tcell.NewEventKey(tcell.KeyRune, 'q', 0))
+	if action, ok := (<-actionChan).(statepkg.RenameCharAction); !ok || action.Char != 'q' {
+		t.Fatalf("expected RenameCharAction for 'q', got %#v", action)
+	}
+	handler.ProcessEvent(tcell.NewEventKey(tcell.KeyLeft, 0, 0))
+	if action, ok := (<-actionChan).(statepkg.RenameEditAction); !ok || action.Op != lineedit.Left {
+		t.Fatalf("expected Left to move the cursor, got %#v", action)
+	}
+	handler.ProcessEvent(tcell.NewEventKey(tcell.KeyEnter, 0, 0))
+	if _, ok := (<-actionChan).(statepkg.RenameSubmitAction); !ok {
+		t.Fatal("expected Enter to submit the rename")
+	}
+	handler.ProcessEvent(tcell.NewEventKey(tcell.KeyEscape, 0, 0))
+	if _, ok := (<-actionChan).(statepkg.RenameCancelAction); !ok {
+		t.Fatal("expected Esc to cancel the rename")
+	}
+}
+
+func TestAltArrowsGoThroughHistoryClosingViewsFirst(t *testing.T) {
+	back := tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModAlt)
+	forward := tcell.NewEventKey(tcell.KeyRight, 0, tcell.ModAlt)
+	cases := []struct {
+		name  string
+		state *statepkg.AppState
+		ev    *tcell.EventKey
+		want  statepkg.Action
+	}{
+		{"back", &statepkg.AppState{}, back, statepkg.GoToHistoryAction{Direction: "back"}},
+		{"forward", &statepkg.AppState{}, forward, statepkg.GoToHistoryAction{Direction: "forward"}},
+		{"back closes global search", &statepkg.AppState{GlobalSearchActive: true, GlobalSearchQuery: "x"}, back, statepkg.GlobalSearchClearAction{}},
+		{"forward from global search", &statepkg.AppState{GlobalSearchActive: true}, forward, statepkg.GoToHistoryAction{Direction: "forward"}},
+		{"back clears the filter", &statepkg.AppState{FilterActive: true, FilterQuery: "a"}, back, statepkg.FilterClearAction{}},
+		{"back leaves the full-screen preview", &statepkg.AppState{PreviewFullScreen: true}, back, statepkg.PreviewExitFullScreenAction{}},
+		{"back hides help", &statepkg.AppState{HelpVisible: true}, back, statepkg.HelpHideAction{}},
+		{"prompt keeps word moves", &statepkg.AppState{Prompt: &statepkg.TextPrompt{Input: "a b"}}, back, statepkg.PromptEditAction{Op: lineedit.WordLeft}},
+		{"ctrl moves by word in the filter", &statepkg.AppState{FilterActive: true, FilterQuery: "a b"}, tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModCtrl), statepkg.FilterEditAction{Op: lineedit.WordLeft}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actionChan := make(chan statepkg.Action, 1)
+			handler := NewInputHandler(actionChan)
+			handler.SetState(tc.state)
+			handler.ProcessEvent(tc.ev)
+			if got := <-actionChan; got != tc.want {
+				t.Fatalf("got %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+
+	actionChan := make(chan statepkg.Action, 1)
+	handler := NewInputHandler(actionChan)
+	handler.SetState(&statepkg.AppState{PreviewFullScreen: true})
+	handler.ProcessEvent(forward)
+	select {
+	case got := <-actionChan:
+		t.Fatalf("forward in the full-screen preview should do nothing, got %#v", got)
+	default:
+	}
+}
+
+func TestInlinePreviewScrollKeys(t *testing.T) {
+	actionChan := make(chan statepkg.Action, 1)
+	handler := NewInputHandler(actionChan)
+	handler.SetState(&statepkg.AppState{PreviewData: &statepkg.PreviewData{}})
+
+	cases := []struct {
+		event *tcell.EventKey
+		down  bool
+	}{
+		{tcell.NewEventKey(tcell.KeyRune, 'J', 0), true},
+		{tcell.NewEventKey(tcell.KeyRune, 'K', 0), false},
+		{tcell.NewEventKey(tcell.KeyCtrlE, 0, 0), true},
+		{tcell.NewEventKey(tcell.KeyCtrlY, 0, 0), false},
+	}
+	for _, tc := range cases {
+		handler.ProcessEvent(tc.event)
+		action := <-actionChan
+		if _, ok := action.(statepkg.PreviewScrollDownAction); tc.down && !ok {
+			t.Fatalf("%s: expected PreviewScrollDownAction, got %T", tc.event.Name(), action)
+		}
+		if _, ok := action.(statepkg.PreviewScrollUpAction); !tc.down && !ok {
+			t.Fatalf("%s: expected PreviewScrollUpAction, got %T", tc.event.Name(), action)
+		}
+	}
+}
+
+func TestLineEditKeysInTextInputs(t *testing.T) {
+	tests := []struct {
+		name  string
+		state *statepkg.AppState
+		ev    *tcell.EventKey
+		want  statepkg.Action
+	}{
+		{"filter left moves cursor", &statepkg.AppState{FilterActive: true, FilterQuery: "abc"}, tcell.NewEventKey(tcell.KeyLeft, 0, 0), statepkg.FilterEditAction{Op: lineedit.Left}},
+		{"filter ctrl-u", &statepkg.AppState{FilterActive: true, FilterQuery: "abc"}, tcell.NewEventKey(tcell.KeyCtrlU, 0, tcell.ModCtrl), statepkg.FilterEditAction{Op: lineedit.KillToStart}},
+		{"filter ctrl-a", &statepkg.AppState{FilterActive: true, FilterQuery: "abc"}, tcell.NewEventKey(tcell.KeyCtrlA, 0, tcell.ModCtrl), statepkg.FilterEditAction{Op: lineedit.Home}},
+		{"filter delete", &statepkg.AppState{FilterActive: true, FilterQuery: "abc", FilterTail: 1}, tcell.NewEventKey(tcell.KeyDelete, 0, 0), statepkg.FilterEditAction{Op: lineedit.Delete}},
+		{"filter right mid-query", &statepkg.AppState{FilterActive: true, FilterQuery: "abc", FilterTail: 1}, tcell.NewEventKey(tcell.KeyRight, 0, 0), statepkg.FilterEditAction{Op: lineedit.Right}},
+		{"filter home scrolls list", &statepkg.AppState{FilterActive: true, FilterQuery: "abc"}, tcell.NewEventKey(tcell.KeyHome, 0, 0), statepkg.ScrollToStartAction{}},
+		{"global search ctrl-k", &statepkg.AppState{GlobalSearchActive: true, GlobalSearchQuery: "abc"}, tcell.NewEventKey(tcell.KeyCtrlK, 0, tcell.ModCtrl), statepkg.GlobalSearchEditAction{Op: lineedit.KillToEnd}},
+		{"global search alt-b", &statepkg.AppState{GlobalSearchActive: true, GlobalSearchQuery: "a b"}, tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModAlt), statepkg.GlobalSearchMoveCursorAction{Direction: "word-left"}},
+		{"prompt alt-d", &statepkg.AppState{Prompt: &statepkg.TextPrompt{Input: "a b"}}, tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModAlt), statepkg.PromptEditAction{Op: lineedit.DeleteWordForward}},
+		{"prompt backspace", &statepkg.AppState{Prompt: &statepkg.TextPrompt{Input: "a"}}, tcell.NewEventKey(tcell.KeyBackspace2, 0, 0), statepkg.PromptEditAction{Op: lineedit.Backspace}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actionChan := make(chan statepkg.Action, 2)
+			handler := NewInputHandler(actionChan)
+			handler.SetState(tc.state)
+			handler.ProcessEvent(tc.ev)
+			select {
+			case got := <-actionChan:
+				if got != tc.want {
+					t.Fatalf("got %#v, want %#v", got, tc.want)
+				}
+			default:
+				t.Fatal("expected an action")
+			}
+		})
+	}
+}
+
+func TestErrorBannerKeys(t *testing.T) {
+	retryable := &statepkg.AppState{}
+	retryable.ReportError(statepkg.ErrorSourceGeneral, fmt.Errorf("clipboard failed"))
+	state := &statepkg.AppState{CurrentPath: "/tmp"}
+	_, _ = statepkg.NewStateReducer().Reduce(state, statepkg.DirectoryLoadResultAction{Path: "/tmp/gone", Err: fmt.Errorf("gone")})
+
+	tests := []struct {
+		name  string
+		state *statepkg.AppState
+		ev    *tcell.EventKey
+		want  statepkg.Action
+	}{
+		{"esc dismisses", retryable, tcell.NewEventKey(tcell.KeyEscape, 0, 0), statepkg.ErrorDismissAction{}},
+		{"r refreshes without retry", retryable, tcell.NewEventKey(tcell.KeyRune, 'r', 0), statepkg.RefreshDirectoryAction{}},
+		{"r retries", state, tcell.NewEventKey(tcell.KeyRune, 'r', 0), statepkg.ErrorRetryAction{}},
+		{"r refreshes without banner", &statepkg.AppState{}, tcell.NewEventKey(tcell.KeyRune, 'r', 0), statepkg.RefreshDirectoryAction{}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actionChan := make(chan statepkg.Action, 2)
+			handler := NewInputHandler(actionChan)
+			handler.SetState(tc.state)
+			handler.ProcessEvent(tc.ev)
+			select {
+			case got := <-actionChan:
+				if got != tc.want {
+					t.Fatalf("got %#v, want %#v", got, tc.want)
+				}
+			default:
+				t.Fatal("expected an action")
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package input
+
+import (
+	"github.com/gdamore/tcell/v2"
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
+)
+
+// historyKey reports whether ev is Alt+← (back) or Alt+→ (forward), the
+// history keys of GUI file managers. Ctrl+←/→ and Alt+B/F still move by word
+// in the text inputs.
+func historyKey(ev *tcell.EventKey) (string, bool) {
+	if ev.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) != tcell.ModAlt {
+		return "", false
+	}
+	switch ev.Key() {
+	case tcell.KeyLeft:
+		return "back", true
+	case tcell.KeyRight:
+		return "forward", true
+	}
+	return "", false
+}
+
+// modalOpen reports whether a prompt or dialog waits for an answer; history
+// keys and buttons leave those alone.
+func modalOpen(state *statepkg.AppState) bool {
+	return state.Prompt != nil || state.Rename != nil || state.PendingConfirm != nil ||
+		state.PathChoice != nil || state.Conflicts != nil
+}
+
+// HistoryAction maps back or forward (Alt+←/→, mouse buttons 4/5) to an
+// action. Back closes the help, audit log, full-screen preview, global
+// search or filter before it leaves the directory. It returns nil when there
+// is nothing to do.
+func HistoryAction(state *statepkg.AppState, direction string) statepkg.Action {
+	if state == nil {
+		return statepkg.GoToHistoryAction{Direction: direction}
+	}
+	if modalOpen(state) {
+		return nil
+	}
+	back := direction == "back"
+	switch {
+	case state.HelpVisible:
+		if back {
+			return statepkg.HelpHideAction{}
+		}
+		return nil
+	case state.AuditView != nil:
+		if back {
+			return statepkg.AuditViewCloseAction{}
+		}
+		return nil
+	case state.PreviewFullScreen:
+		if back {
+			return statepkg.PreviewExitFullScreenAction{}
+		}
+		return nil
+	case back && state.GlobalSearchActive:
+		return statepkg.GlobalSearchClearAction{}
+	case back && state.FilterActive:
+		return statepkg.FilterClearAction{}
+	}
+	return statepkg.GoToHistoryAction{Direction: direction}
+}
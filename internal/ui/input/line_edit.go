@@ -0,0 +1,105 @@
+package input
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/kk-code-lab/rdir/internal/lineedit"
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
+)
+
+// lineEditOp maps the readline keys every text input shares to an editing
+// command: arrows (by word with Ctrl or Alt, or Alt+B/F), Home/End and
+// Ctrl+A/E, Backspace and Delete, Ctrl+W or Alt+Backspace, Alt+D, Ctrl+U and
+// Ctrl+K.
+func lineEditOp(ev *tcell.EventKey) (lineedit.Op, bool) {
+	byWord := ev.Modifiers()&(tcell.ModCtrl|tcell.ModAlt) != 0
+	switch ev.Key() {
+	case tcell.KeyLeft:
+		if byWord {
+			return lineedit.WordLeft, true
+		}
+		return lineedit.Left, true
+	case tcell.KeyRight:
+		if byWord {
+			return lineedit.WordRight, true
+		}
+		return lineedit.Right, true
+	case tcell.KeyHome, tcell.KeyCtrlA:
+		return lineedit.Home, true
+	case tcell.KeyEnd, tcell.KeyCtrlE:
+		return lineedit.End, true
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if ev.Modifiers()&tcell.ModAlt != 0 {
+			return lineedit.DeleteWordBack, true
+		}
+		return lineedit.Backspace, true
+	case tcell.KeyDelete:
+		return lineedit.Delete, true
+	case tcell.KeyCtrlW:
+		return lineedit.DeleteWordBack, true
+	case tcell.KeyCtrlU:
+		return lineedit.KillToStart, true
+	case tcell.KeyCtrlK:
+		return lineedit.KillToEnd, true
+	case tcell.KeyRune:
+		switch mods := ev.Modifiers(); {
+		case mods&tcell.ModCtrl != 0:
+			switch ev.Rune() {
+			case 'a', 'A':
+				return lineedit.Home, true
+			case 'e', 'E':
+				return lineedit.End, true
+			case 'h', 'H':
+				return lineedit.Backspace, true
+			case 'w', 'W':
+				return lineedit.DeleteWordBack, true
+			case 'u', 'U':
+				return lineedit.KillToStart, true
+			case 'k', 'K':
+				return lineedit.KillToEnd, true
+			}
+		case mods&tcell.ModAlt != 0:
+			switch ev.Rune() {
+			case 'b', 'B':
+				return lineedit.WordLeft, true
+			case 'f', 'F':
+				return lineedit.WordRight, true
+			case 'd', 'D':
+				return lineedit.DeleteWordForward, true
+			}
+		}
+	}
+	return "", false
+}
+
+// globalSearchEditAction keeps the actions global search had before the
+// inputs shared their editing keys.
+func globalSearchEditAction(op lineedit.Op) statepkg.Action {
+	switch {
+	case op == lineedit.Backspace:
+		return statepkg.GlobalSearchBackspaceAction{}
+	case op == lineedit.Delete:
+		return statepkg.GlobalSearchDeleteAction{}
+	case op == lineedit.DeleteWordBack:
+		return statepkg.GlobalSearchDeleteWordAction{}
+	case op.Moves():
+		return statepkg.GlobalSearchMoveCursorAction{Direction: string(op)}
+	}
+	return statepkg.GlobalSearchEditAction{Op: op}
+}
+
+// filterEditAction returns the action for an editing key in the filter, or
+// nil to leave the key its list meaning: Home and End scroll the list, ←
+// at the start of the query clears it and → at its end opens the entry.
+func filterEditAction(ev *tcell.EventKey, op lineedit.Op, line lineedit.Line) statepkg.Action {
+	switch {
+	case ev.Key() == tcell.KeyHome || ev.Key() == tcell.KeyEnd:
+		return nil
+	case (op == lineedit.Left || op == lineedit.WordLeft) && line.Cursor == 0:
+		return nil
+	case (op == lineedit.Right || op == lineedit.WordRight) && line.Tail() == 0:
+		return nil
+	case op == lineedit.Backspace:
+		return statepkg.FilterBackspaceAction{}
+	}
+	return statepkg.FilterEditAction{Op: op}
+}
@@ -2,7 +2,14 @@
 
 package pager
 
+import "time"
+
 // startKeyReader is a no-op stub for unsupported platforms.
 func (p *PreviewPager) startKeyReader(done <-chan struct{}) (<-chan keyEvent, <-chan error, func()) {
 	return nil, nil, nil
 }
+
+// waitForInput cannot wait here, so a lone ESC is Escape straight away.
+func (p *PreviewPager) waitForInput(time.Duration) bool {
+	return false
+}
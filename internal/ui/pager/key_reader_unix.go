@@ -5,6 +5,7 @@ package pager
 import (
 	"errors"
 	"os"
+	"time"
 
 	"golang.org/x/sys/unix"
 )
@@ -33,6 +34,24 @@ func (p *PreviewPager) startKeyReader(done <-chan struct{}) (<-chan keyEvent, <-
 		inputFd := int(p.input.Fd())
 		cancelFd := int(cancelR.Fd())
 		for {
+			// Keys that arrived in one read (a paste, fast typing) are
+			// already buffered; select would not report them again.
+			if p.reader != nil && p.reader.Buffered() > 0 {
+				ev, err := p.readKeyEvent()
+				if err != nil {
+					select {
+					case errCh <- err:
+					default:
+					}
+					return
+				}
+				select {
+				case <-done:
+					return
+				case events <- ev:
+				}
+				continue
+			}
 			var readfds unix.FdSet
 			fdSetAdd(&readfds, inputFd)
 			fdSetAdd(&readfds, cancelFd)
@@ -84,6 +103,29 @@ func (p *PreviewPager) startKeyReader(done <-chan struct{}) (<-chan keyEvent, <-
 	return events, errCh, stop
 }
 
+// waitForInput waits up to timeout for the terminal to have bytes to read.
+func (p *PreviewPager) waitForInput(timeout time.Duration) bool {
+	if p.input == nil || timeout <= 0 {
+		return false
+	}
+	fd := int(p.input.Fd())
+	deadline := time.Now().Add(timeout)
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return false
+		}
+		var readfds unix.FdSet
+		fdSetAdd(&readfds, fd)
+		tv := unix.NsecToTimeval(remaining.Nanoseconds())
+		n, err := unix.Select(fd+1, &readfds, nil, nil, &tv)
+		if err == unix.EINTR {
+			continue
+		}
+		return err == nil && n > 0 && fdSetHas(&readfds, fd)
+	}
+}
+
 func fdSetAdd(set *unix.FdSet, fd int) {
 	if fd < 0 {
 		return
@@ -4,9 +4,11 @@ package pager
 
 import (
 	"errors"
+	"time"
 	"unicode/utf16"
 	"unsafe"
 
+	"github.com/kk-code-lab/rdir/internal/lineedit"
 	"golang.org/x/sys/windows"
 )
 
@@ -78,6 +80,7 @@ func (p *PreviewPager) startKeyReader(done <-chan struct{}) (<-chan keyEvent, <-
 
 		waitHandles := []windows.Handle{cancel, handle}
 		var records [16]inputRecord
+		var text utf16Pairer
 
 		for {
 			wait, err := windows.WaitForMultipleObjects(waitHandles, false, windows.INFINITE)
@@ -115,10 +118,14 @@ func (p *PreviewPager) startKeyReader(done <-chan struct{}) (<-chan keyEvent, <-
 				switch rec.EventType {
 				case evtKey:
 					ev := (*keyEventRecord)(unsafe.Pointer(&rec.Event[0]))
-					if ev == nil || ev.KeyDown == 0 {
+					if ev == nil || !carriesKey(ev) {
 						continue
 					}
-					if kev, ok := translateWindowsKey(ev); ok {
+					ch, ok := text.char(ev)
+					if !ok {
+						continue
+					}
+					if kev, ok := translateWindowsKey(ev, ch); ok {
 						select {
 						case <-done:
 							return
@@ -145,12 +152,43 @@ func (p *PreviewPager) startKeyReader(done <-chan struct{}) (<-chan keyEvent, <-
 	return events, errCh, stop
 }
 
-func translateWindowsKey(ev *keyEventRecord) (keyEvent, bool) {
+// carriesKey reports whether ev is a key press. Characters typed as
+// Alt+numpad codes arrive when Alt is released instead.
+func carriesKey(ev *keyEventRecord) bool {
+	return ev.KeyDown != 0 || (ev.VirtualKeyCode == windows.VK_MENU && ev.UnicodeChar != 0)
+}
+
+// utf16Pairer joins characters outside the Basic Multilingual Plane, such
+// as emoji from the input method, which the console delivers as two key
+// events each holding one UTF-16 surrogate.
+type utf16Pairer struct {
+	high rune
+}
+
+// char returns the character ev types, or 0 for none. ok is false while
+// the first half of a surrogate pair waits for the second.
+func (u *utf16Pairer) char(ev *keyEventRecord) (ch rune, ok bool) {
+	c := rune(ev.UnicodeChar)
+	high := u.high
+	u.high = 0
+	switch {
+	case c >= 0xd800 && c < 0xdc00:
+		u.high = c
+		return 0, false
+	case utf16.IsSurrogate(c):
+		if high == 0 {
+			return 0, false
+		}
+		return utf16.DecodeRune(high, c), true
+	}
+	return c, true
+}
+
+func translateWindowsKey(ev *keyEventRecord, ch rune) (keyEvent, bool) {
 	if ev == nil {
 		return keyEvent{}, false
 	}
 	vk := ev.VirtualKeyCode
-	ch := rune(ev.UnicodeChar)
 
 	switch vk {
 	case windows.VK_UP:
@@ -158,9 +196,11 @@ func translateWindowsKey(ev *keyEventRecord) (keyEvent, bool) {
 	case windows.VK_DOWN:
 		return keyEvent{kind: keyDown}, true
 	case windows.VK_LEFT:
-		return keyEvent{kind: keyLeft}, true
+		return keyEvent{kind: keyLeft, mod: windowsModifier(ev)}, true
 	case windows.VK_RIGHT:
-		return keyEvent{kind: keyRight}, true
+		return keyEvent{kind: keyRight, mod: windowsModifier(ev)}, true
+	case windows.VK_DELETE:
+		return keyEvent{kind: keyLineEdit, edit: lineedit.Delete}, true
 	case windows.VK_PRIOR: // PageUp
 		if ev.ControlKeyState&(windows.LEFT_CTRL_PRESSED|windows.RIGHT_CTRL_PRESSED|windows.LEFT_ALT_PRESSED|windows.RIGHT_ALT_PRESSED) != 0 {
 			return keyEvent{kind: keyJumpBackLarge}, true
@@ -180,21 +220,24 @@ func translateWindowsKey(ev *keyEventRecord) (keyEvent, bool) {
 	case windows.VK_RETURN:
 		return keyEvent{kind: keyEnter}, true
 	case windows.VK_BACK:
+		if ev.ControlKeyState&(windows.LEFT_ALT_PRESSED|windows.RIGHT_ALT_PRESSED) != 0 {
+			return keyEvent{kind: keyLineEdit, edit: lineedit.DeleteWordBack}, true
+		}
 		return keyEvent{kind: keyBackspace}, true
 	}
 
+	if ev.ControlKeyState&(windows.LEFT_ALT_PRESSED|windows.RIGHT_ALT_PRESSED) != 0 && ev.ControlKeyState&(windows.LEFT_CTRL_PRESSED|windows.RIGHT_CTRL_PRESSED) == 0 {
+		if op, ok := lineEditAlt(ch); ok {
+			return keyEvent{kind: keyLineEdit, edit: op}, true
+		}
+	}
+
 	if ch == 3 && (ev.ControlKeyState&(windows.LEFT_CTRL_PRESSED|windows.RIGHT_CTRL_PRESSED)) != 0 {
 		return keyEvent{kind: keyCtrlC}, true
 	}
 	if ch != 0 {
 		return runeToPagerKey(ch)
 	}
-	if ev.UnicodeChar == 0 {
-		return keyEvent{}, false
-	}
-	if r := utf16.Decode([]uint16{ev.UnicodeChar}); len(r) == 1 {
-		return runeToPagerKey(r[0])
-	}
 	return keyEvent{}, false
 }
 
@@ -222,12 +265,40 @@ func runeToPagerKey(ch rune) (keyEvent, bool) {
 		return keyEvent{kind: keyToggleInfo, ch: ch}, true
 	case 'f', 'F':
 		return keyEvent{kind: keyToggleFormat, ch: ch}, true
+	case 'a', 'A':
+		return keyEvent{kind: keyToggleANSI, ch: ch}, true
+	case 'm', 'M':
+		return keyEvent{kind: keyToggleScrollbar, ch: ch}, true
+	case 'd', 'D':
+		return keyEvent{kind: keyToggleHexUTF8, ch: ch}, true
+	case 's', 'S':
+		return keyEvent{kind: keyToggleSplit, ch: ch}, true
+	case 'r', 'R':
+		return keyEvent{kind: keyToggleReading, ch: ch}, true
+	case 'u', 'U':
+		return keyEvent{kind: keyToggleInspect, ch: ch}, true
+	case 't', 'T':
+		return keyEvent{kind: keyToggleTOC, ch: ch}, true
+	case 'z':
+		return keyEvent{kind: keyHiddenNext, ch: ch}, true
+	case 'Z':
+		return keyEvent{kind: keyHiddenList, ch: ch}, true
+	case 'v', 'V':
+		return keyEvent{kind: keyRecentFiles, ch: ch}, true
+	case '\t':
+		return keyEvent{kind: keySwitchPane}, true
 	case 'e', 'E':
 		return keyEvent{kind: keyOpenEditor, ch: ch}, true
 	case 'c':
 		return keyEvent{kind: keyCopyVisible, ch: ch}, true
 	case 'C':
 		return keyEvent{kind: keyCopyAll, ch: ch}, true
+	case 'P':
+		return keyEvent{kind: keyCopyAllViaFile, ch: ch}, true
+	case 'y', 'Y':
+		return keyEvent{kind: keyCopyRef, ch: ch}, true
+	case 'o', 'O':
+		return keyEvent{kind: keyExportQuickfix, ch: ch}, true
 	case '/':
 		return keyEvent{kind: keyStartSearch, ch: ch}, true
 	case ':':
@@ -259,8 +330,33 @@ func runeToPagerKey(ch rune) (keyEvent, bool) {
 	case 0x03:
 		return keyEvent{kind: keyCtrlC}, true
 	}
+	if op, ok := lineEditControl(ch); ok {
+		return keyEvent{kind: keyLineEdit, edit: op}, true
+	}
 	if ch >= 0x20 {
 		return keyEvent{kind: keyRune, ch: ch}, true
 	}
 	return keyEvent{}, false
 }
+
+// windowsModifier encodes Shift, Alt and Ctrl like the xterm modifier
+// parameter the byte-stream reader parses (1 + 1 Shift + 2 Alt + 4 Ctrl).
+func windowsModifier(ev *keyEventRecord) int {
+	mod := 1
+	if ev.ControlKeyState&windows.SHIFT_PRESSED != 0 {
+		mod++
+	}
+	if ev.ControlKeyState&(windows.LEFT_ALT_PRESSED|windows.RIGHT_ALT_PRESSED) != 0 {
+		mod += 2
+	}
+	if ev.ControlKeyState&(windows.LEFT_CTRL_PRESSED|windows.RIGHT_CTRL_PRESSED) != 0 {
+		mod += 4
+	}
+	return mod
+}
+
+// waitForInput is only reached by the byte-stream fallback reader; console
+// key events arrive whole, so a lone ESC needs no wait.
+func (p *PreviewPager) waitForInput(time.Duration) bool {
+	return false
+}
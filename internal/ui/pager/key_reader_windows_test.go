@@ -2,7 +2,12 @@
 
 package pager
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/kk-code-lab/rdir/internal/lineedit"
+	"golang.org/x/sys/windows"
+)
 
 func TestRuneToPagerKeyStartsBinarySearch(t *testing.T) {
 	ev, ok := runeToPagerKey(':')
@@ -27,4 +32,35 @@ func TestRuneToPagerKeyCtrlShortcuts(t *testing.T) {
 	if !ok || ev.kind != keyToggleBinarySearchLimit {
 		t.Fatalf("ctrl+l should toggle binary search limit, got %+v (ok=%v)", ev, ok)
 	}
+
+	ev, ok = runeToPagerKey(0x15) // Ctrl+U
+	if !ok || ev.kind != keyLineEdit || ev.edit != lineedit.KillToStart {
+		t.Fatalf("ctrl+u should clear the search input to the cursor, got %+v (ok=%v)", ev, ok)
+	}
+}
+
+func TestUTF16PairerJoinsSurrogates(t *testing.T) {
+	var text utf16Pairer
+	if _, ok := text.char(&keyEventRecord{KeyDown: 1, UnicodeChar: 0xd83d}); ok {
+		t.Fatalf("expected the high surrogate to wait for its pair")
+	}
+	ch, ok := text.char(&keyEventRecord{KeyDown: 1, UnicodeChar: 0xde00})
+	if !ok || ch != '😀' {
+		t.Fatalf("expected 😀, got %q (ok=%v)", ch, ok)
+	}
+	if _, ok := text.char(&keyEventRecord{KeyDown: 1, UnicodeChar: 0xde00}); ok {
+		t.Fatalf("expected a lone low surrogate to be dropped")
+	}
+	if ch, ok := text.char(&keyEventRecord{KeyDown: 1, UnicodeChar: 'ą'}); !ok || ch != 'ą' {
+		t.Fatalf("expected ą to pass through, got %q (ok=%v)", ch, ok)
+	}
+}
+
+func TestCarriesKeyAcceptsAltNumpadRelease(t *testing.T) {
+	if !carriesKey(&keyEventRecord{VirtualKeyCode: windows.VK_MENU, UnicodeChar: 'é'}) {
+		t.Fatalf("expected the Alt release carrying a character to count")
+	}
+	if carriesKey(&keyEventRecord{VirtualKeyCode: 'A', UnicodeChar: 'a'}) {
+		t.Fatalf("expected other key releases to be ignored")
+	}
 }
@@ -14,20 +14,30 @@ import (
 	"sync"
 	"time"
 
+	"github.com/kk-code-lab/rdir/internal/lineedit"
+	"github.com/kk-code-lab/rdir/internal/procwatch"
 	statepkg "github.com/kk-code-lab/rdir/internal/state"
 	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+	renderpkg "github.com/kk-code-lab/rdir/internal/ui/render"
 	"golang.org/x/term"
 )
 
 const (
 	binaryPreviewLineWidth  = 16
-	binaryPagerChunkSize    = 64 * 1024
-	binaryPagerMaxChunks    = 8
+	binaryPagerChunkSize    = 64 * 1024 // default; see binaryChunkPlan
+	binaryPagerMinChunkSize = 16 * 1024
+	binaryPagerMaxChunkSize = 512 * 1024
+	binaryPagerCacheBudget  = 4 << 20 // file bytes kept as formatted lines
+	binaryPagerMinChunks    = 4
+	binaryPagerMaxChunks    = 64
+	binaryChunkScreens      = 8 // a chunk spans at least this many screens
+	binaryReadaheadChunks   = 2
 	headerBarStyle          = "\x1b[48;5;238m\x1b[97m"
 	statusBarStyle          = "\x1b[48;5;236m\x1b[97m"
 	statusSuccessStyle      = "\x1b[48;5;22m\x1b[97m"
 	statusErrorStyle        = "\x1b[48;5;52m\x1b[97m"
 	statusWarnStyle         = "\x1b[48;5;178m\x1b[30m"
+	privilegedHeaderStyle   = "\x1b[48;5;124m\x1b[97m" // content read through RDIR_PRIVILEGED_HELPER
 	binaryJumpSmallBytes    = 4 * 1024
 	binaryJumpLargeBytes    = 64 * 1024
 	clipboardWarnBytes      = int64(16 * 1024 * 1024)
@@ -38,6 +48,7 @@ const (
 	searchHighlightFocusOn  = "\x1b[38;5;16;48;5;178m"
 	searchHighlightFocusOff = "\x1b[0m"
 	searchDebounceDelay     = 140 * time.Millisecond
+	resizeSettleDelay       = 40 * time.Millisecond // quiet time before a resize is redrawn
 )
 
 var (
@@ -50,6 +61,7 @@ var termGetSize = term.GetSize
 
 type PreviewPager struct {
 	state               *statepkg.AppState
+	altScreen           bool
 	editorCmd           []string
 	reducer             *statepkg.StateReducer
 	input               *os.File
@@ -61,7 +73,12 @@ type PreviewPager struct {
 	stopKeyReader       func()
 	width               int
 	height              int
+	termWidth           int // full terminal width; width excludes the scrollbar
 	wrapEnabled         bool
+	ansiColors          bool
+	contrast            *renderpkg.Contrast // nil when RDIR_MIN_CONTRAST is off
+	contrastStyles      map[string]string   // bar styles with contrast applied
+	theme               renderpkg.ColorTheme
 	lines               []string
 	lineWidths          []int
 	rawLines            []string
@@ -72,6 +89,7 @@ type PreviewPager struct {
 	formattedWidths     []int
 	formattedRules      []bool
 	formattedStyles     []string
+	markdownKey         string // layout the formatted markdown lines were reflowed for
 	rowSpans            []int
 	rowPrefix           []int
 	rowMetricsWidth     int
@@ -79,6 +97,7 @@ type PreviewPager struct {
 	binaryMode          bool
 	binarySource        *binaryPagerSource
 	rawTextSource       *textPagerSource
+	fingerprint         *fileFingerprint // what checkFileChange compares against
 	preloadLines        int
 	showInfo            bool
 	showHelp            bool
@@ -91,8 +110,10 @@ type PreviewPager struct {
 	restartKeys         bool
 	clipboardCmd        []string
 	clipboardFunc       func(string) error
+	largeCopyPending    bool // C was pressed once on content over clipboardWarnBytes
 	searchMode          bool
 	searchInput         []rune
+	searchTail          int // runes of searchInput after the cursor
 	searchQuery         string
 	searchHits          []searchHit
 	searchCursor        int
@@ -105,6 +126,21 @@ type PreviewPager struct {
 	searchQueryBinary   bool
 	searchQueryFullScan bool
 	searchFullScan      bool
+	memory              *statepkg.PagerMemory
+	split               *pagerSplit
+	inspect             *inspectCursor
+	showHiddenList      bool
+	hiddenListCursor    int
+	showTOC             bool
+	tocCursor           int
+	toc                 []tocEntry
+	tocKey              string
+	gitInfo             *gitInfoLookup // checkout of the file for copy references
+	tocScan             tocScanner     // scanner state of the lines scanned so far
+	tocScanned          int            // lines scanned into toc
+	showRecent          bool
+	recentCursor        int
+	switchTo            string // recent file picked to be shown next (SwitchTo)
 
 	wrapCacheWidth     int
 	wrapCacheFormatted bool
@@ -119,9 +155,13 @@ func NewPreviewPager(state *statepkg.AppState, editorCmd []string, reducer *stat
 	if state == nil || state.PreviewData == nil {
 		return nil, errors.New("preview data unavailable")
 	}
+	contrast := renderpkg.NewContrast(state.MinContrast, state.Background)
 	pager := &PreviewPager{
 		state:        state,
-		wrapEnabled:  state.PreviewWrap,
+		contrast:     contrast,
+		theme:        contrastTheme(contrast),
+		wrapEnabled:  state.PreviewDefaults.WrapFor(state.PreviewData.Name, state.PreviewWrap),
+		ansiColors:   state.PreviewANSIColors,
 		editorCmd:    append([]string(nil), editorCmd...),
 		reducer:      reducer,
 		clipboardCmd: append([]string(nil), clipboardCmd...),
@@ -131,6 +171,13 @@ func NewPreviewPager(state *statepkg.AppState, editorCmd []string, reducer *stat
 	return pager, nil
 }
 
+// SetAltScreen makes the pager draw in the terminal's alternate screen buffer
+// so it does not overwrite the shell scrollback. Callers enable it when the
+// main UI itself runs in the alternate screen.
+func (p *PreviewPager) SetAltScreen(enabled bool) {
+	p.altScreen = enabled
+}
+
 func (p *PreviewPager) Run() error {
 	if err := p.initTerminal(); err != nil {
 		return err
@@ -138,6 +185,7 @@ func (p *PreviewPager) Run() error {
 	defer p.cleanupTerminal()
 	defer p.persistLoadedLines()
 	defer p.syncBinaryPositionOnExit()
+	defer p.rememberFileState()
 
 	done := make(chan struct{})
 	defer close(done)
@@ -153,6 +201,18 @@ func (p *PreviewPager) Run() error {
 	p.updateSize()
 	p.applyWrapSetting()
 	p.syncBinaryPositionOnEnter()
+	p.restoreFileState()
+	p.applySearchSeed()
+	p.noteRecentFile()
+	p.prefetchGitInfo()
+	var watchC <-chan time.Time
+	if ticker := p.startFileWatch(); ticker != nil {
+		defer ticker.Stop()
+		watchC = ticker.C
+	}
+	// Resizes wait until the size settles, so a storm of SIGWINCH from a
+	// tiling window manager reflows the content once.
+	var resizeSettled <-chan time.Time
 	needsRender := true
 	for {
 		if needsRender {
@@ -188,6 +248,11 @@ func (p *PreviewPager) Run() error {
 				default:
 				}
 			}
+			select {
+			case <-watchC:
+				p.checkFileChange()
+			default:
+			}
 			event, err := p.readKeyEvent()
 			if err != nil {
 				return err
@@ -202,6 +267,9 @@ func (p *PreviewPager) Run() error {
 
 		select {
 		case <-resizeEvents:
+			resizeSettled = time.After(resizeSettleDelay)
+		case <-resizeSettled:
+			resizeSettled = nil
 			needsRender = true
 		case event := <-keyEvents:
 			if done := p.handleKey(event); done {
@@ -220,6 +288,10 @@ func (p *PreviewPager) Run() error {
 		case <-p.searchTimerC():
 			p.runPendingSearch()
 			needsRender = true
+		case <-watchC:
+			if p.checkFileChange() {
+				needsRender = true
+			}
 		}
 	}
 }
@@ -230,7 +302,7 @@ func (p *PreviewPager) Run() error {
 // It is intentionally lightweight: we only copy lines already fetched; display
 // metadata is omitted so the renderer will measure widths lazily.
 func (p *PreviewPager) persistLoadedLines() {
-	if p == nil || p.state == nil || p.state.PreviewData == nil || p.rawTextSource == nil {
+	if p == nil || p.state == nil || p.state.PreviewData == nil || p.rawTextSource == nil || p.rawTextSource.generate != nil {
 		return
 	}
 	count := p.rawTextSource.LineCount()
@@ -255,6 +327,7 @@ func (p *PreviewPager) persistLoadedLines() {
 	preview.TextLines = lines
 	preview.TextLineMeta = metas
 	preview.FormattedTextLines = nil
+	preview.FormattedANSILines = nil
 	preview.FormattedSegments = nil
 	preview.FormattedSegmentLineMeta = nil
 	preview.LineCount = count
@@ -414,9 +487,27 @@ func (p *PreviewPager) initTerminal() error {
 		return err
 	}
 	p.restoreTerm = rawState
+	p.enterAltScreen()
 	return nil
 }
 
+const (
+	altScreenEnter = "\x1b[?1049h"
+	altScreenExit  = "\x1b[?1049l"
+)
+
+func (p *PreviewPager) enterAltScreen() {
+	if p.altScreen {
+		p.writeString(altScreenEnter)
+	}
+}
+
+func (p *PreviewPager) exitAltScreen() {
+	if p.altScreen {
+		p.writeString(altScreenExit)
+	}
+}
+
 func (p *PreviewPager) cleanupTerminal() {
 	if p.binarySource != nil {
 		p.binarySource.Close()
@@ -437,10 +528,12 @@ func (p *PreviewPager) cleanupTerminal() {
 		_ = p.writer.Flush()
 	}
 	if p.writer != nil {
+		p.exitAltScreen()
 		p.writeString("\x1b[?25h")
 		p.writeString("\x1b[?7h")
 		_ = p.writer.Flush()
 	} else {
+		p.exitAltScreen()
 		p.writeString("\x1b[?25h")
 		p.writeString("\x1b[?7h")
 	}
@@ -479,6 +572,7 @@ func (p *PreviewPager) updateSize() {
 	}
 
 	if p.binarySource != nil && p.width > 0 {
+		p.binarySource.SetViewRows(p.height)
 		oldBytesPerLine := p.binarySource.bytesPerLine
 		p.binarySource.UpdateBytesPerLine(p.width)
 		newBytesPerLine := p.binarySource.bytesPerLine
@@ -505,7 +599,8 @@ func (p *PreviewPager) tryUpdateSizeFromFile(file *os.File) bool {
 	if err != nil || width <= 0 || height <= 0 {
 		return false
 	}
-	p.width = width
+	p.termWidth = width
+	p.width = width - p.scrollbarColumns(width)
 	p.height = height
 	return true
 }
@@ -575,6 +670,12 @@ func (p *PreviewPager) clampScroll(totalLines, visible int) {
 func (p *PreviewPager) handleKey(ev keyEvent) bool {
 	p.lastErr = nil
 
+	// Alt+→ is forward in the browser's history; the pager has nothing to go
+	// forward to, so it does not open or wrap anything.
+	if ev.kind == keyRight && hasAltModifier(ev.mod) {
+		return false
+	}
+
 	if p.showHelp {
 		switch ev.kind {
 		case keyToggleHelp, keyQuit, keyEscape, keyLeft:
@@ -584,8 +685,17 @@ func (p *PreviewPager) handleKey(ev keyEvent) bool {
 		}
 		return false
 	}
+	if p.showHiddenList {
+		return p.handleHiddenListKey(ev)
+	}
+	if p.showTOC {
+		return p.handleTOCKey(ev)
+	}
+	if p.showRecent {
+		return p.handleRecentKey(ev)
+	}
 
-	contentRows := p.height - (len(p.headerLines()) + 1) - 1
+	contentRows := p.viewHeight() - (len(p.headerLines()) + 1) - 1
 	if contentRows < 1 {
 		contentRows = 1
 	}
@@ -608,6 +718,13 @@ func (p *PreviewPager) handleKey(ev keyEvent) bool {
 		return false
 	}
 
+	if p.handleInspectKey(ev) {
+		return false
+	}
+	if ev.kind != keyCopyAll {
+		p.largeCopyPending = false
+	}
+
 	switch ev.kind {
 	case keyQuit, keyEscape, keyCtrlC, keyLeft:
 		return true
@@ -691,12 +808,15 @@ func (p *PreviewPager) handleKey(ev keyEvent) bool {
 			break
 		}
 		p.wrapEnabled = !p.wrapEnabled
-		p.state.PreviewWrap = p.wrapEnabled
+		if !p.state.PreviewDefaults.SetWrap(p.state.PreviewData.Name, p.wrapEnabled) {
+			p.state.PreviewWrap = p.wrapEnabled
+		}
 		p.state.PreviewScrollOffset = 0
 		p.state.PreviewWrapOffset = 0
 		p.rowMetricsWidth = 0
 		p.resetWrapCache()
 		p.applyWrapSetting()
+		p.refreshSplitPane(false)
 	case keySpace:
 		if p.wrapEnabled {
 			p.scrollRows(totalLines, contentRows)
@@ -717,9 +837,51 @@ func (p *PreviewPager) handleKey(ev keyEvent) bool {
 		p.showInfo = !p.showInfo
 	case keyToggleFormat:
 		p.toggleFormatView()
+	case keyToggleScrollbar:
+		p.toggleScrollbar()
+	case keyToggleHexUTF8:
+		if p.binaryMode {
+			p.toggleHexUTF8()
+		}
+	case keyToggleSplit:
+		p.toggleSplit()
+	case keyToggleInspect:
+		p.toggleInspect()
+	case keyToggleReading:
+		p.toggleReadingMode()
+	case keyToggleTOC:
+		p.openTOC()
+	case keyHiddenNext:
+		p.nextHiddenFormatting()
+	case keyHiddenList:
+		p.openHiddenList()
+	case keyRecentFiles:
+		p.openRecent()
+	case keySwitchPane:
+		p.switchSplitFocus()
+	case keyToggleANSI:
+		if !p.binaryMode {
+			p.toggleANSIColors()
+		}
 	case keyCopyVisible:
 		p.recordCopyResult(p.copyVisibleToClipboard(), "copied view", "")
+	case keyCopyRef:
+		ref, err := p.copyReference()
+		p.recordCopyResult(err, "copied "+ref, "")
+	case keyCopyAllViaFile:
+		path, err := p.copyAllViaTempFile()
+		p.recordCopyResult(err, "copied path "+path, "")
+	case keyExportQuickfix:
+		msg, err := p.exportQuickfix()
+		if err != nil {
+			p.setStatusMessage(err.Error(), statusErrorStyle)
+		} else {
+			p.setStatusMessage(msg, statusSuccessStyle)
+		}
 	case keyCopyAll:
+		if p.confirmLargeCopy() {
+			break
+		}
 		msg, style, err := p.copyAllToClipboard()
 		if msg == "" {
 			msg = "copied all"
@@ -771,13 +933,40 @@ func (p *PreviewPager) handleSearchModeEvent(ev keyEvent) {
 		p.toggleSearchLimit()
 		return
 	case keyLeft:
+		if hasAltModifier(ev.mod) {
+			// Alt+← is back: it leaves search like Esc.
+			p.cancelSearch()
+			return
+		}
+		if _, line := p.searchLine(); line.Cursor > 0 {
+			p.editSearch(searchMoveOp(ev, lineedit.Left, lineedit.WordLeft))
+			return
+		}
+		// ← at the start of the input clears it, then leaves search.
 		if len(p.searchInput) > 0 {
 			p.searchInput = nil
+			p.searchTail = 0
 			p.onSearchInputChanged()
 			return
 		}
 		p.cancelSearch()
 		return
+	case keyRight:
+		p.editSearch(searchMoveOp(ev, lineedit.Right, lineedit.WordRight))
+		return
+	case keyHome:
+		if ev.ch == 0 {
+			p.editSearch(lineedit.Home)
+			return
+		}
+	case keyEnd:
+		if ev.ch == 0 {
+			p.editSearch(lineedit.End)
+			return
+		}
+	case keyLineEdit:
+		p.editSearch(ev.edit)
+		return
 	case keyEnter:
 		p.finalizeSearchInput()
 		p.exitSearchMode()
@@ -811,11 +1000,28 @@ func (p *PreviewPager) handleSearchModeEvent(ev keyEvent) {
 	}
 }
 
+// searchMoveOp picks the word-wise move when Ctrl or Alt is held.
+func searchMoveOp(ev keyEvent, char, word lineedit.Op) lineedit.Op {
+	if ev.mod > 2 {
+		return word
+	}
+	return char
+}
+
+// filePath is the file shown: the path the preview was built for, which is
+// the browser's selection unless the pager switched to a recent file.
+func (p *PreviewPager) filePath() string {
+	if p.state.PreviewPath != "" {
+		return p.state.PreviewPath
+	}
+	return filepath.Join(p.state.CurrentPath, p.state.PreviewData.Name)
+}
+
 func (p *PreviewPager) canOpenEditor() bool {
 	if p == nil || p.state == nil || p.state.PreviewData == nil {
 		return false
 	}
-	if p.state.PreviewData.IsDir {
+	if p.state.PreviewData.IsDir || p.state.PreviewData.Privileged != "" {
 		return false
 	}
 	if len(p.editorCmd) == 0 || !p.state.EditorAvailable {
@@ -841,7 +1047,8 @@ func (p *PreviewPager) openInEditor() error {
 	savedScroll := p.state.PreviewScrollOffset
 	savedWrap := p.state.PreviewWrapOffset
 
-	filePath := filepath.Join(p.state.CurrentPath, p.state.PreviewData.Name)
+	filePath := p.filePath()
+	args := statepkg.EditorArgs(p.editorCmd, filePath, p.editorLine())
 
 	if p.stopKeyReader != nil {
 		p.stopKeyReader()
@@ -860,20 +1067,21 @@ func (p *PreviewPager) openInEditor() error {
 	if p.restoreTerm != nil {
 		_ = term.Restore(int(p.input.Fd()), p.restoreTerm)
 	}
+	// Leave our alternate screen so an editor that switches screens itself
+	// returns to a sane state; enterPagerMode switches back.
+	p.exitAltScreen()
 	p.writeString("\x1b[?25h")
 	p.writeString("\x1b[?7h")
 	if p.writer != nil {
 		_ = p.writer.Flush()
 	}
 
-	args := append([]string(nil), p.editorCmd...)
-	args = append(args, filePath)
 	cmd := pagerCommand(args[0], args[1:]...)
 	cmd.Stdin = p.input
 	cmd.Stdout = p.output
 	cmd.Stderr = p.output
 
-	err := cmd.Run()
+	err := procwatch.Run(cmd, procwatch.Interactive)
 
 	if err2 := p.enterPagerMode(); err == nil && err2 != nil {
 		err = err2
@@ -896,6 +1104,18 @@ func (p *PreviewPager) openInEditor() error {
 	return err
 }
 
+// editorLine is the 1-based line of the focused search hit for the editor to
+// open at, or 0. Only raw text lines match the file's own line numbers.
+func (p *PreviewPager) editorLine() int {
+	if p.binaryMode || p.showFormatted || p.searchQuery == "" {
+		return 0
+	}
+	if hit := p.focusedHit(); hit != nil {
+		return hit.line + 1
+	}
+	return 0
+}
+
 // enterPagerMode re-enters raw terminal mode after returning from an external
 // editor and reapplies pager-specific terminal settings.
 func (p *PreviewPager) enterPagerMode() error {
@@ -919,6 +1139,7 @@ func (p *PreviewPager) enterPagerMode() error {
 		p.writer = bufio.NewWriter(p.output)
 	}
 
+	p.enterAltScreen()
 	p.applyWrapSetting()
 	p.writeString("\x1b[?25l")
 	if p.writer != nil {
@@ -940,7 +1161,7 @@ func (p *PreviewPager) restoreAfterEditor(savedScroll, savedWrap int) {
 	if p.wrapEnabled {
 		p.ensureRowMetrics()
 	}
-	visible := p.height - (len(p.headerLines()) + 1) - 1
+	visible := p.viewHeight() - (len(p.headerLines()) + 1) - 1
 	if visible < 1 {
 		visible = 1
 	}
@@ -1094,7 +1315,7 @@ func (p *PreviewPager) headerLineCount(preview *statepkg.PreviewData) int {
 
 func (p *PreviewPager) lineAt(idx int) string {
 	if !p.showFormatted && p.rawTextSource != nil {
-		return textutil.SanitizeTerminalText(p.rawTextSource.Line(idx))
+		return p.sanitizeRawLine(p.rawTextSource.Line(idx))
 	}
 	if p.binaryMode {
 		if p.binarySource == nil {
@@ -1226,16 +1447,18 @@ func (p *PreviewPager) scrollToEnd(totalLines int) {
 	p.state.PreviewWrapOffset = rows - 1
 }
 
-func formatDirectoryPreview(preview *statepkg.PreviewData) []string {
-	if preview == nil || len(preview.DirEntries) == 0 {
-		return []string{"(directory is empty)"}
-	}
-
-	lines := make([]string, 0, len(preview.DirEntries))
-	for _, entry := range preview.DirEntries {
-		lines = append(lines, dirEntryLine(entry))
+// newDirPagerSource pages a directory listing through the streaming text
+// source: a line is formatted only when the pager draws, searches or copies
+// it, and at most textPagerCacheLines of them are kept, so opening a
+// directory with tens of thousands of entries costs no more than a screenful.
+func newDirPagerSource(entries []statepkg.FileEntry) *textPagerSource {
+	return &textPagerSource{
+		cache:          make(map[int]string),
+		maxCacheLines:  textPagerCacheLines,
+		eof:            true,
+		generate:       func(idx int) string { return dirEntryLine(entries[idx]) },
+		generatedCount: len(entries),
 	}
-	return lines
 }
 
 func dirEntryLine(entry statepkg.FileEntry) string {
@@ -1247,20 +1470,7 @@ func dirEntryLine(entry statepkg.FileEntry) string {
 		icon = "/"
 	}
 	name := textutil.SanitizeTerminalText(entry.Name)
-	size := formatSize(entry.Size)
+	size := textutil.FormatSize(entry.Size)
 	mod := entry.Modified.Format("2006-01-02 15:04:05")
 	return fmt.Sprintf(" %s %-20s %12s  %s  %s", icon, name, size, entry.Mode.String(), mod)
 }
-
-func formatSize(size int64) string {
-	const unit = 1024
-	if size < unit {
-		return fmt.Sprintf("%d B", size)
-	}
-	div, exp := int64(unit), 0
-	for n := size / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
-}
@@ -0,0 +1,106 @@
+package pager
+
+import (
+	"strings"
+
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+)
+
+// sanitizeRawLine makes a raw file line safe to print. With ANSI colors on,
+// the file's own SGR sequences survive; every other escape is neutralized.
+func (p *PreviewPager) sanitizeRawLine(text string) string {
+	if p.ansiColors {
+		return textutil.SanitizeTerminalTextKeepSGR(text)
+	}
+	return textutil.SanitizeTerminalText(text)
+}
+
+// sanitizeRawLines rebuilds the sanitized copy of static raw content.
+func (p *PreviewPager) sanitizeRawLines() {
+	p.rawSanitized = make([]string, len(p.rawLines))
+	p.rawSanitizedWid = make([]int, len(p.rawLines))
+	for i, line := range p.rawLines {
+		safe := p.sanitizeRawLine(line)
+		p.rawSanitized[i] = safe
+		p.rawSanitizedWid[i] = displayWidth(safe)
+	}
+	if p.rawTextSource != nil {
+		p.rawTextSource.keepSGR = p.ansiColors
+	}
+}
+
+func (p *PreviewPager) toggleANSIColors() {
+	p.setANSIColors(!p.ansiColors)
+	if p.state != nil {
+		p.state.PreviewANSIColors = p.ansiColors
+	}
+}
+
+func (p *PreviewPager) setANSIColors(enabled bool) {
+	p.ansiColors = enabled
+	if len(p.rawLines) > 0 || p.rawTextSource != nil {
+		p.sanitizeRawLines()
+	}
+	p.updateDisplayLines()
+	p.rowSpans = nil
+	p.rowPrefix = nil
+	p.rowMetricsWidth = 0
+	p.resetWrapCache()
+	if p.searchQuery != "" {
+		p.executeSearch(p.searchQuery)
+	}
+	p.refreshSplitPane(false)
+}
+
+// ansiSequenceLen returns the length of the "ESC [ ... m" sequence at the
+// start of text, or 0. It matches the loose parsing of ansiDisplayWidth so
+// wrapping and width measurement agree on what is invisible.
+func ansiSequenceLen(text string) int {
+	if len(text) < 2 || text[0] != '\x1b' || text[1] != '[' {
+		return 0
+	}
+	end := strings.IndexByte(text[2:], 'm')
+	if end == -1 {
+		return len(text)
+	}
+	return end + 3
+}
+
+// sgrCarry returns the SGR sequences in effect at the end of prefix, so a
+// wrapped continuation row keeps the colors its line started with.
+func sgrCarry(prefix string) string {
+	var carry strings.Builder
+	for {
+		esc := strings.IndexByte(prefix, '\x1b')
+		if esc == -1 {
+			break
+		}
+		prefix = prefix[esc:]
+		n := ansiSequenceLen(prefix)
+		if n == 0 {
+			prefix = prefix[1:]
+			continue
+		}
+		seq := prefix[:n]
+		if seq == "\x1b[0m" || seq == "\x1b[m" {
+			carry.Reset()
+		} else {
+			carry.WriteString(seq)
+		}
+		prefix = prefix[n:]
+	}
+	return carry.String()
+}
+
+// wrappedRowColors prefixes a wrapped row of line text with the colors carried
+// over from the columns before it (only needed for ANSI-colored raw lines).
+func (p *PreviewPager) wrappedRowColors(text, seg string, dropCols int) string {
+	if !p.ansiColors || p.showFormatted || dropCols <= 0 || strings.IndexByte(text, '\x1b') == -1 {
+		return seg
+	}
+	prefix, _ := ansiTruncate(text, dropCols, false)
+	if carry := sgrCarry(prefix); carry != "" {
+		return carry + seg
+	}
+	return seg
+}
@@ -4,9 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
-	"path/filepath"
 	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
 )
 
 func (p *PreviewPager) binaryBytesPerLine() int {
@@ -67,7 +70,7 @@ func (p *PreviewPager) syncBinaryPositionOnExit() {
 	// Refresh the inline binary preview window around the last byte offset so the
 	// non-fullscreen panel doesn't end up showing only the last cached line.
 	if p.state.PreviewData != nil && p.binarySource != nil && p.state.CurrentPath != "" && p.state.PreviewData.Name != "" {
-		filePath := filepath.Join(p.state.CurrentPath, p.state.PreviewData.Name)
+		filePath := p.filePath()
 		p.refreshInlineBinaryPreview(filePath, p.binarySource.totalBytes, p.state.PreviewBinaryByteOffset)
 		p.state.PreviewScrollOffset = 0
 		return
@@ -122,19 +125,11 @@ func (p *PreviewPager) refreshInlineBinaryPreview(path string, totalBytes int64,
 		return
 	}
 
-	file := p.binarySource.file
-	closeFile := false
-	if file == nil {
-		f, err := os.Open(path)
-		if err != nil {
-			return
-		}
-		file = f
-		closeFile = true
-	}
-	if closeFile {
-		defer func() { _ = file.Close() }()
+	file, release, err := p.binarySource.readerAt()
+	if err != nil {
+		return
 	}
+	defer release()
 
 	buf := make([]byte, readLen)
 	n, err := file.ReadAt(buf, start)
@@ -155,7 +150,7 @@ func (p *PreviewPager) refreshInlineBinaryPreview(path string, totalBytes int64,
 		if end > len(buf) {
 			end = len(buf)
 		}
-		lines = append(lines, formatHexLine(int(start)+off, buf[off:end], bytesPerLine))
+		lines = append(lines, formatHexLine(int(start)+off, buf[off:end], bytesPerLine, false))
 	}
 	if tail := totalBytes - (start + int64(len(buf))); tail > 0 {
 		lines = append(lines, fmt.Sprintf("… (%d bytes not shown)", tail))
@@ -166,6 +161,18 @@ func (p *PreviewPager) refreshInlineBinaryPreview(path string, totalBytes int64,
 	p.state.PreviewData.BinaryInfo.TotalBytes = totalBytes
 }
 
+// toggleHexUTF8 (d) switches the ASCII column between a dot for every
+// non-ASCII byte and the UTF-8 text those bytes decode to.
+func (p *PreviewPager) toggleHexUTF8() {
+	p.state.PreviewHexUTF8 = !p.state.PreviewHexUTF8
+	p.binarySource.SetDecodeUTF8(p.state.PreviewHexUTF8)
+	if p.state.PreviewHexUTF8 {
+		p.setStatusMessage("ASCII column: UTF-8 text", "")
+	} else {
+		p.setStatusMessage("ASCII column: ASCII only", "")
+	}
+}
+
 func (p *PreviewPager) jumpBinary(deltaBytes int64, stepBytes int64) {
 	if p == nil || !p.binaryMode || p.state == nil {
 		return
@@ -289,7 +296,7 @@ func formatHexOffset(offset int64) string {
 	return b.String()
 }
 
-func formatHexLine(offset int, chunk []byte, bytesPerLine int) string {
+func formatHexLine(offset int, chunk []byte, bytesPerLine int, decodeUTF8 bool) string {
 	var builder strings.Builder
 	// Estimate buffer size: 10 (offset) + bytesPerLine*3 (hex) + bytesPerLine/8 (spaces) + 3 (separators) + bytesPerLine (ASCII)
 	builder.Grow(10 + bytesPerLine*3 + bytesPerLine/8 + 3 + bytesPerLine)
@@ -308,9 +315,7 @@ func formatHexLine(offset int, chunk []byte, bytesPerLine int) string {
 	}
 
 	builder.WriteString(" |")
-	for i := 0; i < len(chunk); i++ {
-		builder.WriteByte(printableASCII(chunk[i]))
-	}
+	writeASCIIColumn(&builder, chunk, decodeUTF8)
 	for i := len(chunk); i < bytesPerLine; i++ {
 		builder.WriteByte(' ')
 	}
@@ -325,57 +330,124 @@ func printableASCII(b byte) byte {
 	return '.'
 }
 
-func alignedBinaryChunkSize(bytesPerLine int) int {
+// utf8FillerCell pads a decoded character to the cells of its bytes.
+const utf8FillerCell = '·'
+
+// writeASCIIColumn writes one cell per byte, so the column lines up with the
+// hex bytes and search highlights. With decodeUTF8 a printable UTF-8
+// sequence shows as its character, one or two cells wide, followed by
+// utf8FillerCell for the rest of its bytes; anything else non-ASCII,
+// including a sequence cut off by the end of the line, is a dot per byte.
+func writeASCIIColumn(builder *strings.Builder, chunk []byte, decodeUTF8 bool) {
+	for i := 0; i < len(chunk); {
+		if decodeUTF8 && chunk[i] >= utf8.RuneSelf {
+			r, size := utf8.DecodeRune(chunk[i:])
+			if width := textutil.DisplayWidth(string(r)); size > 1 && unicode.IsPrint(r) && width >= 1 && width <= size {
+				builder.WriteRune(r)
+				for range size - width {
+					builder.WriteRune(utf8FillerCell)
+				}
+				i += size
+				continue
+			}
+		}
+		builder.WriteByte(printableASCII(chunk[i]))
+		i++
+	}
+}
+
+// alignedBinaryChunkSize rounds size down to whole lines of bytesPerLine.
+func alignedBinaryChunkSize(size, bytesPerLine int) int {
 	if bytesPerLine <= 0 {
 		bytesPerLine = binaryPreviewLineWidth
 	}
-	size := binaryPagerChunkSize
-	if size <= 0 {
-		size = bytesPerLine
-	}
 	if size < bytesPerLine {
 		size = bytesPerLine
 	}
-	aligned := (size / bytesPerLine) * bytesPerLine
-	if aligned < bytesPerLine {
-		aligned = bytesPerLine
+	return (size / bytesPerLine) * bytesPerLine
+}
+
+// binaryChunkPlan picks the chunk size and how many chunks to keep for a
+// file. Bigger files get bigger reads, a chunk always spans several screens
+// so paging rarely crosses a boundary, and the cache holds about
+// binaryPagerCacheBudget bytes of the file (or all of it when smaller).
+func binaryChunkPlan(totalBytes int64, bytesPerLine, viewRows int) (chunkSize, maxChunks int) {
+	if bytesPerLine <= 0 {
+		bytesPerLine = binaryPreviewLineWidth
+	}
+	size := binaryPagerChunkSize
+	switch {
+	case totalBytes >= 256<<20:
+		size = 256 * 1024
+	case totalBytes >= 16<<20:
+		size = 128 * 1024
+	}
+	if viewRows > 0 {
+		size = max(size, binaryChunkScreens*viewRows*bytesPerLine)
+	}
+	size = min(max(size, binaryPagerMinChunkSize), binaryPagerMaxChunkSize)
+	if totalBytes > 0 && totalBytes < int64(size) {
+		size = int(totalBytes) + bytesPerLine - 1
+	}
+	chunkSize = alignedBinaryChunkSize(size, bytesPerLine)
+
+	maxChunks = min(max(binaryPagerCacheBudget/chunkSize, binaryPagerMinChunks), binaryPagerMaxChunks)
+	if totalBytes > 0 {
+		maxChunks = min(maxChunks, int((totalBytes+int64(chunkSize)-1)/int64(chunkSize)))
 	}
-	return aligned
+	return chunkSize, max(maxChunks, 1)
 }
 
 type binaryPagerSource struct {
+	fsys         fsutil.FS // where path is read; nil is the local filesystem
 	path         string
 	totalBytes   int64
 	bytesPerLine int
+	decodeUTF8   bool // ASCII column shows UTF-8 text
+	viewRows     int
 	chunkSize    int
 	maxChunks    int
-	file         *os.File
+	file         io.ReaderAt
+	reader       io.ReaderAt // content printed by the privileged helper, used instead of path
 	cache        map[int]*binaryChunk
 	cacheOrder   []int
+
+	// Sequential readahead: the last chunk read and the chunks being read
+	// in the background, keyed by index.
+	lastChunk int
+	readahead map[int]*binaryReadahead
 }
 
 type binaryChunk struct {
-	index int
-	lines []string
+	index        int
+	bytesPerLine int
+	lines        []string
 }
 
-func newBinaryPagerSource(path string, totalBytes int64, pagerWidth int) (*binaryPagerSource, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
+// binaryReadahead is a chunk read on a background goroutine; done is closed
+// once chunk or err is set.
+type binaryReadahead struct {
+	done  chan struct{}
+	chunk *binaryChunk
+	err   error
+}
 
+func newBinaryPagerSource(fsys fsutil.FS, path string, reader io.ReaderAt, totalBytes int64, pagerWidth int) (*binaryPagerSource, error) {
 	bytesPerLine := calculateBytesPerLine(pagerWidth)
 
 	source := &binaryPagerSource{
+		fsys:         fsys,
 		path:         path,
 		totalBytes:   totalBytes,
 		bytesPerLine: bytesPerLine,
-		chunkSize:    alignedBinaryChunkSize(bytesPerLine),
-		maxChunks:    binaryPagerMaxChunks,
-		file:         file,
+		reader:       reader,
 		cache:        make(map[int]*binaryChunk),
+		lastChunk:    -1,
 	}
+	if err := source.openFile(); err != nil {
+		return nil, err
+	}
+	source.chunkSize, source.maxChunks = binaryChunkPlan(totalBytes, bytesPerLine, 0)
 	return source, nil
 }
 
@@ -383,10 +455,56 @@ func (s *binaryPagerSource) Close() {
 	if s == nil || s.file == nil {
 		return
 	}
-	_ = s.file.Close()
+	s.waitReadahead()
+	// The helper's reader belongs to whoever started the helper.
+	if closer, ok := s.file.(io.Closer); ok && s.file != s.reader {
+		_ = closer.Close()
+	}
 	s.file = nil
 }
 
+// readerAt returns the open file, or opens it for a single read; release
+// closes what it opened.
+func (s *binaryPagerSource) readerAt() (io.ReaderAt, func(), error) {
+	if s.file != nil {
+		return s.file, func() {}, nil
+	}
+	if s.reader != nil {
+		return s.reader, func() {}, nil
+	}
+	file, err := fsutil.OrLocal(s.fsys).Open(s.path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, func() { _ = file.Close() }, nil
+}
+
+// openFile opens the file behind the source unless it is open already.
+func (s *binaryPagerSource) openFile() error {
+	if s.file != nil {
+		return nil
+	}
+	if s.reader != nil {
+		s.file = s.reader
+		return nil
+	}
+	file, err := fsutil.OrLocal(s.fsys).Open(s.path)
+	if err != nil {
+		return err
+	}
+	s.file = file
+	return nil
+}
+
+// waitReadahead lets background reads finish before the file or the chunk
+// layout changes.
+func (s *binaryPagerSource) waitReadahead() {
+	for _, ra := range s.readahead {
+		<-ra.done
+	}
+	s.readahead = nil
+}
+
 func (s *binaryPagerSource) UpdateBytesPerLine(pagerWidth int) {
 	if s == nil {
 		return
@@ -395,12 +513,55 @@ func (s *binaryPagerSource) UpdateBytesPerLine(pagerWidth int) {
 	if newBytesPerLine == s.bytesPerLine {
 		return // no change needed
 	}
+	s.bytesPerLine = newBytesPerLine
+	s.retune()
+}
 
-	// Clear cache since line formatting will change
+// SetDecodeUTF8 switches the ASCII column between dots and decoded UTF-8;
+// the formatted chunks are dropped.
+func (s *binaryPagerSource) SetDecodeUTF8(on bool) {
+	if s == nil || s.decodeUTF8 == on {
+		return
+	}
+	s.decodeUTF8 = on
+	s.waitReadahead()
 	s.cache = make(map[int]*binaryChunk)
 	s.cacheOrder = nil
-	s.bytesPerLine = newBytesPerLine
-	s.chunkSize = alignedBinaryChunkSize(newBytesPerLine)
+	s.lastChunk = -1
+}
+
+// SetViewRows adapts the chunk size to the pager height.
+func (s *binaryPagerSource) SetViewRows(rows int) {
+	if s == nil || rows == s.viewRows {
+		return
+	}
+	s.viewRows = rows
+	s.retune()
+}
+
+// retune recomputes the chunk plan; cached chunks are dropped when their
+// layout changes.
+func (s *binaryPagerSource) retune() {
+	chunkSize, maxChunks := binaryChunkPlan(s.totalBytes, s.bytesPerLine, s.viewRows)
+	s.maxChunks = maxChunks
+	if chunkSize == s.chunkSize && len(s.cache) > 0 && s.cacheLineWidth() == s.bytesPerLine {
+		s.trimCache()
+		return
+	}
+	s.waitReadahead()
+	s.cache = make(map[int]*binaryChunk)
+	s.cacheOrder = nil
+	s.chunkSize = chunkSize
+	s.lastChunk = -1
+}
+
+// cacheLineWidth returns the bytes per line the cached chunks were
+// formatted with, or 0 when nothing is cached.
+func (s *binaryPagerSource) cacheLineWidth() int {
+	for _, chunk := range s.cache {
+		return chunk.bytesPerLine
+	}
+	return 0
 }
 
 func (s *binaryPagerSource) LineCount() int {
@@ -452,22 +613,108 @@ func (s *binaryPagerSource) linesPerChunk() int {
 	return s.chunkSize / s.bytesPerLine
 }
 
+func (s *binaryPagerSource) chunkCount() int {
+	if s.chunkSize <= 0 || s.totalBytes <= 0 {
+		return 0
+	}
+	return int((s.totalBytes + int64(s.chunkSize) - 1) / int64(s.chunkSize))
+}
+
 func (s *binaryPagerSource) loadChunk(index int) (*binaryChunk, error) {
+	defer s.noteAccess(index)
 	if chunk, ok := s.cache[index]; ok {
 		s.touchChunk(index)
 		return chunk, nil
 	}
-	if s.file == nil {
-		file, err := os.Open(s.path)
-		if err != nil {
-			return nil, err
+	if ra, ok := s.readahead[index]; ok {
+		<-ra.done
+		delete(s.readahead, index)
+		if ra.err == nil && ra.chunk != nil {
+			s.addChunk(index, ra.chunk)
+			return ra.chunk, nil
 		}
-		s.file = file
+	}
+	if err := s.openFile(); err != nil {
+		return nil, err
 	}
 
-	buf := make([]byte, s.chunkSize)
-	offset := int64(index) * int64(s.chunkSize)
-	n, err := s.file.ReadAt(buf, offset)
+	chunk, err := readBinaryChunk(s.file, index, s.chunkSize, s.bytesPerLine, s.decodeUTF8)
+	if err != nil || chunk == nil {
+		return nil, err
+	}
+	s.addChunk(index, chunk)
+	return chunk, nil
+}
+
+// noteAccess starts reading the next chunks in the background when the
+// view moves from one chunk to its neighbour, in the direction it moves.
+func (s *binaryPagerSource) noteAccess(index int) {
+	if index == s.lastChunk {
+		return
+	}
+	direction := 0
+	switch index {
+	case s.lastChunk + 1:
+		direction = 1
+	case s.lastChunk - 1:
+		direction = -1
+	}
+	s.lastChunk = index
+	s.pruneReadahead(index)
+	if direction == 0 || s.file == nil {
+		return
+	}
+	// Keep the readahead within the cache so it does not evict what the
+	// view still shows.
+	depth := min(binaryReadaheadChunks, s.maxChunks-2)
+	for i := 1; i <= depth; i++ {
+		s.startReadahead(index + direction*i)
+	}
+}
+
+// pruneReadahead drops finished reads the view moved away from, so
+// abandoned readahead does not pile up outside the cache budget.
+func (s *binaryPagerSource) pruneReadahead(index int) {
+	for idx, ra := range s.readahead {
+		if idx >= index-binaryReadaheadChunks && idx <= index+binaryReadaheadChunks {
+			continue
+		}
+		select {
+		case <-ra.done:
+			delete(s.readahead, idx)
+		default:
+		}
+	}
+}
+
+func (s *binaryPagerSource) startReadahead(index int) {
+	if index < 0 || index >= s.chunkCount() {
+		return
+	}
+	if _, ok := s.cache[index]; ok {
+		return
+	}
+	if _, ok := s.readahead[index]; ok {
+		return
+	}
+	if s.readahead == nil {
+		s.readahead = make(map[int]*binaryReadahead)
+	}
+	ra := &binaryReadahead{done: make(chan struct{})}
+	s.readahead[index] = ra
+	file, chunkSize, bytesPerLine, decodeUTF8 := s.file, s.chunkSize, s.bytesPerLine, s.decodeUTF8
+	go func() {
+		defer close(ra.done)
+		ra.chunk, ra.err = readBinaryChunk(file, index, chunkSize, bytesPerLine, decodeUTF8)
+	}()
+}
+
+// readBinaryChunk reads chunk index and formats it as hex dump lines. It
+// only uses ReadAt, so readahead goroutines can share the file.
+func readBinaryChunk(file io.ReaderAt, index, chunkSize, bytesPerLine int, decodeUTF8 bool) (*binaryChunk, error) {
+	buf := make([]byte, chunkSize)
+	offset := int64(index) * int64(chunkSize)
+	n, err := file.ReadAt(buf, offset)
 	if err != nil && err != io.EOF {
 		return nil, err
 	}
@@ -475,21 +722,16 @@ func (s *binaryPagerSource) loadChunk(index int) (*binaryChunk, error) {
 		return nil, nil
 	}
 	buf = buf[:n]
-	lines := make([]string, 0, (n+s.bytesPerLine-1)/s.bytesPerLine)
-	for i := 0; i < n; i += s.bytesPerLine {
-		end := i + s.bytesPerLine
+	lines := make([]string, 0, (n+bytesPerLine-1)/bytesPerLine)
+	for i := 0; i < n; i += bytesPerLine {
+		end := i + bytesPerLine
 		if end > n {
 			end = n
 		}
 		absOffset := int(offset) + i
-		lines = append(lines, formatHexLine(absOffset, buf[i:end], s.bytesPerLine))
+		lines = append(lines, formatHexLine(absOffset, buf[i:end], bytesPerLine, decodeUTF8))
 	}
-	chunk := &binaryChunk{
-		index: index,
-		lines: lines,
-	}
-	s.addChunk(index, chunk)
-	return chunk, nil
+	return &binaryChunk{index: index, bytesPerLine: bytesPerLine, lines: lines}, nil
 }
 
 func (s *binaryPagerSource) addChunk(index int, chunk *binaryChunk) {
@@ -498,7 +740,15 @@ func (s *binaryPagerSource) addChunk(index int, chunk *binaryChunk) {
 	}
 	s.cache[index] = chunk
 	s.touchChunk(index)
-	if len(s.cache) > s.maxChunks {
+	s.trimCache()
+}
+
+func (s *binaryPagerSource) trimCache() {
+	limit := s.maxChunks
+	if limit <= 0 {
+		limit = binaryPagerMinChunks
+	}
+	for len(s.cache) > limit && len(s.cacheOrder) > 0 {
 		evict := s.cacheOrder[0]
 		s.cacheOrder = s.cacheOrder[1:]
 		delete(s.cache, evict)
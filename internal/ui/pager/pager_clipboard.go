@@ -5,10 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 	"unicode/utf8"
 
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+	"github.com/kk-code-lab/rdir/internal/procwatch"
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
 	textutil "github.com/kk-code-lab/rdir/internal/textutil"
 )
 
@@ -29,7 +34,7 @@ func (p *PreviewPager) recordCopyResult(err error, successMsg string, style stri
 	if err != nil {
 		p.setStatusMessage(err.Error(), statusErrorStyle)
 		if p.state != nil {
-			p.state.LastError = err
+			p.state.ReportError(statepkg.ErrorSourceGeneral, err)
 		}
 		return
 	}
@@ -42,6 +47,77 @@ func (p *PreviewPager) recordCopyResult(err error, successMsg string, style stri
 	}
 }
 
+var gitLookup = fsutil.LookupGit
+
+// gitInfoLookup is the git checkout of one directory, looked up in the
+// background; done is closed once info and err are set.
+type gitInfoLookup struct {
+	dir  string
+	info fsutil.GitInfo
+	err  error
+	done chan struct{}
+}
+
+// prefetchGitInfo starts looking up the file's git checkout when the
+// RDIR_COPY_REF template needs it, so y does not wait for git.
+func (p *PreviewPager) prefetchGitInfo() {
+	if p.state == nil || p.state.PreviewData == nil || !statepkg.CopyRefNeedsGit(p.state.CopyRefTemplate) {
+		return
+	}
+	p.startGitLookup(filepath.Dir(p.filePath()))
+}
+
+func (p *PreviewPager) startGitLookup(dir string) *gitInfoLookup {
+	lookup := &gitInfoLookup{dir: dir, done: make(chan struct{})}
+	p.gitInfo = lookup
+	go func(git func(string) (fsutil.GitInfo, error)) {
+		defer close(lookup.done)
+		lookup.info, lookup.err = git(dir)
+	}(gitLookup)
+	return lookup
+}
+
+// cachedGitInfo returns the git checkout of dir, looking it up once per
+// pager.
+func (p *PreviewPager) cachedGitInfo(dir string) (fsutil.GitInfo, error) {
+	lookup := p.gitInfo
+	if lookup == nil || lookup.dir != dir {
+		lookup = p.startGitLookup(dir)
+	}
+	<-lookup.done
+	return lookup.info, lookup.err
+}
+
+// copyReference (y) copies a reference to the focused search hit's line, or
+// to the top visible line, filled into the RDIR_COPY_REF template.
+func (p *PreviewPager) copyReference() (string, error) {
+	if p.state == nil || p.state.PreviewData == nil {
+		return "", errors.New("nothing to reference")
+	}
+	if p.binaryMode {
+		return "", errors.New("line references apply to text files")
+	}
+	if p.showFormatted {
+		return "", errors.New("line references use the raw view (press f)")
+	}
+	if !p.clipboardAvailable() {
+		return "", errors.New("clipboard unavailable")
+	}
+	line := p.state.PreviewScrollOffset
+	if hit := p.focusedHit(); hit != nil && p.searchQuery != "" {
+		line = hit.line
+	}
+	path := p.filePath()
+	ref, err := statepkg.ExpandCopyRef(p.state.CopyRefTemplate, path, line+1, p.cachedGitInfo)
+	if err != nil {
+		return "", err
+	}
+	if err := p.copyLinesToClipboard([]string{ref}); err != nil {
+		return "", err
+	}
+	return ref, nil
+}
+
 func (p *PreviewPager) copyVisibleToClipboard() error {
 	lines := p.visibleContentLinesForCopy()
 	return p.copyLinesToClipboard(lines)
@@ -54,7 +130,7 @@ func (p *PreviewPager) copyAllToClipboard() (string, string, error) {
 
 	size := p.clipboardByteSize()
 	if size > 0 && size >= clipboardHardLimitBytes {
-		return "", "", fmt.Errorf("copy canceled: %s exceeds clipboard limit (%s)", formatSize(size), formatSize(clipboardHardLimitBytes))
+		return "", "", fmt.Errorf("copy canceled: %s exceeds clipboard limit (%s); P copies the path of a temp file instead", textutil.FormatSize(size), textutil.FormatSize(clipboardHardLimitBytes))
 	}
 
 	if !p.showFormatted && p.rawTextSource != nil {
@@ -65,7 +141,7 @@ func (p *PreviewPager) copyAllToClipboard() (string, string, error) {
 
 	warn := size > 0 && size >= clipboardWarnBytes
 	if warn {
-		p.setStatusMessage(fmt.Sprintf("copying %s; this may be slow", formatSize(size)), statusWarnStyle)
+		p.setStatusMessage(fmt.Sprintf("copying %s; this may be slow", textutil.FormatSize(size)), statusWarnStyle)
 	}
 
 	preferRawCopy := p.showFormatted && !p.binaryMode && (p.rawTextSource != nil || len(p.rawLines) > 0)
@@ -101,7 +177,7 @@ func (p *PreviewPager) copyAllToClipboard() (string, string, error) {
 		msg = "copied all (raw)"
 	}
 	if size > 0 {
-		msg = fmt.Sprintf("%s (%s)", msg, formatSize(size))
+		msg = fmt.Sprintf("%s (%s)", msg, textutil.FormatSize(size))
 	}
 	if warn {
 		return msg, statusWarnStyle, nil
@@ -109,6 +185,65 @@ func (p *PreviewPager) copyAllToClipboard() (string, string, error) {
 	return msg, "", nil
 }
 
+// confirmLargeCopy holds back the first C on content over clipboardWarnBytes
+// and offers the temp file handoff instead, since piping hundreds of MB into
+// a clipboard command is slow and some clipboard managers choke on it. It
+// reports whether the copy should wait for a second C.
+func (p *PreviewPager) confirmLargeCopy() bool {
+	if p.largeCopyPending || !p.clipboardAvailable() {
+		p.largeCopyPending = false
+		return false
+	}
+	size := p.clipboardByteSize()
+	if size < clipboardWarnBytes || size >= clipboardHardLimitBytes {
+		return false
+	}
+	p.largeCopyPending = true
+	p.setStatusMessage(fmt.Sprintf("%s is a lot for the clipboard: C again copies it, P copies the path of a temp file with it", textutil.FormatSize(size)), statusWarnStyle)
+	return true
+}
+
+// copyAllViaTempFile (P) writes what C would copy to a new temp file and
+// copies the file's path. The file is left for the user to remove. In the
+// raw view of a file on disk that content is the file itself, so its own
+// path is copied instead.
+func (p *PreviewPager) copyAllViaTempFile() (string, error) {
+	if !p.clipboardAvailable() {
+		return "", errors.New("clipboard unavailable")
+	}
+	if p.state == nil || p.state.PreviewData == nil {
+		return "", errors.New("nothing to copy")
+	}
+	if path, ok := p.rawFilePath(); ok {
+		if err := p.copyLinesToClipboard([]string{path}); err != nil {
+			return "", err
+		}
+		return path, nil
+	}
+	file, err := os.CreateTemp("", "rdir-copy-*"+filepath.Ext(p.state.PreviewData.Name))
+	if err != nil {
+		return "", err
+	}
+	if p.showFormatted && !p.binaryMode && (p.rawTextSource != nil || len(p.rawLines) > 0) {
+		err = p.writeAllLinesRaw(file)
+	} else if !p.showFormatted && p.rawTextSource != nil {
+		err = writeSourceLines(file, p.rawTextSource, p.sanitizeRawLine)
+	} else {
+		err = p.writeAllLines(file)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return "", err
+	}
+	if err := p.copyLinesToClipboard([]string{file.Name()}); err != nil {
+		return "", err
+	}
+	return file.Name(), nil
+}
+
 func (p *PreviewPager) visibleContentLines() []string {
 	if p == nil {
 		return nil
@@ -120,7 +255,7 @@ func (p *PreviewPager) visibleContentLines() []string {
 	if width <= 0 {
 		width = 1
 	}
-	height := p.height
+	height := p.viewHeight()
 	if height <= 0 {
 		height = 1
 	}
@@ -195,7 +330,7 @@ func (p *PreviewPager) visibleContentLinesForCopy() []string {
 	if !p.wrapEnabled || p.width <= 0 {
 		return p.visibleContentLines()
 	}
-	height := p.height
+	height := p.viewHeight()
 	if height <= 0 {
 		height = 1
 	}
@@ -261,7 +396,7 @@ func (p *PreviewPager) copyLinesToClipboard(lines []string) error {
 	if cmd.Stderr == nil {
 		cmd.Stderr = io.Discard
 	}
-	if err := cmd.Run(); err != nil {
+	if err := procwatch.Run(cmd, procwatch.Clipboard); err != nil {
 		return fmt.Errorf("clipboard command %q failed: %w", p.clipboardCmd[0], err)
 	}
 	return nil
@@ -296,38 +431,58 @@ func (p *PreviewPager) writeAllLinesRaw(w io.Writer) error {
 	if p == nil {
 		return errors.New("pager unavailable")
 	}
-	bufw := bufio.NewWriter(w)
 	if p.rawTextSource != nil {
-		if err := p.rawTextSource.EnsureAll(); err != nil {
+		return writeSourceLines(w, p.rawTextSource, func(line string) string { return line })
+	}
+	bufw := bufio.NewWriter(w)
+
+	total := len(p.rawLines)
+	for i := 0; i < total; i++ {
+		if _, err := bufw.WriteString(lineForClipboard(p.rawLines[i])); err != nil {
 			return err
 		}
-		total := p.rawTextSource.LineCount()
-		if total < 0 {
-			total = 0
-		}
-		for i := 0; i < total; i++ {
-			if _, err := bufw.WriteString(lineForClipboard(p.rawTextSource.Line(i))); err != nil {
+		if i+1 < total {
+			if err := bufw.WriteByte('\n'); err != nil {
 				return err
 			}
-			if i+1 < total {
-				if err := bufw.WriteByte('\n'); err != nil {
-					return err
-				}
-			}
 		}
-		return bufw.Flush()
 	}
+	return bufw.Flush()
+}
 
-	total := len(p.rawLines)
-	for i := 0; i < total; i++ {
-		if _, err := bufw.WriteString(lineForClipboard(p.rawLines[i])); err != nil {
+// rawFilePath returns the file shown in the raw view when its bytes are
+// what the view shows: a local file read directly, not decompressed,
+// decoded from UTF-16, printed by the privileged helper or generated.
+func (p *PreviewPager) rawFilePath() (string, bool) {
+	src := p.rawTextSource
+	if p.showFormatted || p.binaryMode || src == nil || src.generate != nil || src.reader != nil || src.compressed || !fsutil.IsLocal(src.fsys) {
+		return "", false
+	}
+	if src.encoding == fsutil.EncodingUTF16LE || src.encoding == fsutil.EncodingUTF16BE {
+		return "", false
+	}
+	return src.path, src.path != ""
+}
+
+// writeSourceLines writes the lines of src through clean, reading the file
+// a chunk at a time as it goes rather than indexing it all first.
+func writeSourceLines(w io.Writer, src *textPagerSource, clean func(string) string) error {
+	bufw := bufio.NewWriter(w)
+	for i := 0; ; i++ {
+		if err := src.EnsureLine(i); err != nil {
 			return err
 		}
-		if i+1 < total {
+		if i >= src.LineCount() {
+			break
+		}
+		if i > 0 {
 			if err := bufw.WriteByte('\n'); err != nil {
 				return err
 			}
 		}
+		if _, err := bufw.WriteString(lineForClipboard(clean(src.Line(i)))); err != nil {
+			return err
+		}
 	}
 	return bufw.Flush()
 }
@@ -349,7 +504,10 @@ func (p *PreviewPager) streamAllLinesToClipboard() error {
 		writeErrCh <- err
 	}()
 
-	if err := cmd.Run(); err != nil {
+	if err := procwatch.Run(cmd, procwatch.Clipboard); err != nil {
+		// Unblock the writer if the command stopped before reading it all.
+		_ = reader.CloseWithError(err)
+		<-writeErrCh
 		return fmt.Errorf("clipboard command %q failed: %w", p.clipboardCmd[0], err)
 	}
 	if err := <-writeErrCh; err != nil {
@@ -375,7 +533,10 @@ func (p *PreviewPager) streamAllLinesToClipboardRaw() error {
 		writeErrCh <- err
 	}()
 
-	if err := cmd.Run(); err != nil {
+	if err := procwatch.Run(cmd, procwatch.Clipboard); err != nil {
+		// Unblock the writer if the command stopped before reading it all.
+		_ = reader.CloseWithError(err)
+		<-writeErrCh
 		return fmt.Errorf("clipboard command %q failed: %w", p.clipboardCmd[0], err)
 	}
 	if err := <-writeErrCh; err != nil {
@@ -388,6 +549,10 @@ func (p *PreviewPager) clipboardByteSize() int64 {
 	if p == nil || p.state == nil || p.state.PreviewData == nil {
 		return 0
 	}
+	// Decompressed text is as large as its known uncompressed size.
+	if c := p.state.PreviewData.Compressed; c != nil && c.Size >= 0 {
+		return c.Size
+	}
 	// Prefer the stat size when available; fall back to binary source totals.
 	if p.state.PreviewData.Size > 0 {
 		return p.state.PreviewData.Size
@@ -4,8 +4,6 @@ import (
 	"path/filepath"
 	"strings"
 	"unicode/utf8"
-
-	textutil "github.com/kk-code-lab/rdir/internal/textutil"
 )
 
 type pagerContentKind int
@@ -58,6 +56,8 @@ func contentKindLabel(kind pagerContentKind) string {
 
 func (p *PreviewPager) prepareContent() {
 	lines, charCount, binarySource, textSource := p.buildContentLines()
+	p.toc, p.tocKey = nil, ""
+	p.markdownKey = ""
 	if binarySource != nil {
 		p.binaryMode = true
 		p.wrapEnabled = false
@@ -73,12 +73,7 @@ func (p *PreviewPager) prepareContent() {
 	p.rawTextSource = textSource
 
 	if textSource != nil {
-		p.lines = nil
-		p.lineWidths = nil
-		p.rawLines = nil
-		p.rawLineWidths = nil
-		p.rawSanitized = nil
-		p.rawSanitizedWid = nil
+		p.useTextSource(textSource)
 		if p.state != nil && p.state.PreviewScrollOffset > 0 {
 			// Preload up to the remembered scroll position so reopening the pager
 			// lands where the user left off, even when the file was previously only
@@ -91,20 +86,14 @@ func (p *PreviewPager) prepareContent() {
 			lines = []string{""}
 		}
 		widths := make([]int, len(lines))
-		sanitized := make([]string, len(lines))
-		sanitizedWidths := make([]int, len(lines))
 		for i, line := range lines {
 			widths[i] = displayWidth(line)
-			safe := textutil.SanitizeTerminalText(line)
-			sanitized[i] = safe
-			sanitizedWidths[i] = displayWidth(safe)
 		}
 		p.lines = lines
 		p.lineWidths = widths
 		p.rawLines = lines
 		p.rawLineWidths = widths
-		p.rawSanitized = sanitized
-		p.rawSanitizedWid = sanitizedWidths
+		p.sanitizeRawLines()
 		p.charCount = charCount
 	}
 
@@ -115,7 +104,7 @@ func (p *PreviewPager) prepareContent() {
 			rules := make([]bool, len(preview.FormattedSegments))
 			ruleStyles := make([]string, len(preview.FormattedSegments))
 			for i, line := range preview.FormattedSegments {
-				formatted[i], rules[i], ruleStyles[i] = ansiFromSegments(line)
+				formatted[i], rules[i], ruleStyles[i] = ansiFromSegments(line, p.theme)
 				if i < len(preview.FormattedSegmentLineMeta) && preview.FormattedSegmentLineMeta[i].DisplayWidth > 0 {
 					widths[i] = preview.FormattedSegmentLineMeta[i].DisplayWidth
 				} else {
@@ -126,6 +115,15 @@ func (p *PreviewPager) prepareContent() {
 			p.formattedWidths = widths
 			p.formattedRules = rules
 			p.formattedStyles = ruleStyles
+		} else if len(preview.FormattedANSILines) > 0 {
+			// External formatter output: its SGR colors were kept when loading.
+			p.formattedLines = append([]string(nil), preview.FormattedANSILines...)
+			p.formattedWidths = make([]int, len(p.formattedLines))
+			for i, line := range p.formattedLines {
+				p.formattedWidths[i] = ansiDisplayWidth(line)
+			}
+			p.formattedRules = nil
+			p.formattedStyles = nil
 		} else if len(preview.FormattedTextLines) > 0 {
 			p.formattedLines = append([]string(nil), preview.FormattedTextLines...)
 			p.formattedWidths = make([]int, len(p.formattedLines))
@@ -145,8 +143,27 @@ func (p *PreviewPager) prepareContent() {
 	p.applyFormatPreference(true)
 }
 
+// useTextSource makes source the raw content, dropping in-memory lines.
+func (p *PreviewPager) useTextSource(source *textPagerSource) {
+	p.rawTextSource = source
+	p.toc, p.tocKey = nil, ""
+	p.lines = nil
+	p.lineWidths = nil
+	p.rawLines = nil
+	p.rawLineWidths = nil
+	p.rawSanitized = nil
+	p.rawSanitizedWid = nil
+	source.keepSGR = p.ansiColors
+}
+
 func (p *PreviewPager) applyFormatPreference(initial bool) {
-	preferRaw := p.state != nil && p.state.PreviewPreferRaw
+	preferRaw := false
+	if p.state != nil {
+		preferRaw = p.state.PreviewPreferRaw
+		if p.state.PreviewData != nil {
+			preferRaw = p.state.PreviewDefaults.RawFor(p.state.PreviewData.Name, preferRaw)
+		}
+	}
 	if len(p.formattedLines) == 0 {
 		p.showFormatted = false
 	} else {
@@ -175,8 +192,15 @@ func (p *PreviewPager) toggleFormatView() {
 		return
 	}
 	p.showFormatted = !p.showFormatted
+	p.inspect = nil
+	if p.hiddenScanActive() {
+		p.searchQuery = ""
+		p.clearSearchResults()
+	}
 	if p.state != nil {
-		p.state.PreviewPreferRaw = !p.showFormatted
+		if p.state.PreviewData == nil || !p.state.PreviewDefaults.SetRaw(p.state.PreviewData.Name, !p.showFormatted) {
+			p.state.PreviewPreferRaw = !p.showFormatted
+		}
 		p.state.PreviewScrollOffset = 0
 		p.state.PreviewWrapOffset = 0
 	}
@@ -187,6 +211,7 @@ func (p *PreviewPager) toggleFormatView() {
 	if p.searchQuery != "" {
 		p.executeSearch(p.searchQuery)
 	}
+	p.refreshSplitPane(true)
 }
 
 func (p *PreviewPager) buildContentLines() ([]string, int, *binaryPagerSource, *textPagerSource) {
@@ -197,20 +222,25 @@ func (p *PreviewPager) buildContentLines() ([]string, int, *binaryPagerSource, *
 	preview := p.state.PreviewData
 	switch {
 	case preview.IsDir:
-		lines := formatDirectoryPreview(preview)
-		return lines, lineCharCount(lines), nil, nil
+		if len(preview.DirEntries) == 0 {
+			lines := []string{"(directory is empty)"}
+			return lines, lineCharCount(lines), nil, nil
+		}
+		return nil, 0, nil, newDirPagerSource(preview.DirEntries)
 	case len(preview.TextLines) > 0:
 		if preview.TextTruncated && len(preview.TextLineMeta) == len(preview.TextLines) {
-			filePath := filepath.Join(p.state.CurrentPath, preview.Name)
-			if source, err := newTextPagerSource(filePath, preview); err == nil {
+			filePath := p.filePath()
+			if source, err := newTextPagerSource(p.state.FS, filePath, preview); err == nil {
 				return nil, preview.TextCharCount, nil, source
 			}
 		}
 		return preview.TextLines, preview.TextCharCount, nil, nil
 	case len(preview.BinaryInfo.Lines) > 0:
-		filePath := filepath.Join(p.state.CurrentPath, preview.Name)
-		source, err := newBinaryPagerSource(filePath, preview.BinaryInfo.TotalBytes, p.width)
+		filePath := p.filePath()
+		source, err := newBinaryPagerSource(p.state.FS, filePath, preview.Reader, preview.BinaryInfo.TotalBytes, p.width)
 		if err == nil {
+			source.SetViewRows(p.height)
+			source.SetDecodeUTF8(p.state.PreviewHexUTF8)
 			return nil, int(preview.BinaryInfo.TotalBytes), source, nil
 		}
 		lines := append([]string(nil), preview.BinaryInfo.Lines...)
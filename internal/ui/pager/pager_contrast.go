@@ -0,0 +1,96 @@
+package pager
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	renderpkg "github.com/kk-code-lab/rdir/internal/ui/render"
+)
+
+// contrastTheme lifts the theme's code colors to the minimum contrast.
+func contrastTheme(contrast *renderpkg.Contrast) renderpkg.ColorTheme {
+	theme := renderpkg.GetColorTheme()
+	theme.CodeFg = contrast.Foreground(theme.CodeFg, theme.CodeBg)
+	theme.CodeBlockFg = contrast.Foreground(theme.CodeBlockFg, theme.CodeBlockBg)
+	return theme
+}
+
+// contrastStyle returns one of the pager's bar or scrollbar styles with its
+// text color lifted to the minimum contrast.
+func (p *PreviewPager) contrastStyle(style string) string {
+	if p.contrast == nil || style == "" {
+		return style
+	}
+	if adjusted, ok := p.contrastStyles[style]; ok {
+		return adjusted
+	}
+	adjusted := adjustSGRContrast(style, p.contrast)
+	if p.contrastStyles == nil {
+		p.contrastStyles = make(map[string]string)
+	}
+	p.contrastStyles[style] = adjusted
+	return adjusted
+}
+
+// adjustSGRContrast rewrites the foreground of SGR sequences such as
+// "\x1b[48;5;238m\x1b[97m" when it reads poorly on their background. It
+// knows the basic, bright and 256-color codes the pager uses and returns
+// anything else unchanged.
+func adjustSGRContrast(seq string, contrast *renderpkg.Contrast) string {
+	fg, bg := tcell.ColorDefault, tcell.ColorDefault
+	var params, bgParams []string
+	for _, part := range strings.Split(seq, "\x1b[") {
+		if part == "" {
+			continue
+		}
+		body, ok := strings.CutSuffix(part, "m")
+		if !ok {
+			return seq
+		}
+		fields := strings.Split(body, ";")
+		for i := 0; i < len(fields); i++ {
+			n, err := strconv.Atoi(fields[i])
+			if err != nil {
+				return seq
+			}
+			switch {
+			case (n == 38 || n == 48) && i+2 < len(fields) && fields[i+1] == "5":
+				idx, err := strconv.Atoi(fields[i+2])
+				if err != nil || idx < 0 || idx > 255 {
+					return seq
+				}
+				if n == 38 {
+					fg = tcell.PaletteColor(idx)
+				} else {
+					bg = tcell.PaletteColor(idx)
+					bgParams = fields[i : i+3]
+				}
+				i += 2
+			case n >= 30 && n <= 37:
+				fg = tcell.PaletteColor(n - 30)
+			case n >= 90 && n <= 97:
+				fg = tcell.PaletteColor(n - 90 + 8)
+			case n >= 40 && n <= 47, n >= 100 && n <= 107:
+				bg = tcell.PaletteColor(n - 40)
+				if n >= 100 {
+					bg = tcell.PaletteColor(n - 100 + 8)
+				}
+				bgParams = fields[i : i+1]
+			case n == 38 || n == 48:
+				return seq
+			default:
+				params = append(params, fields[i])
+			}
+		}
+	}
+	adjusted := contrast.Foreground(fg, bg)
+	if adjusted == fg {
+		return seq
+	}
+	r, g, b := adjusted.RGB()
+	params = append(params, fmt.Sprintf("38;2;%d;%d;%d", r, g, b))
+	params = append(params, bgParams...)
+	return "\x1b[" + strings.Join(params, ";") + "m"
+}
@@ -0,0 +1,232 @@
+package pager
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+	"golang.org/x/text/unicode/runenames"
+)
+
+// hiddenFormattingQuery stands in for a typed query while the search state
+// holds the hidden-formatting scan, so n/N, Enter, the scrollbar ticks and
+// the split panes treat the occurrences like search hits. It starts with a
+// NUL byte, which cannot be typed into the search prompt.
+const hiddenFormattingQuery = "\x00hidden-formatting"
+
+func (p *PreviewPager) hiddenScanActive() bool {
+	return p.searchQuery == hiddenFormattingQuery
+}
+
+// hiddenFormattingAvailable reports whether the scan can run on the current
+// view, warning in the status bar when it cannot.
+func (p *PreviewPager) hiddenFormattingAvailable() bool {
+	switch {
+	case p.binaryMode:
+		p.setStatusMessage("hidden formatting applies to text files", statusWarnStyle)
+		return false
+	case p.showFormatted:
+		p.setStatusMessage("hidden formatting is listed in the raw view (press f)", statusWarnStyle)
+		return false
+	}
+	return true
+}
+
+// startHiddenScan replaces the current search with the hidden-formatting
+// scan. It reports false when the file has no such characters.
+func (p *PreviewPager) startHiddenScan() bool {
+	if p.searchMode {
+		p.exitSearchMode()
+	}
+	p.executeSearch(hiddenFormattingQuery)
+	if len(p.searchHits) == 0 {
+		p.searchQuery = ""
+		p.clearSearchResults()
+		p.setStatusMessage("no hidden formatting characters", "")
+		return false
+	}
+	return true
+}
+
+// nextHiddenFormatting (z) starts the scan on the first occurrence at or
+// after the current position, then steps to the next one.
+func (p *PreviewPager) nextHiddenFormatting() {
+	if !p.hiddenFormattingAvailable() {
+		return
+	}
+	if !p.hiddenScanActive() {
+		if p.startHiddenScan() {
+			p.focusSearchHit(p.searchCursor)
+		}
+		return
+	}
+	p.moveSearchCursor(1)
+}
+
+// openHiddenList (Z) shows every occurrence in an overlay.
+func (p *PreviewPager) openHiddenList() {
+	if !p.hiddenFormattingAvailable() {
+		return
+	}
+	if !p.hiddenScanActive() && !p.startHiddenScan() {
+		return
+	}
+	p.showHiddenList = true
+	p.hiddenListCursor = max(p.searchCursor, 0)
+}
+
+func (p *PreviewPager) handleHiddenListKey(ev keyEvent) bool {
+	switch ev.kind {
+	case keyCtrlC:
+		return true
+	case keyHiddenList, keyQuit, keyEscape, keyLeft:
+		p.showHiddenList = false
+	case keyUp:
+		p.hiddenListCursor--
+	case keyDown:
+		p.hiddenListCursor++
+	case keyPageUp:
+		p.hiddenListCursor -= p.hiddenListRows()
+	case keyPageDown, keySpace:
+		p.hiddenListCursor += p.hiddenListRows()
+	case keyHome:
+		p.hiddenListCursor = 0
+	case keyEnd:
+		p.hiddenListCursor = len(p.searchHits) - 1
+	case keyEnter, keyRight:
+		p.showHiddenList = false
+		if p.hiddenListCursor >= 0 && p.hiddenListCursor < len(p.searchHits) {
+			p.searchCursor = p.hiddenListCursor
+			p.focusSearchHit(p.searchCursor)
+		}
+		return false
+	}
+	p.hiddenListCursor = min(max(p.hiddenListCursor, 0), max(len(p.searchHits)-1, 0))
+	return false
+}
+
+// collectHiddenFormatting finds bidi and zero-width formatting characters in
+// the raw text. Spans cover the ⟪…⟫ label the character is drawn as.
+func (p *PreviewPager) collectHiddenFormatting() ([]searchHit, map[int][]textSpan, bool, error) {
+	if p.binaryMode || p.showFormatted {
+		return nil, nil, false, nil
+	}
+	hits := []searchHit{}
+	highlights := make(map[int][]textSpan)
+	scan := func(idx int, raw string) bool {
+		for b, r := range raw {
+			label, ok := textutil.FormattingRuneLabel(r)
+			if !ok {
+				continue
+			}
+			start := ansiDisplayWidth(p.sanitizeRawLine(raw[:b]))
+			span := textSpan{start: start, end: start + displayWidth(label)}
+			highlights[idx] = append(highlights[idx], span)
+			hits = append(hits, searchHit{line: idx, span: span, mark: r})
+			if len(hits) >= searchMaxHits {
+				return false
+			}
+		}
+		return true
+	}
+
+	if src := p.rawTextSource; src != nil {
+		for i := 0; i < searchMaxLines; i++ {
+			if err := src.EnsureLine(i); err != nil && !errors.Is(err, io.EOF) {
+				return hits, highlights, true, err
+			}
+			if i >= src.LineCount() {
+				return hits, highlights, false, nil
+			}
+			if !scan(i, src.Line(i)) {
+				return hits, highlights, true, nil
+			}
+		}
+		return hits, highlights, src.LineCount() > searchMaxLines || !src.FullyLoaded(), nil
+	}
+
+	limit := min(len(p.rawLines), searchMaxLines)
+	for i := 0; i < limit; i++ {
+		if !scan(i, p.rawLines[i]) {
+			return hits, highlights, true, nil
+		}
+	}
+	return hits, highlights, len(p.rawLines) > limit, nil
+}
+
+// describeHiddenRune names a formatting character, e.g.
+// "U+202E RLO RIGHT-TO-LEFT OVERRIDE".
+func describeHiddenRune(r rune) string {
+	label, _ := textutil.FormattingRuneLabel(r)
+	label = strings.TrimSuffix(strings.TrimPrefix(label, "⟪"), "⟫")
+	return strings.TrimSpace(fmt.Sprintf("U+%04X %s %s", r, label, runenames.Name(r)))
+}
+
+// hiddenSegment is the status bar text while the scan is active.
+func (p *PreviewPager) hiddenSegment() string {
+	segment := "hidden " + p.searchCountsSegment()
+	if hit := p.focusedHit(); hit != nil {
+		segment += fmt.Sprintf(" · ln %d %s", hit.line+1, describeHiddenRune(hit.mark))
+	}
+	return segment
+}
+
+// hiddenListRows is the number of list entries the overlay shows at once.
+func (p *PreviewPager) hiddenListRows() int {
+	return max(p.height-2, 1)
+}
+
+// hiddenListLines formats the visible part of the occurrence list.
+func (p *PreviewPager) hiddenListLines() []string {
+	rows := p.hiddenListRows()
+	first := 0
+	if p.hiddenListCursor >= rows {
+		first = p.hiddenListCursor - rows + 1
+	}
+	lines := make([]string, 0, rows)
+	for i := first; i < len(p.searchHits) && len(lines) < rows; i++ {
+		hit := p.searchHits[i]
+		marker := "  "
+		if i == p.hiddenListCursor {
+			marker = "› "
+		}
+		line := fmt.Sprintf("%s%6d:%-4d %s", marker, hit.line+1, hit.span.start+1, describeHiddenRune(hit.mark))
+		if p.width > 0 {
+			line = truncateToWidth(line, p.width)
+		}
+		if i == p.hiddenListCursor {
+			line = searchHighlightFocusOn + line + searchHighlightFocusOff
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (p *PreviewPager) renderHiddenList() error {
+	p.writeString("\x1b[?25l")
+	p.writeString("\x1b[2J")
+	p.writeString("\x1b[H")
+
+	title := fmt.Sprintf(" Hidden formatting characters (%d", len(p.searchHits))
+	if p.searchLimited {
+		title += "+"
+	}
+	p.drawStyledRow(1, title+") ", true, headerBarStyle)
+	row := 2
+	for _, line := range p.hiddenListLines() {
+		p.drawRow(row, line, false)
+		row++
+	}
+	for row < p.height {
+		p.drawRow(row, "", false)
+		row++
+	}
+	p.drawStatus("↑/↓ select  ·  Enter jump  ·  q/Esc/Z close")
+
+	if p.writer != nil {
+		return p.writer.Flush()
+	}
+	return nil
+}
@@ -4,7 +4,10 @@ import (
 	"errors"
 	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
+
+	"github.com/kk-code-lab/rdir/internal/lineedit"
 )
 
 type keyKind int
@@ -27,11 +30,25 @@ const (
 	keyToggleHelp
 	keyToggleInfo
 	keyToggleFormat
+	keyToggleANSI
+	keyToggleScrollbar
+	keyToggleHexUTF8
+	keyToggleSplit
+	keySwitchPane
+	keyToggleInspect
+	keyToggleReading
+	keyToggleTOC
+	keyHiddenNext
+	keyHiddenList
+	keyRecentFiles
 	keyOpenEditor
 	keyShiftUp
 	keyShiftDown
 	keyCopyVisible
 	keyCopyAll
+	keyCopyAllViaFile
+	keyCopyRef
+	keyExportQuickfix
 	keyStartSearch
 	keyStartBinarySearch
 	keySearchNext
@@ -45,12 +62,14 @@ const (
 	keyJumpForwardSmall
 	keyJumpBackLarge
 	keyJumpForwardLarge
+	keyLineEdit
 )
 
 type keyEvent struct {
 	kind keyKind
 	ch   rune
 	mod  int
+	edit lineedit.Op // for keyLineEdit
 }
 
 func (p *PreviewPager) readKeyEvent() (keyEvent, error) {
@@ -84,12 +103,40 @@ func (p *PreviewPager) readKeyEvent() (keyEvent, error) {
 		return keyEvent{kind: keyToggleInfo, ch: rune(b)}, nil
 	case 'f', 'F':
 		return keyEvent{kind: keyToggleFormat, ch: rune(b)}, nil
+	case 'a', 'A':
+		return keyEvent{kind: keyToggleANSI, ch: rune(b)}, nil
+	case 'm', 'M':
+		return keyEvent{kind: keyToggleScrollbar, ch: rune(b)}, nil
+	case 'd', 'D':
+		return keyEvent{kind: keyToggleHexUTF8, ch: rune(b)}, nil
+	case 's', 'S':
+		return keyEvent{kind: keyToggleSplit, ch: rune(b)}, nil
+	case 'r', 'R':
+		return keyEvent{kind: keyToggleReading, ch: rune(b)}, nil
+	case 'u', 'U':
+		return keyEvent{kind: keyToggleInspect, ch: rune(b)}, nil
+	case 't', 'T':
+		return keyEvent{kind: keyToggleTOC, ch: rune(b)}, nil
+	case 'z':
+		return keyEvent{kind: keyHiddenNext, ch: rune(b)}, nil
+	case 'Z':
+		return keyEvent{kind: keyHiddenList, ch: rune(b)}, nil
+	case 'v', 'V':
+		return keyEvent{kind: keyRecentFiles, ch: rune(b)}, nil
+	case '\t':
+		return keyEvent{kind: keySwitchPane}, nil
 	case 'e', 'E':
 		return keyEvent{kind: keyOpenEditor, ch: rune(b)}, nil
 	case 'c':
 		return keyEvent{kind: keyCopyVisible, ch: rune(b)}, nil
 	case 'C':
 		return keyEvent{kind: keyCopyAll, ch: rune(b)}, nil
+	case 'P':
+		return keyEvent{kind: keyCopyAllViaFile, ch: rune(b)}, nil
+	case 'y', 'Y':
+		return keyEvent{kind: keyCopyRef, ch: rune(b)}, nil
+	case 'o', 'O':
+		return keyEvent{kind: keyExportQuickfix, ch: rune(b)}, nil
 	case '/':
 		return keyEvent{kind: keyStartSearch, ch: rune(b)}, nil
 	case ':':
@@ -109,7 +156,7 @@ func (p *PreviewPager) readKeyEvent() (keyEvent, error) {
 	case 'g':
 		return keyEvent{kind: keyHome, ch: rune(b)}, nil
 	case 'G':
-		return keyEvent{kind: keyEnd}, nil
+		return keyEvent{kind: keyEnd, ch: rune(b)}, nil
 	case '[':
 		return keyEvent{kind: keyJumpBackSmall, ch: rune(b)}, nil
 	case ']':
@@ -126,6 +173,9 @@ func (p *PreviewPager) readKeyEvent() (keyEvent, error) {
 		return keyEvent{kind: keyCtrlC}, nil
 	default:
 	}
+	if op, ok := lineEditControl(rune(b)); ok {
+		return keyEvent{kind: keyLineEdit, edit: op}, nil
+	}
 
 	if b < utf8.RuneSelf {
 		if b >= 0x20 {
@@ -134,27 +184,46 @@ func (p *PreviewPager) readKeyEvent() (keyEvent, error) {
 		return keyEvent{kind: keyUnknown}, nil
 	}
 
-	buf := []byte{b}
-	for !utf8.FullRune(buf) && len(buf) < utf8.UTFMax {
-		next, err := p.reader.ReadByte()
-		if err != nil {
-			break
-		}
-		buf = append(buf, next)
-	}
-	r, _ := utf8.DecodeRune(buf)
-	if r != utf8.RuneError {
+	if r, ok := p.readRuneAfter(b); ok {
 		return keyEvent{kind: keyRune, ch: r}, nil
 	}
 	return keyEvent{kind: keyUnknown}, nil
 }
 
-func (p *PreviewPager) parseEscapeSequence() (keyEvent, error) {
-	if p.reader.Buffered() == 0 {
-		return keyEvent{kind: keyEscape}, nil
+// readRuneAfter completes the UTF-8 sequence that lead starts, as sent for
+// non-ASCII keys, dead-key compositions and input method text. Only
+// continuation bytes are taken, waiting up to the escape timeout for ones
+// still in flight; a byte that cannot continue the sequence is left for the
+// next key, so a malformed or cut-off sequence costs nothing but itself.
+func (p *PreviewPager) readRuneAfter(lead byte) (rune, bool) {
+	buf := []byte{lead}
+	for !utf8.FullRune(buf) {
+		next, ok := p.sequenceByte()
+		if !ok {
+			return 0, false
+		}
+		if !utf8.RuneStart(next) {
+			buf = append(buf, next)
+			continue
+		}
+		_ = p.reader.UnreadByte()
+		return 0, false
 	}
-	next, err := p.reader.ReadByte()
-	if err != nil {
+	r, size := utf8.DecodeRune(buf)
+	if r == utf8.RuneError && size <= 1 {
+		return 0, false
+	}
+	return r, true
+}
+
+// parseEscapeSequence reads what follows an ESC. Over a slow link the rest
+// of an arrow key's sequence can arrive after the ESC itself, so a lone ESC
+// waits up to the escape timeout (RDIR_ESC_TIMEOUT) before counting as the
+// Escape key, and a sequence that stops halfway is dropped instead of eating
+// the next key.
+func (p *PreviewPager) parseEscapeSequence() (keyEvent, error) {
+	next, ok := p.sequenceByte()
+	if !ok {
 		return keyEvent{kind: keyEscape}, nil
 	}
 
@@ -162,11 +231,19 @@ func (p *PreviewPager) parseEscapeSequence() (keyEvent, error) {
 	case '[':
 		return p.parseCSI()
 	case 'O':
-		final, err := p.reader.ReadByte()
-		if err != nil {
-			return keyEvent{kind: keyEscape}, nil
+		final, ok := p.sequenceByte()
+		if !ok {
+			return keyEvent{kind: keyUnknown}, nil
 		}
 		switch final {
+		case 'A':
+			return keyEvent{kind: keyUp, mod: 1}, nil
+		case 'B':
+			return keyEvent{kind: keyDown, mod: 1}, nil
+		case 'C':
+			return keyEvent{kind: keyRight, mod: 1}, nil
+		case 'D':
+			return keyEvent{kind: keyLeft, mod: 1}, nil
 		case 'H':
 			return keyEvent{kind: keyHome}, nil
 		case 'F':
@@ -174,31 +251,91 @@ func (p *PreviewPager) parseEscapeSequence() (keyEvent, error) {
 		default:
 			return keyEvent{kind: keyUnknown}, nil
 		}
-	default:
+	case 0x1b:
+		// Esc pressed twice: this one is Escape, the next starts over.
+		_ = p.reader.UnreadByte()
 		return keyEvent{kind: keyEscape}, nil
+	default:
+		// Alt+key; only the search input's word editing uses it.
+		if op, ok := lineEditAlt(rune(next)); ok {
+			return keyEvent{kind: keyLineEdit, edit: op}, nil
+		}
+		return keyEvent{kind: keyUnknown}, nil
+	}
+}
+
+// lineEditControl maps the control characters the search input edits with:
+// Ctrl+A/E, Ctrl+W, Ctrl+U and Ctrl+K.
+func lineEditControl(ch rune) (lineedit.Op, bool) {
+	switch ch {
+	case 0x01:
+		return lineedit.Home, true
+	case 0x05:
+		return lineedit.End, true
+	case 0x17:
+		return lineedit.DeleteWordBack, true
+	case 0x15:
+		return lineedit.KillToStart, true
+	case 0x0b:
+		return lineedit.KillToEnd, true
+	}
+	return "", false
+}
+
+// lineEditAlt maps Alt+B/F/D and Alt+Backspace.
+func lineEditAlt(ch rune) (lineedit.Op, bool) {
+	switch ch {
+	case 'b', 'B':
+		return lineedit.WordLeft, true
+	case 'f', 'F':
+		return lineedit.WordRight, true
+	case 'd', 'D':
+		return lineedit.DeleteWordForward, true
+	case 0x7f, 0x08:
+		return lineedit.DeleteWordBack, true
+	}
+	return "", false
+}
+
+// sequenceByte returns the next byte of an escape sequence, waiting up to
+// the escape timeout for it when none is buffered. ok is false when the
+// sequence ends there.
+func (p *PreviewPager) sequenceByte() (byte, bool) {
+	if p.reader.Buffered() == 0 && !p.waitForInput(p.escapeTimeout()) {
+		return 0, false
+	}
+	b, err := p.reader.ReadByte()
+	return b, err == nil
+}
+
+func (p *PreviewPager) escapeTimeout() time.Duration {
+	if p.state == nil {
+		return 0
 	}
+	return p.state.EscapeTimeout
 }
 
 func (p *PreviewPager) parseCSI() (keyEvent, error) {
 	seq := []byte{}
 	for {
-		b, err := p.reader.ReadByte()
-		if err != nil {
-			return keyEvent{kind: keyEscape}, nil
+		b, ok := p.sequenceByte()
+		if !ok {
+			return keyEvent{kind: keyUnknown}, nil
+		}
+		if b == 0x1b || b < 0x20 {
+			// A new key started before this sequence ended.
+			_ = p.reader.UnreadByte()
+			return keyEvent{kind: keyUnknown}, nil
 		}
 		seq = append(seq, b)
 		if isCSIFinalByte(b) {
 			break
 		}
-		if len(seq) >= 16 {
+		if len(seq) >= 32 {
 			return keyEvent{kind: keyUnknown}, nil
 		}
 	}
 
-	if len(seq) == 0 {
-		return keyEvent{kind: keyUnknown}, nil
-	}
-
 	final := seq[len(seq)-1]
 	base, modifier := parseCSIParameters(string(seq[:len(seq)-1]))
 
@@ -231,6 +368,8 @@ func (p *PreviewPager) parseCSI() (keyEvent, error) {
 			return keyEvent{kind: keyHome, mod: modifier}, nil
 		case "4", "8":
 			return keyEvent{kind: keyEnd, mod: modifier}, nil
+		case "3":
+			return keyEvent{kind: keyLineEdit, edit: lineedit.Delete}, nil
 		default:
 			return keyEvent{kind: keyUnknown}, nil
 		}
@@ -238,8 +377,11 @@ func (p *PreviewPager) parseCSI() (keyEvent, error) {
 	return keyEvent{kind: keyUnknown}, nil
 }
 
+// isCSIFinalByte reports whether b ends a control sequence (ECMA-48: 0x40
+// to 0x7e), so sequences the pager does not know, such as CSI u, are still
+// consumed whole.
 func isCSIFinalByte(b byte) bool {
-	return (b >= 'A' && b <= 'Z') || b == '~'
+	return b >= 0x40 && b <= 0x7e
 }
 
 func parseCSIParameters(param string) (string, int) {
@@ -267,6 +409,12 @@ func parseCSIParameters(param string) (string, int) {
 	return base, modifier
 }
 
+// hasAltModifier reports whether an xterm modifier parameter includes Alt
+// (3, 4, 7, 8).
+func hasAltModifier(mod int) bool {
+	return mod > 1 && (mod-1)&2 != 0
+}
+
 func hasShiftModifier(mod int) bool {
 	switch mod {
 	case 2, 4, 6, 8:
@@ -0,0 +1,342 @@
+package pager
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+	"github.com/rivo/uniseg"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/unicode/runenames"
+)
+
+// inspectCursor is the character inspector position: a line and a grapheme
+// cluster index within the line's raw (unexpanded, unsanitized) text.
+type inspectCursor struct {
+	line    int
+	cluster int
+
+	// clusters caches the layout of cachedLine, which is re-read from disk
+	// otherwise on every render.
+	cachedLine int
+	cachedANSI bool
+	clusters   []inspectCluster
+}
+
+// inspectCluster describes one grapheme cluster of a raw line.
+type inspectCluster struct {
+	text   string
+	offset int64 // file offset of the first byte, -1 when unknown
+	bytes  []byte
+	start  int // display column in the rendered line
+	width  int // columns the cluster takes in the rendered line
+}
+
+func (p *PreviewPager) toggleInspect() {
+	if p.inspect != nil {
+		p.inspect = nil
+		return
+	}
+	switch {
+	case p.binaryMode:
+		p.setStatusMessage("the hex view already shows bytes", statusWarnStyle)
+		return
+	case p.showFormatted:
+		p.setStatusMessage("character inspector works on the raw view (press f)", statusWarnStyle)
+		return
+	}
+	p.inspect = &inspectCursor{line: max(p.state.PreviewScrollOffset, 0), cachedLine: -1}
+}
+
+// handleInspectKey moves the inspector cursor; it reports whether the key
+// was consumed.
+func (p *PreviewPager) handleInspectKey(ev keyEvent) bool {
+	if p.inspect == nil {
+		return false
+	}
+	switch ev.kind {
+	case keyEscape, keyToggleInspect:
+		p.inspect = nil
+	case keyLeft:
+		if hasAltModifier(ev.mod) {
+			p.inspect = nil
+			break
+		}
+		if p.inspect.cluster > 0 {
+			p.inspect.cluster--
+		}
+	case keyRight:
+		p.inspect.cluster++
+	case keyUp:
+		if p.inspect.line > 0 {
+			p.inspect.line--
+		}
+	case keyDown:
+		if p.inspect.line+1 < p.lineCount() {
+			p.inspect.line++
+		}
+	default:
+		return false
+	}
+	if p.inspect != nil {
+		p.clampInspectCursor()
+		p.revealInspectCursor()
+	}
+	return true
+}
+
+// cursorClusters returns the cluster layout of the cursor line.
+func (p *PreviewPager) cursorClusters() []inspectCluster {
+	c := p.inspect
+	if c.clusters == nil || c.cachedLine != c.line || c.cachedANSI != p.ansiColors {
+		c.clusters = p.inspectClusters(c.line)
+		c.cachedLine = c.line
+		c.cachedANSI = p.ansiColors
+	}
+	return c.clusters
+}
+
+func (p *PreviewPager) clampInspectCursor() {
+	clusters := p.cursorClusters()
+	if p.inspect.cluster >= len(clusters) {
+		p.inspect.cluster = len(clusters) - 1
+	}
+	if p.inspect.cluster < 0 {
+		p.inspect.cluster = 0
+	}
+}
+
+// revealInspectCursor scrolls so the cursor stays on screen.
+func (p *PreviewPager) revealInspectCursor() {
+	span := p.inspectSpan()
+	if p.hitVisible(searchHit{line: p.inspect.line, span: span}) {
+		return
+	}
+	p.state.PreviewScrollOffset = p.inspect.line
+	p.state.PreviewWrapOffset = 0
+	if p.wrapEnabled && p.width > 0 {
+		p.state.PreviewWrapOffset = span.start / p.width
+	}
+}
+
+// inspectRawLine returns the line as stored in the file (tabs and escapes
+// intact) and the file offset of its first byte, or -1 when it is unknown.
+func (p *PreviewPager) inspectRawLine(idx int) (string, int64) {
+	offset, length := int64(-1), 0
+	if src := p.rawTextSource; src != nil {
+		if err := src.EnsureLine(idx); err == nil && idx < len(src.lines) {
+			offset, length = src.lines[idx].offset, src.lines[idx].length
+		}
+	} else if preview := p.state.PreviewData; preview != nil && idx < len(preview.TextLineMeta) {
+		offset, length = preview.TextLineMeta[idx].Offset, preview.TextLineMeta[idx].Length
+	}
+	if offset >= 0 {
+		if text, ok := p.readRawLine(offset, length); ok {
+			return text, offset
+		}
+	}
+	if idx >= 0 && idx < len(p.rawLines) {
+		return p.rawLines[idx], -1
+	}
+	return p.lineAt(idx), -1
+}
+
+func (p *PreviewPager) readRawLine(offset int64, length int) (string, bool) {
+	preview := p.state.PreviewData
+	if preview == nil || length < 0 {
+		return "", false
+	}
+	if length == 0 {
+		return "", true
+	}
+	// Offsets are into what the text source reads, which for decompressed
+	// or privileged content is not the file on disk.
+	var file io.ReaderAt
+	if src := p.rawTextSource; src != nil && src.generate == nil {
+		if err := src.openFile(); err != nil {
+			return "", false
+		}
+		file = src.file
+	} else if preview.Compressed != nil || preview.Privileged != "" {
+		return "", false
+	} else {
+		f, err := fsutil.OrLocal(p.state.FS).Open(p.filePath())
+		if err != nil {
+			return "", false
+		}
+		defer func() { _ = f.Close() }()
+		file = f
+	}
+	buf := make([]byte, length)
+	if _, err := file.ReadAt(buf, offset); err != nil {
+		return "", false
+	}
+	if enc := preview.TextEncoding; enc == fsutil.EncodingUTF16LE || enc == fsutil.EncodingUTF16BE {
+		endian := unicode.LittleEndian
+		if enc == fsutil.EncodingUTF16BE {
+			endian = unicode.BigEndian
+		}
+		decoded, err := unicode.UTF16(endian, unicode.IgnoreBOM).NewDecoder().Bytes(buf)
+		if err != nil {
+			return "", false
+		}
+		return string(decoded), true
+	}
+	return string(buf), true
+}
+
+// inspectClusters splits a raw line into grapheme clusters with their file
+// bytes and the columns they occupy once the line is tab-expanded and
+// sanitized for display. An empty line yields a single end-of-line entry.
+func (p *PreviewPager) inspectClusters(idx int) []inspectCluster {
+	raw, offset := p.inspectRawLine(idx)
+	utf16Enc := p.state.PreviewData != nil &&
+		(p.state.PreviewData.TextEncoding == fsutil.EncodingUTF16LE || p.state.PreviewData.TextEncoding == fsutil.EncodingUTF16BE)
+
+	var clusters []inspectCluster
+	col, expandedCol := 0, 0
+	pos := 0
+	sgrLeft := 0
+	g := uniseg.NewGraphemes(raw)
+	for g.Next() {
+		cluster := g.Str()
+		bytes := p.encodeCluster(cluster, utf16Enc)
+		width := 0
+		advance := max(textutil.DisplayWidth(cluster), 1)
+		switch {
+		case sgrLeft > 0 || (p.ansiColors && keptSGRLen(raw[pos:]) > 0):
+			// A color sequence the display keeps: it takes no columns.
+			if sgrLeft == 0 {
+				sgrLeft = keptSGRLen(raw[pos:])
+			}
+			sgrLeft = max(sgrLeft-len(cluster), 0)
+		case cluster == "\t":
+			width = textutil.DefaultTabWidth - expandedCol%textutil.DefaultTabWidth
+			advance = width
+		default:
+			width = highlightWidth(textutil.SanitizeTerminalText(cluster))
+		}
+		entry := inspectCluster{text: cluster, offset: -1, bytes: bytes, start: col, width: width}
+		if offset >= 0 {
+			entry.offset = offset
+			offset += int64(len(bytes))
+		}
+		clusters = append(clusters, entry)
+		col += width
+		expandedCol += advance
+		pos += len(cluster)
+	}
+	if len(clusters) == 0 {
+		clusters = append(clusters, inspectCluster{offset: offset, start: 0, width: 1})
+	}
+	return clusters
+}
+
+// keptSGRLen is the length of the SGR sequence at the start of text when
+// the ANSI pass-through would keep it, or 0.
+func keptSGRLen(text string) int {
+	n := ansiSequenceLen(text)
+	if n == 0 || textutil.SanitizeTerminalTextKeepSGR(text[:n]) != text[:n] {
+		return 0
+	}
+	return n
+}
+
+// encodeCluster returns the cluster's bytes as stored in the file.
+func (p *PreviewPager) encodeCluster(cluster string, utf16Enc bool) []byte {
+	if !utf16Enc {
+		return []byte(cluster)
+	}
+	bigEndian := p.state.PreviewData.TextEncoding == fsutil.EncodingUTF16BE
+	var out []byte
+	for _, unit := range utf16.Encode([]rune(cluster)) {
+		if bigEndian {
+			out = append(out, byte(unit>>8), byte(unit))
+		} else {
+			out = append(out, byte(unit), byte(unit>>8))
+		}
+	}
+	return out
+}
+
+// highlightWidth counts columns the way applySearchHighlights does: every
+// grapheme cluster takes at least one column.
+func highlightWidth(text string) int {
+	width := 0
+	g := uniseg.NewGraphemes(text)
+	for g.Next() {
+		width += max(textutil.DisplayWidth(g.Str()), 1)
+	}
+	return width
+}
+
+func (p *PreviewPager) inspectSpan() textSpan {
+	clusters := p.cursorClusters()
+	idx := min(max(p.inspect.cluster, 0), len(clusters)-1)
+	c := clusters[idx]
+	return textSpan{start: c.start, end: c.start + max(c.width, 1)}
+}
+
+// lineHighlights returns the highlight spans for a rendered line: the
+// inspector cursor on its line, search highlights elsewhere.
+func (p *PreviewPager) lineHighlights(lineIdx int, drop int, widthLimit int) ([]textSpan, []textSpan) {
+	if p.inspect != nil && p.inspect.line == lineIdx {
+		if sp, ok := adjustSpan(p.inspectSpan(), drop, widthLimit); ok {
+			return []textSpan{sp}, []textSpan{sp}
+		}
+		return nil, nil
+	}
+	return p.visibleHighlights(lineIdx, drop, widthLimit)
+}
+
+// inspectSegment describes the character under the inspector cursor for
+// the status line.
+func (p *PreviewPager) inspectSegment() string {
+	if p.inspect == nil {
+		return ""
+	}
+	clusters := p.cursorClusters()
+	idx := min(max(p.inspect.cluster, 0), len(clusters)-1)
+	c := clusters[idx]
+	pos := fmt.Sprintf("ln %d col %d", p.inspect.line+1, idx+1)
+	if c.text == "" {
+		return pos + " · end of line"
+	}
+
+	codepoints := make([]string, 0, utf8.RuneCountInString(c.text))
+	for _, r := range c.text {
+		codepoints = append(codepoints, fmt.Sprintf("U+%04X", r))
+	}
+	parts := []string{pos, strings.Join(codepoints, " ")}
+	if r, size := utf8.DecodeRuneInString(c.text); size == len(c.text) {
+		if r == utf8.RuneError && size == 1 {
+			parts = append(parts, "invalid UTF-8")
+		} else if name := runenames.Name(r); name != "" {
+			parts = append(parts, name)
+		}
+	}
+
+	label := "UTF-8"
+	if p.state.PreviewData != nil {
+		switch p.state.PreviewData.TextEncoding {
+		case fsutil.EncodingUTF16LE:
+			label = "UTF-16LE"
+		case fsutil.EncodingUTF16BE:
+			label = "UTF-16BE"
+		}
+	}
+	hex := make([]string, len(c.bytes))
+	for i, b := range c.bytes {
+		hex[i] = fmt.Sprintf("%02X", b)
+	}
+	parts = append(parts, label+" "+strings.Join(hex, " "))
+	if c.offset >= 0 {
+		parts = append(parts, fmt.Sprintf("byte %d (0x%X)", c.offset, c.offset))
+	}
+	parts = append(parts, fmt.Sprintf("width %d", textutil.DisplayWidth(c.text)))
+	return strings.Join(parts, " · ")
+}
@@ -0,0 +1,80 @@
+package pager
+
+import (
+	"time"
+
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
+)
+
+// SetMemory lets the pager restore and record per-file state (wrap,
+// formatted/raw view, ANSI colors, scroll position, last search) across
+// sessions.
+func (p *PreviewPager) SetMemory(mem *statepkg.PagerMemory) {
+	p.memory = mem
+}
+
+func (p *PreviewPager) rememberable() bool {
+	return p != nil && p.memory != nil && p.state != nil && p.state.PreviewData != nil &&
+		!p.state.PreviewData.IsDir && !p.binaryMode
+}
+
+// restoreFileState applies the state remembered for the current file. The
+// scroll position is only restored when the inline preview was not scrolled,
+// since that is the fresher intent.
+func (p *PreviewPager) restoreFileState() {
+	if !p.rememberable() {
+		return
+	}
+	st, ok := p.memory.Lookup(p.filePath())
+	if !ok {
+		return
+	}
+	if st.Wrap != p.wrapEnabled {
+		p.wrapEnabled = st.Wrap
+		p.rowMetricsWidth = 0
+		p.resetWrapCache()
+	}
+	if st.ANSI != p.ansiColors {
+		p.setANSIColors(st.ANSI)
+	}
+	if len(p.formattedLines) > 0 && st.Raw == p.showFormatted {
+		p.showFormatted = !st.Raw
+		p.updateDisplayLines()
+		p.rowSpans = nil
+		p.rowPrefix = nil
+		p.resetWrapCache()
+	}
+	if p.state.PreviewScrollOffset == 0 && p.state.PreviewWrapOffset == 0 {
+		p.state.PreviewScrollOffset = st.Line
+		if p.wrapEnabled {
+			p.state.PreviewWrapOffset = st.WrapOffset
+		}
+		if total := p.lineCount(); st.Line >= total {
+			p.state.PreviewScrollOffset = max(total-1, 0)
+			p.state.PreviewWrapOffset = 0
+		}
+	}
+	if st.Query != "" {
+		p.executeSearch(st.Query)
+	}
+}
+
+// rememberFileState records the current view for the next session.
+func (p *PreviewPager) rememberFileState() {
+	if !p.rememberable() {
+		return
+	}
+	query := p.searchQuery
+	if p.hiddenScanActive() {
+		query = ""
+	}
+	p.memory.Remember(p.filePath(), statepkg.PagerFileState{
+		Wrap:       p.wrapEnabled,
+		Raw:        len(p.formattedLines) > 0 && !p.showFormatted,
+		ANSI:       p.ansiColors,
+		Line:       p.state.PreviewScrollOffset,
+		WrapOffset: p.state.PreviewWrapOffset,
+		Query:      query,
+		UsedAt:     time.Now(),
+	})
+}
@@ -0,0 +1,87 @@
+package pager
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+	"github.com/rivo/uniseg"
+)
+
+// quickfixEntries formats the search hits as path:line:column:text, the
+// format vim -q, :cfile and grep -n --column share. Columns are 1-based byte
+// offsets into the line as the pager holds it; streamed files have their
+// tabs expanded, so columns after a tab can be off there.
+func (p *PreviewPager) quickfixEntries() ([]string, error) {
+	if p.state == nil || p.state.PreviewData == nil {
+		return nil, errors.New("nothing to export")
+	}
+	if p.binaryMode {
+		return nil, errors.New("quickfix export applies to text files")
+	}
+	if p.showFormatted {
+		return nil, errors.New("quickfix export uses the raw view (press f)")
+	}
+	if p.searchQuery == "" || len(p.searchHits) == 0 {
+		return nil, errors.New("no search hits to export (press /)")
+	}
+
+	path := p.filePath()
+	entries := make([]string, 0, len(p.searchHits))
+	for _, hit := range p.searchHits {
+		text := strings.TrimRight(stripANSICodes(p.lineAt(hit.line)), "\r")
+		col := byteOffsetForColumn(text, hit.span.start) + 1
+		entries = append(entries, fmt.Sprintf("%s:%d:%d:%s", path, hit.line+1, col, text))
+	}
+	return entries, nil
+}
+
+// exportQuickfix (o) writes the search hits to state.QuickfixFile, or keeps
+// them for stdout when it is "-". Printing is refused while Enter may print
+// paths to stdout too. It returns the status line to show.
+func (p *PreviewPager) exportQuickfix() (string, error) {
+	entries, err := p.quickfixEntries()
+	if err != nil {
+		return "", err
+	}
+	count := fmt.Sprintf("%d hits", len(entries))
+	if len(entries) == 1 {
+		count = "1 hit"
+	}
+	if p.searchLimited {
+		count = "first " + count
+	}
+
+	target := p.state.QuickfixFile
+	if target == "" {
+		target = statepkg.DefaultQuickfixFile(nil)
+	}
+	if target == statepkg.QuickfixStdout {
+		if p.state.Enter.PrintsPaths() {
+			return "", errors.New("RDIR_QUICKFIX=- shares stdout with the paths RDIR_ENTER=print hands back; set it to a file")
+		}
+		p.state.QuickfixOutput = entries
+		return fmt.Sprintf("%s will be printed on exit", count), nil
+	}
+	if err := statepkg.WriteQuickfixFile(target, entries); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("wrote %s to %s (vim -q)", count, target), nil
+}
+
+// byteOffsetForColumn returns the byte offset of the grapheme that starts at
+// display column col, or len(text) past the end.
+func byteOffsetForColumn(text string, col int) int {
+	width := 0
+	graphemes := uniseg.NewGraphemes(text)
+	for graphemes.Next() {
+		if width >= col {
+			start, _ := graphemes.Positions()
+			return start
+		}
+		width += textutil.DisplayWidth(graphemes.Str())
+	}
+	return len(text)
+}
@@ -0,0 +1,154 @@
+package pager
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
+	"github.com/rivo/uniseg"
+)
+
+// readingIndentPattern matches the indentation and list or quote marker that
+// continuation rows of a reflowed paragraph line up under.
+var readingIndentPattern = regexp.MustCompile(`^ *(?:(?:[-*+•◦▪▸│>]|\d+[.)]) +)?`)
+
+// readingLayout returns the text column and left margin of reading mode, or
+// ok=false when the formatted markdown view is laid out at terminal width.
+func (p *PreviewPager) readingLayout() (column, margin int, ok bool) {
+	if p.state == nil || !p.state.PreviewReadingMode || p.width <= 0 {
+		return 0, 0, false
+	}
+	if preview := p.state.PreviewData; preview == nil || preview.FormattedKind != "markdown" {
+		return 0, 0, false
+	}
+	column = p.state.ReadingWidth
+	if column <= 0 {
+		column = statepkg.DefaultReadingWidth
+	}
+	if column >= p.width {
+		return p.width, 0, true
+	}
+	return column, (p.width - column) / 2, true
+}
+
+// toggleReadingMode (r) switches the formatted markdown view between
+// terminal-width lines and prose reflowed to the reading column, keeping
+// roughly the same place in the document.
+func (p *PreviewPager) toggleReadingMode() {
+	if p.state == nil || p.binaryMode {
+		return
+	}
+	if preview := p.state.PreviewData; preview == nil || preview.FormattedKind != "markdown" || !p.showFormatted {
+		p.setStatusMessage("reading mode applies to formatted markdown (press f)", statusWarnStyle)
+		return
+	}
+	before := len(p.lines)
+	p.state.PreviewReadingMode = !p.state.PreviewReadingMode
+	p.reflowMarkdownFormatted()
+	if before > 0 && len(p.lines) > 0 {
+		p.state.PreviewScrollOffset = p.state.PreviewScrollOffset * len(p.lines) / before
+	}
+	p.state.PreviewWrapOffset = 0
+	p.rowMetricsWidth = 0
+	p.resetWrapCache()
+	if p.searchQuery != "" {
+		p.executeSearch(p.searchQuery)
+	}
+	p.refreshSplitPane(false)
+	if p.state.PreviewReadingMode {
+		column, _, _ := p.readingLayout()
+		p.setStatusMessage(fmt.Sprintf("reading mode: %d columns", column), "")
+	} else {
+		p.setStatusMessage("reading mode off", "")
+	}
+}
+
+// readingLines reflows formatted markdown lines to column and indents them by
+// margin. Rules are drawn at the column width rather than across the screen.
+func readingLines(lines []string, rules []bool, styles []string, column, margin int) []string {
+	pad := strings.Repeat(" ", margin)
+	out := make([]string, 0, len(lines))
+	for i, line := range lines {
+		if i < len(rules) && rules[i] {
+			style, reset := "", ""
+			if i < len(styles) && styles[i] != "" {
+				style, reset = styles[i], "\x1b[0m"
+			}
+			out = append(out, pad+style+strings.Repeat("─", column)+reset)
+			continue
+		}
+		if strings.TrimSpace(stripANSICodes(line)) == "" {
+			out = append(out, "")
+			continue
+		}
+		for _, row := range wrapWords(line, column) {
+			out = append(out, pad+row)
+		}
+	}
+	return out
+}
+
+// wrapWords breaks text into rows of at most width columns at spaces,
+// splitting a word only when it is longer than a row. Continuation rows are
+// indented under the line's list or quote marker and repeat the colors in
+// effect where they start.
+func wrapWords(text string, width int) []string {
+	if width <= 0 || ansiDisplayWidth(text) <= width {
+		return []string{text}
+	}
+	indent := displayWidth(readingIndentPattern.FindString(stripANSICodes(text)))
+	if indent > width/2 {
+		indent = 0
+	}
+
+	rows := []string{}
+	start := 0
+	limit := width
+	for start < len(text) {
+		consumed := 0
+		pos := start
+		breakAt, resumeAt := -1, -1
+		seenText := false
+		fits := true
+		sgrLeft := 0
+		g := uniseg.NewGraphemes(text[start:])
+		for g.Next() {
+			cluster := g.Str()
+			w := wrapClusterWidth(text[pos:], cluster, &sgrLeft)
+			space := cluster == " " && w == 1
+			if consumed+w > limit {
+				fits = false
+				switch {
+				case space || (breakAt < 0 && consumed > 0):
+					breakAt, resumeAt = pos, pos
+				case breakAt < 0:
+					breakAt, resumeAt = pos+len(cluster), pos+len(cluster)
+				}
+				break
+			}
+			if space && seenText {
+				breakAt, resumeAt = pos, pos+1
+			}
+			seenText = seenText || (w > 0 && !space)
+			consumed += w
+			pos += len(cluster)
+		}
+
+		prefix := ""
+		if start > 0 {
+			prefix = strings.Repeat(" ", indent) + sgrCarry(text[:start])
+		}
+		if fits {
+			rows = append(rows, prefix+text[start:])
+			break
+		}
+		rows = append(rows, prefix+strings.TrimRight(text[start:breakAt], " "))
+		for resumeAt < len(text) && text[resumeAt] == ' ' {
+			resumeAt++
+		}
+		start = resumeAt
+		limit = width - indent
+	}
+	return rows
+}
@@ -0,0 +1,143 @@
+package pager
+
+import (
+	"fmt"
+	"path/filepath"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+)
+
+// noteRecentFile adds the file shown to the session's recently viewed
+// files. Directory listings and privileged content cannot be reopened from
+// the list and are left out.
+func (p *PreviewPager) noteRecentFile() {
+	if p.state == nil || p.state.PreviewData == nil {
+		return
+	}
+	if preview := p.state.PreviewData; preview.IsDir || preview.Privileged != "" {
+		return
+	}
+	p.state.NotePagerFile(p.filePath())
+}
+
+// SwitchTo returns the file picked from the recent files list when that is
+// why Run returned; the caller opens a new pager on it.
+func (p *PreviewPager) SwitchTo() string {
+	return p.switchTo
+}
+
+// openRecent (v) lists the files viewed this session with the previous one
+// selected, so v Enter flips between two files.
+func (p *PreviewPager) openRecent() {
+	if p.state.PreviewData.Privileged != "" {
+		p.setStatusMessage("close this file first; it was read with the privileged helper", statusWarnStyle)
+		return
+	}
+	if len(p.state.PagerRecent) < 2 {
+		p.setStatusMessage("no other files viewed yet", "")
+		return
+	}
+	p.showRecent = true
+	p.recentCursor = 0
+	if p.state.PagerRecent[0] == filepath.Clean(p.filePath()) {
+		p.recentCursor = 1
+	}
+}
+
+func (p *PreviewPager) handleRecentKey(ev keyEvent) bool {
+	recent := p.state.PagerRecent
+	switch ev.kind {
+	case keyCtrlC:
+		return true
+	case keyRecentFiles, keyQuit, keyEscape, keyLeft:
+		p.showRecent = false
+	case keyUp:
+		p.recentCursor--
+	case keyDown:
+		p.recentCursor++
+	case keyHome:
+		p.recentCursor = 0
+	case keyEnd:
+		p.recentCursor = len(recent) - 1
+	case keyEnter, keyRight:
+		return p.switchToRecent(p.recentCursor)
+	case keyRune:
+		if ev.ch >= '1' && ev.ch <= '9' {
+			return p.switchToRecent(int(ev.ch - '1'))
+		}
+	}
+	p.recentCursor = min(max(p.recentCursor, 0), max(len(recent)-1, 0))
+	return false
+}
+
+// switchToRecent ends this pager session in favor of recent file idx. The
+// current file's view is remembered on the way out like on any exit.
+func (p *PreviewPager) switchToRecent(idx int) bool {
+	recent := p.state.PagerRecent
+	if idx < 0 || idx >= len(recent) {
+		return false
+	}
+	p.showRecent = false
+	path := recent[idx]
+	if path == filepath.Clean(p.filePath()) {
+		return false
+	}
+	if info, err := fsutil.OrLocal(p.state.FS).Stat(path); err != nil || info.IsDir() {
+		p.setStatusMessage(fmt.Sprintf("%s is gone", textutil.SanitizeTerminalText(filepath.Base(path))), statusWarnStyle)
+		return false
+	}
+	p.switchTo = path
+	return true
+}
+
+// recentLines numbers the recent files for the digit keys, showing the name
+// before its directory.
+func (p *PreviewPager) recentLines() []string {
+	current := filepath.Clean(p.filePath())
+	lines := make([]string, 0, len(p.state.PagerRecent))
+	for i, path := range p.state.PagerRecent {
+		marker := "  "
+		if i == p.recentCursor {
+			marker = "› "
+		}
+		line := fmt.Sprintf("%s%d  %s  %s", marker, i+1, textutil.SanitizeTerminalText(filepath.Base(path)), textutil.SanitizeTerminalText(filepath.Dir(path)))
+		if path == current {
+			line += "  (shown)"
+		}
+		if p.width > 0 {
+			line = truncateToWidth(line, p.width)
+		}
+		if i == p.recentCursor {
+			line = searchHighlightFocusOn + line + searchHighlightFocusOff
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (p *PreviewPager) renderRecent() error {
+	p.writeString("\x1b[?25l")
+	p.writeString("\x1b[2J")
+	p.writeString("\x1b[H")
+
+	p.drawStyledRow(1, fmt.Sprintf(" Recent files (%d) ", len(p.state.PagerRecent)), true, headerBarStyle)
+	row := 2
+	for _, line := range p.recentLines() {
+		if row >= p.height {
+			break
+		}
+		p.drawRow(row, line, false)
+		row++
+	}
+	for row < p.height {
+		p.drawRow(row, "", false)
+		row++
+	}
+	p.drawStatus("↑/↓ select  ·  Enter or 1-9 open  ·  q/Esc/v close")
+
+	if p.writer != nil {
+		return p.writer.Flush()
+	}
+	return nil
+}
@@ -2,7 +2,6 @@ package pager
 
 import (
 	"fmt"
-	"path/filepath"
 	"strings"
 
 	"github.com/gdamore/tcell/v2"
@@ -29,6 +28,15 @@ func (p *PreviewPager) render() error {
 	if p.showHelp {
 		return p.renderHelpOverlay()
 	}
+	if p.showHiddenList {
+		return p.renderHiddenList()
+	}
+	if p.showTOC {
+		return p.renderTOC()
+	}
+	if p.showRecent {
+		return p.renderRecent()
+	}
 
 	header := p.headerLines()
 	headerRows := len(header)
@@ -73,70 +81,29 @@ func (p *PreviewPager) render() error {
 	}
 
 	totalLines := p.lineCount()
-	p.clampScroll(totalLines, contentRows)
+	p.clampScroll(totalLines, p.focusedPaneRows(contentRows))
 
 	p.writeString("\x1b[?25l")
 	p.writeString("\x1b[2J")
 	p.writeString("\x1b[H")
 
 	row := 1
-	for _, line := range header {
+	for i, line := range header {
 		if row > p.height-1 {
 			break
 		}
-		p.drawStyledRow(row, line, false, headerBarStyle)
-		row++
-	}
-
-	start := p.state.PreviewScrollOffset
-	if start < 0 {
-		start = 0
-	}
-	if totalLines > 0 && start > totalLines {
-		start = totalLines
-		p.state.PreviewScrollOffset = start
-	}
-	skipRows := 0
-	if p.wrapEnabled {
-		skipRows = p.state.PreviewWrapOffset
-	}
-
-	for i := start; i < totalLines && row <= contentRowLimit; i++ {
-		text := p.lineAt(i)
-		if p.wrapEnabled && p.width > 0 {
-			currentSkip := skipRows
-			maxRows := contentRowLimit - row + 1
-			segments := p.wrapSegmentsRangeForLine(i, text, currentSkip, maxRows)
-			for segIdx, seg := range segments {
-				dropCols := (currentSkip + segIdx) * p.width
-				if spans, focus := p.visibleHighlights(i, dropCols, p.width); len(spans) > 0 {
-					seg = applySearchHighlights(seg, spans, focus)
-				}
-				p.drawRow(row, seg, false)
-				row++
-				if row > contentRowLimit {
-					break
-				}
-			}
-			skipRows = 0
-			continue
-		}
-
-		displayText := text
-		if p.width > 0 {
-			displayText = truncateToWidth(displayText, p.width)
-		}
-		if spans, focus := p.visibleHighlights(i, 0, p.width); len(spans) > 0 {
-			displayText = applySearchHighlights(displayText, spans, focus)
+		style := headerBarStyle
+		if i == 0 && p.state.PreviewData != nil && p.state.PreviewData.Privileged != "" {
+			style = privilegedHeaderStyle
 		}
-		p.drawRow(row, displayText, false)
+		p.drawStyledRow(row, line, false, style)
 		row++
-		skipRows = 0
 	}
 
-	for row <= contentRowLimit {
-		p.drawRow(row, "", false)
-		row++
+	if p.splitActive() {
+		p.drawSplitPanes(row, contentRowLimit)
+	} else {
+		p.drawPane(row, contentRowLimit, totalLines)
 	}
 
 	searchCursorRow := 0
@@ -192,6 +159,66 @@ func (p *PreviewPager) render() error {
 	return nil
 }
 
+// drawPane draws the content viewport (current scroll offsets and search
+// highlights) into rows firstRow..lastRow.
+func (p *PreviewPager) drawPane(firstRow, lastRow, totalLines int) {
+	row := firstRow
+	start := p.state.PreviewScrollOffset
+	if start < 0 {
+		start = 0
+	}
+	if totalLines > 0 && start > totalLines {
+		start = totalLines
+		p.state.PreviewScrollOffset = start
+	}
+	skipRows := 0
+	if p.wrapEnabled {
+		skipRows = p.state.PreviewWrapOffset
+	}
+
+	endLine := start
+	for i := start; i < totalLines && row <= lastRow; i++ {
+		endLine = i
+		text := p.lineAt(i)
+		if p.wrapEnabled && p.width > 0 {
+			currentSkip := skipRows
+			maxRows := lastRow - row + 1
+			segments := p.wrapSegmentsRangeForLine(i, text, currentSkip, maxRows)
+			for segIdx, seg := range segments {
+				dropCols := (currentSkip + segIdx) * p.width
+				seg = p.wrappedRowColors(text, seg, dropCols)
+				if spans, focus := p.lineHighlights(i, dropCols, p.width); len(spans) > 0 {
+					seg = applySearchHighlights(seg, spans, focus)
+				}
+				p.drawRow(row, seg, false)
+				row++
+				if row > lastRow {
+					break
+				}
+			}
+			skipRows = 0
+			continue
+		}
+
+		displayText := text
+		if p.width > 0 {
+			displayText = truncateToWidth(displayText, p.width)
+		}
+		if spans, focus := p.lineHighlights(i, 0, p.width); len(spans) > 0 {
+			displayText = applySearchHighlights(displayText, spans, focus)
+		}
+		p.drawRow(row, displayText, false)
+		row++
+		skipRows = 0
+	}
+
+	for row <= lastRow {
+		p.drawRow(row, "", false)
+		row++
+	}
+	p.drawScrollbar(firstRow, lastRow, start, endLine, totalLines)
+}
+
 func (p *PreviewPager) renderHelpOverlay() error {
 	p.writeString("\x1b[?25l")
 	p.writeString("\x1b[2J")
@@ -251,6 +278,9 @@ func (p *PreviewPager) drawRow(row int, text string, bold bool) {
 	}
 }
 
+// reflowMarkdownFormatted lays formatted markdown out for the current width.
+// The layout is kept while the width, wrapping and reading column stay the
+// same, so redraws that do not change them also keep the row metrics.
 func (p *PreviewPager) reflowMarkdownFormatted() {
 	if p.state == nil || p.state.PreviewData == nil {
 		return
@@ -263,7 +293,17 @@ func (p *PreviewPager) reflowMarkdownFormatted() {
 	if p.wrapEnabled {
 		maxLines = 0
 	}
-	segments, meta := statepkg.FormatMarkdownPreview(preview.TextLines, p.width, maxLines, p.wrapEnabled)
+	width, wrap := p.width, p.wrapEnabled
+	column, margin, reading := p.readingLayout()
+	if reading {
+		width, maxLines, wrap = column, 0, true
+	}
+	key := fmt.Sprintf("%d/%t/%d/%d/%t/%d", p.width, p.wrapEnabled, column, margin, reading, len(preview.TextLines))
+	if key == p.markdownKey {
+		return
+	}
+	p.markdownKey = key
+	segments, meta := statepkg.FormatMarkdownPreview(preview.TextLines, width, maxLines, wrap)
 	if len(segments) == 0 || len(meta) != len(segments) {
 		return
 	}
@@ -273,7 +313,7 @@ func (p *PreviewPager) reflowMarkdownFormatted() {
 	rules := make([]bool, len(segments))
 	styles := make([]string, len(segments))
 	for i, line := range segments {
-		txt, isRule, style := ansiFromSegments(line)
+		txt, isRule, style := ansiFromSegments(line, p.theme)
 		formatted[i] = txt
 		rules[i] = isRule
 		styles[i] = style
@@ -284,6 +324,15 @@ func (p *PreviewPager) reflowMarkdownFormatted() {
 		}
 	}
 
+	if reading {
+		formatted = readingLines(formatted, rules, styles, column, margin)
+		widths = make([]int, len(formatted))
+		for i, line := range formatted {
+			widths[i] = ansiDisplayWidth(line)
+		}
+		rules, styles = nil, nil
+	}
+
 	p.formattedLines = formatted
 	p.formattedWidths = widths
 	p.formattedRules = rules
@@ -309,7 +358,7 @@ func (p *PreviewPager) drawStyledRow(row int, text string, bold bool, style stri
 	p.writeString("\x1b[0m\x1b[2K")
 
 	if style != "" {
-		p.writeString(style)
+		p.writeString(p.contrastStyle(style))
 	}
 	if bold {
 		p.writeString("\x1b[1m")
@@ -321,7 +370,7 @@ func (p *PreviewPager) drawStyledRow(row int, text string, bold bool, style stri
 		available = 0
 	}
 
-	needsPadding := style == headerBarStyle
+	needsPadding := style == headerBarStyle || style == privilegedHeaderStyle
 	if needsPadding && available >= 2 {
 		bodyWidth := available - 2
 		clipped, truncated := clipTextToWidth(renderText, bodyWidth)
@@ -380,6 +429,9 @@ func (p *PreviewPager) statusLine(totalLines, visible, charCount int, search str
 		segments = append(segments, offset)
 	}
 	segments = append(segments, p.statusBadges(kind)...)
+	if inspect := p.inspectSegment(); inspect != "" {
+		segments = append([]string{inspect}, segments...)
+	}
 	if search != "" {
 		segments = append([]string{search}, segments...)
 	}
@@ -521,11 +573,20 @@ func (p *PreviewPager) statusBadges(kind pagerContentKind) []string {
 		mode := "raw"
 		if formattedAvailable && p.showFormatted {
 			mode = "pretty"
+			if preview != nil && len(preview.FormattedANSILines) > 0 {
+				mode = preview.FormattedKind
+			}
 		} else if formattedReason && !formattedAvailable {
 			mode = "raw*"
 		}
 		badges = append(badges, "fmt:"+mode)
 	}
+	if _, _, reading := p.readingLayout(); reading && p.showFormatted {
+		badges = append(badges, "read:on")
+	}
+	if p.ansiColors && !p.binaryMode {
+		badges = append(badges, "ansi:on")
+	}
 	if preview != nil && preview.HiddenFormattingDetected && !p.binaryMode {
 		badges = append(badges, "hidden:yes")
 	}
@@ -622,20 +683,41 @@ func (p *PreviewPager) helpSections() []helpSection {
 	view := []helpEntry{
 		{keys: "?", desc: "Toggle this help"},
 		{keys: "i", desc: "Toggle info line"},
+		{keys: "m", desc: "Toggle scrollbar (search hits as ticks)"},
+	}
+	if p.binaryMode {
+		view = append(view, helpEntry{keys: "d", desc: "Decode UTF-8 text in the ASCII column"})
+	}
+	if !p.binaryMode {
+		view = append(view, helpEntry{keys: "s / Tab", desc: "Split view / switch pane"})
+		view = append(view, helpEntry{keys: "u", desc: "Inspect characters (arrows move, Esc leaves)"})
+		view = append(view, helpEntry{keys: "z / Z", desc: "Next hidden formatting character / list all"})
 	}
 	if !p.binaryMode {
 		view = append(view, helpEntry{keys: "w or →", desc: "Toggle wrap"})
+		view = append(view, helpEntry{keys: "a", desc: "Toggle ANSI colors from the file"})
 	}
 	if len(p.formattedLines) > 0 {
 		view = append(view, helpEntry{keys: "f", desc: "Toggle formatted view"})
 	}
+	if p.state != nil && p.state.PreviewData != nil && p.state.PreviewData.FormattedKind == "markdown" {
+		view = append(view, helpEntry{keys: "r", desc: "Reading mode (reflow prose to a narrow column)"})
+	}
+	if p.tocSource() != "" {
+		view = append(view, helpEntry{keys: "t", desc: "Table of contents (headings / top-level symbols)"})
+	}
+	view = append(view, helpEntry{keys: "v", desc: "Recent files: switch to another file viewed this session"})
 
 	actions := []helpEntry{}
 	if p.clipboardAvailable() {
 		actions = append(actions,
 			helpEntry{keys: "c", desc: "Copy visible lines"},
 			helpEntry{keys: "C", desc: "Copy entire file (raw)"},
+			helpEntry{keys: "P", desc: "Copy entire file via a temp file (copies its path)"},
 		)
+		if !p.binaryMode {
+			actions = append(actions, helpEntry{keys: "y", desc: "Copy path:line of the current line / search hit"})
+		}
 	}
 	if p.canOpenEditor() {
 		actions = append(actions, helpEntry{keys: "e", desc: "Open in editor"})
@@ -650,6 +732,9 @@ func (p *PreviewPager) helpSections() []helpSection {
 		{keys: "/", desc: "Enter search"},
 		{keys: "n / N", desc: "Jump to next/prev hit"},
 	}
+	if !p.binaryMode {
+		search = append(search, helpEntry{keys: "o", desc: "Export hits as path:line:col:text (vim -q)"})
+	}
 	if p.binaryMode {
 		search = append(search, helpEntry{keys: ":", desc: "Enter binary search"})
 		search = append(search, helpEntry{keys: "Ctrl+B", desc: "Toggle text/hex mode while searching"})
@@ -689,9 +774,16 @@ func (p *PreviewPager) headerLines() []string {
 		return []string{"(no preview available)"}
 	}
 	preview := p.state.PreviewData
-	fullPath := filepath.Join(p.state.CurrentPath, preview.Name)
+	fullPath := p.filePath()
 
-	lines := []string{textutil.SanitizeTerminalText(fullPath)}
+	title := textutil.SanitizeTerminalText(fullPath)
+	if preview.Privileged != "" {
+		title = "⚠ read with " + textutil.SanitizeTerminalText(preview.Privileged) + ": " + title
+	}
+	if crumb := p.tocBreadcrumb(); crumb != "" {
+		title += "  §  " + textutil.SanitizeTerminalText(crumb)
+	}
+	lines := []string{title}
 	if p.showInfo {
 		if info := p.infoLine(preview); info != "" {
 			lines = append(lines, info)
@@ -712,7 +804,7 @@ func (p *PreviewPager) infoSegments(preview *statepkg.PreviewData) []string {
 	if preview == nil {
 		return nil
 	}
-	meta := fmt.Sprintf("%s  %s  %s", preview.Mode.String(), formatSize(preview.Size), preview.Modified.Format("2006-01-02 15:04:05"))
+	meta := fmt.Sprintf("%s  %s  %s", preview.Mode.String(), textutil.FormatSize(preview.Size), preview.Modified.Format("2006-01-02 15:04:05"))
 	segments := []string{meta}
 	segments = append(segments, p.detailInfoSegments(preview)...)
 	out := make([]string, 0, len(segments))
@@ -759,6 +851,12 @@ func (p *PreviewPager) detailInfoSegments(preview *statepkg.PreviewData) []strin
 		if enc := formatEncodingLabel(preview.TextEncoding); enc != "" {
 			segments = append(segments, "encoding:"+enc)
 		}
+		if eol := preview.LineEndings.String(); eol != "" {
+			segments = append(segments, "eol:"+eol)
+		}
+		if preview.Compressed != nil {
+			segments = append(segments, p.compressedSegment(preview))
+		}
 		if p.rawTextSource != nil {
 			if !p.rawTextSource.FullyLoaded() {
 				segments = append(segments, "streaming from disk")
@@ -772,10 +870,35 @@ func (p *PreviewPager) detailInfoSegments(preview *statepkg.PreviewData) []strin
 		if preview.FormattedUnavailableReason != "" {
 			segments = append(segments, preview.FormattedUnavailableReason)
 		}
+		if preview.FormatterStatus != "" {
+			segments = append(segments, preview.FormatterStatus)
+		}
+	}
+	for _, stream := range preview.AltStreams {
+		segments = append(segments, fmt.Sprintf("%s %s", stream.Label(), textutil.FormatSize(stream.Size)))
 	}
 	return segments
 }
 
+// compressedSegment names the compression with the compressed and the
+// uncompressed size, noting when only the first compressedPagerLimit bytes
+// of the content are shown.
+func (p *PreviewPager) compressedSegment(preview *statepkg.PreviewData) string {
+	c := preview.Compressed
+	uncompressed := "?"
+	switch src := p.rawTextSource; {
+	case c.Size >= 0:
+		uncompressed = textutil.FormatSize(c.Size)
+	case src != nil && src.FullyLoaded() && !src.truncatedHead():
+		uncompressed = textutil.FormatSize(src.nextOffset)
+	}
+	segment := fmt.Sprintf("%s %s → %s", c.Format, textutil.FormatSize(preview.Size), uncompressed)
+	if src := p.rawTextSource; src != nil && src.FullyLoaded() && src.truncatedHead() {
+		segment += ", first " + textutil.FormatSize(compressedPagerLimit) + " shown"
+	}
+	return segment
+}
+
 func formatEncodingLabel(enc fsutil.UnicodeEncoding) string {
 	switch enc {
 	case fsutil.EncodingUnknown:
@@ -813,7 +936,7 @@ func displayWidth(text string) int {
 	return ansiDisplayWidth(text)
 }
 
-func ansiFromSegments(segments []statepkg.StyledTextSegment) (string, bool, string) {
+func ansiFromSegments(segments []statepkg.StyledTextSegment, theme renderpkg.ColorTheme) (string, bool, string) {
 	if len(segments) == 0 {
 		return "", false, ""
 	}
@@ -833,7 +956,7 @@ func ansiFromSegments(segments []statepkg.StyledTextSegment) (string, bool, stri
 		if text == "" {
 			continue
 		}
-		code := ansiForStyle(seg.Style)
+		code := ansiForStyle(seg.Style, theme)
 		if code != "" {
 			b.WriteString(code)
 			if styleCode == "" {
@@ -848,7 +971,7 @@ func ansiFromSegments(segments []statepkg.StyledTextSegment) (string, bool, stri
 	return b.String(), rule, styleCode
 }
 
-func ansiForStyle(kind statepkg.TextStyleKind) string {
+func ansiForStyle(kind statepkg.TextStyleKind, theme renderpkg.ColorTheme) string {
 	switch kind {
 	case statepkg.TextStyleStrong, statepkg.TextStyleHeading:
 		return "\x1b[1m"
@@ -857,9 +980,9 @@ func ansiForStyle(kind statepkg.TextStyleKind) string {
 	case statepkg.TextStyleStrike:
 		return "\x1b[9m"
 	case statepkg.TextStyleCode:
-		return ansiColorSequence(pagerTheme.CodeFg, pagerTheme.CodeBg)
+		return ansiColorSequence(theme.CodeFg, theme.CodeBg)
 	case statepkg.TextStyleCodeBlock:
-		return ansiColorSequence(pagerTheme.CodeBlockFg, pagerTheme.CodeBlockBg)
+		return ansiColorSequence(theme.CodeBlockFg, theme.CodeBlockBg)
 	case statepkg.TextStyleLink:
 		return "\x1b[4m"
 	case statepkg.TextStyleRule:
@@ -869,8 +992,6 @@ func ansiForStyle(kind statepkg.TextStyleKind) string {
 	}
 }
 
-var pagerTheme = renderpkg.GetColorTheme()
-
 func ansiColorSequence(fg, bg tcell.Color) string {
 	if fg == tcell.ColorDefault && bg == tcell.ColorDefault {
 		return ""
@@ -0,0 +1,152 @@
+package pager
+
+const (
+	// scrollbarMinWidth is the narrowest terminal that still gives up a
+	// column for the scrollbar.
+	scrollbarMinWidth = 20
+
+	scrollbarTrackStyle    = "\x1b[38;5;240m"
+	scrollbarThumbStyle    = "\x1b[38;5;250m"
+	scrollbarHitStyle      = "\x1b[38;5;220m"
+	scrollbarUnloadedStyle = "\x1b[38;5;237m"
+)
+
+type scrollbarCell int
+
+const (
+	scrollbarTrack scrollbarCell = iota
+	scrollbarThumb
+	scrollbarHit
+	scrollbarThumbHit
+	scrollbarUnloaded
+)
+
+// scrollbarColumns reports how many terminal columns the scrollbar takes
+// from a terminal of the given width.
+func (p *PreviewPager) scrollbarColumns(termWidth int) int {
+	if p.state != nil && p.state.PreviewHideScrollbar {
+		return 0
+	}
+	if termWidth < scrollbarMinWidth {
+		return 0
+	}
+	return 1
+}
+
+func (p *PreviewPager) toggleScrollbar() {
+	if p.state == nil {
+		return
+	}
+	p.state.PreviewHideScrollbar = !p.state.PreviewHideScrollbar
+	p.updateSize()
+}
+
+// scrollFraction maps a line to its position in the file (0..1). Streamed
+// text uses byte offsets against the file size, so the bar stays truthful
+// while the line count is still growing; everything else, including
+// decompressed text whose size is not the file's, is line-based.
+func (p *PreviewPager) scrollFraction(line, totalLines int) float64 {
+	if src := p.streamingSource(); src != nil {
+		size := p.state.PreviewData.Size
+		if line >= len(src.lines) {
+			return float64(src.nextOffset) / float64(size)
+		}
+		if line < 0 {
+			line = 0
+		}
+		return float64(src.lines[line].offset) / float64(size)
+	}
+	if totalLines <= 0 {
+		return 0
+	}
+	return float64(line) / float64(totalLines)
+}
+
+// loadedFraction is the part of the file the bar can describe; the rest is
+// drawn as not yet loaded.
+func (p *PreviewPager) loadedFraction() float64 {
+	src := p.streamingSource()
+	if src == nil || src.FullyLoaded() {
+		return 1
+	}
+	return float64(src.nextOffset) / float64(p.state.PreviewData.Size)
+}
+
+func (p *PreviewPager) streamingSource() *textPagerSource {
+	if p.showFormatted || p.rawTextSource == nil || p.rawTextSource.generate != nil || p.state == nil || p.state.PreviewData == nil || p.state.PreviewData.Size <= 0 || p.state.PreviewData.Compressed != nil {
+		return nil
+	}
+	return p.rawTextSource
+}
+
+// scrollbarCells lays out a bar of rows cells for the visible lines
+// [start, end] (inclusive) out of totalLines, with tick marks for search hits.
+func (p *PreviewPager) scrollbarCells(rows, start, end, totalLines int) []scrollbarCell {
+	if rows <= 0 {
+		return nil
+	}
+	cells := make([]scrollbarCell, rows)
+	cellOf := func(fraction float64) int {
+		idx := int(fraction * float64(rows))
+		if idx >= rows {
+			idx = rows - 1
+		}
+		if idx < 0 {
+			idx = 0
+		}
+		return idx
+	}
+
+	loaded := p.loadedFraction()
+	for i := range cells {
+		if float64(i)/float64(rows) >= loaded {
+			cells[i] = scrollbarUnloaded
+		}
+	}
+
+	thumbStart := cellOf(p.scrollFraction(start, totalLines))
+	thumbEnd := cellOf(p.scrollFraction(end+1, totalLines) - 1e-9)
+	if end+1 >= totalLines && loaded >= 1 {
+		thumbEnd = rows - 1
+	}
+	if thumbEnd < thumbStart {
+		thumbEnd = thumbStart
+	}
+	for i := thumbStart; i <= thumbEnd; i++ {
+		cells[i] = scrollbarThumb
+	}
+
+	for _, hit := range p.searchHits {
+		idx := cellOf(p.scrollFraction(hit.line, totalLines))
+		if cells[idx] == scrollbarThumb || cells[idx] == scrollbarThumbHit {
+			cells[idx] = scrollbarThumbHit
+		} else {
+			cells[idx] = scrollbarHit
+		}
+	}
+	return cells
+}
+
+// drawScrollbar paints the bar in the column reserved right of the content.
+func (p *PreviewPager) drawScrollbar(firstRow, lastRow, start, end, totalLines int) {
+	if p.termWidth <= p.width || p.binaryMode && p.binarySource == nil {
+		return
+	}
+	rows := lastRow - firstRow + 1
+	col := p.width + 1
+	for i, cell := range p.scrollbarCells(rows, start, end, totalLines) {
+		style, glyph := scrollbarTrackStyle, "│"
+		switch cell {
+		case scrollbarThumb:
+			style, glyph = scrollbarThumbStyle, "┃"
+		case scrollbarHit:
+			style, glyph = scrollbarHitStyle, "•"
+		case scrollbarThumbHit:
+			style, glyph = scrollbarHitStyle, "◆"
+		case scrollbarUnloaded:
+			style, glyph = scrollbarUnloadedStyle, "┊"
+		}
+		p.printf("\x1b[%d;%dH", firstRow+i, col)
+		p.writeString(p.contrastStyle(style) + glyph + "\x1b[0m")
+	}
+}
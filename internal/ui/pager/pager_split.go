@@ -0,0 +1,181 @@
+package pager
+
+import "fmt"
+
+// splitMinPaneRows is the smallest pane the split view will draw; on shorter
+// terminals the split is kept but only the focused pane is shown.
+const splitMinPaneRows = 3
+
+// pagerPane is the per-viewport state that differs between split panes.
+type pagerPane struct {
+	scroll              int
+	wrapOffset          int
+	searchQuery         string
+	searchHits          []searchHit
+	searchCursor        int
+	searchHighlights    map[int][]textSpan
+	searchLimited       bool
+	searchErr           error
+	searchFocused       bool
+	searchQueryBinary   bool
+	searchQueryFullScan bool
+}
+
+// pagerSplit holds the pane that is not focused; the focused pane lives in
+// the regular pager/AppState fields so every command keeps working on it.
+type pagerSplit struct {
+	other    pagerPane
+	focusTop bool
+}
+
+func (p *PreviewPager) toggleSplit() {
+	if p.split != nil {
+		p.split = nil
+		return
+	}
+	if p.binaryMode {
+		p.setStatusMessage("split view is not available for binary files", statusWarnStyle)
+		return
+	}
+	p.split = &pagerSplit{
+		other: pagerPane{
+			scroll:       p.state.PreviewScrollOffset,
+			wrapOffset:   p.state.PreviewWrapOffset,
+			searchCursor: -1,
+		},
+		focusTop: true,
+	}
+}
+
+// switchSplitFocus moves the focus to the other pane.
+func (p *PreviewPager) switchSplitFocus() {
+	if p.split == nil {
+		return
+	}
+	p.swapPane()
+	p.split.focusTop = !p.split.focusTop
+}
+
+// swapPane exchanges the focused pane state with the stored one.
+func (p *PreviewPager) swapPane() {
+	o := &p.split.other
+	o.scroll, p.state.PreviewScrollOffset = p.state.PreviewScrollOffset, o.scroll
+	o.wrapOffset, p.state.PreviewWrapOffset = p.state.PreviewWrapOffset, o.wrapOffset
+	o.searchQuery, p.searchQuery = p.searchQuery, o.searchQuery
+	o.searchHits, p.searchHits = p.searchHits, o.searchHits
+	o.searchCursor, p.searchCursor = p.searchCursor, o.searchCursor
+	o.searchHighlights, p.searchHighlights = p.searchHighlights, o.searchHighlights
+	o.searchLimited, p.searchLimited = p.searchLimited, o.searchLimited
+	o.searchErr, p.searchErr = p.searchErr, o.searchErr
+	o.searchFocused, p.searchFocused = p.searchFocused, o.searchFocused
+	o.searchQueryBinary, p.searchQueryBinary = p.searchQueryBinary, o.searchQueryBinary
+	o.searchQueryFullScan, p.searchQueryFullScan = p.searchQueryFullScan, o.searchQueryFullScan
+}
+
+// refreshSplitPane brings the unfocused pane in line after the content
+// changed under it (wrap, formatted/raw or ANSI toggles).
+func (p *PreviewPager) refreshSplitPane(resetScroll bool) {
+	if p.split == nil {
+		return
+	}
+	p.swapPane()
+	p.state.PreviewWrapOffset = 0
+	if resetScroll {
+		p.state.PreviewScrollOffset = 0
+	}
+	if p.searchQuery != "" {
+		p.executeSearch(p.searchQuery)
+	}
+	p.swapPane()
+}
+
+// splitAvailableRows is the number of content rows shared by both panes and
+// the divider, ignoring the search prompt row.
+func (p *PreviewPager) splitAvailableRows() int {
+	headerRows := len(p.headerLines())
+	if headerRows >= p.height {
+		headerRows = p.height - 1
+		if headerRows < 0 {
+			headerRows = 0
+		}
+	}
+	return p.height - headerRows - 1
+}
+
+func (p *PreviewPager) splitActive() bool {
+	return p.split != nil && !p.binaryMode && p.splitAvailableRows() >= 2*splitMinPaneRows+1
+}
+
+// splitTopRows is the height of the top pane; the divider follows it.
+func (p *PreviewPager) splitTopRows() int {
+	return (p.splitAvailableRows() - 1) / 2
+}
+
+// splitRows reports how many rows the unfocused pane and the divider take
+// away from the focused pane.
+func (p *PreviewPager) splitRows() int {
+	if !p.splitActive() {
+		return 0
+	}
+	top := p.splitTopRows()
+	if p.split.focusTop {
+		return p.splitAvailableRows() - top
+	}
+	return top + 1
+}
+
+// viewHeight is the terminal height as seen by the focused pane.
+func (p *PreviewPager) viewHeight() int {
+	return p.height - p.splitRows()
+}
+
+// focusedPaneRows converts the rows available to all content (after the
+// search row is taken) into the rows of the focused pane. The search row is
+// always taken from the bottom pane.
+func (p *PreviewPager) focusedPaneRows(contentRows int) int {
+	if !p.splitActive() {
+		return contentRows
+	}
+	top := p.splitTopRows()
+	if p.split.focusTop {
+		return top
+	}
+	return contentRows - top - 1
+}
+
+// drawSplitPanes draws both panes and the divider between them into rows
+// firstRow..lastRow.
+func (p *PreviewPager) drawSplitPanes(firstRow, lastRow int) {
+	topLast := firstRow + p.splitTopRows() - 1
+	divider := topLast + 1
+
+	draw := func(first, last int) {
+		rows := last - first + 1
+		if !p.showFormatted && p.rawTextSource != nil {
+			p.preloadLines = p.state.PreviewScrollOffset + rows + 2
+		}
+		total := p.lineCount()
+		p.clampScroll(total, rows)
+		p.drawPane(first, last, total)
+	}
+	if p.split.focusTop {
+		draw(firstRow, topLast)
+		p.swapPane()
+		draw(divider+1, lastRow)
+		p.swapPane()
+	} else {
+		p.swapPane()
+		draw(firstRow, topLast)
+		p.swapPane()
+		draw(divider+1, lastRow)
+	}
+	p.drawStyledRow(divider, p.splitDividerLabel(), false, headerBarStyle)
+}
+
+func (p *PreviewPager) splitDividerLabel() string {
+	active := "▼ bottom"
+	if p.split.focusTop {
+		active = "▲ top"
+	}
+	return fmt.Sprintf(" %s pane active · Tab switch · s close", active)
+}
@@ -7,12 +7,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
 
 	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+	"github.com/kk-code-lab/rdir/internal/lineedit"
 	statepkg "github.com/kk-code-lab/rdir/internal/state"
+	"github.com/kk-code-lab/rdir/internal/testutil"
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
 )
 
 func TestTrimWrappedPrefix(t *testing.T) {
@@ -95,7 +99,7 @@ func TestBinaryPagerSourceReadsChunks(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
-			source, err := newBinaryPagerSource(path, int64(len(data)), tc.width)
+			source, err := newBinaryPagerSource(nil, path, nil, int64(len(data)), tc.width)
 			if err != nil {
 				t.Fatalf("newBinaryPagerSource: %v", err)
 			}
@@ -122,6 +126,81 @@ func TestBinaryPagerSourceReadsChunks(t *testing.T) {
 	}
 }
 
+func TestBinaryChunkPlanAdaptsToFileAndView(t *testing.T) {
+	small, smallChunks := binaryChunkPlan(1000, 16, 40)
+	if small < 1000 || small > 1016 || smallChunks != 1 {
+		t.Fatalf("small file: chunk=%d chunks=%d, want one chunk covering the file", small, smallChunks)
+	}
+
+	medium, _ := binaryChunkPlan(4<<20, 16, 0)
+	large, _ := binaryChunkPlan(64<<20, 16, 0)
+	huge, hugeChunks := binaryChunkPlan(1<<30, 16, 0)
+	if !(medium < large && large < huge) {
+		t.Fatalf("expected chunks to grow with file size, got %d, %d, %d", medium, large, huge)
+	}
+	if hugeChunks*huge > binaryPagerCacheBudget {
+		t.Fatalf("cache of %d×%d exceeds budget", hugeChunks, huge)
+	}
+
+	tall, _ := binaryChunkPlan(4<<20, 32, 300)
+	if tall < binaryChunkScreens*300*32 {
+		t.Fatalf("chunk %d does not span %d screens of 300 rows", tall, binaryChunkScreens)
+	}
+	if tall%32 != 0 {
+		t.Fatalf("chunk %d not aligned to 32-byte lines", tall)
+	}
+}
+
+func TestBinaryPagerSourceReadsAheadSequentially(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.bin")
+	data := make([]byte, 8*binaryPagerChunkSize)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write test file: %v", err)
+	}
+
+	source, err := newBinaryPagerSource(nil, path, nil, int64(len(data)), 80)
+	if err != nil {
+		t.Fatalf("newBinaryPagerSource: %v", err)
+	}
+	defer source.Close()
+	source.SetViewRows(20)
+
+	perChunk := source.linesPerChunk()
+	source.Line(0)
+	source.Line(perChunk)
+	for _, idx := range []int{2, 3} {
+		if _, ok := source.readahead[idx]; !ok {
+			t.Fatalf("expected readahead of chunk %d after moving to chunk 1, have %v", idx, source.readahead)
+		}
+	}
+
+	line := source.Line(2 * perChunk)
+	want := fmt.Sprintf("%08X", 2*source.chunkSize)
+	if !strings.HasPrefix(line, want) {
+		t.Fatalf("readahead chunk line = %q, want prefix %s", line, want)
+	}
+	if _, ok := source.readahead[2]; ok {
+		t.Fatalf("expected consumed readahead to move into the cache")
+	}
+	if _, ok := source.cache[2]; !ok {
+		t.Fatalf("expected chunk 2 in cache")
+	}
+
+	// Jumping away does not read ahead, and a width change drops pending reads.
+	source.Line(7 * perChunk)
+	if _, ok := source.readahead[6]; ok {
+		t.Fatalf("unexpected readahead after a jump")
+	}
+	source.UpdateBytesPerLine(120)
+	if len(source.readahead) != 0 || len(source.cache) != 0 {
+		t.Fatalf("expected width change to reset cache and readahead")
+	}
+}
+
 func TestUpdateSizeFallsBackToOutputFd(t *testing.T) {
 	original := termGetSize
 	t.Cleanup(func() {
@@ -153,8 +232,11 @@ func TestUpdateSizeFallsBackToOutputFd(t *testing.T) {
 	}
 	p.updateSize()
 
-	if p.width != 80 || p.height != 25 {
-		t.Fatalf("expected fallback size 80x25, got %dx%d", p.width, p.height)
+	if p.termWidth != 80 || p.height != 25 {
+		t.Fatalf("expected fallback size 80x25, got %dx%d", p.termWidth, p.height)
+	}
+	if p.width != 79 {
+		t.Fatalf("expected one column reserved for the scrollbar, got width %d", p.width)
 	}
 	if len(seen) < 2 {
 		t.Fatalf("expected both descriptors to be attempted, got %v", seen)
@@ -184,7 +266,7 @@ func TestBinarySearchRecomputesHighlightsAfterBytesPerLineChange(t *testing.T) {
 		},
 	}
 
-	source, err := newBinaryPagerSource(path, int64(len(data)), 90) // 16 bytes/line
+	source, err := newBinaryPagerSource(nil, path, nil, int64(len(data)), 90) // 16 bytes/line
 	if err != nil {
 		t.Fatalf("newBinaryPagerSource: %v", err)
 	}
@@ -278,7 +360,7 @@ func TestBinaryPagerExitRefreshesInlineBinaryLinesWindow(t *testing.T) {
 		},
 	}
 
-	source, err := newBinaryPagerSource(path, int64(len(data)), 120)
+	source, err := newBinaryPagerSource(nil, path, nil, int64(len(data)), 120)
 	if err != nil {
 		t.Fatalf("newBinaryPagerSource: %v", err)
 	}
@@ -321,7 +403,7 @@ func TestTextPagerSourceStreamsLines(t *testing.T) {
 		TextTruncated: true,
 		TextBytesRead: 0,
 	}
-	source, err := newTextPagerSource(path, preview)
+	source, err := newTextPagerSource(nil, path, preview)
 	if err != nil {
 		t.Fatalf("newTextPagerSource: %v", err)
 	}
@@ -350,6 +432,115 @@ func TestTextPagerSourceStreamsLines(t *testing.T) {
 	}
 }
 
+func TestTextPagerSourceStreamsDecompressedText(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log.gz")
+	var builder strings.Builder
+	for i := 0; i < 300; i++ {
+		fmt.Fprintf(&builder, "line-%d\n", i)
+	}
+	content := builder.String()
+	testutil.WriteGzip(t, path, []byte(content))
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	preview := &statepkg.PreviewData{
+		Name:          filepath.Base(path),
+		Size:          info.Size(),
+		TextLines:     []string{"line-0"},
+		TextLineMeta:  []statepkg.TextLineMetadata{{Offset: 0, Length: 6, RuneCount: 6, DisplayWidth: 6}},
+		TextBytesRead: 7,
+		TextTruncated: true,
+		Compressed:    &fsutil.Compressed{Format: "gzip", Size: -1},
+	}
+	state := &statepkg.AppState{CurrentPath: filepath.Dir(path), PreviewData: preview}
+	p := &PreviewPager{state: state, width: 80, height: 20}
+	p.prepareContent()
+	if p.rawTextSource == nil {
+		t.Fatalf("expected a streaming text source")
+	}
+	defer p.rawTextSource.Close()
+
+	// Search reads on through the decompressed stream.
+	p.executeSearch("line-250")
+	if len(p.searchHits) != 1 || p.searchHits[0].line != 250 {
+		t.Fatalf("hits = %+v", p.searchHits)
+	}
+	if got := p.compressedSegment(preview); got != fmt.Sprintf("gzip %s → %s", textutil.FormatSize(preview.Size), textutil.FormatSize(int64(len(content)))) {
+		t.Fatalf("segment = %q", got)
+	}
+	if _, ok := p.watchedFile(); ok || p.streamingSource() != nil {
+		t.Fatalf("expected decompressed text to be neither watched nor mapped by file offset")
+	}
+}
+
+func TestTextPagerSourceReadsPrivilegedContent(t *testing.T) {
+	// The file on disk stands in for one rdir cannot open: the content
+	// comes from the helper's reader only.
+	path := filepath.Join(t.TempDir(), "secret.conf")
+	if err := os.WriteFile(path, []byte(strings.Repeat("-", 400)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var builder strings.Builder
+	for i := 0; i < 40; i++ {
+		fmt.Fprintf(&builder, "key%d=value\n", i)
+	}
+	content := []byte(builder.String())
+	preview, err := statepkg.PrivilegedPreview(path, content[:100], bytes.NewReader(content), "sudo cat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !preview.TextTruncated || preview.Privileged != "sudo cat" {
+		t.Fatalf("preview = %+v", preview)
+	}
+
+	state := &statepkg.AppState{CurrentPath: filepath.Dir(path), PreviewData: preview, EditorAvailable: true}
+	p := &PreviewPager{state: state, width: 80, height: 20, editorCmd: []string{"vi"}}
+	p.prepareContent()
+	if p.rawTextSource == nil {
+		t.Fatalf("expected a streaming text source")
+	}
+	defer p.rawTextSource.Close()
+	if err := p.rawTextSource.EnsureAll(); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.rawTextSource.LineCount(); got != 40 {
+		t.Fatalf("line count = %d, want 40", got)
+	}
+	if line := p.rawTextSource.Line(39); line != "key39=value" {
+		t.Fatalf("line 39 = %q", line)
+	}
+	if header := p.headerLines()[0]; !strings.HasPrefix(header, "⚠ read with sudo cat: ") {
+		t.Fatalf("header = %q", header)
+	}
+	if _, ok := p.watchedFile(); ok || p.canOpenEditor() {
+		t.Fatalf("expected privileged content to be neither watched nor editable")
+	}
+}
+
+func TestBinaryPagerSourceReadsPrivilegedContent(t *testing.T) {
+	data := make([]byte, 4096)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	// Nothing exists at the path; every chunk comes from the reader.
+	path := filepath.Join(t.TempDir(), "missing.bin")
+	source, err := newBinaryPagerSource(nil, path, bytes.NewReader(data), int64(len(data)), 80)
+	if err != nil {
+		t.Fatalf("newBinaryPagerSource: %v", err)
+	}
+	source.SetViewRows(20)
+	last := source.Line(source.LineCount() - 1)
+	if !strings.Contains(last, "ff") && !strings.Contains(last, "FF") {
+		t.Fatalf("last line = %q", last)
+	}
+	source.Close()
+	if _, err := source.loadChunk(0); err != nil {
+		t.Fatalf("reloading from the reader after Close: %v", err)
+	}
+}
+
 func TestCleanupTerminalRestoresCursorAndWrap(t *testing.T) {
 	var buf bytes.Buffer
 	p := &PreviewPager{
@@ -366,6 +557,23 @@ func TestCleanupTerminalRestoresCursorAndWrap(t *testing.T) {
 	}
 }
 
+func TestCleanupTerminalLeavesAltScreenWhenEnabled(t *testing.T) {
+	var buf bytes.Buffer
+	p := &PreviewPager{
+		writer: bufio.NewWriter(&buf),
+		output: &buf,
+	}
+	p.SetAltScreen(true)
+
+	p.cleanupTerminal()
+
+	written := buf.String()
+	expected := altScreenExit + "\x1b[?25h\x1b[?7h"
+	if written != expected {
+		t.Fatalf("expected %q, got %q", expected, written)
+	}
+}
+
 func TestAnsiDisplayWidthIgnoresANSIAndCountsEmoji(t *testing.T) {
 	text := "\x1b[31m⚠️foo\x1b[0m"
 	if got := ansiDisplayWidth(text); got != 5 {
@@ -507,6 +715,32 @@ func TestPreviewPagerToggleFormatSwitchesViews(t *testing.T) {
 	}
 }
 
+func TestPreviewPagerAppliesExtensionDefaults(t *testing.T) {
+	preview := &statepkg.PreviewData{
+		Name:               "data.json",
+		TextLines:          []string{`{"a":1}`},
+		FormattedTextLines: []string{"{", `  "a": 1`, "}"},
+	}
+	defaults, _ := statepkg.LoadPreviewDefaults(func(string) string { return "json=raw+wrap" })
+	state := &statepkg.AppState{PreviewData: preview, CurrentPath: ".", PreviewDefaults: defaults}
+	pager, err := NewPreviewPager(state, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	if pager.showFormatted || !pager.wrapEnabled {
+		t.Fatalf("expected json to open raw and wrapped, formatted=%v wrap=%v", pager.showFormatted, pager.wrapEnabled)
+	}
+
+	pager.handleKey(keyEvent{kind: keyToggleFormat})
+	pager.handleKey(keyEvent{kind: keyToggleWrap})
+	if state.PreviewPreferRaw || state.PreviewWrap {
+		t.Fatalf("toggles on a configured extension should not change the global preferences")
+	}
+	if state.PreviewDefaults.RawFor("other.json", true) || state.PreviewDefaults.WrapFor("other.json", true) {
+		t.Fatalf("toggles should stick for json files in this session")
+	}
+}
+
 func TestPreviewPagerToggleWrapResetsWrapOffset(t *testing.T) {
 	preview := &statepkg.PreviewData{
 		Name:      "data.json",
@@ -616,6 +850,154 @@ func TestReadKeyEventPageKeys(t *testing.T) {
 	}
 }
 
+func TestReadKeyEventLineEditKeys(t *testing.T) {
+	t.Parallel()
+	cases := map[string]lineedit.Op{
+		"\x01":     lineedit.Home,
+		"\x05":     lineedit.End,
+		"\x17":     lineedit.DeleteWordBack,
+		"\x15":     lineedit.KillToStart,
+		"\x0b":     lineedit.KillToEnd,
+		"\x1b[3~":  lineedit.Delete,
+		"\x1bb":    lineedit.WordLeft,
+		"\x1bf":    lineedit.WordRight,
+		"\x1bd":    lineedit.DeleteWordForward,
+		"\x1b\x7f": lineedit.DeleteWordBack,
+	}
+	for input, want := range cases {
+		p := &PreviewPager{reader: bufio.NewReader(strings.NewReader(input))}
+		ev, err := p.readKeyEvent()
+		if err != nil {
+			t.Fatalf("readKeyEvent(%q): %v", input, err)
+		}
+		if ev.kind != keyLineEdit || ev.edit != want {
+			t.Fatalf("readKeyEvent(%q) = %+v, want line edit %q", input, ev, want)
+		}
+	}
+}
+
+func TestReadKeyEventUTF8(t *testing.T) {
+	t.Parallel()
+	p := &PreviewPager{reader: bufio.NewReader(strings.NewReader("é漢\xc3q\xa9j"))}
+	want := []keyEvent{
+		{kind: keyRune, ch: 'é'},
+		{kind: keyRune, ch: '漢'},
+		{kind: keyUnknown}, // cut-off sequence; q still arrives
+		{kind: keyQuit, ch: 'q'},
+		{kind: keyUnknown}, // stray continuation byte
+		{kind: keyDown, ch: 'j'},
+	}
+	for i, w := range want {
+		ev, err := p.readKeyEvent()
+		if err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+		if ev != w {
+			t.Fatalf("event %d: expected %+v, got %+v", i, w, ev)
+		}
+	}
+}
+
+func TestSearchComposesDeadKeyInput(t *testing.T) {
+	t.Parallel()
+	p := &PreviewPager{
+		state:      &statepkg.AppState{},
+		searchMode: true,
+		reader:     bufio.NewReader(strings.NewReader("e\u0301t\xc3")),
+	}
+	t.Cleanup(p.stopSearchTimer)
+	ev, err := p.readKeyEvent()
+	if err != nil {
+		t.Fatalf("readKeyEvent: %v", err)
+	}
+	p.handleSearchModeEvent(ev)
+	if got := string(p.searchInput); got != "ét" {
+		t.Fatalf("expected the accent composed and the paste drained up to the broken byte, got %q", got)
+	}
+}
+
+func TestReadKeyEventWaitsForLateEscapeSequence(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the byte-stream reader does not wait on Windows")
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = r.Close(); _ = w.Close() }()
+	p := &PreviewPager{
+		state:  &statepkg.AppState{EscapeTimeout: 500 * time.Millisecond},
+		input:  r,
+		reader: bufio.NewReader(r),
+	}
+	read := func() keyKind {
+		t.Helper()
+		ev, err := p.readKeyEvent()
+		if err != nil {
+			t.Fatalf("readKeyEvent: %v", err)
+		}
+		return ev.kind
+	}
+
+	// The rest of the arrow key arrives after the ESC, as over a slow link.
+	_, _ = w.Write([]byte{0x1b})
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		_, _ = w.Write([]byte("[A"))
+	}()
+	if got := read(); got != keyUp {
+		t.Fatalf("expected keyUp from a split sequence, got %v", got)
+	}
+
+	p.state.EscapeTimeout = 20 * time.Millisecond
+	_, _ = w.Write([]byte{0x1b})
+	if got := read(); got != keyEscape {
+		t.Fatalf("expected a lone ESC to be Escape, got %v", got)
+	}
+
+	// A sequence cut short is dropped without swallowing the next key.
+	_, _ = w.Write([]byte("\x1b["))
+	if got := read(); got != keyUnknown {
+		t.Fatalf("expected an unfinished sequence to be ignored, got %v", got)
+	}
+	_, _ = w.Write([]byte("q"))
+	if got := read(); got != keyQuit {
+		t.Fatalf("expected q after the dropped sequence, got %v", got)
+	}
+}
+
+func TestReadKeyEventSkipsUnknownSequences(t *testing.T) {
+	t.Parallel()
+	p := &PreviewPager{reader: bufio.NewReader(strings.NewReader("\x1b[97;5uj\x1b\x1b[Bx\x1bj"))}
+	want := []keyKind{keyUnknown, keyDown, keyEscape, keyDown, keyQuit, keyUnknown}
+	for i, kind := range want {
+		ev, err := p.readKeyEvent()
+		if err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+		if ev.kind != kind {
+			t.Fatalf("event %d: expected %v, got %v", i, kind, ev.kind)
+		}
+	}
+}
+
+func FuzzReadKeyEvent(f *testing.F) {
+	for _, seed := range []string{"\x1b[A", "\x1b[1;2B", "\x1b[5;2~", "\x1bOH", "\x1b[97;5u", "\x1b[", "\x1b\x1b", "héllo", "\xff\xfe"} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		p := &PreviewPager{reader: bufio.NewReader(bytes.NewReader(data))}
+		for events := 0; ; events++ {
+			if events > len(data) {
+				t.Fatalf("%d events from %d bytes: the parser stopped consuming input", events, len(data))
+			}
+			if _, err := p.readKeyEvent(); err != nil {
+				return
+			}
+		}
+	})
+}
+
 func TestReadKeyEventEdit(t *testing.T) {
 	t.Parallel()
 	p := &PreviewPager{reader: bufio.NewReader(strings.NewReader("e"))}
@@ -1159,7 +1541,7 @@ func TestBinaryHighlightsApplied(t *testing.T) {
 			},
 		},
 	}
-	source, err := newBinaryPagerSource(path, int64(len(data)), 100)
+	source, err := newBinaryPagerSource(nil, path, nil, int64(len(data)), 100)
 	if err != nil {
 		t.Fatalf("newBinaryPagerSource: %v", err)
 	}
@@ -1252,7 +1634,7 @@ func TestBinarySearchAsciiSmartCase(t *testing.T) {
 			},
 		},
 	}
-	source, err := newBinaryPagerSource(path, int64(len(data)), 100)
+	source, err := newBinaryPagerSource(nil, path, nil, int64(len(data)), 100)
 	if err != nil {
 		t.Fatalf("newBinaryPagerSource: %v", err)
 	}
@@ -1290,7 +1672,7 @@ func TestBinarySearchSpaceLiteral(t *testing.T) {
 			},
 		},
 	}
-	source, err := newBinaryPagerSource(path, int64(len(data)), 100)
+	source, err := newBinaryPagerSource(nil, path, nil, int64(len(data)), 100)
 	if err != nil {
 		t.Fatalf("newBinaryPagerSource: %v", err)
 	}
@@ -1330,7 +1712,7 @@ func TestBinarySearchHighlightsMultiBytePattern(t *testing.T) {
 			},
 		},
 	}
-	source, err := newBinaryPagerSource(path, int64(len(data)), 100)
+	source, err := newBinaryPagerSource(nil, path, nil, int64(len(data)), 100)
 	if err != nil {
 		t.Fatalf("newBinaryPagerSource: %v", err)
 	}
@@ -1372,7 +1754,7 @@ func TestBinarySearchHighlightsTrack24BytesPerLine(t *testing.T) {
 			},
 		},
 	}
-	source, err := newBinaryPagerSource(path, int64(len(data)), 120) // 24 bytes/line
+	source, err := newBinaryPagerSource(nil, path, nil, int64(len(data)), 120) // 24 bytes/line
 	if err != nil {
 		t.Fatalf("newBinaryPagerSource: %v", err)
 	}
@@ -1448,7 +1830,7 @@ func TestBinarySearchHexDoesNotFoldASCII(t *testing.T) {
 			},
 		},
 	}
-	source, err := newBinaryPagerSource(path, int64(len(data)), 100)
+	source, err := newBinaryPagerSource(nil, path, nil, int64(len(data)), 100)
 	if err != nil {
 		t.Fatalf("newBinaryPagerSource: %v", err)
 	}
@@ -1489,7 +1871,7 @@ func TestBinarySearchAcceptsPartialHexNibble(t *testing.T) {
 			},
 		},
 	}
-	source, err := newBinaryPagerSource(path, int64(len(data)), 100)
+	source, err := newBinaryPagerSource(nil, path, nil, int64(len(data)), 100)
 	if err != nil {
 		t.Fatalf("newBinaryPagerSource: %v", err)
 	}
@@ -1549,7 +1931,7 @@ func TestBinarySearchFocusSingleNibbleHighlight(t *testing.T) {
 			},
 		},
 	}
-	source, err := newBinaryPagerSource(path, int64(len(data)), 100)
+	source, err := newBinaryPagerSource(nil, path, nil, int64(len(data)), 100)
 	if err != nil {
 		t.Fatalf("newBinaryPagerSource: %v", err)
 	}
@@ -1605,7 +1987,7 @@ func TestBinarySearchClearingKeepsBinaryMode(t *testing.T) {
 			},
 		},
 	}
-	source, err := newBinaryPagerSource(path, int64(len(data)), 100)
+	source, err := newBinaryPagerSource(nil, path, nil, int64(len(data)), 100)
 	if err != nil {
 		t.Fatalf("newBinaryPagerSource: %v", err)
 	}
@@ -1625,7 +2007,8 @@ func TestBinarySearchClearingKeepsBinaryMode(t *testing.T) {
 	p.appendSearchRune('5')
 	p.runPendingSearch()
 
-	p.handleSearchModeEvent(keyEvent{kind: keyLeft}) // clear input
+	p.handleSearchModeEvent(keyEvent{kind: keyHome})
+	p.handleSearchModeEvent(keyEvent{kind: keyLeft}) // clear input at the start
 	if len(p.searchInput) != 0 {
 		t.Fatalf("expected cleared input after left key, got %q", string(p.searchInput))
 	}
@@ -1645,6 +2028,44 @@ func TestBinarySearchClearingKeepsBinaryMode(t *testing.T) {
 	}
 }
 
+func TestSearchInputEditsAtCursor(t *testing.T) {
+	t.Parallel()
+	p := &PreviewPager{state: &statepkg.AppState{}, searchMode: true}
+	t.Cleanup(p.stopSearchTimer)
+	for _, ch := range "foo bar" {
+		p.appendSearchRune(ch)
+	}
+
+	p.handleSearchModeEvent(keyEvent{kind: keyLeft, mod: 5})
+	p.handleSearchModeEvent(keyEvent{kind: keyLeft})
+	p.appendSearchRune('s')
+	if got := string(p.searchInput); got != "foos bar" {
+		t.Fatalf("expected typing at the cursor, got %q", got)
+	}
+	if _, col := p.searchDisplaySegment(); col != len("/foos")+1 {
+		t.Fatalf("expected cursor column after %q, got %d", "/foos", col)
+	}
+
+	p.handleSearchModeEvent(keyEvent{kind: keyLineEdit, edit: lineedit.KillToEnd})
+	if got := string(p.searchInput); got != "foos" {
+		t.Fatalf("expected Ctrl+K to cut the rest, got %q", got)
+	}
+
+	p.handleSearchModeEvent(keyEvent{kind: keyHome, ch: 'g'})
+	if got := string(p.searchInput); got != "foosg" {
+		t.Fatalf("expected g to be typed, got %q", got)
+	}
+
+	p.handleSearchModeEvent(keyEvent{kind: keyHome})
+	p.handleSearchModeEvent(keyEvent{kind: keyLineEdit, edit: lineedit.Delete})
+	if got := string(p.searchInput); got != "oosg" {
+		t.Fatalf("expected Delete at the start to remove the first rune, got %q", got)
+	}
+	if p.searchTail != 4 {
+		t.Fatalf("expected the cursor to stay at the start, tail %d", p.searchTail)
+	}
+}
+
 func TestBinarySearchPartialNibbleAsciiHighlights(t *testing.T) {
 	t.Parallel()
 
@@ -1678,7 +2099,7 @@ func TestBinarySearchPartialNibbleAsciiHighlights(t *testing.T) {
 					},
 				},
 			}
-			source, err := newBinaryPagerSource(path, int64(len(tt.data)), 100)
+			source, err := newBinaryPagerSource(nil, path, nil, int64(len(tt.data)), 100)
 			if err != nil {
 				t.Fatalf("newBinaryPagerSource: %v", err)
 			}
@@ -1729,7 +2150,7 @@ func TestBinarySearchPartialNibbleSkipsOverlappingMatches(t *testing.T) {
 			},
 		},
 	}
-	source, err := newBinaryPagerSource(path, int64(len(data)), 100)
+	source, err := newBinaryPagerSource(nil, path, nil, int64(len(data)), 100)
 	if err != nil {
 		t.Fatalf("newBinaryPagerSource: %v", err)
 	}
@@ -1772,7 +2193,7 @@ func TestBinarySearchHonorsByteLimit(t *testing.T) {
 			},
 		},
 	}
-	source, err := newBinaryPagerSource(path, int64(len(data)), 100)
+	source, err := newBinaryPagerSource(nil, path, nil, int64(len(data)), 100)
 	if err != nil {
 		t.Fatalf("newBinaryPagerSource: %v", err)
 	}
@@ -1818,7 +2239,7 @@ func TestBinarySearchFullScanFindsBeyondBinaryByteLimit(t *testing.T) {
 			},
 		},
 	}
-	source, err := newBinaryPagerSource(path, int64(len(data)), 100)
+	source, err := newBinaryPagerSource(nil, path, nil, int64(len(data)), 100)
 	if err != nil {
 		t.Fatalf("newBinaryPagerSource: %v", err)
 	}
@@ -1875,7 +2296,7 @@ func TestBinarySearchHitCapStillHighlightsVisibleMatch(t *testing.T) {
 		},
 		PreviewScrollOffset: 0,
 	}
-	source, err := newBinaryPagerSource(path, int64(len(data)), 100)
+	source, err := newBinaryPagerSource(nil, path, nil, int64(len(data)), 100)
 	if err != nil {
 		t.Fatalf("newBinaryPagerSource: %v", err)
 	}
@@ -1943,7 +2364,7 @@ func TestBinarySearchHitCapNextPrefersViewport(t *testing.T) {
 			},
 		},
 	}
-	source, err := newBinaryPagerSource(path, int64(len(data)), 100)
+	source, err := newBinaryPagerSource(nil, path, nil, int64(len(data)), 100)
 	if err != nil {
 		t.Fatalf("newBinaryPagerSource: %v", err)
 	}
@@ -1985,9 +2406,56 @@ func TestBinarySearchHitCapNextPrefersViewport(t *testing.T) {
 	}
 }
 
+func TestFormatHexLineDecodesUTF8(t *testing.T) {
+	t.Parallel()
+	chunk := []byte("é漢\x00\xe6\x97")
+	ascii := func(line string) string {
+		return line[strings.Index(line, "|")+1 : strings.LastIndex(line, "|")]
+	}
+	if got := ascii(formatHexLine(0, chunk, 8, false)); got != "........" {
+		t.Fatalf("expected dots without decoding, got %q", got)
+	}
+	// One cell per byte: é (2 bytes, 1 cell) + filler, 漢 (3 bytes, 2 cells)
+	// + filler, NUL, then a sequence cut off by the line end.
+	got := ascii(formatHexLine(0, chunk, 8, true))
+	if got != "é·漢·..." {
+		t.Fatalf("unexpected decoded column %q", got)
+	}
+	if width := displayWidth(got); width != len(chunk) {
+		t.Fatalf("expected the column to keep a cell per byte, got width %d", width)
+	}
+}
+
+func TestToggleHexUTF8ReformatsLines(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "mixed.bin")
+	data := append([]byte{0x00, 0x01}, "zażółć"...)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write test file: %v", err)
+	}
+	source, err := newBinaryPagerSource(nil, path, nil, int64(len(data)), 80)
+	if err != nil {
+		t.Fatalf("newBinaryPagerSource: %v", err)
+	}
+	t.Cleanup(source.Close)
+	state := &statepkg.AppState{}
+	p := &PreviewPager{state: state, binaryMode: true, binarySource: source}
+
+	if line := source.Line(0); strings.Contains(line, "ż") {
+		t.Fatalf("expected dots before toggling, got %q", line)
+	}
+	p.handleKey(keyEvent{kind: keyToggleHexUTF8, ch: 'd'})
+	if !state.PreviewHexUTF8 {
+		t.Fatalf("expected d to turn decoding on")
+	}
+	if line := source.Line(0); !strings.HasSuffix(line, "|..zaż·ó·|") {
+		t.Fatalf("expected decoded text in the ASCII column, got %q", line)
+	}
+}
+
 func TestApplySearchHighlightsBinarySingleByteDoesNotIncludeTrailingSpace(t *testing.T) {
 	t.Parallel()
-	line := formatHexLine(0, []byte{0x00, 0x01}, 16)
+	line := formatHexLine(0, []byte{0x00, 0x01}, 16, false)
 	spans := []textSpan{hexSpanForByte(0, 16)}
 	highlighted := applySearchHighlights(line, spans, nil)
 	want := searchHighlightOn + "00" + searchHighlightOff + " "
@@ -3140,6 +3608,47 @@ func TestCopyAllBlocksHardLimit(t *testing.T) {
 	}
 }
 
+func TestCopyAllOffersTempFileForLargeContent(t *testing.T) {
+	preview := &statepkg.PreviewData{
+		Name:          "big.log",
+		Size:          clipboardWarnBytes + 1,
+		TextLines:     []string{"first", "second"},
+		LineCount:     2,
+		TextCharCount: 11,
+	}
+	state := &statepkg.AppState{CurrentPath: "/tmp", PreviewData: preview, ClipboardAvailable: true}
+	pager, err := NewPreviewPager(state, nil, nil, []string{"clip"})
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	var copied []string
+	pager.clipboardFunc = func(content string) error {
+		copied = append(copied, content)
+		return nil
+	}
+
+	pager.handleKey(keyEvent{kind: keyCopyAll, ch: 'C'})
+	if len(copied) != 0 || !strings.Contains(pager.statusMessage, "P copies the path") {
+		t.Fatalf("expected the first C to offer the temp file, copied=%q status=%q", copied, pager.statusMessage)
+	}
+
+	pager.handleKey(keyEvent{kind: keyCopyAllViaFile, ch: 'P'})
+	if len(copied) != 1 || !strings.HasSuffix(copied[0], ".log") {
+		t.Fatalf("expected the temp file path to be copied, got %q", copied)
+	}
+	t.Cleanup(func() { _ = os.Remove(copied[0]) })
+	data, err := os.ReadFile(copied[0])
+	if err != nil || string(data) != "first\nsecond" {
+		t.Fatalf("unexpected temp file content %q, %v", data, err)
+	}
+
+	pager.handleKey(keyEvent{kind: keyCopyAll, ch: 'C'})
+	pager.handleKey(keyEvent{kind: keyCopyAll, ch: 'C'})
+	if len(copied) != 2 || copied[1] != "first\nsecond" {
+		t.Fatalf("expected C twice to copy the content, got %q", copied)
+	}
+}
+
 func TestCopyVisibleStripsANSIFromFormatted(t *testing.T) {
 	t.Parallel()
 	preview := &statepkg.PreviewData{
@@ -3253,3 +3762,1113 @@ func TestCopyAllUsesRawWhenFormatted(t *testing.T) {
 		t.Fatalf("expected raw copy, got %q", copied)
 	}
 }
+
+func TestSearchSeedOpensAtMatchingLine(t *testing.T) {
+	lines := make([]string, 100)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i)
+	}
+	lines[10] = "needle early"
+	lines[70] = "needle late"
+	preview := &statepkg.PreviewData{Name: "log.txt", TextLines: lines, LineCount: len(lines)}
+	state := &statepkg.AppState{
+		CurrentPath: "/tmp",
+		Files:       []statepkg.FileEntry{{Name: "log.txt"}},
+		PreviewData: preview,
+	}
+	state.SeedPagerSearch("/tmp/log.txt", "needle", 60)
+
+	pager, err := NewPreviewPager(state, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	pager.width = 40
+	pager.height = 10
+	pager.applySearchSeed()
+
+	if hit := pager.focusedHit(); hit == nil || hit.line != 70 {
+		t.Fatalf("expected focus on line 70, got %+v", hit)
+	}
+	if state.PreviewScrollOffset == 0 {
+		t.Fatalf("expected the pager to scroll to the match")
+	}
+	if _, ok := state.TakePagerSearchSeed("/tmp/log.txt"); ok {
+		t.Fatalf("expected the seed to be consumed")
+	}
+}
+
+func TestPagerMemoryRestoresFileState(t *testing.T) {
+	lines := make([]string, 100)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i)
+	}
+	lines[50] = "needle"
+	newState := func() *statepkg.AppState {
+		return &statepkg.AppState{
+			CurrentPath: "/tmp",
+			Files:       []statepkg.FileEntry{{Name: "log.txt"}},
+			PreviewData: &statepkg.PreviewData{Name: "log.txt", TextLines: lines, LineCount: len(lines)},
+		}
+	}
+	mem, err := statepkg.LoadPagerMemory(filepath.Join(t.TempDir(), "pager_state.json"))
+	if err != nil {
+		t.Fatalf("LoadPagerMemory: %v", err)
+	}
+
+	first, err := NewPreviewPager(newState(), nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	first.SetMemory(mem)
+	first.wrapEnabled = true
+	first.state.PreviewScrollOffset = 40
+	first.executeSearch("needle")
+	first.rememberFileState()
+
+	second, err := NewPreviewPager(newState(), nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	second.SetMemory(mem)
+	second.width = 40
+	second.height = 10
+	second.restoreFileState()
+
+	if !second.wrapEnabled {
+		t.Fatalf("expected wrap to be restored")
+	}
+	if second.state.PreviewScrollOffset != 40 {
+		t.Fatalf("expected scroll line 40, got %d", second.state.PreviewScrollOffset)
+	}
+	if second.searchQuery != "needle" || len(second.searchHits) != 1 {
+		t.Fatalf("expected search to be restored, got %q with %d hits", second.searchQuery, len(second.searchHits))
+	}
+}
+
+func TestANSIColorsPassThroughOnlySGR(t *testing.T) {
+	preview := &statepkg.PreviewData{
+		Name:      "build.log",
+		TextLines: []string{"\x1b[1;31mFAIL\x1b[0m pkg\x1b[5;1H\x1b]0;title\x07"},
+		LineCount: 1,
+	}
+	state := &statepkg.AppState{CurrentPath: "/tmp", Files: []statepkg.FileEntry{{Name: "build.log"}}, PreviewData: preview}
+	pager, err := NewPreviewPager(state, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	if got := pager.lineAt(0); strings.Contains(got, "\x1b") {
+		t.Fatalf("expected escapes to be neutralized by default, got %q", got)
+	}
+
+	pager.handleKey(keyEvent{kind: keyToggleANSI})
+	if !state.PreviewANSIColors {
+		t.Fatalf("state should record the ANSI preference")
+	}
+	want := "\x1b[1;31mFAIL\x1b[0m pkg?[5;1H?]0;title?"
+	if got := pager.lineAt(0); got != want {
+		t.Fatalf("expected only SGR to survive, got %q want %q", got, want)
+	}
+	if w := pager.lineWidth(0); w != displayWidth(want) {
+		t.Fatalf("expected width %d to ignore colors, got %d", displayWidth(want), w)
+	}
+
+	pager.executeSearch("FAIL")
+	if len(pager.searchHits) != 1 || pager.searchHits[0].span.start != 0 {
+		t.Fatalf("expected the colored word to be found at column 0, got %+v", pager.searchHits)
+	}
+}
+
+func TestWrapKeepsSGRSequencesWhole(t *testing.T) {
+	text := "\x1b[31mabcdef\x1b[0mgh"
+	segments := wrapLineSegments(text, 3)
+	want := []string{"\x1b[31mabc", "def", "\x1b[0mgh"}
+	if len(segments) != len(want) {
+		t.Fatalf("expected %q, got %q", want, segments)
+	}
+	for i := range want {
+		if segments[i] != want[i] {
+			t.Fatalf("segment %d: expected %q, got %q", i, want[i], segments[i])
+		}
+	}
+	if ranged := wrapLineSegmentsRange(text, 3, 1, 1); len(ranged) != 1 || ranged[0] != "def" {
+		t.Fatalf("unexpected ranged segments %q", ranged)
+	}
+
+	pager := &PreviewPager{ansiColors: true}
+	if got := pager.wrappedRowColors(text, "def", 3); got != "\x1b[31mdef" {
+		t.Fatalf("expected the color to carry into the continuation row, got %q", got)
+	}
+	if got := pager.wrappedRowColors(text, "\x1b[0mgh", 6); got != "\x1b[0mgh" {
+		t.Fatalf("expected a reset to stop the carry, got %q", got)
+	}
+}
+
+func TestScrollbarCellsShowThumbAndHits(t *testing.T) {
+	lines := make([]string, 100)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i)
+	}
+	lines[5] = "needle"
+	lines[95] = "needle"
+	state := &statepkg.AppState{
+		CurrentPath: "/tmp",
+		Files:       []statepkg.FileEntry{{Name: "log.txt"}},
+		PreviewData: &statepkg.PreviewData{Name: "log.txt", TextLines: lines, LineCount: len(lines)},
+	}
+	pager, err := NewPreviewPager(state, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	pager.executeSearch("needle")
+
+	cells := pager.scrollbarCells(10, 50, 59, len(lines))
+	want := []scrollbarCell{
+		scrollbarHit, scrollbarTrack, scrollbarTrack, scrollbarTrack, scrollbarTrack,
+		scrollbarThumb, scrollbarTrack, scrollbarTrack, scrollbarTrack, scrollbarHit,
+	}
+	for i := range want {
+		if cells[i] != want[i] {
+			t.Fatalf("cell %d: expected %v, got %v (all %v)", i, want[i], cells[i], cells)
+		}
+	}
+
+	cells = pager.scrollbarCells(10, 0, 9, len(lines))
+	if cells[0] != scrollbarThumbHit {
+		t.Fatalf("expected a hit inside the thumb to be marked, got %v", cells)
+	}
+}
+
+func TestScrollbarMarksUnloadedPartOfStreamedFile(t *testing.T) {
+	pager := &PreviewPager{
+		state: &statepkg.AppState{PreviewData: &statepkg.PreviewData{Size: 1000}},
+		rawTextSource: &textPagerSource{
+			lines:      []textLineRecord{{offset: 0, length: 100}, {offset: 100, length: 100}},
+			nextOffset: 200,
+		},
+	}
+	cells := pager.scrollbarCells(10, 0, 0, 2)
+	if cells[0] != scrollbarThumb || cells[1] != scrollbarTrack {
+		t.Fatalf("expected the thumb to cover the first line only, got %v", cells)
+	}
+	for i := 2; i < 10; i++ {
+		if cells[i] != scrollbarUnloaded {
+			t.Fatalf("cell %d: expected the unread part to be marked, got %v", i, cells)
+		}
+	}
+
+	pager.state.PreviewHideScrollbar = true
+	if cols := pager.scrollbarColumns(80); cols != 0 {
+		t.Fatalf("expected no scrollbar column when hidden, got %d", cols)
+	}
+}
+
+func TestSplitViewKeepsIndependentPanes(t *testing.T) {
+	lines := make([]string, 200)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i)
+	}
+	lines[3] = "const Limit = 10"
+	lines[150] = "use(Limit)"
+	state := &statepkg.AppState{
+		CurrentPath: "/tmp",
+		Files:       []statepkg.FileEntry{{Name: "main.go"}},
+		PreviewData: &statepkg.PreviewData{Name: "main.go", TextLines: lines, LineCount: len(lines)},
+	}
+	pager, err := NewPreviewPager(state, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	var buf bytes.Buffer
+	pager.writer = bufio.NewWriter(&buf)
+	pager.output = &buf
+	pager.width = 40
+	pager.height = 24
+
+	pager.handleKey(keyEvent{kind: keyToggleSplit})
+	if !pager.splitActive() {
+		t.Fatalf("expected split view to be active")
+	}
+	if pager.viewHeight() >= pager.height {
+		t.Fatalf("expected the focused pane to lose rows to the other pane, view height %d", pager.viewHeight())
+	}
+
+	pager.executeSearch("Limit")
+	pager.moveSearchCursor(1)
+	pager.focusSearchHit(pager.searchCursor)
+	topScroll := state.PreviewScrollOffset
+	if topScroll == 0 {
+		t.Fatalf("expected the top pane to scroll to the far hit")
+	}
+
+	pager.handleKey(keyEvent{kind: keySwitchPane})
+	if state.PreviewScrollOffset != 0 || pager.searchQuery != "" {
+		t.Fatalf("expected the bottom pane to keep its own position and search, got scroll %d query %q", state.PreviewScrollOffset, pager.searchQuery)
+	}
+	pager.executeSearch("const")
+
+	if err := pager.render(); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "use(") || !strings.Contains(out, "const") {
+		t.Fatalf("expected both panes to be drawn")
+	}
+	if !strings.Contains(out, "▼ bottom pane active") {
+		t.Fatalf("expected the divider to show the focused pane")
+	}
+
+	pager.handleKey(keyEvent{kind: keySwitchPane})
+	if state.PreviewScrollOffset != topScroll || pager.searchQuery != "Limit" {
+		t.Fatalf("expected the top pane state back, got scroll %d query %q", state.PreviewScrollOffset, pager.searchQuery)
+	}
+
+	pager.handleKey(keyEvent{kind: keyToggleSplit})
+	if pager.split != nil || pager.viewHeight() != pager.height {
+		t.Fatalf("expected closing the split to give the full height back")
+	}
+}
+
+func TestInspectorReportsCharacterUnderCursor(t *testing.T) {
+	dir := t.TempDir()
+	content := "first\n\ta\u200bb\n"
+	if err := os.WriteFile(filepath.Join(dir, "odd.txt"), []byte(content), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	preview := &statepkg.PreviewData{
+		Name:      "odd.txt",
+		TextLines: []string{"first", "    a\u200bb"},
+		TextLineMeta: []statepkg.TextLineMetadata{
+			{Offset: 0, Length: 5},
+			{Offset: 6, Length: 6},
+		},
+		LineCount: 2,
+	}
+	state := &statepkg.AppState{CurrentPath: dir, Files: []statepkg.FileEntry{{Name: "odd.txt"}}, PreviewData: preview}
+	pager, err := NewPreviewPager(state, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	pager.width = 40
+	pager.height = 10
+
+	pager.handleKey(keyEvent{kind: keyToggleInspect})
+	if pager.inspect == nil {
+		t.Fatalf("expected the inspector to open")
+	}
+	pager.handleKey(keyEvent{kind: keyDown})
+	pager.handleKey(keyEvent{kind: keyRight})
+	pager.handleKey(keyEvent{kind: keyRight})
+
+	info := pager.inspectSegment()
+	for _, want := range []string{"ln 2 col 3", "U+200B", "ZERO WIDTH SPACE", "UTF-8 E2 80 8B", "byte 8 (0x8)", "width 0"} {
+		if !strings.Contains(info, want) {
+			t.Fatalf("expected %q in inspector status, got %q", want, info)
+		}
+	}
+	// Tab expands to 4 columns, "a" takes one, so the label starts at column 5.
+	if span := pager.inspectSpan(); span.start != 5 || span.end != 5+displayWidth("⟪ZWSP⟫") {
+		t.Fatalf("unexpected cursor span %+v", span)
+	}
+
+	if done := pager.handleKey(keyEvent{kind: keyLeft}); done {
+		t.Fatalf("left should move the cursor, not leave the pager")
+	}
+	pager.handleKey(keyEvent{kind: keyEscape})
+	if pager.inspect != nil {
+		t.Fatalf("expected Esc to leave the inspector")
+	}
+}
+
+func TestAltArrowsActAsBackAndForward(t *testing.T) {
+	t.Parallel()
+	p := &PreviewPager{state: &statepkg.AppState{}, searchMode: true}
+	t.Cleanup(p.stopSearchTimer)
+	for _, ch := range "foo bar" {
+		p.appendSearchRune(ch)
+	}
+	p.handleSearchModeEvent(keyEvent{kind: keyLeft, mod: 3})
+	if p.searchMode {
+		t.Fatal("expected Alt+← to leave search")
+	}
+
+	wrap := p.wrapEnabled
+	if done := p.handleKey(keyEvent{kind: keyRight, mod: 3}); done || p.wrapEnabled != wrap {
+		t.Fatalf("expected Alt+→ to do nothing (done=%v, wrap=%v)", done, p.wrapEnabled)
+	}
+	if !hasAltModifier(8) || hasAltModifier(5) || hasAltModifier(1) {
+		t.Fatal("hasAltModifier misreads xterm modifier parameters")
+	}
+}
+
+func TestHiddenFormattingScanJumpsBetweenOccurrences(t *testing.T) {
+	lines := []string{"plain", "if x \u202e{ admin }\u2066", "clean", "a\u200bb"}
+	preview := &statepkg.PreviewData{Name: "trojan.go", TextLines: lines, LineCount: len(lines)}
+	state := &statepkg.AppState{CurrentPath: t.TempDir(), Files: []statepkg.FileEntry{{Name: "trojan.go"}}, PreviewData: preview}
+	pager, err := NewPreviewPager(state, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	pager.width = 60
+	pager.height = 10
+
+	pager.handleKey(keyEvent{kind: keyHiddenNext})
+	if !pager.hiddenScanActive() || len(pager.searchHits) != 3 {
+		t.Fatalf("expected 3 hidden formatting hits, got %d", len(pager.searchHits))
+	}
+	first := pager.searchHits[0]
+	if first.line != 1 || first.mark != 0x202E || first.span != (textSpan{start: 5, end: 5 + displayWidth("⟪RLO⟫")}) {
+		t.Fatalf("unexpected first hit %+v", first)
+	}
+	if got := pager.searchStatusSegment(); !strings.Contains(got, "hidden 1/3") || !strings.Contains(got, "U+202E RLO RIGHT-TO-LEFT OVERRIDE") {
+		t.Fatalf("unexpected status segment %q", got)
+	}
+
+	pager.handleKey(keyEvent{kind: keyHiddenNext})
+	if pager.searchCursor != 1 || pager.focusedHit().mark != 0x2066 {
+		t.Fatalf("expected z to step to the isolate, cursor=%d", pager.searchCursor)
+	}
+
+	pager.handleKey(keyEvent{kind: keyHiddenList})
+	if !pager.showHiddenList {
+		t.Fatalf("expected Z to open the occurrence list")
+	}
+	if list := pager.hiddenListLines(); len(list) != 3 || !strings.Contains(list[2], "4:2") {
+		t.Fatalf("unexpected list %q", list)
+	}
+	pager.handleKey(keyEvent{kind: keyDown})
+	pager.handleKey(keyEvent{kind: keyEnter})
+	if pager.showHiddenList || pager.searchCursor != 2 {
+		t.Fatalf("expected Enter to close the list on the third hit, cursor=%d", pager.searchCursor)
+	}
+
+	pager.handleKey(keyEvent{kind: keyStartSearch})
+	if pager.hiddenScanActive() || len(pager.searchInput) != 0 {
+		t.Fatalf("expected a text search to replace the scan")
+	}
+}
+
+func TestHiddenFormattingScanReportsCleanFile(t *testing.T) {
+	preview := &statepkg.PreviewData{Name: "clean.txt", TextLines: []string{"nothing", "here"}, LineCount: 2}
+	state := &statepkg.AppState{CurrentPath: t.TempDir(), Files: []statepkg.FileEntry{{Name: "clean.txt"}}, PreviewData: preview}
+	pager, err := NewPreviewPager(state, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	pager.handleKey(keyEvent{kind: keyHiddenList})
+	if pager.showHiddenList || pager.hiddenScanActive() {
+		t.Fatalf("expected no list for a clean file")
+	}
+	if !strings.Contains(pager.statusMessage, "no hidden formatting") {
+		t.Fatalf("unexpected status message %q", pager.statusMessage)
+	}
+}
+
+func TestReadingModeReflowsMarkdownProseToColumn(t *testing.T) {
+	paragraph := strings.TrimSpace(strings.Repeat("reading mode keeps prose lines short ", 8))
+	source := []string{"# Title", "", paragraph, "", "- " + paragraph}
+	segments, meta := statepkg.FormatMarkdownPreview(source, 200, 0, true)
+	preview := &statepkg.PreviewData{
+		Name:                     "notes.md",
+		TextLines:                source,
+		LineCount:                len(source),
+		FormattedKind:            "markdown",
+		FormattedSegments:        segments,
+		FormattedSegmentLineMeta: meta,
+	}
+	state := &statepkg.AppState{CurrentPath: t.TempDir(), PreviewData: preview, PreviewWrap: true, ReadingWidth: 40}
+	pager, err := NewPreviewPager(state, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	pager.width = 120
+	pager.height = 20
+	pager.reflowMarkdownFormatted()
+	if !pager.showFormatted {
+		t.Fatalf("expected the formatted view")
+	}
+	wide := len(pager.lines)
+
+	pager.handleKey(keyEvent{kind: keyToggleReading, ch: 'r'})
+	if !state.PreviewReadingMode {
+		t.Fatalf("expected reading mode to turn on")
+	}
+	if len(pager.lines) <= wide {
+		t.Fatalf("expected prose to reflow into more lines, got %d (was %d)", len(pager.lines), wide)
+	}
+	bullet := false
+	for _, line := range pager.lines {
+		plain := stripANSICodes(line)
+		if plain == "" {
+			continue
+		}
+		if !strings.HasPrefix(plain, strings.Repeat(" ", 40)) {
+			t.Fatalf("expected a 40-column margin, got %q", plain)
+		}
+		text := plain[40:]
+		if displayWidth(text) > 40 {
+			t.Fatalf("line exceeds the reading column: %q", text)
+		}
+		if strings.HasSuffix(text, " ") {
+			t.Fatalf("line should not end in a space: %q", text)
+		}
+		if strings.HasPrefix(text, "  reading") {
+			bullet = true
+		}
+		if strings.HasPrefix(text, "mode") || strings.HasPrefix(text, "ode") {
+			t.Fatalf("expected breaks between words, got %q", text)
+		}
+	}
+	if !bullet {
+		t.Fatalf("expected list continuation rows to hang under the bullet text: %q", pager.lines)
+	}
+	if status := strings.Join(pager.statusBadges(pagerContentMarkdown), " "); !strings.Contains(status, "read:on") {
+		t.Fatalf("expected read:on badge, got %q", status)
+	}
+
+	pager.handleKey(keyEvent{kind: keyToggleReading, ch: 'r'})
+	if state.PreviewReadingMode || len(pager.lines) != wide {
+		t.Fatalf("expected reading mode off with %d lines, got %d", wide, len(pager.lines))
+	}
+}
+
+func TestWrapWordsSplitsOverlongWords(t *testing.T) {
+	rows := wrapWords("ab \x1b[1mcdefghij\x1b[0m kl", 4)
+	plain := make([]string, len(rows))
+	for i, row := range rows {
+		plain[i] = stripANSICodes(row)
+	}
+	if got := strings.Join(plain, "|"); got != "ab|cdef|ghij|kl" {
+		t.Fatalf("unexpected rows %q", got)
+	}
+	if !strings.HasPrefix(rows[2], "\x1b[1m") {
+		t.Fatalf("expected the bold style to carry over, got %q", rows[2])
+	}
+}
+
+func TestTableOfContentsJumpsToMarkdownSection(t *testing.T) {
+	source := []string{"# Guide", "intro", "## Install", "```sh", "# not a heading", "```", "### Linux", "apt install", "## Usage", "run it"}
+	segments, meta := statepkg.FormatMarkdownPreview(source, 80, 0, true)
+	preview := &statepkg.PreviewData{
+		Name:                     "guide.md",
+		TextLines:                source,
+		LineCount:                len(source),
+		FormattedKind:            "markdown",
+		FormattedSegments:        segments,
+		FormattedSegmentLineMeta: meta,
+	}
+	state := &statepkg.AppState{CurrentPath: t.TempDir(), PreviewData: preview, PreviewWrap: true}
+	pager, err := NewPreviewPager(state, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	pager.width = 80
+	pager.height = 20
+
+	for _, formatted := range []bool{true, false} {
+		if pager.showFormatted != formatted {
+			pager.toggleFormatView()
+		}
+		state.PreviewScrollOffset = 0
+		pager.handleKey(keyEvent{kind: keyToggleTOC, ch: 't'})
+		if !pager.showTOC {
+			t.Fatalf("expected the contents overlay (formatted=%v)", formatted)
+		}
+		titles := []string{}
+		for _, entry := range pager.toc {
+			titles = append(titles, entry.title)
+		}
+		if got := strings.Join(titles, ","); got != "Guide,Install,Linux,Usage" {
+			t.Fatalf("unexpected entries %q (formatted=%v)", got, formatted)
+		}
+		pager.handleKey(keyEvent{kind: keyDown})
+		pager.handleKey(keyEvent{kind: keyDown})
+		pager.handleKey(keyEvent{kind: keyEnter})
+		if pager.showTOC {
+			t.Fatalf("expected Enter to close the overlay")
+		}
+		if line := stripANSICodes(pager.lineAt(state.PreviewScrollOffset)); !strings.Contains(line, "Linux") {
+			t.Fatalf("expected to land on the Linux heading, got %q (formatted=%v)", line, formatted)
+		}
+		if header := pager.headerLines()[0]; !strings.HasSuffix(header, "§  Guide › Install › Linux") {
+			t.Fatalf("expected the section trail in the header, got %q", header)
+		}
+	}
+}
+
+func TestTableOfContentsListsGoSymbols(t *testing.T) {
+	source := []string{
+		"package demo",
+		"type Server struct{}",
+		"func New() *Server {",
+		"\tfunc() {}()",
+		"}",
+		"func (s *Server) Run() error {",
+	}
+	preview := &statepkg.PreviewData{Name: "demo.go", TextLines: source, LineCount: len(source)}
+	state := &statepkg.AppState{CurrentPath: t.TempDir(), PreviewData: preview}
+	pager, err := NewPreviewPager(state, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	titles := []string{}
+	for _, entry := range pager.tableOfContents(true) {
+		titles = append(titles, fmt.Sprintf("%s@%d", entry.title, entry.line))
+	}
+	if got := strings.Join(titles, ","); got != "Server@1,New@2,Server.Run@5" {
+		t.Fatalf("unexpected symbols %q", got)
+	}
+}
+
+func TestTableOfContentsScansOnlyNewlyLoadedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "parts.py")
+	var builder strings.Builder
+	for i := 0; i < 40; i++ {
+		fmt.Fprintf(&builder, "def part%d():\n    pass\n", i)
+	}
+	if err := os.WriteFile(path, []byte(builder.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	preview := &statepkg.PreviewData{Name: "parts.py", TextTruncated: true}
+	state := &statepkg.AppState{CurrentPath: filepath.Dir(path), PreviewData: preview}
+	pager, err := NewPreviewPager(state, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	source, err := newTextPagerSource(nil, path, preview)
+	if err != nil {
+		t.Fatalf("newTextPagerSource: %v", err)
+	}
+	defer source.Close()
+	source.chunkSize = 32
+	pager.useTextSource(source)
+
+	_ = source.EnsureLine(9)
+	if got := len(pager.tableOfContents(false)); got != (source.LineCount()+1)/2 {
+		t.Fatalf("expected the loaded headings, got %d for %d lines", got, source.LineCount())
+	}
+	key, first := pager.tocKey, pager.toc[0]
+	_ = source.EnsureLine(39)
+	entries := pager.tableOfContents(false)
+	if pager.tocKey != key || pager.tocScanned != source.LineCount() {
+		t.Fatalf("expected the same contents extended to %d lines, got key %q scanned %d", source.LineCount(), pager.tocKey, pager.tocScanned)
+	}
+	if entries[0] != first || len(entries) != (source.LineCount()+1)/2 {
+		t.Fatalf("unexpected entries after loading more: %d", len(entries))
+	}
+	if got := len(pager.tableOfContents(true)); got != 40 {
+		t.Fatalf("expected every heading once loaded, got %d", got)
+	}
+}
+
+func TestCopyReferenceUsesFocusedSearchHit(t *testing.T) {
+	dir := t.TempDir()
+	preview := &statepkg.PreviewData{Name: "app.go", TextLines: []string{"package app", "", "func main() {}", "// main"}, LineCount: 4}
+	state := &statepkg.AppState{CurrentPath: dir, PreviewData: preview, ClipboardAvailable: true}
+	pager, err := NewPreviewPager(state, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	pager.width = 40
+	pager.height = 10
+	var captured string
+	pager.clipboardFunc = func(content string) error {
+		captured = content
+		return nil
+	}
+
+	pager.handleKey(keyEvent{kind: keyCopyRef, ch: 'y'})
+	if want := filepath.Join(dir, "app.go") + ":1"; captured != want {
+		t.Fatalf("expected %q, got %q", want, captured)
+	}
+
+	pager.executeSearch("main")
+	pager.moveSearchCursor(1)
+	state.CopyRefTemplate = "{url}"
+	defer func(orig func(string) (fsutil.GitInfo, error)) { gitLookup = orig }(gitLookup)
+	gitLookup = func(string) (fsutil.GitInfo, error) {
+		return fsutil.GitInfo{Root: dir, Remote: "https://github.com/acme/app.git", Commit: "f00"}, nil
+	}
+	pager.handleKey(keyEvent{kind: keyCopyRef, ch: 'y'})
+	if want := "https://github.com/acme/app/blob/f00/app.go#L4"; captured != want {
+		t.Fatalf("expected %q, got %q", want, captured)
+	}
+	if !strings.Contains(pager.statusMessage, "copied https://github.com") {
+		t.Fatalf("unexpected status %q", pager.statusMessage)
+	}
+}
+
+func TestExportQuickfixWritesSearchHits(t *testing.T) {
+	dir := t.TempDir()
+	preview := &statepkg.PreviewData{Name: "notes.txt", TextLines: []string{"todo: one", "done", "\tżółw todo"}, LineCount: 3}
+	target := filepath.Join(dir, "qf", "hits.txt")
+	state := &statepkg.AppState{CurrentPath: dir, PreviewData: preview, QuickfixFile: target}
+	pager, err := NewPreviewPager(state, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	pager.width = 40
+	pager.height = 10
+
+	pager.handleKey(keyEvent{kind: keyExportQuickfix, ch: 'o'})
+	if !strings.Contains(pager.statusMessage, "no search hits") {
+		t.Fatalf("expected a hint to search first, got %q", pager.statusMessage)
+	}
+
+	pager.executeSearch("todo")
+	pager.handleKey(keyEvent{kind: keyExportQuickfix, ch: 'o'})
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("read quickfix file: %v", err)
+	}
+	path := filepath.Join(dir, "notes.txt")
+	want := path + ":1:1:todo: one\n" + path + ":3:10:\tżółw todo\n"
+	if string(data) != want {
+		t.Fatalf("unexpected quickfix file:\n%s\nwant:\n%s", data, want)
+	}
+	if !strings.Contains(pager.statusMessage, "wrote 2 hits") {
+		t.Fatalf("unexpected status %q", pager.statusMessage)
+	}
+
+	state.QuickfixFile = statepkg.QuickfixStdout
+	pager.handleKey(keyEvent{kind: keyExportQuickfix, ch: 'o'})
+	if len(state.QuickfixOutput) != 2 || state.QuickfixOutput[0] != path+":1:1:todo: one" {
+		t.Fatalf("unexpected stdout entries %q", state.QuickfixOutput)
+	}
+}
+
+func TestEditorLineFollowsFocusedSearchHit(t *testing.T) {
+	preview := &statepkg.PreviewData{Name: "app.go", TextLines: []string{"package app", "func main() {}", "// main"}, LineCount: 3}
+	state := &statepkg.AppState{CurrentPath: t.TempDir(), PreviewData: preview}
+	pager, err := NewPreviewPager(state, []string{"vim"}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	pager.width = 40
+	pager.height = 10
+	if line := pager.editorLine(); line != 0 {
+		t.Fatalf("expected no line without a search, got %d", line)
+	}
+	pager.executeSearch("main")
+	pager.moveSearchCursor(1)
+	if line := pager.editorLine(); line != 3 {
+		t.Fatalf("expected the focused hit's line 3, got %d", line)
+	}
+	args := statepkg.EditorArgs(pager.editorCmd, "app.go", pager.editorLine())
+	if got := strings.Join(args, " "); got != "vim +3 app.go" {
+		t.Fatalf("unexpected editor args %q", got)
+	}
+}
+
+func TestPreviewPagerPagesLargeDirectoryListing(t *testing.T) {
+	entries := make([]statepkg.FileEntry, 50000)
+	for i := range entries {
+		entries[i] = statepkg.FileEntry{Name: fmt.Sprintf("file-%05d.txt", i), Size: int64(i)}
+	}
+	preview := &statepkg.PreviewData{Name: "big", IsDir: true, DirEntries: entries}
+	state := &statepkg.AppState{CurrentPath: t.TempDir(), PreviewData: preview}
+	pager, err := NewPreviewPager(state, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	pager.width, pager.height = 80, 24
+
+	if got := pager.lineCount(); got != len(entries) {
+		t.Fatalf("expected %d lines, got %d", len(entries), got)
+	}
+	if pager.lines != nil {
+		t.Fatalf("directory lines should not be built up front")
+	}
+	if line := pager.lineAt(len(entries) - 1); !strings.Contains(line, "file-49999.txt") {
+		t.Fatalf("unexpected last line %q", line)
+	}
+	pager.scrollToEnd(pager.lineCount())
+	if len(pager.rawTextSource.cache) > textPagerCacheLines {
+		t.Fatalf("expected at most %d cached lines, got %d", textPagerCacheLines, len(pager.rawTextSource.cache))
+	}
+
+	pager.persistLoadedLines()
+	if len(preview.TextLines) != 0 || len(preview.DirEntries) != len(entries) {
+		t.Fatalf("leaving the pager should keep the directory preview intact")
+	}
+}
+
+func TestCheckFileChangeExtendsAppendedLog(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	var builder strings.Builder
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(&builder, "line-%d\n", i)
+	}
+	if err := os.WriteFile(path, []byte(builder.String()), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	state := &statepkg.AppState{
+		CurrentPath: dir,
+		PreviewData: &statepkg.PreviewData{
+			Name:          "app.log",
+			TextLines:     []string{"line-0"},
+			TextLineMeta:  []statepkg.TextLineMetadata{{Offset: 0, Length: 6, RuneCount: 6, DisplayWidth: 6}},
+			TextBytesRead: 7,
+			TextTruncated: true,
+		},
+	}
+	pager, err := NewPreviewPager(state, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	pager.width, pager.height = 40, 10
+	source := pager.rawTextSource
+	if source == nil {
+		t.Fatalf("expected a streaming text source")
+	}
+	if err := source.EnsureAll(); err != nil {
+		t.Fatalf("EnsureAll: %v", err)
+	}
+	state.PreviewScrollOffset = 5
+	pager.executeSearch("line-1")
+	hits := append([]searchHit(nil), pager.searchHits...)
+	if len(hits) == 0 {
+		t.Fatalf("expected search hits")
+	}
+
+	if pager.checkFileChange() {
+		t.Fatalf("expected the first check to only fingerprint the file")
+	}
+	appendToFile(t, path, "line-20\nline-1x\n")
+	if !pager.checkFileChange() {
+		t.Fatalf("expected the append to be detected")
+	}
+	if pager.rawTextSource != source {
+		t.Fatalf("expected the text source to be extended in place")
+	}
+	if state.PreviewScrollOffset != 5 || len(pager.searchHits) != len(hits) || pager.searchHits[0] != hits[0] {
+		t.Fatalf("expected scroll and search hits to be kept, got offset %d hits %v", state.PreviewScrollOffset, pager.searchHits)
+	}
+	if err := source.EnsureAll(); err != nil {
+		t.Fatalf("EnsureAll: %v", err)
+	}
+	if count := source.LineCount(); count != 22 || source.Line(21) != "line-1x" {
+		t.Fatalf("expected 22 lines ending in line-1x, got %d (%q)", count, source.Line(count-1))
+	}
+
+	pager.checkFileChange() // fingerprints the grown file
+	if err := os.WriteFile(path, []byte("rotated\n"), 0o644); err != nil {
+		t.Fatalf("rewrite file: %v", err)
+	}
+	if !pager.checkFileChange() {
+		t.Fatalf("expected the rewrite to be detected")
+	}
+	if pager.rawTextSource == source || pager.rawTextSource.Line(0) != "rotated" {
+		t.Fatalf("expected the file to be reloaded")
+	}
+	if state.PreviewScrollOffset != 0 {
+		t.Fatalf("expected scroll to be clamped to the shorter file, got %d", state.PreviewScrollOffset)
+	}
+}
+
+func TestCheckFileChangeContinuesUnterminatedLine(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.txt")
+	if err := os.WriteFile(path, []byte("a\nb"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	state := &statepkg.AppState{
+		CurrentPath: dir,
+		PreviewData: &statepkg.PreviewData{
+			Name:      "out.txt",
+			TextLines: []string{"a", "b"},
+			TextLineMeta: []statepkg.TextLineMetadata{
+				{Offset: 0, Length: 1, RuneCount: 1, DisplayWidth: 1},
+				{Offset: 2, Length: 1, RuneCount: 1, DisplayWidth: 1},
+			},
+			TextBytesRead: 3,
+		},
+	}
+	pager, err := NewPreviewPager(state, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	if pager.rawTextSource != nil {
+		t.Fatalf("expected in-memory text before the append")
+	}
+
+	pager.checkFileChange()
+	appendToFile(t, path, "c\nd\n")
+	if !pager.checkFileChange() {
+		t.Fatalf("expected the append to be detected")
+	}
+	source := pager.rawTextSource
+	if source == nil {
+		t.Fatalf("expected the appended file to be streamed")
+	}
+	if err := source.EnsureAll(); err != nil {
+		t.Fatalf("EnsureAll: %v", err)
+	}
+	got := []string{}
+	for i := 0; i < source.LineCount(); i++ {
+		got = append(got, source.Line(i))
+	}
+	if strings.Join(got, "|") != "a|bc|d" {
+		t.Fatalf("expected the last line to be continued, got %q", got)
+	}
+}
+
+func appendToFile(t *testing.T, path, text string) {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("open for append: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+	if _, err := file.WriteString(text); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+}
+
+func TestPreviewPagerRecentFilesSwitch(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.txt")
+	second := filepath.Join(dir, "second.txt")
+	for _, path := range []string{first, second} {
+		if err := os.WriteFile(path, []byte("text\n"), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	state := &statepkg.AppState{
+		PreviewData: &statepkg.PreviewData{Name: "first.txt", TextLines: []string{"text"}},
+		PreviewPath: first,
+		CurrentPath: dir,
+		PagerRecent: []string{second},
+	}
+	pager, err := NewPreviewPager(state, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	pager.noteRecentFile()
+	if got := state.PagerRecent; len(got) != 2 || got[0] != first || got[1] != second {
+		t.Fatalf("unexpected recent files: %#v", got)
+	}
+
+	pager.handleKey(keyEvent{kind: keyRecentFiles})
+	if !pager.showRecent || pager.recentCursor != 1 {
+		t.Fatalf("expected list with the previous file selected, got show=%v cursor=%d", pager.showRecent, pager.recentCursor)
+	}
+	if done := pager.handleKey(keyEvent{kind: keyRune, ch: '1'}); done || pager.SwitchTo() != "" {
+		t.Fatalf("picking the shown file should not switch")
+	}
+
+	pager.handleKey(keyEvent{kind: keyRecentFiles})
+	if done := pager.handleKey(keyEvent{kind: keyEnter}); !done || pager.SwitchTo() != second {
+		t.Fatalf("expected switch to %s, got done=%v switchTo=%q", second, done, pager.SwitchTo())
+	}
+}
+
+func TestPreviewPagerRecentFilesMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	state := &statepkg.AppState{
+		PreviewData: &statepkg.PreviewData{Name: "a.txt", TextLines: []string{"text"}},
+		PreviewPath: filepath.Join(dir, "a.txt"),
+		CurrentPath: dir,
+		PagerRecent: []string{filepath.Join(dir, "a.txt"), filepath.Join(dir, "gone.txt")},
+	}
+	pager, err := NewPreviewPager(state, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	pager.openRecent()
+	if done := pager.handleKey(keyEvent{kind: keyRune, ch: '2'}); done || pager.SwitchTo() != "" {
+		t.Fatalf("expected no switch to a missing file")
+	}
+	if !strings.Contains(pager.statusMessage, "gone.txt is gone") {
+		t.Fatalf("expected a gone status, got %q", pager.statusMessage)
+	}
+
+	state.PreviewData.Privileged = "sudo"
+	state.PagerRecent = nil
+	pager.noteRecentFile()
+	if len(state.PagerRecent) != 0 {
+		t.Fatalf("privileged content should not be listed: %#v", state.PagerRecent)
+	}
+}
+
+func TestPreviewPagerContrastStyles(t *testing.T) {
+	state := &statepkg.AppState{
+		PreviewData: &statepkg.PreviewData{Name: "a.txt", TextLines: []string{"text"}},
+		CurrentPath: ".",
+		MinContrast: 3,
+		Background:  statepkg.BackgroundDark,
+	}
+	pager, err := NewPreviewPager(state, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	if got := pager.contrastStyle(headerBarStyle); got != headerBarStyle {
+		t.Fatalf("readable header should stay, got %q", got)
+	}
+	got := pager.contrastStyle(scrollbarUnloadedStyle)
+	if got == scrollbarUnloadedStyle || !strings.HasPrefix(got, "\x1b[38;2;") {
+		t.Fatalf("expected the dim scrollbar color to be lifted, got %q", got)
+	}
+	if got := adjustSGRContrast("\x1b[48;5;238m\x1b[38;5;240m", pager.contrast); !strings.HasSuffix(got, ";48;5;238m") {
+		t.Fatalf("expected the background kept, got %q", got)
+	}
+
+	state.MinContrast = 0
+	pager, err = NewPreviewPager(state, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	if got := pager.contrastStyle(scrollbarUnloadedStyle); got != scrollbarUnloadedStyle {
+		t.Fatalf("expected styles unchanged when off, got %q", got)
+	}
+}
+
+func TestMarkdownReflowKeepsRowMetricsWhileWidthIsUnchanged(t *testing.T) {
+	paragraph := strings.TrimSpace(strings.Repeat("resizing reflows this paragraph ", 10))
+	source := []string{"# Title", "", paragraph}
+	segments, meta := statepkg.FormatMarkdownPreview(source, 80, 0, true)
+	preview := &statepkg.PreviewData{
+		Name:                     "notes.md",
+		TextLines:                source,
+		LineCount:                len(source),
+		FormattedKind:            "markdown",
+		FormattedSegments:        segments,
+		FormattedSegmentLineMeta: meta,
+	}
+	state := &statepkg.AppState{CurrentPath: t.TempDir(), PreviewData: preview, PreviewWrap: true}
+	pager, err := NewPreviewPager(state, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	pager.width = 40
+	pager.height = 20
+	pager.reflowMarkdownFormatted()
+	pager.ensureRowMetrics()
+	if len(pager.rowSpans) == 0 {
+		t.Fatalf("expected row metrics for the formatted view")
+	}
+	spans := pager.rowSpans
+
+	pager.reflowMarkdownFormatted()
+	if len(pager.rowSpans) == 0 || &pager.rowSpans[0] != &spans[0] {
+		t.Fatalf("expected the row metrics to be kept at the same width")
+	}
+
+	pager.width = 60
+	pager.reflowMarkdownFormatted()
+	if pager.rowSpans != nil {
+		t.Fatalf("expected a width change to drop the row metrics")
+	}
+	pager.ensureRowMetrics()
+	if pager.rowMetricsWidth != 60 {
+		t.Fatalf("row metrics width = %d, want 60", pager.rowMetricsWidth)
+	}
+}
+
+func TestCopyAllViaFileStreamsOrUsesRawFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.txt")
+	var builder strings.Builder
+	for i := 0; i < 300; i++ {
+		fmt.Fprintf(&builder, "line-%d\n", i)
+	}
+	if err := os.WriteFile(path, []byte(builder.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	source, err := newTextPagerSource(nil, path, &statepkg.PreviewData{TextTruncated: true})
+	if err != nil {
+		t.Fatalf("newTextPagerSource: %v", err)
+	}
+	defer source.Close()
+	source.chunkSize = 64
+
+	var out bytes.Buffer
+	if err := writeSourceLines(&out, source, strings.ToUpper); err != nil {
+		t.Fatalf("writeSourceLines: %v", err)
+	}
+	if want := strings.ToUpper(strings.TrimSuffix(builder.String(), "\n")); out.String() != want {
+		t.Fatalf("expected every line streamed, got %d bytes, want %d", out.Len(), len(want))
+	}
+
+	pager := &PreviewPager{rawTextSource: source}
+	if got, ok := pager.rawFilePath(); !ok || got != path {
+		t.Fatalf("rawFilePath = %q, %v; want the file itself in the raw view", got, ok)
+	}
+	pager.showFormatted = true
+	if _, ok := pager.rawFilePath(); ok {
+		t.Fatal("expected the formatted view to go through a temp file")
+	}
+	pager.showFormatted = false
+	source.compressed = true
+	if _, ok := pager.rawFilePath(); ok {
+		t.Fatal("expected decompressed text to go through a temp file")
+	}
+}
+
+func TestGlobalSearchHitOpensPagerAtMention(t *testing.T) {
+	dir := t.TempDir()
+	lines := make([]string, 60)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i)
+	}
+	lines[42] = "see notes here"
+	path := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	state := &statepkg.AppState{
+		CurrentPath:        dir,
+		ScreenWidth:        80,
+		ScreenHeight:       24,
+		GlobalSearchActive: true,
+		GlobalSearchQuery:  "notes",
+		GlobalSearchResults: []statepkg.GlobalSearchResult{
+			{FilePath: path, FileName: "notes.txt", DirPath: dir, FileEntry: statepkg.FileEntry{Name: "notes.txt"}},
+		},
+	}
+	reducer := statepkg.NewStateReducer()
+	if _, err := reducer.Reduce(state, statepkg.GlobalSearchOpenAction{}); err != nil {
+		t.Fatalf("open result: %v", err)
+	}
+
+	pager, err := NewPreviewPager(state, nil, reducer, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	pager.width = 40
+	pager.height = 10
+	pager.applySearchSeed()
+
+	if hit := pager.focusedHit(); hit == nil || hit.line != 42 {
+		t.Fatalf("expected the pager to open on line 42, got %+v", hit)
+	}
+	if state.PreviewScrollOffset == 0 {
+		t.Fatalf("expected the pager to scroll to the mention")
+	}
+}
+
+func TestQuickfixStdoutRefusedWhenEnterPrintsPaths(t *testing.T) {
+	preview := &statepkg.PreviewData{Name: "notes.txt", TextLines: []string{"todo: one"}, LineCount: 1}
+	state := &statepkg.AppState{
+		CurrentPath:  t.TempDir(),
+		Files:        []statepkg.FileEntry{{Name: "notes.txt"}},
+		PreviewData:  preview,
+		QuickfixFile: statepkg.QuickfixStdout,
+		Enter:        statepkg.EnterConfig{Default: statepkg.EnterPrint},
+	}
+	pager, err := NewPreviewPager(state, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPreviewPager: %v", err)
+	}
+	pager.width = 40
+	pager.height = 10
+	pager.executeSearch("todo")
+	pager.handleKey(keyEvent{kind: keyExportQuickfix, ch: 'o'})
+	if len(state.QuickfixOutput) != 0 {
+		t.Fatalf("expected nothing kept for stdout, got %q", state.QuickfixOutput)
+	}
+	if !strings.Contains(pager.statusMessage, "RDIR_ENTER=print") {
+		t.Fatalf("expected the status to explain the refusal, got %q", pager.statusMessage)
+	}
+}
@@ -0,0 +1,381 @@
+package pager
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+	renderpkg "github.com/kk-code-lab/rdir/internal/ui/render"
+)
+
+// tocEntry is one heading or top-level symbol of the table of contents.
+type tocEntry struct {
+	level int
+	title string
+	line  int
+}
+
+var (
+	markdownHeadingPattern = regexp.MustCompile(`^ {0,3}(#{1,6})[ \t]+(.*?)(?:[ \t]+#+)?[ \t]*$`)
+	markdownFencePattern   = regexp.MustCompile("^ {0,3}(```|~~~)")
+	formattedHeadingPrefix = ansiForStyle(statepkg.TextStyleHeading, renderpkg.GetColorTheme())
+)
+
+// tocSymbolPatterns lists the top-level declarations listed for code files,
+// keyed by extension. The first submatch is the symbol's name; a second one,
+// when present and non-empty, is a receiver or owner printed before it.
+var tocSymbolPatterns = map[string][]*regexp.Regexp{
+	".go": {
+		regexp.MustCompile(`^func\s+(?:\(\s*\w*\s*\*?([\w.]+)(?:\[[^\]]*\])?\s*\)\s*)?(\w+)`),
+		regexp.MustCompile(`^type\s+(\w+)`),
+	},
+	".py": {
+		regexp.MustCompile(`^(?:async\s+)?def\s+(\w+)`),
+		regexp.MustCompile(`^class\s+(\w+)`),
+	},
+	".rs": {
+		regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?(?:const\s+)?(?:async\s+)?(?:unsafe\s+)?(?:extern\s+"[^"]*"\s+)?fn\s+(\w+)`),
+		regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?(?:struct|enum|trait|union|mod)\s+(\w+)`),
+		regexp.MustCompile(`^impl(?:<[^>]*>)?\s+([^{]+?)\s*(?:\{|$)`),
+	},
+	".js": {
+		regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:async\s+)?function\s*\*?\s*(\w+)`),
+		regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?class\s+(\w+)`),
+		regexp.MustCompile(`^(?:export\s+)?(?:const|let|var)\s+(\w+)\s*=\s*(?:async\s+)?(?:function\b|\([^)]*\)\s*=>|\w+\s*=>)`),
+	},
+	".ts": {
+		regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:async\s+)?function\s*\*?\s*(\w+)`),
+		regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:abstract\s+)?class\s+(\w+)`),
+		regexp.MustCompile(`^(?:export\s+)?(?:interface|type|enum)\s+(\w+)`),
+		regexp.MustCompile(`^(?:export\s+)?(?:const|let|var)\s+(\w+)\s*(?::[^=]+)?=\s*(?:async\s+)?(?:function\b|\([^)]*\)\s*(?::[^=]+)?=>|\w+\s*=>)`),
+	},
+	".rb": {
+		regexp.MustCompile(`^(?:def|class|module)\s+([\w:.?!]+)`),
+	},
+	".sh": {
+		regexp.MustCompile(`^(?:function\s+)?([\w:-]+)\s*\(\)\s*\{?`),
+		regexp.MustCompile(`^function\s+([\w:-]+)`),
+	},
+}
+
+func init() {
+	for _, alias := range [][2]string{
+		{".mjs", ".js"}, {".cjs", ".js"}, {".jsx", ".js"},
+		{".tsx", ".ts"}, {".mts", ".ts"},
+		{".bash", ".sh"}, {".zsh", ".sh"},
+		{".pyw", ".py"},
+	} {
+		tocSymbolPatterns[alias[0]] = tocSymbolPatterns[alias[1]]
+	}
+}
+
+// tocSource names the kind of table of contents the current view supports:
+// "markdown", "code" or "" for none.
+func (p *PreviewPager) tocSource() string {
+	if p.state == nil || p.state.PreviewData == nil || p.binaryMode {
+		return ""
+	}
+	preview := p.state.PreviewData
+	if preview.FormattedKind == "markdown" {
+		return "markdown"
+	}
+	if p.showFormatted {
+		return ""
+	}
+	if _, ok := tocSymbolPatterns[strings.ToLower(filepath.Ext(preview.Name))]; ok {
+		return "code"
+	}
+	return ""
+}
+
+// tableOfContents returns the entries of the current view. They are
+// rebuilt when the view's lines change (format toggle, reading mode or
+// resize) and extended when more of a streamed file has loaded, scanning
+// only the new lines. Streamed files are only scanned as far as they have
+// been read unless load is set.
+func (p *PreviewPager) tableOfContents(load bool) []tocEntry {
+	source := p.tocSource()
+	if source == "" {
+		return nil
+	}
+	key := fmt.Sprintf("%s/%t/%d", source, p.showFormatted, p.width)
+	if p.state.PreviewReadingMode {
+		key += "/read"
+	}
+	if key != p.tocKey {
+		p.tocKey = key
+		p.resetTOC()
+	}
+	p.extendTOC(load)
+	return p.toc
+}
+
+// resetTOC drops the entries and starts a new scan of the current view.
+func (p *PreviewPager) resetTOC() {
+	p.toc = []tocEntry{}
+	p.tocScanned = 0
+	switch {
+	case p.showFormatted:
+		p.tocScan = formattedTOCScanner
+	case p.tocSource() == "markdown":
+		p.tocScan = markdownTOCScanner()
+	default:
+		p.tocScan = codeTOCScanner(tocSymbolPatterns[strings.ToLower(filepath.Ext(p.state.PreviewData.Name))])
+	}
+}
+
+// extendTOC scans the lines past the last one scanned, within the search
+// line limit. A source that lost lines, like a file whose unterminated last
+// line was appended to, is scanned again from the start.
+func (p *PreviewPager) extendTOC(load bool) {
+	lines, count := p.tocLineSource()
+	if count() < p.tocScanned {
+		p.resetTOC()
+	}
+	for i := p.tocScanned; i < searchMaxLines; i++ {
+		if load && p.rawTextSource != nil && !p.showFormatted {
+			if err := p.rawTextSource.EnsureLine(i); err != nil && !errors.Is(err, io.EOF) {
+				break
+			}
+		}
+		if i >= count() {
+			break
+		}
+		if entry, ok := p.tocScan(lines(i)); ok {
+			entry.line = i
+			p.toc = append(p.toc, entry)
+		}
+		p.tocScanned = i + 1
+	}
+}
+
+// tocLineSource returns the lines the contents are taken from: the formatted
+// view, the streamed file or the raw lines held in memory.
+func (p *PreviewPager) tocLineSource() (line func(int) string, count func() int) {
+	switch src := p.rawTextSource; {
+	case p.showFormatted:
+		return func(i int) string { return p.lines[i] }, func() int { return len(p.lines) }
+	case src != nil:
+		return src.Line, src.LineCount
+	default:
+		return func(i int) string { return p.rawLines[i] }, func() int { return len(p.rawLines) }
+	}
+}
+
+// formattedTOCScanner finds headings in the formatted markdown view, which
+// keeps the "## " marker and draws headings in the heading style.
+func formattedTOCScanner(line string) (tocEntry, bool) {
+	level, title, ok := tocFormattedHeading(line)
+	return tocEntry{level: level, title: title}, ok
+}
+
+func tocFormattedHeading(line string) (int, string, bool) {
+	line = strings.TrimLeft(line, " ")
+	if !strings.HasPrefix(line, formattedHeadingPrefix+"#") {
+		return 0, "", false
+	}
+	m := markdownHeadingPattern.FindStringSubmatch(stripANSICodes(line))
+	if m == nil {
+		return 0, "", false
+	}
+	return len(m[1]), m[2], true
+}
+
+// tocScanner reports whether a raw line starts an entry.
+type tocScanner func(line string) (tocEntry, bool)
+
+// markdownTOCScanner matches ATX headings outside fenced code blocks.
+func markdownTOCScanner() tocScanner {
+	fence := ""
+	return func(line string) (tocEntry, bool) {
+		if m := markdownFencePattern.FindStringSubmatch(line); m != nil {
+			switch fence {
+			case "":
+				fence = m[1]
+			case m[1]:
+				fence = ""
+			}
+			return tocEntry{}, false
+		}
+		if fence != "" {
+			return tocEntry{}, false
+		}
+		m := markdownHeadingPattern.FindStringSubmatch(line)
+		if m == nil || m[2] == "" {
+			return tocEntry{}, false
+		}
+		return tocEntry{level: len(m[1]), title: m[2]}, true
+	}
+}
+
+// codeTOCScanner matches unindented declarations. Go methods are listed as
+// "Receiver.Method".
+func codeTOCScanner(patterns []*regexp.Regexp) tocScanner {
+	return func(line string) (tocEntry, bool) {
+		if line == "" || line[0] == ' ' || line[0] == '\t' {
+			return tocEntry{}, false
+		}
+		for _, re := range patterns {
+			m := re.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			title := m[len(m)-1]
+			if len(m) > 2 && m[1] != "" {
+				title = m[1] + "." + title
+			}
+			return tocEntry{level: 1, title: strings.TrimSpace(title)}, true
+		}
+		return tocEntry{}, false
+	}
+}
+
+// currentTOCIndex is the entry the top visible line belongs to, or -1 above
+// the first one.
+func (p *PreviewPager) currentTOCIndex(entries []tocEntry) int {
+	if p.state == nil {
+		return -1
+	}
+	top := p.state.PreviewScrollOffset
+	current := -1
+	for i, entry := range entries {
+		if entry.line > top {
+			break
+		}
+		current = i
+	}
+	return current
+}
+
+// tocBreadcrumb joins the current entry with the headings it is nested
+// under, e.g. "Install › Linux".
+func (p *PreviewPager) tocBreadcrumb() string {
+	entries := p.tableOfContents(false)
+	current := p.currentTOCIndex(entries)
+	if current < 0 {
+		return ""
+	}
+	trail := []string{entries[current].title}
+	level := entries[current].level
+	for i := current - 1; i >= 0 && level > 1; i-- {
+		if entries[i].level < level {
+			trail = append([]string{entries[i].title}, trail...)
+			level = entries[i].level
+		}
+	}
+	return strings.Join(trail, " › ")
+}
+
+// openTOC (t) shows the table of contents with the current section selected.
+func (p *PreviewPager) openTOC() {
+	if p.tocSource() == "" {
+		p.setStatusMessage("no table of contents for this view", statusWarnStyle)
+		return
+	}
+	entries := p.tableOfContents(true)
+	if len(entries) == 0 {
+		p.setStatusMessage("no headings or symbols found", "")
+		return
+	}
+	p.showTOC = true
+	p.tocCursor = max(p.currentTOCIndex(entries), 0)
+}
+
+func (p *PreviewPager) handleTOCKey(ev keyEvent) bool {
+	entries := p.toc
+	switch ev.kind {
+	case keyCtrlC:
+		return true
+	case keyToggleTOC, keyQuit, keyEscape, keyLeft:
+		p.showTOC = false
+	case keyUp:
+		p.tocCursor--
+	case keyDown:
+		p.tocCursor++
+	case keyPageUp:
+		p.tocCursor -= p.tocRows()
+	case keyPageDown, keySpace:
+		p.tocCursor += p.tocRows()
+	case keyHome:
+		p.tocCursor = 0
+	case keyEnd:
+		p.tocCursor = len(entries) - 1
+	case keyEnter, keyRight:
+		p.showTOC = false
+		if p.tocCursor >= 0 && p.tocCursor < len(entries) {
+			p.state.PreviewScrollOffset = entries[p.tocCursor].line
+			p.state.PreviewWrapOffset = 0
+		}
+		return false
+	}
+	p.tocCursor = min(max(p.tocCursor, 0), max(len(entries)-1, 0))
+	return false
+}
+
+// tocRows is the number of entries the overlay shows at once.
+func (p *PreviewPager) tocRows() int {
+	return max(p.height-2, 1)
+}
+
+// tocLines formats the visible part of the contents, indented by level.
+func (p *PreviewPager) tocLines() []string {
+	rows := p.tocRows()
+	first := 0
+	if p.tocCursor >= rows {
+		first = p.tocCursor - rows + 1
+	}
+	minLevel := 6
+	for _, entry := range p.toc {
+		minLevel = min(minLevel, entry.level)
+	}
+	lines := make([]string, 0, rows)
+	for i := first; i < len(p.toc) && len(lines) < rows; i++ {
+		entry := p.toc[i]
+		marker := "  "
+		if i == p.tocCursor {
+			marker = "› "
+		}
+		indent := strings.Repeat("  ", max(entry.level-minLevel, 0))
+		line := fmt.Sprintf("%s%6d  %s%s", marker, entry.line+1, indent, textutil.SanitizeTerminalText(entry.title))
+		if p.width > 0 {
+			line = truncateToWidth(line, p.width)
+		}
+		if i == p.tocCursor {
+			line = searchHighlightFocusOn + line + searchHighlightFocusOff
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (p *PreviewPager) renderTOC() error {
+	p.writeString("\x1b[?25l")
+	p.writeString("\x1b[2J")
+	p.writeString("\x1b[H")
+
+	title := " Contents"
+	if p.tocSource() == "code" {
+		title = " Symbols"
+	}
+	p.drawStyledRow(1, fmt.Sprintf("%s (%d) ", title, len(p.toc)), true, headerBarStyle)
+	row := 2
+	for _, line := range p.tocLines() {
+		p.drawRow(row, line, false)
+		row++
+	}
+	for row < p.height {
+		p.drawRow(row, "", false)
+		row++
+	}
+	p.drawStatus("↑/↓ select  ·  Enter jump  ·  q/Esc/t close")
+
+	if p.writer != nil {
+		return p.writer.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package pager
+
+import (
+	"hash/crc32"
+	"io"
+	"os"
+	"time"
+
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+)
+
+const (
+	fileWatchInterval    = time.Second
+	fileWatchEcoInterval = 5 * time.Second
+	// fileWatchWindow is how much of the start and of the end of the
+	// content already read is checksummed to tell an append from a rewrite.
+	fileWatchWindow = 64 * 1024
+)
+
+// fileFingerprint identifies the content the pager has read: its size and
+// modification time, plus checksums of its first and last bytes.
+type fileFingerprint struct {
+	size    int64
+	modTime time.Time
+	head    uint32
+	tail    uint32
+}
+
+func fingerprintFile(fsys fsutil.FS, path string, size int64, modTime time.Time) (*fileFingerprint, error) {
+	file, err := fsys.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	head, err := checksumRange(file, 0, min(size, fileWatchWindow))
+	if err != nil {
+		return nil, err
+	}
+	tailStart := max(size-fileWatchWindow, 0)
+	tail, err := checksumRange(file, tailStart, size-tailStart)
+	if err != nil {
+		return nil, err
+	}
+	return &fileFingerprint{size: size, modTime: modTime, head: head, tail: tail}, nil
+}
+
+func checksumRange(file io.ReaderAt, offset, length int64) (uint32, error) {
+	buf := make([]byte, length)
+	n, err := file.ReadAt(buf, offset)
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	if int64(n) < length {
+		// The file shrank under us; no checksum can match.
+		return 0, io.ErrUnexpectedEOF
+	}
+	return crc32.ChecksumIEEE(buf), nil
+}
+
+// startFileWatch returns a ticker for checkFileChange while the content is
+// plain text read from a file.
+func (p *PreviewPager) startFileWatch() *time.Ticker {
+	if _, ok := p.watchedFile(); !ok {
+		return nil
+	}
+	interval := fileWatchInterval
+	if p.state != nil && p.state.EcoActive {
+		interval = fileWatchEcoInterval
+	}
+	return time.NewTicker(interval)
+}
+
+// watchedFile returns the path of the file behind the raw text content.
+// Formatted views, binary previews, listings and content read through the
+// privileged helper are not watched.
+func (p *PreviewPager) watchedFile() (string, bool) {
+	if p == nil || p.binaryMode || p.state == nil || p.state.PreviewData == nil {
+		return "", false
+	}
+	preview := p.state.PreviewData
+	if preview.IsDir || len(p.formattedLines) > 0 || preview.Privileged != "" || preview.Compressed != nil {
+		return "", false
+	}
+	if p.rawTextSource == nil {
+		if len(preview.TextLines) == 0 || len(preview.TextLineMeta) != len(preview.TextLines) {
+			return "", false
+		}
+	} else if p.rawTextSource.generate != nil {
+		return "", false
+	}
+	return p.filePath(), true
+}
+
+// readSize is how many bytes of the file the content covers, or -1 while a
+// text source is still streaming the file (appends are read then anyway).
+func (p *PreviewPager) readSize() int64 {
+	if p.rawTextSource != nil {
+		if !p.rawTextSource.FullyLoaded() {
+			return -1
+		}
+		return p.rawTextSource.nextOffset
+	}
+	if p.state.PreviewData.TextTruncated {
+		return -1
+	}
+	return p.state.PreviewData.TextBytesRead
+}
+
+// checkFileChange looks for changes to the file on disk and reports
+// whether the content changed. When the file grew and the checksummed
+// start and end of what was read are unchanged, it was appended to: the
+// text source resumes reading in place, so the scroll position and search
+// hits stay put. Any other change reloads the file from the start.
+func (p *PreviewPager) checkFileChange() bool {
+	path, ok := p.watchedFile()
+	if !ok {
+		return false
+	}
+	size := p.readSize()
+	if size < 0 {
+		return false
+	}
+	fsys := fsutil.OrLocal(p.state.FS)
+	info, err := fsys.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if p.fingerprint == nil || p.fingerprint.size != size {
+		p.fingerprint, _ = fingerprintFile(fsys, path, size, info.ModTime())
+		return false
+	}
+	if info.Size() == size && info.ModTime().Equal(p.fingerprint.modTime) {
+		return false
+	}
+	current, err := fingerprintFile(fsys, path, size, info.ModTime())
+	unchanged := err == nil && current.head == p.fingerprint.head && current.tail == p.fingerprint.tail
+	switch {
+	case unchanged && info.Size() == size:
+		// Touched, or rewritten with the same start and end; keep it.
+		p.fingerprint = current
+		return false
+	case unchanged && info.Size() > size:
+		if p.resumeAppended(path) {
+			p.updatePreviewStat(info)
+			return true
+		}
+	}
+	p.reloadFile(path)
+	p.updatePreviewStat(info)
+	return true
+}
+
+// resumeAppended extends the text source with the appended bytes, first
+// streaming in-memory text from the file so it can grow.
+func (p *PreviewPager) resumeAppended(path string) bool {
+	source := p.rawTextSource
+	if source == nil {
+		var err error
+		if source, err = newTextPagerSource(p.state.FS, path, p.state.PreviewData); err != nil {
+			return false
+		}
+	}
+	if err := source.resumeAfterAppend(); err != nil {
+		return false
+	}
+	if source != p.rawTextSource {
+		p.useTextSource(source)
+	}
+	p.contentGrew()
+	return true
+}
+
+// reloadFile streams the file again from its first byte. The scroll
+// position is kept when the file is still long enough and the search runs
+// again on the new text.
+func (p *PreviewPager) reloadFile(path string) {
+	encoding := p.state.PreviewData.TextEncoding
+	if p.rawTextSource != nil {
+		encoding = p.rawTextSource.encoding
+		p.rawTextSource.Close()
+	}
+	p.useTextSource(&textPagerSource{
+		fsys:          p.state.FS,
+		path:          path,
+		encoding:      encoding,
+		chunkSize:     textPagerChunkSize,
+		cache:         make(map[int]string),
+		maxCacheLines: textPagerCacheLines,
+	})
+	p.fingerprint = nil
+	p.inspect = nil
+	p.contentGrew()
+
+	offset := p.state.PreviewScrollOffset
+	_ = p.rawTextSource.EnsureLine(offset)
+	if count := p.rawTextSource.LineCount(); offset >= count {
+		p.state.PreviewScrollOffset = max(count-1, 0)
+		p.state.PreviewWrapOffset = 0
+	}
+	if p.searchQuery != "" {
+		p.executeSearch(p.searchQuery)
+	} else {
+		p.clearSearchResults()
+	}
+	p.setStatusMessage("file changed on disk; reloaded", statusWarnStyle)
+}
+
+// contentGrew drops layout caches that assume the old line count.
+func (p *PreviewPager) contentGrew() {
+	p.charCount = p.rawTextSource.CharCount()
+	p.rowSpans = nil
+	p.rowPrefix = nil
+	p.resetWrapCache()
+	p.refreshSplitPane(false)
+}
+
+func (p *PreviewPager) updatePreviewStat(info os.FileInfo) {
+	p.state.PreviewData.Size = info.Size()
+	p.state.PreviewData.Modified = info.ModTime()
+}
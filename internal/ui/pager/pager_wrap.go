@@ -207,12 +207,10 @@ func nextWrapSegment(text string, start int, width int) (string, int) {
 	index := start
 	var b strings.Builder
 	g := uniseg.NewGraphemes(text[start:])
+	sgrLeft := 0
 	for g.Next() {
 		cluster := g.Str()
-		w := textutil.DisplayWidth(cluster)
-		if w <= 0 {
-			w = 1
-		}
+		w := wrapClusterWidth(text[index:], cluster, &sgrLeft)
 		if consumed+w > width && consumed > 0 {
 			break
 		}
@@ -495,13 +493,11 @@ func wrapLineSegments(text string, width int) []string {
 	for len(text) > 0 {
 		consumed := 0
 		index := 0
+		sgrLeft := 0
 		g := uniseg.NewGraphemes(text)
 		for g.Next() {
 			cluster := g.Str()
-			w := textutil.DisplayWidth(cluster)
-			if w <= 0 {
-				w = 1
-			}
+			w := wrapClusterWidth(text[index:], cluster, &sgrLeft)
 			if consumed+w > width {
 				if consumed == 0 {
 					index += len(cluster)
@@ -557,12 +553,12 @@ func wrapLineSegmentsRange(text string, width int, skipRows int, maxRows int) []
 	}
 
 	g := uniseg.NewGraphemes(text)
+	pos := 0
+	sgrLeft := 0
 	for g.Next() {
 		cluster := g.Str()
-		w := textutil.DisplayWidth(cluster)
-		if w <= 0 {
-			w = 1
-		}
+		w := wrapClusterWidth(text[pos:], cluster, &sgrLeft)
+		pos += len(cluster)
 		if consumed+w > width && consumed > 0 {
 			flush()
 			if maxRows > 0 && len(out) >= maxRows {
@@ -586,3 +582,24 @@ func wrapLineSegmentsRange(text string, width int, skipRows int, maxRows int) []
 	}
 	return out
 }
+
+// wrapClusterWidth is the column width of cluster, which starts rest. Bytes of
+// an "ESC [ ... m" sequence are zero-width, tracked through sgrLeft, so the
+// wrap loops never split a color sequence or count it as text.
+func wrapClusterWidth(rest, cluster string, sgrLeft *int) int {
+	if *sgrLeft == 0 {
+		*sgrLeft = ansiSequenceLen(rest)
+	}
+	if *sgrLeft > 0 {
+		*sgrLeft -= len(cluster)
+		if *sgrLeft < 0 {
+			*sgrLeft = 0
+		}
+		return 0
+	}
+	w := textutil.DisplayWidth(cluster)
+	if w <= 0 {
+		w = 1
+	}
+	return w
+}
@@ -5,13 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
 	"sort"
 	"strings"
 	"time"
 	"unicode"
 	"unicode/utf8"
 
+	"github.com/kk-code-lab/rdir/internal/lineedit"
 	textutil "github.com/kk-code-lab/rdir/internal/textutil"
 	"github.com/rivo/uniseg"
 )
@@ -28,6 +28,7 @@ type searchHit struct {
 	startByte int
 	nibbleEnd bool
 	nibblePos int
+	mark      rune // formatting character of a hidden-formatting hit
 }
 
 func fastIndex(haystack, needle []byte) int {
@@ -205,6 +206,9 @@ func (p *PreviewPager) searchDisplaySegment() (string, int) {
 	if p == nil {
 		return "", 0
 	}
+	if p.hiddenScanActive() && !p.searchMode {
+		return p.hiddenSegment(), 0
+	}
 	displayRaw := p.searchQuery
 	binary := false
 	if p.searchMode {
@@ -238,6 +242,11 @@ func (p *PreviewPager) searchDisplaySegment() (string, int) {
 	safeQuery := textutil.SanitizeTerminalText(visibleQuery)
 	segment := prefix + safeQuery
 	cursorCol := displayWidth(segment) + 1
+	if p.searchMode && p.searchTail > 0 {
+		_, line := p.searchLine()
+		head := string(line.Text[:line.Cursor])
+		cursorCol = displayWidth(prefix+textutil.SanitizeTerminalText(visualizeSpaces(head))) + 1
+	}
 
 	activeQuery := p.searchQuery
 	matchInput := displayRaw
@@ -703,6 +712,10 @@ func (p *PreviewPager) enterSearchModeWithPreset(binary bool, preset []rune) {
 	p.searchMode = true
 	p.searchBinaryMode = binary
 	p.searchFullScan = false
+	if p.hiddenScanActive() {
+		p.searchQuery = ""
+		p.clearSearchResults()
+	}
 	if len(preset) > 0 && (len(p.searchQuery) == 0 || p.searchQueryBinary != binary) {
 		p.searchInput = append([]rune(nil), preset...)
 	} else if len(p.searchQuery) > 0 && p.searchQueryBinary == binary {
@@ -710,6 +723,7 @@ func (p *PreviewPager) enterSearchModeWithPreset(binary bool, preset []rune) {
 	} else {
 		p.searchInput = nil
 	}
+	p.searchTail = 0
 	p.searchErr = nil
 }
 
@@ -718,6 +732,7 @@ func (p *PreviewPager) exitSearchMode() {
 	p.searchBinaryMode = false
 	p.searchFullScan = false
 	p.searchInput = nil
+	p.searchTail = 0
 	p.stopSearchTimer()
 }
 
@@ -726,6 +741,7 @@ func (p *PreviewPager) cancelSearch() {
 	p.searchBinaryMode = false
 	p.searchFullScan = false
 	p.searchInput = nil
+	p.searchTail = 0
 	p.stopSearchTimer()
 	p.searchQuery = ""
 	p.searchQueryBinary = false
@@ -762,6 +778,33 @@ func (p *PreviewPager) toggleSearchLimit() {
 	p.onSearchInputChanged()
 }
 
+// searchLine returns the ':' that marks a binary query, which editing
+// leaves alone, and the rest of the search input with its cursor.
+func (p *PreviewPager) searchLine() (string, lineedit.Line) {
+	input := string(p.searchInput)
+	prefix := ""
+	if p.searchBinaryMode && strings.HasPrefix(input, ":") {
+		prefix, input = ":", input[1:]
+	}
+	return prefix, lineedit.FromTail(input, p.searchTail)
+}
+
+func (p *PreviewPager) setSearchLine(prefix string, line lineedit.Line) {
+	p.searchInput = []rune(prefix + line.String())
+	p.searchTail = line.Tail()
+}
+
+// editSearch applies a readline-style edit to the search input.
+func (p *PreviewPager) editSearch(op lineedit.Op) {
+	prefix, line := p.searchLine()
+	changed := line.Apply(op)
+	p.setSearchLine(prefix, line)
+	if changed {
+		p.onSearchInputChanged()
+	}
+}
+
+// appendSearchRune types ch, and anything pasted with it, at the cursor.
 func (p *PreviewPager) appendSearchRune(ch rune) {
 	if ch == 0 {
 		return
@@ -769,36 +812,40 @@ func (p *PreviewPager) appendSearchRune(ch rune) {
 	if p.searchMode && p.searchBinaryMode && (len(p.searchInput) == 0 || (len(p.searchInput) > 0 && p.searchInput[0] != ':')) {
 		p.searchInput = append([]rune{':'}, p.searchInput...)
 	}
-	p.searchInput = append(p.searchInput, ch)
-	p.drainSearchBuffer()
+	prefix, line := p.searchLine()
+	line.Insert(append([]rune{ch}, p.drainSearchBuffer()...)...)
+	p.setSearchLine(prefix, line)
 	p.onSearchInputChanged()
 }
 
-// drainSearchBuffer pulls buffered runes (e.g., from a paste burst) into the search input
+// drainSearchBuffer pulls buffered runes (e.g., from a paste burst) for the search input
 // without blocking; stops if an escape sequence prefix is encountered.
-func (p *PreviewPager) drainSearchBuffer() {
+func (p *PreviewPager) drainSearchBuffer() []rune {
 	if p == nil || !p.searchMode || p.reader == nil {
-		return
+		return nil
 	}
+	var runes []rune
 	for p.reader.Buffered() > 0 {
-		r, _, err := p.reader.ReadRune()
+		// ReadRune would block for the rest of a sequence still in flight.
+		if buffered, _ := p.reader.Peek(p.reader.Buffered()); !utf8.FullRune(buffered) {
+			break
+		}
+		r, size, err := p.reader.ReadRune()
 		if err != nil {
-			return
+			break
 		}
-		if r < 32 || r == '\x1b' {
+		// Malformed UTF-8 is left for readKeyEvent to drop.
+		if r < 32 || r == '\x1b' || (r == utf8.RuneError && size == 1) {
 			_ = p.reader.UnreadRune()
-			return
+			break
 		}
-		p.searchInput = append(p.searchInput, r)
+		runes = append(runes, r)
 	}
+	return runes
 }
 
 func (p *PreviewPager) backspaceSearch() {
-	if len(p.searchInput) == 0 {
-		return
-	}
-	p.searchInput = p.searchInput[:len(p.searchInput)-1]
-	p.onSearchInputChanged()
+	p.editSearch(lineedit.Backspace)
 }
 
 func (p *PreviewPager) clearSearchResults() {
@@ -895,6 +942,8 @@ func (p *PreviewPager) executeSearch(query string) {
 	if binaryEngine {
 		hits, highlights, limited, err = p.collectBinarySearchMatches(query, fullScan)
 		p.searchQueryFullScan = fullScan
+	} else if query == hiddenFormattingQuery {
+		hits, highlights, limited, err = p.collectHiddenFormatting()
 	} else {
 		hits, highlights, limited, err = p.collectSearchMatches(query)
 	}
@@ -970,15 +1019,11 @@ func (p *PreviewPager) collectBinarySearchMatches(query string, fullScan bool) (
 		needleFolded = foldASCIIBytes(needle)
 	}
 
-	file := p.binarySource.file
-	if file == nil {
-		f, openErr := os.Open(p.binarySource.path)
-		if openErr != nil {
-			return nil, nil, false, openErr
-		}
-		file = f
-		defer func() { _ = file.Close() }()
+	file, release, openErr := p.binarySource.readerAt()
+	if openErr != nil {
+		return nil, nil, false, openErr
 	}
+	defer release()
 
 	hits := []searchHit{}
 	highlights := make(map[int][]textSpan)
@@ -1102,14 +1147,15 @@ func (p *PreviewPager) appendBinarySearchHighlightsForVisibleRange(highlights ma
 	}
 
 	// Reconstruct how many content rows are visible.
+	height := p.viewHeight()
 	headerRows := len(p.headerLines())
-	if headerRows >= p.height {
-		headerRows = p.height - 1
+	if headerRows >= height {
+		headerRows = height - 1
 		if headerRows < 0 {
 			headerRows = 0
 		}
 	}
-	available := p.height - headerRows - 1
+	available := height - headerRows - 1
 	if available < 1 {
 		available = 1
 	}
@@ -1156,19 +1202,11 @@ func (p *PreviewPager) appendBinarySearchHighlightsForVisibleRange(highlights ma
 		return nil
 	}
 
-	file := p.binarySource.file
-	closeFile := false
-	if file == nil {
-		f, err := os.Open(p.binarySource.path)
-		if err != nil {
-			return nil
-		}
-		file = f
-		closeFile = true
-	}
-	if closeFile {
-		defer func() { _ = file.Close() }()
+	file, release, err := p.binarySource.readerAt()
+	if err != nil {
+		return nil
 	}
+	defer release()
 
 	buf := make([]byte, readLen)
 	n, err := file.ReadAt(buf, startByte)
@@ -1275,7 +1313,7 @@ func (p *PreviewPager) searchStreaming(needle []byte) ([]searchHit, map[int][]te
 		if i >= src.LineCount() {
 			break
 		}
-		line := textutil.SanitizeTerminalText(src.Line(i))
+		line := p.sanitizeRawLine(src.Line(i))
 		spans := literalMatchSpans(line, needle, searchMaxHits-len(hits), caseInsensitive)
 		if len(spans) > 0 {
 			highlights[i] = spans
@@ -1342,6 +1380,35 @@ func (p *PreviewPager) searchStatic(needle []byte) ([]searchHit, map[int][]textS
 	return hits, highlights, limited, nil
 }
 
+// applySearchSeed runs a search handed over by the caller (see
+// AppState.SeedPagerSearch) and focuses the first hit at or after the seeded
+// line, so the pager opens on the match with it highlighted.
+func (p *PreviewPager) applySearchSeed() {
+	if p == nil || p.state == nil || p.binaryMode {
+		return
+	}
+	seed, ok := p.state.TakePagerSearchSeed(p.filePath())
+	if !ok {
+		return
+	}
+	previous := p.searchQuery
+	p.executeSearch(seed.Query)
+	if len(p.searchHits) == 0 {
+		// The file does not mention the query: keep the remembered search.
+		p.executeSearch(previous)
+		return
+	}
+	idx := 0
+	for i, hit := range p.searchHits {
+		if hit.line >= seed.Line {
+			idx = i
+			break
+		}
+	}
+	p.searchCursor = idx
+	p.focusSearchHit(idx)
+}
+
 func (p *PreviewPager) moveSearchCursor(delta int) {
 	if len(p.searchHits) == 0 {
 		return
@@ -1357,10 +1424,17 @@ func (p *PreviewPager) moveSearchCursor(delta int) {
 		}
 		p.searchFocused = true
 	}
-	p.searchCursor = (p.searchCursor + delta) % len(p.searchHits)
-	if p.searchCursor < 0 {
-		p.searchCursor += len(p.searchHits)
+	next := p.searchCursor + delta
+	if next < 0 || next >= len(p.searchHits) {
+		if p.state != nil && !p.state.Wrap.Pager {
+			return
+		}
+		next %= len(p.searchHits)
+		if next < 0 {
+			next += len(p.searchHits)
+		}
 	}
+	p.searchCursor = next
 	p.focusSearchHit(p.searchCursor)
 }
 
@@ -1374,14 +1448,15 @@ func (p *PreviewPager) focusSearchHit(idx int) {
 		_ = p.rawTextSource.EnsureLine(hit.line)
 	}
 
+	height := p.viewHeight()
 	headerRows := len(p.headerLines())
-	if headerRows >= p.height {
-		headerRows = p.height - 1
+	if headerRows >= height {
+		headerRows = height - 1
 		if headerRows < 0 {
 			headerRows = 0
 		}
 	}
-	contentRows := p.height - headerRows - 1
+	contentRows := height - headerRows - 1
 	if contentRows < 1 {
 		contentRows = 1
 	}
@@ -1444,14 +1519,15 @@ func (p *PreviewPager) hitVisible(hit searchHit) bool {
 		return false
 	}
 
+	height := p.viewHeight()
 	headerRows := len(p.headerLines())
-	if headerRows >= p.height {
-		headerRows = p.height - 1
+	if headerRows >= height {
+		headerRows = height - 1
 		if headerRows < 0 {
 			headerRows = 0
 		}
 	}
-	contentRows := p.height - headerRows - 1
+	contentRows := height - headerRows - 1
 	if contentRows < 1 {
 		contentRows = 1
 	}
@@ -2,10 +2,11 @@ package pager
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
-	"os"
+	"strings"
 	"unicode/utf8"
 
 	fsutil "github.com/kk-code-lab/rdir/internal/fs"
@@ -17,13 +18,22 @@ import (
 const (
 	textPagerChunkSize  = 128 * 1024
 	textPagerCacheLines = 512
+
+	// compressedPagerLimit bounds how much of a compressed file is
+	// decompressed (into memory, as far as it is read) for the pager and its
+	// search.
+	compressedPagerLimit = 32 << 20
 )
 
 type textPagerSource struct {
+	fsys          fsutil.FS // where path is read; nil is the local filesystem
 	path          string
 	encoding      fsutil.UnicodeEncoding
 	chunkSize     int
-	file          *os.File
+	file          io.ReaderAt
+	reader        io.ReaderAt // content printed by the privileged helper, used instead of path
+	compressed    bool        // path is compressed; its decompressed head is read instead
+	head          *fsutil.DecompressReader
 	lines         []textLineRecord
 	cache         map[int]string
 	cacheOrder    []int
@@ -34,6 +44,12 @@ type textPagerSource struct {
 	eof           bool
 	bomHandled    bool
 	charCount     int
+	keepSGR       bool
+
+	// generate produces line idx of a listing that is not backed by a file
+	// (see newDirPagerSource); generatedCount is its fixed line count.
+	generate       func(idx int) string
+	generatedCount int
 }
 
 type textLineRecord struct {
@@ -41,14 +57,16 @@ type textLineRecord struct {
 	length       int
 	runeCount    int
 	displayWidth int
+	sgrWidth     int // width with SGR color sequences rendered instead of shown
 }
 
-func newTextPagerSource(path string, preview *statepkg.PreviewData) (*textPagerSource, error) {
+func newTextPagerSource(fsys fsutil.FS, path string, preview *statepkg.PreviewData) (*textPagerSource, error) {
 	if preview == nil {
 		return nil, errors.New("missing preview data")
 	}
 
 	source := &textPagerSource{
+		fsys:          fsys,
 		path:          path,
 		encoding:      preview.TextEncoding,
 		chunkSize:     textPagerChunkSize,
@@ -56,6 +74,8 @@ func newTextPagerSource(path string, preview *statepkg.PreviewData) (*textPagerS
 		maxCacheLines: textPagerCacheLines,
 		bomHandled:    preview.TextBytesRead > 0,
 		nextOffset:    preview.TextBytesRead,
+		reader:        preview.Reader,
+		compressed:    preview.Compressed != nil,
 	}
 
 	for i, line := range preview.TextLines {
@@ -68,6 +88,7 @@ func newTextPagerSource(path string, preview *statepkg.PreviewData) (*textPagerS
 			length:       meta.Length,
 			runeCount:    meta.RuneCount,
 			displayWidth: meta.DisplayWidth,
+			sgrWidth:     sgrVisibleWidth(line, meta.DisplayWidth),
 		}
 		source.lines = append(source.lines, record)
 		source.charCount += meta.RuneCount
@@ -86,14 +107,78 @@ func newTextPagerSource(path string, preview *statepkg.PreviewData) (*textPagerS
 	return source, nil
 }
 
+// Close closes the file behind the source, stopping the decompression of a
+// compressed file when the pager leaves it.
 func (s *textPagerSource) Close() {
 	if s == nil || s.file == nil {
 		return
 	}
-	_ = s.file.Close()
+	// The helper's reader belongs to whoever started the helper.
+	if closer, ok := s.file.(io.Closer); ok && s.file != s.reader {
+		_ = closer.Close()
+	}
 	s.file = nil
 }
 
+// openFile opens the file behind the source unless it is open already.
+func (s *textPagerSource) openFile() error {
+	if s.file != nil {
+		return nil
+	}
+	if s.reader != nil {
+		s.file = s.reader
+		return nil
+	}
+	if s.compressed {
+		head, err := fsutil.OpenDecompressed(context.Background(), s.path, compressedPagerLimit)
+		if err != nil {
+			return err
+		}
+		s.file, s.head = head, head
+		return nil
+	}
+	file, err := fsutil.OrLocal(s.fsys).Open(s.path)
+	if err != nil {
+		return err
+	}
+	s.file = file
+	return nil
+}
+
+// truncatedHead reports whether the decompressed head stopped at
+// compressedPagerLimit.
+func (s *textPagerSource) truncatedHead() bool {
+	return s.head != nil && s.head.Truncated()
+}
+
+// resumeAfterAppend lets a source that read its file to the end continue
+// with bytes appended since, keeping the lines it has. A last line without
+// a newline becomes the partial line again, as the append may continue it.
+func (s *textPagerSource) resumeAfterAppend() error {
+	if s == nil || s.generate != nil || !s.eof {
+		return nil
+	}
+	if n := len(s.lines); n > 0 && len(s.partialLine) == 0 {
+		last := s.lines[n-1]
+		if last.offset+int64(last.length) == s.nextOffset {
+			if err := s.openFile(); err != nil {
+				return err
+			}
+			buf := make([]byte, last.length)
+			if _, err := s.file.ReadAt(buf, last.offset); err != nil && err != io.EOF {
+				return err
+			}
+			s.lines = s.lines[:n-1]
+			s.charCount -= last.runeCount
+			s.uncacheLine(n - 1)
+			s.partialLine = buf
+			s.partialOffset = last.offset
+		}
+	}
+	s.eof = false
+	return nil
+}
+
 func (s *textPagerSource) CharCount() int {
 	if s == nil {
 		return 0
@@ -112,6 +197,9 @@ func (s *textPagerSource) LineCount() int {
 	if s == nil {
 		return 0
 	}
+	if s.generate != nil {
+		return s.generatedCount
+	}
 	return len(s.lines)
 }
 
@@ -158,7 +246,7 @@ func (s *textPagerSource) Line(idx int) string {
 	if err := s.EnsureLine(idx); err != nil {
 		return fmt.Sprintf("(error reading file: %v)", err)
 	}
-	if idx >= len(s.lines) {
+	if idx >= s.LineCount() {
 		return ""
 	}
 	if text, ok := s.cache[idx]; ok {
@@ -176,15 +264,30 @@ func (s *textPagerSource) LineWidth(idx int) int {
 	if s == nil || idx < 0 {
 		return 0
 	}
+	if s.generate != nil {
+		return displayWidth(s.Line(idx))
+	}
 	if err := s.EnsureLine(idx); err != nil {
 		return 0
 	}
 	if idx >= len(s.lines) {
 		return 0
 	}
+	if s.keepSGR {
+		return s.lines[idx].sgrWidth
+	}
 	return s.lines[idx].displayWidth
 }
 
+// sgrVisibleWidth is the width of text once its SGR sequences are rendered
+// as colors; width is the plain display width already measured.
+func sgrVisibleWidth(text string, width int) int {
+	if strings.IndexByte(text, '\x1b') == -1 {
+		return width
+	}
+	return textutil.DisplayWidth(textutil.StripSGR(text))
+}
+
 func (s *textPagerSource) readChunk() error {
 	if s == nil || s.eof {
 		return io.EOF
@@ -192,12 +295,8 @@ func (s *textPagerSource) readChunk() error {
 	if s.encoding == fsutil.EncodingUTF16LE || s.encoding == fsutil.EncodingUTF16BE {
 		return s.readChunkUTF16()
 	}
-	if s.file == nil {
-		file, err := os.Open(s.path)
-		if err != nil {
-			return err
-		}
-		s.file = file
+	if err := s.openFile(); err != nil {
+		return err
 	}
 
 	buf := make([]byte, s.chunkSize)
@@ -268,12 +367,8 @@ func (s *textPagerSource) readChunkUTF16() error {
 	if s == nil || s.eof {
 		return io.EOF
 	}
-	if s.file == nil {
-		file, err := os.Open(s.path)
-		if err != nil {
-			return err
-		}
-		s.file = file
+	if err := s.openFile(); err != nil {
+		return err
 	}
 
 	buf := make([]byte, s.chunkSize)
@@ -371,6 +466,7 @@ func (s *textPagerSource) appendLine(lineBytes []byte, start int64) {
 		length:       len(lineBytes),
 		runeCount:    runes,
 		displayWidth: width,
+		sgrWidth:     sgrVisibleWidth(expanded, width),
 	}
 	s.lines = append(s.lines, record)
 	s.charCount += runes
@@ -400,6 +496,7 @@ func (s *textPagerSource) appendLineUTF16(lineBytes []byte, start int64) {
 		length:       len(lineBytes),
 		runeCount:    runes,
 		displayWidth: width,
+		sgrWidth:     sgrVisibleWidth(expanded, width),
 	}
 	s.lines = append(s.lines, record)
 	s.charCount += runes
@@ -407,12 +504,11 @@ func (s *textPagerSource) appendLineUTF16(lineBytes []byte, start int64) {
 }
 
 func (s *textPagerSource) readLineText(idx int) (string, error) {
-	if s.file == nil {
-		file, err := os.Open(s.path)
-		if err != nil {
-			return "", err
-		}
-		s.file = file
+	if s.generate != nil {
+		return s.generate(idx), nil
+	}
+	if err := s.openFile(); err != nil {
+		return "", err
 	}
 	record := s.lines[idx]
 	if record.length <= 0 {
@@ -457,6 +553,16 @@ func (s *textPagerSource) cacheLine(idx int, text string) {
 	}
 }
 
+func (s *textPagerSource) uncacheLine(idx int) {
+	delete(s.cache, idx)
+	for i, v := range s.cacheOrder {
+		if v == idx {
+			s.cacheOrder = append(s.cacheOrder[:i], s.cacheOrder[i+1:]...)
+			break
+		}
+	}
+}
+
 func isUTF16LF(lo, hi byte, enc fsutil.UnicodeEncoding) bool {
 	if enc == fsutil.EncodingUTF16BE {
 		return lo == 0x00 && hi == 0x0A
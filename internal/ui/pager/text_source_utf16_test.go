@@ -56,7 +56,7 @@ func TestTextPagerSource_UTF16_CRLF(t *testing.T) {
 	preview.TextBytesRead = int64(len(content))
 	preview.TextRemainder = rem
 
-	src, err := newTextPagerSource(path, preview)
+	src, err := newTextPagerSource(nil, path, preview)
 	if err != nil {
 		t.Fatalf("newTextPagerSource: %v", err)
 	}
@@ -108,7 +108,7 @@ func TestTextPagerSource_UTF16_CRLF_BE(t *testing.T) {
 		TextRemainder: rem,
 	}
 
-	src, err := newTextPagerSource(path, preview)
+	src, err := newTextPagerSource(nil, path, preview)
 	if err != nil {
 		t.Fatalf("newTextPagerSource: %v", err)
 	}
@@ -230,7 +230,7 @@ func runUTF16StreamingTest(t *testing.T, enc fsutil.UnicodeEncoding) {
 		HiddenFormattingDetected: false,
 	}
 
-	source, err := newTextPagerSource(filePath, preview)
+	source, err := newTextPagerSource(nil, filePath, preview)
 	if err != nil {
 		t.Fatalf("newTextPagerSource: %v", err)
 	}
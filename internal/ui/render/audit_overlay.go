@@ -0,0 +1,56 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+)
+
+// drawAuditOverlay lists recent file operations, newest first, over the
+// whole screen like the help overlay. Failed operations are highlighted like preview warnings.
+func (r *Renderer) drawAuditOverlay(view *statepkg.AuditView, w, h int) {
+	baseStyle := tcell.StyleDefault.Background(r.theme.Background).Foreground(r.theme.Foreground)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			r.screen.SetContent(x, y, ' ', nil, baseStyle)
+		}
+	}
+
+	headerStyle := baseStyle.Background(r.theme.FooterBg).Foreground(r.theme.FooterFg).Bold(true)
+	title := " Recent operations "
+	titleStart := 0
+	if titleWidth := r.measureTextWidth(title); w > titleWidth {
+		titleStart = (w - titleWidth) / 2
+	}
+	r.drawTextLine(titleStart, 0, w-titleStart, title, headerStyle)
+
+	row := 2
+	maxRow := h - 1
+	if view.Err != "" && row < maxRow {
+		r.drawTextLine(2, row, w-4, r.truncateTextToWidth(textutil.SanitizeTerminalText(view.Err), w-4), baseStyle.Bold(true).Foreground(r.theme.SymlinkFg))
+		row++
+	}
+	if len(view.Entries) == 0 && view.Err == "" && row < maxRow {
+		r.drawTextLine(2, row, w-4, "No file operations recorded yet", baseStyle)
+	}
+	for i := view.Scroll; i < len(view.Entries) && row < maxRow; i++ {
+		entry := view.Entries[i]
+		style := baseStyle
+		if entry.Result != "ok" {
+			style = style.Bold(true).Foreground(r.theme.SymlinkFg)
+		}
+		text := textutil.SanitizeTerminalText(statepkg.FormatAuditEntry(entry))
+		r.drawTextLine(2, row, w-4, r.truncateTextToWidth(text, w-4), style)
+		row++
+	}
+
+	footer := "↑/↓ PgUp/PgDn scroll · Esc/q close"
+	if len(view.Entries) > 0 {
+		footer = fmt.Sprintf("%d-%d of %d · %s", view.Scroll+1, min(view.Scroll+maxRow-2, len(view.Entries)), len(view.Entries), footer)
+	}
+	if h > 0 {
+		r.drawTextLine(0, h-1, w, r.truncateTextToWidth(footer, w), headerStyle)
+	}
+}
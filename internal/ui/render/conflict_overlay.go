@@ -0,0 +1,118 @@
+package render
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+)
+
+// conflictChoiceOrder is the order choices are counted in the summary.
+var conflictChoiceOrder = []statepkg.ConflictChoice{
+	statepkg.ConflictRename,
+	statepkg.ConflictOverwrite,
+	statepkg.ConflictKeepNewer,
+	statepkg.ConflictSkip,
+}
+
+// drawConflictOverlay asks what to do with entries whose destination
+// exists, one at a time, then lists the decisions for confirmation. The
+// footer holds the rename pattern prompt while it is open.
+func (r *Renderer) drawConflictOverlay(state *statepkg.AppState, w, h int) {
+	dialog := state.Conflicts
+	baseStyle := tcell.StyleDefault.Background(r.theme.Background).Foreground(r.theme.Foreground)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			r.screen.SetContent(x, y, ' ', nil, baseStyle)
+		}
+	}
+
+	headerStyle := baseStyle.Background(r.theme.FooterBg).Foreground(r.theme.FooterFg).Bold(true)
+	verb := strings.ToUpper(dialog.Verb[:1]) + dialog.Verb[1:]
+	title := fmt.Sprintf(" %s: %s already exist ", verb, countLabel(len(dialog.Items), "name"))
+	if len(dialog.Items) == 1 {
+		title = fmt.Sprintf(" %s: 1 name already exists ", verb)
+	}
+	titleStart := 0
+	if titleWidth := r.measureTextWidth(title); w > titleWidth {
+		titleStart = (w - titleWidth) / 2
+	}
+	r.drawTextLine(titleStart, 0, w-titleStart, title, headerStyle)
+
+	row := 2
+	maxRow := h - 1
+	line := func(text string, style tcell.Style) {
+		if row < maxRow {
+			r.drawTextLine(2, row, w-4, r.truncateTextToWidth(text, w-4), style)
+		}
+		row++
+	}
+	bold := baseStyle.Bold(true)
+	dim := baseStyle.Dim(true)
+	pattern := "rename to " + textutil.SanitizeTerminalText(dialog.Pattern)
+
+	if !dialog.Summary() {
+		item := dialog.Items[dialog.Index]
+		name := textutil.SanitizeTerminalText(filepath.Base(item.Dest))
+		line(fmt.Sprintf("%d of %d: %s", dialog.Index+1, len(dialog.Items), name), bold)
+		row++
+		line("new       "+conflictSide(item.SourceIsDir, item.SourceSize, item.SourceModTime.Format("2006-01-02 15:04:05"))+"  "+textutil.SanitizeTerminalText(item.Source), baseStyle)
+		line("existing  "+conflictSide(item.DestIsDir, item.DestSize, item.DestModTime.Format("2006-01-02 15:04:05"))+"  "+textutil.SanitizeTerminalText(item.Dest), baseStyle)
+		switch {
+		case item.SourceModTime.After(item.DestModTime):
+			line("the new one is newer", dim)
+		case item.DestModTime.After(item.SourceModTime):
+			line("the existing one is newer", dim)
+		}
+		row++
+		line("s  skip", baseStyle)
+		line("o  overwrite", baseStyle)
+		line("r  "+pattern, baseStyle)
+		line("n  keep newer (overwrite only when the new one is newer)", baseStyle)
+		line("S O R N  the same for this and every remaining name", dim)
+	} else {
+		counts := dialog.Counts()
+		var parts []string
+		for _, choice := range conflictChoiceOrder {
+			if counts[choice] > 0 {
+				parts = append(parts, fmt.Sprintf("%d %s", counts[choice], choice))
+			}
+		}
+		line("Decisions: "+strings.Join(parts, " · "), bold)
+		if counts[statepkg.ConflictRename] > 0 {
+			line("renamed with "+textutil.SanitizeTerminalText(dialog.Pattern), dim)
+		}
+		row++
+		for i, item := range dialog.Items {
+			if row >= maxRow-1 && i < len(dialog.Items)-1 {
+				line(fmt.Sprintf("… %d more", len(dialog.Items)-i), dim)
+				break
+			}
+			line(fmt.Sprintf("%-10s  %s", dialog.Choices[i], textutil.SanitizeTerminalText(filepath.Base(item.Dest))), baseStyle)
+		}
+	}
+
+	footer := "s/o/r/n decide · S/O/R/N all remaining · ↵ keep · p pattern · ←/u back · Esc cancel"
+	if dialog.Summary() {
+		footer = "↵ " + dialog.Verb + " · ←/u back · p pattern · Esc cancel"
+	}
+	if state.Prompt != nil {
+		footer = buildPromptText(state.Prompt)
+	}
+	if h > 0 {
+		r.drawTextLine(0, h-1, w, r.truncateTextToWidth(footer, w), headerStyle)
+	}
+}
+
+// conflictSide describes one side of a conflict: its size (or "folder") and
+// modification time.
+func conflictSide(isDir bool, size int64, modTime string) string {
+	kind := textutil.FormatSize(size)
+	if isDir {
+		kind = "folder"
+	}
+	return fmt.Sprintf("%-9s %s", kind, modTime)
+}
@@ -0,0 +1,182 @@
+package render
+
+import (
+	"math"
+
+	"github.com/gdamore/tcell/v2"
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
+)
+
+// contrastCacheSize bounds the adjusted color pairs kept; a frame uses a
+// few dozen.
+const contrastCacheSize = 1024
+
+// Contrast lifts text colors that fall below a minimum contrast ratio
+// against their background toward white or black, whichever gets there
+// with the smaller change, so dim theme colors stay readable. Colors on the
+// terminal's default background are only judged when the background is
+// known. A nil Contrast keeps every color as chosen.
+type Contrast struct {
+	min        float64
+	background statepkg.Background
+	cache      map[[2]tcell.Color]tcell.Color
+}
+
+// NewContrast returns the adjustment for the given minimum ratio, or nil
+// when min does not ask for any.
+func NewContrast(min float64, background statepkg.Background) *Contrast {
+	if min <= 1 {
+		return nil
+	}
+	return &Contrast{
+		min:        min,
+		background: background,
+		cache:      make(map[[2]tcell.Color]tcell.Color),
+	}
+}
+
+// matches reports whether c was built for these settings.
+func (c *Contrast) matches(min float64, background statepkg.Background) bool {
+	if c == nil {
+		return min <= 1
+	}
+	return c.min == min && c.background == background
+}
+
+// Style adjusts the color the terminal draws text in: the foreground, or
+// the background of a reversed style.
+func (c *Contrast) Style(style tcell.Style) tcell.Style {
+	if c == nil {
+		return style
+	}
+	fg, bg, attrs := style.Decompose()
+	if attrs&tcell.AttrReverse != 0 {
+		if adjusted := c.Foreground(bg, fg); adjusted != bg {
+			return style.Background(adjusted)
+		}
+		return style
+	}
+	if adjusted := c.Foreground(fg, bg); adjusted != fg {
+		return style.Foreground(adjusted)
+	}
+	return style
+}
+
+// Foreground returns fg, or the color nearest to it that reaches the
+// minimum contrast against bg.
+func (c *Contrast) Foreground(fg, bg tcell.Color) tcell.Color {
+	if c == nil {
+		return fg
+	}
+	key := [2]tcell.Color{fg, bg}
+	if adjusted, ok := c.cache[key]; ok {
+		return adjusted
+	}
+	adjusted := c.foreground(fg, bg)
+	if len(c.cache) >= contrastCacheSize {
+		clear(c.cache)
+	}
+	c.cache[key] = adjusted
+	return adjusted
+}
+
+func (c *Contrast) foreground(fg, bg tcell.Color) tcell.Color {
+	text, ok := c.rgb(fg, false)
+	if !ok {
+		return fg
+	}
+	back, ok := c.rgb(bg, true)
+	if !ok {
+		return fg
+	}
+	backLum := relativeLuminance(back)
+	if contrastRatio(relativeLuminance(text), backLum) >= c.min {
+		return fg
+	}
+	target := [3]float64{255, 255, 255}
+	if contrastRatio(0, backLum) > contrastRatio(1, backLum) {
+		target = [3]float64{}
+	}
+	// Binary search for the smallest step toward the target that is enough;
+	// when even the target falls short it is the best there is.
+	lo, hi := 0.0, 1.0
+	for range 12 {
+		mid := (lo + hi) / 2
+		if contrastRatio(relativeLuminance(mixRGB(text, target, mid)), backLum) >= c.min {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	mixed := mixRGB(text, target, hi)
+	var channels [3]int32
+	for i, v := range mixed {
+		// Round toward the target so the result does not fall just short.
+		if target[i] > text[i] {
+			channels[i] = int32(math.Ceil(v))
+		} else {
+			channels[i] = int32(math.Floor(v))
+		}
+	}
+	return tcell.NewRGBColor(channels[0], channels[1], channels[2])
+}
+
+// rgb resolves color, taking the terminal's default colors from the known
+// background: black on white or white on black.
+func (c *Contrast) rgb(color tcell.Color, background bool) ([3]float64, bool) {
+	if color == tcell.ColorDefault {
+		switch {
+		case c.background == statepkg.BackgroundDark && background,
+			c.background == statepkg.BackgroundLight && !background:
+			return [3]float64{}, true
+		case c.background == statepkg.BackgroundDark, c.background == statepkg.BackgroundLight:
+			return [3]float64{255, 255, 255}, true
+		}
+		return [3]float64{}, false
+	}
+	r, g, b := color.RGB()
+	if r < 0 || g < 0 || b < 0 {
+		return [3]float64{}, false
+	}
+	return [3]float64{float64(r), float64(g), float64(b)}, true
+}
+
+func mixRGB(from, to [3]float64, t float64) [3]float64 {
+	var out [3]float64
+	for i := range out {
+		out[i] = from[i] + (to[i]-from[i])*t
+	}
+	return out
+}
+
+// relativeLuminance follows the WCAG 2 definition for sRGB colors.
+func relativeLuminance(rgb [3]float64) float64 {
+	var lin [3]float64
+	for i, v := range rgb {
+		v /= 255
+		if v <= 0.03928 {
+			lin[i] = v / 12.92
+		} else {
+			lin[i] = math.Pow((v+0.055)/1.055, 2.4)
+		}
+	}
+	return 0.2126*lin[0] + 0.7152*lin[1] + 0.0722*lin[2]
+}
+
+func contrastRatio(a, b float64) float64 {
+	if a < b {
+		a, b = b, a
+	}
+	return (a + 0.05) / (b + 0.05)
+}
+
+// contrastScreen applies a Contrast to every cell the renderer draws, so
+// hard-coded colors get the same treatment as the theme's.
+type contrastScreen struct {
+	tcell.Screen
+	contrast *Contrast
+}
+
+func (s *contrastScreen) SetContent(x, y int, primary rune, combining []rune, style tcell.Style) {
+	s.Screen.SetContent(x, y, primary, combining, s.contrast.Style(style))
+}
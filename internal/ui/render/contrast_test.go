@@ -0,0 +1,73 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
+)
+
+func colorContrast(t *testing.T, fg, bg tcell.Color) float64 {
+	t.Helper()
+	fr, fgG, fb := fg.RGB()
+	br, bgG, bb := bg.RGB()
+	return contrastRatio(
+		relativeLuminance([3]float64{float64(fr), float64(fgG), float64(fb)}),
+		relativeLuminance([3]float64{float64(br), float64(bgG), float64(bb)}),
+	)
+}
+
+func TestContrastLiftsDimForeground(t *testing.T) {
+	c := NewContrast(4.5, statepkg.BackgroundUnknown)
+	fg := c.Foreground(tcell.Color240, tcell.Color238)
+	if fg == tcell.Color240 {
+		t.Fatalf("expected grey on grey to be adjusted")
+	}
+	if ratio := colorContrast(t, fg, tcell.Color238); ratio < 4.5 {
+		t.Fatalf("adjusted contrast %.2f is below the minimum", ratio)
+	}
+	if got := c.Foreground(tcell.ColorWhite, tcell.Color238); got != tcell.ColorWhite {
+		t.Fatalf("readable colors should stay, got %v", got)
+	}
+	if got := c.Foreground(tcell.Color240, tcell.ColorDefault); got != tcell.Color240 {
+		t.Fatalf("colors on an unknown background should stay, got %v", got)
+	}
+	if NewContrast(0, statepkg.BackgroundDark) != nil {
+		t.Fatalf("expected no adjustment when off")
+	}
+}
+
+func TestContrastUsesKnownBackground(t *testing.T) {
+	dark := NewContrast(3, statepkg.BackgroundDark)
+	if fg := dark.Foreground(tcell.Color237, tcell.ColorDefault); fg == tcell.Color237 {
+		t.Fatalf("expected dark grey on a dark terminal to be lifted")
+	}
+	light := NewContrast(3, statepkg.BackgroundLight)
+	fg := light.Foreground(tcell.Color226, tcell.ColorDefault)
+	if ratio := colorContrast(t, fg, tcell.ColorWhite); ratio < 3 {
+		t.Fatalf("yellow on a light terminal kept contrast %.2f", ratio)
+	}
+
+	style := tcell.StyleDefault.Foreground(tcell.Color238).Background(tcell.Color240).Reverse(true)
+	gotFg, gotBg, _ := light.Style(style).Decompose()
+	if gotFg != tcell.Color238 || gotBg == tcell.Color240 {
+		t.Fatalf("reversed style should adjust the background, got fg=%v bg=%v", gotFg, gotBg)
+	}
+}
+
+func TestRendererEnforcesMinContrast(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(20, 3)
+
+	r := NewRenderer(screen)
+	r.Render(&statepkg.AppState{MinContrast: 4.5, HelpVisible: true})
+	r.screen.SetContent(0, 0, 'x', nil, tcell.StyleDefault.Foreground(tcell.Color240).Background(tcell.Color238))
+	_, _, style, _ := screen.GetContent(0, 0)
+	if fg, bg, _ := style.Decompose(); colorContrast(t, fg, bg) < 4.5 {
+		t.Fatalf("expected the drawn cell to reach the minimum contrast, got fg=%v bg=%v", fg, bg)
+	}
+}
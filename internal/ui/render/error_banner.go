@@ -0,0 +1,59 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+)
+
+// errorActionHints are the footer hints for the banner actions. Going up
+// and reading as root use the keys that do so anyway.
+var errorActionHints = map[statepkg.ErrorBannerAction]string{
+	statepkg.ErrorActionRetry:      "r: retry",
+	statepkg.ErrorActionGoUp:       "←: go up",
+	statepkg.ErrorActionReadAsRoot: "S: read as root",
+	statepkg.ErrorActionDismiss:    "Esc: dismiss",
+}
+
+// errorBannerParts returns the banner's message and its action hints, with
+// the number of other banners waiting behind it.
+func errorBannerParts(state *statepkg.AppState, banner *statepkg.ErrorBanner) (string, string) {
+	label := banner.Kind.String()
+	if source := banner.Source.String(); source != "" {
+		label = source + " · " + label
+	}
+	message := strings.Join(strings.Fields(banner.Err.Error()), " ")
+	message = " ✗ " + label + ": " + textutil.SanitizeTerminalText(message)
+
+	actions := state.ErrorActions(banner)
+	hints := make([]string, 0, len(actions)+1)
+	for _, action := range actions {
+		hints = append(hints, errorActionHints[action])
+	}
+	if more := state.Errors.Count() - 1; more > 0 {
+		hints = append(hints, fmt.Sprintf("+%d more", more))
+	}
+	return message, "  " + strings.Join(hints, "  ") + " "
+}
+
+// drawErrorBanner replaces the footer hints on row y with the error banner.
+// The message gives way to the hints when the row is too narrow for both.
+func (r *Renderer) drawErrorBanner(state *statepkg.AppState, banner *statepkg.ErrorBanner, w, y int) {
+	style := tcell.StyleDefault.Background(tcell.ColorMaroon).Foreground(tcell.ColorWhite)
+	message, hints := errorBannerParts(state, banner)
+	hintsWidth := r.measureTextWidth(hints)
+	if hintsWidth > w/2 {
+		hintsWidth = w / 2
+		hints = r.truncateTextToWidth(hints, hintsWidth)
+	}
+	message = r.truncateTextToWidth(message, w-hintsWidth)
+
+	x := r.drawTextLine(0, y, w-hintsWidth, message, style.Bold(true))
+	x = r.drawTextLine(x, y, w-x, hints, style)
+	for ; x < w; x++ {
+		r.screen.SetContent(x, y, ' ', nil, style)
+	}
+}
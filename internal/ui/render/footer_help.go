@@ -9,6 +9,24 @@ import (
 
 // buildFooterHelpText returns the contextual footer hint string with leading/trailing padding.
 func buildFooterHelpText(state *statepkg.AppState) string {
+	if state != nil && state.PendingConfirm != nil {
+		return " " + state.PendingConfirm.Prompt + " [y/N] "
+	}
+	if state != nil && state.Prompt != nil {
+		return buildPromptText(state.Prompt)
+	}
+	if state != nil && state.Rename != nil {
+		if state.Rename.Err != "" {
+			return " rename: " + state.Rename.Err + " "
+		}
+		return " rename  ↵: apply  Esc: cancel "
+	}
+	if state != nil && state.PathChoice != nil {
+		return buildPathChoiceText(state.PathChoice)
+	}
+	if state != nil && state.ArchiveJob != nil {
+		return " " + state.ArchiveJob.Status() + "  Esc: cancel "
+	}
 	parts := buildFooterHelpSegments(state)
 	if len(parts) == 0 {
 		return ""
@@ -16,6 +34,15 @@ func buildFooterHelpText(state *statepkg.AppState) string {
 	return " " + strings.Join(parts, "  ") + " "
 }
 
+// buildPathChoiceText lists the numbered forms of the Y menu.
+func buildPathChoiceText(choice *statepkg.PathChoice) string {
+	parts := make([]string, 0, len(choice.Forms))
+	for i, form := range choice.Forms {
+		parts = append(parts, fmt.Sprintf("%d %s: %s", i+1, form.Label, form.Path))
+	}
+	return fmt.Sprintf(" copy as  %s  [1-%d, Esc] ", strings.Join(parts, "  "), len(choice.Forms))
+}
+
 // buildFooterHelpSegments assembles context-aware help hints for the footer.
 func buildFooterHelpSegments(state *statepkg.AppState) []string {
 	if state == nil {
@@ -37,6 +64,13 @@ func contextualHelpSegments(state *statepkg.AppState) []string {
 			"Esc: clear/exit",
 			"↑↓: select match",
 			"PgUp/PgDn: page",
+			"^S: sort",
+		}
+	case state.TypeAheadActive:
+		return []string{
+			"jump: " + state.TypeAheadPrefix + "▏",
+			"type: select by name",
+			"Esc: done",
 		}
 	case state.FilterActive:
 		return []string{
@@ -89,3 +123,23 @@ func persistentHelpSegments(state *statepkg.AppState) []string {
 
 	return segments
 }
+
+// buildPromptText renders the active footer prompt with its input and hints.
+func buildPromptText(prompt *statepkg.TextPrompt) string {
+	line := prompt.Line()
+	text := " " + prompt.Label() + " " + string(line.Text[:line.Cursor]) + "▏" + string(line.Text[line.Cursor:])
+	if prompt.Err != "" {
+		return text + "  " + prompt.Err + " "
+	}
+	hints := []string{"↵: apply", "Esc: cancel"}
+	if prompt.Kind == statepkg.PromptSelectPattern {
+		hints = append([]string{"Tab: mark/unmark/keep"}, hints...)
+	}
+	if prompt.Kind == statepkg.PromptRenamePattern {
+		hints = append([]string{"{name} {n} {ext}"}, hints...)
+	}
+	if prompt.Kind == statepkg.PromptCompress {
+		hints = append([]string{"Tab: zip/tar.gz"}, hints...)
+	}
+	return text + "  " + strings.Join(hints, "  ") + " "
+}
@@ -70,6 +70,7 @@ func TestBuildFooterHelpSegments_GlobalSearchMode(t *testing.T) {
 		"Esc: clear/exit",
 		"↑↓: select match",
 		"PgUp/PgDn: page",
+		"^S: sort",
 		"? help",
 	}
 
@@ -122,3 +123,19 @@ func TestBuildFooterHelpSegments_PreviewMode(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatViewStats(t *testing.T) {
+	tests := []struct {
+		stats statepkg.ViewStats
+		want  string
+	}{
+		{stats: statepkg.ViewStats{Total: 1, Shown: 1}, want: "1 item"},
+		{stats: statepkg.ViewStats{Total: 40, Shown: 40, ListedBytes: 512}, want: "40 items · 512 B"},
+		{stats: statepkg.ViewStats{Total: 40, Shown: 12, ListedBytes: 3 * 1024 * 1024, Marked: 2}, want: "12/40 shown · 3.0 MiB · 2 marked"},
+	}
+	for _, tt := range tests {
+		if got := formatViewStats(tt.stats); got != tt.want {
+			t.Fatalf("formatViewStats(%+v) = %q, want %q", tt.stats, got, tt.want)
+		}
+	}
+}
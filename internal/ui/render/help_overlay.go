@@ -25,48 +25,111 @@ func buildHelpOverlayLines(state *statepkg.AppState) []string {
 		hiddenDesc = "Show hidden files"
 	}
 
+	gridDesc := "Show the list in columns"
+	if state != nil && state.ListGridEnabled {
+		gridDesc = "Show the list in one column"
+	}
+
+	enterDesc := "Open dir or " + statepkg.EnterPager.Description()
+	var enterOverrides []string
+	if state != nil {
+		enterDesc = "Open dir or " + state.Enter.For("").Description()
+		enterOverrides = state.Enter.Overrides()
+	}
+	actions := []helpOverlayEntry{
+		{keys: ".", desc: hiddenDesc},
+		{keys: "G", desc: gridDesc},
+		{keys: "H", desc: "Reveal one entry by exact name"},
+		{keys: "!", desc: "Open shell in current directory"},
+		{keys: "r", desc: "Refresh directory, or retry the error shown"},
+		{keys: "y", desc: "Yank path to clipboard"},
+		{keys: "Y", desc: "Yank path for WSL, Windows or a container"},
+		{keys: "e", desc: "Open in external editor ($VISUAL/$EDITOR)"},
+	}
+	if state != nil && !state.EditorAvailable && state.EditorStatus != "" {
+		actions = append(actions, helpOverlayEntry{keys: "", desc: "  unavailable: " + state.EditorStatus})
+	}
+	actions = append(actions, helpOverlayEntry{keys: "L", desc: "Normalize line endings (keeps a .bak)"})
+	ecoDesc := "Eco mode: less background work (auto on battery)"
+	if state != nil && state.EcoActive {
+		ecoDesc = "Leave eco mode"
+	}
+	actions = append(actions, helpOverlayEntry{keys: "Z", desc: ecoDesc})
+
+	navigation := []helpOverlayEntry{
+		{keys: "↑/↓", desc: "Move selection"},
+		{keys: "↵ / →", desc: enterDesc},
+	}
+	if len(enterOverrides) > 0 {
+		navigation = append(navigation, helpOverlayEntry{keys: "", desc: "  " + strings.Join(enterOverrides, ", ")})
+	}
+	navigation = append(navigation,
+		helpOverlayEntry{keys: "←", desc: "Go up to parent"},
+		helpOverlayEntry{keys: "← / →", desc: "Previous/next column in the column layout"},
+		helpOverlayEntry{keys: "[ / ]", desc: "History back/forward (also Alt+←/→, mouse 4/5)"},
+		helpOverlayEntry{keys: "{ / }", desc: "Previous/next sibling directory"},
+		helpOverlayEntry{keys: "~", desc: "Go home"},
+		helpOverlayEntry{keys: "g", desc: "Go to symlink/shortcut target"},
+		helpOverlayEntry{keys: "PgUp/PgDn", desc: "Page list"},
+		helpOverlayEntry{keys: "Home/End", desc: "Jump to start/end"},
+	)
+
 	sections := []helpOverlaySection{
 		{
-			title: "Navigation",
-			entries: []helpOverlayEntry{
-				{keys: "↑/↓", desc: "Move selection"},
-				{keys: "↵ / →", desc: "Open dir or preview file"},
-				{keys: "←", desc: "Go up to parent"},
-				{keys: "[ / ]", desc: "History back/forward"},
-				{keys: "~", desc: "Go home"},
-				{keys: "PgUp/PgDn", desc: "Page list"},
-				{keys: "Home/End", desc: "Jump to start/end"},
-			},
+			title:   "Navigation",
+			entries: navigation,
 		},
 		{
 			title: "Filter & Search",
 			entries: []helpOverlayEntry{
 				{keys: "/", desc: "Filter current directory"},
+				{keys: "'", desc: "Type-ahead jump by name prefix"},
 				{keys: "f", desc: "Global search"},
+				{keys: "Ctrl+S", desc: "Sort search results (score/path/newest/largest)"},
+				{keys: "Ctrl+D", desc: "Show filter match scores (RDIR_FILTER_SCORING)"},
 				{keys: "Esc", desc: "Clear or exit search/filter"},
 			},
 		},
 		{
 			title: "Preview & Pager",
 			entries: []helpOverlayEntry{
+				{keys: "J / K", desc: "Scroll preview (also Ctrl+E / Ctrl+Y)"},
 				{keys: "P", desc: "Open external pager ($PAGER)"},
+				{keys: "S", desc: "Read an unreadable file via " + statepkg.EnvPrivilegedHelper},
+			},
+		},
+		{
+			title:   "Actions",
+			entries: actions,
+		},
+		{
+			title: "Marks & Tabs",
+			entries: []helpOverlayEntry{
+				{keys: "Space", desc: "Mark/unmark entry"},
+				{keys: "u", desc: "Clear marks"},
+				{keys: "*", desc: "Mark by glob or /regex/ (Tab: add/remove/keep)"},
+				{keys: "t / T", desc: "New tab / close tab"},
+				{keys: "Tab/S-Tab", desc: "Next/previous tab"},
+				{keys: "O", desc: "Open marked dirs in tabs"},
 			},
 		},
 		{
-			title: "Actions",
+			title: "Staging",
 			entries: []helpOverlayEntry{
-				{keys: ".", desc: hiddenDesc},
-				{keys: "!", desc: "Open shell in current directory"},
-				{keys: "r", desc: "Refresh directory"},
-				{keys: "y", desc: "Yank path to clipboard"},
-				{keys: "e", desc: "Open in external editor ($EDITOR)"},
+				{keys: "F2", desc: "Rename entry in place (stem selected)"},
+				{keys: "x / c", desc: "Stage for move/copy (toggle)"},
+				{keys: "p", desc: "Paste staged entries here (asks about taken names)"},
+				{keys: "X", desc: "Clear staging"},
+				{keys: "U", desc: "Extract selected/marked archives"},
+				{keys: "C", desc: "Compress marked entries (zip/tar.gz)"},
+				{keys: "A", desc: "Recent file operations (audit log)"},
 			},
 		},
 		{
 			title: "Exit",
 			entries: []helpOverlayEntry{
 				{keys: "q", desc: "Quit"},
-				{keys: "x", desc: "Quit and cd here"},
+				{keys: "Q", desc: "Quit and cd here"},
 				{keys: "Ctrl+C", desc: "Quit immediately"},
 				{keys: "?", desc: "Close this help"},
 			},
@@ -47,3 +47,31 @@ func TestBuildHelpOverlayLinesReflectsHiddenToggle(t *testing.T) {
 		t.Fatalf("expected help to show hide instruction when hidden files visible, got %v", lines)
 	}
 }
+
+func TestBuildHelpOverlayLinesReflectsEnterBehavior(t *testing.T) {
+	state := &statepkg.AppState{Enter: statepkg.EnterConfig{
+		Default: statepkg.EnterEditor,
+		ByExt:   map[string]statepkg.EnterBehavior{"pdf": statepkg.EnterOpen},
+	}}
+	joined := strings.Join(buildHelpOverlayLines(state), "\n")
+
+	if !strings.Contains(joined, "Open dir or open in editor") {
+		t.Fatalf("expected Enter entry to describe editor behavior, got %s", joined)
+	}
+	if !strings.Contains(joined, ".pdf→open") {
+		t.Fatalf("expected per-extension override to be listed, got %s", joined)
+	}
+}
+
+func TestBuildHelpOverlayLinesExplainsMissingEditor(t *testing.T) {
+	state := &statepkg.AppState{EditorStatus: `$VISUAL "subl -w": subl not found in PATH`}
+	joined := strings.Join(buildHelpOverlayLines(state), "\n")
+	if !strings.Contains(joined, "unavailable: $VISUAL") {
+		t.Fatalf("expected the editor reason in help, got %v", joined)
+	}
+
+	state.EditorAvailable = true
+	if strings.Contains(strings.Join(buildHelpOverlayLines(state), "\n"), "unavailable") {
+		t.Fatalf("did not expect a reason when the editor is available")
+	}
+}
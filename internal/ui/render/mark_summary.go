@@ -0,0 +1,91 @@
+package render
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gdamore/tcell/v2"
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+)
+
+// drawMarkSummary fills the preview panel with the totals of the marked
+// entries: count and size, files and folders, the extensions taking the most
+// space and the largest marked entries.
+func (r *Renderer) drawMarkSummary(state *statepkg.AppState, summary *statepkg.MarkSummary, startX, panelWidth, bottomLimit int, baseStyle tcell.Style) {
+	y := 1
+	row := func(left, right string, style tcell.Style) {
+		if y >= bottomLimit {
+			return
+		}
+		leftWidth := panelWidth
+		if right != "" {
+			leftWidth -= r.measureTextWidth(right) + 1
+		}
+		if leftWidth > 0 {
+			r.drawTextLine(startX, y, leftWidth, left, style)
+		}
+		if right != "" {
+			rightX := startX + panelWidth - r.measureTextWidth(right)
+			r.drawTextLine(rightX, y, startX+panelWidth-rightX, right, style)
+		}
+		y++
+	}
+	heading := baseStyle.Foreground(r.theme.FileFg).Bold(true)
+	dim := baseStyle.Dim(true)
+	warn := baseStyle.Bold(true).Foreground(r.theme.SymlinkFg)
+
+	row(fmt.Sprintf(" %d marked · %s", summary.Count, textutil.FormatSize(summary.Size)), "", heading)
+	row(" "+countLabel(summary.Files, "file")+" · "+countLabel(summary.Dirs, "folder"), "", dim)
+	if summary.Loading {
+		row(" counting…", "", warn)
+	}
+	if summary.Unreadable > 0 {
+		row(" ⚠ "+countLabel(summary.Unreadable, "entry")+" could not be read", "", warn)
+	}
+
+	if len(summary.Extensions) > 0 {
+		y++
+		row(" By extension", "", heading)
+		for _, ext := range summary.Extensions {
+			name := ext.Ext
+			if name == "" {
+				name = "(none)"
+			}
+			left := fmt.Sprintf("   %s  %s", textutil.SanitizeTerminalText(name), countLabel(ext.Files, "file"))
+			row(left, textutil.FormatSize(ext.Size)+" ", baseStyle.Foreground(r.theme.FileFg))
+		}
+	}
+
+	if len(summary.Largest) > 0 {
+		y++
+		row(" Largest", "", heading)
+		for _, item := range summary.Largest {
+			size := textutil.FormatSize(item.Size) + " "
+			prefix := "   "
+			style := baseStyle.Foreground(r.theme.FileFg)
+			if item.IsDir {
+				prefix = " / "
+				style = baseStyle.Foreground(r.theme.DirectoryFg)
+			}
+			name := textutil.SanitizeTerminalText(filepath.Base(item.Path))
+			if nameWidth := panelWidth - r.measureTextWidth(prefix) - r.measureTextWidth(size) - 1; nameWidth > 0 {
+				name = r.truncateName(name, nameWidth, state.Truncate.Preview)
+			} else {
+				name = ""
+			}
+			row(prefix+name, size, style)
+		}
+	}
+}
+
+// countLabel pairs n with noun, adding a plural "s" (or "ies") when needed.
+func countLabel(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	if noun == "entry" {
+		return fmt.Sprintf("%d entries", n)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -2,11 +2,13 @@ package render
 
 import (
 	"fmt"
+	"math"
 	"strings"
 	"time"
 	"unicode/utf8"
 
 	"github.com/gdamore/tcell/v2"
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
 	statepkg "github.com/kk-code-lab/rdir/internal/state"
 	textutil "github.com/kk-code-lab/rdir/internal/textutil"
 	"github.com/rivo/uniseg"
@@ -27,6 +29,11 @@ func (r *Renderer) drawPreviewPanel(state *statepkg.AppState, layout layoutMetri
 		}
 	}
 
+	if state != nil && state.MarkSummary != nil && !state.PreviewFullScreen {
+		r.drawMarkSummary(state, state.MarkSummary, startX, panelWidth, h-1, baseStyle)
+		return
+	}
+
 	y := 1
 	loading := state != nil && state.PreviewLoading
 	loadingLabel := ""
@@ -86,6 +93,50 @@ func (r *Renderer) drawPreviewPanel(state *statepkg.AppState, layout layoutMetri
 		}
 	}
 
+	if preview.IsDir && startIdx == 0 && !loading {
+		if !drawLine(" "+formatItemCount(len(preview.DirEntries)), baseStyle.Dim(true)) {
+			return
+		}
+	}
+
+	if !preview.IsDir && len(preview.AltStreams) > 0 {
+		warnStyle := baseStyle.Bold(true).Foreground(r.theme.SymlinkFg)
+		if !drawLine("⚠ "+altStreamsLabel(preview.AltStreams), warnStyle) {
+			return
+		}
+	}
+
+	if !preview.IsDir && preview.Unreadable && startIdx == 0 {
+		if !drawLine("🔒 permission denied", baseStyle.Bold(true).Foreground(r.theme.SymlinkFg)) {
+			return
+		}
+		hint := "set " + statepkg.EnvPrivilegedHelper + " to read it with e.g. sudo cat"
+		if len(state.PrivilegedHelper) > 0 {
+			hint = "S reads it with " + textutil.SanitizeTerminalText(strings.Join(state.PrivilegedHelper, " "))
+		}
+		if !drawLine(hint, baseStyle.Dim(true)) {
+			return
+		}
+	}
+
+	if !preview.IsDir && preview.Shortcut != nil && startIdx == 0 {
+		for i, line := range shortcutLines(preview.Shortcut) {
+			style := baseStyle.Dim(true)
+			if i == 0 {
+				style = baseStyle.Bold(true).Foreground(r.theme.SymlinkFg)
+			}
+			if !drawLine(line, style) {
+				return
+			}
+		}
+	}
+
+	if !preview.IsDir && preview.Compressed != nil && startIdx == 0 {
+		if !drawLine(compressedLine(preview), baseStyle.Dim(true)) {
+			return
+		}
+	}
+
 	if preview.IsDir && len(preview.DirEntries) > 0 {
 		if startIdx > len(preview.DirEntries) {
 			startIdx = len(preview.DirEntries)
@@ -110,7 +161,7 @@ func (r *Renderer) drawPreviewPanel(state *statepkg.AppState, layout layoutMetri
 			nameWidth := panelWidth - r.measureTextWidth(prefix)
 			displayName := textutil.SanitizeTerminalText(entry.Name)
 			if nameWidth > 0 {
-				displayName = r.truncateTextToWidth(displayName, nameWidth)
+				displayName = r.truncateName(displayName, nameWidth, state.Truncate.Preview)
 			} else {
 				displayName = ""
 			}
@@ -145,6 +196,12 @@ func (r *Renderer) drawPreviewPanel(state *statepkg.AppState, layout layoutMetri
 				return
 			}
 		}
+		if preview.LineEndings.Mixed() {
+			warnStyle := baseStyle.Bold(true).Foreground(r.theme.SymlinkFg)
+			if !drawLine("⚠ "+preview.LineEndings.String()+" line endings", warnStyle) {
+				return
+			}
+		}
 		textStyle := baseStyle.Foreground(r.theme.FileFg)
 		if len(preview.FormattedSegments) > 0 {
 			lines := preview.FormattedSegments
@@ -157,7 +214,7 @@ func (r *Renderer) drawPreviewPanel(state *statepkg.AppState, layout layoutMetri
 				if wrapEnabled {
 					maxLinesPerCell = 0
 				}
-				mdSegments, mdMeta := statepkg.FormatMarkdownPreviewFromData(preview, panelWidth, maxLinesPerCell, wrapEnabled)
+				mdSegments, mdMeta := r.markdownLines(preview, panelWidth, maxLinesPerCell, wrapEnabled, startIdx+bottomLimit-y)
 				if len(mdSegments) > 0 {
 					lines = mdSegments
 					meta = mdMeta
@@ -264,9 +321,20 @@ func (r *Renderer) previewLoadingLabel(state *statepkg.AppState) string {
 	if file := state.CurrentFile(); file != nil && file.Name != "" {
 		label = fmt.Sprintf("%s loading %s… ", spinner, textutil.SanitizeTerminalText(file.Name))
 	}
+	if count, ok := state.PreviewItemCount(); ok {
+		label += "(" + formatItemCount(count) + ") "
+	}
 	return label
 }
 
+// formatItemCount renders a directory entry count for the preview header.
+func formatItemCount(n int) string {
+	if n == 1 {
+		return "1 item"
+	}
+	return fmt.Sprintf("%d items", n)
+}
+
 var previewSpinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
 
 func (r *Renderer) previewSpinner(state *statepkg.AppState) string {
@@ -714,3 +782,87 @@ func joinSegmentsPlain(segments []statepkg.StyledTextSegment) string {
 	}
 	return b.String()
 }
+
+// shortcutLines describes a .desktop or .lnk shortcut: its target first
+// (g goes there), then the command line, icon and working directory.
+func shortcutLines(sc *fsutil.Shortcut) []string {
+	target := sc.Target
+	if target == "" {
+		target = "no file target"
+	}
+	lines := []string{"→ " + textutil.SanitizeTerminalText(target)}
+	for _, field := range []struct{ label, value string }{
+		{"Name", sc.Name},
+		{"Exec", sc.Exec},
+		{"Icon", sc.Icon},
+		{"Dir", sc.WorkDir},
+		{"Note", sc.Comment},
+	} {
+		if field.value != "" {
+			lines = append(lines, "  "+field.label+": "+textutil.SanitizeTerminalText(field.value))
+		}
+	}
+	return lines
+}
+
+// compressedLine tells the preview of a compressed file apart from the file
+// itself: "gzip 12.0 KB → 1.4 MB".
+func compressedLine(preview *statepkg.PreviewData) string {
+	uncompressed := "?"
+	if preview.Compressed.Size >= 0 {
+		uncompressed = textutil.FormatSize(preview.Compressed.Size)
+	}
+	return fmt.Sprintf("%s %s → %s", preview.Compressed.Format, textutil.FormatSize(preview.Size), uncompressed)
+}
+
+// altStreamsLabel lists a file's alternate streams with their sizes.
+func altStreamsLabel(streams []fsutil.AltStream) string {
+	parts := make([]string, 0, len(streams))
+	for _, stream := range streams {
+		parts = append(parts, fmt.Sprintf("%s (%s)", textutil.SanitizeTerminalText(stream.Label()), textutil.FormatSize(stream.Size)))
+	}
+	return "also has " + strings.Join(parts, ", ")
+}
+
+// markdownLayout is the inline markdown preview as last laid out: the first
+// rows lines of preview at width.
+type markdownLayout struct {
+	preview         *statepkg.PreviewData
+	sourceLines     int // len(preview.TextLines); the pager can extend them in place
+	width           int
+	maxLinesPerCell int
+	wrap            bool
+	rows            int
+	segments        [][]statepkg.StyledTextSegment
+	meta            []statepkg.TextLineMetadata
+}
+
+// markdownLines returns at least the first rows lines of the markdown preview
+// laid out at width (all of them when the document is shorter). Only the
+// blocks needed for those rows are rendered, and the layout is reused while
+// the preview, width and wrapping stay the same.
+func (r *Renderer) markdownLines(preview *statepkg.PreviewData, width, maxLinesPerCell int, wrap bool, rows int) ([][]statepkg.StyledTextSegment, []statepkg.TextLineMetadata) {
+	if rows < 1 {
+		rows = 1
+	}
+	cached := &r.markdown
+	if cached.preview == preview && cached.sourceLines == len(preview.TextLines) && cached.width == width && cached.maxLinesPerCell == maxLinesPerCell && cached.wrap == wrap && cached.rows >= rows {
+		return cached.segments, cached.meta
+	}
+	segments, meta := statepkg.FormatMarkdownPreviewRows(preview, width, maxLinesPerCell, wrap, rows)
+	complete := rows
+	if len(segments) < rows {
+		complete = math.MaxInt // the whole document fit
+	}
+	*cached = markdownLayout{
+		preview:         preview,
+		sourceLines:     len(preview.TextLines),
+		width:           width,
+		maxLinesPerCell: maxLinesPerCell,
+		wrap:            wrap,
+		rows:            complete,
+		segments:        segments,
+		meta:            meta,
+	}
+	return segments, meta
+}
@@ -0,0 +1,75 @@
+package render
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/uniseg"
+
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+)
+
+// renameCluster is one grapheme cluster of the inline rename input.
+type renameCluster struct {
+	text  string
+	start int // rune offset in the input
+	width int
+}
+
+// drawRenameField draws the inline rename input in place of an entry's name:
+// the selection reversed and the cursor as "▏". When the name is wider than
+// width, its start gives way to "…" so the cursor stays visible.
+func (r *Renderer) drawRenameField(x, y, width int, rename *statepkg.InlineRename, style tcell.Style) int {
+	line := rename.Line()
+	selStart, selEnd, selected := rename.Selection()
+
+	var clusters []renameCluster
+	cursorAt := -1 // cluster the cursor is drawn before
+	offset := 0
+	g := uniseg.NewGraphemes(string(line.Text))
+	for g.Next() {
+		runes := g.Runes()
+		if cursorAt < 0 && line.Cursor < offset+len(runes) {
+			cursorAt = len(clusters)
+		}
+		text := textutil.SanitizeTerminalText(g.Str())
+		clusters = append(clusters, renameCluster{text: text, start: offset, width: max(textutil.DisplayWidth(text), 1)})
+		offset += len(runes)
+	}
+	if cursorAt < 0 {
+		cursorAt = len(clusters)
+	}
+
+	const cursorMark, ellipsis = "▏", "…"
+	used := textutil.DisplayWidth(cursorMark)
+	for _, c := range clusters[:cursorAt] {
+		used += c.width
+	}
+	first := 0
+	if used > width {
+		room := width - textutil.DisplayWidth(ellipsis)
+		for first < cursorAt && used > room {
+			used -= clusters[first].width
+			first++
+		}
+	}
+
+	end := x + width
+	if first > 0 {
+		x = r.drawTextLine(x, y, end-x, ellipsis, style.Dim(true))
+	}
+	for i := first; i <= len(clusters); i++ {
+		if i == cursorAt {
+			x = r.drawTextLine(x, y, end-x, cursorMark, style)
+		}
+		if i == len(clusters) || x+clusters[i].width > end {
+			break
+		}
+		c := clusters[i]
+		cellStyle := style
+		if selected && c.start >= selStart && c.start < selEnd {
+			cellStyle = style.Reverse(true)
+		}
+		x = r.drawTextLine(x, y, end-x, c.text, cellStyle)
+	}
+	return x
+}
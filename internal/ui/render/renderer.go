@@ -16,18 +16,25 @@ import (
 	"github.com/rivo/uniseg"
 )
 
+// maxTabLabelWidth caps each tab label in the header so the breadcrumb keeps room.
+const maxTabLabelWidth = 16
+
 // Renderer handles all UI rendering
 type Renderer struct {
 	screen      tcell.Screen
+	cells       *contrastScreen // screen, with the minimum contrast enforced
 	theme       ColorTheme
 	lastLayout  layoutMetrics
 	layoutReady bool
+	markdown    markdownLayout // inline markdown preview, kept across frames
 }
 
 // NewRenderer creates a new renderer
 func NewRenderer(screen tcell.Screen) *Renderer {
+	cells := &contrastScreen{Screen: screen}
 	return &Renderer{
-		screen: screen,
+		screen: cells,
+		cells:  cells,
 		theme:  GetColorTheme(),
 	}
 }
@@ -55,6 +62,9 @@ func (r *Renderer) LastLayout() (LayoutSnapshot, bool) {
 func (r *Renderer) Render(state *statepkg.AppState) {
 	w, h := r.screen.Size()
 	r.screen.Clear()
+	if state != nil && !r.cells.contrast.matches(state.MinContrast, state.Background) {
+		r.cells.contrast = NewContrast(state.MinContrast, state.Background)
+	}
 
 	if state != nil && state.HelpVisible {
 		r.drawHelpOverlay(state, w, h)
@@ -62,6 +72,18 @@ func (r *Renderer) Render(state *statepkg.AppState) {
 		return
 	}
 
+	if state != nil && state.AuditView != nil {
+		r.drawAuditOverlay(state.AuditView, w, h)
+		r.screen.Show()
+		return
+	}
+
+	if state != nil && state.Conflicts != nil {
+		r.drawConflictOverlay(state, w, h)
+		r.screen.Show()
+		return
+	}
+
 	if state != nil && state.PreviewFullScreen {
 		r.layoutReady = false
 		r.drawHeader(state, w, h)
@@ -106,7 +128,31 @@ func (r *Renderer) drawHeader(state *statepkg.AppState, w, h int) {
 	headerText := "rdir"
 	headerStyle := tcell.StyleDefault.Background(r.theme.FooterBg).Foreground(r.theme.FooterFg)
 
+	// Slow (network) paths, eco mode and session sharing get a badge at the
+	// right edge; the breadcrumb is laid out in the remaining width.
+	fullWidth := w
+	badge := ""
+	if state.SlowPath {
+		badge = " ⇄ " + textutil.SanitizeTerminalText(state.SlowPathKind) + " "
+	}
+	if state.EcoActive {
+		badge += " eco "
+	}
+	if state.Following != "" {
+		badge += " following · q quits "
+	} else if state.SharingPath != "" {
+		badge += " shared "
+	}
+	if badge != "" {
+		if badgeWidth := r.measureTextWidth(badge); badgeWidth < w/2 {
+			w -= badgeWidth
+		} else {
+			badge = ""
+		}
+	}
+
 	endX := r.drawTextLine(0, 0, w, headerText, headerStyle)
+	endX = r.drawTabBar(state, endX, w, headerStyle)
 	currentPath := state.CurrentPath
 	if currentPath == "" {
 		currentPath = "/"
@@ -147,6 +193,33 @@ func (r *Renderer) drawHeader(state *statepkg.AppState, w, h int) {
 	for x := endX; x < w; x++ {
 		r.screen.SetContent(x, 0, ' ', nil, headerStyle)
 	}
+	if badge != "" {
+		r.drawTextLine(w, 0, fullWidth-w, badge, headerStyle.Foreground(r.theme.MarkedFg).Bold(true))
+	}
+}
+
+// drawTabBar renders " 1:name 2:name ..." after the app name when more than
+// one tab is open, highlighting the active tab.
+func (r *Renderer) drawTabBar(state *statepkg.AppState, x, w int, headerStyle tcell.Style) int {
+	count := state.TabCount()
+	if count <= 1 {
+		return x
+	}
+	activeStyle := tcell.StyleDefault.Background(r.theme.SelectionBg).Foreground(r.theme.SelectionFg).Bold(true)
+	for i := 0; i < count && x < w; i++ {
+		x = r.drawTextLine(x, 0, w-x, " ", headerStyle)
+		label := textutil.SanitizeTerminalText(fmt.Sprintf("%d:%s", i+1, state.TabLabel(i)))
+		label = r.truncateTextToWidth(label, maxTabLabelWidth)
+		style := headerStyle.Dim(true)
+		if i == state.ActiveTab {
+			style = activeStyle
+		}
+		x = r.drawTextLine(x, 0, w-x, label, style)
+	}
+	if x < w {
+		x = r.drawTextLine(x, 0, w-x, " │", headerStyle)
+	}
+	return x
 }
 
 // fitBreadcrumb trims the breadcrumb path to fit within the available width
@@ -322,6 +395,12 @@ func (r *Renderer) drawStatusLine(state *statepkg.AppState, w, h int) {
 		r.screen.SetContent(i, y, ' ', nil, pathStyle)
 	}
 
+	// An error banner takes the place of the help text
+	if banner := state.ErrorBanner(); banner != nil {
+		r.drawErrorBanner(state, banner, w, h-1)
+		return
+	}
+
 	// Draw help text on last line
 	helpText := buildFooterHelpText(state)
 	if helpText == "" {
@@ -332,11 +411,24 @@ func (r *Renderer) drawStatusLine(state *statepkg.AppState, w, h int) {
 	}
 	helpText = textutil.SanitizeTerminalText(helpText)
 
+	// Directory stats sit at the right edge; help hints give way to them.
+	statsText := ""
+	if !state.GlobalSearchActive && state.PendingConfirm == nil && state.Prompt == nil && state.Rename == nil && state.PathChoice == nil && state.ArchiveJob == nil {
+		statsText = " " + formatViewStats(state.ViewStats()) + " "
+	}
+	statsWidth := textutil.DisplayWidth(statsText)
+	helpWidth := w
+	if statsWidth > 0 && statsWidth < w/2 {
+		helpWidth = w - statsWidth
+	} else {
+		statsText, statsWidth = "", 0
+	}
+
 	helpY := h - 1
 	x = 0
 	g = uniseg.NewGraphemes(helpText)
 	for g.Next() {
-		if x >= w {
+		if x >= helpWidth {
 			break
 		}
 		cluster := g.Str()
@@ -351,10 +443,13 @@ func (r *Renderer) drawStatusLine(state *statepkg.AppState, w, h int) {
 		x += wc
 	}
 	// Fill remaining spaces
-	for x < w {
+	for x < helpWidth {
 		r.screen.SetContent(x, helpY, ' ', nil, normalStyle)
 		x++
 	}
+	if statsText != "" {
+		r.drawTextLine(helpWidth, helpY, statsWidth, statsText, normalStyle.Dim(true))
+	}
 }
 
 // drawSidebar renders the left sidebar with entries from the parent directory
@@ -363,10 +458,6 @@ func (r *Renderer) drawSidebar(state *statepkg.AppState, sidebarWidth, h int) {
 
 	parentPath := filepath.Dir(state.CurrentPath)
 	hasParent := parentPath != "" && parentPath != state.CurrentPath
-	currentName := filepath.Base(state.CurrentPath)
-	if currentName == "" {
-		currentName = state.CurrentPath
-	}
 
 	y := 1
 	entries := state.ParentEntries
@@ -394,34 +485,7 @@ func (r *Renderer) drawSidebar(state *statepkg.AppState, sidebarWidth, h int) {
 			maxRows = 1
 		}
 
-		currentIdx := 0
-		foundCurrent := false
-		for idx, entry := range entries {
-			if entry.Name == currentName {
-				currentIdx = idx
-				foundCurrent = true
-				break
-			}
-		}
-		if !foundCurrent {
-			currentIdx = 0
-		}
-
-		startIdx := 0
-		if len(entries) > maxRows {
-			startIdx = currentIdx - maxRows/2
-			if startIdx < 0 {
-				startIdx = 0
-			}
-			if startIdx > len(entries)-maxRows {
-				startIdx = len(entries) - maxRows
-			}
-		}
-
-		endIdx := len(entries)
-		if endIdx-startIdx > maxRows {
-			endIdx = startIdx + maxRows
-		}
+		startIdx, endIdx, currentIdx := state.ParentPaneWindow(maxRows)
 
 		for i := startIdx; i < endIdx; i++ {
 			entry := entries[i]
@@ -430,7 +494,7 @@ func (r *Renderer) drawSidebar(state *statepkg.AppState, sidebarWidth, h int) {
 			}
 
 			rowStyle := baseBgStyle
-			isCurrent := entry.Name == currentName
+			isCurrent := i == currentIdx
 			if isCurrent {
 				rowStyle = tcell.StyleDefault.Background(r.theme.SidebarActiveBg).Foreground(r.theme.SidebarActiveFg)
 			} else if entry.IsSymlink {
@@ -453,7 +517,7 @@ func (r *Renderer) drawSidebar(state *statepkg.AppState, sidebarWidth, h int) {
 			nameWidth := sidebarWidth - r.measureTextWidth(prefix)
 			displayName := textutil.SanitizeTerminalText(entry.Name)
 			if nameWidth > 0 {
-				displayName = r.truncateTextToWidth(displayName, nameWidth)
+				displayName = r.truncateName(displayName, nameWidth, state.Truncate.Sidebar)
 			} else {
 				displayName = ""
 			}
@@ -477,6 +541,31 @@ func (r *Renderer) drawSidebar(state *statepkg.AppState, sidebarWidth, h int) {
 	}
 }
 
+// drawInputLine draws a text input with the character under the cursor
+// highlighted, or a block after them when the cursor is at the end, and
+// returns the column after the input.
+func (r *Renderer) drawInputLine(x, y, maxX int, text []rune, cursor int, style, cursorStyle tcell.Style) int {
+	cursor = min(max(cursor, 0), len(text))
+	idx := 0
+	g := uniseg.NewGraphemes(string(text))
+	for g.Next() && x < maxX {
+		// A cluster, such as a letter with a combining accent, shares a cell.
+		cluster := g.Runes()
+		clusterStyle := style
+		if cursor >= idx && cursor < idx+len(cluster) {
+			clusterStyle = cursorStyle
+		}
+		width := max(textutil.DisplayWidth(g.Str()), 1)
+		r.screen.SetContent(x, y, cluster[0], cluster[1:], clusterStyle)
+		x += width
+		idx += len(cluster)
+	}
+	if cursor == len(text) && x < maxX {
+		x = r.drawStyledRune(x, y, maxX, '█', cursorStyle)
+	}
+	return x
+}
+
 // drawMainPanel renders the file list
 func (r *Renderer) drawMainPanel(state *statepkg.AppState, startX, panelWidth, h int) {
 	baseBgStyle := tcell.StyleDefault.Background(r.theme.SidebarBg)
@@ -491,12 +580,6 @@ func (r *Renderer) drawMainPanel(state *statepkg.AppState, startX, panelWidth, h
 
 		cursor := state.GlobalSearchCursorPos
 		queryRunes := []rune(textutil.SanitizeTerminalText(state.GlobalSearchQuery))
-		if cursor < 0 {
-			cursor = 0
-		}
-		if cursor > len(queryRunes) {
-			cursor = len(queryRunes)
-		}
 
 		highlightStyle := headerStyle.Background(r.theme.SelectionBg).Foreground(r.theme.SelectionFg)
 		placeholderStyle := headerStyle.Dim(true)
@@ -523,25 +606,7 @@ func (r *Renderer) drawMainPanel(state *statepkg.AppState, startX, panelWidth, h
 				x = r.drawStyledRune(x, y, maxX, ru, placeholderStyle)
 			}
 		} else {
-			highlightIndex := -1
-			if cursor < len(queryRunes) {
-				highlightIndex = cursor
-			}
-
-			for idx, ru := range queryRunes {
-				if x >= maxX {
-					break
-				}
-				style := headerStyle
-				if idx == highlightIndex {
-					style = highlightStyle
-				}
-				x = r.drawStyledRune(x, y, maxX, ru, style)
-			}
-
-			if cursor == len(queryRunes) && x < maxX {
-				x = r.drawStyledRune(x, y, maxX, '█', highlightStyle)
-			}
+			x = r.drawInputLine(x, y, maxX, queryRunes, cursor, headerStyle, highlightStyle)
 		}
 
 		status := textutil.SanitizeTerminalText(formatSearchHeaderStatus(state, state.CurrentIndexStatus()))
@@ -558,13 +623,13 @@ func (r *Renderer) drawMainPanel(state *statepkg.AppState, startX, panelWidth, h
 			x = r.drawStyledRune(x, y, maxX, ' ', headerStyle)
 		}
 	} else if state.FilterActive {
-		headerText := "/" + textutil.SanitizeTerminalText(state.FilterQuery)
-		endX := r.drawTextLine(startX, 1, panelWidth, headerText, headerStyle)
-
+		line := state.FilterLine()
+		maxX := startX + panelWidth
+		endX := r.drawStyledRune(startX, 1, maxX, '/', headerStyle)
 		cursorStyle := headerStyle.Background(r.theme.SelectionBg).Foreground(r.theme.SelectionFg)
-		if endX < startX+panelWidth {
-			endX = r.drawStyledRune(endX, 1, startX+panelWidth, '█', cursorStyle)
-		}
+		before := []rune(textutil.SanitizeTerminalText(string(line.Text[:line.Cursor])))
+		after := []rune(textutil.SanitizeTerminalText(string(line.Text[line.Cursor:])))
+		endX = r.drawInputLine(endX, 1, maxX, append(before, after...), len(before), headerStyle, cursorStyle)
 		for x := endX; x < startX+panelWidth; x++ {
 			r.screen.SetContent(x, 1, ' ', nil, headerStyle)
 		}
@@ -587,7 +652,9 @@ func (r *Renderer) drawMainPanel(state *statepkg.AppState, startX, panelWidth, h
 func (r *Renderer) drawFileList(state *statepkg.AppState, startX, panelWidth, h int, listStartY int, baseBgStyle tcell.Style) {
 	// Draw file list
 	displayFiles := state.DisplayFiles()
-	bottomLimit := h - 2
+	panelRows := state.StagingPanelRows()
+	bottomLimit := h - 2 - panelRows
+	defer r.drawStagingPanel(state, startX, panelWidth, bottomLimit, panelRows, baseBgStyle)
 	if listStartY >= bottomLimit {
 		listStartY = bottomLimit - 1
 	}
@@ -614,6 +681,11 @@ func (r *Renderer) drawFileList(state *statepkg.AppState, startX, panelWidth, h
 		return
 	}
 
+	if grid := state.ListGrid(); grid.Columns > 1 {
+		r.drawFileGrid(state, displayFiles, grid, startX, panelWidth, listStartY, bottomLimit, baseBgStyle)
+		return
+	}
+
 	endIndex := state.ScrollOffset + visibleLines
 	if endIndex > len(displayFiles) {
 		endIndex = len(displayFiles)
@@ -624,66 +696,144 @@ func (r *Renderer) drawFileList(state *statepkg.AppState, startX, panelWidth, h
 		if displayY >= bottomLimit {
 			break
 		}
+		r.drawFileEntry(state, &displayFiles[displayIdx], displayIdx, startX, displayY, panelWidth, baseBgStyle)
+		displayY++
+	}
 
-		f := displayFiles[displayIdx]
+	// Fill rest with empty space
+	for y := displayY; y < bottomLimit; y++ {
+		for x := startX; x < startX+panelWidth; x++ {
+			r.screen.SetContent(x, y, ' ', nil, baseBgStyle)
+		}
+	}
+}
 
-		// Get actual file index for selection comparison (testable logic in state.go)
-		actualIdx := state.ActualIndexFromDisplayIndex(displayIdx)
+// drawFileGrid lays the entries out in columns, top to bottom and then left
+// to right, starting at the column scrolled to.
+func (r *Renderer) drawFileGrid(state *statepkg.AppState, displayFiles []statepkg.FileEntry, grid statepkg.ListGrid, startX, panelWidth, listStartY, bottomLimit int, baseBgStyle tcell.Style) {
+	r.clearPanelArea(startX, panelWidth, listStartY, bottomLimit+2, baseBgStyle)
+	columnWidth := grid.ColumnWidth
+	if limit := panelWidth / grid.Columns; columnWidth > limit {
+		columnWidth = limit
+	}
+	for column := 0; column < grid.Columns; column++ {
+		x := startX + column*columnWidth
+		for row := 0; row < grid.Rows && listStartY+row < bottomLimit; row++ {
+			displayIdx := state.ScrollOffset + column*grid.Rows + row
+			if displayIdx >= len(displayFiles) {
+				return
+			}
+			// The gap on the right keeps neighbouring columns apart.
+			r.drawFileEntry(state, &displayFiles[displayIdx], displayIdx, x, listStartY+row, columnWidth-2, baseBgStyle)
+		}
+	}
+}
 
-		isSelected := actualIdx == state.SelectedIndex
-		isHidden := f.IsHidden()
+// drawFileEntry draws one list entry in width cells at x, y.
+func (r *Renderer) drawFileEntry(state *statepkg.AppState, f *statepkg.FileEntry, displayIdx, x, y, width int, baseBgStyle tcell.Style) {
+	// Get actual file index for selection comparison (testable logic in state.go)
+	actualIdx := state.ActualIndexFromDisplayIndex(displayIdx)
 
-		// Highlight selected row
-		var rowStyle tcell.Style
-		if isSelected {
-			rowStyle = tcell.StyleDefault.Background(r.theme.SelectionBg).Foreground(r.theme.SelectionFg)
-		} else if f.IsSymlink {
-			rowStyle = baseBgStyle.Foreground(r.theme.SymlinkFg)
-		} else if f.IsDir {
-			rowStyle = baseBgStyle.Foreground(r.theme.DirectoryFg)
-		} else {
-			rowStyle = baseBgStyle.Foreground(r.theme.FileFg)
-		}
-		if isHidden && !isSelected {
-			rowStyle = rowStyle.Foreground(r.theme.HiddenFg)
-		}
+	isSelected := actualIdx == state.SelectedIndex
+	isHidden := f.IsHidden()
 
-		// Icon: @ for symlinks, / for directories, space for files
-		icon := " "
-		if f.IsSymlink {
-			icon = "@"
-		} else if f.IsDir {
-			icon = "/"
+	// Highlight selected row
+	var rowStyle tcell.Style
+	if isSelected {
+		rowStyle = tcell.StyleDefault.Background(r.theme.SelectionBg).Foreground(r.theme.SelectionFg)
+	} else if f.IsSymlink {
+		rowStyle = baseBgStyle.Foreground(r.theme.SymlinkFg)
+	} else if f.IsDir {
+		rowStyle = baseBgStyle.Foreground(r.theme.DirectoryFg)
+	} else {
+		rowStyle = baseBgStyle.Foreground(r.theme.FileFg)
+	}
+	if isHidden && !isSelected {
+		rowStyle = rowStyle.Foreground(r.theme.HiddenFg)
+	}
+	if state.IsMarked(f) {
+		if !isSelected {
+			rowStyle = rowStyle.Foreground(r.theme.MarkedFg)
 		}
+		rowStyle = rowStyle.Bold(true)
+	}
+
+	// Icon: @ for symlinks, / for directories, space for files
+	icon := " "
+	if f.IsSymlink {
+		icon = "@"
+	} else if f.IsDir {
+		icon = "/"
+	}
+
+	// Marker: * for entries staged for move, + for entries staged for copy
+	marker := " "
+	if state.IsStaged(f) {
+		marker = stagingMarker(state.Staging.Mode)
+	}
 
-		prefix := fmt.Sprintf(" %s ", icon)
-		nameWidth := panelWidth - r.measureTextWidth(prefix)
-		displayName := textutil.SanitizeTerminalText(f.Name)
-		if nameWidth > 0 {
-			displayName = r.truncateTextToWidth(displayName, nameWidth)
+	prefix := fmt.Sprintf("%s%s ", marker, icon)
+	scoreLabel := ""
+	if state.FilterActive && state.FilterScoreDebug {
+		if score, ok := state.FilterScoreFor(actualIdx); ok {
+			scoreLabel = filterScoreLabel(score, isSelected)
+		}
+	}
+	nameWidth := width - r.measureTextWidth(prefix)
+	if scoreLabel != "" {
+		if labelWidth := r.measureTextWidth(scoreLabel); nameWidth-labelWidth-1 >= 4 {
+			nameWidth -= labelWidth + 1
 		} else {
-			displayName = ""
+			scoreLabel = ""
 		}
+	}
+	displayName := textutil.SanitizeTerminalText(f.Name)
+	if nameWidth > 0 {
+		displayName = r.truncateName(displayName, nameWidth, state.Truncate.List)
+	} else {
+		displayName = ""
+	}
 
-		text := prefix + displayName
-
-		// Draw text with proper Unicode handling
-		endX := r.drawTextLine(startX, displayY, panelWidth, text, rowStyle)
+	text := prefix + displayName
+	renaming := isSelected && state.Rename != nil && nameWidth > 0 && filepath.Base(state.Rename.Path) == f.Name
+	if renaming {
+		text = prefix
+	}
 
-		// Fill remaining space with padding
-		for x := endX; x < startX+panelWidth; x++ {
-			r.screen.SetContent(x, displayY, ' ', nil, rowStyle)
-		}
+	// Draw text with proper Unicode handling
+	endX := r.drawTextLine(x, y, width, text, rowStyle)
+	if renaming {
+		endX = r.drawRenameField(endX, y, nameWidth, state.Rename, rowStyle)
+	}
 
-		displayY++
+	// Fill remaining space with padding
+	for fillX := endX; fillX < x+width; fillX++ {
+		r.screen.SetContent(fillX, y, ' ', nil, rowStyle)
+	}
+	if scoreLabel != "" {
+		labelX := x + width - r.measureTextWidth(scoreLabel)
+		r.drawTextLine(labelX, y, x+width-labelX, scoreLabel, rowStyle.Dim(!isSelected))
 	}
 
-	// Fill rest with empty space
-	for y := displayY; y < bottomLimit; y++ {
-		for x := startX; x < startX+panelWidth; x++ {
-			r.screen.SetContent(x, y, ' ', nil, baseBgStyle)
-		}
+}
+
+// filterScoreLabel formats a filter match's score for the Ctrl+D overlay;
+// the selected row shows how the total is made up.
+func filterScoreLabel(score statepkg.FilterScore, detailed bool) string {
+	if !detailed {
+		return fmt.Sprintf("%.2f", score.Total)
+	}
+	label := fmt.Sprintf("match %.2f", score.Match)
+	if score.Prefix != 0 {
+		label += fmt.Sprintf(" prefix %+.2f", score.Prefix)
 	}
+	if score.Dir != 0 {
+		label += fmt.Sprintf(" dir %+.2f", score.Dir)
+	}
+	if score.Ext != 0 {
+		label += fmt.Sprintf(" ext %+.2f", score.Ext)
+	}
+	return label + fmt.Sprintf(" = %.2f", score.Total)
 }
 
 // drawGlobalSearchResults renders global search results
@@ -817,6 +967,9 @@ func (r *Renderer) drawGlobalSearchResults(state *statepkg.AppState, startX, pan
 		}
 
 		segments := buildPathSegments(displayDir, displayFile, dirStyle, dirMatchStyle, fileStyle, fileMatchStyle)
+		if state.Truncate.Search == statepkg.TruncateMiddle {
+			segments, highlightSpans = elidePath(segments, highlightSpans, displayDir, displayFile, pathLimit-x)
+		}
 		offset := 0
 		for _, segment := range segments {
 			x, offset = r.drawSegmentWithHighlights(x, displayY, pathLimit, segment, highlightSpans, offset)
@@ -959,6 +1112,71 @@ func buildPathSegments(dirPart, fileName string, dirStyle, dirMatchStyle, fileSt
 	return segments
 }
 
+// elidePath shortens a search result path to maxWidth cells: the directory
+// loses its middle so the file name stays whole, and a name too long even
+// for that is shown alone with its own middle cut. Segments and highlight
+// spans are rewritten to match the shortened text.
+func elidePath(segments []pathSegment, spans []highlightSpan, dirPart, fileName string, maxWidth int) ([]pathSegment, []highlightSpan) {
+	pathText := fileName
+	if dirPart != "" {
+		pathText = dirPart + string(filepath.Separator) + fileName
+	}
+	fileWidth := textutil.DisplayWidth(fileName)
+	ellipsisWidth := textutil.DisplayWidth(textutil.Ellipsis)
+	if dirPart != "" && fileWidth+1+ellipsisWidth <= maxWidth {
+		if start, end, ok := textutil.MiddleCut(pathText, maxWidth, fileWidth+1); ok {
+			return cutPathSegments(segments, spans, start, end, textutil.Ellipsis)
+		}
+		return segments, spans
+	}
+	if textutil.DisplayWidth(pathText) <= maxWidth {
+		return segments, spans
+	}
+	if dirPart != "" {
+		segments, spans = cutPathSegments(segments, spans, 0, len([]rune(dirPart))+1, "")
+	}
+	if start, end, ok := textutil.MiddleCut(fileName, maxWidth, textutil.NameTailWidth(fileName, maxWidth)); ok {
+		segments, spans = cutPathSegments(segments, spans, start, end, textutil.Ellipsis)
+	}
+	return segments, spans
+}
+
+// cutPathSegments replaces the runes [start, end) of the text spread over
+// segments with repl, drawn in the style of the segment where the cut
+// starts, and moves highlight spans after the cut accordingly.
+func cutPathSegments(segments []pathSegment, spans []highlightSpan, start, end int, repl string) ([]pathSegment, []highlightSpan) {
+	cut := make([]pathSegment, 0, len(segments))
+	pos := 0
+	for _, segment := range segments {
+		var b strings.Builder
+		for _, ru := range segment.text {
+			if pos == start {
+				b.WriteString(repl)
+			}
+			if pos < start || pos >= end {
+				b.WriteRune(ru)
+			}
+			pos++
+		}
+		if b.Len() > 0 {
+			segment.text = b.String()
+			cut = append(cut, segment)
+		}
+	}
+
+	shift := len([]rune(repl)) - (end - start)
+	moved := make([]highlightSpan, 0, len(spans))
+	for _, span := range spans {
+		if span.start < start {
+			moved = append(moved, highlightSpan{start: span.start, end: min(span.end, start)})
+		}
+		if span.end > end {
+			moved = append(moved, highlightSpan{start: max(span.start, end) + shift, end: span.end + shift})
+		}
+	}
+	return cut, moved
+}
+
 func convertMatchSpansToHighlights(spans []statepkg.MatchSpan, text string) []highlightSpan {
 	if len(spans) == 0 || text == "" {
 		return nil
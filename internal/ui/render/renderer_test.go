@@ -1,9 +1,13 @@
 package render
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	statepkg "github.com/kk-code-lab/rdir/internal/state"
@@ -321,6 +325,53 @@ func TestDrawStatusLineSanitizesPath(t *testing.T) {
 	}
 }
 
+func TestDrawStatusLineShowsErrorBanner(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("failed to init simulation screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(100, 5)
+
+	r := NewRenderer(screen)
+	state := &statepkg.AppState{CurrentPath: filepath.FromSlash("/tmp")}
+	state.ReportError(statepkg.ErrorSourceGeneral, errors.New("clipboard failed"))
+	state.ReportError(statepkg.ErrorSourcePreview, fmt.Errorf("first\nsecond: %w", fs.ErrNotExist))
+
+	r.drawStatusLine(state, 100, 5)
+	screen.Show()
+
+	row := readScreenRow(t, screen, 4, 100)
+	for _, want := range []string{"preview · not found: first second: file does not exist", "Esc: dismiss", "+1 more"} {
+		if !strings.Contains(row, want) {
+			t.Fatalf("expected %q in the error banner, got %q", want, row)
+		}
+	}
+}
+
+func TestDrawInputLineKeepsClustersInOneCell(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("failed to init simulation screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(10, 1)
+
+	r := NewRenderer(screen)
+	cursorStyle := tcell.StyleDefault.Reverse(true)
+	end := r.drawInputLine(0, 0, 10, []rune("e\u0301x"), 0, tcell.StyleDefault, cursorStyle)
+	if end != 2 {
+		t.Fatalf("expected the accented letter and x to take 2 cells, got %d", end)
+	}
+	primary, combining, style, _ := screen.GetContent(0, 0)
+	if primary != 'e' || string(combining) != "\u0301" || style != cursorStyle {
+		t.Fatalf("expected e with its accent under the cursor, got %q %q", primary, combining)
+	}
+	if primary, _, _, _ := screen.GetContent(1, 0); primary != 'x' {
+		t.Fatalf("expected x in the second cell, got %q", primary)
+	}
+}
+
 func TestDrawFileListSanitizesNames(t *testing.T) {
 	screen := tcell.NewSimulationScreen("")
 	if err := screen.Init(); err != nil {
@@ -351,6 +402,257 @@ func TestDrawFileListSanitizesNames(t *testing.T) {
 	}
 }
 
+func TestDrawFileListShowsInlineRename(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("failed to init simulation screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(40, 8)
+
+	r := NewRenderer(screen)
+	state := &statepkg.AppState{
+		CurrentPath:   "/tmp",
+		Files:         []statepkg.FileEntry{{Name: "report.md"}},
+		SelectedIndex: 0,
+		Rename:        &statepkg.InlineRename{Path: "/tmp/report.md", Input: "report.md", Tail: 3, SelEnd: 6},
+	}
+
+	baseStyle := tcell.StyleDefault.Background(r.theme.SidebarBg)
+	r.drawFileList(state, 0, 30, 8, 1, baseStyle)
+	screen.Show()
+
+	if row := readScreenRow(t, screen, 1, 30); !strings.Contains(row, "report▏.md") {
+		t.Fatalf("expected the rename field with its cursor, got %q", row)
+	}
+	if _, _, style, _ := screen.GetContent(3, 1); !reversed(style) {
+		t.Fatal("expected the stem to be drawn selected")
+	}
+	if _, _, style, _ := screen.GetContent(10, 1); reversed(style) {
+		t.Fatal("expected the extension to be drawn unselected")
+	}
+
+	state.Rename = &statepkg.InlineRename{Path: "/tmp/report.md", Input: strings.Repeat("x", 40) + ".md"}
+	r.drawFileList(state, 0, 30, 8, 1, baseStyle)
+	screen.Show()
+	if row := readScreenRow(t, screen, 1, 30); !strings.Contains(row, "…") || !strings.Contains(row, "x.md▏") {
+		t.Fatalf("expected a long name trimmed from the left to keep the cursor visible, got %q", row)
+	}
+}
+
+func reversed(style tcell.Style) bool {
+	_, _, attrs := style.Decompose()
+	return attrs&tcell.AttrReverse != 0
+}
+
+func TestDrawFileListTruncatesPerColumnMode(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("failed to init simulation screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(40, 8)
+
+	r := NewRenderer(screen)
+	state := &statepkg.AppState{
+		CurrentPath: "/tmp",
+		Files:       []statepkg.FileEntry{{Name: "quarterly-report-final.pdf"}},
+	}
+	baseStyle := tcell.StyleDefault.Background(r.theme.SidebarBg)
+
+	// Marker, icon and space, then 17 cells of name.
+	r.drawFileList(state, 0, 20, 8, 1, baseStyle)
+	screen.Show()
+	if row := readScreenRow(t, screen, 1, 20); !strings.Contains(row, "quarterl…inal.pdf") {
+		t.Fatalf("expected middle truncation, got %q", row)
+	}
+
+	state.Truncate.List = statepkg.TruncateEnd
+	r.drawFileList(state, 0, 20, 8, 1, baseStyle)
+	screen.Show()
+	if row := readScreenRow(t, screen, 1, 20); !strings.Contains(row, "quarterly-report…") {
+		t.Fatalf("expected end truncation, got %q", row)
+	}
+}
+
+func TestDrawFileListInColumns(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("failed to init simulation screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(40, 8)
+
+	r := NewRenderer(screen)
+	state := &statepkg.AppState{
+		CurrentPath:     "/tmp",
+		ScreenHeight:    8,
+		ListWidth:       30,
+		ListGridEnabled: true,
+	}
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		state.Files = append(state.Files, statepkg.FileEntry{Name: name})
+	}
+	baseStyle := tcell.StyleDefault.Background(r.theme.SidebarBg)
+
+	// Seven entries fit four columns of two rows, filled top to bottom.
+	r.drawFileList(state, 0, 30, 8, 1, baseStyle)
+	screen.Show()
+	if row := readScreenRow(t, screen, 1, 30); row != "   a      c      e      g" {
+		t.Fatalf("first row = %q", row)
+	}
+	if row := readScreenRow(t, screen, 2, 30); row != "   b      d      f" {
+		t.Fatalf("second row = %q", row)
+	}
+}
+
+func TestPreviewShowsMarkSummary(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("failed to init simulation screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(40, 12)
+
+	r := NewRenderer(screen)
+	state := &statepkg.AppState{
+		CurrentPath: "/tmp",
+		MarkSummary: &statepkg.MarkSummary{
+			Count:      2,
+			Files:      3,
+			Dirs:       1,
+			Size:       2048,
+			Extensions: []statepkg.MarkExtension{{Ext: ".go", Files: 2, Size: 2000}, {Files: 1, Size: 48}},
+			Largest:    []statepkg.MarkItem{{Path: "/tmp/src", IsDir: true, Size: 2000}, {Path: "/tmp/README", Size: 48}},
+		},
+	}
+	r.drawPreviewPanel(state, layoutMetrics{previewWidth: 40, showPreview: true}, 40, 12)
+	screen.Show()
+
+	want := []string{
+		" 2 marked · 2.0 KiB",
+		" 3 files · 1 folder",
+		"",
+		" By extension",
+		"   .go  2 files                 2.0 KiB",
+		"   (none)  1 file                  48 B",
+		"",
+		" Largest",
+		" / src                          2.0 KiB",
+		"   README                          48 B",
+	}
+	for i, line := range want {
+		if row := strings.TrimRight(readScreenRow(t, screen, i+1, 40), " "); row != line {
+			t.Fatalf("row %d = %q, want %q", i+1, row, line)
+		}
+	}
+}
+
+func TestConflictOverlay(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("failed to init simulation screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(60, 16)
+
+	r := NewRenderer(screen)
+	modTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+	dialog := &statepkg.ConflictDialog{
+		Verb: "paste",
+		Items: []statepkg.Conflict{
+			{Source: "/src/a.txt", Dest: "/dst/a.txt", SourceSize: 2048, SourceModTime: modTime.Add(time.Hour), DestSize: 10, DestModTime: modTime},
+			{Source: "/src/lib", Dest: "/dst/lib", SourceIsDir: true, DestIsDir: true},
+		},
+		Choices: make([]statepkg.ConflictChoice, 2),
+		Pattern: "{name} ({n}){ext}",
+	}
+	state := &statepkg.AppState{Conflicts: dialog}
+	r.Render(state)
+
+	want := map[int]string{
+		0:  "                Paste: 2 names already exist",
+		2:  "  1 of 2: a.txt",
+		4:  "  new       2.0 KiB   2024-05-01 13:00:00  /src/a.txt",
+		5:  "  existing  10 B      2024-05-01 12:00:00  /dst/a.txt",
+		6:  "  the new one is newer",
+		10: "  r  rename to {name} ({n}){ext}",
+	}
+	for y, line := range want {
+		if row := strings.TrimRight(readScreenRow(t, screen, y, 60), " "); row != line {
+			t.Fatalf("row %d = %q, want %q", y, row, line)
+		}
+	}
+
+	dialog.Choices[0] = statepkg.ConflictOverwrite
+	dialog.Index = 2
+	r.Render(state)
+	want = map[int]string{
+		2: "  Decisions: 1 rename · 1 overwrite",
+		3: "  renamed with {name} ({n}){ext}",
+		5: "  overwrite   a.txt",
+		6: "  rename      lib",
+	}
+	for y, line := range want {
+		if row := strings.TrimRight(readScreenRow(t, screen, y, 60), " "); row != line {
+			t.Fatalf("summary row %d = %q, want %q", y, row, line)
+		}
+	}
+	if footer := readScreenRow(t, screen, 15, 60); !strings.HasPrefix(footer, "↵ paste · ←/u back") {
+		t.Fatalf("footer = %q", footer)
+	}
+}
+
+func TestElidePathKeepsFileNameAndHighlights(t *testing.T) {
+	dirPart := filepath.Join("src", "components", "forms")
+	fileName := "Input.tsx"
+	pathText := dirPart + string(filepath.Separator) + fileName
+	segments := buildPathSegments(dirPart, fileName, tcell.StyleDefault, tcell.StyleDefault, tcell.StyleDefault, tcell.StyleDefault)
+	runes := len([]rune(pathText))
+	spans := []highlightSpan{{start: 0, end: 3}, {start: runes - 9, end: runes - 4}}
+
+	out, moved := elidePath(segments, spans, dirPart, fileName, 18)
+	var b strings.Builder
+	for _, segment := range out {
+		b.WriteString(segment.text)
+	}
+	want := "src" + string(filepath.Separator) + "com…" + string(filepath.Separator) + "Input.tsx"
+	if b.String() != want {
+		t.Fatalf("elided path = %q, want %q", b.String(), want)
+	}
+	textRunes := []rune(b.String())
+	if got := string(textRunes[moved[0].start:moved[0].end]); got != "src" {
+		t.Fatalf("first highlight = %q", got)
+	}
+	if got := string(textRunes[moved[1].start:moved[1].end]); got != "Input" {
+		t.Fatalf("second highlight = %q", got)
+	}
+
+	out, _ = elidePath(segments, spans, dirPart, fileName, 6)
+	if len(out) != 1 || out[0].text != "I….tsx" {
+		t.Fatalf("expected the name alone, got %+v", out)
+	}
+}
+
+func TestHeaderShowsSlowPathBadge(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("failed to init simulation screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(50, 4)
+
+	r := NewRenderer(screen)
+	state := &statepkg.AppState{CurrentPath: "/mnt/share/projects", SlowPath: true, SlowPathKind: "nfs4"}
+	r.drawHeader(state, 50, 4)
+	screen.Show()
+
+	row := readScreenRow(t, screen, 0, 50)
+	if !strings.HasSuffix(strings.TrimRight(row, " "), "⇄ nfs4") || !strings.Contains(row, "projects") {
+		t.Fatalf("expected breadcrumb and nfs4 badge, got %q", row)
+	}
+}
+
 func TestPreviewDrawsMarkdownFrontmatterTitle(t *testing.T) {
 	screen := tcell.NewSimulationScreen("")
 	if err := screen.Init(); err != nil {
@@ -404,3 +706,39 @@ func readScreenRow(t *testing.T, screen tcell.SimulationScreen, y, width int) st
 	}
 	return strings.TrimRight(b.String(), " ")
 }
+
+func TestFilterScoreLabel(t *testing.T) {
+	score := statepkg.FilterScore{Match: 2.5, Prefix: 1, Ext: -0.5, Total: 3}
+	if got := filterScoreLabel(score, false); got != "3.00" {
+		t.Fatalf("compact label = %q", got)
+	}
+	if got, want := filterScoreLabel(score, true), "match 2.50 prefix +1.00 ext -0.50 = 3.00"; got != want {
+		t.Fatalf("detailed label = %q, want %q", got, want)
+	}
+}
+
+func TestMarkdownLinesLaysOutVisibleRowsAndReusesThem(t *testing.T) {
+	var source []string
+	for i := 0; i < 100; i++ {
+		source = append(source, fmt.Sprintf("## Section %d", i), "", "body", "")
+	}
+	preview := &statepkg.PreviewData{TextLines: source, FormattedKind: "markdown"}
+	r := NewRenderer(tcell.NewSimulationScreen(""))
+
+	lines, _ := r.markdownLines(preview, 40, 0, true, 10)
+	if len(lines) < 10 || len(lines) > 20 {
+		t.Fatalf("expected about 10 visible lines laid out, got %d", len(lines))
+	}
+	again, _ := r.markdownLines(preview, 40, 0, true, 8)
+	if &again[0] != &lines[0] {
+		t.Fatalf("expected the layout to be reused for fewer rows at the same width")
+	}
+	more, _ := r.markdownLines(preview, 40, 0, true, 30)
+	if len(more) < 30 {
+		t.Fatalf("expected at least 30 lines after scrolling, got %d", len(more))
+	}
+	narrower, _ := r.markdownLines(preview, 30, 0, true, 8)
+	if &narrower[0] == &more[0] {
+		t.Fatalf("expected a width change to lay the document out again")
+	}
+}
@@ -0,0 +1,146 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
+)
+
+// snapshotStyleKeys letters the distinct styles of a snapshot in order of
+// first appearance; '.' is the default style and '*' covers any overflow.
+const snapshotStyleKeys = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+// NewSnapshotRenderer returns a renderer drawing into an off-screen
+// width x height canvas instead of a terminal. After Render, Snapshot returns
+// the frame as stable text, for golden-file tests of themes and layouts.
+func NewSnapshotRenderer(width, height int) (*Renderer, error) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		return nil, err
+	}
+	screen.SetSize(width, height)
+	return NewRenderer(screen), nil
+}
+
+// SetTheme replaces the colors used for the next Render.
+func (r *Renderer) SetTheme(theme ColorTheme) {
+	r.theme = theme
+}
+
+// Snapshot returns the last rendered frame in the FormatSnapshot layout. The
+// second return value is false when the renderer draws to a real terminal.
+func (r *Renderer) Snapshot() (string, bool) {
+	sim, ok := r.cells.Screen.(tcell.SimulationScreen)
+	if !ok {
+		return "", false
+	}
+	cells, width, height := sim.GetContents()
+	return FormatSnapshot(cells, width, height), true
+}
+
+// FormatSnapshot renders cells as three sections that do not depend on the
+// terminal: the text of each row, a style mask with one letter per cell, and
+// a legend spelling out each letter's colors and attributes. Trailing blanks
+// and trailing default-styled cells are trimmed so diffs stay small.
+func FormatSnapshot(cells []tcell.SimCell, width, height int) string {
+	letters := make(map[tcell.Style]byte)
+	var legend []string
+	letterFor := func(style tcell.Style) byte {
+		if style == tcell.StyleDefault {
+			return '.'
+		}
+		if letter, ok := letters[style]; ok {
+			return letter
+		}
+		letter := byte('*')
+		if len(legend) < len(snapshotStyleKeys) {
+			letter = snapshotStyleKeys[len(legend)]
+			legend = append(legend, string(letter)+" "+describeStyle(style))
+		}
+		letters[style] = letter
+		return letter
+	}
+
+	text := make([]string, height)
+	masks := make([]string, height)
+	for y := 0; y < height; y++ {
+		var line strings.Builder
+		mask := make([]byte, 0, width)
+		for x := 0; x < width; x++ {
+			idx := y*width + x
+			if idx >= len(cells) {
+				break
+			}
+			cell := cells[idx]
+			letter := letterFor(cell.Style)
+			mask = append(mask, letter)
+			cluster := string(cell.Runes)
+			if cluster == "" {
+				cluster = " "
+			}
+			line.WriteString(cluster)
+			if textutil.DisplayWidth(cluster) > 1 && x+1 < width {
+				// The continuation cell shows the wide character; it adds no text.
+				x++
+				mask = append(mask, letter)
+			}
+		}
+		text[y] = strings.TrimRight(line.String(), " ")
+		masks[y] = strings.TrimRight(string(mask), ".")
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "size %dx%d\n-- text\n", width, height)
+	for _, row := range text {
+		out.WriteString(row + "\n")
+	}
+	out.WriteString("-- styles\n")
+	for _, row := range masks {
+		out.WriteString(row + "\n")
+	}
+	out.WriteString("-- legend\n. default\n")
+	for _, entry := range legend {
+		out.WriteString(entry + "\n")
+	}
+	if len(letters) > len(legend) {
+		out.WriteString("* other styles\n")
+	}
+	return out.String()
+}
+
+func describeStyle(style tcell.Style) string {
+	fg, bg, attrs := style.Decompose()
+	parts := []string{"fg=" + describeColor(fg), "bg=" + describeColor(bg)}
+	for _, attr := range []struct {
+		mask tcell.AttrMask
+		name string
+	}{
+		{tcell.AttrBold, "bold"},
+		{tcell.AttrDim, "dim"},
+		{tcell.AttrItalic, "italic"},
+		{tcell.AttrUnderline, "underline"},
+		{tcell.AttrStrikeThrough, "strikethrough"},
+		{tcell.AttrReverse, "reverse"},
+		{tcell.AttrBlink, "blink"},
+	} {
+		if attrs&attr.mask != 0 {
+			parts = append(parts, attr.name)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// describeColor names palette colors by index and RGB colors in hex, so the
+// result does not depend on the terminal's palette.
+func describeColor(c tcell.Color) string {
+	switch {
+	case c == tcell.ColorDefault || !c.Valid():
+		return "default"
+	case c.IsRGB():
+		return fmt.Sprintf("#%06x", c.Hex())
+	default:
+		return fmt.Sprintf("%d", int(c-tcell.ColorValid))
+	}
+}
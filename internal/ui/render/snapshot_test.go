@@ -0,0 +1,82 @@
+package render
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
+)
+
+func TestFormatSnapshotLettersStyles(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("failed to init simulation screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(8, 2)
+
+	bold := tcell.StyleDefault.Foreground(tcell.ColorRed).Bold(true)
+	rgb := tcell.StyleDefault.Background(tcell.NewRGBColor(0x12, 0x34, 0x56))
+	screen.SetContent(0, 0, 'a', nil, bold)
+	screen.SetContent(1, 0, '界', nil, rgb)
+	screen.SetContent(3, 0, 'b', nil, bold)
+	screen.SetContent(0, 1, 'c', nil, tcell.StyleDefault)
+	screen.Show()
+
+	cells, w, h := screen.GetContents()
+	got := FormatSnapshot(cells, w, h)
+	want := strings.Join([]string{
+		"size 8x2",
+		"-- text",
+		"a界b",
+		"c",
+		"-- styles",
+		"ABBA",
+		"",
+		"-- legend",
+		". default",
+		"A fg=9 bg=default bold",
+		"B fg=default bg=#123456",
+		"",
+	}, "\n")
+	if got != want {
+		t.Fatalf("snapshot mismatch\n got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSnapshotRendererIsRepeatable(t *testing.T) {
+	snapshot := func() string {
+		r, err := NewSnapshotRenderer(40, 6)
+		if err != nil {
+			t.Fatalf("NewSnapshotRenderer: %v", err)
+		}
+		r.Render(&statepkg.AppState{
+			CurrentPath: filepath.FromSlash("/tmp"),
+			Files: []statepkg.FileEntry{
+				{Name: "docs", IsDir: true},
+				{Name: "notes.txt", Size: 12},
+			},
+			ScreenWidth:  40,
+			ScreenHeight: 6,
+		})
+		out, ok := r.Snapshot()
+		if !ok {
+			t.Fatalf("expected a snapshot from the off-screen renderer")
+		}
+		return out
+	}
+
+	first := snapshot()
+	if !strings.Contains(first, "notes.txt") || !strings.Contains(first, "-- legend") {
+		t.Fatalf("unexpected snapshot:\n%s", first)
+	}
+	if second := snapshot(); second != first {
+		t.Fatalf("snapshots differ:\n%s\n---\n%s", first, second)
+	}
+
+	if _, ok := NewRenderer(nil).Snapshot(); ok {
+		t.Fatalf("a terminal renderer has no snapshot")
+	}
+}
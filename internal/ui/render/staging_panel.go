@@ -0,0 +1,60 @@
+package render
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gdamore/tcell/v2"
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
+	"github.com/kk-code-lab/rdir/internal/textutil"
+)
+
+// stagingMarker returns the list marker used for staged entries.
+func stagingMarker(mode statepkg.StagingMode) string {
+	if mode == statepkg.StagingCopy {
+		return "+"
+	}
+	return "*"
+}
+
+// drawStagingPanel renders the staged paths below the file list in rows
+// [topY, topY+rows).
+func (r *Renderer) drawStagingPanel(state *statepkg.AppState, startX, panelWidth, topY, rows int, baseBgStyle tcell.Style) {
+	if rows <= 0 || panelWidth <= 0 {
+		return
+	}
+
+	staged := state.Staging.Paths
+	verb := "move"
+	if state.Staging.Mode == statepkg.StagingCopy {
+		verb = "copy"
+	}
+
+	titleStyle := tcell.StyleDefault.Background(r.theme.SelectionBg).Foreground(r.theme.SelectionFg).Bold(true)
+	title := fmt.Sprintf(" Staged for %s (%d)  p: paste  X: clear", verb, len(staged))
+	r.fillStagingRow(startX, topY, panelWidth, r.truncateTextToWidth(title, panelWidth), titleStyle)
+
+	entryRows := rows - 1
+	overflow := len(staged) - entryRows
+	marker := stagingMarker(state.Staging.Mode)
+	entryStyle := baseBgStyle.Foreground(r.theme.SidebarFg)
+	for i := 0; i < entryRows && i < len(staged); i++ {
+		y := topY + 1 + i
+		if overflow > 0 && i == entryRows-1 {
+			more := fmt.Sprintf("   … %d more", overflow+1)
+			r.fillStagingRow(startX, y, panelWidth, r.truncateTextToWidth(more, panelWidth), entryStyle.Dim(true))
+			break
+		}
+		path := staged[i]
+		line := fmt.Sprintf(" %s %s  %s", marker, filepath.Base(path), filepath.Dir(path))
+		line = textutil.SanitizeTerminalText(line)
+		r.fillStagingRow(startX, y, panelWidth, r.truncateTextToWidth(line, panelWidth), entryStyle)
+	}
+}
+
+func (r *Renderer) fillStagingRow(startX, y, panelWidth int, text string, style tcell.Style) {
+	endX := r.drawTextLine(startX, y, panelWidth, text, style)
+	for x := endX; x < startX+panelWidth; x++ {
+		r.screen.SetContent(x, y, ' ', nil, style)
+	}
+}
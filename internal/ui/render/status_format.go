@@ -7,6 +7,7 @@ import (
 	"time"
 
 	statepkg "github.com/kk-code-lab/rdir/internal/state"
+	textutil "github.com/kk-code-lab/rdir/internal/textutil"
 )
 
 func formatSearchHeaderStatus(state *statepkg.AppState, status statepkg.IndexTelemetry) string {
@@ -16,6 +17,9 @@ func formatSearchHeaderStatus(state *statepkg.AppState, status statepkg.IndexTel
 	} else if state.GlobalSearchInProgress {
 		parts = append(parts, "searching…")
 	}
+	if state.GlobalSearchSort != statepkg.SearchSortScore {
+		parts = append(parts, "sort: "+state.GlobalSearchSort.String())
+	}
 	if summary := formatIndexHeaderSummary(status); summary != "" {
 		parts = append(parts, summary)
 	}
@@ -175,3 +179,21 @@ func formatDurationShort(d time.Duration) string {
 		return trimTrailingZero(fmt.Sprintf("%.1fh", d.Hours()))
 	}
 }
+
+// formatViewStats summarises the current directory view, e.g.
+// "12/40 shown · 3.4 MiB · 2 marked".
+func formatViewStats(stats statepkg.ViewStats) string {
+	var parts []string
+	if stats.Shown == stats.Total {
+		parts = append(parts, formatItemCount(stats.Total))
+	} else {
+		parts = append(parts, fmt.Sprintf("%d/%d shown", stats.Shown, stats.Total))
+	}
+	if stats.ListedBytes > 0 {
+		parts = append(parts, textutil.FormatSize(stats.ListedBytes))
+	}
+	if stats.Marked > 0 {
+		parts = append(parts, fmt.Sprintf("%d marked", stats.Marked))
+	}
+	return strings.Join(parts, " · ")
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/uniseg"
 
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
 	textutil "github.com/kk-code-lab/rdir/internal/textutil"
 )
 
@@ -57,6 +58,15 @@ func (r *Renderer) truncateTextToWidth(text string, maxWidth int) string {
 	return builder.String()
 }
 
+// truncateName fits a file name into maxWidth cells, cutting its middle
+// (keeping the extension) or its end per the column's mode.
+func (r *Renderer) truncateName(name string, maxWidth int, mode statepkg.TruncateMode) string {
+	if mode == statepkg.TruncateEnd {
+		return r.truncateTextToWidth(name, maxWidth)
+	}
+	return textutil.TruncateName(name, maxWidth)
+}
+
 func (r *Renderer) drawTextLine(startX, y, maxWidth int, text string, style tcell.Style) int {
 	x := startX
 	g := uniseg.NewGraphemes(text)
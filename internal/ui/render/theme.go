@@ -16,6 +16,7 @@ type ColorTheme struct {
 	DirectoryFg     tcell.Color
 	SymlinkFg       tcell.Color
 	FileFg          tcell.Color
+	MarkedFg        tcell.Color
 	FooterBg        tcell.Color
 	FooterFg        tcell.Color
 	PreviewBg       tcell.Color
@@ -41,6 +42,7 @@ func GetColorTheme() ColorTheme {
 		DirectoryFg:     tcell.Color33,
 		SymlinkFg:       tcell.Color51,
 		FileFg:          tcell.ColorDefault,
+		MarkedFg:        tcell.Color214, // amber for marked entries
 		FooterBg:        tcell.ColorDefault,
 		FooterFg:        tcell.ColorDefault,
 		PreviewBg:       tcell.ColorDefault,
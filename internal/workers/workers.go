@@ -0,0 +1,126 @@
+// Package workers sizes the goroutine pools used by CPU-bound background
+// tasks (search indexing, disk-usage scans).
+//
+// The effective count for a task is resolved in this order:
+//
+//  1. a runtime override set via SetTaskLimit
+//  2. the per-task environment variable (RDIR_WORKERS_INDEX, ...)
+//  3. for indexing, RDIR_INDEX_MAX_WORKERS, which caps the CPU count
+//  4. a runtime global limit set via SetLimit
+//  5. the global environment variable RDIR_WORKERS
+//  6. an automatic default derived from GOMAXPROCS
+package workers
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Task identifies a class of background work with its own worker budget.
+type Task string
+
+const (
+	TaskIndex     Task = "index"
+	TaskDiskUsage Task = "du"
+)
+
+const (
+	// EnvWorkers sets the worker count for every task.
+	EnvWorkers = "RDIR_WORKERS"
+	// envTaskPrefix is combined with the upper-cased task name, e.g. RDIR_WORKERS_INDEX.
+	envTaskPrefix = "RDIR_WORKERS_"
+	// envLegacyIndexWorkers predates RDIR_WORKERS_INDEX and keeps its
+	// meaning: an upper bound on one worker per CPU but one, not a count.
+	envLegacyIndexWorkers = "RDIR_INDEX_MAX_WORKERS"
+
+	minAutoWorkers = 2
+	maxAutoWorkers = 8
+)
+
+var (
+	getenv     = os.Getenv
+	gomaxprocs = func() int { return runtime.GOMAXPROCS(0) }
+
+	mu        sync.RWMutex
+	limit     int
+	overrides = make(map[Task]int)
+)
+
+// Auto reports the default worker count for this machine: one less than the
+// available CPUs so the UI goroutine stays responsive, clamped to a range that
+// keeps small laptops busy without flooding large workstations with I/O.
+func Auto() int {
+	return min(perCPU(), maxAutoWorkers)
+}
+
+// perCPU is one worker per CPU but one, and at least minAutoWorkers.
+func perCPU() int {
+	return max(gomaxprocs()-1, minAutoWorkers)
+}
+
+// Count returns the number of goroutines the given task should use. It is
+// read at the start of each job so runtime adjustments apply to the next run.
+func Count(task Task) int {
+	mu.RLock()
+	override, hasOverride := overrides[task]
+	global := limit
+	mu.RUnlock()
+
+	if hasOverride {
+		return override
+	}
+	if n := envCount(taskEnvName(task)); n > 0 {
+		return n
+	}
+	if task == TaskIndex {
+		if n := envCount(envLegacyIndexWorkers); n > 0 {
+			return min(n, perCPU())
+		}
+	}
+	if global > 0 {
+		return global
+	}
+	if n := envCount(EnvWorkers); n > 0 {
+		return n
+	}
+	return Auto()
+}
+
+// SetLimit adjusts the worker count for all tasks at runtime. Values <= 0
+// clear the adjustment and fall back to the environment or automatic default.
+func SetLimit(n int) {
+	mu.Lock()
+	defer mu.Unlock()
+	limit = max(n, 0)
+}
+
+// SetTaskLimit overrides the worker count for a single task at runtime.
+// Values <= 0 remove the override.
+func SetTaskLimit(task Task, n int) {
+	mu.Lock()
+	defer mu.Unlock()
+	if n <= 0 {
+		delete(overrides, task)
+		return
+	}
+	overrides[task] = n
+}
+
+func taskEnvName(task Task) string {
+	return envTaskPrefix + strings.ToUpper(string(task))
+}
+
+func envCount(name string) int {
+	val := strings.TrimSpace(getenv(name))
+	if val == "" {
+		return 0
+	}
+	parsed, err := strconv.Atoi(val)
+	if err != nil || parsed <= 0 {
+		return 0
+	}
+	return parsed
+}
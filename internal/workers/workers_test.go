@@ -0,0 +1,88 @@
+package workers
+
+import "testing"
+
+func withEnv(t *testing.T, env map[string]string, procs int) {
+	t.Helper()
+	prevGetenv, prevProcs := getenv, gomaxprocs
+	getenv = func(key string) string { return env[key] }
+	gomaxprocs = func() int { return procs }
+	t.Cleanup(func() {
+		getenv, gomaxprocs = prevGetenv, prevProcs
+		SetLimit(0)
+		for _, task := range []Task{TaskIndex, TaskDiskUsage} {
+			SetTaskLimit(task, 0)
+		}
+	})
+}
+
+func TestAutoClampsToRange(t *testing.T) {
+	tests := []struct {
+		procs int
+		want  int
+	}{
+		{procs: 1, want: 2},
+		{procs: 4, want: 3},
+		{procs: 64, want: 8},
+	}
+	for _, tt := range tests {
+		withEnv(t, nil, tt.procs)
+		if got := Auto(); got != tt.want {
+			t.Fatalf("Auto() with %d procs = %d, want %d", tt.procs, got, tt.want)
+		}
+	}
+}
+
+func TestCountResolutionOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		procs   int
+		limit   int
+		taskSet int
+		task    Task
+		want    int
+	}{
+		{name: "auto default", procs: 6, want: 5},
+		{name: "global env", env: map[string]string{EnvWorkers: "3"}, procs: 6, want: 3},
+		{name: "invalid env ignored", env: map[string]string{EnvWorkers: "lots"}, procs: 6, want: 5},
+		{name: "task env beats global env", env: map[string]string{EnvWorkers: "3", "RDIR_WORKERS_INDEX": "12"}, procs: 6, want: 12},
+		{name: "legacy index env caps the CPU count", env: map[string]string{"RDIR_INDEX_MAX_WORKERS": "1"}, procs: 6, want: 1},
+		{name: "legacy index env is a cap, not a count", env: map[string]string{"RDIR_INDEX_MAX_WORKERS": "32"}, procs: 6, want: 5},
+		{name: "legacy index env lifts the auto clamp", env: map[string]string{"RDIR_INDEX_MAX_WORKERS": "32"}, procs: 16, want: 15},
+		{name: "task env beats legacy index env", env: map[string]string{"RDIR_INDEX_MAX_WORKERS": "1", "RDIR_WORKERS_INDEX": "6"}, procs: 6, want: 6},
+		{name: "runtime limit beats global env", env: map[string]string{EnvWorkers: "3"}, procs: 6, limit: 12, task: TaskDiskUsage, want: 12},
+		{name: "task env beats runtime limit", env: map[string]string{"RDIR_WORKERS_DU": "4"}, procs: 6, limit: 12, task: TaskDiskUsage, want: 4},
+		{name: "runtime task override wins", env: map[string]string{"RDIR_WORKERS_INDEX": "6"}, procs: 6, limit: 12, taskSet: 32, want: 32},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withEnv(t, tt.env, tt.procs)
+			task := tt.task
+			if task == "" {
+				task = TaskIndex
+			}
+			SetLimit(tt.limit)
+			SetTaskLimit(task, tt.taskSet)
+			if got := Count(task); got != tt.want {
+				t.Fatalf("Count(%s) = %d, want %d", task, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetTaskLimitOnlyAffectsThatTask(t *testing.T) {
+	withEnv(t, nil, 6)
+	SetTaskLimit(TaskDiskUsage, 16)
+	if got := Count(TaskDiskUsage); got != 16 {
+		t.Fatalf("Count(du) = %d, want 16", got)
+	}
+	if got := Count(TaskIndex); got != 5 {
+		t.Fatalf("Count(index) = %d, want auto default 5", got)
+	}
+	SetTaskLimit(TaskDiskUsage, 0)
+	if got := Count(TaskDiskUsage); got != 5 {
+		t.Fatalf("Count(du) after reset = %d, want 5", got)
+	}
+}
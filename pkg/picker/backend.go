@@ -0,0 +1,53 @@
+package picker
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+)
+
+// FSBackend lists fsys as if it were mounted at root, e.g. an embed.FS or an
+// fstest.MapFS; set Options.Dir to root or a directory below it. Directories
+// outside root cannot be listed.
+func FSBackend(fsys fs.FS, root string) Backend {
+	return fsBackend{fsys: fsys, root: filepath.Clean(root)}
+}
+
+type fsBackend struct {
+	fsys fs.FS
+	root string
+}
+
+func (b fsBackend) ReadDir(ctx context.Context, dir string) ([]Entry, error) {
+	rel, err := filepath.Rel(b.root, dir)
+	if err != nil {
+		return nil, err
+	}
+	name := filepath.ToSlash(rel)
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: dir, Err: fs.ErrNotExist}
+	}
+	dirEntries, err := fs.ReadDir(b.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(dirEntries))
+	for _, d := range dirEntries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		info, err := d.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, Entry{
+			Name:      d.Name(),
+			IsDir:     d.IsDir(),
+			IsSymlink: info.Mode()&fs.ModeSymlink != 0,
+			Size:      info.Size(),
+			Modified:  info.ModTime(),
+			Mode:      info.Mode(),
+		})
+	}
+	return entries, nil
+}
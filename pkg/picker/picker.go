@@ -0,0 +1,188 @@
+// Package picker embeds rdir in other Go programs as a file and directory
+// picker. Pick runs the full rdir interface until the user chooses entries
+// with Enter (the marked entries, or the file under the cursor) or quits.
+//
+//	paths, err := picker.Pick(picker.Options{
+//		Dir: "~/projects",
+//		Keys: map[string]picker.KeyHandler{
+//			// Pick the directory being shown.
+//			"ctrl+d": func(c picker.Context) ([]string, bool) { return []string{c.Dir}, true },
+//		},
+//	})
+//
+// The user's RDIR_* settings apply, except those that decide what Enter does
+// or start a shared session.
+package picker
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	apppkg "github.com/kk-code-lab/rdir/internal/app"
+	fsutil "github.com/kk-code-lab/rdir/internal/fs"
+	statepkg "github.com/kk-code-lab/rdir/internal/state"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Options configures a Pick. The zero value picks files starting in the
+// working directory of the local filesystem, in the terminal.
+type Options struct {
+	// Dir is where the picker opens: a directory, or a file to select in its
+	// directory. Empty means the working directory. With a Backend, Dir must
+	// be a directory path of that backend.
+	Dir string
+	// Screen replaces the terminal, e.g. tcell.NewSimulationScreen in tests.
+	// Pick initializes and finalizes it.
+	Screen tcell.Screen
+	// FS replaces the local filesystem for listing, previews, the pager and
+	// search, e.g. FromFS over an embed.FS. Dir must be a path of FS.
+	FS FS
+	// Backend lists directories instead of FS. Previews and file operations
+	// still use FS or the local filesystem.
+	Backend Backend
+	// Getenv replaces os.Getenv for reading RDIR_* settings, PAGER, VISUAL,
+	// EDITOR and SHELL.
+	Getenv func(string) string
+
+	// OnSelect is called with the chosen paths before Pick returns them;
+	// returning false rejects the choice and keeps the picker open.
+	OnSelect func(paths []string) bool
+	// OnMove is called when the directory or the entry under the cursor
+	// changes; selected is empty in an empty directory.
+	OnMove func(dir, selected string)
+	// Keys binds extra keys in the file list, before rdir's own bindings.
+	// Names are modifiers joined with "+" and a key: "x", "X", "alt+x",
+	// "ctrl+o", "f2", "enter". Printable keys match their character exactly;
+	// everything else is case-insensitive.
+	Keys map[string]KeyHandler
+}
+
+// Context is what a key handler sees of the picker.
+type Context struct {
+	Dir      string   // directory being shown
+	Selected string   // entry under the cursor, empty in an empty directory
+	Marked   []string // marked entries, in list order
+}
+
+// KeyHandler handles a bound key. Returning done ends Pick with paths (still
+// subject to OnSelect); done with no paths ends it without a choice. Not done
+// keeps the picker open.
+type KeyHandler func(c Context) (paths []string, done bool)
+
+// Entry is one directory entry listed by a Backend.
+type Entry struct {
+	Name      string
+	IsDir     bool
+	IsSymlink bool
+	Size      int64
+	Modified  time.Time
+	Mode      fs.FileMode
+}
+
+// Backend lists directories for the picker. dir is an absolute path in the
+// backend's namespace; ReadDir is called from background goroutines and
+// should return when ctx is done.
+type Backend interface {
+	ReadDir(ctx context.Context, dir string) ([]Entry, error)
+}
+
+// FS is a filesystem the picker can browse in place of the local disk.
+// Names are absolute paths in its own namespace, built with path/filepath;
+// ReadDir should return when ctx is done.
+type FS = fsutil.FS
+
+// File is an open file of an FS. Seek and ReadAt may return
+// errors.ErrUnsupported; the pager then reads sequentially.
+type File = fsutil.File
+
+// FromFS serves fsys as an FS mounted at root, e.g. an embed.FS or an
+// fstest.MapFS; set Options.Dir to root or a directory below it.
+func FromFS(fsys fs.FS, root string) FS {
+	return fsutil.FromFS(fsys, root)
+}
+
+// Pick runs the picker and returns the chosen paths, or nil when the user
+// quit without choosing.
+func Pick(opts Options) ([]string, error) {
+	app, err := apppkg.NewApplicationWith(opts.appOptions())
+	if err != nil {
+		return nil, err
+	}
+	app.Run()
+	paths := app.GetPrintPaths()
+	if err := app.Close(); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+func (o Options) appOptions() apppkg.Options {
+	getenv := o.Getenv
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+	app := apppkg.Options{
+		Getenv: func(key string) string {
+			switch key {
+			case apppkg.EnvStartPath:
+				return o.Dir
+			case statepkg.EnvEnter:
+				return "print"
+			case apppkg.EnvPrintMarked:
+				return "1"
+			case statepkg.EnvEnterExt, apppkg.EnvShare, apppkg.EnvFollow:
+				return ""
+			case statepkg.EnvStagingFile:
+				// Entries staged in the picker are not shared with rdir.
+				return "off"
+			}
+			return getenv(key)
+		},
+		Screen:   o.Screen,
+		FS:       o.FS,
+		Accept:   o.OnSelect,
+		OnCursor: o.OnMove,
+	}
+	if o.Backend != nil {
+		app.ReadEntries = readEntries(o.Backend)
+	}
+	if len(o.Keys) > 0 {
+		app.Keys = make(map[string]apppkg.KeyHandler, len(o.Keys))
+		for name, handler := range o.Keys {
+			app.Keys[name] = func(view apppkg.View) ([]string, bool) {
+				return handler(Context{Dir: view.Dir, Selected: view.Selected, Marked: view.Marked})
+			}
+		}
+	}
+	return app
+}
+
+func readEntries(backend Backend) statepkg.EntryReader {
+	return func(ctx context.Context, dir string) ([]statepkg.FileEntry, error) {
+		entries, err := backend.ReadDir(ctx, dir)
+		if err != nil {
+			return nil, err
+		}
+		files := make([]statepkg.FileEntry, 0, len(entries))
+		for _, e := range entries {
+			mode := e.Mode
+			if e.IsDir {
+				mode |= fs.ModeDir
+			}
+			files = append(files, statepkg.FileEntry{
+				Name:      norm.NFC.String(e.Name),
+				FullPath:  filepath.Join(dir, e.Name),
+				IsDir:     e.IsDir,
+				IsSymlink: e.IsSymlink,
+				Size:      e.Size,
+				Modified:  e.Modified,
+				Mode:      mode,
+			})
+		}
+		return files, nil
+	}
+}
@@ -0,0 +1,148 @@
+package picker
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func isolate(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("USERPROFILE", dir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(dir, "state"))
+}
+
+func newScreen(t *testing.T) tcell.SimulationScreen {
+	t.Helper()
+	screen := tcell.NewSimulationScreen("UTF-8")
+	screen.SetSize(80, 20)
+	return screen
+}
+
+// script presses the next keys each time the cursor moves, so they arrive
+// once the picker has shown the previous step.
+func script(screen tcell.SimulationScreen, steps ...[]tcell.Key) func(dir, selected string) {
+	return func(dir, selected string) {
+		if len(steps) == 0 {
+			return
+		}
+		for _, key := range steps[0] {
+			screen.InjectKey(key, 0, tcell.ModNone)
+		}
+		steps = steps[1:]
+	}
+}
+
+func TestPickFromBackend(t *testing.T) {
+	isolate(t)
+	root := filepath.Join(t.TempDir(), "virtual")
+	fsys := fstest.MapFS{
+		"a.txt":      {Data: []byte("a")},
+		"docs/b.txt": {Data: []byte("b")},
+	}
+	screen := newScreen(t)
+	var moves []string
+	var offered [][]string
+	step := script(screen, []tcell.Key{tcell.KeyEnter}, []tcell.Key{tcell.KeyEnter})
+
+	paths, err := Pick(Options{
+		Dir:     root,
+		Screen:  screen,
+		Backend: FSBackend(fsys, root),
+		OnMove: func(dir, selected string) {
+			moves = append(moves, selected)
+			step(dir, selected)
+		},
+		OnSelect: func(paths []string) bool {
+			offered = append(offered, paths)
+			return true
+		},
+	})
+	if err != nil {
+		t.Fatalf("Pick: %v", err)
+	}
+	want := filepath.Join(root, "docs", "b.txt")
+	if len(paths) != 1 || paths[0] != want {
+		t.Fatalf("paths = %v, want [%s] (moves %v)", paths, want, moves)
+	}
+	if len(offered) != 1 {
+		t.Fatalf("OnSelect calls = %v", offered)
+	}
+	if len(moves) < 2 || moves[0] != filepath.Join(root, "docs") {
+		t.Fatalf("moves = %v", moves)
+	}
+}
+
+func TestPickKeyHandlerAndRejectedChoice(t *testing.T) {
+	isolate(t)
+	dir := t.TempDir()
+	screen := newScreen(t)
+	rejected := 0
+	step := script(screen, []tcell.Key{tcell.KeyCtrlP, tcell.KeyCtrlD})
+
+	paths, err := Pick(Options{
+		Dir:    dir,
+		Screen: screen,
+		OnMove: step,
+		OnSelect: func(paths []string) bool {
+			rejected++
+			return rejected > 1
+		},
+		Keys: map[string]KeyHandler{
+			"Ctrl+P": func(c Context) ([]string, bool) { return []string{c.Dir}, true },
+			"ctrl+d": func(c Context) ([]string, bool) { return []string{c.Dir + "!"}, true },
+		},
+	})
+	if err != nil {
+		t.Fatalf("Pick: %v", err)
+	}
+	if len(paths) != 1 || !strings.HasSuffix(paths[0], "!") || rejected != 2 {
+		t.Fatalf("paths = %v after %d OnSelect calls", paths, rejected)
+	}
+}
+
+func TestPickFromFSStartsOnFile(t *testing.T) {
+	isolate(t)
+	root := filepath.Join(t.TempDir(), "virtual")
+	fsys := fstest.MapFS{
+		"a.txt":      {Data: []byte("a")},
+		"docs/b.txt": {Data: []byte("b")},
+		"docs/c.txt": {Data: []byte("c")},
+	}
+	screen := newScreen(t)
+	step := script(screen, []tcell.Key{tcell.KeyEnter})
+
+	paths, err := Pick(Options{
+		Dir:    filepath.Join(root, "docs", "c.txt"),
+		Screen: screen,
+		FS:     FromFS(fsys, root),
+		OnMove: step,
+	})
+	if err != nil {
+		t.Fatalf("Pick: %v", err)
+	}
+	want := filepath.Join(root, "docs", "c.txt")
+	if len(paths) != 1 || paths[0] != want {
+		t.Fatalf("paths = %v, want [%s]", paths, want)
+	}
+}
+
+func TestPickerSettingsComeFromGetenv(t *testing.T) {
+	opts := Options{Dir: "/start", Getenv: func(key string) string {
+		return map[string]string{"PAGER": "most", "RDIR_STAGING_FILE": "/shared.json"}[key]
+	}}
+	getenv := opts.appOptions().Getenv
+	if got := getenv("PAGER"); got != "most" {
+		t.Fatalf("PAGER = %q, want the value from Options.Getenv", got)
+	}
+	if got := getenv("RDIR_STAGING_FILE"); got != "off" {
+		t.Fatalf("RDIR_STAGING_FILE = %q, want the picker to keep its own staging", got)
+	}
+}
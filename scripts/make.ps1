@@ -26,7 +26,7 @@ try {
     $exeSuffix = $IsWindows ? '.exe' : ''
     $binPath = Join-Path $buildDir "$binaryName$exeSuffix"
     $mainEntry = './cmd/rdir'
-    $internalPackages = './internal/...'
+    $internalPackages = @('./internal/...', './pkg/...')
 
     if (-not $env:RDIR_DEBUG_LOG) {
         $env:RDIR_DEBUG_LOG = '1'